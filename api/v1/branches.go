@@ -0,0 +1,181 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"mote/internal/gateway/handlers"
+	"mote/internal/storage"
+)
+
+// ForkSessionRequest is the body of POST /api/v1/sessions/{id}/fork.
+type ForkSessionRequest struct {
+	// MessageID is the last message copied into the fork; empty copies the
+	// whole conversation.
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// EditMessageRequest is the body of POST /api/v1/sessions/{id}/messages/{messageId}/edit.
+type EditMessageRequest struct {
+	Content string `json:"content"`
+}
+
+// ForkSessionResponse describes a newly created branch.
+type ForkSessionResponse struct {
+	Session       SessionSummary `json:"session"`
+	ParentID      string         `json:"parent_id"`
+	ForkMessageID string         `json:"fork_message_id,omitempty"`
+	// Content is the edited message. Send it to the new session with
+	// /chat or /chat/stream to regenerate the conversation from there.
+	Content string `json:"content,omitempty"`
+}
+
+// SessionBranchesResponse is returned by GET /api/v1/sessions/{id}/branches.
+type SessionBranchesResponse struct {
+	Current string                 `json:"current"`
+	Tree    *storage.SessionBranch `json:"tree"`
+}
+
+// HandleForkSession copies a session up to a message into a new branch.
+func (r *Router) HandleForkSession(w http.ResponseWriter, req *http.Request) {
+	if r.db == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "Database not available")
+		return
+	}
+	id := mux.Vars(req)["id"]
+
+	var body ForkSessionRequest
+	if req.ContentLength > 0 {
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			handlers.SendError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid JSON body")
+			return
+		}
+	}
+
+	branch, err := r.db.ForkSession(id, body.MessageID)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.SendError(w, http.StatusNotFound, handlers.ErrCodeNotFound, "Session or message not found")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("session", id).Msg("Failed to fork session")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "Failed to fork session")
+		return
+	}
+	r.copyWorkspaceBinding(id, branch.ID)
+
+	handlers.SendJSON(w, http.StatusCreated, r.forkResponse(id, branch.ID, ""))
+}
+
+// HandleEditMessage branches a session before a user message so the edited
+// message can be sent to the branch in its place.
+func (r *Router) HandleEditMessage(w http.ResponseWriter, req *http.Request) {
+	if r.db == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "Database not available")
+		return
+	}
+	vars := mux.Vars(req)
+	id, messageID := vars["id"], vars["messageId"]
+
+	var body EditMessageRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid JSON body")
+		return
+	}
+	if strings.TrimSpace(body.Content) == "" {
+		handlers.SendError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "content is required")
+		return
+	}
+
+	branch, _, err := r.db.BranchForEdit(id, messageID)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		handlers.SendError(w, http.StatusNotFound, handlers.ErrCodeNotFound, "Session or message not found")
+		return
+	case errors.Is(err, storage.ErrNotUserMessage):
+		handlers.SendError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	case err != nil:
+		log.Error().Err(err).Str("session", id).Str("message", messageID).Msg("Failed to branch session for edit")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "Failed to create branch")
+		return
+	}
+	r.copyWorkspaceBinding(id, branch.ID)
+
+	handlers.SendJSON(w, http.StatusCreated, r.forkResponse(id, branch.ID, body.Content))
+}
+
+// HandleGetSessionBranches returns the branch tree the session belongs to.
+func (r *Router) HandleGetSessionBranches(w http.ResponseWriter, req *http.Request) {
+	if r.db == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "Database not available")
+		return
+	}
+	id := mux.Vars(req)["id"]
+
+	tree, err := r.db.GetSessionTree(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		handlers.SendError(w, http.StatusNotFound, handlers.ErrCodeNotFound, "Session not found")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("session", id).Msg("Failed to load session branches")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "Failed to load session branches")
+		return
+	}
+	handlers.SendJSON(w, http.StatusOK, SessionBranchesResponse{Current: id, Tree: tree})
+}
+
+// forkResponse describes the branch created from parentID.
+func (r *Router) forkResponse(parentID, branchID, content string) ForkSessionResponse {
+	resp := ForkSessionResponse{ParentID: parentID, Content: content}
+	if tree, err := r.db.GetSessionTree(branchID); err == nil {
+		if node := findBranch(tree, branchID); node != nil {
+			resp.ForkMessageID = node.ForkMessageID
+			resp.Session = SessionSummary{
+				ID:           node.ID,
+				CreatedAt:    node.CreatedAt,
+				UpdatedAt:    node.UpdatedAt,
+				MessageCount: node.MessageCount,
+				Title:        node.Title,
+				Model:        node.Model,
+			}
+		}
+	}
+	if resp.Session.ID == "" {
+		resp.Session.ID = branchID
+	}
+	return resp
+}
+
+// copyWorkspaceBinding binds the branch to the parent's workspace.
+func (r *Router) copyWorkspaceBinding(parentID, branchID string) {
+	if r.workspaceManager == nil {
+		return
+	}
+	binding, ok := r.workspaceManager.Get(parentID)
+	if !ok || binding == nil {
+		return
+	}
+	if err := r.workspaceManager.BindWithAlias(branchID, binding.Path, binding.Alias, binding.ReadOnly); err != nil {
+		log.Warn().Err(err).Str("parent", parentID).Str("branch", branchID).Msg("Failed to copy workspace binding to branch")
+	}
+}
+
+// findBranch finds a node in a branch tree.
+func findBranch(node *storage.SessionBranch, id string) *storage.SessionBranch {
+	if node.ID == id {
+		return node
+	}
+	for _, child := range node.Children {
+		if found := findBranch(child, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
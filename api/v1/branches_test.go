@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"mote/internal/storage"
+)
+
+func TestSessionBranchesAPI(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	defer db.Close()
+
+	src, _ := db.CreateSession(nil)
+	first, _ := db.AppendMessage(src.ID, "user", "write a poem", nil, "")
+	_, _ = db.AppendMessage(src.ID, "assistant", "roses are red", nil, "")
+
+	m := mux.NewRouter()
+	NewRouter(&RouterDeps{DB: db}).RegisterRoutes(m)
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		m.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodPost, "/api/v1/sessions/"+src.ID+"/fork", nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("fork: status %d: %s", rr.Code, rr.Body.String())
+	}
+	var fork ForkSessionResponse
+	_ = json.Unmarshal(rr.Body.Bytes(), &fork)
+	if fork.ParentID != src.ID || fork.Session.MessageCount != 2 {
+		t.Errorf("unexpected fork response: %+v", fork)
+	}
+
+	rr = do(http.MethodPost, "/api/v1/sessions/"+src.ID+"/messages/"+first.ID+"/edit",
+		EditMessageRequest{Content: "write a haiku"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("edit: status %d: %s", rr.Code, rr.Body.String())
+	}
+	var edit ForkSessionResponse
+	_ = json.Unmarshal(rr.Body.Bytes(), &edit)
+	if edit.Content != "write a haiku" || edit.Session.MessageCount != 0 {
+		t.Errorf("unexpected edit response: %+v", edit)
+	}
+
+	rr = do(http.MethodGet, "/api/v1/sessions/"+edit.Session.ID+"/branches", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("branches: status %d: %s", rr.Code, rr.Body.String())
+	}
+	var branches SessionBranchesResponse
+	_ = json.Unmarshal(rr.Body.Bytes(), &branches)
+	if branches.Current != edit.Session.ID || branches.Tree.ID != src.ID || len(branches.Tree.Children) != 2 {
+		t.Errorf("unexpected tree: %+v", branches)
+	}
+
+	if rr := do(http.MethodGet, "/api/v1/sessions/missing/branches", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown session: status %d", rr.Code)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/viper"
 
 	internalChannel "mote/internal/channel"
+	"mote/internal/config"
 	"mote/internal/gateway/handlers"
 	"mote/pkg/channel"
 )
@@ -107,6 +108,195 @@ type AppleRemindersChannelConfigRequest struct {
 	PollInterval string           `json:"pollInterval"`
 }
 
+// TelegramChannelConfigResponse Telegram 配置响应（不返回 token 本身）
+type TelegramChannelConfigResponse struct {
+	Enabled        bool              `json:"enabled"`
+	Model          string            `json:"model,omitempty"`
+	TokenSet       bool              `json:"tokenSet"`
+	BaseURL        string            `json:"baseUrl"`
+	Mode           string            `json:"mode"`
+	WebhookURL     string            `json:"webhookUrl,omitempty"`
+	WebhookListen  string            `json:"webhookListen,omitempty"`
+	RequireMention bool              `json:"requireMention"`
+	AllowFrom      []string          `json:"allowFrom"`
+	Trigger        TriggerConfigResp `json:"trigger"`
+	Reply          ReplyConfigResp   `json:"reply"`
+}
+
+// TelegramChannelConfigRequest Telegram 配置请求，token 为空时保留原值
+type TelegramChannelConfigRequest struct {
+	Enabled        bool             `json:"enabled"`
+	Model          string           `json:"model,omitempty"`
+	Token          string           `json:"token,omitempty"`
+	BaseURL        string           `json:"baseUrl"`
+	Mode           string           `json:"mode"`
+	WebhookURL     string           `json:"webhookUrl,omitempty"`
+	WebhookListen  string           `json:"webhookListen,omitempty"`
+	WebhookSecret  string           `json:"webhookSecret,omitempty"`
+	RequireMention bool             `json:"requireMention"`
+	AllowFrom      []string         `json:"allowFrom"`
+	Trigger        TriggerConfigReq `json:"trigger"`
+	Reply          ReplyConfigReq   `json:"reply"`
+}
+
+// EmailChannelConfigResponse 邮件渠道配置响应（不返回密码本身）
+type EmailChannelConfigResponse struct {
+	Enabled              bool              `json:"enabled"`
+	Model                string            `json:"model,omitempty"`
+	IMAPAddr             string            `json:"imapAddr"`
+	IMAPTLS              bool              `json:"imapTls"`
+	Username             string            `json:"username"`
+	PasswordSet          bool              `json:"passwordSet"`
+	Folder               string            `json:"folder"`
+	PollInterval         string            `json:"pollInterval"`
+	UseIdle              bool              `json:"useIdle"`
+	SMTPAddr             string            `json:"smtpAddr"`
+	SMTPTLS              bool              `json:"smtpTls"`
+	SMTPUsername         string            `json:"smtpUsername,omitempty"`
+	SMTPPasswordSet      bool              `json:"smtpPasswordSet"`
+	From                 string            `json:"from,omitempty"`
+	AllowFrom            []string          `json:"allowFrom"`
+	RequireAuthenticated bool              `json:"requireAuthenticated"`
+	AuthServID           string            `json:"authServId"`
+	Trigger              TriggerConfigResp `json:"trigger"`
+	Reply                ReplyConfigResp   `json:"reply"`
+}
+
+// EmailChannelConfigRequest 邮件渠道配置请求，密码为空时保留原值
+type EmailChannelConfigRequest struct {
+	Enabled              bool             `json:"enabled"`
+	Model                string           `json:"model,omitempty"`
+	IMAPAddr             string           `json:"imapAddr"`
+	IMAPTLS              bool             `json:"imapTls"`
+	Username             string           `json:"username"`
+	Password             string           `json:"password,omitempty"`
+	Folder               string           `json:"folder"`
+	PollInterval         string           `json:"pollInterval"`
+	UseIdle              bool             `json:"useIdle"`
+	SMTPAddr             string           `json:"smtpAddr"`
+	SMTPTLS              bool             `json:"smtpTls"`
+	SMTPUsername         string           `json:"smtpUsername,omitempty"`
+	SMTPPassword         string           `json:"smtpPassword,omitempty"`
+	From                 string           `json:"from,omitempty"`
+	AllowFrom            []string         `json:"allowFrom"`
+	RequireAuthenticated bool             `json:"requireAuthenticated"`
+	AuthServID           string           `json:"authServId"`
+	Trigger              TriggerConfigReq `json:"trigger"`
+	Reply                ReplyConfigReq   `json:"reply"`
+}
+
+// MatrixChannelConfigResponse Matrix 配置响应（不返回 access token 本身）
+type MatrixChannelConfigResponse struct {
+	Enabled        bool              `json:"enabled"`
+	Model          string            `json:"model,omitempty"`
+	Homeserver     string            `json:"homeserver"`
+	AccessTokenSet bool              `json:"accessTokenSet"`
+	SyncTimeout    string            `json:"syncTimeout"`
+	RequireMention bool              `json:"requireMention"`
+	AllowFrom      []string          `json:"allowFrom"`
+	AutoJoin       bool              `json:"autoJoin"`
+	Trigger        TriggerConfigResp `json:"trigger"`
+	Reply          ReplyConfigResp   `json:"reply"`
+}
+
+// MatrixChannelConfigRequest Matrix 配置请求，access token 为空时保留原值
+type MatrixChannelConfigRequest struct {
+	Enabled        bool             `json:"enabled"`
+	Model          string           `json:"model,omitempty"`
+	Homeserver     string           `json:"homeserver"`
+	AccessToken    string           `json:"accessToken,omitempty"`
+	SyncTimeout    string           `json:"syncTimeout"`
+	RequireMention bool             `json:"requireMention"`
+	AllowFrom      []string         `json:"allowFrom"`
+	AutoJoin       bool             `json:"autoJoin"`
+	Trigger        TriggerConfigReq `json:"trigger"`
+	Reply          ReplyConfigReq   `json:"reply"`
+}
+
+// SlackChannelConfigResponse Slack 配置响应（不返回 token 和签名密钥本身）
+type SlackChannelConfigResponse struct {
+	Enabled          bool              `json:"enabled"`
+	Model            string            `json:"model,omitempty"`
+	BotTokenSet      bool              `json:"botTokenSet"`
+	AppTokenSet      bool              `json:"appTokenSet"`
+	SigningSecretSet bool              `json:"signingSecretSet"`
+	BaseURL          string            `json:"baseUrl"`
+	Mode             string            `json:"mode"`
+	EventsListen     string            `json:"eventsListen,omitempty"`
+	EventsPath       string            `json:"eventsPath,omitempty"`
+	RequireMention   bool              `json:"requireMention"`
+	AllowFrom        []string          `json:"allowFrom"`
+	Trigger          TriggerConfigResp `json:"trigger"`
+	Reply            ReplyConfigResp   `json:"reply"`
+}
+
+// SlackChannelConfigRequest Slack 配置请求，token 和签名密钥为空时保留原值
+type SlackChannelConfigRequest struct {
+	Enabled        bool             `json:"enabled"`
+	Model          string           `json:"model,omitempty"`
+	BotToken       string           `json:"botToken,omitempty"`
+	AppToken       string           `json:"appToken,omitempty"`
+	SigningSecret  string           `json:"signingSecret,omitempty"`
+	BaseURL        string           `json:"baseUrl"`
+	Mode           string           `json:"mode"`
+	EventsListen   string           `json:"eventsListen,omitempty"`
+	EventsPath     string           `json:"eventsPath,omitempty"`
+	RequireMention bool             `json:"requireMention"`
+	AllowFrom      []string         `json:"allowFrom"`
+	Trigger        TriggerConfigReq `json:"trigger"`
+	Reply          ReplyConfigReq   `json:"reply"`
+}
+
+// WebhookHookConfig 一个 webhook 入口的配置。响应中不返回 secret，只返回 secretSet；
+// 请求中 secret 为空时保留同名入口的原值
+type WebhookHookConfig struct {
+	Name             string                      `json:"name"`
+	Auth             string                      `json:"auth"`
+	Secret           string                      `json:"secret,omitempty"`
+	SecretSet        bool                        `json:"secretSet"`
+	SignatureHeader  string                      `json:"signatureHeader,omitempty"`
+	TokenHeader      string                      `json:"tokenHeader,omitempty"`
+	Match            []config.WebhookMatchConfig `json:"match"`
+	Mapping          WebhookMappingConfig        `json:"mapping"`
+	CallbackURL      string                      `json:"callbackUrl,omitempty"`
+	CallbackMethod   string                      `json:"callbackMethod,omitempty"`
+	CallbackHeaders  map[string]string           `json:"callbackHeaders,omitempty"`
+	CallbackTemplate string                      `json:"callbackTemplate,omitempty"`
+}
+
+// WebhookMappingConfig 入站 JSON 到消息字段的路径映射
+type WebhookMappingConfig struct {
+	Content         string `json:"content,omitempty"`
+	ContentTemplate string `json:"contentTemplate,omitempty"`
+	ChatID          string `json:"chatId,omitempty"`
+	SenderID        string `json:"senderId,omitempty"`
+	SenderName      string `json:"senderName,omitempty"`
+	MessageID       string `json:"messageId,omitempty"`
+}
+
+// WebhookChannelConfigResponse Webhook 配置响应
+type WebhookChannelConfigResponse struct {
+	Enabled bool                `json:"enabled"`
+	Model   string              `json:"model,omitempty"`
+	Hooks   []WebhookHookConfig `json:"hooks"`
+	Trigger TriggerConfigResp   `json:"trigger"`
+	Reply   ReplyConfigResp     `json:"reply"`
+}
+
+// WebhookChannelConfigRequest Webhook 配置请求
+type WebhookChannelConfigRequest struct {
+	Enabled bool                `json:"enabled"`
+	Model   string              `json:"model,omitempty"`
+	Hooks   []WebhookHookConfig `json:"hooks"`
+	Trigger TriggerConfigReq    `json:"trigger"`
+	Reply   ReplyConfigReq      `json:"reply"`
+}
+
+// webhookServer 能处理 webhook 入站请求的渠道
+type webhookServer interface {
+	ServeWebhook(w http.ResponseWriter, r *http.Request, name string)
+}
+
 // SetChannelRegistry 设置 channel registry 依赖
 func (r *Router) SetChannelRegistry(registry *internalChannel.Registry) {
 	r.channelRegistry = registry
@@ -120,6 +310,31 @@ var supportedChannels = []struct {
 	{Type: string(channel.ChannelTypeIMessage), Name: "iMessage"},
 	{Type: string(channel.ChannelTypeNotes), Name: "Apple Notes"},
 	{Type: string(channel.ChannelTypeReminders), Name: "Apple Reminders"},
+	{Type: string(channel.ChannelTypeTelegram), Name: "Telegram"},
+	{Type: string(channel.ChannelTypeEmail), Name: "Email"},
+	{Type: string(channel.ChannelTypeMatrix), Name: "Matrix"},
+	{Type: string(channel.ChannelTypeSlack), Name: "Slack"},
+	{Type: string(channel.ChannelTypeWebhook), Name: "Webhook"},
+}
+
+// HandleChannelWebhook 接收外部系统发往 webhook 渠道的请求
+func (r *Router) HandleChannelWebhook(w http.ResponseWriter, req *http.Request) {
+	registry := r.channelRegistry
+	if r.runner != nil {
+		if runnerRegistry := r.runner.ChannelRegistry(); runnerRegistry != nil {
+			registry = runnerRegistry
+		}
+	}
+	var plugin channel.ChannelPlugin
+	if registry != nil {
+		plugin, _ = registry.Get(channel.ChannelTypeWebhook)
+	}
+	server, ok := plugin.(webhookServer)
+	if !ok {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "webhook channel is not running")
+		return
+	}
+	server.ServeWebhook(w, req, mux.Vars(req)["name"])
 }
 
 // HandleListChannels 返回所有渠道状态列表
@@ -174,6 +389,16 @@ func (r *Router) HandleGetChannelConfig(w http.ResponseWriter, req *http.Request
 		r.getAppleNotesConfig(w)
 	case string(channel.ChannelTypeReminders):
 		r.getAppleRemindersConfig(w)
+	case string(channel.ChannelTypeTelegram):
+		r.getTelegramConfig(w)
+	case string(channel.ChannelTypeEmail):
+		r.getEmailConfig(w)
+	case string(channel.ChannelTypeMatrix):
+		r.getMatrixConfig(w)
+	case string(channel.ChannelTypeSlack):
+		r.getSlackConfig(w)
+	case string(channel.ChannelTypeWebhook):
+		r.getWebhookConfig(w)
 	default:
 		handlers.SendError(w, http.StatusNotFound, handlers.ErrCodeNotFound, "channel not found")
 	}
@@ -283,6 +508,146 @@ func (r *Router) getAppleRemindersConfig(w http.ResponseWriter) {
 	handlers.SendJSON(w, http.StatusOK, config)
 }
 
+func (r *Router) getTelegramConfig(w http.ResponseWriter) {
+	config := TelegramChannelConfigResponse{
+		Enabled:        viper.GetBool("channels.telegram.enabled"),
+		Model:          viper.GetString("channels.telegram.model"),
+		TokenSet:       viper.GetString("channels.telegram.token") != "",
+		BaseURL:        viper.GetString("channels.telegram.base_url"),
+		Mode:           viper.GetString("channels.telegram.mode"),
+		WebhookURL:     viper.GetString("channels.telegram.webhook_url"),
+		WebhookListen:  viper.GetString("channels.telegram.webhook_listen"),
+		RequireMention: viper.GetBool("channels.telegram.require_mention"),
+		AllowFrom:      viper.GetStringSlice("channels.telegram.allow_from"),
+		Trigger: TriggerConfigResp{
+			Prefix:        viper.GetString("channels.telegram.trigger.prefix"),
+			CaseSensitive: viper.GetBool("channels.telegram.trigger.case_sensitive"),
+		},
+		Reply: ReplyConfigResp{
+			Prefix:    viper.GetString("channels.telegram.reply.prefix"),
+			Separator: viper.GetString("channels.telegram.reply.separator"),
+		},
+	}
+
+	// 设置默认值
+	if config.Mode == "" {
+		config.Mode = "polling"
+	}
+	if config.AllowFrom == nil {
+		config.AllowFrom = []string{}
+	}
+
+	handlers.SendJSON(w, http.StatusOK, config)
+}
+
+func (r *Router) getEmailConfig(w http.ResponseWriter) {
+	config := EmailChannelConfigResponse{
+		Enabled:              viper.GetBool("channels.email.enabled"),
+		Model:                viper.GetString("channels.email.model"),
+		IMAPAddr:             viper.GetString("channels.email.imap_addr"),
+		IMAPTLS:              viper.GetBool("channels.email.imap_tls"),
+		Username:             viper.GetString("channels.email.username"),
+		PasswordSet:          viper.GetString("channels.email.password") != "",
+		Folder:               viper.GetString("channels.email.folder"),
+		PollInterval:         viper.GetString("channels.email.poll_interval"),
+		UseIdle:              viper.GetBool("channels.email.use_idle"),
+		SMTPAddr:             viper.GetString("channels.email.smtp_addr"),
+		SMTPTLS:              viper.GetBool("channels.email.smtp_tls"),
+		SMTPUsername:         viper.GetString("channels.email.smtp_username"),
+		SMTPPasswordSet:      viper.GetString("channels.email.smtp_password") != "",
+		From:                 viper.GetString("channels.email.from"),
+		AllowFrom:            viper.GetStringSlice("channels.email.allow_from"),
+		RequireAuthenticated: viper.GetBool("channels.email.require_authenticated"),
+		AuthServID:           viper.GetString("channels.email.auth_serv_id"),
+		Trigger: TriggerConfigResp{
+			Prefix:        viper.GetString("channels.email.trigger.prefix"),
+			CaseSensitive: viper.GetBool("channels.email.trigger.case_sensitive"),
+		},
+		Reply: ReplyConfigResp{
+			Prefix:    viper.GetString("channels.email.reply.prefix"),
+			Separator: viper.GetString("channels.email.reply.separator"),
+		},
+	}
+
+	// 设置默认值
+	if config.Folder == "" {
+		config.Folder = "INBOX"
+	}
+	if config.PollInterval == "" {
+		config.PollInterval = "1m"
+	}
+	if config.AllowFrom == nil {
+		config.AllowFrom = []string{}
+	}
+
+	handlers.SendJSON(w, http.StatusOK, config)
+}
+
+func (r *Router) getMatrixConfig(w http.ResponseWriter) {
+	config := MatrixChannelConfigResponse{
+		Enabled:        viper.GetBool("channels.matrix.enabled"),
+		Model:          viper.GetString("channels.matrix.model"),
+		Homeserver:     viper.GetString("channels.matrix.homeserver"),
+		AccessTokenSet: viper.GetString("channels.matrix.access_token") != "",
+		SyncTimeout:    viper.GetString("channels.matrix.sync_timeout"),
+		RequireMention: viper.GetBool("channels.matrix.require_mention"),
+		AllowFrom:      viper.GetStringSlice("channels.matrix.allow_from"),
+		AutoJoin:       viper.GetBool("channels.matrix.auto_join"),
+		Trigger: TriggerConfigResp{
+			Prefix:        viper.GetString("channels.matrix.trigger.prefix"),
+			CaseSensitive: viper.GetBool("channels.matrix.trigger.case_sensitive"),
+		},
+		Reply: ReplyConfigResp{
+			Prefix:    viper.GetString("channels.matrix.reply.prefix"),
+			Separator: viper.GetString("channels.matrix.reply.separator"),
+		},
+	}
+
+	// 设置默认值
+	if config.SyncTimeout == "" {
+		config.SyncTimeout = "30s"
+	}
+	if config.AllowFrom == nil {
+		config.AllowFrom = []string{}
+	}
+
+	handlers.SendJSON(w, http.StatusOK, config)
+}
+
+func (r *Router) getSlackConfig(w http.ResponseWriter) {
+	config := SlackChannelConfigResponse{
+		Enabled:          viper.GetBool("channels.slack.enabled"),
+		Model:            viper.GetString("channels.slack.model"),
+		BotTokenSet:      viper.GetString("channels.slack.bot_token") != "",
+		AppTokenSet:      viper.GetString("channels.slack.app_token") != "",
+		SigningSecretSet: viper.GetString("channels.slack.signing_secret") != "",
+		BaseURL:          viper.GetString("channels.slack.base_url"),
+		Mode:             viper.GetString("channels.slack.mode"),
+		EventsListen:     viper.GetString("channels.slack.events_listen"),
+		EventsPath:       viper.GetString("channels.slack.events_path"),
+		RequireMention:   viper.GetBool("channels.slack.require_mention"),
+		AllowFrom:        viper.GetStringSlice("channels.slack.allow_from"),
+		Trigger: TriggerConfigResp{
+			Prefix:        viper.GetString("channels.slack.trigger.prefix"),
+			CaseSensitive: viper.GetBool("channels.slack.trigger.case_sensitive"),
+		},
+		Reply: ReplyConfigResp{
+			Prefix:    viper.GetString("channels.slack.reply.prefix"),
+			Separator: viper.GetString("channels.slack.reply.separator"),
+		},
+	}
+
+	// 设置默认值
+	if config.Mode == "" {
+		config.Mode = "socket"
+	}
+	if config.AllowFrom == nil {
+		config.AllowFrom = []string{}
+	}
+
+	handlers.SendJSON(w, http.StatusOK, config)
+}
+
 // HandleUpdateChannelConfig 更新指定渠道配置
 func (r *Router) HandleUpdateChannelConfig(w http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
@@ -295,6 +660,16 @@ func (r *Router) HandleUpdateChannelConfig(w http.ResponseWriter, req *http.Requ
 		r.updateAppleNotesConfig(w, req)
 	case string(channel.ChannelTypeReminders):
 		r.updateAppleRemindersConfig(w, req)
+	case string(channel.ChannelTypeTelegram):
+		r.updateTelegramConfig(w, req)
+	case string(channel.ChannelTypeEmail):
+		r.updateEmailConfig(w, req)
+	case string(channel.ChannelTypeMatrix):
+		r.updateMatrixConfig(w, req)
+	case string(channel.ChannelTypeSlack):
+		r.updateSlackConfig(w, req)
+	case string(channel.ChannelTypeWebhook):
+		r.updateWebhookConfig(w, req)
 	default:
 		handlers.SendError(w, http.StatusNotFound, handlers.ErrCodeNotFound, "channel not found")
 	}
@@ -374,6 +749,168 @@ func (r *Router) updateAppleRemindersConfig(w http.ResponseWriter, req *http.Req
 	handlers.SendJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
+func (r *Router) updateTelegramConfig(w http.ResponseWriter, req *http.Request) {
+	var body TelegramChannelConfigRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if body.Mode != "" && body.Mode != "polling" && body.Mode != "webhook" {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "mode must be polling or webhook")
+		return
+	}
+
+	viper.Set("channels.telegram.enabled", body.Enabled)
+	viper.Set("channels.telegram.model", body.Model)
+	if body.Token != "" {
+		viper.Set("channels.telegram.token", body.Token)
+	}
+	if body.WebhookSecret != "" {
+		viper.Set("channels.telegram.webhook_secret", body.WebhookSecret)
+	}
+	viper.Set("channels.telegram.base_url", body.BaseURL)
+	viper.Set("channels.telegram.mode", body.Mode)
+	viper.Set("channels.telegram.webhook_url", body.WebhookURL)
+	viper.Set("channels.telegram.webhook_listen", body.WebhookListen)
+	viper.Set("channels.telegram.require_mention", body.RequireMention)
+	viper.Set("channels.telegram.allow_from", body.AllowFrom)
+	viper.Set("channels.telegram.trigger.prefix", body.Trigger.Prefix)
+	viper.Set("channels.telegram.trigger.case_sensitive", body.Trigger.CaseSensitive)
+	viper.Set("channels.telegram.reply.prefix", body.Reply.Prefix)
+	viper.Set("channels.telegram.reply.separator", body.Reply.Separator)
+
+	if err := viper.WriteConfig(); err != nil {
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to save config: "+err.Error())
+		return
+	}
+
+	handlers.SendJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (r *Router) updateEmailConfig(w http.ResponseWriter, req *http.Request) {
+	var body EmailChannelConfigRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if body.PollInterval != "" {
+		if _, err := time.ParseDuration(body.PollInterval); err != nil {
+			handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid pollInterval: "+err.Error())
+			return
+		}
+	}
+
+	viper.Set("channels.email.enabled", body.Enabled)
+	viper.Set("channels.email.model", body.Model)
+	if body.Password != "" {
+		viper.Set("channels.email.password", body.Password)
+	}
+	if body.SMTPPassword != "" {
+		viper.Set("channels.email.smtp_password", body.SMTPPassword)
+	}
+	viper.Set("channels.email.imap_addr", body.IMAPAddr)
+	viper.Set("channels.email.imap_tls", body.IMAPTLS)
+	viper.Set("channels.email.username", body.Username)
+	viper.Set("channels.email.folder", body.Folder)
+	viper.Set("channels.email.poll_interval", body.PollInterval)
+	viper.Set("channels.email.use_idle", body.UseIdle)
+	viper.Set("channels.email.smtp_addr", body.SMTPAddr)
+	viper.Set("channels.email.smtp_tls", body.SMTPTLS)
+	viper.Set("channels.email.smtp_username", body.SMTPUsername)
+	viper.Set("channels.email.from", body.From)
+	viper.Set("channels.email.allow_from", body.AllowFrom)
+	viper.Set("channels.email.require_authenticated", body.RequireAuthenticated)
+	viper.Set("channels.email.auth_serv_id", body.AuthServID)
+	viper.Set("channels.email.trigger.prefix", body.Trigger.Prefix)
+	viper.Set("channels.email.trigger.case_sensitive", body.Trigger.CaseSensitive)
+	viper.Set("channels.email.reply.prefix", body.Reply.Prefix)
+	viper.Set("channels.email.reply.separator", body.Reply.Separator)
+
+	if err := viper.WriteConfig(); err != nil {
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to save config: "+err.Error())
+		return
+	}
+
+	handlers.SendJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (r *Router) updateMatrixConfig(w http.ResponseWriter, req *http.Request) {
+	var body MatrixChannelConfigRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if body.SyncTimeout != "" {
+		if _, err := time.ParseDuration(body.SyncTimeout); err != nil {
+			handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid syncTimeout: "+err.Error())
+			return
+		}
+	}
+
+	viper.Set("channels.matrix.enabled", body.Enabled)
+	viper.Set("channels.matrix.model", body.Model)
+	if body.AccessToken != "" {
+		viper.Set("channels.matrix.access_token", body.AccessToken)
+	}
+	viper.Set("channels.matrix.homeserver", body.Homeserver)
+	viper.Set("channels.matrix.sync_timeout", body.SyncTimeout)
+	viper.Set("channels.matrix.require_mention", body.RequireMention)
+	viper.Set("channels.matrix.allow_from", body.AllowFrom)
+	viper.Set("channels.matrix.auto_join", body.AutoJoin)
+	viper.Set("channels.matrix.trigger.prefix", body.Trigger.Prefix)
+	viper.Set("channels.matrix.trigger.case_sensitive", body.Trigger.CaseSensitive)
+	viper.Set("channels.matrix.reply.prefix", body.Reply.Prefix)
+	viper.Set("channels.matrix.reply.separator", body.Reply.Separator)
+
+	if err := viper.WriteConfig(); err != nil {
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to save config: "+err.Error())
+		return
+	}
+
+	handlers.SendJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (r *Router) updateSlackConfig(w http.ResponseWriter, req *http.Request) {
+	var body SlackChannelConfigRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if body.Mode != "" && body.Mode != "socket" && body.Mode != "events" {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "mode must be socket or events")
+		return
+	}
+
+	viper.Set("channels.slack.enabled", body.Enabled)
+	viper.Set("channels.slack.model", body.Model)
+	if body.BotToken != "" {
+		viper.Set("channels.slack.bot_token", body.BotToken)
+	}
+	if body.AppToken != "" {
+		viper.Set("channels.slack.app_token", body.AppToken)
+	}
+	if body.SigningSecret != "" {
+		viper.Set("channels.slack.signing_secret", body.SigningSecret)
+	}
+	viper.Set("channels.slack.base_url", body.BaseURL)
+	viper.Set("channels.slack.mode", body.Mode)
+	viper.Set("channels.slack.events_listen", body.EventsListen)
+	viper.Set("channels.slack.events_path", body.EventsPath)
+	viper.Set("channels.slack.require_mention", body.RequireMention)
+	viper.Set("channels.slack.allow_from", body.AllowFrom)
+	viper.Set("channels.slack.trigger.prefix", body.Trigger.Prefix)
+	viper.Set("channels.slack.trigger.case_sensitive", body.Trigger.CaseSensitive)
+	viper.Set("channels.slack.reply.prefix", body.Reply.Prefix)
+	viper.Set("channels.slack.reply.separator", body.Reply.Separator)
+
+	if err := viper.WriteConfig(); err != nil {
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to save config: "+err.Error())
+		return
+	}
+
+	handlers.SendJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
 // HandleStartChannel 启动指定渠道
 func (r *Router) HandleStartChannel(w http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
@@ -421,3 +958,110 @@ func (r *Router) HandleStopChannel(w http.ResponseWriter, req *http.Request) {
 
 	handlers.SendJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
+
+func (r *Router) getWebhookConfig(w http.ResponseWriter) {
+	cfg := config.GetChannelsConfig().Webhook
+	resp := WebhookChannelConfigResponse{
+		Enabled: viper.GetBool("channels.webhook.enabled"),
+		Model:   viper.GetString("channels.webhook.model"),
+		Hooks:   make([]WebhookHookConfig, 0, len(cfg.Hooks)),
+		Trigger: TriggerConfigResp{
+			Prefix:        viper.GetString("channels.webhook.trigger.prefix"),
+			CaseSensitive: viper.GetBool("channels.webhook.trigger.case_sensitive"),
+		},
+		Reply: ReplyConfigResp{
+			Prefix:    viper.GetString("channels.webhook.reply.prefix"),
+			Separator: viper.GetString("channels.webhook.reply.separator"),
+		},
+	}
+	for _, h := range cfg.Hooks {
+		match := h.Match
+		if match == nil {
+			match = []config.WebhookMatchConfig{}
+		}
+		resp.Hooks = append(resp.Hooks, WebhookHookConfig{
+			Name:            h.Name,
+			Auth:            h.Auth,
+			SecretSet:       h.Secret != "",
+			SignatureHeader: h.SignatureHeader,
+			TokenHeader:     h.TokenHeader,
+			Match:           match,
+			Mapping: WebhookMappingConfig{
+				Content:         h.Mapping.Content,
+				ContentTemplate: h.Mapping.ContentTemplate,
+				ChatID:          h.Mapping.ChatID,
+				SenderID:        h.Mapping.SenderID,
+				SenderName:      h.Mapping.SenderName,
+				MessageID:       h.Mapping.MessageID,
+			},
+			CallbackURL:      h.CallbackURL,
+			CallbackMethod:   h.CallbackMethod,
+			CallbackHeaders:  h.CallbackHeaders,
+			CallbackTemplate: h.CallbackTemplate,
+		})
+	}
+
+	handlers.SendJSON(w, http.StatusOK, resp)
+}
+
+func (r *Router) updateWebhookConfig(w http.ResponseWriter, req *http.Request) {
+	var body WebhookChannelConfigRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	// secret 为空时沿用同名入口的原值
+	secrets := make(map[string]string)
+	for _, h := range config.GetChannelsConfig().Webhook.Hooks {
+		secrets[h.Name] = h.Secret
+	}
+	hooks := make([]config.WebhookHookConfig, 0, len(body.Hooks))
+	seen := make(map[string]bool)
+	for _, h := range body.Hooks {
+		if h.Name == "" || seen[h.Name] {
+			handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "webhook names must be unique and non-empty")
+			return
+		}
+		seen[h.Name] = true
+		secret := h.Secret
+		if secret == "" {
+			secret = secrets[h.Name]
+		}
+		hooks = append(hooks, config.WebhookHookConfig{
+			Name:            h.Name,
+			Auth:            h.Auth,
+			Secret:          secret,
+			SignatureHeader: h.SignatureHeader,
+			TokenHeader:     h.TokenHeader,
+			Match:           h.Match,
+			Mapping: config.WebhookMappingConfig{
+				Content:         h.Mapping.Content,
+				ContentTemplate: h.Mapping.ContentTemplate,
+				ChatID:          h.Mapping.ChatID,
+				SenderID:        h.Mapping.SenderID,
+				SenderName:      h.Mapping.SenderName,
+				MessageID:       h.Mapping.MessageID,
+			},
+			CallbackURL:      h.CallbackURL,
+			CallbackMethod:   h.CallbackMethod,
+			CallbackHeaders:  h.CallbackHeaders,
+			CallbackTemplate: h.CallbackTemplate,
+		})
+	}
+
+	viper.Set("channels.webhook.enabled", body.Enabled)
+	viper.Set("channels.webhook.model", body.Model)
+	viper.Set("channels.webhook.hooks", hooks)
+	viper.Set("channels.webhook.trigger.prefix", body.Trigger.Prefix)
+	viper.Set("channels.webhook.trigger.case_sensitive", body.Trigger.CaseSensitive)
+	viper.Set("channels.webhook.reply.prefix", body.Reply.Prefix)
+	viper.Set("channels.webhook.reply.separator", body.Reply.Separator)
+
+	if err := viper.WriteConfig(); err != nil {
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to save config: "+err.Error())
+		return
+	}
+
+	handlers.SendJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -2,15 +2,23 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	internalChannel "mote/internal/channel"
+	"mote/internal/channel/webhook"
+	"mote/internal/config"
+	"mote/pkg/channel"
 )
 
 func TestHandleListChannels_ReturnsAllSupportedChannels(t *testing.T) {
@@ -31,7 +39,7 @@ func TestHandleListChannels_ReturnsAllSupportedChannels(t *testing.T) {
 	require.NoError(t, err)
 
 	// 应该返回所有支持的渠道（即使未启用）
-	assert.Len(t, statuses, 3)
+	assert.Len(t, statuses, 8)
 
 	// 验证渠道类型
 	types := make([]string, len(statuses))
@@ -41,6 +49,11 @@ func TestHandleListChannels_ReturnsAllSupportedChannels(t *testing.T) {
 	assert.Contains(t, types, "imessage")
 	assert.Contains(t, types, "apple-notes")
 	assert.Contains(t, types, "apple-reminders")
+	assert.Contains(t, types, "telegram")
+	assert.Contains(t, types, "email")
+	assert.Contains(t, types, "matrix")
+	assert.Contains(t, types, "slack")
+	assert.Contains(t, types, "webhook")
 
 	// 默认都应该是停止状态
 	for _, s := range statuses {
@@ -167,6 +180,198 @@ func TestHandleUpdateChannelConfig_IMessage(t *testing.T) {
 	assert.ElementsMatch(t, []string{"newuser@example.com"}, viper.GetStringSlice("channels.imessage.allow_from"))
 }
 
+func TestHandleChannelConfig_TelegramTokenIsWriteOnly(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	router := NewRouter(nil)
+
+	bodyBytes, _ := json.Marshal(TelegramChannelConfigRequest{Enabled: true, Token: "123:abc", Mode: "webhook"})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/channels/telegram/config", bytes.NewReader(bodyBytes))
+	req = mux.SetURLVars(req, map[string]string{"type": "telegram"})
+	router.HandleUpdateChannelConfig(httptest.NewRecorder(), req)
+
+	// 不带 token 的更新保留原 token
+	bodyBytes, _ = json.Marshal(TelegramChannelConfigRequest{Enabled: true, Mode: "polling"})
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/channels/telegram/config", bytes.NewReader(bodyBytes))
+	req = mux.SetURLVars(req, map[string]string{"type": "telegram"})
+	router.HandleUpdateChannelConfig(httptest.NewRecorder(), req)
+	assert.Equal(t, "123:abc", viper.GetString("channels.telegram.token"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/channels/telegram/config", nil)
+	req = mux.SetURLVars(req, map[string]string{"type": "telegram"})
+	rr := httptest.NewRecorder()
+	router.HandleGetChannelConfig(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "123:abc")
+	var config TelegramChannelConfigResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&config))
+	assert.True(t, config.TokenSet)
+	assert.Equal(t, "polling", config.Mode)
+}
+
+func TestHandleChannelConfig_EmailPasswordIsWriteOnly(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	router := NewRouter(nil)
+
+	bodyBytes, _ := json.Marshal(EmailChannelConfigRequest{Enabled: true, Username: "mote@example.com", Password: "s3cret", PollInterval: "2m"})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/channels/email/config", bytes.NewReader(bodyBytes))
+	req = mux.SetURLVars(req, map[string]string{"type": "email"})
+	router.HandleUpdateChannelConfig(httptest.NewRecorder(), req)
+
+	// 不带密码的更新保留原密码
+	bodyBytes, _ = json.Marshal(EmailChannelConfigRequest{Enabled: true, Username: "mote@example.com", AllowFrom: []string{"@example.com"}})
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/channels/email/config", bytes.NewReader(bodyBytes))
+	req = mux.SetURLVars(req, map[string]string{"type": "email"})
+	router.HandleUpdateChannelConfig(httptest.NewRecorder(), req)
+	assert.Equal(t, "s3cret", viper.GetString("channels.email.password"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/channels/email/config", nil)
+	req = mux.SetURLVars(req, map[string]string{"type": "email"})
+	rr := httptest.NewRecorder()
+	router.HandleGetChannelConfig(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "s3cret")
+	var config EmailChannelConfigResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&config))
+	assert.True(t, config.PasswordSet)
+	assert.False(t, config.SMTPPasswordSet)
+	assert.Equal(t, []string{"@example.com"}, config.AllowFrom)
+
+	bodyBytes, _ = json.Marshal(EmailChannelConfigRequest{PollInterval: "often"})
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/channels/email/config", bytes.NewReader(bodyBytes))
+	req = mux.SetURLVars(req, map[string]string{"type": "email"})
+	rr = httptest.NewRecorder()
+	router.HandleUpdateChannelConfig(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestHandleChannelConfig_SlackSecretsAreWriteOnly(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	router := NewRouter(nil)
+
+	bodyBytes, _ := json.Marshal(SlackChannelConfigRequest{Enabled: true, BotToken: "xoxb-1", AppToken: "xapp-1", Mode: "socket"})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/channels/slack/config", bytes.NewReader(bodyBytes))
+	req = mux.SetURLVars(req, map[string]string{"type": "slack"})
+	router.HandleUpdateChannelConfig(httptest.NewRecorder(), req)
+
+	bodyBytes, _ = json.Marshal(SlackChannelConfigRequest{Enabled: true, Mode: "events", SigningSecret: "shh"})
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/channels/slack/config", bytes.NewReader(bodyBytes))
+	req = mux.SetURLVars(req, map[string]string{"type": "slack"})
+	router.HandleUpdateChannelConfig(httptest.NewRecorder(), req)
+	assert.Equal(t, "xoxb-1", viper.GetString("channels.slack.bot_token"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/channels/slack/config", nil)
+	req = mux.SetURLVars(req, map[string]string{"type": "slack"})
+	rr := httptest.NewRecorder()
+	router.HandleGetChannelConfig(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "xoxb-1")
+	assert.NotContains(t, rr.Body.String(), "shh")
+	var config SlackChannelConfigResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&config))
+	assert.True(t, config.BotTokenSet)
+	assert.True(t, config.AppTokenSet)
+	assert.True(t, config.SigningSecretSet)
+	assert.Equal(t, "events", config.Mode)
+
+	bodyBytes, _ = json.Marshal(SlackChannelConfigRequest{Mode: "rtm"})
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/channels/slack/config", bytes.NewReader(bodyBytes))
+	req = mux.SetURLVars(req, map[string]string{"type": "slack"})
+	rr = httptest.NewRecorder()
+	router.HandleUpdateChannelConfig(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestHandleChannelConfig_WebhookSecretsAreWriteOnly(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	router := NewRouter(nil)
+	put := func(body WebhookChannelConfigRequest) *httptest.ResponseRecorder {
+		bodyBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/channels/webhook/config", bytes.NewReader(bodyBytes))
+		req = mux.SetURLVars(req, map[string]string{"type": "webhook"})
+		rr := httptest.NewRecorder()
+		router.HandleUpdateChannelConfig(rr, req)
+		return rr
+	}
+
+	hook := WebhookHookConfig{
+		Name:    "gitlab",
+		Auth:    "bearer",
+		Secret:  "s3cret",
+		Match:   []config.WebhookMatchConfig{{Path: "object_kind", Value: "note"}},
+		Mapping: WebhookMappingConfig{Content: "object_attributes.note", ChatID: "issue.iid"},
+	}
+	put(WebhookChannelConfigRequest{Enabled: true, Hooks: []WebhookHookConfig{hook}})
+	hook.Secret = ""
+	hook.CallbackURL = "https://gitlab.example.com/api/v4/notes"
+	put(WebhookChannelConfigRequest{Enabled: true, Hooks: []WebhookHookConfig{hook}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/channels/webhook/config", nil)
+	req = mux.SetURLVars(req, map[string]string{"type": "webhook"})
+	rr := httptest.NewRecorder()
+	router.HandleGetChannelConfig(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "s3cret")
+	var resp WebhookChannelConfigResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp.Hooks, 1)
+	assert.True(t, resp.Hooks[0].SecretSet)
+	assert.Equal(t, "https://gitlab.example.com/api/v4/notes", resp.Hooks[0].CallbackURL)
+	assert.Equal(t, "issue.iid", resp.Hooks[0].Mapping.ChatID)
+	assert.Equal(t, "s3cret", config.GetChannelsConfig().Webhook.Hooks[0].Secret)
+
+	rr = put(WebhookChannelConfigRequest{Hooks: []WebhookHookConfig{{Name: "a"}, {Name: "a"}}})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestHandleChannelWebhook(t *testing.T) {
+	registry := internalChannel.NewRegistry()
+	router := NewRouter(&RouterDeps{ChannelRegistry: registry})
+	m := mux.NewRouter()
+	router.RegisterRoutes(m)
+
+	post := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"text": "hello"}`))
+		req.Header.Set("Authorization", "Bearer t0ken")
+		rr := httptest.NewRecorder()
+		m.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Not running yet
+	assert.Equal(t, http.StatusServiceUnavailable, post("/api/v1/channels/webhook/ci").Code)
+
+	ch := webhook.New(webhook.Config{Hooks: []webhook.Hook{{Name: "ci", Auth: webhook.AuthBearer, Secret: "t0ken", Mapping: webhook.Mapping{Content: "text"}}}})
+	received := make(chan channel.InboundMessage, 1)
+	ch.OnMessage(func(ctx context.Context, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	require.NoError(t, ch.Start(context.Background()))
+	registry.Register(ch)
+
+	assert.Equal(t, http.StatusAccepted, post("/api/v1/channels/webhook/ci").Code)
+	select {
+	case msg := <-received:
+		assert.Equal(t, "hello", msg.Content)
+		assert.Equal(t, "ci", msg.ChatID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+	assert.Equal(t, http.StatusNotFound, post("/api/v1/channels/webhook/other").Code)
+}
+
 func TestHandleUpdateChannelConfig_InvalidBody(t *testing.T) {
 	router := NewRouter(nil)
 
@@ -68,6 +68,9 @@ func (r *Router) HandleChat(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	// Attach MCP resources referenced as @server:uri
+	attachments = append(attachments, BuildResourceAttachments(ctx, r.mcpClient, chatReq.Message)...)
+
 	// Provide a default message if only images are sent
 	message := chatReq.Message
 	if message == "" && len(attachments) > 0 {
@@ -464,6 +467,9 @@ func (r *Router) HandleChatStream(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	// Attach MCP resources referenced as @server:uri
+	attachments = append(attachments, BuildResourceAttachments(ctx, r.mcpClient, chatReq.Message)...)
+
 	// Provide a default message if only images are sent
 	message := chatReq.Message
 	if message == "" && len(attachments) > 0 {
@@ -531,6 +537,7 @@ func (r *Router) HandleChatStream(w http.ResponseWriter, req *http.Request) {
 						ToolName:   event.ToolCallUpdate.ToolName,
 						Status:     event.ToolCallUpdate.Status,
 						Arguments:  event.ToolCallUpdate.Arguments,
+						Message:    event.ToolCallUpdate.Message,
 					},
 				}
 			} else {
@@ -609,6 +616,8 @@ func (r *Router) HandleChatStream(w http.ResponseWriter, req *http.Request) {
 						Reason:    event.ApprovalRequest.Reason,
 						SessionID: event.ApprovalRequest.SessionID,
 						ExpiresAt: event.ApprovalRequest.ExpiresAt,
+
+						WorkspacePath: event.ApprovalRequest.WorkspacePath,
 					},
 				}
 			} else {
@@ -0,0 +1,277 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"mote/internal/config"
+	"mote/internal/eval"
+	"mote/internal/gateway/handlers"
+)
+
+// RunEvalRequest is the body of POST /api/v1/eval/runs.
+type RunEvalRequest struct {
+	// Suite is the suite definition in YAML.
+	Suite string `json:"suite"`
+	// Models to run the suite against, one run each. Empty uses the suite's
+	// models, then the agent's own model.
+	Models []string `json:"models,omitempty"`
+	Label  string   `json:"label,omitempty"`
+	// Agent optionally replaces the installed agent config, so a candidate
+	// version can be evaluated before it is saved.
+	Agent *config.AgentConfig `json:"agent,omitempty"`
+	// Timeout limits each run, as a Go duration such as "30m". Empty uses
+	// defaultEvalRunTimeout.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// defaultEvalRunTimeout bounds a background eval run that sets no timeout.
+const defaultEvalRunTimeout = time.Hour
+
+// RunEvalResponse is returned by POST /api/v1/eval/runs. The runs are still
+// running; poll GET /api/v1/eval/runs/{id} for their results.
+type RunEvalResponse struct {
+	Runs []*eval.Run `json:"runs"`
+}
+
+func (r *Router) evalStore() *eval.Store {
+	if r.db == nil {
+		return nil
+	}
+	return eval.NewStore(r.db.DB)
+}
+
+// HandleRunEval starts runs of a suite against one or more models and returns
+// them right away. The runs execute one after another in the background and
+// store their results as they go.
+func (r *Router) HandleRunEval(w http.ResponseWriter, req *http.Request) {
+	var body RunEvalRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid request body")
+		return
+	}
+	suite, err := eval.ParseSuite([]byte(body.Suite))
+	if err != nil {
+		handlers.SendError(w, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+		return
+	}
+	timeout := defaultEvalRunTimeout
+	if body.Timeout != "" {
+		if timeout, err = time.ParseDuration(body.Timeout); err != nil || timeout <= 0 {
+			handlers.SendError(w, http.StatusBadRequest, ErrCodeValidationFailed, "invalid timeout: "+body.Timeout)
+			return
+		}
+	}
+
+	if r.runner == nil || r.runner.DelegateFactory() == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "delegate support not initialized")
+		return
+	}
+
+	cfg := config.GetConfig()
+	var agent config.AgentConfig
+	switch {
+	case body.Agent != nil:
+		agent = *body.Agent
+	case cfg != nil && cfg.Agents != nil:
+		var ok bool
+		if agent, ok = cfg.Agents[suite.Agent]; !ok {
+			handlers.SendError(w, http.StatusNotFound, "NOT_FOUND", "agent not found: "+suite.Agent)
+			return
+		}
+	default:
+		handlers.SendError(w, http.StatusNotFound, "NOT_FOUND", "agent not found: "+suite.Agent)
+		return
+	}
+
+	maxDepth := 0
+	if cfg != nil {
+		maxDepth = cfg.Delegate.GetMaxDepth()
+	}
+	var judge eval.Judge
+	if r.multiPool != nil {
+		judge = eval.NewProviderJudge(r.multiPool)
+	}
+	runner := eval.NewRunner(eval.NewFactoryExecutor(r.runner.DelegateFactory(), maxDepth), judge, r.evalStore())
+
+	models := body.Models
+	if len(models) == 0 {
+		models = suite.Models
+	}
+	if len(models) == 0 {
+		models = []string{""}
+	}
+
+	// Each run gets its own cancellable context, registered before the
+	// response is sent so that a queued run can be cancelled as well.
+	type pendingRun struct {
+		run    *eval.Run
+		ctx    context.Context
+		cancel context.CancelFunc
+	}
+	resp := RunEvalResponse{}
+	var runs []pendingRun
+	for _, model := range models {
+		run, err := runner.Begin(suite, agent, eval.RunOptions{Model: model, Label: body.Label})
+		if err != nil {
+			// Finish the runs already begun as failed so they do not stay running.
+			for _, p := range runs {
+				r.evalCancels.Delete(p.run.ID)
+				p.cancel()
+				_ = runner.Execute(p.ctx, p.run, suite, agent)
+			}
+			log.Error().Err(err).Str("suite", suite.Name).Str("model", model).Msg("Failed to start eval run")
+			handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to start eval run: "+err.Error())
+			return
+		}
+		snapshot := *run
+		resp.Runs = append(resp.Runs, &snapshot)
+		ctx, cancel := context.WithCancel(context.Background())
+		r.evalCancels.Store(run.ID, cancel)
+		runs = append(runs, pendingRun{run: run, ctx: ctx, cancel: cancel})
+	}
+
+	go func() {
+		for _, p := range runs {
+			ctx, cancel := context.WithTimeout(p.ctx, timeout)
+			if err := runner.Execute(ctx, p.run, suite, agent); err != nil {
+				log.Error().Err(err).Str("suite", suite.Name).Str("run", p.run.ID).Msg("Eval run failed")
+			}
+			cancel()
+			p.cancel()
+			r.evalCancels.Delete(p.run.ID)
+		}
+	}()
+	handlers.SendJSON(w, http.StatusAccepted, resp)
+}
+
+// HandleCancelEvalRun stops a running or queued eval run. The run is marked
+// failed with the results of the cases that finished before it stopped.
+func (r *Router) HandleCancelEvalRun(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if cancel, ok := r.evalCancels.LoadAndDelete(id); ok {
+		cancel.(context.CancelFunc)()
+		handlers.SendJSON(w, http.StatusAccepted, map[string]any{"cancelled": true})
+		return
+	}
+
+	store := r.evalStore()
+	if store == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "database not available")
+		return
+	}
+	run, err := store.GetRun(id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get eval run")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to get eval run")
+		return
+	}
+	if run == nil {
+		handlers.SendError(w, http.StatusNotFound, "NOT_FOUND", "eval run not found")
+		return
+	}
+	handlers.SendError(w, http.StatusConflict, "CONFLICT", "eval run is not running: "+run.Status)
+}
+
+// HandleListEvalRuns lists recent eval runs, optionally filtered by ?suite=.
+func (r *Router) HandleListEvalRuns(w http.ResponseWriter, req *http.Request) {
+	store := r.evalStore()
+	if store == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "database not available")
+		return
+	}
+
+	limit := 50
+	if l := req.URL.Query().Get("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 {
+			limit = n
+		}
+	}
+	runs, err := store.ListRuns(req.URL.Query().Get("suite"), limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list eval runs")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to list eval runs")
+		return
+	}
+	if runs == nil {
+		runs = []eval.Run{}
+	}
+	handlers.SendJSON(w, http.StatusOK, map[string]any{"runs": runs})
+}
+
+// HandleGetEvalRun returns one run with its case results.
+func (r *Router) HandleGetEvalRun(w http.ResponseWriter, req *http.Request) {
+	store := r.evalStore()
+	if store == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "database not available")
+		return
+	}
+
+	run, err := store.GetRun(mux.Vars(req)["id"])
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get eval run")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to get eval run")
+		return
+	}
+	if run == nil {
+		handlers.SendError(w, http.StatusNotFound, "NOT_FOUND", "eval run not found")
+		return
+	}
+	handlers.SendJSON(w, http.StatusOK, run)
+}
+
+// HandleDeleteEvalRun deletes a run and its results.
+func (r *Router) HandleDeleteEvalRun(w http.ResponseWriter, req *http.Request) {
+	store := r.evalStore()
+	if store == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "database not available")
+		return
+	}
+	id := mux.Vars(req)["id"]
+	if cancel, ok := r.evalCancels.LoadAndDelete(id); ok {
+		cancel.(context.CancelFunc)()
+	}
+	if err := store.DeleteRun(id); err != nil {
+		handlers.SendError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+		return
+	}
+	handlers.SendJSON(w, http.StatusOK, map[string]any{"deleted": true})
+}
+
+// HandleCompareEvalRuns diffs two runs case by case: ?base=<id>&other=<id>.
+func (r *Router) HandleCompareEvalRuns(w http.ResponseWriter, req *http.Request) {
+	store := r.evalStore()
+	if store == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "database not available")
+		return
+	}
+
+	q := req.URL.Query()
+	baseID, otherID := q.Get("base"), q.Get("other")
+	if baseID == "" || otherID == "" {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "base and other run IDs are required")
+		return
+	}
+
+	var runs [2]*eval.Run
+	for i, id := range []string{baseID, otherID} {
+		run, err := store.GetRun(id)
+		if err != nil {
+			log.Error().Err(err).Str("run", id).Msg("Failed to get eval run")
+			handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to get eval run")
+			return
+		}
+		if run == nil {
+			handlers.SendError(w, http.StatusNotFound, "NOT_FOUND", "eval run not found: "+id)
+			return
+		}
+		runs[i] = run
+	}
+	handlers.SendJSON(w, http.StatusOK, eval.Compare(runs[0], runs[1]))
+}
@@ -13,6 +13,7 @@ import (
 	"mote/internal/config"
 	"mote/internal/gateway/handlers"
 	"mote/internal/mcp/client"
+	"mote/internal/mcp/oauth"
 	"mote/internal/mcp/transport"
 
 	"github.com/gorilla/mux"
@@ -26,6 +27,34 @@ type MCPServerPersist struct {
 	Headers map[string]string `json:"headers,omitempty"`
 	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
+	OAuth   *oauth.Config     `json:"oauth,omitempty"`
+	MCPServerLimits
+}
+
+// clientConfig builds the client config for a persisted server. It reports
+// false for an unknown server type.
+func (p MCPServerPersist) clientConfig() (client.ClientConfig, bool) {
+	cfg := client.ClientConfig{
+		Command:      p.Name, // Command is used as the client name
+		IncludeTools: p.IncludeTools,
+		ExcludeTools: p.ExcludeTools,
+		CallTimeout:  time.Duration(p.Timeout) * time.Second,
+		CacheTTL:     time.Duration(p.CacheTTL) * time.Second,
+		CacheTools:   p.CacheTools,
+	}
+	switch p.Type {
+	case "http":
+		cfg.TransportType = transport.TransportStreamableHTTP
+		cfg.URL = p.URL
+		cfg.Headers = p.Headers
+	case "stdio":
+		cfg.TransportType = transport.TransportStdio
+		cfg.Command = p.Command
+		cfg.Args = p.Args
+	default:
+		return cfg, false
+	}
+	return cfg, true
 }
 
 var mcpConfigMu sync.Mutex
@@ -143,20 +172,8 @@ func LoadSavedMCPServers(ctx context.Context, manager *client.Manager) error {
 	}
 
 	for _, server := range servers {
-		cfg := client.ClientConfig{
-			Command: server.Name,
-		}
-
-		switch server.Type {
-		case "http":
-			cfg.TransportType = transport.TransportHTTP
-			cfg.URL = server.URL
-			cfg.Headers = server.Headers
-		case "stdio":
-			cfg.TransportType = transport.TransportStdio
-			cfg.Command = server.Command
-			cfg.Args = server.Args
-		default:
+		cfg, ok := server.clientConfig()
+		if !ok {
 			continue
 		}
 
@@ -184,12 +201,14 @@ func (r *Router) HandleListMCPServers(w http.ResponseWriter, req *http.Request)
 		statuses := r.mcpClient.ListServers()
 		for _, status := range statuses {
 			info := MCPServerInfo{
-				Name:        status.Name,
-				Status:      status.State.String(),
-				Transport:   status.TransportType,
-				ToolCount:   status.ToolCount,
-				PromptCount: status.PromptCount,
-				Error:       status.LastError,
+				Name:          status.Name,
+				Status:        status.State.String(),
+				Transport:     transportName(status.TransportType),
+				ToolCount:     status.ToolCount,
+				PromptCount:   status.PromptCount,
+				ResourceCount: status.ResourceCount,
+				Error:         status.LastError,
+				Health:        status.Health,
 			}
 			// Add config info if available
 			if cfg, ok := cfgMap[status.Name]; ok {
@@ -197,6 +216,8 @@ func (r *Router) HandleListMCPServers(w http.ResponseWriter, req *http.Request)
 				info.Headers = cfg.Headers
 				info.Command = cfg.Command
 				info.Args = cfg.Args
+				info.Authorized = r.mcpAuthorized(cfg)
+				info.MCPServerLimits = cfg.MCPServerLimits
 			}
 			connectedServers[status.Name] = info
 		}
@@ -216,14 +237,16 @@ func (r *Router) HandleListMCPServers(w http.ResponseWriter, req *http.Request)
 	for _, cfg := range configuredServers {
 		if !seenNames[cfg.Name] {
 			servers = append(servers, MCPServerInfo{
-				Name:      cfg.Name,
-				Status:    "disconnected",
-				Transport: cfg.Type,
-				URL:       cfg.URL,
-				Headers:   cfg.Headers,
-				Command:   cfg.Command,
-				Args:      cfg.Args,
-				Error:     "未连接 - 服务可能未启动",
+				Name:            cfg.Name,
+				Status:          "disconnected",
+				Transport:       cfg.Type,
+				URL:             cfg.URL,
+				Headers:         cfg.Headers,
+				Command:         cfg.Command,
+				Args:            cfg.Args,
+				Authorized:      r.mcpAuthorized(cfg),
+				Error:           "未连接 - 服务可能未启动",
+				MCPServerLimits: cfg.MCPServerLimits,
 			})
 		}
 	}
@@ -396,8 +419,20 @@ type MCPAddRequest struct {
 	Headers     map[string]string `json:"headers,omitempty"`
 	Command     string            `json:"command,omitempty"`
 	Args        []string          `json:"args,omitempty"`
+	OAuth       *oauth.Config     `json:"oauth,omitempty"`
 	Description string            `json:"description,omitempty"`
 	Version     string            `json:"version,omitempty"`
+	MCPServerLimits
+}
+
+// MCPServerLimits are the per-server tool filters, call timeout and result
+// cache settings.
+type MCPServerLimits struct {
+	IncludeTools []string `json:"include_tools,omitempty"` // only expose these tools (globs)
+	ExcludeTools []string `json:"exclude_tools,omitempty"` // hide these tools (globs)
+	Timeout      int      `json:"timeout,omitempty"`       // tool call timeout in seconds
+	CacheTTL     int      `json:"cache_ttl,omitempty"`     // cache tool results for this many seconds
+	CacheTools   []string `json:"cache_tools,omitempty"`   // cacheable tools (globs), default read-only idempotent ones
 }
 
 // MCPImportRequest is the request body for importing MCP servers from JSON config.
@@ -411,8 +446,10 @@ type MCPServerConfig struct {
 	Headers     map[string]string `json:"headers,omitempty"`
 	Command     string            `json:"command,omitempty"`
 	Args        []string          `json:"args,omitempty"`
+	OAuth       *oauth.Config     `json:"oauth,omitempty"`
 	Description string            `json:"description,omitempty"`
 	Version     string            `json:"version,omitempty"`
+	MCPServerLimits
 }
 
 // HandleAddMCPServer adds a new MCP server connection.
@@ -433,29 +470,18 @@ func (r *Router) HandleAddMCPServer(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Build client config
-	config := client.ClientConfig{
-		Command: reqBody.Name,
-	}
-
 	switch reqBody.Type {
 	case "http":
 		if reqBody.URL == "" {
 			handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "url is required for http type")
 			return
 		}
-		config.TransportType = transport.TransportHTTP
-		config.URL = reqBody.URL
-		config.Headers = reqBody.Headers
 
 	case "stdio":
 		if reqBody.Command == "" {
 			handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "command is required for stdio type")
 			return
 		}
-		config.TransportType = transport.TransportStdio
-		config.Command = reqBody.Command
-		config.Args = reqBody.Args
 
 	default:
 		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "type must be 'http' or 'stdio'")
@@ -464,17 +490,20 @@ func (r *Router) HandleAddMCPServer(w http.ResponseWriter, req *http.Request) {
 
 	// Persist the server configuration first (so it can be reconnected later even if connection fails now)
 	persist := MCPServerPersist{
-		Name:    reqBody.Name,
-		Type:    reqBody.Type,
-		URL:     reqBody.URL,
-		Headers: reqBody.Headers,
-		Command: reqBody.Command,
-		Args:    reqBody.Args,
+		Name:            reqBody.Name,
+		Type:            reqBody.Type,
+		URL:             reqBody.URL,
+		Headers:         reqBody.Headers,
+		Command:         reqBody.Command,
+		Args:            reqBody.Args,
+		OAuth:           reqBody.OAuth,
+		MCPServerLimits: reqBody.MCPServerLimits,
 	}
 	if err := AddMCPServerToConfig(persist); err != nil {
 		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "Failed to save config: "+err.Error())
 		return
 	}
+	config, _ := persist.clientConfig()
 
 	// Try to connect to the server (non-blocking - failures don't prevent adding)
 	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
@@ -521,6 +550,10 @@ type MCPUpdateRequest struct {
 	Headers map[string]string `json:"headers,omitempty"`
 	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
+	OAuth   *oauth.Config     `json:"oauth,omitempty"`
+	// Limits replaces the server's tool filters, timeout and cache settings
+	// when set.
+	Limits *MCPServerLimits `json:"limits,omitempty"`
 }
 
 // HandleUpdateMCPServer updates an existing MCP server configuration.
@@ -576,6 +609,12 @@ func (r *Router) HandleUpdateMCPServer(w http.ResponseWriter, req *http.Request)
 	if reqBody.Args != nil {
 		existing.Args = reqBody.Args
 	}
+	if reqBody.OAuth != nil {
+		existing.OAuth = reqBody.OAuth
+	}
+	if reqBody.Limits != nil {
+		existing.MCPServerLimits = *reqBody.Limits
+	}
 
 	// Save updated config
 	if err := saveMCPServersConfig(servers); err != nil {
@@ -584,19 +623,7 @@ func (r *Router) HandleUpdateMCPServer(w http.ResponseWriter, req *http.Request)
 	}
 
 	// Reconnect the server with new config
-	config := client.ClientConfig{
-		Command: name,
-	}
-	switch existing.Type {
-	case "http":
-		config.TransportType = transport.TransportHTTP
-		config.URL = existing.URL
-		config.Headers = existing.Headers
-	case "stdio":
-		config.TransportType = transport.TransportStdio
-		config.Command = existing.Command
-		config.Args = existing.Args
-	}
+	config, _ := existing.clientConfig()
 
 	// First disconnect if connected
 	_ = r.mcpClient.Disconnect(name)
@@ -660,28 +687,18 @@ func (r *Router) HandleImportMCPServers(w http.ResponseWriter, req *http.Request
 	errors := make([]string, 0)
 
 	for name, serverConfig := range importReq {
-		config := client.ClientConfig{
-			Command: name,
-		}
-
 		switch serverConfig.Type {
 		case "http":
 			if serverConfig.URL == "" {
 				errors = append(errors, name+": url is required for http type")
 				continue
 			}
-			config.TransportType = transport.TransportHTTP
-			config.URL = serverConfig.URL
-			config.Headers = serverConfig.Headers
 
 		case "stdio":
 			if serverConfig.Command == "" {
 				errors = append(errors, name+": command is required for stdio type")
 				continue
 			}
-			config.TransportType = transport.TransportStdio
-			config.Command = serverConfig.Command
-			config.Args = serverConfig.Args
 
 		default:
 			errors = append(errors, name+": type must be 'http' or 'stdio'")
@@ -690,17 +707,20 @@ func (r *Router) HandleImportMCPServers(w http.ResponseWriter, req *http.Request
 
 		// Persist config first (so it can be reconnected later even if connection fails now)
 		persist := MCPServerPersist{
-			Name:    name,
-			Type:    serverConfig.Type,
-			URL:     serverConfig.URL,
-			Headers: serverConfig.Headers,
-			Command: serverConfig.Command,
-			Args:    serverConfig.Args,
+			Name:            name,
+			Type:            serverConfig.Type,
+			URL:             serverConfig.URL,
+			Headers:         serverConfig.Headers,
+			Command:         serverConfig.Command,
+			Args:            serverConfig.Args,
+			OAuth:           serverConfig.OAuth,
+			MCPServerLimits: serverConfig.MCPServerLimits,
 		}
 		if err := AddMCPServerToConfig(persist); err != nil {
 			errors = append(errors, name+": failed to save config: "+err.Error())
 			continue
 		}
+		config, _ := persist.clientConfig()
 
 		// Try to connect to the server with a shorter timeout for batch operations
 		ctx, cancel := context.WithTimeout(req.Context(), 10*time.Second)
@@ -864,20 +884,8 @@ func (r *Router) HandleRestartMCPServer(w http.ResponseWriter, req *http.Request
 	}
 
 	// Build client config and connect
-	config := client.ClientConfig{
-		Command: serverConfig.Name, // Command is used as the client name
-	}
-
-	switch serverConfig.Type {
-	case "stdio":
-		config.TransportType = transport.TransportStdio
-		config.Command = serverConfig.Command
-		config.Args = serverConfig.Args
-	case "http":
-		config.TransportType = transport.TransportHTTP
-		config.URL = serverConfig.URL
-		config.Headers = serverConfig.Headers
-	default:
+	config, ok := serverConfig.clientConfig()
+	if !ok {
 		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "Unknown transport type: "+serverConfig.Type)
 		return
 	}
@@ -936,19 +944,27 @@ func (r *Router) HandleGetMCPServer(w http.ResponseWriter, req *http.Request) {
 	}
 
 	config := cli.GetConfig()
-	serverType := "stdio"
-	if config.TransportType == transport.TransportHTTP {
-		serverType = "http"
-	}
-
 	handlers.SendJSON(w, http.StatusOK, MCPServerDetail{
 		Name:        name,
 		Status:      cli.State().String(),
-		Transport:   serverType,
+		Transport:   transportName(string(config.TransportType)),
 		URL:         config.URL,
 		ToolCount:   len(tools),
 		PromptCount: len(prompts),
 		Tools:       toolInfos,
 		Prompts:     promptInfos,
+		Resources:   resourceInfos(name, cli.Resources()),
 	})
 }
+
+// transportName returns the server type shown to users for a transport.
+func transportName(t string) string {
+	switch transport.TransportType(t) {
+	case transport.TransportStdio:
+		return "stdio"
+	case transport.TransportHTTP, transport.TransportStreamableHTTP:
+		return "http"
+	default:
+		return t
+	}
+}
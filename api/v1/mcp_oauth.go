@@ -0,0 +1,153 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"mote/internal/gateway/handlers"
+	"mote/internal/mcp/client"
+	"mote/internal/mcp/transport"
+
+	"github.com/gorilla/mux"
+)
+
+// mcpOAuthCallbackPath is the redirect target registered with authorization servers.
+const mcpOAuthCallbackPath = "/api/v1/mcp/oauth/callback"
+
+// MCPAuthorizeResponse is the response for starting an MCP server authorization.
+type MCPAuthorizeResponse struct {
+	AuthURL string `json:"auth_url"`
+}
+
+// findMCPServerConfig returns the persisted config of the named server.
+func findMCPServerConfig(name string) (*MCPServerPersist, error) {
+	servers, err := loadMCPServersConfig()
+	if err != nil {
+		return nil, err
+	}
+	for i := range servers {
+		if servers[i].Name == name {
+			return &servers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// mcpAuthorized reports whether an OAuth token is stored for the server.
+func (r *Router) mcpAuthorized(cfg MCPServerPersist) bool {
+	if r.mcpAuth == nil || cfg.Type != "http" {
+		return false
+	}
+	_, err := r.mcpAuth.Token(cfg.Name)
+	return err == nil
+}
+
+// HandleAuthorizeMCPServer starts the OAuth flow for an HTTP MCP server and
+// returns the URL the user must open to grant access.
+func (r *Router) HandleAuthorizeMCPServer(w http.ResponseWriter, req *http.Request) {
+	if r.mcpAuth == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "MCP OAuth not initialized")
+		return
+	}
+
+	name := mux.Vars(req)["name"]
+	server, err := findMCPServerConfig(name)
+	if err != nil {
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "Failed to load config: "+err.Error())
+		return
+	}
+	if server == nil {
+		handlers.SendError(w, http.StatusNotFound, handlers.ErrCodeNotFound, "Server not found in config: "+name)
+		return
+	}
+	if server.Type != "http" {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "OAuth is only supported for http servers")
+		return
+	}
+
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	redirectURL := scheme + "://" + req.Host + mcpOAuthCallbackPath
+
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+	authURL, err := r.mcpAuth.Begin(ctx, name, server.URL, server.OAuth, redirectURL)
+	if err != nil {
+		handlers.SendError(w, http.StatusBadGateway, handlers.ErrCodeInternalError, "Failed to start authorization: "+err.Error())
+		return
+	}
+
+	handlers.SendJSON(w, http.StatusOK, MCPAuthorizeResponse{AuthURL: authURL})
+}
+
+// HandleMCPOAuthCallback completes an authorization started by
+// HandleAuthorizeMCPServer and reconnects the server. It is opened in the
+// user's browser, so it answers with a small HTML page.
+func (r *Router) HandleMCPOAuthCallback(w http.ResponseWriter, req *http.Request) {
+	if r.mcpAuth == nil {
+		writeOAuthPage(w, http.StatusServiceUnavailable, "MCP OAuth not initialized")
+		return
+	}
+
+	q := req.URL.Query()
+	if e := q.Get("error"); e != "" {
+		writeOAuthPage(w, http.StatusBadRequest, "Authorization failed: "+e+" "+q.Get("error_description"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+	name, err := r.mcpAuth.Complete(ctx, q.Get("state"), q.Get("code"))
+	if err != nil {
+		writeOAuthPage(w, http.StatusBadRequest, "Authorization failed: "+err.Error())
+		return
+	}
+
+	message := fmt.Sprintf("Authorized %s. You can close this window.", name)
+	if r.mcpClient != nil {
+		if server, _ := findMCPServerConfig(name); server != nil {
+			_ = r.mcpClient.Disconnect(name)
+			cfg := client.ClientConfig{
+				Command:       name,
+				TransportType: transport.TransportStreamableHTTP,
+				URL:           server.URL,
+				Headers:       server.Headers,
+			}
+			if err := r.mcpClient.Connect(ctx, cfg); err != nil {
+				message = fmt.Sprintf("Authorized %s, but reconnecting failed: %v", name, err)
+			}
+		}
+	}
+	writeOAuthPage(w, http.StatusOK, message)
+}
+
+// HandleRevokeMCPServerAuth forgets the OAuth token of an MCP server.
+func (r *Router) HandleRevokeMCPServerAuth(w http.ResponseWriter, req *http.Request) {
+	if r.mcpAuth == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "MCP OAuth not initialized")
+		return
+	}
+
+	name := mux.Vars(req)["name"]
+	if err := r.mcpAuth.Revoke(name); err != nil {
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "Failed to revoke token: "+err.Error())
+		return
+	}
+
+	handlers.SendJSON(w, http.StatusOK, map[string]any{
+		"success": true,
+		"message": "Authorization removed: " + name,
+	})
+}
+
+func writeOAuthPage(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>Mote</title></head>"+
+		"<body style=\"font-family:sans-serif;padding:2em\"><p>%s</p></body></html>", html.EscapeString(message))
+}
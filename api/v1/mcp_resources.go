@@ -0,0 +1,243 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mote/internal/gateway/handlers"
+	"mote/internal/mcp/client"
+	"mote/internal/mcp/protocol"
+	"mote/internal/provider"
+	"mote/pkg/logger"
+)
+
+// MCPResourceInfo represents a resource exposed by an MCP server.
+type MCPResourceInfo struct {
+	Server      string `json:"server"`
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+}
+
+// MCPResourceTemplateInfo represents a resource template exposed by an MCP server.
+type MCPResourceTemplateInfo struct {
+	Server      string `json:"server"`
+	URITemplate string `json:"uri_template"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+}
+
+// MCPResourcesResponse represents the response for listing MCP resources.
+type MCPResourcesResponse struct {
+	Resources []MCPResourceInfo         `json:"resources"`
+	Templates []MCPResourceTemplateInfo `json:"templates"`
+}
+
+// MCPResourceRequest identifies a resource on an MCP server.
+type MCPResourceRequest struct {
+	Server string `json:"server"`
+	URI    string `json:"uri"`
+}
+
+// MCPReadResourceResponse represents the contents of a resource.
+type MCPReadResourceResponse struct {
+	Server   string                      `json:"server"`
+	URI      string                      `json:"uri"`
+	Contents []protocol.ResourceContents `json:"contents"`
+}
+
+// resourceMentionTimeout bounds reading resources referenced in a chat message.
+const resourceMentionTimeout = 15 * time.Second
+
+// HandleListMCPResources lists resources and templates from connected MCP servers.
+func (r *Router) HandleListMCPResources(w http.ResponseWriter, req *http.Request) {
+	resp := MCPResourcesResponse{
+		Resources: []MCPResourceInfo{},
+		Templates: []MCPResourceTemplateInfo{},
+	}
+	if r.mcpClient == nil {
+		handlers.SendJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	serverFilter := req.URL.Query().Get("server")
+	for _, res := range r.mcpClient.GetAllResources() {
+		if serverFilter != "" && res.ServerName != serverFilter {
+			continue
+		}
+		resp.Resources = append(resp.Resources, MCPResourceInfo{
+			Server:      res.ServerName,
+			URI:         res.URI,
+			Name:        res.Name,
+			Description: res.Description,
+			MimeType:    res.MimeType,
+		})
+	}
+	for _, tmpl := range r.mcpClient.GetAllResourceTemplates() {
+		if serverFilter != "" && tmpl.ServerName != serverFilter {
+			continue
+		}
+		resp.Templates = append(resp.Templates, MCPResourceTemplateInfo{
+			Server:      tmpl.ServerName,
+			URITemplate: tmpl.URITemplate,
+			Name:        tmpl.Name,
+			Description: tmpl.Description,
+			MimeType:    tmpl.MimeType,
+		})
+	}
+
+	handlers.SendJSON(w, http.StatusOK, resp)
+}
+
+// decodeResourceRequest parses and validates a MCPResourceRequest body.
+func (r *Router) decodeResourceRequest(w http.ResponseWriter, req *http.Request) (MCPResourceRequest, bool) {
+	var body MCPResourceRequest
+	if r.mcpClient == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "MCP manager not initialized")
+		return body, false
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "Invalid request body")
+		return body, false
+	}
+	if body.Server == "" || body.URI == "" {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "server and uri are required")
+		return body, false
+	}
+	if _, ok := r.mcpClient.GetClient(body.Server); !ok {
+		handlers.SendError(w, http.StatusNotFound, handlers.ErrCodeNotFound, fmt.Sprintf("MCP server '%s' not found", body.Server))
+		return body, false
+	}
+	return body, true
+}
+
+// HandleReadMCPResource reads a resource from an MCP server.
+func (r *Router) HandleReadMCPResource(w http.ResponseWriter, req *http.Request) {
+	body, ok := r.decodeResourceRequest(w, req)
+	if !ok {
+		return
+	}
+
+	result, err := r.mcpClient.ReadResource(req.Context(), body.Server, body.URI)
+	if err != nil {
+		handlers.SendError(w, http.StatusBadGateway, handlers.ErrCodeInternalError, err.Error())
+		return
+	}
+
+	handlers.SendJSON(w, http.StatusOK, MCPReadResourceResponse{
+		Server:   body.Server,
+		URI:      body.URI,
+		Contents: result.Contents,
+	})
+}
+
+// HandleSubscribeMCPResource subscribes to updates of a resource. Updates are
+// pushed to WebSocket clients as mcp_resource_updated messages.
+func (r *Router) HandleSubscribeMCPResource(w http.ResponseWriter, req *http.Request) {
+	body, ok := r.decodeResourceRequest(w, req)
+	if !ok {
+		return
+	}
+	if err := r.mcpClient.Subscribe(req.Context(), body.Server, body.URI); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	handlers.SendJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Subscribed"})
+}
+
+// HandleUnsubscribeMCPResource cancels a resource subscription.
+func (r *Router) HandleUnsubscribeMCPResource(w http.ResponseWriter, req *http.Request) {
+	body, ok := r.decodeResourceRequest(w, req)
+	if !ok {
+		return
+	}
+	if err := r.mcpClient.Unsubscribe(req.Context(), body.Server, body.URI); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	handlers.SendJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Unsubscribed"})
+}
+
+// resourceInfos converts a server's resources to API info.
+func resourceInfos(server string, resources []protocol.Resource) []MCPResourceInfo {
+	infos := make([]MCPResourceInfo, 0, len(resources))
+	for _, res := range resources {
+		infos = append(infos, MCPResourceInfo{
+			Server:      server,
+			URI:         res.URI,
+			Name:        res.Name,
+			Description: res.Description,
+			MimeType:    res.MimeType,
+		})
+	}
+	return infos
+}
+
+// BuildResourceAttachments reads the "@server:uri" resources mentioned in
+// message and returns them as attachments. Resources that cannot be read are
+// logged and skipped. Servers that support it are subscribed to, so clients
+// are told when an attached resource changes.
+func BuildResourceAttachments(ctx context.Context, mgr *client.Manager, message string) []provider.Attachment {
+	if mgr == nil || !strings.Contains(message, "@") {
+		return nil
+	}
+
+	mentions := mgr.ResolveMentions(message)
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resourceMentionTimeout)
+	defer cancel()
+
+	var attachments []provider.Attachment
+	for _, m := range mentions {
+		result, err := mgr.ReadResource(ctx, m.Server, m.URI)
+		if err != nil {
+			logger.Warn().Err(err).Str("server", m.Server).Str("uri", m.URI).Msg("Failed to read mentioned MCP resource")
+			continue
+		}
+		for _, c := range result.Contents {
+			attachments = append(attachments, resourceAttachment(m.Server, c))
+		}
+		if c, ok := mgr.GetClient(m.Server); ok && c.SupportsSubscribe() {
+			if err := c.Subscribe(ctx, m.URI); err != nil {
+				logger.Debug().Err(err).Str("server", m.Server).Str("uri", m.URI).Msg("Failed to subscribe to MCP resource")
+			}
+		}
+	}
+	return attachments
+}
+
+// resourceAttachment converts resource contents to a provider attachment.
+func resourceAttachment(server string, c protocol.ResourceContents) provider.Attachment {
+	att := provider.Attachment{
+		Filename: c.URI,
+		MimeType: c.MimeType,
+		Metadata: map[string]any{
+			"mcp_server": server,
+			"uri":        c.URI,
+			"language":   detectLanguage(c.URI),
+		},
+	}
+	switch {
+	case c.Blob != "" && strings.HasPrefix(c.MimeType, "image/"):
+		att.Type = "image_url"
+		att.ImageURL = &provider.ImageURL{URL: fmt.Sprintf("data:%s;base64,%s", c.MimeType, c.Blob)}
+		att.Size = len(c.Blob) * 3 / 4
+	case c.Blob != "":
+		att.Type = "text"
+		att.Text = fmt.Sprintf("[binary resource %s (%s) omitted]", c.URI, c.MimeType)
+	default:
+		att.Type = "text"
+		att.Text = c.Text
+		att.Size = len(c.Text)
+	}
+	return att
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -192,3 +193,27 @@ func TestMCPToolsResponse_EmptyTools(t *testing.T) {
 		t.Errorf("Expected 0 tools, got %d", len(result.Tools))
 	}
 }
+
+func TestMCPServerPersist_ClientConfig(t *testing.T) {
+	var p MCPServerPersist
+	data := `{"name":"fs","type":"stdio","command":"mcp-fs","include_tools":["read_*"],"exclude_tools":["read_secret"],"timeout":10,"cache_ttl":60}`
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, ok := p.clientConfig()
+	if !ok {
+		t.Fatal("stdio server rejected")
+	}
+	if cfg.Command != "mcp-fs" || len(cfg.IncludeTools) != 1 || len(cfg.ExcludeTools) != 1 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.CallTimeout != 10*time.Second || cfg.CacheTTL != time.Minute {
+		t.Errorf("timeout = %v, cache ttl = %v", cfg.CallTimeout, cfg.CacheTTL)
+	}
+
+	p.Type = "ftp"
+	if _, ok := p.clientConfig(); ok {
+		t.Error("unknown type accepted")
+	}
+}
@@ -187,6 +187,7 @@ func (r *Router) handlePDAResume(w http.ResponseWriter, req *http.Request, sessi
 						ToolName:   event.ToolCallUpdate.ToolName,
 						Status:     event.ToolCallUpdate.Status,
 						Arguments:  event.ToolCallUpdate.Arguments,
+						Message:    event.ToolCallUpdate.Message,
 					},
 				}
 			} else {
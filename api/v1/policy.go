@@ -2,7 +2,12 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -19,14 +24,23 @@ type PolicyStatusResponse struct {
 	AllowlistCount      int    `json:"allowlist_count"`
 	DangerousRulesCount int    `json:"dangerous_rules_count"`
 	ParamRulesCount     int    `json:"param_rules_count"`
+	RulesCount          int    `json:"rules_count"`
+	OverlaysCount       int    `json:"overlays_count"`
 	Message             string `json:"message,omitempty"`
 }
 
 // PolicyCheckRequest represents a request to check a tool call.
+// The check is a dry run: the call is not counted or logged.
 type PolicyCheckRequest struct {
-	Tool      string `json:"tool"`
-	Arguments string `json:"arguments"`
-	SessionID string `json:"session_id,omitempty"`
+	Tool          string `json:"tool"`
+	Arguments     string `json:"arguments"`
+	SessionID     string `json:"session_id,omitempty"`
+	AgentID       string `json:"agent_id,omitempty"`
+	WorkspacePath string `json:"workspace_path,omitempty"`
+
+	// Context sets the time and call counts the call is evaluated with;
+	// defaults to now with no earlier calls.
+	Context *policy.EvalContext `json:"context,omitempty"`
 }
 
 // PolicyCheckResponse represents the result of a policy check.
@@ -36,7 +50,15 @@ type PolicyCheckResponse struct {
 	RequireApproval bool     `json:"require_approval"`
 	Blocked         bool     `json:"blocked"`
 	Reason          string   `json:"reason"`
+	ApprovalReason  string   `json:"approval_reason,omitempty"`
 	Warnings        []string `json:"warnings,omitempty"`
+	MatchedRules    []string `json:"matched_rules,omitempty"`
+}
+
+// PolicyDecisionsResponse represents the recent policy decisions.
+type PolicyDecisionsResponse struct {
+	Decisions []policy.Decision `json:"decisions"`
+	Count     int               `json:"count"`
 }
 
 // ApprovalListResponse represents the list of pending approvals.
@@ -50,14 +72,28 @@ type ApprovalRespondRequest struct {
 	Approved          bool   `json:"approved"`
 	Reason            string `json:"reason,omitempty"`
 	ModifiedArguments string `json:"modified_arguments,omitempty"`
+
+	// Scope remembers an approval: once (default), session or workspace.
+	Scope string `json:"scope,omitempty"`
+	// Pattern is a regex over the canonical arguments; empty matches them exactly.
+	Pattern string `json:"pattern,omitempty"`
+	// TTL overrides the rule's default lifetime, e.g. "8h".
+	TTL string `json:"ttl,omitempty"`
 }
 
 // ApprovalRespondResponse represents the response to an approval action.
 type ApprovalRespondResponse struct {
-	Success   bool   `json:"success"`
-	RequestID string `json:"request_id"`
-	Approved  bool   `json:"approved"`
-	Message   string `json:"message"`
+	Success   bool           `json:"success"`
+	RequestID string         `json:"request_id"`
+	Approved  bool           `json:"approved"`
+	Message   string         `json:"message"`
+	Rule      *approval.Rule `json:"rule,omitempty"`
+}
+
+// ApprovalRulesResponse represents the list of remembered approval rules.
+type ApprovalRulesResponse struct {
+	Rules []*approval.Rule `json:"rules"`
+	Count int              `json:"count"`
 }
 
 // HandlePolicyStatus returns the current policy status.
@@ -83,6 +119,8 @@ func (r *Router) HandlePolicyStatus(w http.ResponseWriter, req *http.Request) {
 		AllowlistCount:      status.AllowlistCount,
 		DangerousRulesCount: status.DangerousRulesCount,
 		ParamRulesCount:     status.ParamRulesCount,
+		RulesCount:          status.RulesCount,
+		OverlaysCount:       status.OverlaysCount,
 	})
 }
 
@@ -120,12 +158,18 @@ func (r *Router) HandlePolicyCheck(w http.ResponseWriter, req *http.Request) {
 	}
 
 	call := &policy.ToolCall{
-		Name:      checkReq.Tool,
-		Arguments: checkReq.Arguments,
-		SessionID: checkReq.SessionID,
+		Name:          checkReq.Tool,
+		Arguments:     checkReq.Arguments,
+		SessionID:     checkReq.SessionID,
+		AgentID:       checkReq.AgentID,
+		WorkspacePath: checkReq.WorkspacePath,
+	}
+	ec := policy.EvalContext{Time: time.Now()}
+	if checkReq.Context != nil {
+		ec = *checkReq.Context
 	}
 
-	result, err := r.policyExecutor.Check(req.Context(), call)
+	result, err := r.policyExecutor.Evaluate(req.Context(), call, ec)
 	if err != nil {
 		handlers.SendError(w, http.StatusInternalServerError, ErrCodeInternalError, "Policy check failed: "+err.Error())
 		return
@@ -137,7 +181,31 @@ func (r *Router) HandlePolicyCheck(w http.ResponseWriter, req *http.Request) {
 		RequireApproval: result.RequireApproval,
 		Blocked:         !result.Allowed && !result.RequireApproval,
 		Reason:          result.Reason,
+		ApprovalReason:  result.ApprovalReason,
 		Warnings:        result.Warnings,
+		MatchedRules:    result.MatchedRules,
+	})
+}
+
+// HandlePolicyDecisions returns the most recent policy decisions.
+func (r *Router) HandlePolicyDecisions(w http.ResponseWriter, req *http.Request) {
+	limit := 100
+	if v := req.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			handlers.SendError(w, http.StatusBadRequest, ErrCodeValidationFailed, "Invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	decisions := []policy.Decision{}
+	if r.policyExecutor != nil {
+		decisions = r.policyExecutor.RecentDecisions(limit)
+	}
+	handlers.SendJSON(w, http.StatusOK, PolicyDecisionsResponse{
+		Decisions: decisions,
+		Count:     len(decisions),
 	})
 }
 
@@ -174,16 +242,37 @@ func (r *Router) HandleApprovalRespond(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	var ttl time.Duration
+	if respondReq.TTL != "" {
+		d, err := time.ParseDuration(respondReq.TTL)
+		if err != nil || d < 0 {
+			handlers.SendError(w, http.StatusBadRequest, ErrCodeValidationFailed, "Invalid ttl: "+respondReq.TTL)
+			return
+		}
+		ttl = d
+	}
+
 	if r.approvalManager == nil {
 		handlers.SendError(w, http.StatusServiceUnavailable, ErrCodeServiceUnavailable, "Approval manager not initialized")
 		return
 	}
 
-	err := r.approvalManager.HandleResponse(requestID, respondReq.Approved, respondReq.Reason, respondReq.ModifiedArguments)
-	if err != nil {
+	rule, err := r.approvalManager.Respond(requestID, approval.Response{
+		Approved:          respondReq.Approved,
+		Message:           respondReq.Reason,
+		ModifiedArguments: respondReq.ModifiedArguments,
+		Scope:             approval.RuleScope(respondReq.Scope),
+		Pattern:           respondReq.Pattern,
+		TTL:               ttl,
+	})
+	if errors.Is(err, policy.ErrRequestNotFound) {
 		handlers.SendError(w, http.StatusNotFound, ErrCodeNotFound, "Approval request not found or already processed")
 		return
 	}
+	if err != nil {
+		handlers.SendError(w, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+		return
+	}
 
 	message := "Request denied"
 	if respondReq.Approved {
@@ -195,9 +284,112 @@ func (r *Router) HandleApprovalRespond(w http.ResponseWriter, req *http.Request)
 		RequestID: requestID,
 		Approved:  respondReq.Approved,
 		Message:   message,
+		Rule:      rule,
+	})
+}
+
+// HandleListApprovalRules lists the remembered approval rules.
+func (r *Router) HandleListApprovalRules(w http.ResponseWriter, req *http.Request) {
+	rules := []*approval.Rule{}
+	if r.approvalManager != nil {
+		if set := r.approvalManager.Rules(); set != nil {
+			rules = set.List()
+		}
+	}
+	handlers.SendJSON(w, http.StatusOK, ApprovalRulesResponse{
+		Rules: rules,
+		Count: len(rules),
+	})
+}
+
+// HandleRevokeApprovalRule removes a remembered approval rule.
+func (r *Router) HandleRevokeApprovalRule(w http.ResponseWriter, req *http.Request) {
+	ruleID := mux.Vars(req)["id"]
+
+	if r.approvalManager == nil || r.approvalManager.Rules() == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, ErrCodeServiceUnavailable, "Approval rules not initialized")
+		return
+	}
+	if err := r.approvalManager.Rules().Revoke(ruleID); err != nil {
+		handlers.SendError(w, http.StatusNotFound, ErrCodeNotFound, "Approval rule not found")
+		return
+	}
+
+	handlers.SendJSON(w, http.StatusOK, map[string]any{
+		"success": true,
+		"message": "Approval rule revoked",
 	})
 }
 
+// HandleApprovalDecide resolves an approval request from a signed link sent
+// by an out-of-band notifier. GET shows a confirmation page, so link
+// previews and prefetchers cannot decide; POST (the page's button or a push
+// notification action) applies the decision.
+func (r *Router) HandleApprovalDecide(w http.ResponseWriter, req *http.Request) {
+	requestID := mux.Vars(req)["id"]
+	q := req.URL.Query()
+	action := q.Get("action")
+
+	var links *approval.LinkSigner
+	if r.approvalManager != nil {
+		links = r.approvalManager.Links()
+	}
+	if links == nil {
+		writeApprovalPage(w, http.StatusServiceUnavailable, "Approval links are not enabled.", "")
+		return
+	}
+	if err := links.Verify(requestID, action, q.Get("exp"), q.Get("sig")); err != nil {
+		status := http.StatusForbidden
+		message := "This approval link is invalid."
+		if errors.Is(err, approval.ErrLinkExpired) {
+			status = http.StatusGone
+			message = "This approval link has expired."
+		}
+		writeApprovalPage(w, status, message, "")
+		return
+	}
+
+	pending, ok := r.approvalManager.GetPending(requestID)
+	if !ok {
+		writeApprovalPage(w, http.StatusNotFound, "Approval request not found or already processed.", "")
+		return
+	}
+
+	approved := action == approval.ActionApprove
+	verb := "Reject"
+	if approved {
+		verb = "Approve"
+	}
+	if req.Method == http.MethodGet {
+		writeApprovalPage(w, http.StatusOK, approval.FormatRequestText(pending), verb)
+		return
+	}
+
+	if err := r.approvalManager.HandleResponseFrom(requestID, approved, "", "link"); err != nil {
+		writeApprovalPage(w, http.StatusNotFound, "Approval request not found or already processed.", "")
+		return
+	}
+	message := "Request denied"
+	if approved {
+		message = "Request approved"
+	}
+	writeApprovalPage(w, http.StatusOK, fmt.Sprintf("%s: %s (%s).", message, pending.ToolName, approval.ShortID(requestID)), "")
+}
+
+// writeApprovalPage renders a minimal page; with a verb it adds a button
+// that POSTs the same signed link.
+func writeApprovalPage(w http.ResponseWriter, status int, message, verb string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	form := ""
+	if verb != "" {
+		form = fmt.Sprintf("<form method=\"post\"><button type=\"submit\">%s</button></form>", html.EscapeString(verb))
+	}
+	fmt.Fprintf(w, "<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>Mote</title></head>"+
+		"<body style=\"font-family:sans-serif;padding:2em\"><pre style=\"white-space:pre-wrap\">%s</pre>%s</body></html>",
+		html.EscapeString(message), form)
+}
+
 // HandleGetPolicyConfig returns the full policy configuration.
 func (r *Router) HandleGetPolicyConfig(w http.ResponseWriter, req *http.Request) {
 	if r.policyExecutor == nil {
@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"mote/internal/gateway/handlers"
+	"mote/internal/procmgr"
+)
+
+// ProcessOutputResponse is returned by GET /api/v1/processes/{id}/output.
+type ProcessOutputResponse struct {
+	Process procmgr.ProcessInfo `json:"process"`
+	Output  string              `json:"output"`
+	// Offset is where the next incremental read should start.
+	Offset int64 `json:"offset"`
+}
+
+// ProcessInputRequest is the body of POST /api/v1/processes/{id}/input.
+type ProcessInputRequest struct {
+	Input string `json:"input"`
+}
+
+// cleanupSessionProcesses stops background processes owned by deleted sessions.
+func (r *Router) cleanupSessionProcesses(sessionIDs ...string) {
+	if r.processManager == nil {
+		return
+	}
+	for _, id := range sessionIDs {
+		go r.processManager.CleanupSession(id)
+	}
+}
+
+func (r *Router) lookupProcess(w http.ResponseWriter, req *http.Request) (*procmgr.BackgroundProcess, bool) {
+	if r.processManager == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "process manager not available")
+		return nil, false
+	}
+	id := mux.Vars(req)["id"]
+	proc, ok := r.processManager.Get(id)
+	if !ok {
+		handlers.SendError(w, http.StatusNotFound, "NOT_FOUND", "process not found: "+id)
+		return nil, false
+	}
+	return proc, true
+}
+
+// HandleListProcesses lists background processes, optionally for one session (?session=).
+func (r *Router) HandleListProcesses(w http.ResponseWriter, req *http.Request) {
+	if r.processManager == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "process manager not available")
+		return
+	}
+	processes := r.processManager.List(req.URL.Query().Get("session"))
+	handlers.SendJSON(w, http.StatusOK, map[string]any{"processes": processes})
+}
+
+// HandleGetProcess returns one background process.
+func (r *Router) HandleGetProcess(w http.ResponseWriter, req *http.Request) {
+	proc, ok := r.lookupProcess(w, req)
+	if !ok {
+		return
+	}
+	handlers.SendJSON(w, http.StatusOK, proc.Info())
+}
+
+// HandleGetProcessOutput returns process output from ?offset= (default 0).
+// Reading does not affect what the agent's own polls return.
+func (r *Router) HandleGetProcessOutput(w http.ResponseWriter, req *http.Request) {
+	proc, ok := r.lookupProcess(w, req)
+	if !ok {
+		return
+	}
+	var offset int64
+	if o := req.URL.Query().Get("offset"); o != "" {
+		n, err := strconv.ParseInt(o, 10, 64)
+		if err != nil || n < 0 {
+			handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid offset")
+			return
+		}
+		offset = n
+	}
+	output, next := proc.Read(offset)
+	handlers.SendJSON(w, http.StatusOK, ProcessOutputResponse{Process: proc.Info(), Output: output, Offset: next})
+}
+
+// HandleWriteProcessInput sends input to a running process.
+func (r *Router) HandleWriteProcessInput(w http.ResponseWriter, req *http.Request) {
+	proc, ok := r.lookupProcess(w, req)
+	if !ok {
+		return
+	}
+	var body ProcessInputRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "invalid request body")
+		return
+	}
+	if err := proc.Write(body.Input); err != nil {
+		handlers.SendError(w, http.StatusConflict, handlers.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	handlers.SendJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Input sent"})
+}
+
+// HandleKillProcess stops a background process and forgets it.
+func (r *Router) HandleKillProcess(w http.ResponseWriter, req *http.Request) {
+	if r.processManager == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "process manager not available")
+		return
+	}
+	id := mux.Vars(req)["id"]
+	if err := r.processManager.Remove(id); err != nil {
+		if errors.Is(err, procmgr.ErrProcessNotFound) {
+			handlers.SendError(w, http.StatusNotFound, "NOT_FOUND", "process not found: "+id)
+			return
+		}
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, err.Error())
+		return
+	}
+	handlers.SendJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Process stopped"})
+}
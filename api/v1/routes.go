@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -21,10 +22,12 @@ import (
 	"mote/internal/cron"
 	"mote/internal/gateway/handlers"
 	"mote/internal/mcp/client"
+	"mote/internal/mcp/oauth"
 	"mote/internal/mcp/server"
 	"mote/internal/memory"
 	"mote/internal/policy"
 	"mote/internal/policy/approval"
+	"mote/internal/procmgr"
 	"mote/internal/prompts"
 	"mote/internal/provider"
 	"mote/internal/provider/copilot"
@@ -89,6 +92,9 @@ type Router struct {
 	versionChecker   interface{} // *skills.VersionChecker
 	skillUpdater     interface{} // *skills.SkillUpdater
 	delegateTracker  *delegate.DelegationTracker
+	processManager   *procmgr.BackgroundManager
+	mcpAuth          *oauth.Authenticator
+	evalCancels      sync.Map // eval run ID -> context.CancelFunc
 }
 
 // NewRouter creates a new v1 API router.
@@ -184,6 +190,16 @@ func (r *Router) SetDelegateTracker(t *delegate.DelegationTracker) {
 	r.delegateTracker = t
 }
 
+// SetProcessManager sets the background process manager.
+func (r *Router) SetProcessManager(m *procmgr.BackgroundManager) {
+	r.processManager = m
+}
+
+// SetMCPAuthenticator sets the OAuth authenticator for remote MCP servers.
+func (r *Router) SetMCPAuthenticator(a *oauth.Authenticator) {
+	r.mcpAuth = a
+}
+
 // RegisterRoutes registers all v1 API routes.
 func (r *Router) RegisterRoutes(router *mux.Router) {
 	v1 := router.PathPrefix("/api/v1").Subrouter()
@@ -219,6 +235,9 @@ func (r *Router) RegisterRoutes(router *mux.Router) {
 	v1.HandleFunc("/sessions/{id}/skills", r.HandleUpdateSessionSkills).Methods(http.MethodPut)
 	v1.HandleFunc("/sessions/{id}/reconfigure", r.HandleReconfigureSession).Methods(http.MethodPost)
 	v1.HandleFunc("/sessions/{id}/pda", r.HandlePDAControl).Methods(http.MethodPost)
+	v1.HandleFunc("/sessions/{id}/fork", r.HandleForkSession).Methods(http.MethodPost)
+	v1.HandleFunc("/sessions/{id}/branches", r.HandleGetSessionBranches).Methods(http.MethodGet)
+	v1.HandleFunc("/sessions/{id}/messages/{messageId}/edit", r.HandleEditMessage).Methods(http.MethodPost)
 
 	// Tools
 	v1.HandleFunc("/tools", r.HandleListTools).Methods(http.MethodGet)
@@ -277,9 +296,16 @@ func (r *Router) RegisterRoutes(router *mux.Router) {
 	v1.HandleFunc("/mcp/servers/{name}", r.HandleDeleteMCPServer).Methods(http.MethodDelete)
 	v1.HandleFunc("/mcp/servers/{name}/stop", r.HandleStopMCPServer).Methods(http.MethodPost)
 	v1.HandleFunc("/mcp/servers/{name}/restart", r.HandleRestartMCPServer).Methods(http.MethodPost)
+	v1.HandleFunc("/mcp/servers/{name}/oauth/authorize", r.HandleAuthorizeMCPServer).Methods(http.MethodPost)
+	v1.HandleFunc("/mcp/servers/{name}/oauth", r.HandleRevokeMCPServerAuth).Methods(http.MethodDelete)
+	v1.HandleFunc("/mcp/oauth/callback", r.HandleMCPOAuthCallback).Methods(http.MethodGet)
 	v1.HandleFunc("/mcp/tools", r.HandleListMCPTools).Methods(http.MethodGet)
 	v1.HandleFunc("/mcp/prompts", r.HandleListMCPPrompts).Methods(http.MethodGet)
 	v1.HandleFunc("/mcp/prompts/{server}/{name}", r.HandleGetMCPPrompt).Methods(http.MethodPost)
+	v1.HandleFunc("/mcp/resources", r.HandleListMCPResources).Methods(http.MethodGet)
+	v1.HandleFunc("/mcp/resources/read", r.HandleReadMCPResource).Methods(http.MethodPost)
+	v1.HandleFunc("/mcp/resources/subscribe", r.HandleSubscribeMCPResource).Methods(http.MethodPost)
+	v1.HandleFunc("/mcp/resources/unsubscribe", r.HandleUnsubscribeMCPResource).Methods(http.MethodPost)
 
 	// UI
 	v1.HandleFunc("/ui/components", r.HandleUIComponents).Methods(http.MethodGet)
@@ -289,12 +315,16 @@ func (r *Router) RegisterRoutes(router *mux.Router) {
 	// Policy (M08)
 	v1.HandleFunc("/policy/status", r.HandlePolicyStatus).Methods(http.MethodGet)
 	v1.HandleFunc("/policy/check", r.HandlePolicyCheck).Methods(http.MethodPost)
+	v1.HandleFunc("/policy/decisions", r.HandlePolicyDecisions).Methods(http.MethodGet)
 	v1.HandleFunc("/policy/config", r.HandleGetPolicyConfig).Methods(http.MethodGet)
 	v1.HandleFunc("/policy/config", r.HandleUpdatePolicyConfig).Methods(http.MethodPut)
 
 	// Approvals (M08)
 	v1.HandleFunc("/approvals", r.HandleApprovalList).Methods(http.MethodGet)
+	v1.HandleFunc("/approvals/rules", r.HandleListApprovalRules).Methods(http.MethodGet)
+	v1.HandleFunc("/approvals/rules/{id}", r.HandleRevokeApprovalRule).Methods(http.MethodDelete)
 	v1.HandleFunc("/approvals/{id}/respond", r.HandleApprovalRespond).Methods(http.MethodPost)
+	v1.HandleFunc("/approvals/{id}/decide", r.HandleApprovalDecide).Methods(http.MethodGet, http.MethodPost)
 
 	// Channels
 	v1.HandleFunc("/channels", r.HandleListChannels).Methods(http.MethodGet)
@@ -302,6 +332,7 @@ func (r *Router) RegisterRoutes(router *mux.Router) {
 	v1.HandleFunc("/channels/{type}/config", r.HandleUpdateChannelConfig).Methods(http.MethodPut)
 	v1.HandleFunc("/channels/{type}/start", r.HandleStartChannel).Methods(http.MethodPost)
 	v1.HandleFunc("/channels/{type}/stop", r.HandleStopChannel).Methods(http.MethodPost)
+	v1.HandleFunc("/channels/webhook/{name}", r.HandleChannelWebhook).Methods(http.MethodPost)
 
 	// Workspace
 	v1.HandleFunc("/workspaces", r.HandleListWorkspaces).Methods(http.MethodGet)
@@ -341,8 +372,32 @@ func (r *Router) RegisterRoutes(router *mux.Router) {
 	v1.HandleFunc("/delegations", r.HandleListRecentDelegations).Methods(http.MethodGet)
 	v1.HandleFunc("/delegations/batch-delete", r.HandleBatchDeleteDelegations).Methods(http.MethodPost)
 	v1.HandleFunc("/sessions/{id}/delegations", r.HandleListDelegations).Methods(http.MethodGet)
+	v1.HandleFunc("/sessions/{id}/trace", r.HandleGetSessionTrace).Methods(http.MethodGet)
 	v1.HandleFunc("/delegations/{id}", r.HandleGetDelegation).Methods(http.MethodGet)
 
+	// Evals (agent golden test suites)
+	v1.HandleFunc("/eval/runs", r.HandleListEvalRuns).Methods(http.MethodGet)
+	v1.HandleFunc("/eval/runs", r.HandleRunEval).Methods(http.MethodPost)
+	v1.HandleFunc("/eval/compare", r.HandleCompareEvalRuns).Methods(http.MethodGet)
+	v1.HandleFunc("/eval/runs/{id}", r.HandleGetEvalRun).Methods(http.MethodGet)
+	v1.HandleFunc("/eval/runs/{id}", r.HandleDeleteEvalRun).Methods(http.MethodDelete)
+	v1.HandleFunc("/eval/runs/{id}/cancel", r.HandleCancelEvalRun).Methods(http.MethodPost)
+
+	// Background processes (started by agents)
+	v1.HandleFunc("/processes", r.HandleListProcesses).Methods(http.MethodGet)
+	v1.HandleFunc("/processes/{id}", r.HandleGetProcess).Methods(http.MethodGet)
+	v1.HandleFunc("/processes/{id}/output", r.HandleGetProcessOutput).Methods(http.MethodGet)
+	v1.HandleFunc("/processes/{id}/input", r.HandleWriteProcessInput).Methods(http.MethodPost)
+	v1.HandleFunc("/processes/{id}", r.HandleKillProcess).Methods(http.MethodDelete)
+
+	// Transcript search
+	v1.HandleFunc("/search", r.HandleSearch).Methods(http.MethodGet)
+
+	// Usage ledger and budgets
+	v1.HandleFunc("/usage", r.HandleUsageReport).Methods(http.MethodGet)
+	v1.HandleFunc("/usage/entries", r.HandleUsageEntries).Methods(http.MethodGet)
+	v1.HandleFunc("/usage/budgets", r.HandleUsageBudgets).Methods(http.MethodGet)
+
 	// Agents (multi-agent CRUD)
 	v1.HandleFunc("/agents", r.HandleListAgents).Methods(http.MethodGet)
 	v1.HandleFunc("/agents", r.HandleAddAgent).Methods(http.MethodPost)
@@ -647,6 +702,7 @@ func (r *Router) HandleDeleteSession(w http.ResponseWriter, req *http.Request) {
 		handlers.SendError(w, http.StatusNotFound, handlers.ErrCodeNotFound, "Session not found")
 		return
 	}
+	r.cleanupSessionProcesses(id)
 
 	handlers.SendJSON(w, http.StatusOK, SuccessResponse{Success: true, Message: "Session deleted"})
 }
@@ -693,6 +749,7 @@ func (r *Router) HandleBatchDeleteSessions(w http.ResponseWriter, req *http.Requ
 		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "Failed to delete sessions")
 		return
 	}
+	r.cleanupSessionProcesses(body.IDs...)
 
 	deleted, _ := result.RowsAffected()
 	handlers.SendJSON(w, http.StatusOK, map[string]interface{}{
@@ -2605,6 +2662,42 @@ func (r *Router) HandleGetDelegation(w http.ResponseWriter, req *http.Request) {
 	handlers.SendJSON(w, http.StatusOK, record)
 }
 
+// HandleGetSessionTrace returns the execution tree of a session: sub-agents,
+// PDA steps and tool calls with per-node tokens, model and latency.
+// With ?format=otel the trace is returned as OTLP/JSON.
+func (r *Router) HandleGetSessionTrace(w http.ResponseWriter, req *http.Request) {
+	if r.delegateTracker == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "delegation tracking not enabled")
+		return
+	}
+
+	sessionID := mux.Vars(req)["id"]
+	if sessionID == "" {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "session ID required")
+		return
+	}
+
+	trace, err := r.delegateTracker.BuildTrace(sessionID)
+	if err != nil {
+		log.Error().Err(err).Str("sessionID", sessionID).Msg("Failed to build session trace")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to build trace")
+		return
+	}
+	if trace == nil {
+		handlers.SendError(w, http.StatusNotFound, "NOT_FOUND", "session not found")
+		return
+	}
+
+	switch req.URL.Query().Get("format") {
+	case "", "tree":
+		handlers.SendJSON(w, http.StatusOK, trace)
+	case "otel", "otlp":
+		handlers.SendJSON(w, http.StatusOK, delegate.ExportOTLP(trace))
+	default:
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "unsupported format: "+req.URL.Query().Get("format"))
+	}
+}
+
 // HandleBatchDeleteDelegations deletes multiple delegation records at once.
 func (r *Router) HandleBatchDeleteDelegations(w http.ResponseWriter, req *http.Request) {
 	if r.delegateTracker == nil {
@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mote/internal/gateway/handlers"
+	"mote/internal/storage"
+	"mote/internal/usage"
+)
+
+// SearchResult is a message matching a transcript search.
+type SearchResult struct {
+	*storage.MessageSearchResult
+	// Link points at the message within its session transcript.
+	Link string `json:"link"`
+}
+
+// SearchResponse is returned by GET /api/v1/search.
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+}
+
+// HandleSearch searches message content, tool call arguments and tool results
+// across all session transcripts.
+//
+// Query parameters: q (required), since, until (e.g. 7d, 2006-01-02), model,
+// role, workspace (bound path or alias), session and limit.
+func (r *Router) HandleSearch(w http.ResponseWriter, req *http.Request) {
+	if r.db == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "database not available")
+		return
+	}
+	q := req.URL.Query()
+	query := strings.TrimSpace(q.Get("q"))
+	if query == "" {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, "q is required")
+		return
+	}
+	now := time.Now()
+	since, err := usage.ParseSince(q.Get("since"), now)
+	if err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	until, err := usage.ParseSince(q.Get("until"), now)
+	if err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	opts := storage.MessageSearchOptions{
+		Query: query,
+		Since: since,
+		Until: until,
+		Model: q.Get("model"),
+		Role:  q.Get("role"),
+		Limit: queryLimit(req, 20),
+	}
+	if session := q.Get("session"); session != "" {
+		opts.SessionIDs = []string{session}
+	}
+	if ws := q.Get("workspace"); ws != "" {
+		ids := r.workspaceSessions(ws)
+		if opts.SessionIDs != nil {
+			ids = intersect(ids, opts.SessionIDs)
+		}
+		opts.SessionIDs = ids
+	}
+
+	matches, err := r.db.SearchMessages(opts)
+	if err != nil {
+		log.Error().Err(err).Str("query", query).Msg("Failed to search messages")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to search messages")
+		return
+	}
+
+	resp := SearchResponse{Query: query, Results: make([]SearchResult, 0, len(matches))}
+	for _, m := range matches {
+		resp.Results = append(resp.Results, SearchResult{
+			MessageSearchResult: m,
+			Link:                "/api/v1/sessions/" + m.SessionID + "/messages#" + m.AnchorID,
+		})
+	}
+	handlers.SendJSON(w, http.StatusOK, resp)
+}
+
+// workspaceSessions returns the sessions bound to a workspace, matched by
+// alias or path. The result is never nil so an unknown workspace matches
+// nothing rather than everything.
+func (r *Router) workspaceSessions(workspace string) []string {
+	ids := []string{}
+	if r.workspaceManager == nil {
+		return ids
+	}
+	path := workspace
+	if abs, err := filepath.Abs(workspace); err == nil {
+		path = abs
+	}
+	for _, b := range r.workspaceManager.List() {
+		if b.Alias == workspace || filepath.Clean(b.Path) == path {
+			ids = append(ids, b.SessionID)
+		}
+	}
+	return ids
+}
+
+func intersect(a, b []string) []string {
+	set := make(map[string]bool, len(b))
+	for _, s := range b {
+		set[s] = true
+	}
+	out := []string{}
+	for _, s := range a {
+		if set[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"mote/internal/storage"
+)
+
+func TestHandleSearch(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	defer db.Close()
+
+	sess, _ := db.CreateSession(nil)
+	msg, _ := db.AppendMessage(sess.ID, "user", "the deploy failed with exit code 137", nil, "")
+
+	m := mux.NewRouter()
+	NewRouter(&RouterDeps{DB: db}).RegisterRoutes(m)
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		m.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	rr := get("/api/v1/search?q=deploy+137&since=7d")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp SearchResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].MessageID != msg.ID {
+		t.Fatalf("unexpected results: %s", rr.Body.String())
+	}
+	if want := "/api/v1/sessions/" + sess.ID + "/messages#" + msg.ID; resp.Results[0].Link != want {
+		t.Errorf("link = %q, want %q", resp.Results[0].Link, want)
+	}
+
+	// Without a workspace manager no session is bound to any workspace
+	rr = get("/api/v1/search?q=deploy&workspace=proj")
+	_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || len(resp.Results) != 0 {
+		t.Errorf("workspace filter: status %d, %d results", rr.Code, len(resp.Results))
+	}
+
+	if rr := get("/api/v1/search"); rr.Code != http.StatusBadRequest {
+		t.Errorf("missing q: status %d", rr.Code)
+	}
+	if rr := get("/api/v1/search?q=deploy&since=yesterday"); rr.Code != http.StatusBadRequest {
+		t.Errorf("bad since: status %d", rr.Code)
+	}
+}
@@ -1,7 +1,11 @@
 // Package v1 provides API v1 data types and handlers.
 package v1
 
-import "time"
+import (
+	"time"
+
+	"mote/internal/mcp/client"
+)
 
 // =============================================================================
 // Error Codes
@@ -91,6 +95,8 @@ type ApprovalRequestSSEEvent struct {
 	Reason    string `json:"reason"`
 	SessionID string `json:"session_id"`
 	ExpiresAt string `json:"expires_at"`
+
+	WorkspacePath string `json:"workspace_path,omitempty"`
 }
 
 // ApprovalResolvedSSEEvent represents an approval resolution sent via SSE.
@@ -129,6 +135,7 @@ type ToolCallUpdateEvent struct {
 	ToolName   string `json:"tool_name"`
 	Status     string `json:"status,omitempty"`    // "running", "completed"
 	Arguments  string `json:"arguments,omitempty"` // May be partial during streaming
+	Message    string `json:"message,omitempty"`   // Progress or log line while running
 }
 
 // ErrorDetail represents detailed error information for SSE events.
@@ -539,17 +546,21 @@ type UpdateConfigRequest struct {
 
 // MCPServerInfo represents MCP server connection info.
 type MCPServerInfo struct {
-	Name        string            `json:"name"`
-	Status      string            `json:"status"`    // connected, disconnected, error
-	Transport   string            `json:"transport"` // stdio, http
-	Tools       []string          `json:"tools,omitempty"`
-	ToolCount   int               `json:"tool_count,omitempty"`
-	PromptCount int               `json:"prompt_count,omitempty"`
-	Error       string            `json:"error,omitempty"`
-	URL         string            `json:"url,omitempty"`
-	Headers     map[string]string `json:"headers,omitempty"`
-	Command     string            `json:"command,omitempty"`
-	Args        []string          `json:"args,omitempty"`
+	Name          string               `json:"name"`
+	Status        string               `json:"status"`    // connected, disconnected, error
+	Transport     string               `json:"transport"` // stdio, http
+	Tools         []string             `json:"tools,omitempty"`
+	ToolCount     int                  `json:"tool_count,omitempty"`
+	PromptCount   int                  `json:"prompt_count,omitempty"`
+	ResourceCount int                  `json:"resource_count,omitempty"`
+	Error         string               `json:"error,omitempty"`
+	URL           string               `json:"url,omitempty"`
+	Headers       map[string]string    `json:"headers,omitempty"`
+	Command       string               `json:"command,omitempty"`
+	Args          []string             `json:"args,omitempty"`
+	Authorized    bool                 `json:"authorized,omitempty"` // OAuth token stored
+	Health        *client.ServerHealth `json:"health,omitempty"`
+	MCPServerLimits
 }
 
 // MCPServersResponse represents the response for listing MCP servers.
@@ -592,14 +603,15 @@ type MCPPromptsResponse struct {
 
 // MCPServerDetail represents detailed info about an MCP server.
 type MCPServerDetail struct {
-	Name        string          `json:"name"`
-	Status      string          `json:"status"`
-	Transport   string          `json:"transport"`
-	URL         string          `json:"url,omitempty"`
-	ToolCount   int             `json:"tool_count"`
-	PromptCount int             `json:"prompt_count"`
-	Tools       []MCPToolInfo   `json:"tools"`
-	Prompts     []MCPPromptInfo `json:"prompts"`
+	Name        string            `json:"name"`
+	Status      string            `json:"status"`
+	Transport   string            `json:"transport"`
+	URL         string            `json:"url,omitempty"`
+	ToolCount   int               `json:"tool_count"`
+	PromptCount int               `json:"prompt_count"`
+	Tools       []MCPToolInfo     `json:"tools"`
+	Prompts     []MCPPromptInfo   `json:"prompts"`
+	Resources   []MCPResourceInfo `json:"resources,omitempty"`
 }
 
 // =============================================================================
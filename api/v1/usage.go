@@ -0,0 +1,151 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"mote/internal/config"
+	"mote/internal/gateway/handlers"
+	"mote/internal/usage"
+)
+
+// UsageReportResponse is returned by GET /api/v1/usage.
+type UsageReportResponse struct {
+	By     usage.Dimension `json:"by"`
+	Since  *time.Time      `json:"since,omitempty"`
+	Total  usage.Totals    `json:"total"`
+	Groups []usage.Totals  `json:"groups"`
+}
+
+func (r *Router) usageLedger() *usage.Ledger {
+	if r.db == nil {
+		return nil
+	}
+	return usage.NewLedger(r.db.DB)
+}
+
+// usageFilter builds a ledger filter from the since/until/session/agent/cron/model
+// query parameters.
+func usageFilter(req *http.Request) (usage.Filter, error) {
+	q := req.URL.Query()
+	now := time.Now()
+	since, err := usage.ParseSince(q.Get("since"), now)
+	if err != nil {
+		return usage.Filter{}, err
+	}
+	until, err := usage.ParseSince(q.Get("until"), now)
+	if err != nil {
+		return usage.Filter{}, err
+	}
+	return usage.Filter{
+		Since:         since,
+		Until:         until,
+		RootSessionID: q.Get("session"),
+		Agent:         q.Get("agent"),
+		CronJob:       q.Get("cron"),
+		Model:         q.Get("model"),
+	}, nil
+}
+
+func queryLimit(req *http.Request, def int) int {
+	if l := req.URL.Query().Get("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 {
+			return n
+		}
+	}
+	return def
+}
+
+// HandleUsageReport returns token usage and estimated cost grouped by one
+// dimension (?by=agent|session|cron|model|day, default model).
+func (r *Router) HandleUsageReport(w http.ResponseWriter, req *http.Request) {
+	ledger := r.usageLedger()
+	if ledger == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "database not available")
+		return
+	}
+	filter, err := usageFilter(req)
+	if err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	by := usage.ByModel
+	if b := req.URL.Query().Get("by"); b != "" {
+		if by, err = usage.ParseDimension(b); err != nil {
+			handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	groups, err := ledger.Report(filter, by, queryLimit(req, 100))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build usage report")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to build usage report")
+		return
+	}
+	total, err := ledger.Sum(filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to sum usage")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to build usage report")
+		return
+	}
+	if groups == nil {
+		groups = []usage.Totals{}
+	}
+
+	resp := UsageReportResponse{By: by, Total: total, Groups: groups}
+	if !filter.Since.IsZero() {
+		resp.Since = &filter.Since
+	}
+	handlers.SendJSON(w, http.StatusOK, resp)
+}
+
+// HandleUsageEntries returns the most recent ledger entries matching the filter.
+func (r *Router) HandleUsageEntries(w http.ResponseWriter, req *http.Request) {
+	ledger := r.usageLedger()
+	if ledger == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "database not available")
+		return
+	}
+	filter, err := usageFilter(req)
+	if err != nil {
+		handlers.SendError(w, http.StatusBadRequest, handlers.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	entries, err := ledger.Recent(filter, queryLimit(req, 50))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list usage entries")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to list usage entries")
+		return
+	}
+	if entries == nil {
+		entries = []usage.Entry{}
+	}
+	handlers.SendJSON(w, http.StatusOK, map[string]any{"entries": entries})
+}
+
+// HandleUsageBudgets reports spend against each configured budget.
+func (r *Router) HandleUsageBudgets(w http.ResponseWriter, req *http.Request) {
+	ledger := r.usageLedger()
+	if ledger == nil {
+		handlers.SendError(w, http.StatusServiceUnavailable, handlers.ErrCodeServiceUnavailable, "database not available")
+		return
+	}
+	var usageCfg config.UsageConfig
+	if cfg := config.GetConfig(); cfg != nil {
+		usageCfg = cfg.Usage
+	}
+	statuses, err := usage.NewMeter(ledger, usageCfg).Status(queryLimit(req, 20))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to evaluate usage budgets")
+		handlers.SendError(w, http.StatusInternalServerError, handlers.ErrCodeInternalError, "failed to evaluate usage budgets")
+		return
+	}
+	if statuses == nil {
+		statuses = []usage.BudgetStatus{}
+	}
+	handlers.SendJSON(w, http.StatusOK, map[string]any{"budgets": statuses})
+}
@@ -0,0 +1,414 @@
+// Package agentbundle packages an agent together with the sub-agents, skills,
+// prompts and MCP server declarations it depends on, so it can be shared and
+// imported into another Mote installation.
+//
+// A bundle is a gzip-compressed tar archive with a bundle.yaml manifest at the
+// root, skill directories under skills/<id>/ and prompt files under prompts/.
+package agentbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"mote/internal/config"
+	"mote/internal/runner/delegate/cfg"
+	"mote/internal/skills"
+)
+
+// FormatVersion is the bundle layout version written by this package.
+const FormatVersion = 1
+
+// ManifestFile is the name of the manifest entry inside a bundle.
+const ManifestFile = "bundle.yaml"
+
+// maxBundleFileSize caps a single extracted file to guard against archive bombs.
+const maxBundleFileSize = 32 << 20
+
+// Manifest describes the contents of a bundle.
+type Manifest struct {
+	FormatVersion int                           `yaml:"format_version" json:"format_version"`
+	Name          string                        `yaml:"name" json:"name"`
+	Version       string                        `yaml:"version" json:"version"`
+	Description   string                        `yaml:"description,omitempty" json:"description,omitempty"`
+	CreatedAt     time.Time                     `yaml:"created_at" json:"created_at"`
+	MoteVersion   string                        `yaml:"mote_version,omitempty" json:"mote_version,omitempty"`
+	Agents        map[string]config.AgentConfig `yaml:"agents" json:"agents"`
+	Skills        []SkillEntry                  `yaml:"skills,omitempty" json:"skills,omitempty"`
+	Prompts       []PromptEntry                 `yaml:"prompts,omitempty" json:"prompts,omitempty"`
+	MCPServers    []MCPServer                   `yaml:"mcp_servers,omitempty" json:"mcp_servers,omitempty"`
+	RequiredTools []string                      `yaml:"required_tools,omitempty" json:"required_tools,omitempty"`
+}
+
+// SkillEntry records a skill packaged under skills/<ID>/.
+type SkillEntry struct {
+	ID      string   `yaml:"id" json:"id"`
+	Name    string   `yaml:"name,omitempty" json:"name,omitempty"`
+	Version string   `yaml:"version,omitempty" json:"version,omitempty"`
+	Tools   []string `yaml:"tools,omitempty" json:"tools,omitempty"`
+}
+
+// PromptEntry records a prompt file packaged under prompts/<File>.
+type PromptEntry struct {
+	Name string `yaml:"name" json:"name"`
+	File string `yaml:"file" json:"file"`
+}
+
+// MCPServer is a portable MCP server declaration. Header values are never
+// exported; only the header names are kept so the importer knows what to fill in.
+type MCPServer struct {
+	Name    string            `yaml:"name" json:"name"`
+	Type    string            `yaml:"type" json:"type"`
+	URL     string            `yaml:"url,omitempty" json:"url,omitempty"`
+	Command string            `yaml:"command,omitempty" json:"command,omitempty"`
+	Args    []string          `yaml:"args,omitempty" json:"args,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// PromptFile is a prompt available for export.
+type PromptFile struct {
+	Name string
+	Path string
+}
+
+// Sources is the local state an export draws from.
+type Sources struct {
+	Agents     map[string]config.AgentConfig
+	Skills     []*skills.Skill
+	Prompts    []PromptFile
+	MCPServers []MCPServer
+}
+
+// ExportOptions controls what goes into a bundle besides the agent itself.
+type ExportOptions struct {
+	Version     string
+	Description string
+	MoteVersion string
+	// Prompts lists prompt names to include. Prompts are not referenced by
+	// agent configs, so they must be selected explicitly.
+	Prompts []string
+}
+
+// Bundle is an in-memory bundle: the manifest plus packaged files keyed by
+// their slash-separated path inside the archive.
+type Bundle struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// Export builds a bundle for agentName, pulling in the sub-agents it references
+// through PDA steps, the skills that provide its tools, the MCP servers whose
+// bridged tools it uses and the selected prompts.
+func Export(agentName string, src Sources, opts ExportOptions) (*Bundle, error) {
+	if _, ok := src.Agents[agentName]; !ok {
+		return nil, fmt.Errorf("agent not found: %s", agentName)
+	}
+
+	version := opts.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+
+	b := &Bundle{
+		Manifest: Manifest{
+			FormatVersion: FormatVersion,
+			Name:          agentName,
+			Version:       version,
+			Description:   opts.Description,
+			CreatedAt:     time.Now().UTC().Truncate(time.Second),
+			MoteVersion:   opts.MoteVersion,
+			Agents:        make(map[string]config.AgentConfig),
+		},
+		Files: make(map[string][]byte),
+	}
+	if b.Manifest.Description == "" {
+		b.Manifest.Description = src.Agents[agentName].Description
+	}
+
+	// Collect the agent and every agent reachable through its steps.
+	var missing []string
+	queue := []string{agentName}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, done := b.Manifest.Agents[name]; done {
+			continue
+		}
+		agent, ok := src.Agents[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		agent.Draft = nil
+		b.Manifest.Agents[name] = agent
+		queue = append(queue, referencedAgents(agent)...)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("agent %s references unknown agents: %s", agentName, strings.Join(missing, ", "))
+	}
+
+	required := requiredTools(b.Manifest.Agents)
+	b.Manifest.RequiredTools = required
+
+	// Skills providing any required tool.
+	for _, skill := range src.Skills {
+		if skill == nil || skill.FilePath == "" {
+			continue
+		}
+		var provided []string
+		for _, def := range skill.Tools {
+			if containsString(required, def.Name) {
+				provided = append(provided, def.Name)
+			}
+		}
+		if len(provided) == 0 {
+			continue
+		}
+		if err := b.addDir(filepath.Dir(skill.FilePath), "skills/"+skill.ID); err != nil {
+			return nil, fmt.Errorf("package skill %s: %w", skill.ID, err)
+		}
+		b.Manifest.Skills = append(b.Manifest.Skills, SkillEntry{
+			ID:      skill.ID,
+			Name:    skill.Name,
+			Version: skill.Version,
+			Tools:   provided,
+		})
+	}
+
+	// MCP servers whose bridged tools ("<server>_<tool>") are required.
+	for _, server := range src.MCPServers {
+		if !usesServer(required, server.Name) {
+			continue
+		}
+		decl := server
+		if len(server.Headers) > 0 {
+			decl.Headers = make(map[string]string, len(server.Headers))
+			for k := range server.Headers {
+				decl.Headers[k] = ""
+			}
+		}
+		b.Manifest.MCPServers = append(b.Manifest.MCPServers, decl)
+	}
+
+	// Explicitly selected prompts.
+	for _, name := range opts.Prompts {
+		var found *PromptFile
+		for i := range src.Prompts {
+			if src.Prompts[i].Name == name {
+				found = &src.Prompts[i]
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("prompt not found: %s", name)
+		}
+		data, err := os.ReadFile(found.Path)
+		if err != nil {
+			return nil, fmt.Errorf("read prompt %s: %w", name, err)
+		}
+		file := filepath.Base(found.Path)
+		b.Files["prompts/"+file] = data
+		b.Manifest.Prompts = append(b.Manifest.Prompts, PromptEntry{Name: name, File: file})
+	}
+
+	return b, nil
+}
+
+// Write serializes the bundle as a gzip-compressed tar archive.
+func (b *Bundle) Write(w io.Writer) error {
+	manifest, err := yaml.Marshal(b.Manifest)
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	writeEntry := func(name string, data []byte) error {
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: b.Manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := writeEntry(ManifestFile, manifest); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	names := make([]string, 0, len(b.Files))
+	for name := range b.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writeEntry(name, b.Files[name]); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read parses a bundle produced by Write.
+func Read(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open bundle: %w", err)
+	}
+	defer gz.Close()
+
+	b := &Bundle{Files: make(map[string][]byte)}
+	var manifest []byte
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, err := cleanEntryName(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Size > maxBundleFileSize {
+			return nil, fmt.Errorf("bundle entry %s too large (%d bytes)", name, hdr.Size)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxBundleFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		if name == ManifestFile {
+			manifest = data
+			continue
+		}
+		b.Files[name] = data
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("bundle has no %s", ManifestFile)
+	}
+	if err := yaml.Unmarshal(manifest, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if b.Manifest.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("bundle format version %d is newer than supported version %d",
+			b.Manifest.FormatVersion, FormatVersion)
+	}
+	if _, ok := b.Manifest.Agents[b.Manifest.Name]; !ok {
+		return nil, fmt.Errorf("bundle manifest does not contain its root agent %q", b.Manifest.Name)
+	}
+	return b, nil
+}
+
+// addDir packages every regular file under dir at prefix/<relative path>.
+func (b *Bundle) addDir(dir, prefix string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") && p != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		b.Files[prefix+"/"+filepath.ToSlash(rel)] = data
+		return nil
+	})
+}
+
+// referencedAgents returns the agent names referenced by an agent's PDA steps.
+func referencedAgents(agent config.AgentConfig) []string {
+	var refs []string
+	for _, step := range agent.Steps {
+		if step.Agent != "" {
+			refs = append(refs, step.Agent)
+		}
+		for _, target := range step.Branches {
+			if target != "" && target != cfg.RouteEndMarker {
+				refs = append(refs, target)
+			}
+		}
+	}
+	return refs
+}
+
+// requiredTools returns the sorted union of tool names listed by the agents.
+func requiredTools(agents map[string]config.AgentConfig) []string {
+	set := make(map[string]bool)
+	for _, agent := range agents {
+		for _, tool := range agent.Tools {
+			set[tool] = true
+		}
+	}
+	out := make([]string, 0, len(set))
+	for tool := range set {
+		out = append(out, tool)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// usesServer reports whether any tool is an MCP tool bridged from server.
+func usesServer(tools []string, server string) bool {
+	prefix := server + "_"
+	for _, tool := range tools {
+		if strings.HasPrefix(tool, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanEntryName rejects absolute paths and traversal in archive entries.
+func cleanEntryName(name string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid bundle entry path: %s", name)
+	}
+	return clean, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
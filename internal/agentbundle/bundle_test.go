@@ -0,0 +1,261 @@
+package agentbundle
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mote/internal/config"
+	"mote/internal/runner/delegate/cfg"
+	"mote/internal/skills"
+)
+
+func testSources(t *testing.T) Sources {
+	t.Helper()
+	dir := t.TempDir()
+
+	skillDir := filepath.Join(dir, "skills", "web-search")
+	if err := os.MkdirAll(skillDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := filepath.Join(skillDir, "manifest.json")
+	if err := os.WriteFile(manifest, []byte(`{"id":"web-search"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "search.js"), []byte("function search() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	promptPath := filepath.Join(dir, "style.md")
+	if err := os.WriteFile(promptPath, []byte("---\nname: style\n---\nBe terse."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return Sources{
+		Agents: map[string]config.AgentConfig{
+			"lead": {
+				Description: "Lead researcher",
+				Tools:       []string{"web_search", "github_list_issues"},
+				Steps: []cfg.Step{
+					{Type: cfg.StepAgentRef, Agent: "writer"},
+					{Type: cfg.StepRoute, Branches: map[string]string{"done": cfg.RouteEndMarker, "again": "lead"}},
+				},
+			},
+			"writer":    {Description: "Writes", Tools: []string{"write_file"}},
+			"unrelated": {Description: "Not exported"},
+		},
+		Skills: []*skills.Skill{{
+			ID:       "web-search",
+			Name:     "Web Search",
+			Version:  "0.2.0",
+			FilePath: manifest,
+			Tools:    []*skills.ToolDef{{Name: "web_search"}, {Name: "web_fetch"}},
+		}},
+		Prompts: []PromptFile{{Name: "style", Path: promptPath}},
+		MCPServers: []MCPServer{
+			{Name: "github", Type: "http", URL: "https://example.com/mcp", Headers: map[string]string{"Authorization": "Bearer secret"}},
+			{Name: "slack", Type: "stdio", Command: "slack-mcp"},
+		},
+	}
+}
+
+func TestExport_CollectsDependencies(t *testing.T) {
+	b, err := Export("lead", testSources(t), ExportOptions{Version: "2.0.0", Prompts: []string{"style"}})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	if len(b.Manifest.Agents) != 2 {
+		t.Errorf("expected lead and writer, got %v", agentNames(b.Manifest.Agents))
+	}
+	if _, ok := b.Manifest.Agents["unrelated"]; ok {
+		t.Error("unrelated agent should not be exported")
+	}
+	if b.Manifest.Description != "Lead researcher" || b.Manifest.Version != "2.0.0" {
+		t.Errorf("unexpected metadata: %+v", b.Manifest)
+	}
+
+	if len(b.Manifest.Skills) != 1 || b.Manifest.Skills[0].ID != "web-search" {
+		t.Fatalf("expected web-search skill, got %+v", b.Manifest.Skills)
+	}
+	if _, ok := b.Files["skills/web-search/search.js"]; !ok {
+		t.Error("skill files not packaged")
+	}
+
+	if len(b.Manifest.MCPServers) != 1 || b.Manifest.MCPServers[0].Name != "github" {
+		t.Fatalf("expected only github MCP server, got %+v", b.Manifest.MCPServers)
+	}
+	if v := b.Manifest.MCPServers[0].Headers["Authorization"]; v != "" {
+		t.Errorf("header value should not be exported, got %q", v)
+	}
+
+	if len(b.Manifest.Prompts) != 1 || string(b.Files["prompts/style.md"]) == "" {
+		t.Errorf("prompt not packaged: %+v", b.Manifest.Prompts)
+	}
+}
+
+func TestExport_UnknownAgent(t *testing.T) {
+	src := testSources(t)
+	if _, err := Export("missing", src, ExportOptions{}); err == nil {
+		t.Error("expected error for unknown agent")
+	}
+
+	lead := src.Agents["lead"]
+	lead.Steps = append(lead.Steps, cfg.Step{Type: cfg.StepAgentRef, Agent: "ghost"})
+	src.Agents["lead"] = lead
+	if _, err := Export("lead", src, ExportOptions{}); err == nil {
+		t.Error("expected error for dangling step reference")
+	}
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	b, err := Export("lead", testSources(t), ExportOptions{Prompts: []string{"style"}})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	if got.Manifest.Name != "lead" || len(got.Manifest.Agents) != 2 {
+		t.Errorf("manifest mismatch: %+v", got.Manifest)
+	}
+	if len(got.Files) != len(b.Files) {
+		t.Errorf("files = %d, want %d", len(got.Files), len(b.Files))
+	}
+	if string(got.Files["skills/web-search/search.js"]) != "function search() {}" {
+		t.Error("skill file content mismatch")
+	}
+}
+
+func TestPlan_Conflicts(t *testing.T) {
+	b, err := Export("lead", testSources(t), ExportOptions{})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	env := Environment{
+		Agents:   map[string]config.AgentConfig{"writer": {}},
+		SkillIDs: []string{"web-search"},
+	}
+
+	if _, err := b.Plan(env, ConflictFail); err == nil {
+		t.Error("expected conflict error with fail policy")
+	}
+
+	plan, err := b.Plan(env, ConflictRename)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	actions := map[string]ItemAction{}
+	for _, a := range plan.Agents {
+		actions[a.Name] = a
+	}
+	if actions["writer"].Action != ActionRename || actions["writer"].Target != "writer-imported" {
+		t.Errorf("unexpected writer action: %+v", actions["writer"])
+	}
+	if actions["lead"].Action != ActionAdd {
+		t.Errorf("unexpected lead action: %+v", actions["lead"])
+	}
+	if plan.Skills[0].Action != ActionSkip {
+		t.Errorf("existing skill should be skipped under rename, got %+v", plan.Skills[0])
+	}
+	if !plan.ToolCheckSkipped {
+		t.Error("expected tool check to be skipped without known tools")
+	}
+}
+
+func TestPlan_MissingTools(t *testing.T) {
+	b, err := Export("lead", testSources(t), ExportOptions{})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	plan, err := b.Plan(Environment{KnownTools: []string{"read_file"}}, ConflictFail)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	// web_search comes from the bundled skill, github_* from the bundled MCP server.
+	if len(plan.MissingTools) != 1 || plan.MissingTools[0] != "write_file" {
+		t.Errorf("missing tools = %v, want [write_file]", plan.MissingTools)
+	}
+	if keys := plan.MissingHeaders["github"]; len(keys) != 1 || keys[0] != "Authorization" {
+		t.Errorf("missing headers = %v", plan.MissingHeaders)
+	}
+}
+
+func TestApply_RenameRewritesReferences(t *testing.T) {
+	b, err := Export("lead", testSources(t), ExportOptions{Prompts: []string{"style"}})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	plan, err := b.Plan(Environment{
+		Agents: map[string]config.AgentConfig{"writer": {}},
+	}, ConflictRename)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	dst := t.TempDir()
+	saved := map[string]config.AgentConfig{}
+	var servers []MCPServer
+	err = b.Apply(plan, Destination{
+		SkillsDir:  filepath.Join(dst, "skills"),
+		PromptsDir: filepath.Join(dst, "prompts"),
+		SaveAgent: func(name string, agent config.AgentConfig, overwrite bool) error {
+			saved[name] = agent
+			return nil
+		},
+		AddMCPServer: func(s MCPServer) error {
+			servers = append(servers, s)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if _, ok := saved["writer-imported"]; !ok {
+		t.Fatalf("expected writer-imported to be saved, got %v", agentNames(saved))
+	}
+	if got := saved["lead"].Steps[0].Agent; got != "writer-imported" {
+		t.Errorf("step reference not rewritten: %q", got)
+	}
+	if got := saved["lead"].Steps[1].Branches["done"]; got != cfg.RouteEndMarker {
+		t.Errorf("end marker should be preserved, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "skills", "web-search", "search.js")); err != nil {
+		t.Errorf("skill not installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "prompts", "style.md")); err != nil {
+		t.Errorf("prompt not installed: %v", err)
+	}
+	if len(servers) != 1 || servers[0].Name != "github" {
+		t.Errorf("unexpected MCP servers: %+v", servers)
+	}
+}
+
+func TestCleanEntryName(t *testing.T) {
+	for _, bad := range []string{"../etc/passwd", "/abs/path", "a/../../b"} {
+		if _, err := cleanEntryName(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+	if got, err := cleanEntryName("./skills/x/a.js"); err != nil || got != "skills/x/a.js" {
+		t.Errorf("cleanEntryName = %q, %v", got, err)
+	}
+}
+
+func agentNames(m map[string]config.AgentConfig) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
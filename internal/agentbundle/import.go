@@ -0,0 +1,350 @@
+package agentbundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mote/internal/config"
+)
+
+// ConflictPolicy decides what happens when a bundled item already exists locally.
+type ConflictPolicy string
+
+const (
+	// ConflictFail aborts the import on the first conflict.
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictSkip keeps the local item and ignores the bundled one.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the local item with the bundled one.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictRename imports conflicting agents under a new name and rewrites
+	// step references inside the bundle. Other items fall back to skip.
+	ConflictRename ConflictPolicy = "rename"
+)
+
+// ParseConflictPolicy validates a conflict policy string.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case ConflictFail, ConflictSkip, ConflictOverwrite, ConflictRename:
+		return p, nil
+	case "":
+		return ConflictFail, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q (want fail, skip, overwrite or rename)", s)
+	}
+}
+
+// Import actions recorded in a plan.
+const (
+	ActionAdd       = "add"
+	ActionOverwrite = "overwrite"
+	ActionSkip      = "skip"
+	ActionRename    = "rename"
+)
+
+// Environment describes the local installation a bundle is imported into.
+type Environment struct {
+	Agents     map[string]config.AgentConfig
+	SkillIDs   []string
+	Prompts    []string
+	MCPServers []string
+	// KnownTools lists tool names available locally. Nil means the tool set
+	// could not be determined and the missing-tool check is skipped.
+	KnownTools []string
+}
+
+// ItemAction is the planned outcome for one bundled item.
+type ItemAction struct {
+	Name   string `json:"name"`
+	Target string `json:"target,omitempty"`
+	Action string `json:"action"`
+}
+
+// Plan is the result of resolving a bundle against an Environment.
+type Plan struct {
+	Agents     []ItemAction `json:"agents"`
+	Skills     []ItemAction `json:"skills"`
+	Prompts    []ItemAction `json:"prompts"`
+	MCPServers []ItemAction `json:"mcp_servers"`
+	// MissingTools are required tools provided neither locally nor by the bundle.
+	MissingTools []string `json:"missing_tools,omitempty"`
+	// MissingHeaders lists, per MCP server, header names that need values.
+	MissingHeaders map[string][]string `json:"missing_headers,omitempty"`
+	// ToolCheckSkipped is set when KnownTools was unavailable.
+	ToolCheckSkipped bool `json:"tool_check_skipped,omitempty"`
+
+	renames map[string]string
+}
+
+// Plan resolves conflicts and dependencies without changing anything.
+func (b *Bundle) Plan(env Environment, policy ConflictPolicy) (*Plan, error) {
+	plan := &Plan{renames: make(map[string]string)}
+
+	agentNames := make([]string, 0, len(b.Manifest.Agents))
+	for name := range b.Manifest.Agents {
+		agentNames = append(agentNames, name)
+	}
+	sort.Strings(agentNames)
+
+	taken := make(map[string]bool, len(env.Agents))
+	for name := range env.Agents {
+		taken[name] = true
+	}
+	for _, name := range agentNames {
+		taken[name] = true
+	}
+
+	for _, name := range agentNames {
+		if _, exists := env.Agents[name]; !exists {
+			plan.Agents = append(plan.Agents, ItemAction{Name: name, Target: name, Action: ActionAdd})
+			continue
+		}
+		switch policy {
+		case ConflictSkip:
+			plan.Agents = append(plan.Agents, ItemAction{Name: name, Target: name, Action: ActionSkip})
+		case ConflictOverwrite:
+			plan.Agents = append(plan.Agents, ItemAction{Name: name, Target: name, Action: ActionOverwrite})
+		case ConflictRename:
+			target := uniqueName(name, taken)
+			taken[target] = true
+			plan.renames[name] = target
+			plan.Agents = append(plan.Agents, ItemAction{Name: name, Target: target, Action: ActionRename})
+		default:
+			return nil, fmt.Errorf("agent %q already exists", name)
+		}
+	}
+
+	for _, s := range b.Manifest.Skills {
+		if !isPlainName(s.ID) {
+			return nil, fmt.Errorf("invalid skill id in bundle: %q", s.ID)
+		}
+		action, err := resolveItem("skill", s.ID, containsString(env.SkillIDs, s.ID), policy)
+		if err != nil {
+			return nil, err
+		}
+		plan.Skills = append(plan.Skills, ItemAction{Name: s.ID, Target: s.ID, Action: action})
+	}
+	for _, p := range b.Manifest.Prompts {
+		if !isPlainName(p.File) {
+			return nil, fmt.Errorf("invalid prompt file in bundle: %q", p.File)
+		}
+		action, err := resolveItem("prompt", p.Name, containsString(env.Prompts, p.Name), policy)
+		if err != nil {
+			return nil, err
+		}
+		plan.Prompts = append(plan.Prompts, ItemAction{Name: p.Name, Target: p.File, Action: action})
+	}
+	for _, s := range b.Manifest.MCPServers {
+		action, err := resolveItem("MCP server", s.Name, containsString(env.MCPServers, s.Name), policy)
+		if err != nil {
+			return nil, err
+		}
+		plan.MCPServers = append(plan.MCPServers, ItemAction{Name: s.Name, Target: s.Name, Action: action})
+		if action != ActionSkip && len(s.Headers) > 0 {
+			if plan.MissingHeaders == nil {
+				plan.MissingHeaders = make(map[string][]string)
+			}
+			keys := make([]string, 0, len(s.Headers))
+			for k := range s.Headers {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			plan.MissingHeaders[s.Name] = keys
+		}
+	}
+
+	if env.KnownTools == nil {
+		plan.ToolCheckSkipped = true
+	} else {
+		plan.MissingTools = b.missingTools(env)
+	}
+	return plan, nil
+}
+
+// missingTools returns required tools not available locally, from bundled
+// skills, or from an MCP server declared in the bundle or configured locally.
+func (b *Bundle) missingTools(env Environment) []string {
+	provided := make(map[string]bool)
+	for _, t := range env.KnownTools {
+		provided[t] = true
+	}
+	for _, s := range b.Manifest.Skills {
+		for _, t := range s.Tools {
+			provided[t] = true
+		}
+	}
+	servers := append([]string{}, env.MCPServers...)
+	for _, s := range b.Manifest.MCPServers {
+		servers = append(servers, s.Name)
+	}
+
+	var missing []string
+	for _, tool := range b.Manifest.RequiredTools {
+		if provided[tool] {
+			continue
+		}
+		bridged := false
+		for _, server := range servers {
+			if strings.HasPrefix(tool, server+"_") {
+				bridged = true
+				break
+			}
+		}
+		if !bridged {
+			missing = append(missing, tool)
+		}
+	}
+	return missing
+}
+
+// Destination is where Apply installs bundle contents.
+type Destination struct {
+	SkillsDir  string
+	PromptsDir string
+	// SaveAgent persists an agent config; overwrite is set when replacing one.
+	SaveAgent func(name string, agent config.AgentConfig, overwrite bool) error
+	// AddMCPServer persists an MCP server declaration.
+	AddMCPServer func(server MCPServer) error
+}
+
+// Apply installs the bundle according to plan. Skills and prompts are written
+// before agents so a failure never leaves agents pointing at absent files.
+func (b *Bundle) Apply(plan *Plan, dst Destination) error {
+	for _, item := range plan.Skills {
+		if item.Action == ActionSkip {
+			continue
+		}
+		dir := filepath.Join(dst.SkillsDir, item.Target)
+		if item.Action == ActionOverwrite {
+			if err := os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("remove skill %s: %w", item.Name, err)
+			}
+		}
+		if err := b.extract("skills/"+item.Name+"/", dir); err != nil {
+			return fmt.Errorf("install skill %s: %w", item.Name, err)
+		}
+	}
+
+	for _, item := range plan.Prompts {
+		if item.Action == ActionSkip {
+			continue
+		}
+		if err := os.MkdirAll(dst.PromptsDir, 0755); err != nil {
+			return fmt.Errorf("create prompts dir: %w", err)
+		}
+		data, ok := b.Files["prompts/"+item.Target]
+		if !ok {
+			return fmt.Errorf("bundle is missing prompt file %s", item.Target)
+		}
+		if err := os.WriteFile(filepath.Join(dst.PromptsDir, item.Target), data, 0644); err != nil {
+			return fmt.Errorf("install prompt %s: %w", item.Name, err)
+		}
+	}
+
+	if dst.AddMCPServer != nil {
+		for _, item := range plan.MCPServers {
+			if item.Action == ActionSkip {
+				continue
+			}
+			for _, s := range b.Manifest.MCPServers {
+				if s.Name == item.Name {
+					if err := dst.AddMCPServer(s); err != nil {
+						return fmt.Errorf("add MCP server %s: %w", s.Name, err)
+					}
+				}
+			}
+		}
+	}
+
+	if dst.SaveAgent == nil {
+		return fmt.Errorf("no agent destination configured")
+	}
+	for _, item := range plan.Agents {
+		if item.Action == ActionSkip {
+			continue
+		}
+		agent := renameRefs(b.Manifest.Agents[item.Name], plan.renames)
+		if err := dst.SaveAgent(item.Target, agent, item.Action == ActionOverwrite); err != nil {
+			return fmt.Errorf("save agent %s: %w", item.Target, err)
+		}
+	}
+	return nil
+}
+
+// extract writes every bundled file under prefix into dir.
+func (b *Bundle) extract(prefix, dir string) error {
+	found := false
+	for name, data := range b.Files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		found = true
+		target := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, prefix)))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("bundle has no files under %s", prefix)
+	}
+	return nil
+}
+
+// renameRefs rewrites step references to agents renamed during import.
+func renameRefs(agent config.AgentConfig, renames map[string]string) config.AgentConfig {
+	if len(renames) == 0 || len(agent.Steps) == 0 {
+		return agent
+	}
+	out := agent
+	out.Steps = append(out.Steps[:0:0], agent.Steps...)
+	for i := range out.Steps {
+		if to, ok := renames[out.Steps[i].Agent]; ok {
+			out.Steps[i].Agent = to
+		}
+		if len(out.Steps[i].Branches) > 0 {
+			branches := make(map[string]string, len(out.Steps[i].Branches))
+			for match, target := range out.Steps[i].Branches {
+				if to, ok := renames[target]; ok {
+					target = to
+				}
+				branches[match] = target
+			}
+			out.Steps[i].Branches = branches
+		}
+	}
+	return out
+}
+
+func resolveItem(kind, name string, exists bool, policy ConflictPolicy) (string, error) {
+	if !exists {
+		return ActionAdd, nil
+	}
+	switch policy {
+	case ConflictOverwrite:
+		return ActionOverwrite, nil
+	case ConflictSkip, ConflictRename:
+		return ActionSkip, nil
+	default:
+		return "", fmt.Errorf("%s %q already exists", kind, name)
+	}
+}
+
+// uniqueName returns name with the first "-imported[-N]" suffix not in taken.
+func uniqueName(name string, taken map[string]bool) string {
+	candidate := name + "-imported"
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-imported-%d", name, i)
+	}
+	return candidate
+}
+
+// isPlainName reports whether s is usable as a single path element.
+func isPlainName(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`)
+}
@@ -0,0 +1,147 @@
+package artifacts_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mote/internal/artifacts"
+	"mote/internal/storage"
+	"mote/internal/tools"
+)
+
+func openStore(t *testing.T) (*artifacts.Store, *storage.DB) {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "artifacts.db"))
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return artifacts.NewStore(db.DB), db
+}
+
+func numberedLines(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestStore_OffloadAndGet(t *testing.T) {
+	store, db := openStore(t)
+	sess, err := db.CreateSession(nil)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	content := numberedLines(1000)
+	stub, err := store.Offload(sess.ID, "read_file", content, 64)
+	if err != nil {
+		t.Fatalf("offload: %v", err)
+	}
+	if len(stub) >= len(content) {
+		t.Fatalf("stub should be much smaller than content: %d bytes", len(stub))
+	}
+	if !strings.Contains(stub, "line 1\n") || strings.Contains(stub, "line 500") {
+		t.Errorf("stub should preview the beginning only:\n%s", stub)
+	}
+	if !strings.Contains(stub, artifacts.RecallToolName) {
+		t.Errorf("stub should name the recall tool:\n%s", stub)
+	}
+
+	list, err := store.List(sess.ID)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("list = %v, %v; want one artifact", list, err)
+	}
+	if list[0].Lines != 1000 || list[0].Size != len(content) || list[0].Content != "" {
+		t.Errorf("unexpected listing: %+v", list[0])
+	}
+
+	a, err := store.Get(sess.ID, list[0].ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if a.Content != content {
+		t.Error("content not preserved")
+	}
+
+	// Artifacts are scoped to their session and go away with it
+	if _, err := store.Get("other-session", a.ID); err != artifacts.ErrNotFound {
+		t.Errorf("get from other session: err = %v, want ErrNotFound", err)
+	}
+	if err := db.DeleteSession(sess.ID); err != nil {
+		t.Fatalf("delete session: %v", err)
+	}
+	if _, err := store.Get(sess.ID, a.ID); err != artifacts.ErrNotFound {
+		t.Errorf("get after session delete: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPaging(t *testing.T) {
+	a := &artifacts.Artifact{ID: "art_test", Content: numberedLines(50)}
+
+	got := artifacts.Lines(a, 10, 5)
+	if !strings.Contains(got, "line 10\n") || !strings.Contains(got, "line 14\n") || strings.Contains(got, "line 15\n") {
+		t.Errorf("Lines(10, 5) returned the wrong range:\n%s", got)
+	}
+	if !strings.Contains(got, "offset=15") {
+		t.Errorf("Lines should say where to continue:\n%s", got)
+	}
+	if got := artifacts.Lines(a, 48, 10); !strings.Contains(got, "End of artifact") {
+		t.Errorf("last page should say so:\n%s", got)
+	}
+
+	got = artifacts.Search(a, "LINE 42")
+	if !strings.Contains(got, "line 42\n") || !strings.Contains(got, "line 40\n") || !strings.Contains(got, "line 44\n") {
+		t.Errorf("Search should show the hit with context:\n%s", got)
+	}
+	if strings.Contains(got, "line 39\n") {
+		t.Errorf("Search context too wide:\n%s", got)
+	}
+
+	got = artifacts.Bytes(a, 7, 6)
+	if !strings.HasPrefix(got, "line 2") || !strings.Contains(got, "byte_offset=13") {
+		t.Errorf("Bytes returned the wrong range:\n%s", got)
+	}
+}
+
+func TestRecallTool(t *testing.T) {
+	store, db := openStore(t)
+	sess, err := db.CreateSession(nil)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	a, err := store.Put(sess.ID, "http", numberedLines(300))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	tool := artifacts.NewRecallTool(store)
+	ctx := tools.WithSessionID(context.Background(), sess.ID)
+
+	res, err := tool.Execute(ctx, map[string]any{"handle": a.ID, "offset": float64(250)})
+	if err != nil || res.IsError {
+		t.Fatalf("recall: %v %+v", err, res)
+	}
+	if !strings.Contains(res.Content, "line 250\n") || !strings.Contains(res.Content, "line 300\n") {
+		t.Errorf("recall by offset:\n%s", res.Content)
+	}
+
+	res, _ = tool.Execute(ctx, map[string]any{"handle": a.ID, "query": "line 123"})
+	if !strings.Contains(res.Content, "line 123\n") || !strings.Contains(res.Content, "1 matching lines") {
+		t.Errorf("recall by query:\n%s", res.Content)
+	}
+
+	res, _ = tool.Execute(ctx, map[string]any{})
+	if !strings.Contains(res.Content, a.ID) {
+		t.Errorf("listing should include the artifact:\n%s", res.Content)
+	}
+
+	res, _ = tool.Execute(tools.WithSessionID(context.Background(), "other"), map[string]any{"handle": a.ID})
+	if !res.IsError {
+		t.Error("artifacts of other sessions must not be readable")
+	}
+}
@@ -0,0 +1,128 @@
+package artifacts
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxPageBytes caps the text returned by one recall.
+	maxPageBytes = 16 * 1024
+	// maxLineBytes cuts single lines (minified JSON, base64) so one line
+	// cannot take the whole page; byte_offset reaches the rest.
+	maxLineBytes = 2000
+	// searchContext is the number of lines shown around each search hit.
+	searchContext = 2
+	// maxSearchHits caps the hits returned by one search.
+	maxSearchHits = 20
+)
+
+// Lines returns up to limit lines starting at the 1-based line offset,
+// numbered like `cat -n`. The page stops early at maxPageBytes; the footer
+// says where to continue.
+func Lines(a *Artifact, offset, limit int) string {
+	if offset < 1 {
+		offset = 1
+	}
+	lines := splitLines(a.Content)
+	if offset > len(lines) {
+		return fmt.Sprintf("[%s has %d lines; offset %d is past the end.]", a.ID, len(lines), offset)
+	}
+
+	var b strings.Builder
+	last := offset - 1
+	for i := offset - 1; i < len(lines) && i < offset-1+limit; i++ {
+		line := numberLine(i+1, lines[i])
+		if b.Len() > 0 && b.Len()+len(line) > maxPageBytes {
+			break
+		}
+		b.WriteString(line)
+		last = i + 1
+	}
+	if last < len(lines) {
+		fmt.Fprintf(&b, "[Lines %d-%d of %d. Continue with offset=%d.]", offset, last, len(lines), last+1)
+	} else {
+		fmt.Fprintf(&b, "[Lines %d-%d of %d. End of artifact.]", offset, last, len(lines))
+	}
+	return b.String()
+}
+
+// Bytes returns up to limit raw bytes starting at byteOffset.
+func Bytes(a *Artifact, byteOffset, limit int) string {
+	if byteOffset < 0 {
+		byteOffset = 0
+	}
+	if byteOffset >= len(a.Content) {
+		return fmt.Sprintf("[%s has %d bytes; byte_offset %d is past the end.]", a.ID, len(a.Content), byteOffset)
+	}
+	if limit <= 0 || limit > maxPageBytes {
+		limit = maxPageBytes
+	}
+	end := min(byteOffset+limit, len(a.Content))
+	chunk := strings.ToValidUTF8(a.Content[byteOffset:end], "")
+	if end < len(a.Content) {
+		return fmt.Sprintf("%s\n[Bytes %d-%d of %d. Continue with byte_offset=%d.]", chunk, byteOffset, end, len(a.Content), end)
+	}
+	return fmt.Sprintf("%s\n[Bytes %d-%d of %d. End of artifact.]", chunk, byteOffset, end, len(a.Content))
+}
+
+// Search returns the lines containing query (case-insensitive) with
+// searchContext lines around each hit. Overlapping windows are merged.
+func Search(a *Artifact, query string) string {
+	needle := strings.ToLower(query)
+	lines := splitLines(a.Content)
+
+	var hits []int
+	for i, line := range lines {
+		if strings.Contains(strings.ToLower(line), needle) {
+			hits = append(hits, i)
+		}
+	}
+	if len(hits) == 0 {
+		return fmt.Sprintf("[No lines of %s match %q.]", a.ID, query)
+	}
+
+	var b strings.Builder
+	shown := hits
+	if len(shown) > maxSearchHits {
+		shown = shown[:maxSearchHits]
+	}
+	end := -1 // last line written
+	for _, h := range shown {
+		from := max(h-searchContext, end+1)
+		to := min(h+searchContext, len(lines)-1)
+		if from > end+1 && end >= 0 {
+			b.WriteString("--\n")
+		}
+		for i := from; i <= to; i++ {
+			b.WriteString(numberLine(i+1, lines[i]))
+		}
+		end = to
+		if b.Len() > maxPageBytes {
+			break
+		}
+	}
+	if len(hits) > len(shown) {
+		fmt.Fprintf(&b, "[%d matching lines, first %d shown. Narrow the query or page with offset.]", len(hits), len(shown))
+	} else {
+		fmt.Fprintf(&b, "[%d matching lines.]", len(hits))
+	}
+	return b.String()
+}
+
+// splitLines splits content into lines without their terminators.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// numberLine formats a line with its number, cutting very long lines.
+func numberLine(n int, line string) string {
+	if len(line) > maxLineBytes {
+		line = strings.ToValidUTF8(line[:maxLineBytes], "") +
+			fmt.Sprintf(" [... line cut, %d bytes; use byte_offset to read it]", len(line))
+	}
+	return fmt.Sprintf("%6d\t%s\n", n, line)
+}
@@ -0,0 +1,150 @@
+// Package artifacts keeps oversized tool results out of the context window.
+//
+// Instead of truncating a large result (and losing the rest for good), the
+// runner stores it as a per-session artifact and gives the model a short
+// stub with a handle. The model pages the content back in with the
+// recall_artifact tool, by line range, byte range or search.
+package artifacts
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned when an artifact does not exist in the session.
+var ErrNotFound = errors.New("artifact not found")
+
+// Artifact is a stored tool result.
+type Artifact struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Tool      string    `json:"tool"`
+	Content   string    `json:"content,omitempty"`
+	Size      int       `json:"size"`
+	Lines     int       `json:"lines"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store persists artifacts in the artifacts table. Artifacts are deleted
+// together with their session.
+type Store struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewStore creates a store on db.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db, now: time.Now}
+}
+
+// Put stores content produced by tool in the session.
+func (s *Store) Put(sessionID, tool, content string) (*Artifact, error) {
+	a := &Artifact{
+		ID:        "art_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:12],
+		SessionID: sessionID,
+		Tool:      tool,
+		Content:   content,
+		Size:      len(content),
+		Lines:     countLines(content),
+		CreatedAt: s.now(),
+	}
+	_, err := s.db.Exec(
+		`INSERT INTO artifacts (id, session_id, tool, content, size, lines, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		a.ID, a.SessionID, a.Tool, a.Content, a.Size, a.Lines, a.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert artifact: %w", err)
+	}
+	return a, nil
+}
+
+// Get returns an artifact of the session, including its content.
+func (s *Store) Get(sessionID, id string) (*Artifact, error) {
+	a := &Artifact{}
+	err := s.db.QueryRow(
+		`SELECT id, session_id, tool, content, size, lines, created_at
+		 FROM artifacts WHERE id = ? AND session_id = ?`,
+		id, sessionID,
+	).Scan(&a.ID, &a.SessionID, &a.Tool, &a.Content, &a.Size, &a.Lines, &a.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get artifact: %w", err)
+	}
+	return a, nil
+}
+
+// List returns the artifacts of a session without their content, oldest
+// first.
+func (s *Store) List(sessionID string) ([]*Artifact, error) {
+	rows, err := s.db.Query(
+		`SELECT id, session_id, tool, size, lines, created_at
+		 FROM artifacts WHERE session_id = ? ORDER BY created_at, id`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list artifacts: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Artifact
+	for rows.Next() {
+		a := &Artifact{}
+		if err := rows.Scan(&a.ID, &a.SessionID, &a.Tool, &a.Size, &a.Lines, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan artifact: %w", err)
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// Offload stores content and returns the stub that replaces it in the
+// conversation: a preview of the first previewBytes and the handle to
+// recall the rest with.
+func (s *Store) Offload(sessionID, tool, content string, previewBytes int) (string, error) {
+	a, err := s.Put(sessionID, tool, content)
+	if err != nil {
+		return "", err
+	}
+	return Stub(a, previewBytes), nil
+}
+
+// Stub renders the placeholder for a stored artifact.
+func Stub(a *Artifact, previewBytes int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Result of %s stored as artifact %s: %d bytes, %d lines. Only the beginning is shown.]\n",
+		a.Tool, a.ID, a.Size, a.Lines)
+	if previewBytes > 0 {
+		preview := a.Content
+		if len(preview) > previewBytes {
+			preview = preview[:previewBytes]
+			// Cut at a line boundary when there is one
+			if i := strings.LastIndexByte(preview, '\n'); i > 0 {
+				preview = preview[:i]
+			}
+		}
+		b.WriteString(strings.ToValidUTF8(preview, ""))
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "[Use %s with handle=%q and offset/limit (lines) or query to read the rest.]", RecallToolName, a.ID)
+	return b.String()
+}
+
+// countLines counts lines the way Lines numbers them.
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	n := strings.Count(s, "\n")
+	if !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
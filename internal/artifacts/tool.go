@@ -0,0 +1,98 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"mote/internal/tools"
+)
+
+// RecallToolName is the name of the tool that reads artifacts back.
+const RecallToolName = "recall_artifact"
+
+// defaultRecallLines is the number of lines returned when limit is unset.
+const defaultRecallLines = 200
+
+// RecallArgs defines the parameters for the recall_artifact tool.
+type RecallArgs struct {
+	Handle     string `json:"handle" jsonschema:"description=Artifact handle from the stub (art_...). Leave empty to list the artifacts of this conversation"`
+	Offset     int    `json:"offset" jsonschema:"description=First line to read (1-based; default 1)"`
+	Limit      int    `json:"limit" jsonschema:"description=Number of lines to read (default 200)"`
+	Query      string `json:"query" jsonschema:"description=Return only the lines containing this text (case-insensitive) with surrounding lines"`
+	ByteOffset int    `json:"byte_offset" jsonschema:"description=Read raw bytes from this offset instead of lines (for content without line breaks)"`
+}
+
+// RecallTool pages stored tool results back into the conversation.
+type RecallTool struct {
+	tools.BaseTool
+	store *Store
+}
+
+// NewRecallTool creates the recall_artifact tool.
+func NewRecallTool(store *Store) *RecallTool {
+	return &RecallTool{
+		BaseTool: tools.BaseTool{
+			ToolName: RecallToolName,
+			ToolDescription: `Read a tool result that was too large for the conversation and was stored as an artifact.
+Large results are replaced by a stub naming their handle (art_...). Read them back by line range (offset/limit), by byte range (byte_offset) or search them with query instead of re-running the tool.`,
+			ToolParameters: tools.BuildSchema(RecallArgs{}),
+		},
+		store: store,
+	}
+}
+
+// Execute reads an artifact of the current session.
+func (t *RecallTool) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	sessionID, ok := tools.SessionIDFromContext(ctx)
+	if !ok || sessionID == "" {
+		return tools.NewErrorResult("recall_artifact needs a conversation session"), nil
+	}
+
+	handle, _ := args["handle"].(string)
+	handle = strings.TrimSpace(handle)
+	if handle == "" {
+		return t.list(sessionID)
+	}
+
+	a, err := t.store.Get(sessionID, handle)
+	if errors.Is(err, ErrNotFound) {
+		return tools.NewErrorResult(fmt.Sprintf("artifact %s not found in this conversation", handle)), nil
+	}
+	if err != nil {
+		return tools.NewErrorResult(err.Error()), nil
+	}
+
+	if query, _ := args["query"].(string); strings.TrimSpace(query) != "" {
+		return tools.NewSuccessResult(Search(a, strings.TrimSpace(query))), nil
+	}
+	if v, ok := args["byte_offset"].(float64); ok && v > 0 {
+		return tools.NewSuccessResult(Bytes(a, int(v), maxPageBytes)), nil
+	}
+	offset, limit := 1, defaultRecallLines
+	if v, ok := args["offset"].(float64); ok && v > 0 {
+		offset = int(v)
+	}
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = int(v)
+	}
+	return tools.NewSuccessResult(Lines(a, offset, limit)), nil
+}
+
+// list describes the artifacts of the session.
+func (t *RecallTool) list(sessionID string) (tools.ToolResult, error) {
+	list, err := t.store.List(sessionID)
+	if err != nil {
+		return tools.NewErrorResult(err.Error()), nil
+	}
+	if len(list) == 0 {
+		return tools.NewSuccessResult("No artifacts stored in this conversation."), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d artifact(s):\n", len(list))
+	for _, a := range list {
+		fmt.Fprintf(&b, "- %s: %s result, %d bytes, %d lines\n", a.ID, a.Tool, a.Size, a.Lines)
+	}
+	return tools.NewSuccessResult(b.String()), nil
+}
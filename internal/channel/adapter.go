@@ -0,0 +1,94 @@
+package channel
+
+import (
+	"strings"
+
+	"mote/pkg/channel"
+)
+
+// fenceMargin 拆分含代码块的消息时为补齐围栏预留的字符数
+const fenceMargin = 16
+
+// ChatAdapter 聊天类渠道（Telegram、Matrix、Slack）共用的触发判断和回复格式化
+type ChatAdapter struct {
+	Trigger channel.TriggerConfig
+	Reply   channel.ReplyConfig
+	// RequireMention 群聊中需提及机器人才处理
+	RequireMention bool
+	// MaxMessageLength 单条消息的字符上限，0 表示不拆分
+	MaxMessageLength int
+	// Format 把 Markdown 转换为平台格式，nil 表示原样发送
+	Format func(markdown string) string
+}
+
+// OutboundPart 拆分后的一条出站消息
+type OutboundPart struct {
+	Text      string // Markdown 原文，作为纯文本回退
+	Formatted string // 平台格式；未设置 Format 时与 Text 相同
+}
+
+// Accept 判断是否处理入站消息，命中触发前缀时会去掉前缀。
+// 私聊总是处理；群聊需提及机器人，除非关闭了 RequireMention。
+// 配置了触发前缀时，前缀是提及之外的另一种触发方式，未提及也未命中前缀则不处理。
+func (a ChatAdapter) Accept(msg *channel.InboundMessage) bool {
+	mentioned := msg.WasMentioned || msg.MessageType == channel.MessageTypeDM
+	if a.Trigger.Prefix != "" {
+		if result := channel.CheckTrigger(*msg, a.Trigger); result.ShouldProcess {
+			msg.Content = result.StrippedContent
+			return true
+		}
+		return mentioned
+	}
+	return mentioned || !a.RequireMention
+}
+
+// Outbound 加上回复前缀，按长度上限拆分后逐条转换格式
+func (a ChatAdapter) Outbound(content string) []OutboundPart {
+	content = channel.InjectReplyPrefix(content, a.Reply)
+	texts := []string{content}
+	if a.MaxMessageLength > 0 {
+		limit := a.MaxMessageLength
+		hasFence := strings.Contains(content, "```")
+		if hasFence && limit > 2*fenceMargin {
+			limit -= fenceMargin
+		}
+		texts = channel.SplitMessage(content, limit)
+		if hasFence {
+			texts = balanceFences(texts)
+		}
+	}
+	parts := make([]OutboundPart, 0, len(texts))
+	for _, text := range texts {
+		part := OutboundPart{Text: text, Formatted: text}
+		if a.Format != nil {
+			part.Formatted = a.Format(text)
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// balanceFences 代码块被拆到两条消息时，在前一条末尾补上结束围栏，后一条开头重新打开
+func balanceFences(parts []string) []string {
+	open := "" // 未闭合代码块的开始围栏，如 "```go"
+	for i, part := range parts {
+		if open != "" {
+			part = open + "\n" + part
+		}
+		open = ""
+		for _, line := range strings.Split(part, "\n") {
+			if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") {
+				if open == "" {
+					open = trimmed
+				} else {
+					open = ""
+				}
+			}
+		}
+		if open != "" {
+			part += "\n```"
+		}
+		parts[i] = part
+	}
+	return parts
+}
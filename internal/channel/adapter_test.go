@@ -0,0 +1,57 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+
+	"mote/pkg/channel"
+)
+
+func TestChatAdapter_Accept(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter ChatAdapter
+		msg     channel.InboundMessage
+		want    bool
+		content string
+	}{
+		{"dm", ChatAdapter{RequireMention: true}, channel.InboundMessage{MessageType: channel.MessageTypeDM, Content: "hi"}, true, "hi"},
+		{"group without mention", ChatAdapter{RequireMention: true}, channel.InboundMessage{MessageType: channel.MessageTypeGroup, Content: "hi"}, false, "hi"},
+		{"group with mention", ChatAdapter{RequireMention: true}, channel.InboundMessage{MessageType: channel.MessageTypeGroup, Content: "hi", WasMentioned: true}, true, "hi"},
+		{"group, mention not required", ChatAdapter{}, channel.InboundMessage{MessageType: channel.MessageTypeGroup, Content: "hi"}, true, "hi"},
+		{"prefix", ChatAdapter{RequireMention: true, Trigger: channel.TriggerConfig{Prefix: "!mote"}}, channel.InboundMessage{MessageType: channel.MessageTypeGroup, Content: "!mote deploy"}, true, "deploy"},
+		{"prefix required without mention", ChatAdapter{Trigger: channel.TriggerConfig{Prefix: "!mote"}}, channel.InboundMessage{MessageType: channel.MessageTypeGroup, Content: "deploy"}, false, "deploy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := tt.msg
+			if got := tt.adapter.Accept(&msg); got != tt.want || msg.Content != tt.content {
+				t.Errorf("Accept = %v, content %q", got, msg.Content)
+			}
+		})
+	}
+}
+
+func TestChatAdapter_Outbound(t *testing.T) {
+	a := ChatAdapter{Reply: channel.ReplyConfig{Prefix: "[Mote]"}, Format: MarkdownToSlack}
+	parts := a.Outbound("**done**")
+	if len(parts) != 1 || parts[0].Text != "[Mote]\n**done**" || parts[0].Formatted != "[Mote]\n*done*" {
+		t.Errorf("parts = %+v", parts)
+	}
+
+	// A code block split across messages is closed and reopened
+	a = ChatAdapter{MaxMessageLength: 60}
+	code := "```go\n" + strings.Repeat("x := 1\n", 12) + "```"
+	parts = a.Outbound(code)
+	if len(parts) < 2 {
+		t.Fatalf("expected a split, got %+v", parts)
+	}
+	for _, p := range parts {
+		if len([]rune(p.Text)) > 60 || strings.Count(p.Text, "```")%2 != 0 {
+			t.Errorf("unbalanced or oversized part %q", p.Text)
+		}
+	}
+	if !strings.HasPrefix(parts[1].Text, "```go\n") {
+		t.Errorf("second part does not reopen the fence: %q", parts[1].Text)
+	}
+}
@@ -0,0 +1,54 @@
+package channel
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"mote/internal/provider"
+)
+
+// AttachmentsKey InboundMessage.Metadata 中存放 []provider.Attachment 的键
+const AttachmentsKey = "attachments"
+
+// SupportedAttachment 判断附件能否交给模型：图片或文本类文件
+func SupportedAttachment(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "image/") || isTextMime(mimeType)
+}
+
+// BuildAttachment 把渠道收到的文件转换为 provider.Attachment，不支持的类型返回 false
+func BuildAttachment(name, mimeType string, data []byte) (provider.Attachment, bool) {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return provider.Attachment{
+			Type:     "image_url",
+			ImageURL: &provider.ImageURL{URL: fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))},
+			Filename: name,
+			MimeType: mimeType,
+			Size:     len(data),
+		}, true
+	case isTextMime(mimeType):
+		return provider.Attachment{
+			Type:     "text",
+			Text:     string(data),
+			Filename: name,
+			MimeType: mimeType,
+			Size:     len(data),
+			Metadata: map[string]any{"filepath": name},
+		}, true
+	}
+	return provider.Attachment{}, false
+}
+
+// isTextMime 判断文件能否作为文本交给模型
+func isTextMime(mimeType string) bool {
+	if strings.HasPrefix(mimeType, "text/") {
+		return true
+	}
+	switch mimeType {
+	case "application/json", "application/xml", "application/x-yaml", "application/yaml",
+		"application/javascript", "application/x-sh", "application/toml", "application/sql":
+		return true
+	}
+	return false
+}
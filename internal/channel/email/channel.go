@@ -0,0 +1,526 @@
+// Package email implements the email channel plugin: it reads an IMAP folder
+// and replies over SMTP.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	internalChannel "mote/internal/channel"
+	"mote/pkg/channel"
+)
+
+const (
+	defaultFolder             = "INBOX"
+	defaultPollInterval       = time.Minute
+	defaultMaxAttachmentBytes = 10 * 1024 * 1024
+	// idleTimeout RFC 2177 建议 29 分钟内重新发起 IDLE
+	idleTimeout   = 25 * time.Minute
+	retryInterval = 30 * time.Second
+	// threadTTL 线程回复信息的保留时间
+	threadTTL = 7 * 24 * time.Hour
+)
+
+// Config 邮件渠道配置
+type Config struct {
+	Trigger channel.TriggerConfig `json:"trigger"`
+	Reply   channel.ReplyConfig   `json:"reply"`
+
+	IMAPAddr     string        `json:"imapAddr"` // host:port
+	IMAPTLS      bool          `json:"imapTls"`  // 隐式 TLS（993）；为 false 时服务器支持则使用 STARTTLS
+	Username     string        `json:"username"`
+	Password     string        `json:"password"`
+	Folder       string        `json:"folder"`
+	PollInterval time.Duration `json:"pollInterval"`
+	UseIdle      bool          `json:"useIdle"` // 服务器支持时使用 IDLE 代替轮询
+
+	SMTPAddr     string `json:"smtpAddr"` // host:port
+	SMTPTLS      bool   `json:"smtpTls"`  // 隐式 TLS（465）；为 false 时服务器支持则使用 STARTTLS
+	SMTPUsername string `json:"smtpUsername"`
+	SMTPPassword string `json:"smtpPassword"`
+	From         string `json:"from"` // 发件地址，默认为 Username
+
+	// AllowFrom 允许的发件人地址或域名（"@example.com"），必填
+	AllowFrom []string `json:"allowFrom"`
+	// RequireAuthenticated 要求收件服务器验证过发件人域名（DMARC，或与发件人域名对齐的 DKIM）
+	RequireAuthenticated bool `json:"requireAuthenticated"`
+	// AuthServID 收件服务器在 Authentication-Results 中的 authserv-id，如 "mx.example.com"；
+	// 只信任它写下的验证结果
+	AuthServID         string `json:"authServId"`
+	MaxAttachmentBytes int    `json:"maxAttachmentBytes"`
+}
+
+// thread 回复一个邮件线程需要的信息
+type thread struct {
+	to         string
+	subject    string
+	messageID  string
+	references []string
+	updated    time.Time
+}
+
+// emailChannel 邮件渠道实现
+type emailChannel struct {
+	config  Config
+	handler channel.MessageHandler
+	mu      sync.RWMutex
+
+	threads map[string]*thread
+	skipped map[uint32]bool // 不处理的邮件 UID，保持未读
+
+	cancel  context.CancelFunc
+	done    chan struct{}
+	running bool
+
+	// 用于测试替换
+	dial     func(ctx context.Context, cfg Config) (mailbox, error)
+	sendMail func(from string, to []string, msg []byte) error
+}
+
+// New 创建新的邮件渠道
+func New(cfg Config) *emailChannel {
+	if cfg.Folder == "" {
+		cfg.Folder = defaultFolder
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.MaxAttachmentBytes <= 0 {
+		cfg.MaxAttachmentBytes = defaultMaxAttachmentBytes
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	if cfg.SMTPUsername == "" {
+		cfg.SMTPUsername, cfg.SMTPPassword = cfg.Username, cfg.Password
+	}
+	c := &emailChannel{
+		config:  cfg,
+		threads: make(map[string]*thread),
+		skipped: make(map[uint32]bool),
+		dial:    dialIMAP,
+	}
+	c.sendMail = c.smtpSend
+	return c
+}
+
+// ID 返回渠道唯一标识
+func (c *emailChannel) ID() channel.ChannelType {
+	return channel.ChannelTypeEmail
+}
+
+// Name 返回渠道显示名称
+func (c *emailChannel) Name() string {
+	return "Email"
+}
+
+// Capabilities 返回渠道能力
+func (c *emailChannel) Capabilities() channel.ChannelCapabilities {
+	return channel.ChannelCapabilities{
+		CanSendText:      true,
+		CanSendMedia:     false,
+		CanDetectMention: false,
+		CanWatch:         true,
+	}
+}
+
+// Start 开始监听邮箱
+func (c *emailChannel) Start(ctx context.Context) error {
+	if c.config.IMAPAddr == "" || c.config.SMTPAddr == "" {
+		return fmt.Errorf("email channel requires imap_addr and smtp_addr")
+	}
+	// 任何人都能发邮件，必须限定发件人
+	if len(c.config.AllowFrom) == 0 {
+		return fmt.Errorf("email channel requires allow_from")
+	}
+	// From 头可以伪造，没有可信的验证结果就无法确认发件人
+	if c.config.RequireAuthenticated && c.config.AuthServID == "" {
+		return fmt.Errorf("email channel requires auth_serv_id when require_authenticated is set")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return nil
+	}
+
+	// 先连接一次以尽早暴露配置错误
+	mb, err := c.dial(ctx, c.config)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	c.running = true
+	go c.run(runCtx, mb, c.done)
+
+	slog.Info("email channel started", "imap", c.config.IMAPAddr, "folder", c.config.Folder)
+	return nil
+}
+
+// Stop 停止监听，之后可以再次 Start
+func (c *emailChannel) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	c.running = false
+	cancel, done := c.cancel, c.done
+	c.mu.Unlock()
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnMessage 注册消息回调
+func (c *emailChannel) OnMessage(handler channel.MessageHandler) {
+	c.mu.Lock()
+	c.handler = handler
+	c.mu.Unlock()
+}
+
+// SendMessage 在邮件线程中回复发件人
+func (c *emailChannel) SendMessage(ctx context.Context, msg channel.OutboundMessage) error {
+	c.mu.RLock()
+	t := c.threads[msg.ChatID]
+	c.mu.RUnlock()
+	if t == nil {
+		return fmt.Errorf("unknown email thread: %s", msg.ChatID)
+	}
+
+	content := channel.InjectReplyPrefix(msg.Content, c.config.Reply)
+	raw, err := c.compose(t, content)
+	if err != nil {
+		return err
+	}
+	if err := c.sendMail(c.config.From, []string{t.to}, raw); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+	return nil
+}
+
+// run 处理邮箱直到 ctx 取消，连接断开时重连
+func (c *emailChannel) run(ctx context.Context, mb mailbox, done chan struct{}) {
+	defer close(done)
+	for {
+		if mb != nil {
+			err := c.serve(ctx, mb)
+			_ = mb.close()
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("email mailbox connection lost", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryInterval):
+		}
+		var err error
+		if mb, err = c.dial(ctx, c.config); err != nil {
+			slog.Warn("email reconnect failed", "error", err)
+			mb = nil
+		}
+	}
+}
+
+// serve 处理未读邮件，然后等待新邮件
+func (c *emailChannel) serve(ctx context.Context, mb mailbox) error {
+	for {
+		uids, err := mb.unseen()
+		if err != nil {
+			return err
+		}
+		for _, uid := range uids {
+			if c.isSkipped(uid) {
+				continue
+			}
+			raw, err := mb.fetch(uid)
+			if err != nil {
+				return err
+			}
+			inbound, ok := c.accept(raw)
+			if !ok {
+				// 保持未读，留给人工处理
+				c.mu.Lock()
+				c.skipped[uid] = true
+				c.mu.Unlock()
+				continue
+			}
+			// 先标记已读，避免重连后重复处理
+			if err := mb.markSeen(uid); err != nil {
+				return err
+			}
+			go c.dispatch(ctx, inbound)
+		}
+
+		if c.config.UseIdle && mb.supportsIdle() {
+			if err := mb.idle(ctx, idleTimeout); err != nil {
+				return err
+			}
+		} else {
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.config.PollInterval):
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (c *emailChannel) isSkipped(uid uint32) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.skipped[uid]
+}
+
+func (c *emailChannel) dispatch(ctx context.Context, inbound channel.InboundMessage) {
+	c.mu.RLock()
+	handler := c.handler
+	c.mu.RUnlock()
+	if handler == nil {
+		return
+	}
+	if err := handler(ctx, inbound); err != nil {
+		slog.Warn("email message handler failed", "thread", inbound.ChatID, "error", err)
+	}
+}
+
+// accept 解析邮件并检查发件人，返回要交给 handler 的入站消息
+func (c *emailChannel) accept(raw []byte) (channel.InboundMessage, bool) {
+	m, err := parseMail(raw, c.config.MaxAttachmentBytes)
+	if err != nil {
+		slog.Warn("email parse failed", "error", err)
+		return channel.InboundMessage{}, false
+	}
+	sender := strings.ToLower(m.From.Address)
+	if m.AutoGenerated || strings.EqualFold(sender, c.config.From) {
+		slog.Debug("email skipping automatic or own message", "from", sender, "subject", m.Subject)
+		return channel.InboundMessage{}, false
+	}
+	if !c.allowed(sender) {
+		slog.Debug("email skipping message from unauthorized sender", "from", sender)
+		return channel.InboundMessage{}, false
+	}
+	if c.config.RequireAuthenticated && !m.authenticated(c.config.AuthServID) {
+		slog.Warn("email skipping unauthenticated message", "from", sender)
+		return channel.InboundMessage{}, false
+	}
+
+	// 新线程把主题作为任务的一部分
+	content := m.Body
+	if m.InReplyTo == "" && m.Subject != "" {
+		content = strings.TrimSpace(m.Subject + "\n\n" + m.Body)
+	}
+	for _, name := range m.Skipped {
+		content += fmt.Sprintf("\n[attachment %s is not supported]", name)
+	}
+
+	timestamp := m.Date
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	name := m.From.Name
+	if name == "" {
+		name = m.From.Address
+	}
+	inbound := channel.InboundMessage{
+		ID:           m.MessageID,
+		ChannelType:  channel.ChannelTypeEmail,
+		MessageType:  channel.MessageTypeDM,
+		ChatID:       threadID(m.threadRoot()),
+		SenderID:     sender,
+		SenderName:   name,
+		Content:      strings.TrimSpace(content),
+		RawContent:   m.Body,
+		Timestamp:    timestamp,
+		Metadata:     map[string]any{"subject": m.Subject},
+		WasMentioned: true,
+	}
+	if len(m.Attachments) > 0 {
+		inbound.Metadata[internalChannel.AttachmentsKey] = m.Attachments
+	}
+
+	if c.config.Trigger.Prefix != "" {
+		result := channel.CheckTrigger(inbound, c.config.Trigger)
+		if !result.ShouldProcess {
+			return channel.InboundMessage{}, false
+		}
+		inbound.Content = result.StrippedContent
+	}
+	if inbound.Content == "" && len(m.Attachments) == 0 {
+		return channel.InboundMessage{}, false
+	}
+
+	c.remember(inbound.ChatID, m)
+	return inbound, true
+}
+
+// allowed 按完整地址或 "@域名" 匹配发件人
+func (c *emailChannel) allowed(sender string) bool {
+	for _, a := range c.config.AllowFrom {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" {
+			continue
+		}
+		if a == sender || (strings.HasPrefix(a, "@") && strings.HasSuffix(sender, a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// remember 记录线程中最新一封邮件，用于回复
+func (c *emailChannel) remember(chatID string, m *parsedMail) {
+	to := m.From.Address
+	if m.ReplyTo != nil {
+		to = m.ReplyTo.Address
+	}
+	refs := append([]string{}, m.References...)
+	if len(refs) == 0 && m.InReplyTo != "" {
+		refs = append(refs, m.InReplyTo)
+	}
+	if m.MessageID != "" {
+		refs = append(refs, m.MessageID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for id, t := range c.threads {
+		if now.Sub(t.updated) > threadTTL {
+			delete(c.threads, id)
+		}
+	}
+	c.threads[chatID] = &thread{to: to, subject: m.Subject, messageID: m.MessageID, references: refs, updated: now}
+}
+
+// compose 生成回复邮件
+func (c *emailChannel) compose(t *thread, body string) ([]byte, error) {
+	subject := t.subject
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+
+	var buf bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", c.config.From)
+	header("To", t.to)
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", newMessageID(c.config.From))
+	if t.messageID != "" {
+		header("In-Reply-To", t.messageID)
+	}
+	if len(t.references) > 0 {
+		header("References", strings.Join(t.references, " "))
+	}
+	// 标记为自动回复，避免对方的自动回复形成循环
+	header("Auto-Submitted", "auto-replied")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// smtpSend 通过 SMTP 发送邮件
+func (c *emailChannel) smtpSend(from string, to []string, msg []byte) error {
+	host, _, _ := net.SplitHostPort(c.config.SMTPAddr)
+	var auth smtp.Auth
+	if c.config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", c.config.SMTPUsername, c.config.SMTPPassword, host)
+	}
+	if !c.config.SMTPTLS {
+		// 服务器支持时 SendMail 会自动 STARTTLS
+		return smtp.SendMail(c.config.SMTPAddr, auth, from, to, msg)
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", c.config.SMTPAddr, &tls.Config{ServerName: host})
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9._@+=-]`)
+
+// threadID 由线程首封邮件的 Message-ID 生成可用于会话 ID 的标识
+func threadID(messageID string) string {
+	id := strings.Trim(messageID, "<>")
+	if id == "" {
+		id = strings.Trim(newMessageID("mote"), "<>")
+	}
+	return unsafeIDChars.ReplaceAllString(id, "_")
+}
+
+func newMessageID(from string) string {
+	domain := "mote.local"
+	if i := strings.LastIndexByte(from, '@'); i >= 0 && i < len(from)-1 {
+		domain = from[i+1:]
+	}
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
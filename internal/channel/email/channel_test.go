@@ -0,0 +1,248 @@
+package email
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	internalChannel "mote/internal/channel"
+	"mote/internal/provider"
+	"mote/pkg/channel"
+)
+
+const taskMail = "From: Ann <ann@example.com>\r\n" +
+	"To: mote@example.com\r\n" +
+	"Subject: =?utf-8?q?Deploy_r=C3=A9sum=C3=A9_site?=\r\n" +
+	"Message-ID: <task-1@example.com>\r\n" +
+	"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=XYZ\r\n" +
+	"\r\n" +
+	"--XYZ\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Please deploy to staging =3D today.\r\n" +
+	"--XYZ\r\n" +
+	"Content-Type: text/csv; name=\"hosts.csv\"\r\n" +
+	"Content-Disposition: attachment; filename=\"hosts.csv\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"aG9zdCxwb3J0CmEsMjIK\r\n" +
+	"--XYZ\r\n" +
+	"Content-Type: application/zip\r\n" +
+	"Content-Disposition: attachment; filename=\"site.zip\"\r\n" +
+	"\r\n" +
+	"PK\r\n" +
+	"--XYZ--\r\n"
+
+func TestParseMail(t *testing.T) {
+	m, err := parseMail([]byte(taskMail), 1024)
+	if err != nil {
+		t.Fatalf("parseMail failed: %v", err)
+	}
+	if m.Subject != "Deploy résumé site" || m.Body != "Please deploy to staging = today." {
+		t.Errorf("subject %q, body %q", m.Subject, m.Body)
+	}
+	if len(m.Attachments) != 1 || m.Attachments[0].Type != "text" || m.Attachments[0].Text != "host,port\na,22\n" {
+		t.Errorf("attachments: %+v", m.Attachments)
+	}
+	if len(m.Skipped) != 1 || m.Skipped[0] != "site.zip" {
+		t.Errorf("skipped: %v", m.Skipped)
+	}
+	if m.threadRoot() != "<task-1@example.com>" {
+		t.Errorf("thread root = %s", m.threadRoot())
+	}
+
+	reply := "From: ann@example.com\r\n" +
+		"Subject: Re: Deploy\r\n" +
+		"Message-ID: <task-3@example.com>\r\n" +
+		"In-Reply-To: <reply-2@example.com>\r\n" +
+		"References: <task-1@example.com> <reply-2@example.com>\r\n" +
+		"Auto-Submitted: auto-replied\r\n" +
+		"\r\n" +
+		"Thanks, ship it.\r\n\r\nOn Mon, 2 Jan 2006, Mote <mote@example.com> wrote:\r\n> Done.\r\n"
+	m, err = parseMail([]byte(reply), 1024)
+	if err != nil {
+		t.Fatalf("parseMail reply failed: %v", err)
+	}
+	if m.Body != "Thanks, ship it." || m.threadRoot() != "<task-1@example.com>" || !m.AutoGenerated {
+		t.Errorf("reply parsed as %+v", m)
+	}
+}
+
+type fakeMailbox struct {
+	mu       sync.Mutex
+	messages map[uint32][]byte
+	seen     map[uint32]bool
+}
+
+func (f *fakeMailbox) unseen() ([]uint32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var uids []uint32
+	for uid := range f.messages {
+		if !f.seen[uid] {
+			uids = append(uids, uid)
+		}
+	}
+	return uids, nil
+}
+
+func (f *fakeMailbox) fetch(uid uint32) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.messages[uid], nil
+}
+
+func (f *fakeMailbox) markSeen(uid uint32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seen[uid] = true
+	return nil
+}
+
+func (f *fakeMailbox) supportsIdle() bool { return false }
+
+func (f *fakeMailbox) idle(ctx context.Context, timeout time.Duration) error { return nil }
+
+func (f *fakeMailbox) close() error { return nil }
+
+func TestEmailChannel_ReceiveAndReply(t *testing.T) {
+	mb := &fakeMailbox{
+		messages: map[uint32][]byte{
+			1: []byte(taskMail),
+			2: []byte("From: eve@evil.test\r\nSubject: wire money\r\nMessage-ID: <x@evil.test>\r\n\r\nnow\r\n"),
+		},
+		seen: map[uint32]bool{},
+	}
+	ch := New(Config{
+		IMAPAddr:     "imap.example.com:993",
+		SMTPAddr:     "smtp.example.com:587",
+		Username:     "mote@example.com",
+		AllowFrom:    []string{"@example.com"},
+		PollInterval: 20 * time.Millisecond,
+		Reply:        channel.ReplyConfig{Prefix: "[Mote]"},
+	})
+	ch.dial = func(ctx context.Context, cfg Config) (mailbox, error) { return mb, nil }
+
+	type sent struct {
+		to  []string
+		msg []byte
+	}
+	sentCh := make(chan sent, 1)
+	ch.sendMail = func(from string, to []string, msg []byte) error {
+		sentCh <- sent{to, msg}
+		return nil
+	}
+
+	received := make(chan channel.InboundMessage, 2)
+	ch.OnMessage(func(ctx context.Context, msg channel.InboundMessage) error {
+		received <- msg
+		return ch.SendMessage(ctx, channel.OutboundMessage{ChatID: msg.ChatID, Content: "Deployed.", ReplyToID: msg.ID})
+	})
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer ch.Stop(context.Background())
+
+	var msg channel.InboundMessage
+	select {
+	case msg = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+	if msg.ChatID != "task-1@example.com" || msg.SenderID != "ann@example.com" || msg.ID != "<task-1@example.com>" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	if !strings.HasPrefix(msg.Content, "Deploy résumé site\n\nPlease deploy") || !strings.Contains(msg.Content, "site.zip is not supported") {
+		t.Errorf("content = %q", msg.Content)
+	}
+	if atts, _ := msg.Metadata[internalChannel.AttachmentsKey].([]provider.Attachment); len(atts) != 1 {
+		t.Errorf("attachments = %v", msg.Metadata[internalChannel.AttachmentsKey])
+	}
+
+	var out sent
+	select {
+	case out = <-sentCh:
+	case <-time.After(time.Second):
+		t.Fatal("no reply sent")
+	}
+	ch.Stop(context.Background())
+
+	if len(out.to) != 1 || out.to[0] != "ann@example.com" {
+		t.Fatalf("reply sent to %v", out.to)
+	}
+	reply, err := mail.ReadMessage(strings.NewReader(string(out.msg)))
+	if err != nil {
+		t.Fatalf("reply is not a valid message: %v", err)
+	}
+	if reply.Header.Get("In-Reply-To") != "<task-1@example.com>" || reply.Header.Get("References") != "<task-1@example.com>" {
+		t.Errorf("threading headers: %v", reply.Header)
+	}
+	if subject := decodeHeader(reply.Header.Get("Subject")); subject != "Re: Deploy résumé site" {
+		t.Errorf("subject = %q", subject)
+	}
+	if reply.Header.Get("Auto-Submitted") != "auto-replied" {
+		t.Error("reply should be marked Auto-Submitted")
+	}
+
+	// The stranger's mail is left unread and never reaches the handler
+	select {
+	case msg := <-received:
+		t.Errorf("unexpected message: %+v", msg)
+	default:
+	}
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	if mb.seen[2] || !mb.seen[1] {
+		t.Errorf("seen flags = %v", mb.seen)
+	}
+}
+
+func TestEmailChannel_RequiresAllowList(t *testing.T) {
+	ch := New(Config{IMAPAddr: "imap:993", SMTPAddr: "smtp:587"})
+	if err := ch.Start(context.Background()); err == nil {
+		t.Error("Start should fail without allow_from")
+	}
+}
+
+func TestParsedMail_Authenticated(t *testing.T) {
+	const trusted = "mx.example.org"
+	tests := []struct {
+		name    string
+		headers []string
+		want    bool
+	}{
+		{"dmarc pass", []string{"mx.example.org; spf=pass smtp.mailfrom=example.com; dmarc=pass (p=reject) header.from=example.com"}, true},
+		{"aligned dkim", []string{"mx.example.org 1; dkim=pass (2048-bit key) header.d=example.com header.s=s1"}, true},
+		{"subdomain sender", []string{"mx.example.org; dkim=pass header.d=example.com"}, true},
+		{"other domain dkim", []string{"mx.example.org; dkim=pass header.d=attacker.test; dmarc=fail header.from=example.com"}, false},
+		{"untrusted server", []string{"mx.attacker.test; dmarc=pass header.from=example.com"}, false},
+		{"forged header below", []string{
+			"mx.example.org; dkim=none; dmarc=fail header.from=example.com",
+			"mx.example.org; dmarc=pass header.from=example.com",
+		}, false},
+		{"no results", nil, false},
+	}
+	for _, tt := range tests {
+		m := &parsedMail{From: &mail.Address{Address: "ann@example.com"}, AuthResults: tt.headers}
+		if tt.name == "subdomain sender" {
+			m.From.Address = "ann@eu.example.com"
+		}
+		if got := m.authenticated(trusted); got != tt.want {
+			t.Errorf("%s: authenticated = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if (&parsedMail{From: &mail.Address{Address: "ann@example.com"}, AuthResults: []string{"mx.example.org; dmarc=pass"}}).authenticated("") {
+		t.Error("results without a configured authserv-id should not be trusted")
+	}
+
+	ch := New(Config{IMAPAddr: "imap:993", SMTPAddr: "smtp:587", AllowFrom: []string{"@example.com"}, RequireAuthenticated: true})
+	if err := ch.Start(context.Background()); err == nil {
+		t.Error("Start should fail without auth_serv_id when authentication is required")
+	}
+}
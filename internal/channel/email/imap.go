@@ -0,0 +1,308 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mailbox 渠道使用的邮箱操作，便于测试时替换
+type mailbox interface {
+	// unseen 返回未读邮件的 UID
+	unseen() ([]uint32, error)
+	// fetch 返回完整的 RFC 822 邮件，不改变已读状态
+	fetch(uid uint32) ([]byte, error)
+	// markSeen 标记为已读
+	markSeen(uid uint32) error
+	// supportsIdle 服务器是否支持 IDLE
+	supportsIdle() bool
+	// idle 等待新邮件，直到有新邮件、超时或 ctx 取消
+	idle(ctx context.Context, timeout time.Duration) error
+	close() error
+}
+
+// imapClient 最小的 IMAP4rev1 客户端，只实现渠道需要的命令
+type imapClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+	tag  int
+	caps map[string]bool
+}
+
+// imapResponse 一条未标记响应，字面量（{n}）单独存放
+type imapResponse struct {
+	line     string
+	literals [][]byte
+}
+
+// dialIMAP 连接、登录并选择邮箱文件夹
+func dialIMAP(ctx context.Context, cfg Config) (mailbox, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	var err error
+	host, _, _ := net.SplitHostPort(cfg.IMAPAddr)
+	tlsConfig := &tls.Config{ServerName: host}
+	if cfg.IMAPTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", cfg.IMAPAddr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", cfg.IMAPAddr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect imap: %w", err)
+	}
+
+	c := newIMAPClient(conn)
+	if err := c.greeting(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := c.capability(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !cfg.IMAPTLS && c.caps["STARTTLS"] {
+		if _, err := c.cmd("STARTTLS"); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("imap starttls: %w", err)
+		}
+		c = newIMAPClient(tlsConn)
+		if err := c.capability(); err != nil {
+			tlsConn.Close()
+			return nil, err
+		}
+	}
+
+	if _, err := c.cmd("LOGIN %s %s", quote(cfg.Username), quote(cfg.Password)); err != nil {
+		c.close()
+		return nil, fmt.Errorf("imap login: %w", err)
+	}
+	// 登录后能力可能变化（如 IDLE）
+	if err := c.capability(); err != nil {
+		c.close()
+		return nil, err
+	}
+	if _, err := c.cmd("SELECT %s", quote(cfg.Folder)); err != nil {
+		c.close()
+		return nil, fmt.Errorf("imap select %s: %w", cfg.Folder, err)
+	}
+	return c, nil
+}
+
+func newIMAPClient(conn net.Conn) *imapClient {
+	return &imapClient{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn), caps: map[string]bool{}}
+}
+
+func (c *imapClient) greeting() error {
+	resp, err := c.readResponse()
+	if err != nil {
+		return fmt.Errorf("imap greeting: %w", err)
+	}
+	if !strings.HasPrefix(resp.line, "* OK") && !strings.HasPrefix(resp.line, "* PREAUTH") {
+		return fmt.Errorf("imap greeting: %s", resp.line)
+	}
+	return nil
+}
+
+func (c *imapClient) capability() error {
+	responses, err := c.cmd("CAPABILITY")
+	if err != nil {
+		return fmt.Errorf("imap capability: %w", err)
+	}
+	c.caps = map[string]bool{}
+	for _, r := range responses {
+		if fields := strings.Fields(r.line); len(fields) > 1 && strings.EqualFold(fields[1], "CAPABILITY") {
+			for _, f := range fields[2:] {
+				c.caps[strings.ToUpper(f)] = true
+			}
+		}
+	}
+	return nil
+}
+
+func (c *imapClient) unseen() ([]uint32, error) {
+	responses, err := c.cmd("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, fmt.Errorf("imap search: %w", err)
+	}
+	var uids []uint32
+	for _, r := range responses {
+		fields := strings.Fields(r.line)
+		if len(fields) < 2 || !strings.EqualFold(fields[1], "SEARCH") {
+			continue
+		}
+		for _, f := range fields[2:] {
+			if n, err := strconv.ParseUint(f, 10, 32); err == nil {
+				uids = append(uids, uint32(n))
+			}
+		}
+	}
+	return uids, nil
+}
+
+func (c *imapClient) fetch(uid uint32) ([]byte, error) {
+	responses, err := c.cmd("UID FETCH %d BODY.PEEK[]", uid)
+	if err != nil {
+		return nil, fmt.Errorf("imap fetch %d: %w", uid, err)
+	}
+	for _, r := range responses {
+		if strings.Contains(r.line, "FETCH") && len(r.literals) > 0 {
+			return r.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("imap fetch %d: message not found", uid)
+}
+
+func (c *imapClient) markSeen(uid uint32) error {
+	if _, err := c.cmd(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid); err != nil {
+		return fmt.Errorf("imap store %d: %w", uid, err)
+	}
+	return nil
+}
+
+func (c *imapClient) supportsIdle() bool {
+	return c.caps["IDLE"]
+}
+
+// idle 发送 IDLE 并等待 EXISTS 通知；超时或 ctx 取消时正常结束 IDLE
+func (c *imapClient) idle(ctx context.Context, timeout time.Duration) error {
+	tag, err := c.send("IDLE")
+	if err != nil {
+		return err
+	}
+	resp, err := c.readResponse()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(resp.line, "+") {
+		return fmt.Errorf("imap idle: %s", resp.line)
+	}
+
+	// ctx 取消时通过读超时唤醒阻塞的读取
+	stop := context.AfterFunc(ctx, func() { _ = c.conn.SetReadDeadline(time.Now()) })
+	defer stop()
+	_ = c.conn.SetReadDeadline(time.Now().Add(timeout))
+
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				return err
+			}
+			break
+		}
+		if strings.HasSuffix(strings.ToUpper(resp.line), " EXISTS") {
+			break
+		}
+	}
+
+	_ = c.conn.SetReadDeadline(time.Time{})
+	if _, err := c.w.WriteString("DONE\r\n"); err != nil {
+		return err
+	}
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	_, err = c.waitTagged(tag)
+	return err
+}
+
+func (c *imapClient) close() error {
+	_ = c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, _ = c.cmd("LOGOUT")
+	return c.conn.Close()
+}
+
+// cmd 发送命令并读取到对应的标记响应，返回其间的未标记响应
+func (c *imapClient) cmd(format string, args ...any) ([]imapResponse, error) {
+	tag, err := c.send(format, args...)
+	if err != nil {
+		return nil, err
+	}
+	return c.waitTagged(tag)
+}
+
+func (c *imapClient) send(format string, args ...any) (string, error) {
+	c.tag++
+	tag := fmt.Sprintf("a%d", c.tag)
+	if _, err := fmt.Fprintf(c.w, tag+" "+format+"\r\n", args...); err != nil {
+		return "", err
+	}
+	return tag, c.w.Flush()
+}
+
+func (c *imapClient) waitTagged(tag string) ([]imapResponse, error) {
+	var untagged []imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(resp.line, tag+" ") {
+			untagged = append(untagged, resp)
+			continue
+		}
+		status := strings.TrimPrefix(resp.line, tag+" ")
+		if !strings.HasPrefix(strings.ToUpper(status), "OK") {
+			return nil, fmt.Errorf("%s", status)
+		}
+		return untagged, nil
+	}
+}
+
+// readResponse 读取一条完整响应，展开行尾的 {n} 字面量
+func (c *imapClient) readResponse() (imapResponse, error) {
+	var resp imapResponse
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return resp, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		resp.line += line
+
+		n, ok := literalSize(line)
+		if !ok {
+			return resp, nil
+		}
+		literal := make([]byte, n)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, literal)
+	}
+}
+
+// literalSize 解析行尾的 {n}
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	i := strings.LastIndexByte(line, '{')
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(line[i+1:len(line)-1], "+"))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// quote 生成 IMAP 带引号字符串
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,193 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIMAPServer is a scripted IMAP server that understands the handful of
+// commands the channel sends.
+type fakeIMAPServer struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	messages map[uint32]string
+	seen     map[uint32]bool
+	commands []string
+	newMail  chan struct{}
+}
+
+func newFakeIMAPServer(t *testing.T) *fakeIMAPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeIMAPServer{ln: ln, messages: map[uint32]string{}, seen: map[uint32]bool{}, newMail: make(chan struct{}, 1)}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeIMAPServer) add(uid uint32, raw string) {
+	s.mu.Lock()
+	s.messages[uid] = raw
+	s.mu.Unlock()
+	select {
+	case s.newMail <- struct{}{}:
+	default:
+	}
+}
+
+func (s *fakeIMAPServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeIMAPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	var wmu sync.Mutex
+	reply := func(format string, args ...any) {
+		wmu.Lock()
+		defer wmu.Unlock()
+		fmt.Fprintf(w, format+"\r\n", args...)
+		w.Flush()
+	}
+	reply("* OK fake IMAP ready")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		tag, cmd, _ := strings.Cut(line, " ")
+		s.mu.Lock()
+		s.commands = append(s.commands, cmd)
+		s.mu.Unlock()
+
+		upper := strings.ToUpper(cmd)
+		switch {
+		case upper == "CAPABILITY":
+			reply("* CAPABILITY IMAP4rev1 IDLE")
+		case strings.HasPrefix(upper, "LOGIN"):
+			if cmd != `LOGIN "mote@example.com" "pa\"ss"` {
+				reply("%s NO invalid credentials", tag)
+				continue
+			}
+		case strings.HasPrefix(upper, "SELECT"):
+			reply("* 1 EXISTS")
+		case upper == "UID SEARCH UNSEEN":
+			s.mu.Lock()
+			var uids []string
+			for uid := range s.messages {
+				if !s.seen[uid] {
+					uids = append(uids, fmt.Sprint(uid))
+				}
+			}
+			s.mu.Unlock()
+			reply("* SEARCH %s", strings.Join(uids, " "))
+		case strings.HasPrefix(upper, "UID FETCH"):
+			var uid uint32
+			fmt.Sscanf(cmd, "UID FETCH %d", &uid)
+			s.mu.Lock()
+			raw := s.messages[uid]
+			s.mu.Unlock()
+			reply("* 1 FETCH (UID %d BODY[] {%d}\r\n%s)", uid, len(raw), raw)
+		case strings.HasPrefix(upper, "UID STORE"):
+			var uid uint32
+			fmt.Sscanf(cmd, "UID STORE %d", &uid)
+			s.mu.Lock()
+			s.seen[uid] = true
+			s.mu.Unlock()
+		case upper == "IDLE":
+			reply("+ idling")
+			done := make(chan struct{})
+			finished := make(chan struct{})
+			go func() {
+				defer close(finished)
+				select {
+				case <-s.newMail:
+					reply("* 2 EXISTS")
+				case <-done:
+				}
+			}()
+			_, _ = r.ReadString('\n') // DONE
+			close(done)
+			<-finished
+		case upper == "LOGOUT":
+			reply("* BYE")
+			reply("%s OK LOGOUT completed", tag)
+			return
+		}
+		reply("%s OK done", tag)
+	}
+}
+
+func TestIMAPClient(t *testing.T) {
+	srv := newFakeIMAPServer(t)
+	raw := "From: a@example.com\r\nSubject: hi\r\n\r\nbody {with braces}\r\n"
+	srv.add(7, raw)
+
+	cfg := Config{IMAPAddr: srv.ln.Addr().String(), Username: "mote@example.com", Password: `pa"ss`, Folder: "INBOX"}
+	mb, err := dialIMAP(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("dialIMAP failed: %v", err)
+	}
+	defer mb.close()
+
+	uids, err := mb.unseen()
+	if err != nil || len(uids) != 1 || uids[0] != 7 {
+		t.Fatalf("unseen = %v, %v", uids, err)
+	}
+	got, err := mb.fetch(7)
+	if err != nil || string(got) != raw {
+		t.Fatalf("fetch = %q, %v", got, err)
+	}
+	if err := mb.markSeen(7); err != nil {
+		t.Fatalf("markSeen failed: %v", err)
+	}
+	if uids, _ := mb.unseen(); len(uids) != 0 {
+		t.Errorf("message still unseen: %v", uids)
+	}
+
+	if !mb.supportsIdle() {
+		t.Fatal("IDLE capability not detected")
+	}
+	start := time.Now()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		srv.add(8, raw)
+	}()
+	if err := mb.idle(context.Background(), 5*time.Second); err != nil {
+		t.Fatalf("idle failed: %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("idle did not return on new mail")
+	}
+
+	// Timeout ends IDLE cleanly and the connection stays usable
+	if err := mb.idle(context.Background(), 50*time.Millisecond); err != nil {
+		t.Fatalf("idle timeout failed: %v", err)
+	}
+	if uids, err := mb.unseen(); err != nil || len(uids) != 1 {
+		t.Errorf("after idle: %v, %v", uids, err)
+	}
+
+	bad := cfg
+	bad.Password = "wrong"
+	if _, err := dialIMAP(context.Background(), bad); err == nil || !strings.Contains(err.Error(), "login") {
+		t.Errorf("bad password: err = %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	internalChannel "mote/internal/channel"
+	"mote/internal/provider"
+)
+
+// parsedMail 渠道关心的邮件内容
+type parsedMail struct {
+	MessageID  string
+	InReplyTo  string
+	References []string
+	From       *mail.Address
+	ReplyTo    *mail.Address
+	Subject    string
+	Date       time.Time
+	Body       string
+	// AutoGenerated 自动回复、退信或邮件列表，不应答以免循环
+	AutoGenerated bool
+	// AuthResults Authentication-Results 头，按从上到下的顺序
+	AuthResults []string
+
+	Attachments []provider.Attachment
+	Skipped     []string // 无法处理的附件名
+}
+
+var (
+	wordDecoder = &mime.WordDecoder{}
+	msgIDRe     = regexp.MustCompile(`<[^<>\s]+>`)
+	// 回复邮件中引用原文的开头，如 "On Mon, 1 Jan 2024, Ann <a@b.c> wrote:"
+	quoteHeaderRe = regexp.MustCompile(`(?m)^(On .+wrote:|在.+写道：)\s*$`)
+	htmlTagRe     = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]+>`)
+	// Authentication-Results 中的注释，如 "(1024-bit key)"
+	authCommentRe = regexp.MustCompile(`\([^()]*\)`)
+)
+
+// parseMail 解析 RFC 822 邮件；maxAttachment 为单个附件的大小上限
+func parseMail(raw []byte, maxAttachment int) (*parsedMail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("parse mail: %w", err)
+	}
+	h := msg.Header
+
+	m := &parsedMail{
+		MessageID:  firstMessageID(h.Get("Message-ID")),
+		InReplyTo:  firstMessageID(h.Get("In-Reply-To")),
+		References: msgIDRe.FindAllString(h.Get("References"), -1),
+		Subject:    decodeHeader(h.Get("Subject")),
+	}
+	if m.From, err = mail.ParseAddress(h.Get("From")); err != nil {
+		return nil, fmt.Errorf("parse From: %w", err)
+	}
+	if rt := h.Get("Reply-To"); rt != "" {
+		m.ReplyTo, _ = mail.ParseAddress(rt)
+	}
+	if d, err := h.Date(); err == nil {
+		m.Date = d
+	}
+
+	auto := strings.ToLower(h.Get("Auto-Submitted"))
+	precedence := strings.ToLower(h.Get("Precedence"))
+	m.AutoGenerated = (auto != "" && auto != "no") ||
+		precedence == "bulk" || precedence == "junk" || precedence == "list" ||
+		h.Get("List-Id") != "" || h.Get("X-Autoreply") != "" ||
+		strings.Contains(strings.ToLower(m.From.Address), "mailer-daemon")
+
+	m.AuthResults = h["Authentication-Results"]
+
+	var plain, html string
+	err = walkParts(h.Get("Content-Type"), h.Get("Content-Transfer-Encoding"), "", msg.Body,
+		func(mediaType, filename string, data []byte) {
+			switch {
+			case filename == "" && mediaType == "text/plain" && plain == "":
+				plain = string(data)
+			case filename == "" && mediaType == "text/html" && html == "":
+				html = string(data)
+			case filename != "" || !strings.HasPrefix(mediaType, "text/"):
+				if filename == "" {
+					filename = "attachment"
+				}
+				if len(data) > maxAttachment {
+					m.Skipped = append(m.Skipped, filename+" (too large)")
+					return
+				}
+				if att, ok := internalChannel.BuildAttachment(filename, mediaType, data); ok {
+					m.Attachments = append(m.Attachments, att)
+				} else {
+					m.Skipped = append(m.Skipped, filename)
+				}
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	body := plain
+	if body == "" && html != "" {
+		body = htmlToText(html)
+	}
+	m.Body = stripQuoted(body)
+	return m, nil
+}
+
+// authenticated 报告 authServID 记录的验证结果能否证明发件人域名：
+// 只看该 authserv-id 最上面的一个 Authentication-Results 头（发件人自己加的头在它下面），
+// 要求 dmarc=pass，或 dkim=pass 且 header.d 与发件人域名对齐
+func (m *parsedMail) authenticated(authServID string) bool {
+	authServID = strings.ToLower(strings.TrimSpace(authServID))
+	if authServID == "" {
+		return false
+	}
+	_, domain, ok := strings.Cut(strings.ToLower(m.From.Address), "@")
+	if !ok || domain == "" {
+		return false
+	}
+	for _, header := range m.AuthResults {
+		parts := strings.Split(strings.ToLower(authCommentRe.ReplaceAllString(header, "")), ";")
+		if id := strings.Fields(parts[0]); len(id) == 0 || id[0] != authServID {
+			continue
+		}
+		for _, resinfo := range parts[1:] {
+			fields := strings.Fields(resinfo)
+			if len(fields) == 0 {
+				continue
+			}
+			props := make(map[string]string)
+			for _, f := range fields[1:] {
+				if k, v, ok := strings.Cut(f, "="); ok {
+					props[k] = strings.Trim(v, `"`)
+				}
+			}
+			switch fields[0] {
+			case "dmarc=pass":
+				if from := props["header.from"]; from == "" || from == domain {
+					return true
+				}
+			case "dkim=pass":
+				if d := props["header.d"]; d != "" && (d == domain || strings.HasSuffix(domain, "."+d)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// walkParts 递归遍历 MIME 结构，对每个叶子部分调用 fn（内容已解码）
+func walkParts(contentType, encoding, disposition string, r io.Reader, fn func(mediaType, filename string, data []byte)) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("read mime part: %w", err)
+			}
+			err = walkParts(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"),
+				part.Header.Get("Content-Disposition"), part, fn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: r})
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("decode mime part: %w", err)
+	}
+
+	filename := params["name"]
+	if _, dparams, err := mime.ParseMediaType(disposition); err == nil && dparams["filename"] != "" {
+		filename = dparams["filename"]
+	}
+	if strings.HasPrefix(strings.ToLower(disposition), "attachment") && filename == "" {
+		filename = "attachment"
+	}
+	fn(mediaType, decodeHeader(filename), data)
+	return nil
+}
+
+// newlineStripper 去掉 base64 内容中的换行
+type newlineStripper struct{ r io.Reader }
+
+func (s *newlineStripper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	j := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' {
+			p[j] = b
+			j++
+		}
+	}
+	return j, err
+}
+
+// stripQuoted 去掉回复中引用的原文
+func stripQuoted(body string) string {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	if loc := quoteHeaderRe.FindStringIndex(body); loc != nil {
+		body = body[:loc[0]]
+	}
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, ">") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// htmlToText 粗略地把 HTML 正文转为纯文本
+func htmlToText(html string) string {
+	html = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n", "</div>", "\n").Replace(html)
+	text := htmlTagRe.ReplaceAllString(html, "")
+	text = strings.NewReplacer("&nbsp;", " ", "&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&#39;", "'").Replace(text)
+	return strings.TrimSpace(text)
+}
+
+func decodeHeader(s string) string {
+	if decoded, err := wordDecoder.DecodeHeader(s); err == nil {
+		return decoded
+	}
+	return s
+}
+
+func firstMessageID(s string) string {
+	if id := msgIDRe.FindString(s); id != "" {
+		return id
+	}
+	return strings.TrimSpace(s)
+}
+
+// threadRoot 返回邮件所在线程的第一封邮件的 Message-ID
+func (m *parsedMail) threadRoot() string {
+	switch {
+	case len(m.References) > 0:
+		return m.References[0]
+	case m.InReplyTo != "":
+		return m.InReplyTo
+	default:
+		return m.MessageID
+	}
+}
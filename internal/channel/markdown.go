@@ -0,0 +1,255 @@
+package channel
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// 模型输出的是 Markdown，各聊天平台的富文本格式不同：
+// Slack 使用 mrkdwn，Matrix 使用 HTML 子集。这里只处理常见的块和行内语法，
+// 识别不了的内容按普通文本输出。
+
+type mdBlockKind int
+
+const (
+	mdParagraph mdBlockKind = iota
+	mdHeading
+	mdCode
+	mdQuote
+	mdList
+)
+
+// mdBlock 一个 Markdown 块
+type mdBlock struct {
+	kind    mdBlockKind
+	level   int    // 标题级别
+	lang    string // 代码块语言
+	ordered bool   // 有序列表
+	lines   []string
+}
+
+var (
+	headingRe  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	listItemRe = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+(.*)$`)
+	// 行内语法：代码、链接、粗体、删除线、斜体
+	inlineRe = regexp.MustCompile("`([^`]+)`" +
+		`|\[([^\]]+)\]\(((?:[^()\s]|\([^()\s]*\))+)\)` +
+		`|\*\*(.+?)\*\*|__(.+?)__` +
+		`|~~(.+?)~~` +
+		`|\*([^*\s](?:[^*]*[^*\s])?)\*|\b_([^_\s](?:[^_]*[^_\s])?)_\b`)
+)
+
+// parseMarkdown 把 Markdown 拆分为块
+func parseMarkdown(md string) []mdBlock {
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	var blocks []mdBlock
+	for i := 0; i < len(lines); {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			b := mdBlock{kind: mdCode, lang: strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))}
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				b.lines = append(b.lines, lines[i])
+			}
+			i++ // 结束围栏（缺失时到文末）
+			blocks = append(blocks, b)
+
+		case headingRe.MatchString(trimmed):
+			m := headingRe.FindStringSubmatch(trimmed)
+			blocks = append(blocks, mdBlock{kind: mdHeading, level: len(m[1]), lines: []string{m[2]}})
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			b := mdBlock{kind: mdQuote}
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				line := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				b.lines = append(b.lines, strings.TrimPrefix(line, " "))
+			}
+			blocks = append(blocks, b)
+
+		case listItemRe.MatchString(lines[i]):
+			m := listItemRe.FindStringSubmatch(lines[i])
+			b := mdBlock{kind: mdList, ordered: !strings.ContainsAny(m[1], "-*+")}
+			for ; i < len(lines); i++ {
+				if m := listItemRe.FindStringSubmatch(lines[i]); m != nil {
+					b.lines = append(b.lines, m[2])
+					continue
+				}
+				// 缩进的续行并入上一项
+				line := lines[i]
+				if strings.TrimSpace(line) == "" || !strings.HasPrefix(line, " ") {
+					break
+				}
+				b.lines[len(b.lines)-1] += " " + strings.TrimSpace(line)
+			}
+			blocks = append(blocks, b)
+
+		default:
+			b := mdBlock{kind: mdParagraph}
+			for ; i < len(lines); i++ {
+				if strings.TrimSpace(lines[i]) == "" || (len(b.lines) > 0 && isBlockStart(lines[i])) {
+					break
+				}
+				b.lines = append(b.lines, strings.TrimRight(lines[i], " "))
+			}
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+func isBlockStart(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, ">") ||
+		headingRe.MatchString(trimmed) || listItemRe.MatchString(line)
+}
+
+// inlineStyle 行内语法在目标格式中的写法
+type inlineStyle struct {
+	text   func(string) string // 普通文本的转义
+	code   func(string) string
+	link   func(text, href string) string
+	bold   func(string) string
+	italic func(string) string
+	strike func(string) string
+}
+
+// renderInline 转换行内语法，嵌套的语法递归处理
+func renderInline(s string, st inlineStyle) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range inlineRe.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(st.text(s[last:m[0]]))
+		last = m[1]
+		group := func(n int) string {
+			if m[2*n] < 0 {
+				return ""
+			}
+			return s[m[2*n]:m[2*n+1]]
+		}
+		switch {
+		case m[2] >= 0:
+			b.WriteString(st.code(group(1)))
+		case m[4] >= 0:
+			b.WriteString(st.link(renderInline(group(2), st), group(3)))
+		case m[8] >= 0 || m[10] >= 0:
+			b.WriteString(st.bold(renderInline(group(4)+group(5), st)))
+		case m[12] >= 0:
+			b.WriteString(st.strike(renderInline(group(6), st)))
+		default:
+			b.WriteString(st.italic(renderInline(group(7)+group(8), st)))
+		}
+	}
+	b.WriteString(st.text(s[last:]))
+	return b.String()
+}
+
+// slackEscape 转义 mrkdwn 中的控制字符
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+var slackInline = inlineStyle{
+	text: slackEscape,
+	code: func(s string) string { return "`" + slackEscape(s) + "`" },
+	link: func(text, href string) string {
+		return "<" + strings.ReplaceAll(href, "|", "%7C") + "|" + text + ">"
+	},
+	bold:   func(s string) string { return "*" + s + "*" },
+	italic: func(s string) string { return "_" + s + "_" },
+	strike: func(s string) string { return "~" + s + "~" },
+}
+
+// MarkdownToSlack 把 Markdown 转换为 Slack mrkdwn
+func MarkdownToSlack(md string) string {
+	var out []string
+	for _, b := range parseMarkdown(md) {
+		switch b.kind {
+		case mdCode:
+			out = append(out, "```\n"+slackEscape(strings.Join(b.lines, "\n"))+"\n```")
+		case mdHeading:
+			out = append(out, "*"+renderInline(b.lines[0], slackInline)+"*")
+		case mdQuote:
+			lines := make([]string, len(b.lines))
+			for i, line := range b.lines {
+				lines[i] = "> " + renderInline(line, slackInline)
+			}
+			out = append(out, strings.Join(lines, "\n"))
+		case mdList:
+			lines := make([]string, len(b.lines))
+			for i, line := range b.lines {
+				bullet := "•"
+				if b.ordered {
+					bullet = fmt.Sprintf("%d.", i+1)
+				}
+				lines[i] = bullet + " " + renderInline(line, slackInline)
+			}
+			out = append(out, strings.Join(lines, "\n"))
+		default:
+			out = append(out, renderInline(strings.Join(b.lines, "\n"), slackInline))
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+var htmlInline = inlineStyle{
+	text: html.EscapeString,
+	code: func(s string) string { return "<code>" + html.EscapeString(s) + "</code>" },
+	link: func(text, href string) string {
+		// 只保留安全的链接协议
+		if u, err := url.Parse(href); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") {
+			return text
+		}
+		return `<a href="` + html.EscapeString(href) + `">` + text + "</a>"
+	},
+	bold:   func(s string) string { return "<strong>" + s + "</strong>" },
+	italic: func(s string) string { return "<em>" + s + "</em>" },
+	strike: func(s string) string { return "<del>" + s + "</del>" },
+}
+
+// MarkdownToHTML 把 Markdown 转换为 Matrix 支持的 HTML 子集（org.matrix.custom.html）
+func MarkdownToHTML(md string) string {
+	var out []string
+	for _, b := range parseMarkdown(md) {
+		switch b.kind {
+		case mdCode:
+			class := ""
+			if b.lang != "" {
+				class = ` class="language-` + html.EscapeString(b.lang) + `"`
+			}
+			out = append(out, "<pre><code"+class+">"+html.EscapeString(strings.Join(b.lines, "\n"))+"</code></pre>")
+		case mdHeading:
+			out = append(out, fmt.Sprintf("<h%d>%s</h%d>", b.level, renderInline(b.lines[0], htmlInline), b.level))
+		case mdQuote:
+			out = append(out, "<blockquote>"+renderLines(b.lines)+"</blockquote>")
+		case mdList:
+			tag := "ul"
+			if b.ordered {
+				tag = "ol"
+			}
+			var items strings.Builder
+			for _, line := range b.lines {
+				items.WriteString("<li>" + renderInline(line, htmlInline) + "</li>")
+			}
+			out = append(out, "<"+tag+">"+items.String()+"</"+tag+">")
+		default:
+			out = append(out, "<p>"+renderLines(b.lines)+"</p>")
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderLines 转换多行文本，保留换行
+func renderLines(lines []string) string {
+	rendered := make([]string, len(lines))
+	for i, line := range lines {
+		rendered[i] = renderInline(line, htmlInline)
+	}
+	return strings.Join(rendered, "<br>")
+}
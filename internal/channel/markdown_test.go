@@ -0,0 +1,52 @@
+package channel
+
+import "testing"
+
+const sampleMarkdown = "# Deploy plan\n\n" +
+	"Run **all** the `tests` & check [the docs](https://example.com/a?b=1).\n" +
+	"Then _ship_ it, keep snake_case_names ~~maybe~~.\n\n" +
+	"- first *item*\n" +
+	"- second item\n" +
+	"  continued\n\n" +
+	"1. one\n" +
+	"2. two\n\n" +
+	"> quoted <b>\n\n" +
+	"```go\n" +
+	"if a < b && **c** {\n" +
+	"}\n" +
+	"```"
+
+func TestMarkdownToSlack(t *testing.T) {
+	want := "*Deploy plan*\n\n" +
+		"Run *all* the `tests` &amp; check <https://example.com/a?b=1|the docs>.\n" +
+		"Then _ship_ it, keep snake_case_names ~maybe~.\n\n" +
+		"• first _item_\n" +
+		"• second item continued\n\n" +
+		"1. one\n" +
+		"2. two\n\n" +
+		"> quoted &lt;b&gt;\n\n" +
+		"```\n" +
+		"if a &lt; b &amp;&amp; **c** {\n" +
+		"}\n" +
+		"```"
+	if got := MarkdownToSlack(sampleMarkdown); got != want {
+		t.Errorf("MarkdownToSlack:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	want := "<h1>Deploy plan</h1>\n" +
+		`<p>Run <strong>all</strong> the <code>tests</code> &amp; check <a href="https://example.com/a?b=1">the docs</a>.<br>` +
+		"Then <em>ship</em> it, keep snake_case_names <del>maybe</del>.</p>\n" +
+		"<ul><li>first <em>item</em></li><li>second item continued</li></ul>\n" +
+		"<ol><li>one</li><li>two</li></ol>\n" +
+		"<blockquote>quoted &lt;b&gt;</blockquote>\n" +
+		`<pre><code class="language-go">if a &lt; b &amp;&amp; **c** {` + "\n}</code></pre>"
+	if got := MarkdownToHTML(sampleMarkdown); got != want {
+		t.Errorf("MarkdownToHTML:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := MarkdownToHTML("[x](javascript:alert(1))"); got != "<p>x</p>" {
+		t.Errorf("unsafe link rendered as %q", got)
+	}
+}
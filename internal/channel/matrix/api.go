@@ -0,0 +1,191 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// syncResponse /sync 响应中渠道用到的部分
+type syncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join   map[string]joinedRoom  `json:"join"`
+		Invite map[string]invitedRoom `json:"invite"`
+	} `json:"rooms"`
+}
+
+type joinedRoom struct {
+	Summary struct {
+		JoinedMemberCount *int `json:"m.joined_member_count,omitempty"`
+	} `json:"summary"`
+	Timeline struct {
+		Events []Event `json:"events"`
+	} `json:"timeline"`
+}
+
+type invitedRoom struct {
+	InviteState struct {
+		Events []Event `json:"events"`
+	} `json:"invite_state"`
+}
+
+// Event 房间事件
+type Event struct {
+	Type           string          `json:"type"`
+	EventID        string          `json:"event_id"`
+	Sender         string          `json:"sender"`
+	OriginServerTS int64           `json:"origin_server_ts"`
+	StateKey       *string         `json:"state_key,omitempty"`
+	Content        json.RawMessage `json:"content"`
+}
+
+// MessageContent m.room.message 事件内容
+type MessageContent struct {
+	MsgType       string     `json:"msgtype"`
+	Body          string     `json:"body"`
+	Format        string     `json:"format,omitempty"`
+	FormattedBody string     `json:"formatted_body,omitempty"`
+	FileName      string     `json:"filename,omitempty"`
+	URL           string     `json:"url,omitempty"` // mxc:// 媒体地址
+	Info          *FileInfo  `json:"info,omitempty"`
+	RelatesTo     *RelatesTo `json:"m.relates_to,omitempty"`
+	Mentions      *Mentions  `json:"m.mentions,omitempty"`
+}
+
+// FileInfo 媒体信息
+type FileInfo struct {
+	MimeType string `json:"mimetype,omitempty"`
+	Size     int    `json:"size,omitempty"`
+}
+
+// RelatesTo 事件关系：线程、回复、编辑
+type RelatesTo struct {
+	RelType       string     `json:"rel_type,omitempty"`
+	EventID       string     `json:"event_id,omitempty"`
+	IsFallingBack bool       `json:"is_falling_back,omitempty"`
+	InReplyTo     *InReplyTo `json:"m.in_reply_to,omitempty"`
+}
+
+// InReplyTo 被回复的事件
+type InReplyTo struct {
+	EventID string `json:"event_id"`
+}
+
+// Mentions 显式提及的用户
+type Mentions struct {
+	UserIDs []string `json:"user_ids,omitempty"`
+}
+
+// apiError Matrix 标准错误响应
+type apiError struct {
+	ErrCode string `json:"errcode"`
+	Message string `json:"error"`
+}
+
+// do 调用客户端-服务端 API；path 中的参数需已转义
+func (c *matrixChannel) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal matrix request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	u := c.homeserver() + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.config.AccessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("matrix %s %s: %w", method, apiName(path), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e apiError
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("matrix %s %s: status %d %s %s", method, apiName(path), resp.StatusCode, e.ErrCode, e.Message)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("matrix %s %s: decode response: %w", method, apiName(path), err)
+		}
+	}
+	return nil
+}
+
+// download 下载 mxc:// 媒体，先尝试需要认证的新接口，再回退到旧接口
+func (c *matrixChannel) download(ctx context.Context, mxc string) ([]byte, error) {
+	server, mediaID, ok := strings.Cut(strings.TrimPrefix(mxc, "mxc://"), "/")
+	if !strings.HasPrefix(mxc, "mxc://") || !ok || server == "" || mediaID == "" {
+		return nil, fmt.Errorf("invalid media url %q", mxc)
+	}
+	suffix := url.PathEscape(server) + "/" + url.PathEscape(mediaID)
+
+	data, err := c.fetchMedia(ctx, "/_matrix/client/v1/media/download/"+suffix)
+	if errors.Is(err, errMediaNotFound) {
+		data, err = c.fetchMedia(ctx, "/_matrix/media/v3/download/"+suffix)
+	}
+	return data, err
+}
+
+var errMediaNotFound = errors.New("media endpoint not found")
+
+func (c *matrixChannel) fetchMedia(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.homeserver()+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.config.AccessToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("matrix media download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusBadRequest:
+		return nil, errMediaNotFound
+	default:
+		return nil, fmt.Errorf("matrix media download: status %d", resp.StatusCode)
+	}
+	limit := c.maxAttachmentBytes()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("matrix media download: %w", err)
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("file too large (over %d bytes)", limit)
+	}
+	return data, nil
+}
+
+// apiName 去掉路径中的版本前缀和参数，用于错误信息
+func apiName(path string) string {
+	path = strings.TrimPrefix(path, "/_matrix/client/v3/")
+	if i := strings.IndexAny(path, "!@%"); i > 0 {
+		path = path[:i] + "…"
+	}
+	return path
+}
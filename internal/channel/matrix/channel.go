@@ -0,0 +1,505 @@
+// Package matrix implements the Matrix client-server API channel plugin.
+package matrix
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	internalChannel "mote/internal/channel"
+	"mote/internal/provider"
+	"mote/pkg/channel"
+)
+
+const (
+	// MaxMessageLength 单条消息的最大字符数；事件上限为 64KB，HTML 格式会使内容膨胀
+	MaxMessageLength = 16000
+
+	defaultSyncTimeout        = 30 * time.Second
+	defaultMaxAttachmentBytes = 10 * 1024 * 1024
+	retryInterval             = 5 * time.Second
+	// maxTracked 记录的事件线程数上限，超过后清空
+	maxTracked = 5000
+)
+
+// Config Matrix 渠道配置
+type Config struct {
+	Trigger channel.TriggerConfig `json:"trigger"`
+	Reply   channel.ReplyConfig   `json:"reply"`
+
+	Homeserver  string        `json:"homeserver"` // 如 https://matrix.example.org
+	AccessToken string        `json:"accessToken"`
+	SyncTimeout time.Duration `json:"syncTimeout"` // /sync 长轮询超时
+
+	RequireMention     bool     `json:"requireMention"`     // 群聊房间中需提及机器人或在机器人参与的线程中才处理
+	AllowFrom          []string `json:"allowFrom"`          // 允许的用户 ID（@user:server）、服务器（:server）或房间 ID（为空则允许所有）
+	AutoJoin           bool     `json:"autoJoin"`           // 自动接受允许的用户发来的邀请，需设置 AllowFrom
+	MaxAttachmentBytes int      `json:"maxAttachmentBytes"` // 单个附件大小上限
+}
+
+// matrixChannel Matrix 渠道实现
+type matrixChannel struct {
+	config  Config
+	adapter internalChannel.ChatAdapter
+	client  *http.Client
+	handler channel.MessageHandler
+	mu      sync.RWMutex
+
+	// 机器人身份
+	userID      string
+	displayName string
+
+	members       map[string]int    // 房间 ID -> 成员数，用于区分私聊
+	threadOf      map[string]string // 入站事件 ID -> 回复所在线程的根事件 ID
+	participating map[string]bool   // 机器人回复过的线程根事件 ID
+	txn           atomic.Int64
+
+	// 运行状态
+	cancel  context.CancelFunc
+	done    chan struct{}
+	running bool
+}
+
+// New 创建新的 Matrix 渠道
+func New(cfg Config) *matrixChannel {
+	if cfg.SyncTimeout <= 0 {
+		cfg.SyncTimeout = defaultSyncTimeout
+	}
+	return &matrixChannel{
+		config: cfg,
+		adapter: internalChannel.ChatAdapter{
+			Trigger:          cfg.Trigger,
+			Reply:            cfg.Reply,
+			RequireMention:   cfg.RequireMention,
+			MaxMessageLength: MaxMessageLength,
+			Format:           internalChannel.MarkdownToHTML,
+		},
+		client:        &http.Client{Timeout: cfg.SyncTimeout + 30*time.Second},
+		members:       map[string]int{},
+		threadOf:      map[string]string{},
+		participating: map[string]bool{},
+	}
+}
+
+// ID 返回渠道唯一标识
+func (c *matrixChannel) ID() channel.ChannelType {
+	return channel.ChannelTypeMatrix
+}
+
+// Name 返回渠道显示名称
+func (c *matrixChannel) Name() string {
+	return "Matrix"
+}
+
+// Capabilities 返回渠道能力
+func (c *matrixChannel) Capabilities() channel.ChannelCapabilities {
+	return channel.ChannelCapabilities{
+		CanSendText:      true,
+		CanSendMedia:     false,
+		CanDetectMention: true,
+		CanWatch:         true,
+		MaxMessageLength: MaxMessageLength,
+	}
+}
+
+// Start 查询机器人身份，做一次初始同步（跳过历史消息），然后开始同步循环
+func (c *matrixChannel) Start(ctx context.Context) error {
+	if c.config.Homeserver == "" || c.config.AccessToken == "" {
+		return fmt.Errorf("matrix homeserver and access token are required")
+	}
+	// 否则任何人都能把机器人拉进房间并下达任务
+	if c.config.AutoJoin && len(c.config.AllowFrom) == 0 {
+		return fmt.Errorf("matrix auto_join requires allow_from")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return nil
+	}
+
+	var whoami struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/_matrix/client/v3/account/whoami", nil, nil, &whoami); err != nil {
+		return err
+	}
+	c.userID = whoami.UserID
+
+	var profile struct {
+		DisplayName string `json:"displayname"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/_matrix/client/v3/profile/"+url.PathEscape(c.userID)+"/displayname", nil, nil, &profile); err == nil {
+		c.displayName = profile.DisplayName
+	}
+
+	// 初始同步只取最新状态，不处理之前的消息
+	var initial syncResponse
+	query := url.Values{"filter": {`{"room":{"timeline":{"limit":1}}}`}}
+	if err := c.do(ctx, http.MethodGet, "/_matrix/client/v3/sync", query, nil, &initial); err != nil {
+		return err
+	}
+
+	// 渠道长期运行，不跟随调用方的 ctx
+	runCtx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	c.running = true
+	go c.syncLoop(runCtx, c.done, initial)
+
+	slog.Info("matrix channel started", "user", c.userID, "homeserver", c.config.Homeserver)
+	return nil
+}
+
+// Stop 停止同步循环，之后可以再次 Start
+func (c *matrixChannel) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	c.running = false
+	cancel, done := c.cancel, c.done
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+
+	cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// OnMessage 注册消息回调
+func (c *matrixChannel) OnMessage(handler channel.MessageHandler) {
+	c.mu.Lock()
+	c.handler = handler
+	c.mu.Unlock()
+}
+
+// SendMessage 发送消息；ReplyToID 为入站事件 ID 时在该事件所在线程中回复
+func (c *matrixChannel) SendMessage(ctx context.Context, msg channel.OutboundMessage) error {
+	c.mu.RLock()
+	root := c.threadOf[msg.ReplyToID]
+	c.mu.RUnlock()
+	if root == "" {
+		root = msg.ReplyToID
+	}
+
+	for _, part := range c.adapter.Outbound(msg.Content) {
+		content := MessageContent{
+			MsgType:       "m.text",
+			Body:          part.Text,
+			Format:        "org.matrix.custom.html",
+			FormattedBody: part.Formatted,
+		}
+		if root != "" {
+			content.RelatesTo = &RelatesTo{
+				RelType:       "m.thread",
+				EventID:       root,
+				IsFallingBack: true,
+				InReplyTo:     &InReplyTo{EventID: msg.ReplyToID},
+			}
+		}
+		path := fmt.Sprintf("/_matrix/client/v3/rooms/%s/send/m.room.message/%s", url.PathEscape(msg.ChatID), c.txnID())
+		if err := c.do(ctx, http.MethodPut, path, nil, content, nil); err != nil {
+			return fmt.Errorf("send message: %w", err)
+		}
+	}
+
+	if root != "" {
+		c.mu.Lock()
+		c.participating[root] = true
+		delete(c.threadOf, msg.ReplyToID)
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// syncLoop 持续调用 /sync，直到 ctx 取消
+func (c *matrixChannel) syncLoop(ctx context.Context, done chan struct{}, initial syncResponse) {
+	defer close(done)
+
+	c.handleSync(ctx, initial, false)
+	since := initial.NextBatch
+	for ctx.Err() == nil {
+		var resp syncResponse
+		query := url.Values{
+			"since":   {since},
+			"timeout": {fmt.Sprint(c.config.SyncTimeout.Milliseconds())},
+		}
+		if err := c.do(ctx, http.MethodGet, "/_matrix/client/v3/sync", query, nil, &resp); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("matrix sync failed", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+		since = resp.NextBatch
+		c.handleSync(ctx, resp, true)
+	}
+}
+
+// handleSync 处理邀请、更新房间成员数，并分发新消息（dispatch 为 false 时只更新状态）
+func (c *matrixChannel) handleSync(ctx context.Context, resp syncResponse, dispatch bool) {
+	for roomID, room := range resp.Rooms.Invite {
+		c.handleInvite(ctx, roomID, room)
+	}
+	for roomID, room := range resp.Rooms.Join {
+		if n := room.Summary.JoinedMemberCount; n != nil {
+			c.mu.Lock()
+			c.members[roomID] = *n
+			c.mu.Unlock()
+		}
+		if !dispatch {
+			continue
+		}
+		for _, ev := range room.Timeline.Events {
+			switch ev.Type {
+			case "m.room.message":
+				// 处理可能等待模型回复，不阻塞同步
+				go c.handleEvent(ctx, roomID, ev)
+			case "m.room.encrypted":
+				slog.Warn("matrix skipping encrypted message; end-to-end encryption is not supported", "room", roomID)
+			}
+		}
+	}
+}
+
+// handleInvite 自动加入允许的用户发来的邀请
+func (c *matrixChannel) handleInvite(ctx context.Context, roomID string, room invitedRoom) {
+	if !c.config.AutoJoin || len(c.config.AllowFrom) == 0 {
+		return
+	}
+	inviter := ""
+	for _, ev := range room.InviteState.Events {
+		if ev.Type == "m.room.member" && ev.StateKey != nil && *ev.StateKey == c.userID {
+			inviter = ev.Sender
+		}
+	}
+	if inviter == "" || !c.allowed(inviter, roomID) {
+		slog.Debug("matrix ignoring invite", "room", roomID, "inviter", inviter)
+		return
+	}
+	if err := c.do(ctx, http.MethodPost, "/_matrix/client/v3/join/"+url.PathEscape(roomID), nil, map[string]any{}, nil); err != nil {
+		slog.Warn("matrix join failed", "room", roomID, "error", err)
+		return
+	}
+	slog.Info("matrix joined room", "room", roomID, "inviter", inviter)
+}
+
+// handleEvent 把一条房间消息转换为入站消息并交给 handler
+func (c *matrixChannel) handleEvent(ctx context.Context, roomID string, ev Event) {
+	c.mu.RLock()
+	handler := c.handler
+	userID, displayName := c.userID, c.displayName
+	members, known := c.members[roomID]
+	c.mu.RUnlock()
+	if handler == nil || ev.Sender == userID {
+		return
+	}
+	if !c.allowed(ev.Sender, roomID) {
+		slog.Debug("matrix skipping message from unauthorized sender", "sender", ev.Sender, "room", roomID)
+		return
+	}
+
+	var content MessageContent
+	if err := json.Unmarshal(ev.Content, &content); err != nil {
+		return
+	}
+	// m.notice 通常来自其他机器人；编辑事件不重复处理
+	if content.MsgType == "m.notice" || (content.RelatesTo != nil && content.RelatesTo.RelType == "m.replace") {
+		return
+	}
+
+	body := content.Body
+	if content.RelatesTo != nil && content.RelatesTo.InReplyTo != nil {
+		body = stripReplyFallback(body)
+	}
+	isMedia := content.MsgType == "m.image" || content.MsgType == "m.file"
+	if isMedia {
+		body = ""
+	}
+	text, mentioned := stripMention(body, userID, displayName)
+	if content.Mentions != nil {
+		for _, id := range content.Mentions.UserIDs {
+			if id == userID {
+				mentioned = true
+			}
+		}
+	}
+
+	root := ev.EventID
+	if rel := content.RelatesTo; rel != nil && rel.RelType == "m.thread" && rel.EventID != "" {
+		root = rel.EventID
+	}
+	c.mu.RLock()
+	if c.participating[root] {
+		mentioned = true
+	}
+	c.mu.RUnlock()
+
+	inbound := channel.InboundMessage{
+		ID:           ev.EventID,
+		ChannelType:  channel.ChannelTypeMatrix,
+		MessageType:  channel.MessageTypeGroup,
+		ChatID:       roomID,
+		SenderID:     ev.Sender,
+		SenderName:   localpart(ev.Sender),
+		Content:      text,
+		RawContent:   content.Body,
+		Timestamp:    time.UnixMilli(ev.OriginServerTS),
+		Metadata:     map[string]any{internalChannel.ThreadKey: root},
+		WasMentioned: mentioned,
+	}
+	// 只有机器人和对方两个成员的房间视为私聊
+	if known && members <= 2 {
+		inbound.MessageType = channel.MessageTypeDM
+	}
+	if !c.adapter.Accept(&inbound) {
+		return
+	}
+
+	if isMedia {
+		att, note := c.attachment(ctx, content)
+		if note != "" {
+			inbound.Content = strings.TrimSpace(inbound.Content + "\n" + note)
+		} else {
+			inbound.Metadata[internalChannel.AttachmentsKey] = []provider.Attachment{att}
+		}
+	}
+	if inbound.Content == "" && inbound.Metadata[internalChannel.AttachmentsKey] == nil {
+		return
+	}
+
+	c.mu.Lock()
+	if len(c.threadOf) >= maxTracked {
+		c.threadOf = map[string]string{}
+	}
+	if len(c.participating) >= maxTracked {
+		c.participating = map[string]bool{}
+	}
+	c.threadOf[ev.EventID] = root
+	c.mu.Unlock()
+
+	if err := handler(ctx, inbound); err != nil {
+		slog.Warn("matrix message handler failed", "room", roomID, "error", err)
+	}
+}
+
+// attachment 下载图片或文件并转换为 provider.Attachment；失败时返回说明文字
+func (c *matrixChannel) attachment(ctx context.Context, content MessageContent) (provider.Attachment, string) {
+	name := content.FileName
+	if name == "" {
+		name = content.Body
+	}
+	mimeType := ""
+	if content.Info != nil {
+		mimeType = content.Info.MimeType
+		if content.Info.Size > c.maxAttachmentBytes() {
+			return provider.Attachment{}, fmt.Sprintf("[attachment %s is too large]", name)
+		}
+	}
+	if !internalChannel.SupportedAttachment(mimeType) {
+		return provider.Attachment{}, fmt.Sprintf("[attachment %s (%s) is not supported]", name, mimeType)
+	}
+	data, err := c.download(ctx, content.URL)
+	if err != nil {
+		slog.Warn("matrix media download failed", "file", name, "error", err)
+		return provider.Attachment{}, fmt.Sprintf("[attachment %s could not be downloaded: %v]", name, err)
+	}
+	att, _ := internalChannel.BuildAttachment(name, mimeType, data)
+	return att, ""
+}
+
+// allowed 检查白名单，可按用户 ID、服务器（":example.org"）或房间 ID 匹配
+func (c *matrixChannel) allowed(sender, roomID string) bool {
+	if len(c.config.AllowFrom) == 0 {
+		return true
+	}
+	for _, a := range c.config.AllowFrom {
+		a = strings.TrimSpace(a)
+		if a == sender || a == roomID {
+			return true
+		}
+		if strings.HasPrefix(a, ":") && strings.HasSuffix(sender, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripMention 检测并移除对机器人的提及：完整用户 ID，或客户端插入的 "显示名: " 前缀
+func stripMention(body, userID, displayName string) (string, bool) {
+	mentioned := false
+	if userID != "" && strings.Contains(body, userID) {
+		body = strings.ReplaceAll(body, userID, "")
+		mentioned = true
+	}
+	trimmed := strings.TrimSpace(body)
+	for _, name := range []string{displayName, localpart(userID)} {
+		if name == "" || len(trimmed) <= len(name) || !strings.EqualFold(trimmed[:len(name)], name) {
+			continue
+		}
+		if rest := trimmed[len(name):]; strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, ",") {
+			trimmed = rest[1:]
+			mentioned = true
+			break
+		}
+	}
+	trimmed = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(trimmed), ":,"))
+	return trimmed, mentioned
+}
+
+// stripReplyFallback 去掉回复正文开头引用原消息的 "> " 行
+func stripReplyFallback(body string) string {
+	lines := strings.Split(body, "\n")
+	i := 0
+	for i < len(lines) && strings.HasPrefix(lines[i], ">") {
+		i++
+	}
+	if i == 0 {
+		return body
+	}
+	return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+}
+
+// localpart 返回用户 ID 的本地部分，如 "@mote:example.org" -> "mote"
+func localpart(userID string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(userID, "@"), ":")
+	return name
+}
+
+// txnID 生成发送事件用的事务 ID，进程内唯一
+func (c *matrixChannel) txnID() string {
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf("mote-%s-%d", hex.EncodeToString(b[:]), c.txn.Add(1))
+}
+
+func (c *matrixChannel) homeserver() string {
+	return strings.TrimRight(c.config.Homeserver, "/")
+}
+
+func (c *matrixChannel) maxAttachmentBytes() int {
+	if c.config.MaxAttachmentBytes > 0 {
+		return c.config.MaxAttachmentBytes
+	}
+	return defaultMaxAttachmentBytes
+}
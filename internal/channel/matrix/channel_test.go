@@ -0,0 +1,228 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	internalChannel "mote/internal/channel"
+	"mote/internal/provider"
+	"mote/pkg/channel"
+)
+
+// fakeHomeserver is a minimal stand-in for a Matrix homeserver.
+type fakeHomeserver struct {
+	*httptest.Server
+	mu      sync.Mutex
+	batches []syncResponse // returned by successive incremental syncs
+	sent    []MessageContent
+	sentTo  []string
+	joined  []string
+}
+
+func event(typ, id, sender string, content any) Event {
+	data, _ := json.Marshal(content)
+	return Event{Type: typ, EventID: id, Sender: sender, OriginServerTS: 1700000000000, Content: data}
+}
+
+func joined(members int, events ...Event) joinedRoom {
+	var r joinedRoom
+	r.Summary.JoinedMemberCount = &members
+	r.Timeline.Events = events
+	return r
+}
+
+func newFakeHomeserver(t *testing.T) *fakeHomeserver {
+	f := &fakeHomeserver{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer TOKEN" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(apiError{ErrCode: "M_UNKNOWN_TOKEN", Message: "bad token"})
+			return
+		}
+		path := r.URL.EscapedPath()
+		switch {
+		case path == "/_matrix/client/v3/account/whoami":
+			_ = json.NewEncoder(w).Encode(map[string]string{"user_id": "@mote:example.org"})
+		case strings.HasPrefix(path, "/_matrix/client/v3/profile/"):
+			_ = json.NewEncoder(w).Encode(map[string]string{"displayname": "Mote"})
+		case path == "/_matrix/client/v3/sync":
+			var resp syncResponse
+			if r.URL.Query().Get("since") == "" {
+				// Initial sync: history must not be dispatched
+				resp.NextBatch = "s1"
+				resp.Rooms.Join = map[string]joinedRoom{
+					"!team:example.org": joined(5, event("m.room.message", "$old", "@ann:example.org", MessageContent{MsgType: "m.text", Body: "Mote: old news"})),
+				}
+			} else {
+				f.mu.Lock()
+				if len(f.batches) > 0 {
+					resp, f.batches = f.batches[0], f.batches[1:]
+				}
+				f.mu.Unlock()
+				if resp.NextBatch == "" {
+					select {
+					case <-r.Context().Done():
+					case <-time.After(50 * time.Millisecond):
+					}
+					resp.NextBatch = r.URL.Query().Get("since")
+				}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case strings.HasPrefix(path, "/_matrix/client/v3/join/"):
+			f.mu.Lock()
+			f.joined = append(f.joined, strings.TrimPrefix(r.URL.Path, "/_matrix/client/v3/join/"))
+			f.mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]string{"room_id": "!new:example.org"})
+		case strings.HasPrefix(path, "/_matrix/client/v3/rooms/"):
+			var content MessageContent
+			_ = json.NewDecoder(r.Body).Decode(&content)
+			room := strings.TrimPrefix(r.URL.Path, "/_matrix/client/v3/rooms/")
+			room, _, _ = strings.Cut(room, "/send/")
+			f.mu.Lock()
+			f.sent = append(f.sent, content)
+			f.sentTo = append(f.sentTo, room)
+			f.mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]string{"event_id": "$reply"})
+		case path == "/_matrix/client/v1/media/download/example.org/abc":
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(apiError{ErrCode: "M_UNRECOGNIZED"})
+		case path == "/_matrix/media/v3/download/example.org/abc":
+			_, _ = w.Write([]byte("host,port\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(apiError{ErrCode: "M_UNRECOGNIZED", Message: path})
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestMatrixChannel_SyncAndThreadedReply(t *testing.T) {
+	hs := newFakeHomeserver(t)
+
+	var batch syncResponse
+	batch.NextBatch = "s2"
+	stateKey := "@mote:example.org"
+	batch.Rooms.Invite = map[string]invitedRoom{"!new:example.org": {}}
+	invite := batch.Rooms.Invite["!new:example.org"]
+	invite.InviteState.Events = []Event{{Type: "m.room.member", Sender: "@ann:example.org", StateKey: &stateKey}}
+	batch.Rooms.Invite["!new:example.org"] = invite
+	batch.Rooms.Join = map[string]joinedRoom{
+		"!team:example.org": joined(5,
+			event("m.room.message", "$chatter", "@ann:example.org", MessageContent{MsgType: "m.text", Body: "lunch?"}),
+			event("m.room.message", "$ask", "@ann:example.org", MessageContent{
+				MsgType: "m.text", Body: "Mote: **deploy** staging",
+				Mentions: &Mentions{UserIDs: []string{"@mote:example.org"}},
+			}),
+			event("m.room.message", "$eve", "@eve:evil.test", MessageContent{MsgType: "m.text", Body: "Mote: rm -rf"}),
+		),
+		"!dm:example.org": joined(2,
+			event("m.room.message", "$file", "@bob:example.org", MessageContent{
+				MsgType: "m.file", Body: "hosts.csv", URL: "mxc://example.org/abc",
+				Info: &FileInfo{MimeType: "text/csv", Size: 10},
+			}),
+		),
+	}
+	hs.batches = []syncResponse{batch}
+
+	ch := New(Config{
+		Homeserver:     hs.URL,
+		AccessToken:    "TOKEN",
+		SyncTimeout:    time.Second,
+		RequireMention: true,
+		AllowFrom:      []string{":example.org"},
+		AutoJoin:       true,
+		Reply:          channel.ReplyConfig{Prefix: "[Mote]"},
+	})
+	received := make(chan channel.InboundMessage, 4)
+	ch.OnMessage(func(ctx context.Context, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer ch.Stop(context.Background())
+
+	got := map[string]channel.InboundMessage{}
+	for len(got) < 2 {
+		select {
+		case msg := <-received:
+			got[msg.ID] = msg
+		case <-time.After(3 * time.Second):
+			t.Fatalf("received only %v", got)
+		}
+	}
+	select {
+	case msg := <-received:
+		t.Errorf("unexpected message: %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	ask := got["$ask"]
+	if ask.Content != "**deploy** staging" || !ask.WasMentioned || ask.MessageType != channel.MessageTypeGroup || ask.ChatID != "!team:example.org" {
+		t.Errorf("unexpected group message: %+v", ask)
+	}
+	file := got["$file"]
+	atts, _ := file.Metadata[internalChannel.AttachmentsKey].([]provider.Attachment)
+	if file.MessageType != channel.MessageTypeDM || len(atts) != 1 || atts[0].Text != "host,port\n" {
+		t.Errorf("unexpected DM: %+v", file)
+	}
+
+	hs.mu.Lock()
+	joinedRooms := hs.joined
+	hs.mu.Unlock()
+	if len(joinedRooms) != 1 || joinedRooms[0] != "!new:example.org" {
+		t.Errorf("joined = %v", joinedRooms)
+	}
+
+	err := ch.SendMessage(context.Background(), channel.OutboundMessage{ChatID: ask.ChatID, Content: "Done, see `make deploy`.", ReplyToID: ask.ID})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if len(hs.sent) != 1 || hs.sentTo[0] != "!team:example.org" {
+		t.Fatalf("sent = %+v to %v", hs.sent, hs.sentTo)
+	}
+	sent := hs.sent[0]
+	if sent.Body != "[Mote]\nDone, see `make deploy`." || sent.FormattedBody != "<p>[Mote]<br>Done, see <code>make deploy</code>.</p>" {
+		t.Errorf("unexpected content: %+v", sent)
+	}
+	if rel := sent.RelatesTo; rel == nil || rel.RelType != "m.thread" || rel.EventID != "$ask" || rel.InReplyTo.EventID != "$ask" {
+		t.Errorf("reply is not threaded: %+v", sent.RelatesTo)
+	}
+}
+
+func TestMatrixChannel_AutoJoinRequiresAllowList(t *testing.T) {
+	ch := New(Config{Homeserver: "https://matrix.example.org", AccessToken: "TOKEN", AutoJoin: true})
+	if err := ch.Start(context.Background()); err == nil {
+		t.Error("Start should fail with auto_join and no allow_from")
+	}
+}
+
+func TestStripMention(t *testing.T) {
+	tests := []struct {
+		body      string
+		want      string
+		mentioned bool
+	}{
+		{"Mote: hello", "hello", true},
+		{"mote, hello", "hello", true},
+		{"@mote:example.org what's up", "what's up", true},
+		{"hello everyone", "hello everyone", false},
+		{"Motel booking", "Motel booking", false},
+	}
+	for _, tt := range tests {
+		got, mentioned := stripMention(tt.body, "@mote:example.org", "Mote")
+		if got != tt.want || mentioned != tt.mentioned {
+			t.Errorf("stripMention(%q) = %q, %v", tt.body, got, mentioned)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"mote/internal/provider"
+	"mote/pkg/channel"
+)
+
+// ThreadKey InboundMessage.Metadata 中存放线程根消息 ID 的键
+const ThreadKey = "threadId"
+
+// sessionKeyPrefix KV 存储中会话映射的键前缀
+const sessionKeyPrefix = "channel_session:"
+
+var (
+	// ErrRateLimited 发送者超出配额，消息被丢弃
+	ErrRateLimited = errors.New("sender rate limit exceeded")
+	// ErrQueueFull 会话运行中排队的消息已满，消息被丢弃
+	ErrQueueFull = errors.New("conversation queue is full")
+)
+
+// RouteRule 按渠道和会话选择 Agent，空字段匹配任意值
+type RouteRule struct {
+	Channel   channel.ChannelType
+	ChatID    string
+	Agent     string        // 交给该 Agent 处理，空表示主 Agent
+	IdleReset time.Duration // 覆盖默认的空闲重置时间
+}
+
+// RouterConfig 渠道消息路由配置
+type RouterConfig struct {
+	IdleReset      time.Duration // 会话空闲超过该时间后开启新会话，0 表示不重置
+	ThreadSessions bool          // 群聊中每个线程使用独立会话
+	RateLimit      int           // 每个发送者在 RateWindow 内最多处理的消息数，0 表示不限制
+	RateWindow     time.Duration
+	QueueSize      int // 运行期间每个会话最多排队的消息数
+	Rules          []RouteRule
+}
+
+// Route 一条消息的路由结果
+type Route struct {
+	Key       string // 会话键：<渠道>:<ChatID>[:<线程>]
+	SessionID string // 当前使用的会话 ID
+	Agent     string
+}
+
+// SessionStore 持久化会话映射，*storage.DB 实现了该接口
+type SessionStore interface {
+	KVGet(key string) (string, error)
+	KVSet(key, value string, ttl time.Duration) error
+}
+
+// DispatchFunc 在路由到的会话中处理一条消息
+type DispatchFunc func(ctx context.Context, route Route, msg channel.InboundMessage) error
+
+// sessionEntry 会话键当前对应的会话
+type sessionEntry struct {
+	SessionID  string    `json:"sessionId"`
+	LastActive time.Time `json:"lastActive"`
+}
+
+// conversation 会话键的运行状态
+type conversation struct {
+	busy  bool
+	queue []channel.InboundMessage
+}
+
+// Router 把渠道消息映射到持久会话，选择 Agent，并做限流和排队。
+// 同一会话同时只运行一次，运行期间到达的消息在运行结束后合并为一条继续处理。
+type Router struct {
+	config   RouterConfig
+	store    SessionStore
+	dispatch DispatchFunc
+	now      func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*sessionEntry
+	convs    map[string]*conversation
+	senders  map[string][]time.Time
+}
+
+// NewRouter 创建路由器；store 为 nil 时会话映射只保存在内存中
+func NewRouter(cfg RouterConfig, store SessionStore, dispatch DispatchFunc) *Router {
+	if cfg.RateWindow <= 0 {
+		cfg.RateWindow = time.Hour
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 5
+	}
+	return &Router{
+		config:   cfg,
+		store:    store,
+		dispatch: dispatch,
+		now:      time.Now,
+		sessions: make(map[string]*sessionEntry),
+		convs:    make(map[string]*conversation),
+		senders:  make(map[string][]time.Time),
+	}
+}
+
+// Handle 路由并处理一条入站消息。会话空闲时同步处理；
+// 会话正在运行时消息进入队列，立即返回 nil。
+func (r *Router) Handle(ctx context.Context, msg channel.InboundMessage) error {
+	if !r.allow(msg) {
+		slog.Warn("channel message dropped by rate limit", "channel", msg.ChannelType, "sender", msg.SenderID)
+		return ErrRateLimited
+	}
+
+	key := r.key(msg)
+	r.mu.Lock()
+	conv := r.convs[key]
+	if conv == nil {
+		conv = &conversation{}
+		r.convs[key] = conv
+	}
+	if conv.busy {
+		if len(conv.queue) >= r.config.QueueSize {
+			r.mu.Unlock()
+			slog.Warn("channel message dropped, queue full", "key", key, "queued", r.config.QueueSize)
+			return ErrQueueFull
+		}
+		conv.queue = append(conv.queue, msg)
+		r.mu.Unlock()
+		slog.Info("channel message queued while a run is in progress", "key", key)
+		return nil
+	}
+	conv.busy = true
+	r.mu.Unlock()
+
+	var errs []error
+	for {
+		route := r.resolve(key, msg)
+		if err := r.dispatch(ctx, route, msg); err != nil {
+			errs = append(errs, err)
+		}
+
+		r.mu.Lock()
+		if len(conv.queue) == 0 {
+			conv.busy = false
+			delete(r.convs, key)
+			r.mu.Unlock()
+			return errors.Join(errs...)
+		}
+		msg = mergeMessages(conv.queue)
+		conv.queue = nil
+		r.mu.Unlock()
+	}
+}
+
+// resolve 查找会话键当前的会话，空闲超时则开启新会话，并记录活跃时间
+func (r *Router) resolve(key string, msg channel.InboundMessage) Route {
+	rule := r.rule(msg)
+	now := r.now()
+
+	r.mu.Lock()
+	entry := r.loadLocked(key)
+	switch {
+	case entry == nil:
+		entry = &sessionEntry{SessionID: baseSessionID(key)}
+	case r.expired(entry, rule):
+		slog.Info("channel session idle, starting a new one", "key", key, "previous", entry.SessionID)
+		entry = &sessionEntry{SessionID: fmt.Sprintf("%s:%d", baseSessionID(key), now.Unix())}
+	}
+	entry.LastActive = now
+	r.sessions[key] = entry
+	saved := *entry
+	r.mu.Unlock()
+
+	if r.store != nil {
+		if data, err := json.Marshal(saved); err == nil {
+			if err := r.store.KVSet(sessionKeyPrefix+key, string(data), 0); err != nil {
+				slog.Warn("failed to save channel session mapping", "key", key, "error", err)
+			}
+		}
+	}
+	return Route{Key: key, SessionID: saved.SessionID, Agent: rule.Agent}
+}
+
+// loadLocked 从内存或持久化存储读取会话映射，调用方需持有 r.mu
+func (r *Router) loadLocked(key string) *sessionEntry {
+	if entry, ok := r.sessions[key]; ok {
+		return entry
+	}
+	if r.store == nil {
+		return nil
+	}
+	data, err := r.store.KVGet(sessionKeyPrefix + key)
+	if err != nil {
+		return nil
+	}
+	var entry sessionEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil || entry.SessionID == "" {
+		return nil
+	}
+	r.sessions[key] = &entry
+	return &entry
+}
+
+func (r *Router) expired(entry *sessionEntry, rule RouteRule) bool {
+	idle := r.config.IdleReset
+	if rule.IdleReset > 0 {
+		idle = rule.IdleReset
+	}
+	return idle > 0 && !entry.LastActive.IsZero() && r.now().Sub(entry.LastActive) > idle
+}
+
+// key 计算会话键；群聊线程在开启 ThreadSessions 时使用独立的键
+func (r *Router) key(msg channel.InboundMessage) string {
+	key := string(msg.ChannelType) + ":" + msg.ChatID
+	if r.config.ThreadSessions && msg.MessageType == channel.MessageTypeGroup {
+		if thread, _ := msg.Metadata[ThreadKey].(string); thread != "" {
+			key += ":" + thread
+		}
+	}
+	return key
+}
+
+// rule 返回第一条匹配的规则
+func (r *Router) rule(msg channel.InboundMessage) RouteRule {
+	for _, rule := range r.config.Rules {
+		if rule.Channel != "" && rule.Channel != msg.ChannelType {
+			continue
+		}
+		if rule.ChatID != "" && rule.ChatID != msg.ChatID {
+			continue
+		}
+		return rule
+	}
+	return RouteRule{}
+}
+
+// allow 按发送者做滑动窗口限流
+func (r *Router) allow(msg channel.InboundMessage) bool {
+	if r.config.RateLimit <= 0 {
+		return true
+	}
+	sender := msg.SenderID
+	if sender == "" {
+		sender = msg.ChatID
+	}
+	key := string(msg.ChannelType) + ":" + sender
+	now := r.now()
+	cutoff := now.Add(-r.config.RateWindow)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recent := r.senders[key][:0]
+	for _, t := range r.senders[key] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= r.config.RateLimit {
+		r.senders[key] = recent
+		return false
+	}
+	r.senders[key] = append(recent, now)
+	return true
+}
+
+// baseSessionID 会话键对应的初始会话 ID，与未使用路由器时的会话 ID 保持一致
+func baseSessionID(key string) string {
+	return "channel:" + key
+}
+
+// mergeMessages 把排队的多条消息合并为一条，回复最后一条
+func mergeMessages(queue []channel.InboundMessage) channel.InboundMessage {
+	if len(queue) == 1 {
+		return queue[0]
+	}
+	merged := queue[len(queue)-1]
+	contents := make([]string, 0, len(queue))
+	var attachments []provider.Attachment
+	for _, m := range queue {
+		if m.Content != "" {
+			contents = append(contents, m.Content)
+		}
+		if atts, ok := m.Metadata[AttachmentsKey].([]provider.Attachment); ok {
+			attachments = append(attachments, atts...)
+		}
+	}
+	merged.Content = strings.Join(contents, "\n\n")
+	metadata := make(map[string]any, len(merged.Metadata)+1)
+	for k, v := range merged.Metadata {
+		metadata[k] = v
+	}
+	if len(attachments) > 0 {
+		metadata[AttachmentsKey] = attachments
+	}
+	merged.Metadata = metadata
+	return merged
+}
@@ -0,0 +1,159 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"mote/internal/provider"
+	"mote/pkg/channel"
+)
+
+// memStore is an in-memory SessionStore.
+type memStore struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func (s *memStore) KVGet(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func (s *memStore) KVSet(key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func TestRouter_SessionsAndAgents(t *testing.T) {
+	store := &memStore{data: map[string]string{}}
+	var routes []Route
+	dispatch := func(ctx context.Context, route Route, msg channel.InboundMessage) error {
+		routes = append(routes, route)
+		return nil
+	}
+	cfg := RouterConfig{
+		IdleReset:      time.Hour,
+		ThreadSessions: true,
+		Rules: []RouteRule{
+			{Channel: channel.ChannelTypeSlack, ChatID: "CSUPPORT", Agent: "support", IdleReset: 10 * time.Minute},
+			{Channel: channel.ChannelTypeTelegram, Agent: "assistant"},
+		},
+	}
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	r := NewRouter(cfg, store, dispatch)
+	r.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	_ = r.Handle(ctx, channel.InboundMessage{ChannelType: channel.ChannelTypeTelegram, MessageType: channel.MessageTypeDM, ChatID: "42"})
+	_ = r.Handle(ctx, channel.InboundMessage{ChannelType: channel.ChannelTypeSlack, MessageType: channel.MessageTypeGroup, ChatID: "CSUPPORT", Metadata: map[string]any{ThreadKey: "1.1"}})
+	_ = r.Handle(ctx, channel.InboundMessage{ChannelType: channel.ChannelTypeSlack, MessageType: channel.MessageTypeDM, ChatID: "DANN", Metadata: map[string]any{ThreadKey: "2.1"}})
+
+	want := []Route{
+		{Key: "telegram:42", SessionID: "channel:telegram:42", Agent: "assistant"},
+		{Key: "slack:CSUPPORT:1.1", SessionID: "channel:slack:CSUPPORT:1.1", Agent: "support"},
+		{Key: "slack:DANN", SessionID: "channel:slack:DANN"},
+	}
+	for i, w := range want {
+		if routes[i] != w {
+			t.Errorf("route %d = %+v, want %+v", i, routes[i], w)
+		}
+	}
+
+	// Within the idle window the session is kept; after it a new one starts
+	now = now.Add(30 * time.Minute)
+	_ = r.Handle(ctx, channel.InboundMessage{ChannelType: channel.ChannelTypeTelegram, MessageType: channel.MessageTypeDM, ChatID: "42"})
+	_ = r.Handle(ctx, channel.InboundMessage{ChannelType: channel.ChannelTypeSlack, MessageType: channel.MessageTypeGroup, ChatID: "CSUPPORT", Metadata: map[string]any{ThreadKey: "1.1"}})
+	if got := routes[3].SessionID; got != "channel:telegram:42" {
+		t.Errorf("telegram session = %s, want it kept", got)
+	}
+	reset := routes[4].SessionID
+	if reset == "channel:slack:CSUPPORT:1.1" {
+		t.Errorf("slack session was not reset after the rule's idle timeout")
+	}
+
+	// The mapping survives a restart
+	r2 := NewRouter(cfg, store, dispatch)
+	r2.now = func() time.Time { return now }
+	_ = r2.Handle(ctx, channel.InboundMessage{ChannelType: channel.ChannelTypeSlack, MessageType: channel.MessageTypeGroup, ChatID: "CSUPPORT", Metadata: map[string]any{ThreadKey: "1.1"}})
+	if got := routes[5].SessionID; got != reset {
+		t.Errorf("after restart session = %s, want %s", got, reset)
+	}
+}
+
+func TestRouter_QueueAndRateLimit(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var mu sync.Mutex
+	var handled []channel.InboundMessage
+	r := NewRouter(RouterConfig{RateLimit: 4, RateWindow: time.Minute, QueueSize: 2}, nil, func(ctx context.Context, route Route, msg channel.InboundMessage) error {
+		mu.Lock()
+		handled = append(handled, msg)
+		first := len(handled) == 1
+		mu.Unlock()
+		if first {
+			started <- struct{}{}
+			<-release
+		}
+		return nil
+	})
+
+	msg := func(id, content string) channel.InboundMessage {
+		m := channel.InboundMessage{ID: id, ChannelType: channel.ChannelTypeTelegram, ChatID: "42", SenderID: "ann", Content: content}
+		if id == "3" {
+			m.Metadata = map[string]any{AttachmentsKey: []provider.Attachment{{Filename: "a.png"}}}
+		}
+		return m
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- r.Handle(context.Background(), msg("1", "first")) }()
+	<-started
+
+	ctx := context.Background()
+	if err := r.Handle(ctx, msg("2", "second")); err != nil {
+		t.Errorf("queued message: %v", err)
+	}
+	if err := r.Handle(ctx, msg("3", "third")); err != nil {
+		t.Errorf("queued message: %v", err)
+	}
+	if err := r.Handle(ctx, msg("4", "fourth")); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+	if err := r.Handle(ctx, msg("5", "fifth")); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+	// Other senders have their own quota
+	other := msg("6", "hi")
+	other.SenderID, other.ChatID = "bob", "43"
+	if err := r.Handle(ctx, other); err != nil {
+		t.Errorf("other sender: %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 3 {
+		t.Fatalf("handled %d messages, want 3", len(handled))
+	}
+	merged := handled[2]
+	if merged.Content != "second\n\nthird" || merged.ID != "3" {
+		t.Errorf("merged message = %+v", merged)
+	}
+	if atts, _ := merged.Metadata[AttachmentsKey].([]provider.Attachment); len(atts) != 1 {
+		t.Errorf("merged attachments = %v", merged.Metadata[AttachmentsKey])
+	}
+}
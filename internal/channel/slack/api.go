@@ -0,0 +1,129 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Envelope Socket Mode 推送的消息
+type Envelope struct {
+	EnvelopeID string          `json:"envelope_id,omitempty"`
+	Type       string          `json:"type"` // hello, events_api, disconnect, ...
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// EventCallback Events API 请求体（Socket Mode 中为 events_api 的 payload）
+type EventCallback struct {
+	Type      string          `json:"type"` // event_callback, url_verification
+	EventID   string          `json:"event_id,omitempty"`
+	Challenge string          `json:"challenge,omitempty"`
+	Event     json.RawMessage `json:"event,omitempty"`
+}
+
+// Event message 或 app_mention 事件
+type Event struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"`
+	User        string `json:"user,omitempty"`
+	BotID       string `json:"bot_id,omitempty"`
+	Text        string `json:"text"`
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts,omitempty"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type,omitempty"` // im, mpim, channel, group
+	Files       []File `json:"files,omitempty"`
+}
+
+// File 消息中上传的文件
+type File struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	MimeType           string `json:"mimetype"`
+	Size               int    `json:"size"`
+	URLPrivateDownload string `json:"url_private_download"`
+}
+
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// call 调用 Web API 方法；token 为 bot token 或 app-level token
+func (c *slackChannel) call(ctx context.Context, method, token string, params, out any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("marshal %s params: %w", method, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL()+"/"+method, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("slack %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("slack %s: read response: %w", method, err)
+	}
+	var result apiResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("slack %s: decode response (status %d): %w", method, resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack %s: %s", method, result.Error)
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("slack %s: decode result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// downloadFile 下载消息中的私有文件，需要 files:read 权限
+func (c *slackChannel) downloadFile(ctx context.Context, f File) ([]byte, error) {
+	if f.URLPrivateDownload == "" {
+		return nil, fmt.Errorf("no download url")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URLPrivateDownload, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.config.BotToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("slack file download failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("slack file download: status %d", resp.StatusCode)
+	}
+	// 缺少权限时 Slack 返回登录页面而不是错误状态
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") && !strings.HasPrefix(f.MimeType, "text/html") {
+		return nil, fmt.Errorf("slack file download returned a login page; is the files:read scope granted?")
+	}
+
+	limit := c.maxAttachmentBytes()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("slack file download: %w", err)
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("file too large (over %d bytes)", limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,581 @@
+// Package slack implements the Slack channel plugin over Socket Mode or the
+// Events API.
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	internalChannel "mote/internal/channel"
+	"mote/internal/provider"
+	"mote/pkg/channel"
+)
+
+const (
+	// DefaultBaseURL Slack Web API 地址
+	DefaultBaseURL = "https://slack.com/api"
+	// MaxMessageLength 单条消息的最大字符数（Slack 建议 text 不超过 4000 字符）
+	MaxMessageLength = 4000
+
+	// ModeSocket 通过 Socket Mode 的 WebSocket 接收事件，不需要公网地址
+	ModeSocket = "socket"
+	// ModeEvents 由 Slack 通过 HTTP 推送事件
+	ModeEvents = "events"
+
+	defaultEventsPath         = "/slack/events"
+	defaultMaxAttachmentBytes = 10 * 1024 * 1024
+	retryInterval             = 5 * time.Second
+	// maxClockSkew 请求签名允许的时间偏差
+	maxClockSkew = 5 * time.Minute
+	// maxTracked 记录的消息线程数上限，超过后清空
+	maxTracked = 5000
+)
+
+// Config Slack 渠道配置
+type Config struct {
+	Trigger channel.TriggerConfig `json:"trigger"`
+	Reply   channel.ReplyConfig   `json:"reply"`
+
+	BotToken string `json:"botToken"` // xoxb-...
+	AppToken string `json:"appToken"` // xapp-...，Socket Mode 需要
+	BaseURL  string `json:"baseUrl"`  // Web API 地址（为空使用官方地址），可指向本地测试服务
+	Mode     string `json:"mode"`     // socket（默认）或 events
+
+	SigningSecret string `json:"signingSecret"` // Events API 请求签名密钥
+	EventsListen  string `json:"eventsListen"`  // Events API 本地监听地址，如 ":3000"；为空则不自行监听
+	EventsPath    string `json:"eventsPath"`    // Events API 请求路径，默认 /slack/events
+
+	RequireMention     bool     `json:"requireMention"`     // 频道中需 @ 机器人或在机器人参与的线程中才处理
+	AllowFrom          []string `json:"allowFrom"`          // 允许的用户 ID 或频道 ID（为空则允许所有）
+	MaxAttachmentBytes int      `json:"maxAttachmentBytes"` // 单个附件大小上限
+}
+
+// slackChannel Slack 渠道实现
+type slackChannel struct {
+	config  Config
+	adapter internalChannel.ChatAdapter
+	client  *http.Client
+	handler channel.MessageHandler
+	mu      sync.RWMutex
+
+	// 机器人身份
+	botUserID string
+
+	threadOf      map[string]string // 频道:消息 ts -> 回复所在线程的 thread_ts
+	participating map[string]bool   // 机器人回复过的 频道:thread_ts
+
+	// 运行状态
+	cancel  context.CancelFunc
+	done    chan struct{}
+	server  *http.Server
+	running bool
+}
+
+// New 创建新的 Slack 渠道
+func New(cfg Config) *slackChannel {
+	if cfg.Mode == "" {
+		cfg.Mode = ModeSocket
+	}
+	if cfg.EventsPath == "" {
+		cfg.EventsPath = defaultEventsPath
+	}
+	return &slackChannel{
+		config: cfg,
+		adapter: internalChannel.ChatAdapter{
+			Trigger:          cfg.Trigger,
+			Reply:            cfg.Reply,
+			RequireMention:   cfg.RequireMention,
+			MaxMessageLength: MaxMessageLength,
+			Format:           internalChannel.MarkdownToSlack,
+		},
+		client:        &http.Client{Timeout: 30 * time.Second},
+		threadOf:      map[string]string{},
+		participating: map[string]bool{},
+	}
+}
+
+// ID 返回渠道唯一标识
+func (c *slackChannel) ID() channel.ChannelType {
+	return channel.ChannelTypeSlack
+}
+
+// Name 返回渠道显示名称
+func (c *slackChannel) Name() string {
+	return "Slack"
+}
+
+// Capabilities 返回渠道能力
+func (c *slackChannel) Capabilities() channel.ChannelCapabilities {
+	return channel.ChannelCapabilities{
+		CanSendText:      true,
+		CanSendMedia:     false,
+		CanDetectMention: true,
+		CanWatch:         true,
+		MaxMessageLength: MaxMessageLength,
+	}
+}
+
+// Start 查询机器人身份，然后连接 Socket Mode 或开始接收 Events API 请求
+func (c *slackChannel) Start(ctx context.Context) error {
+	if c.config.BotToken == "" {
+		return fmt.Errorf("slack bot token is not configured")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return nil
+	}
+
+	var auth struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.call(ctx, "auth.test", c.config.BotToken, map[string]any{}, &auth); err != nil {
+		return err
+	}
+	c.botUserID = auth.UserID
+
+	// 渠道长期运行，不跟随调用方的 ctx
+	runCtx, cancel := context.WithCancel(context.Background())
+
+	switch c.config.Mode {
+	case ModeSocket:
+		if c.config.AppToken == "" {
+			cancel()
+			return fmt.Errorf("slack socket mode requires app_token")
+		}
+		c.done = make(chan struct{})
+		go c.socketLoop(runCtx, c.done)
+
+	case ModeEvents:
+		if c.config.SigningSecret == "" {
+			cancel()
+			return fmt.Errorf("slack events mode requires signing_secret")
+		}
+		if c.config.EventsListen != "" {
+			if err := c.listen(runCtx); err != nil {
+				cancel()
+				return err
+			}
+		}
+
+	default:
+		cancel()
+		return fmt.Errorf("unknown slack mode %q (want socket or events)", c.config.Mode)
+	}
+
+	c.cancel = cancel
+	c.running = true
+	slog.Info("slack channel started", "bot", c.botUserID, "mode", c.config.Mode)
+	return nil
+}
+
+// Stop 停止渠道监听，之后可以再次 Start
+func (c *slackChannel) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	c.running = false
+	cancel, done, server := c.cancel, c.done, c.server
+	c.cancel, c.done, c.server = nil, nil, nil
+	c.mu.Unlock()
+
+	cancel()
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			return fmt.Errorf("shutdown events server: %w", err)
+		}
+	}
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// OnMessage 注册消息回调
+func (c *slackChannel) OnMessage(handler channel.MessageHandler) {
+	c.mu.Lock()
+	c.handler = handler
+	c.mu.Unlock()
+}
+
+// SendMessage 发送消息；ReplyToID 为入站消息 ts 时在该消息所在线程中回复
+func (c *slackChannel) SendMessage(ctx context.Context, msg channel.OutboundMessage) error {
+	key := msg.ChatID + ":" + msg.ReplyToID
+	c.mu.RLock()
+	threadTS := c.threadOf[key]
+	c.mu.RUnlock()
+	if threadTS == "" {
+		threadTS = msg.ReplyToID
+	}
+
+	for _, part := range c.adapter.Outbound(msg.Content) {
+		params := map[string]any{
+			"channel": msg.ChatID,
+			"text":    part.Formatted,
+		}
+		if threadTS != "" {
+			params["thread_ts"] = threadTS
+		}
+		if err := c.call(ctx, "chat.postMessage", c.config.BotToken, params, nil); err != nil {
+			return fmt.Errorf("send message: %w", err)
+		}
+	}
+
+	if threadTS != "" {
+		c.mu.Lock()
+		c.participating[msg.ChatID+":"+threadTS] = true
+		delete(c.threadOf, key)
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// ServeHTTP 处理 Events API 请求
+func (c *slackChannel) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "read body", http.StatusBadRequest)
+		return
+	}
+	if !c.verifySignature(req.Header, body, time.Now()) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var cb EventCallback
+	if err := json.Unmarshal(body, &cb); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if cb.Type == "url_verification" {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(cb.Challenge))
+		return
+	}
+	// 立即应答；Slack 超时重发的请求已处理过，直接忽略
+	w.WriteHeader(http.StatusOK)
+	if req.Header.Get("X-Slack-Retry-Num") != "" {
+		return
+	}
+	go c.handleCallback(context.Background(), cb)
+}
+
+// verifySignature 校验 X-Slack-Signature：v0=HMAC-SHA256(secret, "v0:" + timestamp + ":" + body)
+func (c *slackChannel) verifySignature(h http.Header, body []byte, now time.Time) bool {
+	ts, err := strconv.ParseInt(h.Get("X-Slack-Request-Timestamp"), 10, 64)
+	if err != nil {
+		return false
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > maxClockSkew || d < -maxClockSkew {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(c.config.SigningSecret))
+	fmt.Fprintf(mac, "v0:%d:", ts)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(h.Get("X-Slack-Signature")))
+}
+
+// listen 在 EventsListen 上接收 Events API 请求
+func (c *slackChannel) listen(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(c.config.EventsPath, c)
+	c.server = &http.Server{
+		Addr:              c.config.EventsListen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+	ln, err := net.Listen("tcp", c.config.EventsListen)
+	if err != nil {
+		return fmt.Errorf("listen for slack events: %w", err)
+	}
+	go func(server *http.Server) {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("slack events server stopped", "error", err)
+		}
+	}(c.server)
+	return nil
+}
+
+// socketLoop 保持 Socket Mode 连接，断开后重新连接，直到 ctx 取消
+func (c *slackChannel) socketLoop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	for ctx.Err() == nil {
+		err := c.socketSession(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Warn("slack socket mode connection failed", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+}
+
+// socketSession 打开一个 Socket Mode 连接并处理事件，服务端要求断开时返回 nil
+func (c *slackChannel) socketSession(ctx context.Context) error {
+	var open struct {
+		URL string `json:"url"`
+	}
+	if err := c.call(ctx, "apps.connections.open", c.config.AppToken, map[string]any{}, &open); err != nil {
+		return err
+	}
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, open.URL, nil)
+	if err != nil {
+		return fmt.Errorf("connect socket mode: %w", err)
+	}
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for {
+		var env Envelope
+		if err := conn.ReadJSON(&env); err != nil {
+			return fmt.Errorf("read socket mode message: %w", err)
+		}
+		// 先确认再处理，避免 Slack 重发
+		if env.EnvelopeID != "" {
+			if err := conn.WriteJSON(map[string]string{"envelope_id": env.EnvelopeID}); err != nil {
+				return fmt.Errorf("ack socket mode message: %w", err)
+			}
+		}
+		switch env.Type {
+		case "events_api":
+			var cb EventCallback
+			if err := json.Unmarshal(env.Payload, &cb); err == nil {
+				go c.handleCallback(ctx, cb)
+			}
+		case "disconnect":
+			return nil
+		}
+	}
+}
+
+// handleCallback 解析事件回调中的事件
+func (c *slackChannel) handleCallback(ctx context.Context, cb EventCallback) {
+	if cb.Type != "event_callback" {
+		return
+	}
+	var ev Event
+	if err := json.Unmarshal(cb.Event, &ev); err != nil {
+		return
+	}
+	c.handleEvent(ctx, ev)
+}
+
+// handleEvent 把一条消息事件转换为入站消息并交给 handler
+func (c *slackChannel) handleEvent(ctx context.Context, ev Event) {
+	c.mu.RLock()
+	handler := c.handler
+	botUserID := c.botUserID
+	c.mu.RUnlock()
+	if handler == nil {
+		return
+	}
+
+	// 忽略机器人（包括自己）的消息和编辑、删除等子类型
+	if ev.BotID != "" || ev.User == "" || ev.User == botUserID {
+		return
+	}
+	if ev.Subtype != "" && ev.Subtype != "file_share" && ev.Subtype != "thread_broadcast" {
+		return
+	}
+
+	mention := "<@" + botUserID + ">"
+	msgType := channel.MessageTypeGroup
+	mentioned := false
+	switch ev.Type {
+	case "app_mention":
+		mentioned = true
+	case "message":
+		if ev.ChannelType == "im" {
+			msgType = channel.MessageTypeDM
+		} else if strings.Contains(ev.Text, mention) {
+			// 同一条消息还会以 app_mention 推送，由那里处理
+			return
+		}
+	default:
+		return
+	}
+
+	if !c.allowed(ev.User, ev.Channel) {
+		slog.Debug("slack skipping message from unauthorized sender", "user", ev.User, "channel", ev.Channel)
+		return
+	}
+
+	threadTS := ev.ThreadTS
+	if threadTS == "" {
+		threadTS = ev.TS
+	}
+	c.mu.RLock()
+	if c.participating[ev.Channel+":"+threadTS] {
+		mentioned = true
+	}
+	c.mu.RUnlock()
+
+	inbound := channel.InboundMessage{
+		ID:           ev.TS,
+		ChannelType:  channel.ChannelTypeSlack,
+		MessageType:  msgType,
+		ChatID:       ev.Channel,
+		SenderID:     ev.User,
+		SenderName:   ev.User,
+		Content:      slackToText(strings.ReplaceAll(ev.Text, mention, "")),
+		RawContent:   ev.Text,
+		Timestamp:    parseTS(ev.TS),
+		Metadata:     map[string]any{internalChannel.ThreadKey: threadTS},
+		WasMentioned: mentioned,
+	}
+	if !c.adapter.Accept(&inbound) {
+		return
+	}
+
+	attachments, notes := c.attachments(ctx, ev.Files)
+	if len(attachments) > 0 {
+		inbound.Metadata[internalChannel.AttachmentsKey] = attachments
+	}
+	for _, note := range notes {
+		inbound.Content = strings.TrimSpace(inbound.Content + "\n" + note)
+	}
+	if inbound.Content == "" && len(attachments) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	if len(c.threadOf) >= maxTracked {
+		c.threadOf = map[string]string{}
+	}
+	if len(c.participating) >= maxTracked {
+		c.participating = map[string]bool{}
+	}
+	c.threadOf[ev.Channel+":"+ev.TS] = threadTS
+	c.mu.Unlock()
+
+	if err := handler(ctx, inbound); err != nil {
+		slog.Warn("slack message handler failed", "channel", ev.Channel, "error", err)
+	}
+}
+
+// attachments 下载上传的文件并转换为 provider.Attachment；无法处理的文件以说明文字的形式返回
+func (c *slackChannel) attachments(ctx context.Context, files []File) ([]provider.Attachment, []string) {
+	var atts []provider.Attachment
+	var notes []string
+	for _, f := range files {
+		if !internalChannel.SupportedAttachment(f.MimeType) {
+			notes = append(notes, fmt.Sprintf("[attachment %s (%s) is not supported]", f.Name, f.MimeType))
+			continue
+		}
+		if f.Size > c.maxAttachmentBytes() {
+			notes = append(notes, fmt.Sprintf("[attachment %s is too large]", f.Name))
+			continue
+		}
+		data, err := c.downloadFile(ctx, f)
+		if err != nil {
+			slog.Warn("slack file download failed", "file", f.Name, "error", err)
+			notes = append(notes, fmt.Sprintf("[attachment %s could not be downloaded: %v]", f.Name, err))
+			continue
+		}
+		if att, ok := internalChannel.BuildAttachment(f.Name, f.MimeType, data); ok {
+			atts = append(atts, att)
+		}
+	}
+	return atts, notes
+}
+
+// allowed 检查白名单，可按用户 ID 或频道 ID 匹配
+func (c *slackChannel) allowed(user, channelID string) bool {
+	if len(c.config.AllowFrom) == 0 {
+		return true
+	}
+	for _, a := range c.config.AllowFrom {
+		a = strings.TrimSpace(a)
+		if a == user || a == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+var slackLinkRe = regexp.MustCompile(`<([^<>|]+)(?:\|([^<>]*))?>`)
+
+// slackToText 把 Slack 消息格式转换为纯文本：链接、提及、频道引用和转义字符
+func slackToText(text string) string {
+	text = slackLinkRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := slackLinkRe.FindStringSubmatch(s)
+		target, label := m[1], m[2]
+		switch {
+		case strings.HasPrefix(target, "@"), strings.HasPrefix(target, "#"):
+			if label != "" {
+				return target[:1] + label
+			}
+			return target
+		case strings.HasPrefix(target, "!"):
+			return "@" + strings.TrimPrefix(target, "!")
+		case label != "" && label != target:
+			return label + " (" + target + ")"
+		default:
+			return target
+		}
+	})
+	text = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(text)
+	return strings.TrimSpace(text)
+}
+
+// parseTS 解析 Slack 消息 ts（"1700000000.000100"）
+func parseTS(ts string) time.Time {
+	sec, _, _ := strings.Cut(ts, ".")
+	n, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Now()
+	}
+	return time.Unix(n, 0)
+}
+
+func (c *slackChannel) baseURL() string {
+	if c.config.BaseURL == "" {
+		return DefaultBaseURL
+	}
+	return strings.TrimRight(c.config.BaseURL, "/")
+}
+
+func (c *slackChannel) maxAttachmentBytes() int {
+	if c.config.MaxAttachmentBytes > 0 {
+		return c.config.MaxAttachmentBytes
+	}
+	return defaultMaxAttachmentBytes
+}
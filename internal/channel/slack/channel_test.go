@@ -0,0 +1,253 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	internalChannel "mote/internal/channel"
+	"mote/internal/provider"
+	"mote/pkg/channel"
+)
+
+// fakeSlack is a minimal stand-in for the Slack Web API and Socket Mode.
+type fakeSlack struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []json.RawMessage // pushed over Socket Mode, then the server asks to disconnect
+	acks   []string
+	posted []map[string]any
+}
+
+func newFakeSlack(t *testing.T) *fakeSlack {
+	f := &fakeSlack{}
+	upgrader := websocket.Upgrader{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth.test":
+			if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "invalid_auth"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "user_id": "UBOT"})
+		case "/api/apps.connections.open":
+			if r.Header.Get("Authorization") != "Bearer xapp-test" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "invalid_auth"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "url": "ws" + strings.TrimPrefix(f.URL, "http") + "/ws"})
+		case "/api/chat.postMessage":
+			var params map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			f.mu.Lock()
+			f.posted = append(f.posted, params)
+			f.mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+		case "/files/notes.txt":
+			if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = w.Write([]byte("<html>login</html>"))
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("remember the milk"))
+		case "/ws":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			_ = conn.WriteJSON(Envelope{Type: "hello"})
+			f.mu.Lock()
+			events := f.events
+			f.events = nil
+			f.mu.Unlock()
+			for i, ev := range events {
+				payload, _ := json.Marshal(EventCallback{Type: "event_callback", Event: ev})
+				_ = conn.WriteJSON(Envelope{EnvelopeID: fmt.Sprintf("env-%d", i), Type: "events_api", Payload: payload})
+				var ack map[string]string
+				if err := conn.ReadJSON(&ack); err != nil {
+					return
+				}
+				f.mu.Lock()
+				f.acks = append(f.acks, ack["envelope_id"])
+				f.mu.Unlock()
+			}
+			// Keep the connection open until the client goes away
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func rawEvent(ev Event) json.RawMessage {
+	data, _ := json.Marshal(ev)
+	return data
+}
+
+func TestSlackChannel_SocketMode(t *testing.T) {
+	api := newFakeSlack(t)
+	api.events = []json.RawMessage{
+		// Channel chatter without a mention is ignored
+		rawEvent(Event{Type: "message", User: "UANN", Text: "lunch?", TS: "1.1", Channel: "CTEAM", ChannelType: "channel"}),
+		// The message copy of a mention is left to the app_mention event
+		rawEvent(Event{Type: "message", User: "UANN", Text: "<@UBOT> deploy", TS: "1.2", Channel: "CTEAM", ChannelType: "channel"}),
+		rawEvent(Event{Type: "app_mention", User: "UANN", Text: "<@UBOT> deploy <https://example.com|staging> &amp; check", TS: "1.2", Channel: "CTEAM"}),
+		// Bots, including ourselves, are ignored
+		rawEvent(Event{Type: "message", BotID: "B1", User: "UBOT", Text: "hi", TS: "1.3", Channel: "DME", ChannelType: "im"}),
+	}
+
+	ch := New(Config{
+		BotToken: "xoxb-test", AppToken: "xapp-test", BaseURL: api.URL + "/api",
+		RequireMention: true, Reply: channel.ReplyConfig{Prefix: "[Mote]"},
+	})
+	received := make(chan channel.InboundMessage, 4)
+	ch.OnMessage(func(ctx context.Context, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer ch.Stop(context.Background())
+
+	var msg channel.InboundMessage
+	select {
+	case msg = <-received:
+	case <-time.After(3 * time.Second):
+		t.Fatal("no message received")
+	}
+	if msg.Content != "deploy staging (https://example.com) & check" || !msg.WasMentioned || msg.ChatID != "CTEAM" || msg.ID != "1.2" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	select {
+	case extra := <-received:
+		t.Errorf("unexpected message: %+v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+	api.mu.Lock()
+	acks := len(api.acks)
+	api.mu.Unlock()
+	if acks != 4 {
+		t.Errorf("acked %d envelopes, want 4", acks)
+	}
+
+	if err := ch.SendMessage(context.Background(), channel.OutboundMessage{ChatID: "CTEAM", Content: "**Done**", ReplyToID: msg.ID}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	api.mu.Lock()
+	posted := api.posted
+	api.mu.Unlock()
+	if len(posted) != 1 || posted[0]["thread_ts"] != "1.2" || posted[0]["text"] != "[Mote]\n*Done*" {
+		t.Errorf("posted = %v", posted)
+	}
+
+	// Follow-ups in a thread Mote replied in don't need a mention
+	ch.handleEvent(context.Background(), Event{Type: "message", User: "UANN", Text: "and prod?", TS: "1.5", ThreadTS: "1.2", Channel: "CTEAM", ChannelType: "channel"})
+	select {
+	case msg := <-received:
+		if msg.Content != "and prod?" || msg.Metadata[internalChannel.ThreadKey] != "1.2" {
+			t.Errorf("unexpected thread message: %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Error("thread follow-up was ignored")
+	}
+}
+
+func sign(secret string, ts int64, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%d:%s", ts, body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSlackChannel_EventsAPI(t *testing.T) {
+	api := newFakeSlack(t)
+	ch := New(Config{BotToken: "xoxb-test", BaseURL: api.URL + "/api", Mode: ModeEvents, SigningSecret: "shh", AllowFrom: []string{"UANN"}})
+	ch.botUserID = "UBOT"
+	received := make(chan channel.InboundMessage, 2)
+	ch.OnMessage(func(ctx context.Context, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+
+	post := func(body string, sig string, ts int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+		req.Header.Set("X-Slack-Request-Timestamp", fmt.Sprint(ts))
+		req.Header.Set("X-Slack-Signature", sig)
+		rr := httptest.NewRecorder()
+		ch.ServeHTTP(rr, req)
+		return rr
+	}
+	now := time.Now().Unix()
+
+	challenge := `{"type":"url_verification","challenge":"abc123"}`
+	if rr := post(challenge, sign("shh", now, challenge), now); rr.Code != http.StatusOK || rr.Body.String() != "abc123" {
+		t.Errorf("url_verification: %d %q", rr.Code, rr.Body.String())
+	}
+	if rr := post(challenge, sign("wrong", now, challenge), now); rr.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status %d", rr.Code)
+	}
+	old := now - 600
+	if rr := post(challenge, sign("shh", old, challenge), old); rr.Code != http.StatusUnauthorized {
+		t.Errorf("stale timestamp: status %d", rr.Code)
+	}
+
+	ev := Event{
+		Type: "message", Subtype: "file_share", User: "UANN", Text: "what's on this list?", TS: "2.1",
+		Channel: "DANN", ChannelType: "im",
+		Files: []File{
+			{Name: "notes.txt", MimeType: "text/plain", Size: 17, URLPrivateDownload: api.URL + "/files/notes.txt"},
+			{Name: "clip.mp4", MimeType: "video/mp4", Size: 100},
+		},
+	}
+	payload, _ := json.Marshal(EventCallback{Type: "event_callback", Event: rawEvent(ev)})
+	if rr := post(string(payload), sign("shh", now, string(payload)), now); rr.Code != http.StatusOK {
+		t.Fatalf("event: status %d", rr.Code)
+	}
+
+	select {
+	case msg := <-received:
+		if msg.MessageType != channel.MessageTypeDM || !strings.HasPrefix(msg.Content, "what's on this list?") || !strings.Contains(msg.Content, "clip.mp4 (video/mp4) is not supported") {
+			t.Errorf("unexpected message: %+v", msg)
+		}
+		atts, _ := msg.Metadata[internalChannel.AttachmentsKey].([]provider.Attachment)
+		if len(atts) != 1 || atts[0].Text != "remember the milk" {
+			t.Errorf("attachments = %+v", msg.Metadata[internalChannel.AttachmentsKey])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+
+	// Senders outside allow_from are ignored
+	ch.handleEvent(context.Background(), Event{Type: "message", User: "UEVE", Text: "hi", TS: "2.2", Channel: "DEVE", ChannelType: "im"})
+	select {
+	case msg := <-received:
+		t.Errorf("unexpected message: %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSlackToText(t *testing.T) {
+	got := slackToText("hi <@U1|ann> see <#C1|general>, <!here> <https://a.b/c> &lt;tag&gt;")
+	if want := "hi @ann see #general, @here https://a.b/c <tag>"; got != want {
+		t.Errorf("slackToText = %q, want %q", got, want)
+	}
+}
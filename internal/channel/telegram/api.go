@@ -0,0 +1,183 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf16"
+)
+
+// Update Bot API 更新
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message Bot API 消息
+type Message struct {
+	MessageID       int64           `json:"message_id"`
+	From            *User           `json:"from,omitempty"`
+	Chat            Chat            `json:"chat"`
+	Date            int64           `json:"date"`
+	Text            string          `json:"text,omitempty"`
+	Caption         string          `json:"caption,omitempty"`
+	Entities        []MessageEntity `json:"entities,omitempty"`
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+	Photo           []PhotoSize     `json:"photo,omitempty"`
+	Document        *Document       `json:"document,omitempty"`
+	ReplyToMessage  *Message        `json:"reply_to_message,omitempty"`
+}
+
+// User Bot API 用户
+type User struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Chat Bot API 会话
+type Chat struct {
+	ID    int64  `json:"id"`
+	Type  string `json:"type"` // private, group, supergroup, channel
+	Title string `json:"title,omitempty"`
+}
+
+// MessageEntity 消息实体，Offset/Length 以 UTF-16 码元计
+type MessageEntity struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	User   *User  `json:"user,omitempty"`
+}
+
+// PhotoSize 图片的一种尺寸
+type PhotoSize struct {
+	FileID   string `json:"file_id"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	FileSize int    `json:"file_size,omitempty"`
+}
+
+// Document 文件附件
+type Document struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileSize int    `json:"file_size,omitempty"`
+}
+
+// File getFile 返回的文件信息
+type File struct {
+	FileID   string `json:"file_id"`
+	FileSize int    `json:"file_size,omitempty"`
+	FilePath string `json:"file_path,omitempty"`
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	ErrorCode   int             `json:"error_code,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// call 调用 Bot API 方法
+func (c *telegramChannel) call(ctx context.Context, method string, params any, out any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("marshal %s params: %w", method, err)
+	}
+	url := fmt.Sprintf("%s/bot%s/%s", c.baseURL(), c.config.Token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// 错误信息中的 URL 含有 token，不直接返回
+		return fmt.Errorf("telegram %s: request failed", method)
+	}
+	defer resp.Body.Close()
+
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram %s: decode response (status %d): %w", method, resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram %s: %d %s", method, result.ErrorCode, result.Description)
+	}
+	if out != nil {
+		if err := json.Unmarshal(result.Result, out); err != nil {
+			return fmt.Errorf("telegram %s: decode result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// downloadFile 通过 getFile 下载附件内容
+func (c *telegramChannel) downloadFile(ctx context.Context, fileID string) ([]byte, *File, error) {
+	var file File
+	if err := c.call(ctx, "getFile", map[string]any{"file_id": fileID}, &file); err != nil {
+		return nil, nil, err
+	}
+	if file.FilePath == "" {
+		return nil, nil, fmt.Errorf("telegram getFile: no file path for %s", fileID)
+	}
+	if file.FileSize > c.maxAttachmentBytes() {
+		return nil, nil, fmt.Errorf("file too large (%d bytes)", file.FileSize)
+	}
+
+	url := fmt.Sprintf("%s/file/bot%s/%s", c.baseURL(), c.config.Token, file.FilePath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("telegram file download failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("telegram file download: status %d", resp.StatusCode)
+	}
+
+	limit := c.maxAttachmentBytes()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read file: %w", err)
+	}
+	if len(data) > limit {
+		return nil, nil, fmt.Errorf("file too large (over %d bytes)", limit)
+	}
+	return data, &file, nil
+}
+
+// entityText 取出实体对应的文本（实体偏移按 UTF-16 计算）
+func entityText(text string, e MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	if e.Offset < 0 || e.Length <= 0 || e.Offset+e.Length > len(units) {
+		return ""
+	}
+	return string(utf16.Decode(units[e.Offset : e.Offset+e.Length]))
+}
+
+// removeEntity 从文本中删除实体并整理空白
+func removeEntity(text string, e MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	if e.Offset < 0 || e.Length <= 0 || e.Offset+e.Length > len(units) {
+		return text
+	}
+	before := strings.TrimRight(string(utf16.Decode(units[:e.Offset])), " ")
+	after := strings.TrimLeft(string(utf16.Decode(units[e.Offset+e.Length:])), " ")
+	if before != "" && after != "" && !strings.HasSuffix(before, "\n") && !strings.HasPrefix(after, "\n") {
+		return strings.TrimSpace(before + " " + after)
+	}
+	return strings.TrimSpace(before + after)
+}
@@ -0,0 +1,511 @@
+// Package telegram implements the Telegram Bot API channel plugin.
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalChannel "mote/internal/channel"
+	"mote/internal/provider"
+	"mote/pkg/channel"
+)
+
+const (
+	// DefaultBaseURL Telegram Bot API 地址
+	DefaultBaseURL = "https://api.telegram.org"
+	// MaxMessageLength 单条消息的最大字符数
+	MaxMessageLength = 4096
+
+	// ModePolling 通过 getUpdates 长轮询接收消息
+	ModePolling = "polling"
+	// ModeWebhook 由 Telegram 推送到 webhook
+	ModeWebhook = "webhook"
+
+	defaultPollTimeout        = 30 * time.Second
+	defaultMaxAttachmentBytes = 10 * 1024 * 1024
+	retryInterval             = 5 * time.Second
+
+	// secretHeader webhook 请求中携带的校验头
+	secretHeader = "X-Telegram-Bot-Api-Secret-Token"
+)
+
+// Config Telegram 渠道配置
+type Config struct {
+	Trigger channel.TriggerConfig `json:"trigger"`
+	Reply   channel.ReplyConfig   `json:"reply"`
+
+	Token   string `json:"token"`
+	BaseURL string `json:"baseUrl"` // Bot API 地址（为空使用官方地址），可指向本地测试服务
+	Mode    string `json:"mode"`    // polling（默认）或 webhook
+
+	PollTimeout time.Duration `json:"pollTimeout"` // 长轮询超时
+
+	WebhookURL    string `json:"webhookUrl"`    // 注册给 Telegram 的公网地址
+	WebhookListen string `json:"webhookListen"` // 本地监听地址，如 ":8443"；为空则不自行监听
+	WebhookSecret string `json:"webhookSecret"` // 校验 webhook 请求的 secret_token，webhook 模式必填
+
+	RequireMention     bool     `json:"requireMention"`     // 群聊中需 @ 机器人或回复机器人才处理
+	AllowFrom          []string `json:"allowFrom"`          // 允许的用户 ID、用户名或会话 ID，必填
+	MaxAttachmentBytes int      `json:"maxAttachmentBytes"` // 单个附件大小上限
+}
+
+// telegramChannel Telegram 渠道实现
+type telegramChannel struct {
+	config  Config
+	adapter internalChannel.ChatAdapter
+	client  *http.Client
+	handler channel.MessageHandler
+	mu      sync.RWMutex
+
+	// 运行状态
+	bot     User
+	cancel  context.CancelFunc
+	done    chan struct{}
+	server  *http.Server
+	running bool
+}
+
+// New 创建新的 Telegram 渠道
+func New(cfg Config) *telegramChannel {
+	if cfg.Mode == "" {
+		cfg.Mode = ModePolling
+	}
+	if cfg.PollTimeout <= 0 {
+		cfg.PollTimeout = defaultPollTimeout
+	}
+	return &telegramChannel{
+		config: cfg,
+		adapter: internalChannel.ChatAdapter{
+			Trigger:          cfg.Trigger,
+			Reply:            cfg.Reply,
+			RequireMention:   cfg.RequireMention,
+			MaxMessageLength: MaxMessageLength,
+		},
+		client: &http.Client{Timeout: cfg.PollTimeout + 30*time.Second},
+	}
+}
+
+// ID 返回渠道唯一标识
+func (c *telegramChannel) ID() channel.ChannelType {
+	return channel.ChannelTypeTelegram
+}
+
+// Name 返回渠道显示名称
+func (c *telegramChannel) Name() string {
+	return "Telegram"
+}
+
+// Capabilities 返回渠道能力
+func (c *telegramChannel) Capabilities() channel.ChannelCapabilities {
+	return channel.ChannelCapabilities{
+		CanSendText:      true,
+		CanSendMedia:     false,
+		CanDetectMention: true,
+		CanWatch:         true,
+		MaxMessageLength: MaxMessageLength,
+	}
+}
+
+// Start 查询机器人身份，然后开始长轮询或注册 webhook
+func (c *telegramChannel) Start(ctx context.Context) error {
+	if c.config.Token == "" {
+		return fmt.Errorf("telegram bot token is not configured")
+	}
+	// 任何人都能找到机器人并发消息，必须限定发信人
+	if len(c.config.AllowFrom) == 0 {
+		return fmt.Errorf("telegram channel requires allow_from")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return nil
+	}
+
+	var bot User
+	if err := c.call(ctx, "getMe", map[string]any{}, &bot); err != nil {
+		return err
+	}
+	c.bot = bot
+
+	// 渠道长期运行，不跟随调用方的 ctx
+	runCtx, cancel := context.WithCancel(context.Background())
+
+	switch c.config.Mode {
+	case ModePolling:
+		// 设置了 webhook 时 getUpdates 会被拒绝
+		if err := c.call(ctx, "deleteWebhook", map[string]any{}, nil); err != nil {
+			cancel()
+			return err
+		}
+		c.done = make(chan struct{})
+		go c.poll(runCtx, c.done)
+
+	case ModeWebhook:
+		if c.config.WebhookURL == "" {
+			cancel()
+			return fmt.Errorf("telegram webhook mode requires webhook_url")
+		}
+		// 没有 secret 时任何人都能向 webhook 地址伪造更新
+		if c.config.WebhookSecret == "" {
+			cancel()
+			return fmt.Errorf("telegram webhook mode requires webhook_secret")
+		}
+		params := map[string]any{
+			"url":             c.config.WebhookURL,
+			"allowed_updates": []string{"message"},
+			"secret_token":    c.config.WebhookSecret,
+		}
+		if err := c.call(ctx, "setWebhook", params, nil); err != nil {
+			cancel()
+			return err
+		}
+		if c.config.WebhookListen != "" {
+			if err := c.listen(runCtx); err != nil {
+				cancel()
+				return err
+			}
+		}
+
+	default:
+		cancel()
+		return fmt.Errorf("unknown telegram mode %q (want polling or webhook)", c.config.Mode)
+	}
+
+	c.cancel = cancel
+	c.running = true
+	slog.Info("telegram channel started", "bot", c.bot.Username, "mode", c.config.Mode)
+	return nil
+}
+
+// Stop 停止渠道监听，之后可以再次 Start
+func (c *telegramChannel) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	c.running = false
+	cancel, done, server := c.cancel, c.done, c.server
+	c.cancel, c.done, c.server = nil, nil, nil
+	c.mu.Unlock()
+
+	cancel()
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			return fmt.Errorf("shutdown webhook server: %w", err)
+		}
+	}
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// OnMessage 注册消息回调
+func (c *telegramChannel) OnMessage(handler channel.MessageHandler) {
+	c.mu.Lock()
+	c.handler = handler
+	c.mu.Unlock()
+}
+
+// SendMessage 发送消息，超过长度上限时拆分为多条
+func (c *telegramChannel) SendMessage(ctx context.Context, msg channel.OutboundMessage) error {
+	replyTo, _ := strconv.ParseInt(msg.ReplyToID, 10, 64)
+
+	for i, part := range c.adapter.Outbound(msg.Content) {
+		params := map[string]any{
+			"chat_id": msg.ChatID,
+			"text":    part.Text,
+		}
+		// 只有第一条作为回复，其余顺序跟在后面
+		if i == 0 && replyTo != 0 {
+			params["reply_parameters"] = map[string]any{
+				"message_id":                  replyTo,
+				"allow_sending_without_reply": true,
+			}
+		}
+		if err := c.call(ctx, "sendMessage", params, nil); err != nil {
+			return fmt.Errorf("send message: %w", err)
+		}
+	}
+	return nil
+}
+
+// ServeHTTP 处理 Telegram 推送的 webhook 请求
+func (c *telegramChannel) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	secret := req.Header.Get(secretHeader)
+	if c.config.WebhookSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(c.config.WebhookSecret)) != 1 {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	var update Update
+	if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid update", http.StatusBadRequest)
+		return
+	}
+	// 立即应答，避免 Telegram 因处理耗时而重发
+	w.WriteHeader(http.StatusOK)
+	go c.handleUpdate(context.Background(), update)
+}
+
+// listen 在 WebhookListen 上接收 webhook 请求，路径取自 WebhookURL
+func (c *telegramChannel) listen(ctx context.Context) error {
+	path := "/"
+	if u, err := url.Parse(c.config.WebhookURL); err == nil && u.Path != "" {
+		path = u.Path
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, c)
+	c.server = &http.Server{
+		Addr:              c.config.WebhookListen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+	ln, err := net.Listen("tcp", c.config.WebhookListen)
+	if err != nil {
+		return fmt.Errorf("listen for telegram webhook: %w", err)
+	}
+	go func(server *http.Server) {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("telegram webhook server stopped", "error", err)
+		}
+	}(c.server)
+	return nil
+}
+
+// poll 长轮询 getUpdates，直到 ctx 取消
+func (c *telegramChannel) poll(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	var offset int64
+	for ctx.Err() == nil {
+		var updates []Update
+		err := c.call(ctx, "getUpdates", map[string]any{
+			"offset":          offset,
+			"timeout":         int(c.config.PollTimeout / time.Second),
+			"allowed_updates": []string{"message"},
+		}, &updates)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("telegram getUpdates failed", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			// 处理可能等待模型回复，不阻塞轮询
+			go c.handleUpdate(ctx, u)
+		}
+	}
+}
+
+// handleUpdate 把一条更新转换为入站消息并交给 handler
+func (c *telegramChannel) handleUpdate(ctx context.Context, u Update) {
+	msg := u.Message
+	if msg == nil || msg.From == nil {
+		return
+	}
+
+	c.mu.RLock()
+	handler := c.handler
+	bot := c.bot
+	c.mu.RUnlock()
+	if handler == nil {
+		return
+	}
+
+	// 忽略自己和其他机器人的消息，防止循环
+	if msg.From.ID == bot.ID || msg.From.IsBot {
+		return
+	}
+	if !c.allowed(msg) {
+		slog.Debug("telegram skipping message from unauthorized sender", "from", msg.From.ID, "chat", msg.Chat.ID)
+		return
+	}
+
+	text, entities := msg.Text, msg.Entities
+	if text == "" {
+		text, entities = msg.Caption, msg.CaptionEntities
+	}
+	content, mentioned := stripMentions(text, entities, bot)
+	if msg.ReplyToMessage != nil && msg.ReplyToMessage.From != nil && msg.ReplyToMessage.From.ID == bot.ID {
+		mentioned = true
+	}
+
+	inbound := channel.InboundMessage{
+		ID:          strconv.FormatInt(msg.MessageID, 10),
+		ChannelType: channel.ChannelTypeTelegram,
+		MessageType: channel.MessageTypeDM,
+		ChatID:      strconv.FormatInt(msg.Chat.ID, 10),
+		SenderID:    strconv.FormatInt(msg.From.ID, 10),
+		SenderName:  displayName(msg.From),
+		Content:     content,
+		RawContent:  text,
+		Timestamp:   time.Unix(msg.Date, 0),
+		Metadata:    map[string]any{"username": msg.From.Username},
+	}
+	if msg.Chat.Type != "private" {
+		inbound.MessageType = channel.MessageTypeGroup
+		inbound.Metadata["chatTitle"] = msg.Chat.Title
+	} else {
+		mentioned = true
+	}
+	inbound.WasMentioned = mentioned
+	if !c.adapter.Accept(&inbound) {
+		return
+	}
+
+	attachments, notes := c.attachments(ctx, msg)
+	if len(attachments) > 0 {
+		inbound.Metadata[internalChannel.AttachmentsKey] = attachments
+	}
+	for _, note := range notes {
+		inbound.Content = strings.TrimSpace(inbound.Content + "\n" + note)
+	}
+	if inbound.Content == "" && len(attachments) == 0 {
+		return
+	}
+
+	if err := handler(ctx, inbound); err != nil {
+		slog.Warn("telegram message handler failed", "chat", inbound.ChatID, "error", err)
+	}
+}
+
+// allowed 检查发信人白名单，可按用户 ID、用户名或会话 ID 匹配
+func (c *telegramChannel) allowed(msg *Message) bool {
+	userID := strconv.FormatInt(msg.From.ID, 10)
+	chatID := strconv.FormatInt(msg.Chat.ID, 10)
+	for _, a := range c.config.AllowFrom {
+		a = strings.TrimSpace(a)
+		if a == userID || a == chatID {
+			return true
+		}
+		if msg.From.Username != "" && strings.EqualFold(strings.TrimPrefix(a, "@"), msg.From.Username) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripMentions 检测并移除对机器人的提及（@username、text_mention、/cmd@username）
+func stripMentions(text string, entities []MessageEntity, bot User) (string, bool) {
+	var mentions []MessageEntity
+	for _, e := range entities {
+		switch e.Type {
+		case "mention":
+			if bot.Username != "" && strings.EqualFold(entityText(text, e), "@"+bot.Username) {
+				mentions = append(mentions, e)
+			}
+		case "text_mention":
+			if e.User != nil && e.User.ID == bot.ID {
+				mentions = append(mentions, e)
+			}
+		case "bot_command":
+			cmd := entityText(text, e)
+			if bot.Username != "" && strings.HasSuffix(strings.ToLower(cmd), "@"+strings.ToLower(bot.Username)) {
+				// 只去掉 @username 部分，保留命令本身
+				suffix := len("@" + bot.Username)
+				mentions = append(mentions, MessageEntity{Type: e.Type, Offset: e.Offset + e.Length - suffix, Length: suffix})
+			}
+		}
+	}
+	if len(mentions) == 0 {
+		return strings.TrimSpace(text), false
+	}
+
+	// 从后往前删除，前面实体的偏移保持不变
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].Offset > mentions[j].Offset })
+	for _, e := range mentions {
+		text = removeEntity(text, e)
+	}
+	return text, true
+}
+
+// attachments 下载图片和文件附件并转换为 provider.Attachment；
+// 无法处理的附件以说明文字的形式返回
+func (c *telegramChannel) attachments(ctx context.Context, msg *Message) ([]provider.Attachment, []string) {
+	var atts []provider.Attachment
+	var notes []string
+
+	if len(msg.Photo) > 0 {
+		// 同一张图片有多种尺寸，取最大的
+		photo := msg.Photo[len(msg.Photo)-1]
+		data, _, err := c.downloadFile(ctx, photo.FileID)
+		if err != nil {
+			slog.Warn("telegram photo download failed", "error", err)
+			notes = append(notes, "[photo could not be downloaded]")
+		} else {
+			att, _ := internalChannel.BuildAttachment("photo.jpg", "image/jpeg", data)
+			atts = append(atts, att)
+		}
+	}
+
+	if doc := msg.Document; doc != nil {
+		name := doc.FileName
+		if name == "" {
+			name = "document"
+		}
+		if !internalChannel.SupportedAttachment(doc.MimeType) {
+			notes = append(notes, fmt.Sprintf("[attachment %s (%s) is not supported]", name, doc.MimeType))
+			return atts, notes
+		}
+		data, _, err := c.downloadFile(ctx, doc.FileID)
+		if err != nil {
+			slog.Warn("telegram document download failed", "file", name, "error", err)
+			notes = append(notes, fmt.Sprintf("[attachment %s could not be downloaded: %v]", name, err))
+		} else if att, ok := internalChannel.BuildAttachment(name, doc.MimeType, data); ok {
+			atts = append(atts, att)
+		}
+	}
+	return atts, notes
+}
+
+func displayName(u *User) string {
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if name == "" {
+		name = u.Username
+	}
+	return name
+}
+
+func (c *telegramChannel) baseURL() string {
+	if c.config.BaseURL == "" {
+		return DefaultBaseURL
+	}
+	return strings.TrimRight(c.config.BaseURL, "/")
+}
+
+func (c *telegramChannel) maxAttachmentBytes() int {
+	if c.config.MaxAttachmentBytes > 0 {
+		return c.config.MaxAttachmentBytes
+	}
+	return defaultMaxAttachmentBytes
+}
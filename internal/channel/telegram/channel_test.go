@@ -0,0 +1,217 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	internalChannel "mote/internal/channel"
+	"mote/internal/provider"
+	"mote/pkg/channel"
+)
+
+// fakeBotAPI is a minimal stand-in for the Telegram Bot API.
+type fakeBotAPI struct {
+	*httptest.Server
+	mu      sync.Mutex
+	updates []Update
+	sent    []map[string]any
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+	f := &fakeBotAPI{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/file/botTOKEN/photos/p.jpg" {
+			_, _ = w.Write([]byte("jpeg-bytes"))
+			return
+		}
+		method := strings.TrimPrefix(r.URL.Path, "/botTOKEN/")
+		var params map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+
+		var result any = true
+		switch method {
+		case "getMe":
+			result = User{ID: 99, IsBot: true, FirstName: "Mote", Username: "mote_bot"}
+		case "getUpdates":
+			f.mu.Lock()
+			updates := f.updates
+			f.updates = nil
+			f.mu.Unlock()
+			if len(updates) == 0 {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(50 * time.Millisecond):
+				}
+				updates = []Update{}
+			}
+			result = updates
+		case "getFile":
+			result = File{FileID: params["file_id"].(string), FileSize: 10, FilePath: "photos/p.jpg"}
+		case "sendMessage":
+			f.mu.Lock()
+			f.sent = append(f.sent, params)
+			f.mu.Unlock()
+		case "deleteWebhook", "setWebhook":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(apiResponse{OK: false, ErrorCode: 404, Description: "Not Found"})
+			return
+		}
+		data, _ := json.Marshal(result)
+		_ = json.NewEncoder(w).Encode(apiResponse{OK: true, Result: data})
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestTelegramChannel_Polling(t *testing.T) {
+	api := newFakeBotAPI(t)
+	api.updates = []Update{
+		{UpdateID: 1, Message: &Message{
+			MessageID: 10, From: &User{ID: 1, FirstName: "Ann"}, Chat: Chat{ID: -100, Type: "group"},
+			Text: "no mention here",
+		}},
+		{UpdateID: 2, Message: &Message{
+			MessageID: 11, From: &User{ID: 1, FirstName: "Ann"}, Chat: Chat{ID: -100, Type: "group", Title: "Team"},
+			Caption:         "@mote_bot what is this?",
+			CaptionEntities: []MessageEntity{{Type: "mention", Offset: 0, Length: 9}},
+			Photo:           []PhotoSize{{FileID: "small"}, {FileID: "large"}},
+		}},
+	}
+
+	ch := New(Config{Token: "TOKEN", BaseURL: api.URL, PollTimeout: time.Second, RequireMention: true, AllowFrom: []string{"1"}})
+	received := make(chan channel.InboundMessage, 2)
+	ch.OnMessage(func(ctx context.Context, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer ch.Stop(context.Background())
+
+	select {
+	case msg := <-received:
+		if msg.Content != "what is this?" || !msg.WasMentioned || msg.MessageType != channel.MessageTypeGroup {
+			t.Errorf("unexpected message: %+v", msg)
+		}
+		if msg.ChatID != "-100" || msg.ID != "11" || msg.SenderName != "Ann" {
+			t.Errorf("unexpected ids: %+v", msg)
+		}
+		atts, _ := msg.Metadata[internalChannel.AttachmentsKey].([]provider.Attachment)
+		if len(atts) != 1 || atts[0].Type != "image_url" || !strings.HasPrefix(atts[0].ImageURL.URL, "data:image/jpeg;base64,") {
+			t.Errorf("unexpected attachments: %+v", msg.Metadata[internalChannel.AttachmentsKey])
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no message received")
+	}
+	select {
+	case msg := <-received:
+		t.Errorf("group message without mention should be ignored: %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestTelegramChannel_SendMessageSplits(t *testing.T) {
+	api := newFakeBotAPI(t)
+	ch := New(Config{Token: "TOKEN", BaseURL: api.URL})
+
+	long := strings.Repeat("word ", MaxMessageLength/5+10)
+	err := ch.SendMessage(context.Background(), channel.OutboundMessage{ChatID: "42", Content: long, ReplyToID: "7"})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if len(api.sent) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(api.sent))
+	}
+	if api.sent[0]["reply_parameters"] == nil || api.sent[1]["reply_parameters"] != nil {
+		t.Errorf("only the first part should reply: %v", api.sent)
+	}
+	if n := len([]rune(api.sent[0]["text"].(string))); n > MaxMessageLength {
+		t.Errorf("first part has %d characters", n)
+	}
+}
+
+func TestTelegramChannel_Webhook(t *testing.T) {
+	ch := New(Config{Token: "TOKEN", WebhookSecret: "s3cret", AllowFrom: []string{"@bo"}})
+	ch.bot = User{ID: 99, Username: "mote_bot"}
+	received := make(chan channel.InboundMessage, 1)
+	ch.OnMessage(func(ctx context.Context, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+
+	body := `{"update_id":5,"message":{"message_id":3,"from":{"id":7,"first_name":"Bo","username":"bo"},"chat":{"id":7,"type":"private"},"date":1700000000,"text":"hello"}}`
+	post := func(secret string) int {
+		req := httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(body))
+		req.Header.Set(secretHeader, secret)
+		rr := httptest.NewRecorder()
+		ch.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := post("wrong"); code != http.StatusForbidden {
+		t.Errorf("wrong secret: status %d", code)
+	}
+	if code := post(""); code != http.StatusForbidden {
+		t.Errorf("missing secret: status %d", code)
+	}
+	if code := post("s3cret"); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	select {
+	case msg := <-received:
+		if msg.Content != "hello" || msg.MessageType != channel.MessageTypeDM || !msg.WasMentioned {
+			t.Errorf("unexpected message: %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+func TestTelegramChannel_RequiresAllowListAndSecret(t *testing.T) {
+	api := newFakeBotAPI(t)
+	if err := New(Config{Token: "TOKEN", BaseURL: api.URL}).Start(context.Background()); err == nil {
+		t.Error("Start should fail without allow_from")
+	}
+	ch := New(Config{Token: "TOKEN", BaseURL: api.URL, Mode: ModeWebhook, WebhookURL: "https://example.com/tg", AllowFrom: []string{"1"}})
+	if err := ch.Start(context.Background()); err == nil {
+		t.Error("webhook mode should fail without webhook_secret")
+	}
+
+	// A secret-less channel never accepts webhook posts.
+	req := httptest.NewRequest(http.MethodPost, "/tg", strings.NewReader(`{}`))
+	rr := httptest.NewRecorder()
+	New(Config{Token: "TOKEN"}).ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("webhook without secret: status %d", rr.Code)
+	}
+}
+
+func TestStripMentions(t *testing.T) {
+	bot := User{ID: 99, Username: "mote_bot"}
+	tests := []struct {
+		text      string
+		entities  []MessageEntity
+		want      string
+		mentioned bool
+	}{
+		{"hi @mote_bot please help", []MessageEntity{{Type: "mention", Offset: 3, Length: 9}}, "hi please help", true},
+		{"@other hi", []MessageEntity{{Type: "mention", Offset: 0, Length: 6}}, "@other hi", false},
+		{"/status@mote_bot now", []MessageEntity{{Type: "bot_command", Offset: 0, Length: 16}}, "/status now", true},
+		{"😀 @mote_bot go", []MessageEntity{{Type: "mention", Offset: 3, Length: 9}}, "😀 go", true},
+		{"Mote help", []MessageEntity{{Type: "text_mention", Offset: 0, Length: 4, User: &User{ID: 99}}}, "help", true},
+	}
+	for _, tt := range tests {
+		got, mentioned := stripMentions(tt.text, tt.entities, bot)
+		if got != tt.want || mentioned != tt.mentioned {
+			t.Errorf("stripMentions(%q) = %q, %v; want %q, %v", tt.text, got, mentioned, tt.want, tt.mentioned)
+		}
+	}
+}
@@ -0,0 +1,560 @@
+// Package webhook implements a generic HTTP webhook channel plugin.
+//
+// Inbound requests arrive on /api/v1/channels/webhook/{name}, are verified
+// with an HMAC signature or a shared token, and are mapped onto an
+// InboundMessage with JSON paths. Replies are POSTed to a per-hook callback
+// URL rendered from a template.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"mote/pkg/channel"
+)
+
+const (
+	// AuthHMAC 校验请求体的 HMAC-SHA256 签名
+	AuthHMAC = "hmac"
+	// AuthBearer 校验请求头中的共享 token
+	AuthBearer = "bearer"
+	// AuthNone 不校验，只应在受信任的内网中使用
+	AuthNone = "none"
+
+	defaultSignatureHeader = "X-Signature-256"
+	defaultTokenHeader     = "Authorization"
+	defaultMaxBodyBytes    = 1 << 20
+	callbackTimeout        = 30 * time.Second
+	pendingTTL             = 24 * time.Hour
+
+	// defaultCallbackTemplate 未配置回调模板时发送的请求体
+	defaultCallbackTemplate = `{"hook": {{json .Hook}}, "chat_id": {{json .ChatID}}, "reply_to": {{json .MessageID}}, "content": {{json .Content}}}`
+)
+
+// reservedNames 与渠道管理接口路径冲突的 hook 名称
+var reservedNames = map[string]bool{"config": true, "start": true, "stop": true}
+
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Mapping 入站 JSON 到消息字段的路径映射
+type Mapping struct {
+	Content         string `json:"content"`         // 消息正文的路径
+	ContentTemplate string `json:"contentTemplate"` // 正文模板，设置后优先于 Content；. 为请求体
+	ChatID          string `json:"chatId"`          // 会话标识的路径，为空则整个 hook 共用一个会话
+	SenderID        string `json:"senderId"`
+	SenderName      string `json:"senderName"`
+	MessageID       string `json:"messageId"` // 为空或取不到时自动生成
+}
+
+// Match 过滤条件：路径上的值等于 Value 才处理
+type Match struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// Hook 一个命名的 webhook 入口
+type Hook struct {
+	Name string `json:"name"`
+
+	Auth            string `json:"auth"`            // hmac、bearer 或 none
+	Secret          string `json:"secret"`          // HMAC 密钥或 token
+	SignatureHeader string `json:"signatureHeader"` // hmac 模式的签名头，值可带 "sha256=" 前缀
+	TokenHeader     string `json:"tokenHeader"`     // bearer 模式的 token 头，Authorization 头需带 "Bearer " 前缀
+
+	Match   []Match `json:"match"` // 全部满足才处理，否则忽略
+	Mapping Mapping `json:"mapping"`
+
+	CallbackURL      string            `json:"callbackUrl"`    // 回复地址模板，为空则不回复
+	CallbackMethod   string            `json:"callbackMethod"` // 默认 POST
+	CallbackHeaders  map[string]string `json:"callbackHeaders"`
+	CallbackTemplate string            `json:"callbackTemplate"` // 回复请求体模板，默认为 JSON
+}
+
+// Config Webhook 渠道配置
+type Config struct {
+	Trigger channel.TriggerConfig `json:"trigger"`
+	Reply   channel.ReplyConfig   `json:"reply"`
+
+	Hooks        []Hook `json:"hooks"`
+	MaxBodyBytes int    `json:"maxBodyBytes"` // 入站请求体大小上限
+}
+
+// hook 编译后的 Hook
+type hook struct {
+	Hook
+	matchPaths [][]string // 与 Match 一一对应
+	content    []string
+	contentTpl *template.Template
+	chatID     []string
+	senderID   []string
+	senderName []string
+	messageID  []string
+	urlTpl     *template.Template
+	bodyTpl    *template.Template
+}
+
+// pending 等待回复的入站请求，回调模板可以引用其中的字段
+type pending struct {
+	payload  any
+	received time.Time
+}
+
+// webhookChannel Webhook 渠道实现
+type webhookChannel struct {
+	config  Config
+	client  *http.Client
+	handler channel.MessageHandler
+	mu      sync.RWMutex
+
+	hooks   map[string]*hook
+	pending map[string]*pending // 消息 ID -> 入站请求
+	running bool
+}
+
+// New 创建新的 Webhook 渠道
+func New(cfg Config) *webhookChannel {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	return &webhookChannel{
+		config:  cfg,
+		client:  &http.Client{Timeout: callbackTimeout},
+		pending: make(map[string]*pending),
+	}
+}
+
+// ID 返回渠道唯一标识
+func (c *webhookChannel) ID() channel.ChannelType {
+	return channel.ChannelTypeWebhook
+}
+
+// Name 返回渠道显示名称
+func (c *webhookChannel) Name() string {
+	return "Webhook"
+}
+
+// Capabilities 返回渠道能力
+func (c *webhookChannel) Capabilities() channel.ChannelCapabilities {
+	return channel.ChannelCapabilities{
+		CanSendText:      true,
+		CanSendMedia:     false,
+		CanDetectMention: false,
+		CanWatch:         true,
+	}
+}
+
+// Start 校验并编译 hook 配置，之后开始接收请求
+func (c *webhookChannel) Start(ctx context.Context) error {
+	hooks := make(map[string]*hook, len(c.config.Hooks))
+	for _, h := range c.config.Hooks {
+		compiled, err := compileHook(h)
+		if err != nil {
+			return err
+		}
+		if hooks[h.Name] != nil {
+			return fmt.Errorf("webhook %q is defined more than once", h.Name)
+		}
+		hooks[h.Name] = compiled
+	}
+	if len(hooks) == 0 {
+		return fmt.Errorf("webhook channel has no hooks configured")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = hooks
+	c.running = true
+	slog.Info("webhook channel started", "hooks", len(hooks))
+	return nil
+}
+
+// Stop 停止接收请求，之后可以再次 Start
+func (c *webhookChannel) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running = false
+	return nil
+}
+
+// OnMessage 注册消息回调
+func (c *webhookChannel) OnMessage(handler channel.MessageHandler) {
+	c.mu.Lock()
+	c.handler = handler
+	c.mu.Unlock()
+}
+
+// SendMessage 把回复发送到入站 hook 配置的回调地址
+func (c *webhookChannel) SendMessage(ctx context.Context, msg channel.OutboundMessage) error {
+	name, chatID, _ := strings.Cut(msg.ChatID, ":")
+
+	c.mu.RLock()
+	h := c.hooks[name]
+	p := c.pending[msg.ReplyToID]
+	c.mu.RUnlock()
+	if h == nil {
+		return fmt.Errorf("unknown webhook: %s", name)
+	}
+	if h.urlTpl == nil {
+		slog.Debug("webhook has no callback, dropping reply", "hook", name)
+		return nil
+	}
+
+	data := callbackData{
+		Hook:      name,
+		ChatID:    chatID,
+		MessageID: msg.ReplyToID,
+		Content:   channel.InjectReplyPrefix(msg.Content, c.config.Reply),
+	}
+	if p != nil {
+		data.Payload = p.payload
+	}
+
+	target, err := render(h.urlTpl, data)
+	if err != nil {
+		return fmt.Errorf("webhook %s: render callback url: %w", name, err)
+	}
+	body, err := render(h.bodyTpl, data)
+	if err != nil {
+		return fmt.Errorf("webhook %s: render callback body: %w", name, err)
+	}
+
+	method := h.CallbackMethod
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSpace(target), strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.CallbackHeaders {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s callback: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook %s callback: status %d: %s", name, resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+
+	c.mu.Lock()
+	delete(c.pending, msg.ReplyToID)
+	c.mu.Unlock()
+	return nil
+}
+
+// ServeWebhook 处理发往指定 hook 的入站请求
+func (c *webhookChannel) ServeWebhook(w http.ResponseWriter, r *http.Request, name string) {
+	c.mu.RLock()
+	running := c.running
+	h := c.hooks[name]
+	c.mu.RUnlock()
+	if !running {
+		http.Error(w, "webhook channel is not running", http.StatusServiceUnavailable)
+		return
+	}
+	if h == nil {
+		http.Error(w, "unknown webhook", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(c.config.MaxBodyBytes)))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "read body failed", http.StatusBadRequest)
+		return
+	}
+	if !h.verify(r, body) {
+		slog.Warn("webhook request rejected", "hook", name, "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var payload any
+	if err := dec.Decode(&payload); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	// 不处理的请求也返回 2xx，避免来源系统反复重试
+	inbound, reason := c.accept(h, payload)
+	if reason != "" {
+		slog.Debug("webhook request ignored", "hook", name, "reason", reason)
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ignored", "reason": reason})
+		return
+	}
+
+	c.remember(inbound.ID, &pending{payload: payload, received: time.Now()})
+	go c.dispatch(inbound)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted", "id": inbound.ID})
+}
+
+// accept 按 hook 的映射生成入站消息；不处理时返回原因
+func (c *webhookChannel) accept(h *hook, payload any) (channel.InboundMessage, string) {
+	for i, m := range h.Match {
+		v, ok := lookup(payload, h.matchPaths[i])
+		if !ok || stringify(v) != m.Value {
+			return channel.InboundMessage{}, "match " + m.Path
+		}
+	}
+
+	var content string
+	if h.contentTpl != nil {
+		rendered, err := render(h.contentTpl, payload)
+		if err != nil {
+			slog.Warn("webhook content template failed", "hook", h.Name, "error", err)
+			return channel.InboundMessage{}, "content template failed"
+		}
+		content = rendered
+	} else if v, ok := lookup(payload, h.content); ok {
+		content = stringify(v)
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return channel.InboundMessage{}, "empty content"
+	}
+
+	chatID := h.Name
+	if v := optional(payload, h.chatID); v != "" {
+		chatID = h.Name + ":" + v
+	}
+	id := optional(payload, h.messageID)
+	if id == "" {
+		id = newMessageID()
+	}
+
+	inbound := channel.InboundMessage{
+		ID:           id,
+		ChannelType:  channel.ChannelTypeWebhook,
+		MessageType:  channel.MessageTypeDM,
+		ChatID:       chatID,
+		SenderID:     optional(payload, h.senderID),
+		SenderName:   optional(payload, h.senderName),
+		Content:      content,
+		RawContent:   content,
+		Timestamp:    time.Now(),
+		Metadata:     map[string]any{"hook": h.Name},
+		WasMentioned: true,
+	}
+	if c.config.Trigger.Prefix != "" {
+		result := channel.CheckTrigger(inbound, c.config.Trigger)
+		if !result.ShouldProcess {
+			return channel.InboundMessage{}, result.SkipReason
+		}
+		inbound.Content = result.StrippedContent
+	}
+	return inbound, ""
+}
+
+func (c *webhookChannel) dispatch(inbound channel.InboundMessage) {
+	c.mu.RLock()
+	handler := c.handler
+	c.mu.RUnlock()
+	if handler == nil {
+		return
+	}
+	// 请求已经应答，agent 运行不跟随请求的 ctx
+	if err := handler(context.Background(), inbound); err != nil {
+		slog.Warn("webhook message handler failed", "chat", inbound.ChatID, "error", err)
+	}
+}
+
+// remember 记录入站请求供回复时使用，并清理过期记录
+func (c *webhookChannel) remember(id string, p *pending) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, old := range c.pending {
+		if p.received.Sub(old.received) > pendingTTL {
+			delete(c.pending, k)
+		}
+	}
+	c.pending[id] = p
+}
+
+// verify 校验请求签名或 token
+func (h *hook) verify(r *http.Request, body []byte) bool {
+	switch h.Auth {
+	case AuthNone:
+		return true
+	case AuthHMAC:
+		header := h.SignatureHeader
+		if header == "" {
+			header = defaultSignatureHeader
+		}
+		got := strings.TrimSpace(r.Header.Get(header))
+		got = strings.TrimPrefix(got, "sha256=")
+		sig, err := hex.DecodeString(got)
+		if err != nil || len(sig) == 0 {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(h.Secret))
+		mac.Write(body)
+		return hmac.Equal(sig, mac.Sum(nil))
+	case AuthBearer:
+		header := h.TokenHeader
+		if header == "" {
+			header = defaultTokenHeader
+		}
+		got := strings.TrimSpace(r.Header.Get(header))
+		if strings.EqualFold(header, defaultTokenHeader) {
+			scheme, token, ok := strings.Cut(got, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") {
+				return false
+			}
+			got = strings.TrimSpace(token)
+		}
+		return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(h.Secret)) == 1
+	default:
+		return false
+	}
+}
+
+// compileHook 校验 hook 配置并解析其中的路径和模板
+func compileHook(h Hook) (*hook, error) {
+	if !nameRe.MatchString(h.Name) || reservedNames[h.Name] {
+		return nil, fmt.Errorf("invalid webhook name %q", h.Name)
+	}
+	switch h.Auth {
+	case AuthHMAC, AuthBearer:
+		if h.Secret == "" {
+			return nil, fmt.Errorf("webhook %s: %s auth requires a secret", h.Name, h.Auth)
+		}
+	case AuthNone:
+	case "":
+		return nil, fmt.Errorf("webhook %s: auth must be hmac, bearer or none", h.Name)
+	default:
+		return nil, fmt.Errorf("webhook %s: unknown auth %q", h.Name, h.Auth)
+	}
+
+	compiled := &hook{Hook: h, matchPaths: make([][]string, len(h.Match))}
+	var err error
+	for i, m := range h.Match {
+		if compiled.matchPaths[i], err = parsePath(m.Path); err != nil {
+			return nil, fmt.Errorf("webhook %s: match: %w", h.Name, err)
+		}
+	}
+
+	m := h.Mapping
+	if m.ContentTemplate != "" {
+		if compiled.contentTpl, err = newTemplate(h.Name+" content", m.ContentTemplate); err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", h.Name, err)
+		}
+	} else if m.Content == "" {
+		return nil, fmt.Errorf("webhook %s: mapping needs content or content_template", h.Name)
+	}
+	for _, f := range []struct {
+		path string
+		dst  *[]string
+	}{
+		{m.Content, &compiled.content},
+		{m.ChatID, &compiled.chatID},
+		{m.SenderID, &compiled.senderID},
+		{m.SenderName, &compiled.senderName},
+		{m.MessageID, &compiled.messageID},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if *f.dst, err = parsePath(f.path); err != nil {
+			return nil, fmt.Errorf("webhook %s: mapping: %w", h.Name, err)
+		}
+	}
+
+	if h.CallbackURL != "" {
+		if compiled.urlTpl, err = newTemplate(h.Name+" callback url", h.CallbackURL); err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", h.Name, err)
+		}
+		body := h.CallbackTemplate
+		if body == "" {
+			body = defaultCallbackTemplate
+		}
+		if compiled.bodyTpl, err = newTemplate(h.Name+" callback body", body); err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", h.Name, err)
+		}
+	}
+	return compiled, nil
+}
+
+// callbackData 回调模板的数据
+type callbackData struct {
+	Hook      string
+	ChatID    string // 映射出的会话标识，不含 hook 名称
+	MessageID string // 被回复的入站消息 ID
+	Content   string
+	Payload   any // 入站请求体，进程重启后为空
+}
+
+// templateFuncs 模板函数：json 编码为 JSON 字面量，path 按 JSON 路径取值
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"path": func(doc any, path string) (string, error) {
+		segments, err := parsePath(path)
+		if err != nil {
+			return "", err
+		}
+		v, _ := lookup(doc, segments)
+		return stringify(v), nil
+	},
+}
+
+func newTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+func render(tpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// optional 取可选字段，未配置路径时返回空字符串而不是整个文档
+func optional(payload any, segments []string) string {
+	if segments == nil {
+		return ""
+	}
+	v, _ := lookup(payload, segments)
+	return stringify(v)
+}
+
+func newMessageID() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
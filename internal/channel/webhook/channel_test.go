@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mote/pkg/channel"
+)
+
+const gitlabNote = `{
+	"object_kind": "note",
+	"user": {"username": "ann", "name": "Ann"},
+	"project": {"id": 42},
+	"issue": {"iid": 7},
+	"object_attributes": {"id": 9001, "note": "@mote why is CI red?"}
+}`
+
+func TestWebhookChannel_GitLabRoundTrip(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		gotPath  string
+		gotToken string
+		gotBody  map[string]string
+	)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		gotPath = r.URL.Path
+		gotToken = r.Header.Get("PRIVATE-TOKEN")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer callback.Close()
+
+	ch := New(Config{
+		Trigger: channel.TriggerConfig{Prefix: "@mote"},
+		Reply:   channel.ReplyConfig{Prefix: "[Mote]"},
+		Hooks: []Hook{{
+			Name:        "gitlab",
+			Auth:        AuthBearer,
+			Secret:      "s3cret",
+			TokenHeader: "X-Gitlab-Token",
+			Match:       []Match{{Path: "object_kind", Value: "note"}},
+			Mapping: Mapping{
+				Content:    "$.object_attributes.note",
+				ChatID:     "$.issue.iid",
+				SenderID:   "user.username",
+				SenderName: "user.name",
+				MessageID:  "object_attributes.id",
+			},
+			CallbackURL:      callback.URL + `/projects/{{path .Payload "project.id"}}/issues/{{.ChatID}}/notes`,
+			CallbackHeaders:  map[string]string{"PRIVATE-TOKEN": "glpat"},
+			CallbackTemplate: `{"body": {{json .Content}}}`,
+		}},
+	})
+	received := make(chan channel.InboundMessage, 2)
+	ch.OnMessage(func(ctx context.Context, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	post := func(name, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/channels/webhook/"+name, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("X-Gitlab-Token", token)
+		}
+		rr := httptest.NewRecorder()
+		ch.ServeWebhook(rr, req, name)
+		return rr
+	}
+
+	if rr := post("gitlab", "wrong", gitlabNote); rr.Code != http.StatusUnauthorized {
+		t.Errorf("bad token: status %d", rr.Code)
+	}
+	if rr := post("jira", "s3cret", gitlabNote); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown hook: status %d", rr.Code)
+	}
+	if rr := post("gitlab", "s3cret", `{"object_kind": "push"}`); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "ignored") {
+		t.Errorf("non-matching event: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := post("gitlab", "s3cret", "not json"); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid body: status %d", rr.Code)
+	}
+	if rr := post("gitlab", "s3cret", gitlabNote); rr.Code != http.StatusAccepted {
+		t.Fatalf("note: %d %s", rr.Code, rr.Body.String())
+	}
+
+	var msg channel.InboundMessage
+	select {
+	case msg = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+	if msg.Content != "why is CI red?" || msg.ChatID != "gitlab:7" || msg.SenderID != "ann" || msg.SenderName != "Ann" || msg.ID != "9001" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	if err := ch.SendMessage(context.Background(), channel.OutboundMessage{ChatID: msg.ChatID, Content: "A flaky test.", ReplyToID: msg.ID}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if gotPath != "/projects/42/issues/7/notes" || gotToken != "glpat" || gotBody["body"] != "[Mote]\nA flaky test." {
+		t.Errorf("callback: path=%s token=%s body=%v", gotPath, gotToken, gotBody)
+	}
+}
+
+func TestWebhookChannel_HMACAndContentTemplate(t *testing.T) {
+	ch := New(Config{Hooks: []Hook{{
+		Name:   "alerts",
+		Auth:   AuthHMAC,
+		Secret: "key",
+		Mapping: Mapping{
+			ContentTemplate: `{{range .alerts}}{{.labels.alertname}} is {{.status}}; {{end}}`,
+			ChatID:          "groupKey",
+		},
+	}}})
+	received := make(chan channel.InboundMessage, 1)
+	ch.OnMessage(func(ctx context.Context, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	body := `{"groupKey": "g1", "alerts": [{"status": "firing", "labels": {"alertname": "DiskFull"}}]}`
+	mac := hmac.New(sha256.New, []byte("key"))
+	_, _ = io.WriteString(mac, body)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("X-Signature-256", "sha256=deadbeef")
+	rr := httptest.NewRecorder()
+	ch.ServeWebhook(rr, req, "alerts")
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	rr = httptest.NewRecorder()
+	ch.ServeWebhook(rr, req, "alerts")
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("signed request: %d %s", rr.Code, rr.Body.String())
+	}
+	select {
+	case msg := <-received:
+		if msg.Content != "DiskFull is firing;" || msg.ChatID != "alerts:g1" || msg.ID == "" {
+			t.Errorf("unexpected message: %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+
+	// Without a callback the reply is dropped
+	if err := ch.SendMessage(context.Background(), channel.OutboundMessage{ChatID: "alerts:g1", Content: "ok"}); err != nil {
+		t.Errorf("SendMessage failed: %v", err)
+	}
+}
+
+func TestWebhookChannel_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		hook Hook
+	}{
+		{"reserved name", Hook{Name: "config", Auth: AuthNone, Mapping: Mapping{Content: "text"}}},
+		{"missing auth", Hook{Name: "a", Mapping: Mapping{Content: "text"}}},
+		{"missing secret", Hook{Name: "a", Auth: AuthHMAC, Mapping: Mapping{Content: "text"}}},
+		{"missing content", Hook{Name: "a", Auth: AuthNone}},
+		{"bad path", Hook{Name: "a", Auth: AuthNone, Mapping: Mapping{Content: "items[x]"}}},
+		{"bad template", Hook{Name: "a", Auth: AuthNone, Mapping: Mapping{Content: "text"}, CallbackURL: "{{.Nope"}},
+	}
+	for _, tt := range tests {
+		if err := New(Config{Hooks: []Hook{tt.hook}}).Start(context.Background()); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	var doc any
+	_ = json.Unmarshal([]byte(`{"a": {"b": [{"c": "x"}, {"c": 2}]}, "n": 12345678901}`), &doc)
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"$.a.b[0].c", "x", true},
+		{"a.b[1].c", "2", true},
+		{"a.b[-1].c", "2", true},
+		{"a.b[2].c", "", false},
+		{"a.missing", "", false},
+		{"a.b[0]", `{"c":"x"}`, true},
+	}
+	for _, tt := range tests {
+		segments, err := parsePath(tt.path)
+		if err != nil {
+			t.Fatalf("parsePath(%q): %v", tt.path, err)
+		}
+		v, ok := lookup(doc, segments)
+		if ok != tt.ok || (ok && stringify(v) != tt.want) {
+			t.Errorf("lookup(%q) = %v, %v", tt.path, stringify(v), ok)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parsePath 解析简化的 JSON 路径，如 "$.object_attributes.note"、"alerts[0].labels.alertname"。
+// 支持点号分隔的字段名和 [n] 数组下标，"$" 或空路径表示整个文档。
+func parsePath(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name == "" && rest == "" {
+			return nil, fmt.Errorf("invalid path %q: empty segment", path)
+		}
+		if name != "" {
+			segments = append(segments, name)
+		}
+		for rest != "" {
+			index, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("invalid path %q: missing ]", path)
+			}
+			if _, err := strconv.Atoi(index); err != nil {
+				return nil, fmt.Errorf("invalid path %q: bad index %q", path, index)
+			}
+			segments = append(segments, "["+index)
+			if after == "" {
+				break
+			}
+			if !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("invalid path %q: unexpected %q", path, after)
+			}
+			rest = after[1:]
+		}
+	}
+	return segments, nil
+}
+
+// lookup 按路径在解码后的 JSON 文档中取值
+func lookup(doc any, segments []string) (any, bool) {
+	cur := doc
+	for _, seg := range segments {
+		if strings.HasPrefix(seg, "[") {
+			arr, ok := cur.([]any)
+			if !ok {
+				return nil, false
+			}
+			i, _ := strconv.Atoi(seg[1:])
+			if i < 0 {
+				i += len(arr)
+			}
+			if i < 0 || i >= len(arr) {
+				return nil, false
+			}
+			cur = arr[i]
+			continue
+		}
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = obj[seg]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// stringify 把取到的值转换为字符串：字符串原样返回，数字去掉多余的小数，对象和数组编码为 JSON
+func stringify(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
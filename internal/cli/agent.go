@@ -0,0 +1,379 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	v1 "mote/api/v1"
+	"mote/internal/agentbundle"
+	"mote/internal/config"
+	"mote/internal/prompts"
+	"mote/internal/skills"
+)
+
+// NewAgentCmd creates the agent packaging command.
+func NewAgentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Export and import agent bundles",
+		Long: `Share agents between Mote installations.
+
+An agent bundle contains the agent config, the sub-agents it references
+through steps, the skills providing its tools, selected prompt files,
+the MCP server declarations its tools come from, and version metadata.`,
+		Example: `  # Export an agent with its dependencies
+  mote agent export researcher -o researcher.moteagent
+
+  # Preview what an import would change
+  mote agent import researcher.moteagent --dry-run
+
+  # Import, renaming agents that already exist locally
+  mote agent import researcher.moteagent --on-conflict rename`,
+	}
+
+	cmd.AddCommand(newAgentExportCmd())
+	cmd.AddCommand(newAgentImportCmd())
+
+	return cmd
+}
+
+func newAgentExportCmd() *cobra.Command {
+	var (
+		output      string
+		version     string
+		description string
+		promptNames []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export <agent-name>",
+		Short: "Export an agent as a versioned bundle",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := GetCLIContext(cmd)
+			if cliCtx == nil {
+				return fmt.Errorf("CLI context not initialized")
+			}
+
+			src, err := loadBundleSources(cliCtx.Config)
+			if err != nil {
+				return err
+			}
+
+			bundle, err := agentbundle.Export(args[0], src, agentbundle.ExportOptions{
+				Version:     version,
+				Description: description,
+				MoteVersion: Version,
+				Prompts:     promptNames,
+			})
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				output = fmt.Sprintf("%s-%s.moteagent", args[0], bundle.Manifest.Version)
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("create bundle file: %w", err)
+			}
+			if err := bundle.Write(f); err != nil {
+				f.Close()
+				return fmt.Errorf("write bundle: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+
+			m := bundle.Manifest
+			fmt.Printf("Exported %s v%s to %s\n", m.Name, m.Version, output)
+			fmt.Printf("  Agents:      %s\n", strings.Join(sortedAgentNames(m.Agents), ", "))
+			fmt.Printf("  Skills:      %d\n", len(m.Skills))
+			fmt.Printf("  Prompts:     %d\n", len(m.Prompts))
+			fmt.Printf("  MCP servers: %d\n", len(m.MCPServers))
+			for _, s := range m.MCPServers {
+				if len(s.Headers) > 0 {
+					fmt.Printf("  Note: header values for MCP server %q were not exported\n", s.Name)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output file (default <agent>-<version>.moteagent)")
+	cmd.Flags().StringVar(&version, "bundle-version", "1.0.0", "version recorded in the bundle")
+	cmd.Flags().StringVar(&description, "description", "", "bundle description (default: agent description)")
+	cmd.Flags().StringSliceVar(&promptNames, "prompt", nil, "prompt name to include (repeatable)")
+
+	return cmd
+}
+
+func newAgentImportCmd() *cobra.Command {
+	var (
+		onConflict string
+		dryRun     bool
+		jsonOutput bool
+		serverURL  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <bundle-file>",
+		Short: "Import an agent bundle",
+		Long: `Import an agent bundle, resolving conflicts with existing agents,
+skills, prompts and MCP servers, and reporting tools the agents need
+that are not available.
+
+Tool availability is checked against the running Mote server; if it is
+not reachable only bundled skills and MCP servers are considered.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := GetCLIContext(cmd)
+			if cliCtx == nil {
+				return fmt.Errorf("CLI context not initialized")
+			}
+
+			policy, err := agentbundle.ParseConflictPolicy(onConflict)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("open bundle: %w", err)
+			}
+			bundle, err := agentbundle.Read(f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+
+			src, err := loadBundleSources(cliCtx.Config)
+			if err != nil {
+				return err
+			}
+			env := agentbundle.Environment{
+				Agents:     src.Agents,
+				KnownTools: fetchToolNames(serverURL),
+			}
+			for _, s := range src.Skills {
+				env.SkillIDs = append(env.SkillIDs, s.ID)
+			}
+			for _, p := range src.Prompts {
+				env.Prompts = append(env.Prompts, p.Name)
+			}
+			for _, s := range src.MCPServers {
+				env.MCPServers = append(env.MCPServers, s.Name)
+			}
+
+			plan, err := bundle.Plan(env, policy)
+			if err != nil {
+				return fmt.Errorf("%w (use --on-conflict skip, overwrite or rename)", err)
+			}
+
+			if jsonOutput {
+				data, _ := json.MarshalIndent(plan, "", "  ")
+				fmt.Println(string(data))
+			} else {
+				printImportPlan(bundle.Manifest, plan)
+			}
+			if dryRun {
+				return nil
+			}
+
+			dirs, err := bundleDirs()
+			if err != nil {
+				return err
+			}
+			err = bundle.Apply(plan, agentbundle.Destination{
+				SkillsDir:  dirs.skills,
+				PromptsDir: dirs.prompts,
+				SaveAgent: func(name string, agent config.AgentConfig, overwrite bool) error {
+					if overwrite {
+						return config.UpdateAgent(name, agent)
+					}
+					return config.AddAgent(name, agent)
+				},
+				AddMCPServer: func(s agentbundle.MCPServer) error {
+					return v1.AddMCPServerToConfig(v1.MCPServerPersist{
+						Name:    s.Name,
+						Type:    s.Type,
+						URL:     s.URL,
+						Headers: s.Headers,
+						Command: s.Command,
+						Args:    s.Args,
+					})
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			if !jsonOutput {
+				fmt.Println("\nImport complete. Restart the server or reload agents and skills to use it.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "fail", "conflict policy: fail, skip, overwrite or rename")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the import plan without changing anything")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output the import plan in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL used to check tool availability")
+
+	return cmd
+}
+
+type agentBundleDirs struct {
+	skills  string
+	prompts string
+}
+
+func bundleDirs() (agentBundleDirs, error) {
+	configDir, err := config.DefaultConfigDir()
+	if err != nil {
+		return agentBundleDirs{}, err
+	}
+	return agentBundleDirs{
+		skills:  filepath.Join(configDir, "skills"),
+		prompts: filepath.Join(configDir, "prompts"),
+	}, nil
+}
+
+// loadBundleSources gathers the local agents, skills, prompts and MCP
+// servers that bundles are exported from and checked against on import.
+func loadBundleSources(cfg *config.Config) (agentbundle.Sources, error) {
+	dirs, err := bundleDirs()
+	if err != nil {
+		return agentbundle.Sources{}, err
+	}
+
+	src := agentbundle.Sources{Agents: cfg.Agents}
+	if src.Agents == nil {
+		src.Agents = map[string]config.AgentConfig{}
+	}
+
+	skillManager := skills.NewManager(skills.ManagerConfig{SkillsDir: dirs.skills})
+	if _, statErr := os.Stat(dirs.skills); statErr == nil {
+		if err := skillManager.ScanDirectory(dirs.skills); err != nil {
+			return agentbundle.Sources{}, fmt.Errorf("scan skills: %w", err)
+		}
+	}
+	for _, status := range skillManager.ListSkills() {
+		if status.Skill != nil {
+			src.Skills = append(src.Skills, status.Skill)
+		}
+	}
+
+	promptManager := prompts.NewManager()
+	if err := promptManager.LoadFromDirectory(dirs.prompts); err != nil {
+		return agentbundle.Sources{}, fmt.Errorf("load prompts: %w", err)
+	}
+	for _, p := range promptManager.ListPrompts() {
+		if p.FilePath != "" {
+			src.Prompts = append(src.Prompts, agentbundle.PromptFile{Name: p.Name, Path: p.FilePath})
+		}
+	}
+
+	servers, err := v1.LoadMCPServersConfigPublic()
+	if err != nil {
+		return agentbundle.Sources{}, fmt.Errorf("load MCP servers: %w", err)
+	}
+	for _, s := range servers {
+		src.MCPServers = append(src.MCPServers, agentbundle.MCPServer{
+			Name:    s.Name,
+			Type:    s.Type,
+			URL:     s.URL,
+			Command: s.Command,
+			Args:    s.Args,
+			Headers: s.Headers,
+		})
+	}
+	return src, nil
+}
+
+// fetchToolNames returns the tool names registered on the running server,
+// or nil if the server cannot be reached.
+func fetchToolNames(serverURL string) []string {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(serverURL + "/api/v1/tools")
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	var tools []toolResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tools); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func printImportPlan(m agentbundle.Manifest, plan *agentbundle.Plan) {
+	fmt.Printf("Bundle: %s v%s", m.Name, m.Version)
+	if m.MoteVersion != "" {
+		fmt.Printf(" (exported by mote %s)", m.MoteVersion)
+	}
+	fmt.Println()
+
+	printItems := func(title string, items []agentbundle.ItemAction) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Printf("\n%s:\n", title)
+		for _, item := range items {
+			if item.Action == agentbundle.ActionRename {
+				fmt.Printf("  %-10s %s -> %s\n", item.Action, item.Name, item.Target)
+			} else {
+				fmt.Printf("  %-10s %s\n", item.Action, item.Name)
+			}
+		}
+	}
+	printItems("Agents", plan.Agents)
+	printItems("Skills", plan.Skills)
+	printItems("Prompts", plan.Prompts)
+	printItems("MCP servers", plan.MCPServers)
+
+	if plan.ToolCheckSkipped {
+		fmt.Println("\nWarning: Mote server not reachable; tool availability was not checked.")
+	} else if len(plan.MissingTools) > 0 {
+		fmt.Println("\nMissing tools (agents will not be able to call these):")
+		for _, t := range plan.MissingTools {
+			fmt.Printf("  - %s\n", t)
+		}
+	}
+
+	if len(plan.MissingHeaders) > 0 {
+		servers := make([]string, 0, len(plan.MissingHeaders))
+		for name := range plan.MissingHeaders {
+			servers = append(servers, name)
+		}
+		sort.Strings(servers)
+		fmt.Println("\nMCP server headers to fill in after import:")
+		for _, name := range servers {
+			fmt.Printf("  - %s: %s\n", name, strings.Join(plan.MissingHeaders[name], ", "))
+		}
+	}
+}
+
+func sortedAgentNames(agents map[string]config.AgentConfig) []string {
+	names := make([]string, 0, len(agents))
+	for name := range agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
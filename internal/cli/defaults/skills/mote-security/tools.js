@@ -57,7 +57,7 @@ function policyCheck(args) {
         var dangerous = false;
         var reason = '';
         
-        if (tool === 'shell') {
+        if (tool === 'shell' || tool === 'shell_session' || tool === 'process') {
           if (arguments_str.indexOf('rm -rf') !== -1) {
             dangerous = true;
             reason = 'rm -rf is prohibited';
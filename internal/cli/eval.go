@@ -0,0 +1,469 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"mote/internal/config"
+	"mote/internal/eval"
+)
+
+// evalPollInterval is how often a started eval run is polled for completion.
+const evalPollInterval = 2 * time.Second
+
+// NewEvalCmd creates the agent evaluation command.
+func NewEvalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Run golden test suites against agents",
+		Long: `Evaluate agents with YAML test suites.
+
+A suite lists inputs for one agent together with the tools it is expected
+(or forbidden) to call and assertions on its final answer: contains, regex,
+json_path, or a judge rubric graded by a model. Runs are executed by the
+Mote server and stored, so pass rates can be tracked over time and two
+models or two agent versions can be compared.`,
+		Example: `  # Run a suite against the agent's configured model
+  mote eval run evals/researcher.yaml
+
+  # Run against two models and diff the results
+  mote eval compare --suite evals/researcher.yaml --model gpt-4o --model claude-sonnet-4
+
+  # Compare the installed agent with a candidate config
+  mote eval compare --suite evals/researcher.yaml --agent-config researcher-v2.yaml
+
+  # Diff two stored runs
+  mote eval compare eval_1712 eval_1745`,
+	}
+
+	cmd.AddCommand(newEvalRunCmd())
+	cmd.AddCommand(newEvalListCmd())
+	cmd.AddCommand(newEvalShowCmd())
+	cmd.AddCommand(newEvalCompareCmd())
+
+	return cmd
+}
+
+func newEvalRunCmd() *cobra.Command {
+	var (
+		models      []string
+		label       string
+		agentConfig string
+		minPassRate float64
+		jsonOutput  bool
+		serverURL   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run <suite.yaml|dir>",
+		Short: "Run an eval suite (or every suite in a directory)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths, err := suitePaths(args[0])
+			if err != nil {
+				return err
+			}
+			agent, err := loadAgentConfigFile(agentConfig)
+			if err != nil {
+				return err
+			}
+
+			var all []*eval.Run
+			for _, path := range paths {
+				runs, err := runEvalSuite(serverURL, path, models, label, agent)
+				if err != nil {
+					return err
+				}
+				all = append(all, runs...)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(all); err != nil {
+					return err
+				}
+			} else {
+				for _, run := range all {
+					printEvalRun(run, false)
+				}
+			}
+
+			for _, run := range all {
+				if run.PassRate() < minPassRate {
+					return fmt.Errorf("suite %s on %s: pass rate %.0f%% is below %.0f%%",
+						run.Suite, displayModel(run.Model), run.PassRate()*100, minPassRate*100)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&models, "model", "m", nil, "model to evaluate (repeatable; default: suite models, then the agent's model)")
+	cmd.Flags().StringVar(&label, "label", "", "label stored with the run, e.g. prompt-v2")
+	cmd.Flags().StringVar(&agentConfig, "agent-config", "", "YAML agent config to evaluate instead of the installed agent")
+	cmd.Flags().Float64Var(&minPassRate, "min-pass-rate", 0, "exit with an error if any run's pass rate is below this (0-1)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+func newEvalListCmd() *cobra.Command {
+	var (
+		suite      string
+		limit      int
+		jsonOutput bool
+		serverURL  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List stored eval runs and their pass rates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q := url.Values{}
+			if suite != "" {
+				q.Set("suite", suite)
+			}
+			q.Set("limit", fmt.Sprint(limit))
+
+			var resp struct {
+				Runs []eval.Run `json:"runs"`
+			}
+			if err := evalRequest(http.MethodGet, serverURL+"/api/v1/eval/runs?"+q.Encode(), nil, &resp); err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Runs)
+			}
+			if len(resp.Runs) == 0 {
+				fmt.Println("No eval runs found.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tSUITE\tMODEL\tVERSION\tLABEL\tPASSED\tRATE\tTOKENS\tSTARTED")
+			for _, r := range resp.Runs {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d/%d\t%.0f%%\t%d\t%s\n",
+					r.ID, r.Suite, displayModel(r.Model), r.AgentVersion, valueOrDefault(r.Label, "-"),
+					r.Passed, r.Total, r.PassRate()*100, r.TotalTokens, r.StartedAt.Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&suite, "suite", "", "only show runs of this suite")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "number of runs to show")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+func newEvalShowCmd() *cobra.Command {
+	var (
+		jsonOutput bool
+		serverURL  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "show <run-id>",
+		Short: "Show the case results of an eval run",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var run eval.Run
+			if err := evalRequest(http.MethodGet, serverURL+"/api/v1/eval/runs/"+url.PathEscape(args[0]), nil, &run); err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(run)
+			}
+			printEvalRun(&run, true)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+func newEvalCompareCmd() *cobra.Command {
+	var (
+		suitePath   string
+		models      []string
+		agentConfig string
+		jsonOutput  bool
+		serverURL   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compare [<base-run-id> <other-run-id>]",
+		Short: "Diff two eval runs, two models, or two agent versions",
+		Long: `Compare eval results case by case.
+
+With two run IDs, the stored runs are compared. With --suite, the suite is
+run first: either against two --model values, or against the installed
+agent and the candidate given by --agent-config.`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var baseID, otherID string
+			switch {
+			case len(args) == 2:
+				baseID, otherID = args[0], args[1]
+			case suitePath != "" && len(models) == 2 && agentConfig == "":
+				base, err := runEvalSuite(serverURL, suitePath, models[:1], "", nil)
+				if err != nil {
+					return err
+				}
+				other, err := runEvalSuite(serverURL, suitePath, models[1:], "", nil)
+				if err != nil {
+					return err
+				}
+				baseID, otherID = base[0].ID, other[0].ID
+			case suitePath != "" && agentConfig != "" && len(models) <= 1:
+				candidate, err := loadAgentConfigFile(agentConfig)
+				if err != nil {
+					return err
+				}
+				base, err := runEvalSuite(serverURL, suitePath, models, "installed", nil)
+				if err != nil {
+					return err
+				}
+				other, err := runEvalSuite(serverURL, suitePath, models, "candidate", candidate)
+				if err != nil {
+					return err
+				}
+				baseID, otherID = base[0].ID, other[0].ID
+			default:
+				return fmt.Errorf("give two run IDs, or --suite with two --model values or with --agent-config")
+			}
+
+			q := url.Values{"base": {baseID}, "other": {otherID}}
+			var cmp eval.Comparison
+			if err := evalRequest(http.MethodGet, serverURL+"/api/v1/eval/compare?"+q.Encode(), nil, &cmp); err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(cmp)
+			}
+			printEvalComparison(&cmp)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&suitePath, "suite", "", "suite file to run before comparing")
+	cmd.Flags().StringSliceVarP(&models, "model", "m", nil, "model to run the suite against (give two to compare models)")
+	cmd.Flags().StringVar(&agentConfig, "agent-config", "", "candidate agent config to compare with the installed agent")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+// suitePaths expands a suite file or directory into suite file paths.
+func suitePaths(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(path, pattern))
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no suite files in %s", path)
+	}
+	return paths, nil
+}
+
+// loadAgentConfigFile reads a candidate agent config; an empty path returns nil.
+func loadAgentConfigFile(path string) (*config.AgentConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read agent config: %w", err)
+	}
+	var agent config.AgentConfig
+	if err := yaml.Unmarshal(data, &agent); err != nil {
+		return nil, fmt.Errorf("parse agent config: %w", err)
+	}
+	return &agent, nil
+}
+
+// runEvalSuite validates a suite locally, starts it on the server and waits
+// for every run to finish.
+func runEvalSuite(serverURL, path string, models []string, label string, agent *config.AgentConfig) ([]*eval.Run, error) {
+	suite, err := eval.LoadSuite(path)
+	if err != nil {
+		return nil, err
+	}
+	// Re-encode so a suite name defaulted from the file name reaches the server.
+	data, err := yaml.Marshal(suite)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Running %s (%d cases)...\n", suite.Name, len(suite.Cases))
+	var resp struct {
+		Runs []*eval.Run `json:"runs"`
+	}
+	err = evalRequest(http.MethodPost, serverURL+"/api/v1/eval/runs", map[string]any{
+		"suite":  string(data),
+		"models": models,
+		"label":  label,
+		"agent":  agent,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Runs) == 0 {
+		return nil, fmt.Errorf("server returned no runs for %s", suite.Name)
+	}
+
+	runs := make([]*eval.Run, 0, len(resp.Runs))
+	for _, started := range resp.Runs {
+		run, err := waitEvalRun(serverURL, started.ID)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// waitEvalRun polls a run until it is no longer running.
+func waitEvalRun(serverURL, id string) (*eval.Run, error) {
+	for {
+		var run eval.Run
+		if err := evalRequest(http.MethodGet, serverURL+"/api/v1/eval/runs/"+url.PathEscape(id), nil, &run); err != nil {
+			return nil, err
+		}
+		if run.Status != eval.StatusRunning {
+			if run.Status == eval.StatusFailed {
+				return nil, fmt.Errorf("eval run %s failed after %d/%d cases", run.ID, len(run.Results), run.Total)
+			}
+			return &run, nil
+		}
+		time.Sleep(evalPollInterval)
+	}
+}
+
+// evalRequest sends a JSON request to the eval API and decodes the reply into out.
+func evalRequest(method, endpoint string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w\nIs the server running? Start it with: mote serve", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func printEvalRun(run *eval.Run, verbose bool) {
+	fmt.Printf("%s  %s on %s (agent %s, version %s)\n",
+		run.ID, run.Suite, displayModel(run.Model), run.Agent, run.AgentVersion)
+	for _, r := range run.Results {
+		mark := "✓"
+		if !r.Passed {
+			mark = "✗"
+		}
+		fmt.Printf("  %s %-30s %6dms %6d tokens\n", mark, r.Case, r.DurationMs, r.TotalTokens)
+		for _, f := range r.Failures {
+			fmt.Printf("      - %s\n", f)
+		}
+		if verbose {
+			if len(r.ToolCalls) > 0 {
+				fmt.Printf("      tools: %s\n", strings.Join(r.ToolCalls, ", "))
+			}
+			if r.Output != "" {
+				fmt.Printf("      output: %s\n", truncate(strings.ReplaceAll(r.Output, "\n", " "), 200))
+			}
+		}
+	}
+	fmt.Printf("  Passed %d/%d (%.0f%%), %d tokens\n\n", run.Passed, run.Total, run.PassRate()*100, run.TotalTokens)
+}
+
+func printEvalComparison(cmp *eval.Comparison) {
+	describe := func(r eval.Run) string {
+		s := fmt.Sprintf("%s: %s, version %s", r.ID, displayModel(r.Model), r.AgentVersion)
+		if r.Label != "" {
+			s += ", " + r.Label
+		}
+		return s
+	}
+	fmt.Printf("Base:  %s  %d/%d\n", describe(cmp.Base), cmp.Base.Passed, cmp.Base.Total)
+	fmt.Printf("Other: %s  %d/%d\n", describe(cmp.Other), cmp.Other.Passed, cmp.Other.Total)
+	fmt.Printf("Pass rate: %+.0f%%  (fixed %d, regressed %d)\n\n", cmp.PassRateDelta*100, cmp.Fixed, cmp.Regressed)
+
+	status := func(ok *bool) string {
+		switch {
+		case ok == nil:
+			return "-"
+		case *ok:
+			return "pass"
+		default:
+			return "fail"
+		}
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CASE\tBASE\tOTHER\tCHANGE\tTOKENS Δ")
+	for _, c := range cmp.Cases {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%+d\n", c.Case, status(c.BaseOK), status(c.OtherOK), c.Change, c.TokenDelta)
+	}
+	w.Flush()
+}
+
+func displayModel(model string) string {
+	return valueOrDefault(model, "(agent default)")
+}
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +13,8 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"mote/internal/mcp/transport"
+
 	"github.com/spf13/cobra"
 )
 
@@ -35,11 +39,21 @@ and data sources. Use these commands to manage MCP server connections.`,
   # Add an HTTP server with headers (JSON format)
   mote mcp add-http myserver http://localhost:8001/mcp --headers '{"Authorization": "Bearer token"}'
 
+  # Authorize an HTTP server that requires OAuth
+  mote mcp auth myserver
+
   # Import servers from JSON config
   echo '{"local": {"type": "http", "url": "http://127.0.0.1:8001/mcp"}}' | mote mcp import -
 
   # List all available MCP tools
-  mote mcp tools`,
+  mote mcp tools
+
+  # List resources and read one
+  mote mcp resources
+  mote mcp read filesystem file:///home/user/notes.md
+
+  # Let a stdio MCP host use Mote's agents, prompts and memory
+  mote mcp serve`,
 	}
 
 	cmd.AddCommand(newMCPListCmd())
@@ -47,7 +61,11 @@ and data sources. Use these commands to manage MCP server connections.`,
 	cmd.AddCommand(newMCPAddHTTPCmd())
 	cmd.AddCommand(newMCPImportCmd())
 	cmd.AddCommand(newMCPRemoveCmd())
+	cmd.AddCommand(newMCPAuthCmd())
 	cmd.AddCommand(newMCPToolsCmd())
+	cmd.AddCommand(newMCPResourcesCmd())
+	cmd.AddCommand(newMCPReadCmd())
+	cmd.AddCommand(newMCPServeCmd())
 
 	return cmd
 }
@@ -220,6 +238,53 @@ func newMCPToolsCmd() *cobra.Command {
 	return cmd
 }
 
+func newMCPResourcesCmd() *cobra.Command {
+	var (
+		serverFilter string
+		jsonOutput   bool
+		serverURL    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "resources",
+		Short: "List MCP resources",
+		Long: `List all resources and resource templates provided by connected MCP servers.
+
+Resources can be attached to a chat message with @server:uri.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMCPResources(serverURL, serverFilter, jsonOutput)
+		},
+	}
+
+	cmd.Flags().StringVarP(&serverFilter, "server", "s", "", "filter by server name")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+func newMCPReadCmd() *cobra.Command {
+	var (
+		jsonOutput bool
+		serverURL  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "read <server> <uri>",
+		Short: "Read an MCP resource",
+		Long:  `Read a resource from a connected MCP server and print its contents.`,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMCPRead(serverURL, args[0], args[1], jsonOutput)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
 type mcpServerInfo struct {
 	Name      string `json:"name"`
 	Status    string `json:"status"`
@@ -242,6 +307,39 @@ type mcpToolsResponse struct {
 	Tools []mcpToolInfo `json:"tools"`
 }
 
+type mcpResourceInfo struct {
+	Server      string `json:"server"`
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+}
+
+type mcpResourceTemplateInfo struct {
+	Server      string `json:"server"`
+	URITemplate string `json:"uri_template"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type mcpResourcesResponse struct {
+	Resources []mcpResourceInfo         `json:"resources"`
+	Templates []mcpResourceTemplateInfo `json:"templates"`
+}
+
+type mcpResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+type mcpReadResourceResponse struct {
+	Server   string                `json:"server"`
+	URI      string                `json:"uri"`
+	Contents []mcpResourceContents `json:"contents"`
+}
+
 func runMCPList(serverURL string, jsonOutput bool) error {
 	client := &http.Client{Timeout: 30 * time.Second}
 
@@ -477,6 +575,143 @@ func runMCPRemove(serverURL, name string) error {
 	return nil
 }
 
+func newMCPAuthCmd() *cobra.Command {
+	var serverURL string
+	var revoke bool
+
+	cmd := &cobra.Command{
+		Use:   "auth <name>",
+		Short: "Authorize an HTTP MCP server with OAuth",
+		Long: `Start the OAuth authorization flow for a remote MCP server.
+
+Prints the URL to open in a browser. After access is granted, the token
+is stored by the Mote server, refreshed automatically, and the MCP server
+is reconnected.`,
+		Example: `  # Authorize a server
+  mote mcp auth myserver
+
+  # Forget the stored token
+  mote mcp auth myserver --revoke`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMCPAuth(serverURL, args[0], revoke)
+		},
+	}
+
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+	cmd.Flags().BoolVar(&revoke, "revoke", false, "remove the stored authorization")
+
+	return cmd
+}
+
+func runMCPAuth(serverURL, name string, revoke bool) error {
+	client := &http.Client{Timeout: 60 * time.Second}
+
+	method := http.MethodPost
+	url := fmt.Sprintf("%s/api/v1/mcp/servers/%s/oauth/authorize", serverURL, name)
+	if revoke {
+		method = http.MethodDelete
+		url = fmt.Sprintf("%s/api/v1/mcp/servers/%s/oauth", serverURL, name)
+	}
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w\nIs the server running? Start it with: mote serve", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if revoke {
+		fmt.Printf("✓ Removed authorization for MCP server '%s'\n", name)
+		return nil
+	}
+
+	var result struct {
+		AuthURL string `json:"auth_url"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	fmt.Printf("Open this URL in your browser to authorize '%s':\n\n  %s\n", name, result.AuthURL)
+	return nil
+}
+
+func newMCPServeCmd() *cobra.Command {
+	var serverURL string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve Mote as an MCP server over stdio",
+		Long: `Bridge an MCP host that launches servers over stdio to the MCP endpoint
+of the running Mote server (/mcp). What is exposed is configured under
+mcp.server.expose in the Mote config.`,
+		Example: `  # In the host's MCP config
+  {"mote": {"command": "mote", "args": ["mcp", "serve"]}}`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMCPServe(cmd.Context(), serverURL)
+		},
+	}
+
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+func runMCPServe(ctx context.Context, serverURL string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	remote := transport.NewStreamableHTTPClientTransport(strings.TrimSuffix(serverURL, "/")+"/mcp", nil)
+	if err := remote.Start(); err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer remote.Close()
+	local := transport.NewStdioServerTransport()
+	defer local.Close()
+
+	// Relay server messages to stdout until the host goes away
+	go func() {
+		defer cancel()
+		for {
+			data, err := remote.Receive(ctx)
+			if err != nil {
+				return
+			}
+			if err := local.Send(ctx, data); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		data, err := local.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, transport.ErrTransportClosed) {
+				return nil
+			}
+			return err
+		}
+		if err := remote.Send(ctx, data); err != nil {
+			return fmt.Errorf("failed to reach server: %w\nIs the server running? Start it with: mote serve", err)
+		}
+	}
+}
+
 func runMCPTools(serverURL, serverFilter string, jsonOutput bool) error {
 	client := &http.Client{Timeout: 30 * time.Second}
 
@@ -537,3 +772,103 @@ func runMCPTools(serverURL, serverFilter string, jsonOutput bool) error {
 
 	return nil
 }
+
+func runMCPResources(serverURL, serverFilter string, jsonOutput bool) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	url := fmt.Sprintf("%s/api/v1/mcp/resources", serverURL)
+	if serverFilter != "" {
+		url = fmt.Sprintf("%s?server=%s", url, serverFilter)
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w\nIs the server running? Start it with: mote serve", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response mcpResourcesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(response)
+	}
+
+	if len(response.Resources) == 0 && len(response.Templates) == 0 {
+		fmt.Println("No MCP resources available.")
+		if serverFilter != "" {
+			fmt.Printf("(Filtered by server: %s)\n", serverFilter)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tURI\tNAME")
+	fmt.Fprintln(w, "------\t---\t----")
+	for _, r := range response.Resources {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Server, r.URI, r.Name)
+	}
+	for _, t := range response.Templates {
+		fmt.Fprintf(w, "%s\t%s\t%s (template)\n", t.Server, t.URITemplate, t.Name)
+	}
+	w.Flush()
+
+	fmt.Printf("\nTotal: %d resources, %d templates\n", len(response.Resources), len(response.Templates))
+	fmt.Println("Attach a resource to a chat message with @server:uri")
+
+	return nil
+}
+
+func runMCPRead(serverURL, server, uri string, jsonOutput bool) error {
+	client := &http.Client{Timeout: 60 * time.Second}
+
+	payload, _ := json.Marshal(map[string]string{"server": server, "uri": uri})
+	resp, err := client.Post(serverURL+"/api/v1/mcp/resources/read", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w\nIs the server running? Start it with: mote serve", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response mcpReadResourceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(response)
+	}
+
+	for i, c := range response.Contents {
+		if len(response.Contents) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("--- %s ---\n", c.URI)
+		}
+		if c.Blob != "" {
+			fmt.Printf("[binary content: %s, %d bytes base64]\n", c.MimeType, len(c.Blob))
+			continue
+		}
+		fmt.Print(c.Text)
+		if !strings.HasSuffix(c.Text, "\n") {
+			fmt.Println()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"mote/internal/config"
+	"mote/internal/policy"
+)
+
+// NewPolicyCmd creates the policy command.
+func NewPolicyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Inspect and test the tool policy",
+		Long: `Inspect and test the tool execution policy.
+
+The policy is read from policy.file in the config (default
+~/.mote/policy.yaml). Besides allow/block lists, dangerous operation
+patterns and parameter rules it can hold conditional rules written as
+expressions over the call, the agent, the workspace, the time of day and
+the session's call counts, and overlays that adjust the policy for
+specific agents or workspaces.`,
+	}
+
+	cmd.AddCommand(newPolicyTestCmd())
+
+	return cmd
+}
+
+func newPolicyTestCmd() *cobra.Command {
+	var (
+		policyFile string
+		at         string
+		agent      string
+		workspace  string
+		expect     string
+		jsonOutput bool
+		serverURL  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test <call.json|decisions.jsonl|->",
+		Short: "Evaluate recorded tool calls against a policy",
+		Long: `Evaluate recorded tool calls against a policy without running them.
+
+The input holds one or more JSON records: policy decisions as written to
+policy.decision_log or returned by GET /api/v1/policy/decisions, or bare
+tool calls such as {"name": "shell", "arguments": {"command": "ls"}}.
+Decisions are replayed with the time and call counts they were recorded
+with, and any change from the recorded outcome is reported.
+
+Without --policy the calls are checked against the running server's
+policy; with --policy they are evaluated locally against a policy file.`,
+		Example: `  # Would this call be allowed for the researcher agent at night?
+  echo '{"name":"shell","arguments":{"command":"git push"}}' | \
+    mote policy test - --agent researcher --at 2026-03-02T23:30:00+08:00
+
+  # Replay yesterday's decisions against a candidate policy
+  mote policy test ~/.mote/policy-decisions.jsonl --policy policy-v2.yaml
+
+  # Fail in CI unless the call is blocked
+  mote policy test testdata/rm-rf.json --policy policy.yaml --expect block`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if expect != "" && expect != "allow" && expect != "approve" && expect != "block" {
+				return fmt.Errorf("invalid --expect %q (must be allow, approve or block)", expect)
+			}
+			var atTime time.Time
+			if at != "" {
+				t, err := time.Parse(time.RFC3339, at)
+				if err != nil {
+					return fmt.Errorf("invalid --at (want RFC 3339, e.g. 2026-03-02T23:30:00Z): %w", err)
+				}
+				atTime = t
+			}
+
+			records, err := readPolicyTestRecords(args[0])
+			if err != nil {
+				return err
+			}
+
+			evaluate := func(call *policy.ToolCall, ec policy.EvalContext) (*policy.PolicyResult, error) {
+				return checkPolicyOnServer(serverURL, call, ec)
+			}
+			if policyFile != "" {
+				path, err := config.ExpandPath(policyFile)
+				if err != nil {
+					return err
+				}
+				cfg, err := policy.LoadConfig(path)
+				if err != nil {
+					return err
+				}
+				executor := policy.NewPolicyExecutor(&cfg.ToolPolicy)
+				executor.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+				evaluate = func(call *policy.ToolCall, ec policy.EvalContext) (*policy.PolicyResult, error) {
+					return executor.Evaluate(context.Background(), call, ec)
+				}
+			}
+
+			var outcomes []policyTestOutcome
+			mismatches := 0
+			for _, rec := range records {
+				if agent != "" {
+					rec.Call.AgentID = agent
+				}
+				if workspace != "" {
+					rec.Call.WorkspacePath = workspace
+				}
+				if !atTime.IsZero() {
+					rec.Context.Time = atTime
+				}
+				if rec.Context.Time.IsZero() {
+					rec.Context.Time = time.Now()
+				}
+
+				result, err := evaluate(&rec.Call, rec.Context)
+				if err != nil {
+					return err
+				}
+				out := policyTestOutcome{
+					Call:     rec.Call,
+					Context:  rec.Context,
+					Result:   *result,
+					Decision: policyOutcome(result),
+				}
+				if rec.Recorded != nil {
+					out.Recorded = policyOutcome(rec.Recorded)
+				}
+				if expect != "" && out.Decision != expect {
+					mismatches++
+				}
+				outcomes = append(outcomes, out)
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(outcomes); err != nil {
+					return err
+				}
+			} else {
+				printPolicyTestOutcomes(outcomes)
+			}
+
+			if mismatches > 0 {
+				return fmt.Errorf("%d of %d calls did not %s", mismatches, len(outcomes), expect)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&policyFile, "policy", "", "evaluate locally against this policy file instead of the server's policy")
+	cmd.Flags().StringVar(&at, "at", "", "evaluate as if at this time (RFC 3339)")
+	cmd.Flags().StringVar(&agent, "agent", "", "evaluate as this agent")
+	cmd.Flags().StringVar(&workspace, "workspace", "", "evaluate with this workspace bound")
+	cmd.Flags().StringVar(&expect, "expect", "", "exit with an error unless every call gets this outcome: allow, approve or block")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+// policyTestRecord is a call to evaluate, with the outcome it was recorded
+// with if it came from a decision log.
+type policyTestRecord struct {
+	Call     policy.ToolCall
+	Context  policy.EvalContext
+	Recorded *policy.PolicyResult
+}
+
+// policyTestOutcome is the result of evaluating one record.
+type policyTestOutcome struct {
+	Call     policy.ToolCall     `json:"call"`
+	Context  policy.EvalContext  `json:"context"`
+	Result   policy.PolicyResult `json:"result"`
+	Decision string              `json:"decision"`
+	Recorded string              `json:"recorded,omitempty"`
+}
+
+// recordedCall accepts arguments as a JSON string (as recorded) or as an
+// object (easier to write by hand).
+type recordedCall struct {
+	Name          string          `json:"name"`
+	Arguments     json.RawMessage `json:"arguments"`
+	SessionID     string          `json:"session_id"`
+	AgentID       string          `json:"agent_id"`
+	WorkspacePath string          `json:"workspace_path"`
+}
+
+func (c *recordedCall) toolCall() (policy.ToolCall, error) {
+	call := policy.ToolCall{
+		Name:          c.Name,
+		SessionID:     c.SessionID,
+		AgentID:       c.AgentID,
+		WorkspacePath: c.WorkspacePath,
+	}
+	raw := bytes.TrimSpace(c.Arguments)
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+	case raw[0] == '"':
+		if err := json.Unmarshal(raw, &call.Arguments); err != nil {
+			return call, err
+		}
+	default:
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			return call, err
+		}
+		call.Arguments = buf.String()
+	}
+	return call, nil
+}
+
+// readPolicyTestRecords reads decision records or bare calls from a file,
+// or from stdin when path is "-".
+func readPolicyTestRecords(path string) ([]policyTestRecord, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var records []policyTestRecord
+	dec := json.NewDecoder(r)
+	for {
+		var raw struct {
+			recordedCall
+			Call    *recordedCall        `json:"call"`
+			Context *policy.EvalContext  `json:"context"`
+			Result  *policy.PolicyResult `json:"result"`
+		}
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+
+		source := &raw.recordedCall
+		if raw.Call != nil {
+			source = raw.Call
+		}
+		call, err := source.toolCall()
+		if err != nil {
+			return nil, fmt.Errorf("record %d: invalid arguments: %w", len(records)+1, err)
+		}
+		if call.Name == "" {
+			return nil, fmt.Errorf("record %d: missing tool name", len(records)+1)
+		}
+		rec := policyTestRecord{Call: call, Recorded: raw.Result}
+		if raw.Context != nil {
+			rec.Context = *raw.Context
+		}
+		records = append(records, rec)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no tool calls in %s", path)
+	}
+	return records, nil
+}
+
+// checkPolicyOnServer dry-runs a call against the server's policy.
+func checkPolicyOnServer(serverURL string, call *policy.ToolCall, ec policy.EvalContext) (*policy.PolicyResult, error) {
+	body := map[string]any{
+		"tool":           call.Name,
+		"arguments":      call.Arguments,
+		"session_id":     call.SessionID,
+		"agent_id":       call.AgentID,
+		"workspace_path": call.WorkspacePath,
+		"context":        ec,
+	}
+	var resp struct {
+		Allowed         bool     `json:"allowed"`
+		RequireApproval bool     `json:"require_approval"`
+		Reason          string   `json:"reason"`
+		ApprovalReason  string   `json:"approval_reason"`
+		Warnings        []string `json:"warnings"`
+		MatchedRules    []string `json:"matched_rules"`
+	}
+	if err := evalRequest(http.MethodPost, serverURL+"/api/v1/policy/check", body, &resp); err != nil {
+		return nil, err
+	}
+	return &policy.PolicyResult{
+		Allowed:         resp.Allowed,
+		RequireApproval: resp.RequireApproval,
+		Reason:          resp.Reason,
+		ApprovalReason:  resp.ApprovalReason,
+		Warnings:        resp.Warnings,
+		MatchedRules:    resp.MatchedRules,
+	}, nil
+}
+
+// policyOutcome names a result: allow, approve or block.
+func policyOutcome(r *policy.PolicyResult) string {
+	switch {
+	case !r.Allowed:
+		return "block"
+	case r.RequireApproval:
+		return "approve"
+	}
+	return "allow"
+}
+
+func printPolicyTestOutcomes(outcomes []policyTestOutcome) {
+	for _, o := range outcomes {
+		who := o.Call.AgentID
+		if who == "" {
+			who = "main"
+		}
+		line := fmt.Sprintf("%-7s  %s  (agent %s", strings.ToUpper(o.Decision), o.Call.Name, who)
+		if o.Call.WorkspacePath != "" {
+			line += ", workspace " + o.Call.WorkspacePath
+		}
+		line += ", " + o.Context.Time.Local().Format("2006-01-02 15:04") + ")"
+		if o.Recorded != "" && o.Recorded != o.Decision {
+			line += fmt.Sprintf("  [changed, was %s]", strings.ToUpper(o.Recorded))
+		}
+		fmt.Println(line)
+
+		switch {
+		case o.Result.Reason != "":
+			fmt.Printf("         reason: %s\n", o.Result.Reason)
+		case o.Result.ApprovalReason != "":
+			fmt.Printf("         reason: %s\n", o.Result.ApprovalReason)
+		}
+		for _, w := range o.Result.Warnings {
+			fmt.Printf("         warning: %s\n", w)
+		}
+		if len(o.Result.MatchedRules) > 0 {
+			fmt.Printf("         matched: %s\n", strings.Join(o.Result.MatchedRules, ", "))
+		}
+	}
+}
@@ -132,6 +132,9 @@ for building and running AI agents.`,
 	rootCmd.AddCommand(NewWorkspaceCmd())
 	rootCmd.AddCommand(NewPromptCmd())
 	rootCmd.AddCommand(NewDelegateCmd())
+	rootCmd.AddCommand(NewAgentCmd())
+	rootCmd.AddCommand(NewEvalCmd())
+	rootCmd.AddCommand(NewPolicyCmd())
 
 	return rootCmd
 }
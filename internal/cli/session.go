@@ -18,7 +18,7 @@ func NewSessionCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "session",
 		Short: "Manage conversation sessions",
-		Long:  `List, view, and delete conversation sessions.`,
+		Long:  `List, view, fork, edit and delete conversation sessions.`,
 	}
 
 	cmd.AddCommand(newSessionListCmd())
@@ -26,6 +26,10 @@ func NewSessionCmd() *cobra.Command {
 	cmd.AddCommand(newSessionDeleteCmd())
 	cmd.AddCommand(newSessionClearCmd())
 	cmd.AddCommand(newSessionModelCmd())
+	cmd.AddCommand(newSessionForkCmd())
+	cmd.AddCommand(newSessionEditCmd())
+	cmd.AddCommand(newSessionBranchesCmd())
+	cmd.AddCommand(newSessionSearchCmd())
 
 	return cmd
 }
@@ -240,7 +244,7 @@ func runSessionShow(serverURL, sessionID string) error {
 				content = content[:200] + "..."
 			}
 
-			fmt.Printf("\n%s [%s] %s\n", rolePrefix, msg.Role, msg.CreatedAt.Format("15:04:05"))
+			fmt.Printf("\n%s [%s] %s  %s\n", rolePrefix, msg.Role, msg.CreatedAt.Format("15:04:05"), msg.ID)
 			fmt.Println(content)
 		}
 	}
@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newSessionForkCmd() *cobra.Command {
+	var (
+		messageID string
+		serverURL string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fork <session-id>",
+		Short: "Fork a session into a new branch",
+		Long: `Copy a session into a new branch session.
+
+The branch gets the history up to and including --at (default: the whole
+conversation), plus the model, selected skills and workspace binding.
+
+Example:
+  mote session fork abc123
+  mote session fork abc123 --at 6f1c...    # fork after a specific message`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionFork(serverURL, args[0], messageID)
+		},
+	}
+
+	cmd.Flags().StringVar(&messageID, "at", "", "last message to copy into the branch (see 'mote session show')")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+func newSessionEditCmd() *cobra.Command {
+	var (
+		noRun     bool
+		serverURL string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit <session-id> <message-id> <new-content>",
+		Short: "Edit a past user message and regenerate as a new branch",
+		Long: `Branch a session just before one of its user messages and send the
+edited message to the branch, regenerating the conversation from there.
+The original session is left unchanged.
+
+Example:
+  mote session edit abc123 6f1c... "Use PostgreSQL instead"`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionEdit(serverURL, args[0], args[1], args[2], !noRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noRun, "no-run", false, "only create the branch, do not regenerate")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+func newSessionBranchesCmd() *cobra.Command {
+	var serverURL string
+
+	cmd := &cobra.Command{
+		Use:   "branches <session-id>",
+		Short: "Show the branch tree of a session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionBranches(serverURL, args[0])
+		},
+	}
+
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+type forkResponse struct {
+	Session struct {
+		ID           string `json:"id"`
+		MessageCount int    `json:"message_count"`
+	} `json:"session"`
+	ParentID string `json:"parent_id"`
+	Content  string `json:"content"`
+}
+
+type branchNode struct {
+	ID            string        `json:"id"`
+	ForkMessageID string        `json:"fork_message_id"`
+	Title         string        `json:"title"`
+	MessageCount  int           `json:"message_count"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Children      []*branchNode `json:"children"`
+}
+
+func postSessionJSON(url string, body any, out any) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(data))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func runSessionFork(serverURL, sessionID, messageID string) error {
+	var fork forkResponse
+	url := fmt.Sprintf("%s/api/v1/sessions/%s/fork", serverURL, sessionID)
+	if err := postSessionJSON(url, map[string]string{"message_id": messageID}, &fork); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Forked %s into %s (%d messages)\n", sessionID, fork.Session.ID, fork.Session.MessageCount)
+	fmt.Printf("  Continue with: mote chat --session %s\n", fork.Session.ID)
+	return nil
+}
+
+func runSessionEdit(serverURL, sessionID, messageID, content string, regenerate bool) error {
+	var fork forkResponse
+	url := fmt.Sprintf("%s/api/v1/sessions/%s/messages/%s/edit", serverURL, sessionID, messageID)
+	if err := postSessionJSON(url, map[string]string{"content": content}, &fork); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Created branch %s from %s\n", fork.Session.ID, sessionID)
+	if !regenerate {
+		fmt.Printf("  Send the edited message with: mote chat --session %s\n", fork.Session.ID)
+		return nil
+	}
+	fmt.Println()
+	return sendSyncMessage(serverURL, fork.Session.ID, fork.Content)
+}
+
+func runSessionBranches(serverURL, sessionID string) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	resp, err := client.Get(fmt.Sprintf("%s/api/v1/sessions/%s/branches", serverURL, sessionID))
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Current string      `json:"current"`
+		Tree    *branchNode `json:"tree"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	printBranch(result.Tree, result.Current, "", true, true)
+	return nil
+}
+
+// printBranch prints a branch tree, marking the current session with '*'.
+func printBranch(node *branchNode, current, prefix string, last, root bool) {
+	if node == nil {
+		return
+	}
+	marker := " "
+	if node.ID == current {
+		marker = "*"
+	}
+	connector := ""
+	childPrefix := ""
+	if !root {
+		connector = "├── "
+		childPrefix = prefix + "│   "
+		if last {
+			connector = "└── "
+			childPrefix = prefix + "    "
+		}
+	}
+	line := fmt.Sprintf("%s%s%s %s  %d msgs  %s", prefix, connector, marker, node.ID, node.MessageCount,
+		node.UpdatedAt.Format("2006-01-02 15:04"))
+	if node.Title != "" {
+		line += "  " + node.Title
+	}
+	if node.ForkMessageID != "" {
+		line += "  (from " + shortID(node.ForkMessageID) + ")"
+	}
+	fmt.Println(strings.TrimRight(line, " "))
+
+	for i, child := range node.Children {
+		printBranch(child, current, childPrefix, i == len(node.Children)-1, false)
+	}
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newSessionSearchCmd() *cobra.Command {
+	var (
+		since      string
+		until      string
+		model      string
+		workspace  string
+		role       string
+		limit      int
+		jsonOutput bool
+		serverURL  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search messages across all sessions",
+		Long: `Full-text search over every session transcript, including tool call
+arguments and tool results. All words must match; results are ranked by
+relevance.
+
+Example:
+  mote session search "connection pool"
+  mote session search deploy --since 7d --role tool
+  mote session search migration --workspace . --model gpt-4o`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := url.Values{}
+			params.Set("q", strings.Join(args, " "))
+			params.Set("limit", strconv.Itoa(limit))
+			for key, value := range map[string]string{
+				"since": since, "until": until, "model": model, "role": role,
+			} {
+				if value != "" {
+					params.Set(key, value)
+				}
+			}
+			if workspace != "" {
+				params.Set("workspace", workspaceParam(workspace))
+			}
+			return runSessionSearch(serverURL, params, jsonOutput)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "only messages after this time (e.g. 24h, 7d, 2006-01-02)")
+	cmd.Flags().StringVar(&until, "until", "", "only messages before this time")
+	cmd.Flags().StringVar(&model, "model", "", "only sessions using this model")
+	cmd.Flags().StringVar(&workspace, "workspace", "", "only sessions bound to this workspace path or alias")
+	cmd.Flags().StringVar(&role, "role", "", "only messages with this role (user, assistant, tool)")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "maximum number of results")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+// workspaceParam resolves a relative workspace path against the current
+// directory; anything that does not look like a path is sent as an alias.
+func workspaceParam(ws string) string {
+	if ws != "." && ws != ".." && !strings.ContainsRune(ws, filepath.Separator) {
+		return ws
+	}
+	if abs, err := filepath.Abs(ws); err == nil {
+		return abs
+	}
+	return ws
+}
+
+type searchResult struct {
+	MessageID    string    `json:"message_id"`
+	SessionID    string    `json:"session_id"`
+	SessionTitle string    `json:"session_title"`
+	Role         string    `json:"role"`
+	Snippet      string    `json:"snippet"`
+	CreatedAt    time.Time `json:"created_at"`
+	AnchorID     string    `json:"anchor_id"`
+	Link         string    `json:"link"`
+}
+
+func runSessionSearch(serverURL string, params url.Values, jsonOutput bool) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	resp, err := client.Get(serverURL + "/api/v1/search?" + params.Encode())
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w\nIs the server running? Start it with: mote serve", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Results []searchResult `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result.Results)
+	}
+
+	if len(result.Results) == 0 {
+		fmt.Println("No matching messages.")
+		return nil
+	}
+
+	for _, r := range result.Results {
+		header := fmt.Sprintf("%s  %s  %-9s  %s", r.SessionID, shortID(r.AnchorID), r.Role,
+			r.CreatedAt.Format("2006-01-02 15:04"))
+		if r.SessionTitle != "" {
+			header += "  " + r.SessionTitle
+		}
+		fmt.Println(header)
+		fmt.Printf("    %s\n\n", r.Snippet)
+	}
+	fmt.Printf("%d results. Open one with: mote session show <session-id>\n", len(result.Results))
+	return nil
+}
@@ -1,12 +1,18 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"mote/internal/provider/copilot"
+	"mote/internal/usage"
 )
 
 // NewUsageCmd creates the usage command.
@@ -16,13 +22,19 @@ func NewUsageCmd() *cobra.Command {
 		Short: "View usage statistics",
 		Long: `View Copilot usage statistics and quota status.
 
-Tracks your model usage, including free and premium requests.`,
+Tracks your model usage, including free and premium requests.
+
+The report and budgets subcommands query the running server's usage ledger,
+which records tokens and estimated cost for every LLM call by session,
+agent, cron job and model.`,
 	}
 
 	cmd.AddCommand(newUsageStatusCmd())
 	cmd.AddCommand(newUsageHistoryCmd())
 	cmd.AddCommand(newUsageModelsCmd())
 	cmd.AddCommand(newUsageResetCmd())
+	cmd.AddCommand(newUsageReportCmd())
+	cmd.AddCommand(newUsageBudgetsCmd())
 
 	return cmd
 }
@@ -245,3 +257,150 @@ func runUsageReset(cmd *cobra.Command, force bool) error {
 
 	return nil
 }
+
+func newUsageReportCmd() *cobra.Command {
+	var (
+		by         string
+		since      string
+		session    string
+		agent      string
+		cronJob    string
+		model      string
+		limit      int
+		jsonOutput bool
+		serverURL  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Show token usage and cost from the usage ledger",
+		Long: `Show token usage and estimated cost recorded by the server, grouped by
+session, agent, cron job, model or day.
+
+Examples:
+  mote usage report --by agent --since 7d
+  mote usage report --by day --model gpt-4o
+  mote usage report --by model --cron nightly-digest`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q := url.Values{}
+			q.Set("by", by)
+			q.Set("limit", fmt.Sprint(limit))
+			for key, value := range map[string]string{
+				"since": since, "session": session, "agent": agent, "cron": cronJob, "model": model,
+			} {
+				if value != "" {
+					q.Set(key, value)
+				}
+			}
+
+			var resp struct {
+				By     string         `json:"by"`
+				Total  usage.Totals   `json:"total"`
+				Groups []usage.Totals `json:"groups"`
+			}
+			if err := evalRequest(http.MethodGet, serverURL+"/api/v1/usage?"+q.Encode(), nil, &resp); err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp)
+			}
+			if len(resp.Groups) == 0 {
+				fmt.Println("No usage recorded.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "%s\tCALLS\tINPUT\tOUTPUT\tTOTAL\tCOST\n", usageColumnTitle(resp.By))
+			for _, t := range resp.Groups {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t$%.4f\n",
+					truncate(valueOrDefault(t.Key, "-"), 50), t.Calls, t.PromptTokens, t.CompletionTokens, t.TotalTokens, t.Cost)
+			}
+			fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t%d\t$%.4f\n",
+				resp.Total.Calls, resp.Total.PromptTokens, resp.Total.CompletionTokens, resp.Total.TotalTokens, resp.Total.Cost)
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&by, "by", "model", "group by: session, agent, cron, model or day")
+	cmd.Flags().StringVar(&since, "since", "", "only include calls since (e.g. 24h, 7d, 2006-01-02)")
+	cmd.Flags().StringVar(&session, "session", "", "only include this root session")
+	cmd.Flags().StringVar(&agent, "agent", "", "only include this agent")
+	cmd.Flags().StringVar(&cronJob, "cron", "", "only include this cron job")
+	cmd.Flags().StringVar(&model, "model", "", "only include this model")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "number of groups to show")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+func newUsageBudgetsCmd() *cobra.Command {
+	var (
+		jsonOutput bool
+		serverURL  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "budgets",
+		Short: "Show spend against configured usage budgets",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var resp struct {
+				Budgets []usage.BudgetStatus `json:"budgets"`
+			}
+			if err := evalRequest(http.MethodGet, serverURL+"/api/v1/usage/budgets", nil, &resp); err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Budgets)
+			}
+			if len(resp.Budgets) == 0 {
+				fmt.Println("No usage budgets configured.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "BUDGET\tSCOPE\tKEY\tPERIOD\tTOKENS\tCOST\tUSED\tSTATE\tACTION")
+			for _, st := range resp.Budgets {
+				b := st.Budget
+				tokens := fmt.Sprint(st.Spent.TotalTokens)
+				if b.MaxTokens > 0 {
+					tokens += fmt.Sprintf("/%d", b.MaxTokens)
+				}
+				cost := fmt.Sprintf("$%.2f", st.Spent.Cost)
+				if b.MaxCost > 0 {
+					cost += fmt.Sprintf("/$%.2f", b.MaxCost)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%.0f%%\t%s\t%s\n",
+					valueOrDefault(b.Name, "-"), b.Scope, truncate(valueOrDefault(st.Key, "-"), 40),
+					valueOrDefault(b.Period, "all time"), tokens, cost, st.Fraction*100, st.State, b.Action)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	cmd.Flags().StringVar(&serverURL, "url", "http://localhost:18788", "Mote server URL")
+
+	return cmd
+}
+
+func usageColumnTitle(by string) string {
+	switch by {
+	case "session":
+		return "SESSION"
+	case "agent":
+		return "AGENT"
+	case "cron":
+		return "CRON JOB"
+	case "day":
+		return "DAY"
+	default:
+		return "MODEL"
+	}
+}
@@ -241,6 +241,11 @@ func (c *Compactor) NeedsMemoryFlush(messages []provider.Message) bool {
 //  3. Drop oldest non-system messages entirely (keeping tool-call pairs
 //     intact) — last resort when sheer message count × overhead exceeds
 //     the budget.  Protected tail messages are never dropped.
+//
+// Pinned messages (see pinnedIndices) are never dropped; when they fall in
+// the dropped range they are carried over in a pinned-context message.
+// Together they may take MaxPinnedRatio of the budget and are truncated to
+// fit, and further in the last phase when they alone exceed the budget.
 func (c *Compactor) BudgetMessages(messages []provider.Message, toolsOverhead int, protectedTail ...int) []provider.Message {
 	budget := c.config.MaxRequestBytes
 	if budget <= 0 {
@@ -272,6 +277,10 @@ func (c *Compactor) BudgetMessages(messages []provider.Message, toolsOverhead in
 	result := make([]provider.Message, len(messages))
 	copy(result, messages)
 
+	// Phase 0: cap the pinned messages so a huge task statement cannot
+	// take the budget on every call from now on.
+	result = c.capPinned(result, len(result)-protected, c.pinnedLimit(budget))
+
 	// Truncation tiers: progressively more aggressive
 	tiers := []int{4096, 1024, 256}
 
@@ -300,7 +309,8 @@ func (c *Compactor) BudgetMessages(messages []provider.Message, toolsOverhead in
 	}
 
 	// Phase 2: Truncate HISTORICAL non-system message content (assistant, user).
-	// Skip protected tail — only compress older context.
+	// Skip protected tail and pinned messages — only compress older context.
+	pinned := c.pinnedIndices(result)
 	contentTiers := []int{2048, 512}
 	for _, maxBytes := range contentTiers {
 		if c.estimateRequestBytes(result, toolsOverhead) <= budget {
@@ -314,6 +324,9 @@ func (c *Compactor) BudgetMessages(messages []provider.Message, toolsOverhead in
 			if result[i].Role == provider.RoleSystem || result[i].Role == provider.RoleTool {
 				continue // system is untouchable; tool results handled in Phase 1
 			}
+			if pinned[i] {
+				continue
+			}
 			if len(result[i].Content) <= maxBytes {
 				continue
 			}
@@ -386,6 +399,16 @@ func (c *Compactor) BudgetMessages(messages []provider.Message, toolsOverhead in
 		}
 	}
 
+	// Phase 5 (last resort): the pinned messages alone exceed the budget;
+	// truncate them further rather than send an oversized request.
+	for _, maxBytes := range []int{budget / 8, 4096, 1024} {
+		if c.estimateRequestBytes(result, toolsOverhead) <= budget {
+			break
+		}
+		slog.Warn("BudgetMessages Phase 5: truncating pinned messages", "maxBytes", maxBytes)
+		result = c.capPinned(result, len(result), maxBytes)
+	}
+
 	return result
 }
 
@@ -434,6 +457,7 @@ func (c *Compactor) estimateSingleMessageBytes(msg provider.Message) int {
 // estimated request size fits within the budget. Tool-call pairs are kept
 // intact (never split an assistant tool_call from its tool result).
 // protectedTail specifies how many tail messages must never be dropped.
+// Pinned messages in the dropped range are kept in a pinned-context message.
 func (c *Compactor) dropOldestToBudget(messages []provider.Message, budget int, toolsOverhead int, protectedTail int) []provider.Message {
 	var systemMsgs, convMsgs []provider.Message
 	for _, msg := range messages {
@@ -466,6 +490,15 @@ func (c *Compactor) dropOldestToBudget(messages []provider.Message, budget int,
 		available = 8192
 	}
 
+	// Pinned messages are kept whatever happens; reserve their space first.
+	pinned := c.pinnedIndices(convMsgs)
+	for i := range pinned {
+		available -= c.estimateSingleMessageBytes(convMsgs[i])
+	}
+	if available < 0 {
+		available = 0
+	}
+
 	// Walk backward from newest, keeping messages that fit.
 	keptBytes := 0
 	splitIdx := len(convMsgs)
@@ -536,19 +569,14 @@ func (c *Compactor) dropOldestToBudget(messages []provider.Message, budget int,
 		splitIdx = roundStart
 	}
 
-	// Build result with a notice about the drop
-	result := make([]provider.Message, 0, len(systemMsgs)+1+len(keptConv))
+	// Build result with the pinned context and a notice about the drop.
+	// Roles alternate to avoid consecutive same-role messages.
+	result := make([]provider.Message, 0, len(systemMsgs)+2+len(keptConv))
 	result = append(result, systemMsgs...)
 	if splitIdx > 0 {
-		// Choose role to avoid consecutive same-role with the first kept message.
-		role := provider.RoleAssistant
-		if len(keptConv) > 0 {
-			role = noticeRoleFor(keptConv[0].Role)
-		}
-		result = append(result, provider.Message{
-			Role:    role,
-			Content: "[Earlier context dropped to fit request size budget.]",
-		})
+		result = append(result, noticeMessages(keptConv,
+			c.pinnedContent(convMsgs, splitIdx),
+			"[Earlier context dropped to fit request size budget.]")...)
 	}
 	result = append(result, keptConv...)
 
@@ -649,6 +677,10 @@ func (c *Compactor) Compact(ctx context.Context, messages []provider.Message, pr
 	// Detect a previous summary from an earlier compaction.
 	previousSummary := c.detectPreviousSummary(toCompact)
 
+	// Pinned messages are carried over verbatim instead of summarized.
+	pinnedContent := c.pinnedContent(convMsgs, splitIdx)
+	toCompact = c.withoutPinned(toCompact)
+
 	// Replace oversized messages with stubs before summarization.
 	toCompact = c.replaceOversizedMessages(toCompact)
 
@@ -698,22 +730,17 @@ func (c *Compactor) Compact(ctx context.Context, messages []provider.Message, pr
 		}
 	}
 
-	// Build result: system + summary + kept messages
-	result := make([]provider.Message, 0, len(systemMsgs)+1+len(keptMsgs))
+	// Build result: system + pinned context + summary + kept messages
+	result := make([]provider.Message, 0, len(systemMsgs)+2+len(keptMsgs))
 	result = append(result, systemMsgs...)
+	summaryContent := ""
 	if finalSummary != "" {
-		// Choose role to avoid consecutive same-role messages.
-		// After adjustKeepBoundary the first kept message may be assistant
-		// (tool_calls), so the summary must use a different role.
-		role := provider.RoleAssistant
-		if len(keptMsgs) > 0 {
-			role = noticeRoleFor(keptMsgs[0].Role)
-		}
-		result = append(result, provider.Message{
-			Role:    role,
-			Content: fmt.Sprintf("[Previous conversation summary]\n%s", finalSummary),
-		})
+		summaryContent = fmt.Sprintf("[Previous conversation summary]\n%s", finalSummary)
 	}
+	// Choose roles to avoid consecutive same-role messages.
+	// After adjustKeepBoundary the first kept message may be assistant
+	// (tool_calls), so the summary must use a different role.
+	result = append(result, noticeMessages(keptMsgs, pinnedContent, summaryContent)...)
 	result = append(result, keptMsgs...)
 
 	return result, nil
@@ -1288,10 +1315,12 @@ func (c *Compactor) truncate(messages []provider.Message) []provider.Message {
 		for _, m := range round {
 			keptMsgs = append(keptMsgs, c.truncateMessageContent(m, maxPerMsg))
 		}
+		splitIdx = roundStart
 	}
 
-	result := make([]provider.Message, 0, len(systemMsgs)+len(keptMsgs))
+	result := make([]provider.Message, 0, len(systemMsgs)+1+len(keptMsgs))
 	result = append(result, systemMsgs...)
+	result = append(result, noticeMessages(keptMsgs, c.pinnedContent(convMsgs, splitIdx))...)
 	result = append(result, keptMsgs...)
 	return result
 }
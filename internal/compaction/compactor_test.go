@@ -132,9 +132,9 @@ func TestCompactor_Compact(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 
-		// Should have: 1 system + 1 summary + 2 kept = 4
-		if len(result) != 4 {
-			t.Errorf("expected 4 messages, got %d", len(result))
+		// Should have: 1 system + 1 pinned task + 1 summary + 2 kept = 5
+		if len(result) != 5 {
+			t.Fatalf("expected 5 messages, got %d", len(result))
 		}
 
 		// First should be system
@@ -142,9 +142,14 @@ func TestCompactor_Compact(t *testing.T) {
 			t.Errorf("first message should be system, got %s", result[0].Role)
 		}
 
-		// Second should be summary
-		if result[1].Role != "assistant" {
-			t.Errorf("second message should be assistant (summary), got %s", result[1].Role)
+		// Second should carry the pinned task statement
+		if !strings.Contains(result[1].Content, "hello") {
+			t.Errorf("second message should carry the pinned task, got %q", result[1].Content)
+		}
+
+		// Third should be summary
+		if result[2].Role != "assistant" {
+			t.Errorf("third message should be assistant (summary), got %s", result[2].Role)
 		}
 	})
 
@@ -1364,11 +1369,11 @@ func TestCompact_StructuredSummary(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// Should have: system + summary + 2 kept
-	if len(result) != 4 {
-		t.Errorf("expected 4 messages, got %d", len(result))
+	// Should have: system + pinned task + summary + 2 kept
+	if len(result) != 5 {
+		t.Fatalf("expected 5 messages, got %d", len(result))
 	}
-	if !strings.Contains(result[1].Content, "## Goal") {
+	if !strings.Contains(result[2].Content, "## Goal") {
 		t.Error("summary should contain structured content")
 	}
 }
@@ -129,6 +129,21 @@ type CompactionConfig struct {
 	// AdaptiveChunkMaxRatio is the maximum chunk size as a ratio of MaxContextTokens.
 	// Default: 0.40 (40%)
 	AdaptiveChunkMaxRatio float64 `json:"adaptive_chunk_max_ratio" yaml:"adaptiveChunkMaxRatio"`
+
+	// PinFirstUserMessage keeps the first user message (the task statement)
+	// through truncation, dropping and summarization.
+	// Default: true
+	PinFirstUserMessage bool `json:"pin_first_user_message" yaml:"pinFirstUserMessage"`
+
+	// PinMarkers pins every user or assistant message containing one of
+	// these strings, e.g. a plan the model is working through.
+	// Default: ["[pin]", "## Plan"]
+	PinMarkers []string `json:"pin_markers" yaml:"pinMarkers"`
+
+	// MaxPinnedRatio is the share of MaxRequestBytes that pinned messages
+	// may take together. Larger pinned content is truncated to fit.
+	// Default: 0.25 (25%)
+	MaxPinnedRatio float64 `json:"max_pinned_ratio" yaml:"maxPinnedRatio"`
 }
 
 // DefaultConfig returns a CompactionConfig with default values.
@@ -149,6 +164,9 @@ func DefaultConfig() CompactionConfig {
 		MaxSingleMsgRatio:           0.5,   // 50%  — single message > this ratio → replaced with notice
 		AdaptiveChunkMinRatio:       0.15,  // 15% of MaxContextTokens
 		AdaptiveChunkMaxRatio:       0.40,  // 40% of MaxContextTokens
+		PinFirstUserMessage:         true,
+		PinMarkers:                  []string{"[pin]", "## Plan"},
+		MaxPinnedRatio:              0.25, // 25% of MaxRequestBytes for pinned messages
 	}
 }
 
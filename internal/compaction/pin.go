@@ -0,0 +1,208 @@
+package compaction
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"mote/internal/provider"
+)
+
+// pinnedPrefix starts the message that carries pinned messages across
+// compactions. The message is itself pinned, so the pinned context
+// survives any number of compactions.
+const pinnedPrefix = "[Pinned context]\n"
+
+// noticePrefixes start the messages inserted by the compactor itself;
+// they are never taken for the task statement.
+var noticePrefixes = []string{
+	"[Previous conversation summary]",
+	"[Earlier context dropped",
+	"[Previous conversation context was truncated",
+	pinnedPrefix,
+}
+
+// pinnedIndices returns the indices of the messages that must never be
+// truncated, dropped or summarized away: the first user message (the task
+// statement) when PinFirstUserMessage is set, messages containing one of
+// PinMarkers (e.g. a plan), and the pinned-context message of an earlier
+// compaction. Only user and assistant messages can be pinned.
+func (c *Compactor) pinnedIndices(messages []provider.Message) map[int]bool {
+	pinned := make(map[int]bool)
+	carried := false
+	for i, msg := range messages {
+		if msg.Role != provider.RoleUser && msg.Role != provider.RoleAssistant {
+			continue
+		}
+		if strings.HasPrefix(msg.Content, pinnedPrefix) {
+			pinned[i] = true
+			carried = true
+			continue
+		}
+		for _, marker := range c.config.PinMarkers {
+			if marker != "" && strings.Contains(msg.Content, marker) {
+				pinned[i] = true
+				break
+			}
+		}
+	}
+
+	// Once a pinned-context message exists it already holds the task
+	// statement; the first user message left is just an old turn.
+	if c.config.PinFirstUserMessage && !carried {
+		for i, msg := range messages {
+			if msg.Role == provider.RoleUser && !isNotice(msg.Content) {
+				pinned[i] = true
+				break
+			}
+		}
+	}
+	return pinned
+}
+
+// pinnedContent returns the pinned-context text for the pinned messages
+// among messages[:upto], or "" when there are none. Content carried by an
+// earlier pinned-context message is merged rather than nested.
+func (c *Compactor) pinnedContent(messages []provider.Message, upto int) string {
+	pinned := c.pinnedIndices(messages)
+	var parts []string
+	for i := 0; i < upto && i < len(messages); i++ {
+		if !pinned[i] {
+			continue
+		}
+		content := strings.TrimPrefix(messages[i].Content, pinnedPrefix)
+		if content == "" {
+			continue
+		}
+		if !strings.HasPrefix(messages[i].Content, pinnedPrefix) {
+			content = messages[i].Role + ": " + content
+		}
+		parts = append(parts, content)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return pinnedPrefix + strings.Join(parts, "\n\n")
+}
+
+// withoutPinned returns messages minus the pinned ones.
+func (c *Compactor) withoutPinned(messages []provider.Message) []provider.Message {
+	pinned := c.pinnedIndices(messages)
+	if len(pinned) == 0 {
+		return messages
+	}
+	result := make([]provider.Message, 0, len(messages)-len(pinned))
+	for i, msg := range messages {
+		if !pinned[i] {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// pinnedLimit returns how many content bytes the pinned messages may take
+// together within a request budget.
+func (c *Compactor) pinnedLimit(budget int) int {
+	ratio := c.config.MaxPinnedRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = DefaultConfig().MaxPinnedRatio
+	}
+	return int(float64(budget) * ratio)
+}
+
+// capPinned truncates the pinned messages among messages[:upto] so that
+// their content takes at most limit bytes together. Each message gets a
+// share proportional to its size and keeps its head and tail; a pin marker
+// the cut would lose is put back in front so the message stays pinned.
+func (c *Compactor) capPinned(messages []provider.Message, upto, limit int) []provider.Message {
+	pinned := c.pinnedIndices(messages)
+	total := 0
+	for i := range pinned {
+		if i < upto {
+			total += len(messages[i].Content)
+		}
+	}
+	if total <= limit {
+		return messages
+	}
+
+	result := make([]provider.Message, len(messages))
+	copy(result, messages)
+	for i := range pinned {
+		if i >= upto {
+			continue
+		}
+		content := result[i].Content
+		share := int(int64(len(content)) * int64(limit) / int64(total))
+		if len(content) <= share {
+			continue
+		}
+		prefix := ""
+		if strings.HasPrefix(content, pinnedPrefix) {
+			prefix, content = pinnedPrefix, strings.TrimPrefix(content, pinnedPrefix)
+			share -= len(pinnedPrefix)
+		}
+		truncated := truncateMiddle(content, share, "\n\n[... pinned content truncated to fit context budget ...]\n\n")
+		if prefix == "" {
+			for _, marker := range c.config.PinMarkers {
+				if marker != "" && strings.Contains(content, marker) && !strings.Contains(truncated, marker) {
+					truncated = marker + "\n" + truncated
+					break
+				}
+			}
+		}
+		result[i].Content = prefix + truncated
+	}
+	return result
+}
+
+// truncateMiddle keeps the head and tail of s within max bytes, joined by
+// notice, cutting on rune boundaries.
+func truncateMiddle(s string, max int, notice string) string {
+	if len(s) <= max {
+		return s
+	}
+	if max < 0 {
+		max = 0
+	}
+	head := max * 2 / 3
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+	tail := len(s) - (max - head)
+	for tail < len(s) && !utf8.RuneStart(s[tail]) {
+		tail++
+	}
+	return s[:head] + notice + s[tail:]
+}
+
+// noticeMessages turns the non-empty contents into messages that go in
+// front of next, alternating roles so that neither two notices nor the
+// last notice and next[0] share a role.
+func noticeMessages(next []provider.Message, contents ...string) []provider.Message {
+	var nonEmpty []string
+	for _, content := range contents {
+		if content != "" {
+			nonEmpty = append(nonEmpty, content)
+		}
+	}
+	role := provider.RoleAssistant
+	if len(next) > 0 {
+		role = noticeRoleFor(next[0].Role)
+	}
+	notices := make([]provider.Message, len(nonEmpty))
+	for i := len(nonEmpty) - 1; i >= 0; i-- {
+		notices[i] = provider.Message{Role: role, Content: nonEmpty[i]}
+		role = noticeRoleFor(role)
+	}
+	return notices
+}
+
+// isNotice reports whether content was inserted by the compactor.
+func isNotice(content string) bool {
+	for _, prefix := range noticePrefixes {
+		if strings.HasPrefix(content, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package compaction
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"mote/internal/provider"
+)
+
+func TestPinnedIndices(t *testing.T) {
+	c := NewCompactor(DefaultConfig(), nil)
+	messages := []provider.Message{
+		{Role: provider.RoleSystem, Content: "sys"},
+		{Role: provider.RoleUser, Content: "Refactor the parser"},
+		{Role: provider.RoleAssistant, Content: "## Plan\n1. read\n2. edit"},
+		{Role: provider.RoleUser, Content: "ok"},
+		{Role: provider.RoleTool, Content: "[pin] tool output", ToolCallID: "t1"},
+	}
+	pinned := c.pinnedIndices(messages)
+	if !pinned[1] || !pinned[2] || pinned[3] || pinned[4] || len(pinned) != 2 {
+		t.Errorf("pinned = %v, want task statement and plan only", pinned)
+	}
+
+	// A carried pinned-context message replaces the first-user rule
+	messages = []provider.Message{
+		{Role: provider.RoleUser, Content: pinnedPrefix + "user: Refactor the parser"},
+		{Role: provider.RoleAssistant, Content: "[Previous conversation summary]\n..."},
+		{Role: provider.RoleUser, Content: "continue"},
+	}
+	pinned = c.pinnedIndices(messages)
+	if !pinned[0] || pinned[2] {
+		t.Errorf("pinned = %v, want only the pinned-context message", pinned)
+	}
+}
+
+func TestBudgetMessages_KeepsPinnedMessages(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxRequestBytes = 30000
+	c := NewCompactor(config, nil)
+
+	task := "Migrate the billing service to the new API " + strings.Repeat("with details ", 300)
+	messages := []provider.Message{
+		{Role: provider.RoleSystem, Content: "sys"},
+		{Role: provider.RoleUser, Content: task},
+	}
+	for i := 0; i < 30; i++ {
+		messages = append(messages,
+			provider.Message{Role: provider.RoleAssistant, Content: strings.Repeat("a", 1500)},
+			provider.Message{Role: provider.RoleUser, Content: strings.Repeat("u", 1500)},
+		)
+	}
+
+	result := c.BudgetMessages(messages, 0, 2)
+	found := false
+	for _, msg := range result {
+		if strings.Contains(msg.Content, task) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("task statement was truncated or dropped")
+	}
+	assertNoConsecutiveRoles(t, result)
+}
+
+func TestBudgetMessages_CapsPinnedMessages(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxRequestBytes = 50000
+	c := NewCompactor(config, nil)
+
+	task := "Find why the nightly import fails. Log:\n" + strings.Repeat("ERROR row rejected ", 21000) + "\nWhat went wrong?"
+	messages := []provider.Message{
+		{Role: provider.RoleSystem, Content: "sys"},
+		{Role: provider.RoleUser, Content: task},
+	}
+	for i := 0; i < 10; i++ {
+		messages = append(messages,
+			provider.Message{Role: provider.RoleAssistant, Content: strings.Repeat("a", 500)},
+			provider.Message{Role: provider.RoleUser, Content: strings.Repeat("u", 500)},
+		)
+	}
+
+	result := c.BudgetMessages(messages, 0, 2)
+	if got := c.estimateRequestBytes(result, 0); got > config.MaxRequestBytes {
+		t.Errorf("request is %d bytes, budget %d", got, config.MaxRequestBytes)
+	}
+	if !strings.Contains(result[1].Content, "Find why the nightly import fails") || !strings.Contains(result[1].Content, "What went wrong?") {
+		t.Errorf("pinned task should keep its head and tail, got %.80q", result[1].Content)
+	}
+	if !strings.Contains(result[len(result)-1].Content, strings.Repeat("u", 500)) {
+		t.Error("protected tail was truncated")
+	}
+	assertNoConsecutiveRoles(t, result)
+
+	// The pinned message alone exceeds the budget.
+	result = c.BudgetMessages([]provider.Message{
+		{Role: provider.RoleSystem, Content: "sys"},
+		{Role: provider.RoleUser, Content: task},
+	}, 0, 0)
+	if got := c.estimateRequestBytes(result, 0); got > config.MaxRequestBytes {
+		t.Errorf("pinned-only request is %d bytes, budget %d", got, config.MaxRequestBytes)
+	}
+
+	// A marker survives truncation so the message stays pinned.
+	plan := strings.Repeat("step ", 2000) + "[pin]" + strings.Repeat("step ", 2000)
+	capped := c.capPinned([]provider.Message{
+		{Role: provider.RoleUser, Content: "go"},
+		{Role: provider.RoleAssistant, Content: plan},
+	}, 2, 1000)
+	if !c.pinnedIndices(capped)[1] || len(capped[1].Content) > 1100 {
+		t.Errorf("capped plan lost its pin or was not truncated: %d bytes", len(capped[1].Content))
+	}
+}
+
+func TestCompact_CarriesPinnedMessages(t *testing.T) {
+	var summarized string
+	mp := &mockProvider{
+		chatFunc: func(ctx context.Context, req provider.ChatRequest) (*provider.ChatResponse, error) {
+			summarized = req.Messages[0].Content
+			return &provider.ChatResponse{Content: "## Goal\nsummary"}, nil
+		},
+	}
+	config := DefaultConfig()
+	config.KeepRecentCount = 2
+	c := NewCompactor(config, mp)
+
+	messages := []provider.Message{
+		{Role: provider.RoleSystem, Content: "sys"},
+		{Role: provider.RoleUser, Content: "Write the release notes"},
+		{Role: provider.RoleAssistant, Content: "## Plan\n1. collect commits"},
+		{Role: provider.RoleUser, Content: "go on"},
+		{Role: provider.RoleAssistant, Content: "collected"},
+		{Role: provider.RoleUser, Content: "next"},
+		{Role: provider.RoleAssistant, Content: "done"},
+	}
+	result, err := c.Compact(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if strings.Contains(summarized, "Write the release notes") {
+		t.Error("pinned messages should not be summarized")
+	}
+	if !strings.HasPrefix(result[1].Content, pinnedPrefix) ||
+		!strings.Contains(result[1].Content, "Write the release notes") ||
+		!strings.Contains(result[1].Content, "## Plan") {
+		t.Fatalf("pinned context missing: %q", result[1].Content)
+	}
+	assertNoConsecutiveRoles(t, result)
+
+	// A second compaction keeps the same pinned context
+	result = append(result,
+		provider.Message{Role: provider.RoleUser, Content: "more"},
+		provider.Message{Role: provider.RoleAssistant, Content: "ok"},
+	)
+	again, err := c.Compact(context.Background(), result)
+	if err != nil {
+		t.Fatalf("second compact: %v", err)
+	}
+	if !strings.HasPrefix(again[1].Content, pinnedPrefix) ||
+		strings.Count(again[1].Content, "Write the release notes") != 1 {
+		t.Errorf("pinned context not carried over once: %q", again[1].Content)
+	}
+	assertNoConsecutiveRoles(t, again)
+}
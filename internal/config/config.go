@@ -19,23 +19,27 @@ import (
 
 // Config 是应用配置的根结构体
 type Config struct {
-	Version  string                 `mapstructure:"version" yaml:"version"`
-	Gateway  GatewayConfig          `mapstructure:"gateway" yaml:"gateway"`
-	Provider ProviderConfig         `mapstructure:"provider" yaml:"provider"` // 新增: Provider 选择
-	Copilot  CopilotConfig          `mapstructure:"copilot" yaml:"copilot"`
-	Ollama   OllamaConfig           `mapstructure:"ollama" yaml:"ollama"`   // 新增: Ollama 配置
-	Minimax  MinimaxConfig          `mapstructure:"minimax" yaml:"minimax"` // 新增: MiniMax 配置
-	GLM      GLMConfig              `mapstructure:"glm" yaml:"glm"`         // 新增: GLM (智谱AI) 配置
-	VLLM     VLLMConfig             `mapstructure:"vllm" yaml:"vllm"`       // 新增: vLLM 配置
-	Log      LogConfig              `mapstructure:"log" yaml:"log"`
-	Storage  StorageConfig          `mapstructure:"storage" yaml:"storage"`
-	Memory   MemoryConfig           `mapstructure:"memory" yaml:"memory"`
-	JSVM     JSVMConfig             `mapstructure:"jsvm" yaml:"jsvm"`
-	Cron     CronConfig             `mapstructure:"cron" yaml:"cron"`
-	MCP      MCPConfig              `mapstructure:"mcp" yaml:"mcp"`
-	Channels ChannelsConfig         `mapstructure:"channels" yaml:"channels"`
-	Agents   map[string]AgentConfig `mapstructure:"agents" yaml:"agents,omitempty"`
-	Delegate DelegateConfig         `mapstructure:"delegate" yaml:"delegate,omitempty"`
+	Version   string                 `mapstructure:"version" yaml:"version"`
+	Gateway   GatewayConfig          `mapstructure:"gateway" yaml:"gateway"`
+	Provider  ProviderConfig         `mapstructure:"provider" yaml:"provider"` // 新增: Provider 选择
+	Copilot   CopilotConfig          `mapstructure:"copilot" yaml:"copilot"`
+	Ollama    OllamaConfig           `mapstructure:"ollama" yaml:"ollama"`   // 新增: Ollama 配置
+	Minimax   MinimaxConfig          `mapstructure:"minimax" yaml:"minimax"` // 新增: MiniMax 配置
+	GLM       GLMConfig              `mapstructure:"glm" yaml:"glm"`         // 新增: GLM (智谱AI) 配置
+	VLLM      VLLMConfig             `mapstructure:"vllm" yaml:"vllm"`       // 新增: vLLM 配置
+	Log       LogConfig              `mapstructure:"log" yaml:"log"`
+	Storage   StorageConfig          `mapstructure:"storage" yaml:"storage"`
+	Memory    MemoryConfig           `mapstructure:"memory" yaml:"memory"`
+	JSVM      JSVMConfig             `mapstructure:"jsvm" yaml:"jsvm"`
+	Cron      CronConfig             `mapstructure:"cron" yaml:"cron"`
+	MCP       MCPConfig              `mapstructure:"mcp" yaml:"mcp"`
+	Tools     ToolsConfig            `mapstructure:"tools" yaml:"tools"`
+	Channels  ChannelsConfig         `mapstructure:"channels" yaml:"channels"`
+	Agents    map[string]AgentConfig `mapstructure:"agents" yaml:"agents,omitempty"`
+	Delegate  DelegateConfig         `mapstructure:"delegate" yaml:"delegate,omitempty"`
+	Usage     UsageConfig            `mapstructure:"usage" yaml:"usage,omitempty"`
+	Approvals ApprovalsConfig        `mapstructure:"approvals" yaml:"approvals,omitempty"`
+	Policy    PolicyConfig           `mapstructure:"policy" yaml:"policy,omitempty"`
 }
 
 // AgentConfig 子代理配置
@@ -138,6 +142,70 @@ func (c *DelegateConfig) GetMaxStackDepth() int {
 	return 0
 }
 
+// UsageConfig 用量记账与预算配置
+type UsageConfig struct {
+	// Prices 按模型的价格表（USD / 百万 token），键支持 "claude-*" 前缀通配
+	Prices  map[string]ModelPrice `mapstructure:"prices" yaml:"prices,omitempty"`
+	Budgets []BudgetConfig        `mapstructure:"budgets" yaml:"budgets,omitempty"`
+}
+
+// ModelPrice 模型单价（USD / 百万 token）
+type ModelPrice struct {
+	Input  float64 `json:"input" mapstructure:"input" yaml:"input"`
+	Output float64 `json:"output" mapstructure:"output" yaml:"output"`
+}
+
+// BudgetConfig 预算配置
+type BudgetConfig struct {
+	Name  string `json:"name" mapstructure:"name" yaml:"name"`
+	Scope string `json:"scope" mapstructure:"scope" yaml:"scope"`                     // session | agent | cron | model | global
+	Match string `json:"match,omitempty" mapstructure:"match" yaml:"match,omitempty"` // 限定 agent/cron/model 名称，空表示逐个计算
+	// Period 统计周期：day | month | 空（session 生命周期 / 累计）
+	Period         string  `json:"period,omitempty" mapstructure:"period" yaml:"period,omitempty"`
+	MaxTokens      int     `json:"max_tokens,omitempty" mapstructure:"max_tokens" yaml:"max_tokens,omitempty"`
+	MaxCost        float64 `json:"max_cost,omitempty" mapstructure:"max_cost" yaml:"max_cost,omitempty"`
+	SoftLimit      float64 `json:"soft_limit,omitempty" mapstructure:"soft_limit" yaml:"soft_limit,omitempty"` // 软限制比例，默认 0.8
+	Action         string  `json:"action" mapstructure:"action" yaml:"action"`                                 // 超出硬限制时：warn | downgrade | stop
+	DowngradeModel string  `json:"downgrade_model,omitempty" mapstructure:"downgrade_model" yaml:"downgrade_model,omitempty"`
+}
+
+// ApprovalsConfig 工具调用审批配置
+type ApprovalsConfig struct {
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"` // 超时未处理的审批自动拒绝
+	// PublicURL 签名审批链接使用的外部地址，空表示 http://<gateway.host>:<gateway.port>
+	PublicURL string                   `mapstructure:"public_url" yaml:"public_url,omitempty"`
+	Notifiers []ApprovalNotifierConfig `mapstructure:"notifiers" yaml:"notifiers,omitempty"`
+	// 记住的审批决定（按会话 / 按工作区）默认有效期，0 表示不过期
+	SessionRuleTTL   time.Duration `mapstructure:"session_rule_ttl" yaml:"session_rule_ttl,omitempty"`
+	WorkspaceRuleTTL time.Duration `mapstructure:"workspace_rule_ttl" yaml:"workspace_rule_ttl,omitempty"`
+}
+
+// ApprovalNotifierConfig 带外审批通知，按 Delay 逐级升级
+type ApprovalNotifierConfig struct {
+	Type string `mapstructure:"type" yaml:"type"` // channel | push
+	// type=channel：发送到渠道的会话，在该会话中回复 "approve <id>" / "reject <id>"
+	Channel string `mapstructure:"channel" yaml:"channel,omitempty"`
+	ChatID  string `mapstructure:"chat_id" yaml:"chat_id,omitempty"`
+	// Approvers 可以在该会话中回复审批的用户 ID，为空时会话中的回复不能审批
+	Approvers []string `mapstructure:"approvers" yaml:"approvers,omitempty"`
+	// type=push：ntfy 风格的主题地址，通知中带签名的批准/拒绝按钮
+	URL      string `mapstructure:"url" yaml:"url,omitempty"`
+	Token    string `mapstructure:"token" yaml:"token,omitempty"`
+	Priority string `mapstructure:"priority" yaml:"priority,omitempty"`
+	// Delay 审批请求未处理多久后通知，0 表示立即通知
+	Delay time.Duration `mapstructure:"delay" yaml:"delay,omitempty"`
+}
+
+// PolicyConfig 工具调用安全策略配置
+type PolicyConfig struct {
+	// File 策略文件（YAML，格式同 policy.Config），不存在时使用内置默认策略
+	File string `mapstructure:"file" yaml:"file,omitempty"`
+	// DecisionLog 策略决策日志（JSON Lines），空表示只在内存中保留最近的决策
+	DecisionLog string `mapstructure:"decision_log" yaml:"decision_log,omitempty"`
+	// EgressProxy 出站过滤代理监听地址，工具启动的进程通过 HTTP(S)_PROXY 使用；空表示不启动
+	EgressProxy string `mapstructure:"egress_proxy" yaml:"egress_proxy,omitempty"`
+}
+
 // GatewayConfig 网关配置
 type GatewayConfig struct {
 	Port      int             `mapstructure:"port" yaml:"port"`
@@ -327,13 +395,71 @@ type MCPConfig struct {
 
 // MCPServerConfig MCP 服务端配置
 type MCPServerConfig struct {
-	Enabled   bool   `mapstructure:"enabled" yaml:"enabled"`
-	Transport string `mapstructure:"transport" yaml:"transport"`
+	Enabled   bool            `mapstructure:"enabled" yaml:"enabled"`
+	Transport string          `mapstructure:"transport" yaml:"transport"`
+	Expose    MCPExposeConfig `mapstructure:"expose" yaml:"expose"`
+}
+
+// MCPExposeConfig Mote 作为 MCP 服务端对外暴露的能力，名称列表支持 "*" 与 glob，空列表表示不暴露
+type MCPExposeConfig struct {
+	Tools   []string `mapstructure:"tools" yaml:"tools,omitempty"`     // 工具注册表中的工具
+	Prompts []string `mapstructure:"prompts" yaml:"prompts,omitempty"` // 提示词库中的提示词
+	Agents  []string `mapstructure:"agents" yaml:"agents,omitempty"`   // 以 run_agent_<name> 工具暴露的代理
+	Memory  bool     `mapstructure:"memory" yaml:"memory"`             // memory_search / memory_add 工具与记忆资源
 }
 
 // MCPClientConfig MCP 客户端配置
 type MCPClientConfig struct {
-	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	Enabled     bool              `mapstructure:"enabled" yaml:"enabled"`
+	Sampling    MCPSamplingConfig `mapstructure:"sampling" yaml:"sampling"`
+	Elicitation bool              `mapstructure:"elicitation" yaml:"elicitation"` // 允许 MCP 服务器通过审批界面向用户提问
+	Roots       bool              `mapstructure:"roots" yaml:"roots"`             // 向 MCP 服务器提供会话绑定的工作区
+	HealthCheck MCPHealthConfig   `mapstructure:"health_check" yaml:"health_check"`
+}
+
+// MCPHealthConfig MCP 服务器健康检查配置：定期 ping，连续失败后按退避策略重启
+type MCPHealthConfig struct {
+	Enabled          bool          `mapstructure:"enabled" yaml:"enabled"`
+	Interval         time.Duration `mapstructure:"interval" yaml:"interval"`                   // 检查间隔
+	Timeout          time.Duration `mapstructure:"timeout" yaml:"timeout"`                     // 单次 ping 超时
+	FailureThreshold int           `mapstructure:"failure_threshold" yaml:"failure_threshold"` // 连续失败多少次视为宕机
+	MaxRestarts      int           `mapstructure:"max_restarts" yaml:"max_restarts"`           // 放弃前的最大重启次数
+}
+
+// MCPSamplingConfig MCP 服务器反向调用模型 (sampling/createMessage) 配置
+type MCPSamplingConfig struct {
+	Enabled         bool                               `mapstructure:"enabled" yaml:"enabled"`
+	Model           string                             `mapstructure:"model" yaml:"model,omitempty"`             // 默认模型，空表示使用 chat 默认模型
+	MaxTokens       int                                `mapstructure:"max_tokens" yaml:"max_tokens,omitempty"`   // 单次调用的 token 上限
+	RequireApproval bool                               `mapstructure:"require_approval" yaml:"require_approval"` // 每次调用前需用户审批
+	Servers         map[string]MCPServerSamplingConfig `mapstructure:"servers" yaml:"servers,omitempty"`         // 按服务器覆盖
+}
+
+// MCPServerSamplingConfig 单个 MCP 服务器的 sampling 配置
+type MCPServerSamplingConfig struct {
+	Disabled        bool     `mapstructure:"disabled" yaml:"disabled,omitempty"`
+	Models          []string `mapstructure:"models" yaml:"models,omitempty"`                     // 允许的模型，空表示仅默认模型，"*" 表示任意模型
+	RequireApproval *bool    `mapstructure:"require_approval" yaml:"require_approval,omitempty"` // 覆盖全局审批设置
+}
+
+// ToolsConfig 工具配置
+type ToolsConfig struct {
+	Retrieval ToolRetrievalConfig `mapstructure:"retrieval" yaml:"retrieval"`
+	Artifacts ToolArtifactsConfig `mapstructure:"artifacts" yaml:"artifacts"`
+}
+
+// ToolRetrievalConfig 工具检索模式：只向模型提供核心工具和 search_tools，其余工具（含 MCP 工具）按需搜索激活
+type ToolRetrievalConfig struct {
+	Enabled    bool     `mapstructure:"enabled" yaml:"enabled"`
+	Core       []string `mapstructure:"core" yaml:"core,omitempty"`               // 始终加载的工具，支持 glob
+	MaxResults int      `mapstructure:"max_results" yaml:"max_results,omitempty"` // search_tools 默认返回数量
+}
+
+// ToolArtifactsConfig 工具结果卸载：超过阈值的工具结果存入会话 artifact，上下文中只保留预览和句柄，模型可用 recall_artifact 按需读取
+type ToolArtifactsConfig struct {
+	Enabled   bool `mapstructure:"enabled" yaml:"enabled"`
+	Threshold int  `mapstructure:"threshold" yaml:"threshold,omitempty"` // 超过此字节数的结果被卸载
+	Preview   int  `mapstructure:"preview" yaml:"preview,omitempty"`     // 存根中保留的开头字节数
 }
 
 // ChannelsConfig 渠道配置
@@ -342,6 +468,12 @@ type ChannelsConfig struct {
 	IMessage       IMessageConfig       `mapstructure:"imessage" yaml:"imessage"`
 	AppleNotes     AppleNotesConfig     `mapstructure:"apple_notes" yaml:"apple_notes"`
 	AppleReminders AppleRemindersConfig `mapstructure:"apple_reminders" yaml:"apple_reminders"`
+	Telegram       TelegramConfig       `mapstructure:"telegram" yaml:"telegram"`
+	Email          EmailConfig          `mapstructure:"email" yaml:"email"`
+	Matrix         MatrixConfig         `mapstructure:"matrix" yaml:"matrix"`
+	Slack          SlackConfig          `mapstructure:"slack" yaml:"slack"`
+	Webhook        WebhookConfig        `mapstructure:"webhook" yaml:"webhook"`
+	Routing        ChannelRoutingConfig `mapstructure:"routing" yaml:"routing"`
 }
 
 // TriggerConfig 触发配置
@@ -389,6 +521,136 @@ type AppleRemindersConfig struct {
 	Reply        ReplyConfig   `mapstructure:"reply" yaml:"reply"`
 }
 
+// TelegramConfig Telegram Bot 渠道配置
+type TelegramConfig struct {
+	Enabled        bool          `mapstructure:"enabled" yaml:"enabled"`
+	Model          string        `mapstructure:"model" yaml:"model,omitempty"` // 渠道专属模型（空=使用默认）
+	Token          string        `mapstructure:"token" yaml:"token"`
+	BaseURL        string        `mapstructure:"base_url" yaml:"base_url,omitempty"`             // Bot API 地址，可指向本地测试服务
+	Mode           string        `mapstructure:"mode" yaml:"mode"`                               // polling 或 webhook
+	PollTimeout    time.Duration `mapstructure:"poll_timeout" yaml:"poll_timeout"`               // 长轮询超时
+	WebhookURL     string        `mapstructure:"webhook_url" yaml:"webhook_url,omitempty"`       // 注册给 Telegram 的公网地址
+	WebhookListen  string        `mapstructure:"webhook_listen" yaml:"webhook_listen,omitempty"` // 本地监听地址，如 ":8443"
+	WebhookSecret  string        `mapstructure:"webhook_secret" yaml:"webhook_secret,omitempty"`
+	RequireMention bool          `mapstructure:"require_mention" yaml:"require_mention"` // 群聊中需 @ 机器人
+	AllowFrom      []string      `mapstructure:"allow_from" yaml:"allow_from"`           // 用户 ID、用户名或会话 ID 白名单，必填
+	Trigger        TriggerConfig `mapstructure:"trigger" yaml:"trigger"`
+	Reply          ReplyConfig   `mapstructure:"reply" yaml:"reply"`
+}
+
+// EmailConfig 邮件渠道配置（IMAP 收信，SMTP 回复）
+type EmailConfig struct {
+	Enabled              bool          `mapstructure:"enabled" yaml:"enabled"`
+	Model                string        `mapstructure:"model" yaml:"model,omitempty"` // 渠道专属模型（空=使用默认）
+	IMAPAddr             string        `mapstructure:"imap_addr" yaml:"imap_addr"`   // host:port
+	IMAPTLS              bool          `mapstructure:"imap_tls" yaml:"imap_tls"`     // 隐式 TLS；为 false 时尝试 STARTTLS
+	Username             string        `mapstructure:"username" yaml:"username"`
+	Password             string        `mapstructure:"password" yaml:"password"`
+	Folder               string        `mapstructure:"folder" yaml:"folder"`
+	PollInterval         time.Duration `mapstructure:"poll_interval" yaml:"poll_interval"`
+	UseIdle              bool          `mapstructure:"use_idle" yaml:"use_idle"`                           // 服务器支持时使用 IDLE
+	SMTPAddr             string        `mapstructure:"smtp_addr" yaml:"smtp_addr"`                         // host:port
+	SMTPTLS              bool          `mapstructure:"smtp_tls" yaml:"smtp_tls"`                           // 隐式 TLS（465）；为 false 时尝试 STARTTLS
+	SMTPUsername         string        `mapstructure:"smtp_username" yaml:"smtp_username,omitempty"`       // 空=使用 username
+	SMTPPassword         string        `mapstructure:"smtp_password" yaml:"smtp_password,omitempty"`       // 空=使用 password
+	From                 string        `mapstructure:"from" yaml:"from,omitempty"`                         // 发件地址，空=使用 username
+	AllowFrom            []string      `mapstructure:"allow_from" yaml:"allow_from"`                       // 发件人地址或 "@域名" 白名单，必填
+	RequireAuthenticated bool          `mapstructure:"require_authenticated" yaml:"require_authenticated"` // 要求收件服务器验证过发件人域名
+	AuthServID           string        `mapstructure:"auth_serv_id" yaml:"auth_serv_id"`                   // 收件服务器的 authserv-id，只信任它的 Authentication-Results
+	Trigger              TriggerConfig `mapstructure:"trigger" yaml:"trigger"`
+	Reply                ReplyConfig   `mapstructure:"reply" yaml:"reply"`
+}
+
+// MatrixConfig Matrix 渠道配置
+type MatrixConfig struct {
+	Enabled        bool          `mapstructure:"enabled" yaml:"enabled"`
+	Model          string        `mapstructure:"model" yaml:"model,omitempty"` // 渠道专属模型（空=使用默认）
+	Homeserver     string        `mapstructure:"homeserver" yaml:"homeserver"` // 如 https://matrix.example.org
+	AccessToken    string        `mapstructure:"access_token" yaml:"access_token"`
+	SyncTimeout    time.Duration `mapstructure:"sync_timeout" yaml:"sync_timeout"`
+	RequireMention bool          `mapstructure:"require_mention" yaml:"require_mention"` // 群聊房间中需提及机器人
+	AllowFrom      []string      `mapstructure:"allow_from" yaml:"allow_from"`           // 用户 ID、":服务器" 或房间 ID 白名单
+	AutoJoin       bool          `mapstructure:"auto_join" yaml:"auto_join"`             // 自动接受白名单用户的邀请，需设置 allow_from
+	Trigger        TriggerConfig `mapstructure:"trigger" yaml:"trigger"`
+	Reply          ReplyConfig   `mapstructure:"reply" yaml:"reply"`
+}
+
+// SlackConfig Slack 渠道配置
+type SlackConfig struct {
+	Enabled        bool          `mapstructure:"enabled" yaml:"enabled"`
+	Model          string        `mapstructure:"model" yaml:"model,omitempty"` // 渠道专属模型（空=使用默认）
+	BotToken       string        `mapstructure:"bot_token" yaml:"bot_token"`   // xoxb-...
+	AppToken       string        `mapstructure:"app_token" yaml:"app_token"`   // xapp-...，Socket Mode 需要
+	BaseURL        string        `mapstructure:"base_url" yaml:"base_url,omitempty"`
+	Mode           string        `mapstructure:"mode" yaml:"mode"` // socket 或 events
+	SigningSecret  string        `mapstructure:"signing_secret" yaml:"signing_secret,omitempty"`
+	EventsListen   string        `mapstructure:"events_listen" yaml:"events_listen,omitempty"` // Events API 本地监听地址，如 ":3000"
+	EventsPath     string        `mapstructure:"events_path" yaml:"events_path,omitempty"`
+	RequireMention bool          `mapstructure:"require_mention" yaml:"require_mention"` // 频道中需 @ 机器人
+	AllowFrom      []string      `mapstructure:"allow_from" yaml:"allow_from"`           // 用户 ID 或频道 ID 白名单
+	Trigger        TriggerConfig `mapstructure:"trigger" yaml:"trigger"`
+	Reply          ReplyConfig   `mapstructure:"reply" yaml:"reply"`
+}
+
+// ChannelRoutingConfig 渠道消息路由：会话映射、Agent 选择、限流和排队
+type ChannelRoutingConfig struct {
+	IdleReset      time.Duration      `mapstructure:"idle_reset" yaml:"idle_reset"`           // 会话空闲超过该时间后开启新会话，0 表示不重置
+	ThreadSessions bool               `mapstructure:"thread_sessions" yaml:"thread_sessions"` // 群聊中每个线程使用独立会话
+	RateLimit      int                `mapstructure:"rate_limit" yaml:"rate_limit"`           // 每个发送者在 rate_window 内最多处理的消息数，0 表示不限制
+	RateWindow     time.Duration      `mapstructure:"rate_window" yaml:"rate_window"`
+	QueueSize      int                `mapstructure:"queue_size" yaml:"queue_size"` // 运行期间每个会话最多排队的消息数
+	Rules          []ChannelRouteRule `mapstructure:"rules" yaml:"rules,omitempty"`
+}
+
+// ChannelRouteRule 按渠道和会话选择 Agent，按顺序匹配第一条
+type ChannelRouteRule struct {
+	Channel   string        `mapstructure:"channel" yaml:"channel,omitempty"` // 渠道类型，空表示任意渠道
+	ChatID    string        `mapstructure:"chat_id" yaml:"chat_id,omitempty"` // 会话 ID，空表示任意会话
+	Agent     string        `mapstructure:"agent" yaml:"agent,omitempty"`     // agents 中的名称，空表示主 Agent
+	IdleReset time.Duration `mapstructure:"idle_reset" yaml:"idle_reset,omitempty"`
+}
+
+// WebhookConfig 通用 webhook 渠道配置
+type WebhookConfig struct {
+	Enabled      bool                `mapstructure:"enabled" yaml:"enabled"`
+	Model        string              `mapstructure:"model" yaml:"model,omitempty"` // 渠道专属模型（空=使用默认）
+	Hooks        []WebhookHookConfig `mapstructure:"hooks" yaml:"hooks"`
+	MaxBodyBytes int                 `mapstructure:"max_body_bytes" yaml:"max_body_bytes,omitempty"`
+	Trigger      TriggerConfig       `mapstructure:"trigger" yaml:"trigger"`
+	Reply        ReplyConfig         `mapstructure:"reply" yaml:"reply"`
+}
+
+// WebhookHookConfig 一个命名的 webhook 入口，接收 /api/v1/channels/webhook/{name}
+type WebhookHookConfig struct {
+	Name             string               `mapstructure:"name" yaml:"name"`
+	Auth             string               `mapstructure:"auth" yaml:"auth"`     // hmac、bearer 或 none
+	Secret           string               `mapstructure:"secret" yaml:"secret"` // HMAC 密钥或 token
+	SignatureHeader  string               `mapstructure:"signature_header" yaml:"signature_header,omitempty"`
+	TokenHeader      string               `mapstructure:"token_header" yaml:"token_header,omitempty"`
+	Match            []WebhookMatchConfig `mapstructure:"match" yaml:"match,omitempty"`
+	Mapping          WebhookMappingConfig `mapstructure:"mapping" yaml:"mapping"`
+	CallbackURL      string               `mapstructure:"callback_url" yaml:"callback_url,omitempty"` // 回复地址模板，为空则不回复
+	CallbackMethod   string               `mapstructure:"callback_method" yaml:"callback_method,omitempty"`
+	CallbackHeaders  map[string]string    `mapstructure:"callback_headers" yaml:"callback_headers,omitempty"`
+	CallbackTemplate string               `mapstructure:"callback_template" yaml:"callback_template,omitempty"`
+}
+
+// WebhookMatchConfig 过滤条件：JSON 路径上的值等于 value 才处理
+type WebhookMatchConfig struct {
+	Path  string `mapstructure:"path" yaml:"path"`
+	Value string `mapstructure:"value" yaml:"value"`
+}
+
+// WebhookMappingConfig 入站 JSON 到消息字段的路径映射
+type WebhookMappingConfig struct {
+	Content         string `mapstructure:"content" yaml:"content,omitempty"`
+	ContentTemplate string `mapstructure:"content_template" yaml:"content_template,omitempty"`
+	ChatID          string `mapstructure:"chat_id" yaml:"chat_id,omitempty"`
+	SenderID        string `mapstructure:"sender_id" yaml:"sender_id,omitempty"`
+	SenderName      string `mapstructure:"sender_name" yaml:"sender_name,omitempty"`
+	MessageID       string `mapstructure:"message_id" yaml:"message_id,omitempty"`
+}
+
 var (
 	globalConfig     *Config
 	configPath       string
@@ -512,6 +774,17 @@ func GetConfig() *Config {
 	return globalConfig
 }
 
+// GetChannelsConfig 从 viper 读取最新的渠道配置，包含通过 API 修改但尚未重新加载的值
+func GetChannelsConfig() ChannelsConfig {
+	mu.RLock()
+	defer mu.RUnlock()
+	var cfg ChannelsConfig
+	if err := viper.UnmarshalKey("channels", &cfg); err != nil && globalConfig != nil {
+		return globalConfig.Channels
+	}
+	return cfg
+}
+
 // Get 获取任意配置键值
 func Get(key string) any {
 	return viper.Get(key)
@@ -52,9 +52,33 @@ func SetDefaults() {
 	// MCP Server 配置
 	viper.SetDefault("mcp.server.enabled", true)
 	viper.SetDefault("mcp.server.transport", "stdio")
+	viper.SetDefault("mcp.server.expose.prompts", []string{"*"})
+	viper.SetDefault("mcp.server.expose.agents", []string{})
+	viper.SetDefault("mcp.server.expose.memory", false)
 
 	// MCP Client 配置
 	viper.SetDefault("mcp.client.enabled", false)
+	viper.SetDefault("mcp.client.sampling.enabled", false)
+	viper.SetDefault("mcp.client.sampling.require_approval", true)
+	viper.SetDefault("mcp.client.sampling.max_tokens", 4096)
+	viper.SetDefault("mcp.client.elicitation", true)
+	viper.SetDefault("mcp.client.roots", true)
+	viper.SetDefault("mcp.client.health_check.enabled", true)
+	viper.SetDefault("mcp.client.health_check.interval", 30*time.Second)
+	viper.SetDefault("mcp.client.health_check.timeout", 5*time.Second)
+	viper.SetDefault("mcp.client.health_check.failure_threshold", 2)
+	viper.SetDefault("mcp.client.health_check.max_restarts", 5)
+
+	// 工具检索配置
+	viper.SetDefault("tools.retrieval.enabled", false)
+	viper.SetDefault("tools.retrieval.core", []string{
+		"read_file", "write_file", "edit_file", "list_dir", "shell",
+		"memory_search", "delegate", "pda_control", "recall_artifact",
+	})
+	viper.SetDefault("tools.retrieval.max_results", 5)
+	viper.SetDefault("tools.artifacts.enabled", true)
+	viper.SetDefault("tools.artifacts.threshold", 16384)
+	viper.SetDefault("tools.artifacts.preview", 2048)
 
 	// Copilot 配置
 	// NOTE: Default model must be compatible with provider.default (copilot-acp).
@@ -108,4 +132,74 @@ func SetDefaults() {
 	viper.SetDefault("channels.apple_reminders.trigger.case_sensitive", false)
 	viper.SetDefault("channels.apple_reminders.reply.prefix", "[Mote]")
 	viper.SetDefault("channels.apple_reminders.reply.separator", "\n")
+
+	// Telegram
+	viper.SetDefault("channels.telegram.enabled", false)
+	viper.SetDefault("channels.telegram.token", "")
+	viper.SetDefault("channels.telegram.base_url", "https://api.telegram.org")
+	viper.SetDefault("channels.telegram.mode", "polling")
+	viper.SetDefault("channels.telegram.poll_timeout", 30*time.Second)
+	viper.SetDefault("channels.telegram.require_mention", true)
+	viper.SetDefault("channels.telegram.allow_from", []string{})
+	viper.SetDefault("channels.telegram.trigger.prefix", "")
+	viper.SetDefault("channels.telegram.reply.prefix", "")
+
+	// Email
+	viper.SetDefault("channels.email.enabled", false)
+	viper.SetDefault("channels.email.imap_tls", true)
+	viper.SetDefault("channels.email.folder", "INBOX")
+	viper.SetDefault("channels.email.poll_interval", time.Minute)
+	viper.SetDefault("channels.email.use_idle", true)
+	viper.SetDefault("channels.email.smtp_tls", false)
+	viper.SetDefault("channels.email.allow_from", []string{})
+	viper.SetDefault("channels.email.require_authenticated", true)
+	viper.SetDefault("channels.email.auth_serv_id", "")
+	viper.SetDefault("channels.email.trigger.prefix", "")
+	viper.SetDefault("channels.email.reply.prefix", "")
+
+	// Matrix
+	viper.SetDefault("channels.matrix.enabled", false)
+	viper.SetDefault("channels.matrix.homeserver", "")
+	viper.SetDefault("channels.matrix.access_token", "")
+	viper.SetDefault("channels.matrix.sync_timeout", 30*time.Second)
+	viper.SetDefault("channels.matrix.require_mention", true)
+	viper.SetDefault("channels.matrix.allow_from", []string{})
+	viper.SetDefault("channels.matrix.auto_join", true)
+	viper.SetDefault("channels.matrix.trigger.prefix", "")
+	viper.SetDefault("channels.matrix.reply.prefix", "")
+
+	// Slack
+	viper.SetDefault("channels.slack.enabled", false)
+	viper.SetDefault("channels.slack.bot_token", "")
+	viper.SetDefault("channels.slack.app_token", "")
+	viper.SetDefault("channels.slack.base_url", "https://slack.com/api")
+	viper.SetDefault("channels.slack.mode", "socket")
+	viper.SetDefault("channels.slack.events_path", "/slack/events")
+	viper.SetDefault("channels.slack.require_mention", true)
+	viper.SetDefault("channels.slack.allow_from", []string{})
+	viper.SetDefault("channels.slack.trigger.prefix", "")
+	viper.SetDefault("channels.slack.reply.prefix", "")
+
+	// Webhook
+	viper.SetDefault("channels.webhook.enabled", false)
+	viper.SetDefault("channels.webhook.trigger.prefix", "")
+	viper.SetDefault("channels.webhook.reply.prefix", "")
+
+	// 渠道消息路由
+	viper.SetDefault("channels.routing.idle_reset", time.Duration(0))
+	viper.SetDefault("channels.routing.thread_sessions", true)
+	viper.SetDefault("channels.routing.rate_limit", 0)
+	viper.SetDefault("channels.routing.rate_window", time.Hour)
+	viper.SetDefault("channels.routing.queue_size", 5)
+
+	// 工具调用审批
+	viper.SetDefault("approvals.timeout", 5*time.Minute)
+	viper.SetDefault("approvals.public_url", "")
+	viper.SetDefault("approvals.session_rule_ttl", 24*time.Hour)
+	viper.SetDefault("approvals.workspace_rule_ttl", 30*24*time.Hour)
+
+	// 工具调用安全策略
+	viper.SetDefault("policy.file", "~/.mote/policy.yaml")
+	viper.SetDefault("policy.decision_log", "")
+	viper.SetDefault("policy.egress_proxy", "127.0.0.1:0")
 }
@@ -1,5 +1,13 @@
 package config
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
 // SecretStore abstracts encrypted credential storage.
 // Phase 3: Currently all secrets are stored in plaintext config files.
 // Future implementations will use platform-specific secure storage.
@@ -47,6 +55,103 @@ func (p *PlaintextStore) Available() bool {
 	return true
 }
 
+// FileSecretStore keeps secrets in a JSON file readable only by the owner.
+// It is used for credentials Mote obtains at runtime, such as OAuth tokens.
+type FileSecretStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSecretStore creates a file-backed secret store at path.
+func NewFileSecretStore(path string) *FileSecretStore {
+	return &FileSecretStore{path: path}
+}
+
+// DefaultSecretsPath returns the default secrets file path (~/.mote/secrets.json).
+func DefaultSecretsPath() (string, error) {
+	dir, err := DefaultConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "secrets.json"), nil
+}
+
+// Get returns the secret for key, or "" if none is stored.
+func (f *FileSecretStore) Get(key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	secrets, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	return secrets[key], nil
+}
+
+// Set stores a secret for key.
+func (f *FileSecretStore) Set(key string, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return f.save(secrets)
+}
+
+// Delete removes the secret for key.
+func (f *FileSecretStore) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+	delete(secrets, key)
+	return f.save(secrets)
+}
+
+// Available returns true — the file is created on first write.
+func (f *FileSecretStore) Available() bool {
+	return f.path != ""
+}
+
+func (f *FileSecretStore) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read secrets: %w", err)
+	}
+	if len(data) == 0 {
+		return secrets, nil
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("parse secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+func (f *FileSecretStore) save(secrets map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("create secrets dir: %w", err)
+	}
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write secrets: %w", err)
+	}
+	return os.Rename(tmp, f.path)
+}
+
 // KeychainStore uses macOS Keychain for secure storage.
 // Phase 3 implementation placeholder.
 // type KeychainStore struct{}
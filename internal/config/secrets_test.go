@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSecretStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "secrets.json")
+	store := NewFileSecretStore(path)
+
+	if v, err := store.Get("missing"); err != nil || v != "" {
+		t.Fatalf("Get on empty store = %q, %v", v, err)
+	}
+
+	if err := store.Set("token", "s3cret"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("secrets file not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("secrets file mode = %o, want 600", perm)
+	}
+
+	reopened := NewFileSecretStore(path)
+	if v, _ := reopened.Get("token"); v != "s3cret" {
+		t.Errorf("Get after reopen = %q, want %q", v, "s3cret")
+	}
+
+	if err := reopened.Delete("token"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if v, _ := store.Get("token"); v != "" {
+		t.Errorf("Get after Delete = %q, want empty", v)
+	}
+}
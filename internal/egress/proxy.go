@@ -0,0 +1,392 @@
+// Package egress runs the local proxy that applies the egress policy to
+// processes started by tools (shell commands, background processes, script
+// tools and stdio MCP servers).
+//
+// Processes are pointed at the proxy through HTTP_PROXY and HTTPS_PROXY.
+// The proxy URL carries a token that identifies the tool, session and agent
+// the process was started for, so per-agent overlays apply. This only
+// covers programs that honour the proxy variables; it is not a sandbox.
+package egress
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mote/internal/policy"
+)
+
+// maxClients bounds the shared proxy tokens. Own tokens belong to running
+// processes and are never evicted.
+const maxClients = 1000
+
+// maxBlocked bounds the blocked connections kept per token until taken.
+const maxBlocked = 20
+
+// Proxy is a local HTTP proxy that checks every request and CONNECT tunnel
+// against the egress policy.
+type Proxy struct {
+	guard *policy.EgressGuard
+
+	mu       sync.Mutex
+	clients  map[string]*client // by token
+	tokens   map[policy.EgressRequest]string
+	listener net.Listener
+	server   *http.Server
+}
+
+// client is the state kept for one proxy token.
+type client struct {
+	req       policy.EgressRequest
+	transport *http.Transport
+	blocked   []string
+	lastUsed  time.Time
+	own       bool
+	refs      int // calls using a shared token
+}
+
+// NewProxy creates a proxy that checks connections with guard.
+func NewProxy(guard *policy.EgressGuard) *Proxy {
+	return &Proxy{
+		guard:   guard,
+		clients: make(map[string]*client),
+		tokens:  make(map[policy.EgressRequest]string),
+	}
+}
+
+// Start listens on addr (e.g. "127.0.0.1:0") and serves in the background.
+func (p *Proxy) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("egress: failed to listen on %s: %w", addr, err)
+	}
+	server := &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+
+	p.mu.Lock()
+	p.listener = ln
+	p.server = server
+	p.mu.Unlock()
+
+	go func() { _ = server.Serve(ln) }()
+	return nil
+}
+
+// Close stops the proxy.
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	server := p.server
+	p.server, p.listener = nil, nil
+	p.mu.Unlock()
+	if server == nil {
+		return nil
+	}
+	return server.Close()
+}
+
+// Addr returns the address the proxy listens on, or "" if not started.
+func (p *Proxy) Addr() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.listener == nil {
+		return ""
+	}
+	return p.listener.Addr().String()
+}
+
+// Env returns the environment variables that route a process started for
+// req through the proxy, and the token identifying it. It returns nil if
+// the proxy is not running or no egress policy applies to req. The token is
+// shared by concurrent calls for req; Release it when the call ends.
+func (p *Proxy) Env(req policy.EgressRequest) ([]string, string) {
+	return p.env(req, false)
+}
+
+// ProcessEnv is like Env but the token is not shared with other calls for
+// the same req, so connections blocked for a long-running process can be
+// told apart. Release the token when the process is gone.
+func (p *Proxy) ProcessEnv(req policy.EgressRequest) ([]string, string) {
+	return p.env(req, true)
+}
+
+func (p *Proxy) env(req policy.EgressRequest, own bool) ([]string, string) {
+	if p == nil || !p.guard.Enabled(req) {
+		return nil, ""
+	}
+	addr := p.Addr()
+	if addr == "" {
+		return nil, ""
+	}
+	token := p.register(req, own)
+	proxyURL := "http://" + token + "@" + addr
+	return []string{
+		"HTTP_PROXY=" + proxyURL,
+		"HTTPS_PROXY=" + proxyURL,
+		"http_proxy=" + proxyURL,
+		"https_proxy=" + proxyURL,
+		"NO_PROXY=",
+		"no_proxy=",
+	}, token
+}
+
+// TakeBlocked returns and clears the connections blocked for token since
+// the last call.
+func (p *Proxy) TakeBlocked(token string) []string {
+	if p == nil || token == "" {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.clients[token]
+	if !ok {
+		return nil
+	}
+	blocked := c.blocked
+	c.blocked = nil
+	return blocked
+}
+
+// Release gives up token. An own token is dropped; a shared one once no
+// call uses it any more. Later requests with a dropped token are rejected.
+func (p *Proxy) Release(token string) {
+	if p == nil || token == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.clients[token]; ok && !c.own && c.refs > 1 {
+		c.refs--
+		return
+	}
+	p.dropLocked(token)
+}
+
+// register returns the token for req, creating one if needed. An own token
+// is always new and not reused for req.
+func (p *Proxy) register(req policy.EgressRequest, own bool) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if token, ok := p.tokens[req]; ok && !own {
+		c := p.clients[token]
+		c.lastUsed = time.Now()
+		c.refs++
+		return token
+	}
+	if !own && len(p.tokens) >= maxClients {
+		p.evictOldestLocked()
+	}
+	token := strings.ReplaceAll(uuid.NewString(), "-", "")
+	c := p.newClient(req)
+	c.own = own
+	if !own {
+		c.refs = 1
+		p.tokens[req] = token
+	}
+	p.clients[token] = c
+	return token
+}
+
+// evictOldestLocked drops the least recently used shared token, e.g. one a
+// caller never released.
+func (p *Proxy) evictOldestLocked() {
+	var oldest string
+	var oldestTime time.Time
+	for token, c := range p.clients {
+		if c.own {
+			continue
+		}
+		if oldest == "" || c.lastUsed.Before(oldestTime) {
+			oldest, oldestTime = token, c.lastUsed
+		}
+	}
+	p.dropLocked(oldest)
+}
+
+// dropLocked removes the client for token. Caller holds p.mu.
+func (p *Proxy) dropLocked(token string) {
+	c, ok := p.clients[token]
+	if !ok {
+		return
+	}
+	c.transport.CloseIdleConnections()
+	if p.tokens[c.req] == token {
+		delete(p.tokens, c.req)
+	}
+	delete(p.clients, token)
+}
+
+func (p *Proxy) newClient(req policy.EgressRequest) *client {
+	return &client{
+		req: req,
+		transport: &http.Transport{
+			DialContext:         p.guard.DialContext(req),
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		lastUsed: time.Now(),
+	}
+}
+
+// clientFor returns the client identified by the request's proxy
+// credentials. Requests without credentials are challenged and unknown or
+// released tokens are refused rather than checked against some other
+// caller's policy.
+func (p *Proxy) clientFor(w http.ResponseWriter, r *http.Request) (*client, bool) {
+	header := r.Header.Get("Proxy-Authorization")
+	if header == "" {
+		w.Header().Set("Proxy-Authenticate", `Basic realm="mote-egress"`)
+		http.Error(w, "egress proxy: credentials required", http.StatusProxyAuthRequired)
+		return nil, false
+	}
+	token := proxyToken(header)
+	p.mu.Lock()
+	c, ok := p.clients[token]
+	if ok {
+		c.lastUsed = time.Now()
+	}
+	p.mu.Unlock()
+	if !ok {
+		w.Header().Set("X-Mote-Egress", "blocked")
+		http.Error(w, "egress proxy: unknown or expired token", http.StatusForbidden)
+		return nil, false
+	}
+	return c, true
+}
+
+// proxyToken extracts the user name from Basic proxy credentials.
+func proxyToken(header string) string {
+	encoded, ok := strings.CutPrefix(header, "Basic ")
+	if !ok {
+		return ""
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return ""
+	}
+	user, _, _ := strings.Cut(string(decoded), ":")
+	return user
+}
+
+// ServeHTTP proxies a request or CONNECT tunnel.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, ok := p.clientFor(w, r)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodConnect {
+		p.serveConnect(w, r, c)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "egress proxy: absolute URL required", http.StatusBadRequest)
+		return
+	}
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	removeHopHeaders(out.Header)
+	resp, err := c.transport.RoundTrip(out)
+	if err != nil {
+		p.fail(w, c, r.URL.Host, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	removeHopHeaders(resp.Header)
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func (p *Proxy) serveConnect(w http.ResponseWriter, r *http.Request, c *client) {
+	upstream, err := c.transport.DialContext(r.Context(), "tcp", r.Host)
+	if err != nil {
+		p.fail(w, c, r.Host, err)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "egress proxy: hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	if _, err := conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		conn.Close()
+		upstream.Close()
+		return
+	}
+
+	go func() {
+		defer upstream.Close()
+		// Bytes the client sent after the CONNECT request
+		if n := buf.Reader.Buffered(); n > 0 {
+			pending, _ := buf.Reader.Peek(n)
+			if _, err := upstream.Write(pending); err != nil {
+				return
+			}
+		}
+		_, _ = io.Copy(upstream, conn)
+	}()
+	go func() {
+		defer conn.Close()
+		_, _ = io.Copy(conn, upstream)
+	}()
+}
+
+// fail answers a request that could not be forwarded. Blocked connections
+// get 403 and are kept for TakeBlocked.
+func (p *Proxy) fail(w http.ResponseWriter, c *client, host string, err error) {
+	var egressErr *policy.EgressError
+	if !errors.As(err, &egressErr) {
+		http.Error(w, fmt.Sprintf("egress proxy: %v", err), http.StatusBadGateway)
+		return
+	}
+	p.mu.Lock()
+	if len(c.blocked) < maxBlocked {
+		c.blocked = append(c.blocked, fmt.Sprintf("%s [%s]", egressErr.Error(), egressErr.Rule))
+	}
+	p.mu.Unlock()
+	w.Header().Set("X-Mote-Egress", "blocked")
+	http.Error(w, "Blocked by Mote egress policy: "+egressErr.Reason, http.StatusForbidden)
+}
+
+// hopHeaders are removed when forwarding (RFC 9110 section 7.6.1).
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, f := range h["Connection"] {
+		for _, name := range strings.Split(f, ",") {
+			h.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopHeaders {
+		h.Del(name)
+	}
+}
@@ -0,0 +1,235 @@
+package egress
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mote/internal/policy"
+)
+
+func newTestProxy(t *testing.T, pol *policy.ToolPolicy) (*Proxy, *policy.PolicyExecutor) {
+	executor := policy.NewPolicyExecutor(pol)
+	p := NewProxy(policy.NewEgressGuard(executor))
+	require.NoError(t, p.Start("127.0.0.1:0"))
+	t.Cleanup(func() { _ = p.Close() })
+	return p, executor
+}
+
+// proxyURL returns the proxy URL from the environment Env returned.
+func proxyURL(t *testing.T, env []string) *url.URL {
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, "HTTP_PROXY="); ok {
+			u, err := url.Parse(v)
+			require.NoError(t, err)
+			return u
+		}
+	}
+	t.Fatal("HTTP_PROXY not set")
+	return nil
+}
+
+func TestProxy_Env(t *testing.T) {
+	p, _ := newTestProxy(t, &policy.ToolPolicy{DefaultAllow: true})
+	env, token := p.Env(policy.EgressRequest{Tool: "shell"})
+	assert.Nil(t, env, "no egress policy, no proxy")
+	assert.Empty(t, token)
+
+	p, _ = newTestProxy(t, &policy.ToolPolicy{DefaultAllow: true, Egress: &policy.EgressPolicy{}})
+	req := policy.EgressRequest{Tool: "shell", SessionID: "s1"}
+	env, token = p.Env(req)
+	assert.Contains(t, env, "NO_PROXY=")
+	assert.Equal(t, token, proxyURL(t, env).User.Username())
+	assert.Equal(t, p.Addr(), proxyURL(t, env).Host)
+
+	_, again := p.Env(req)
+	assert.Equal(t, token, again, "tokens are reused per request")
+
+	var nilProxy *Proxy
+	env, _ = nilProxy.Env(req)
+	assert.Nil(t, env)
+}
+
+func TestProxy_HTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Proxy-Authorization"))
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer upstream.Close()
+
+	p, executor := newTestProxy(t, &policy.ToolPolicy{
+		DefaultAllow: true,
+		Egress:       &policy.EgressPolicy{BlockPrivate: true},
+		Overlays: []policy.PolicyOverlay{
+			{Agent: "local", Egress: &policy.EgressPolicy{Allow: []string{"127.0.0.0/8"}}},
+		},
+	})
+
+	get := func(req policy.EgressRequest) (*http.Response, string) {
+		env, token := p.Env(req)
+		client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL(t, env))}}
+		resp, err := client.Get(upstream.URL)
+		require.NoError(t, err)
+		return resp, token
+	}
+
+	resp, token := get(policy.EgressRequest{Tool: "shell", SessionID: "s1"})
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "blocked", resp.Header.Get("X-Mote-Egress"))
+	assert.Contains(t, string(body), "private address")
+
+	blocked := p.TakeBlocked(token)
+	require.Len(t, blocked, 1)
+	assert.Contains(t, blocked[0], "127.0.0.1")
+	assert.Contains(t, blocked[0], "[egress:private]")
+	assert.Empty(t, p.TakeBlocked(token), "taken")
+
+	decisions := executor.RecentDecisions(0)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, "shell", decisions[0].Call.Name)
+	assert.Equal(t, "s1", decisions[0].Call.SessionID)
+
+	resp, _ = get(policy.EgressRequest{Tool: "shell", AgentID: "local"})
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello", string(body))
+}
+
+func TestProxy_Connect(t *testing.T) {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer upstream.Close()
+	go func() {
+		conn, err := upstream.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		_, _ = conn.Write([]byte("echo " + line))
+	}()
+
+	p, _ := newTestProxy(t, &policy.ToolPolicy{
+		DefaultAllow: true,
+		Egress:       &policy.EgressPolicy{Deny: []string{"10.0.0.0/8"}},
+	})
+	env, _ := p.Env(policy.EgressRequest{Tool: "shell"})
+	auth := proxyURL(t, env).User.Username()
+
+	connect := func(target string) (*bufio.Reader, net.Conn, *http.Response) {
+		conn, err := net.Dial("tcp", p.Addr())
+		require.NoError(t, err)
+		fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\nProxy-Authorization: Basic %s\r\n\r\n",
+			target, target, basicAuth(auth))
+		r := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(r, &http.Request{Method: http.MethodConnect})
+		require.NoError(t, err)
+		return r, conn, resp
+	}
+
+	r, conn, resp := connect(upstream.Addr().String())
+	defer conn.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	_, _ = conn.Write([]byte("ping\n"))
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "echo ping\n", line)
+
+	_, conn2, resp := connect("10.255.255.1:443")
+	defer conn2.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestProxy_Tokens(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer upstream.Close()
+
+	p, _ := newTestProxy(t, &policy.ToolPolicy{DefaultAllow: true, Egress: &policy.EgressPolicy{}})
+	do := func(proxy *url.URL) *http.Response {
+		client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxy)}}
+		resp, err := client.Get(upstream.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := do(&url.URL{Scheme: "http", Host: p.Addr()})
+	assert.Equal(t, http.StatusProxyAuthRequired, resp.StatusCode, "no credentials")
+	assert.NotEmpty(t, resp.Header.Get("Proxy-Authenticate"))
+
+	resp = do(&url.URL{Scheme: "http", Host: p.Addr(), User: url.User("unknown")})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "unknown token")
+
+	req := policy.EgressRequest{Tool: "process", SessionID: "s1"}
+	env1, token1 := p.ProcessEnv(req)
+	_, token2 := p.ProcessEnv(req)
+	_, shared := p.Env(req)
+	assert.NotEqual(t, token1, token2, "each process gets its own token")
+	assert.NotEqual(t, token1, shared)
+	assert.Equal(t, http.StatusOK, do(proxyURL(t, env1)).StatusCode)
+
+	p.Release(token1)
+	assert.Equal(t, http.StatusForbidden, do(proxyURL(t, env1)).StatusCode, "released token")
+	_, again := p.Env(req)
+	assert.Equal(t, shared, again, "releasing a process token keeps the shared one")
+}
+
+func TestProxy_Release(t *testing.T) {
+	p, _ := newTestProxy(t, &policy.ToolPolicy{DefaultAllow: true, Egress: &policy.EgressPolicy{}})
+	known := func(token string) bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		_, ok := p.clients[token]
+		return ok
+	}
+
+	req := policy.EgressRequest{Tool: "shell", SessionID: "s1"}
+	_, first := p.Env(req)
+	_, second := p.Env(req)
+	require.Equal(t, first, second)
+	p.Release(first)
+	assert.True(t, known(first), "still used by the second call")
+	p.Release(second)
+	assert.False(t, known(first), "released by the last call")
+
+	// Only shared tokens are evicted when there are too many.
+	_, own := p.ProcessEnv(req)
+	_, mcp := p.ProcessEnv(policy.EgressRequest{Tool: "mcp:docs"})
+	for i := 0; i <= maxClients; i++ {
+		p.Env(policy.EgressRequest{Tool: "shell", SessionID: fmt.Sprintf("s%d", i)})
+	}
+	assert.True(t, known(own), "process token evicted")
+	assert.True(t, known(mcp), "MCP server token evicted")
+	p.mu.Lock()
+	assert.Len(t, p.tokens, maxClients)
+	p.mu.Unlock()
+}
+
+func TestProxyToken(t *testing.T) {
+	assert.Equal(t, "abc", proxyToken("Basic "+basicAuth("abc")))
+	assert.Equal(t, "abc", proxyToken("Basic "+basicAuth("abc:secret")))
+	assert.Empty(t, proxyToken("Bearer abc"))
+	assert.Empty(t, proxyToken("Basic !!!"))
+}
+
+func basicAuth(userinfo string) string {
+	if !strings.Contains(userinfo, ":") {
+		userinfo += ":"
+	}
+	return base64.StdEncoding.EncodeToString([]byte(userinfo))
+}
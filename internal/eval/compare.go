@@ -0,0 +1,101 @@
+package eval
+
+import "sort"
+
+// Case comparison outcomes.
+const (
+	ChangeFixed     = "fixed"
+	ChangeRegressed = "regressed"
+	ChangeSame      = "unchanged"
+	ChangeAdded     = "added"
+	ChangeRemoved   = "removed"
+)
+
+// CaseDiff compares one case across two runs.
+type CaseDiff struct {
+	Case    string `json:"case"`
+	BaseOK  *bool  `json:"base_passed,omitempty"`
+	OtherOK *bool  `json:"other_passed,omitempty"`
+	Change  string `json:"change"`
+	// TokenDelta is other minus base.
+	TokenDelta int `json:"token_delta"`
+	// Failures are the other run's failure messages for this case.
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Comparison diffs two runs, typically two models or two agent versions
+// on the same suite.
+type Comparison struct {
+	Base          Run        `json:"base"`
+	Other         Run        `json:"other"`
+	PassRateDelta float64    `json:"pass_rate_delta"`
+	Fixed         int        `json:"fixed"`
+	Regressed     int        `json:"regressed"`
+	Cases         []CaseDiff `json:"cases"`
+}
+
+// Compare diffs other against base, case by case.
+func Compare(base, other *Run) *Comparison {
+	cmp := &Comparison{
+		Base:          *base,
+		Other:         *other,
+		PassRateDelta: other.PassRate() - base.PassRate(),
+	}
+	cmp.Base.Results = nil
+	cmp.Other.Results = nil
+
+	baseByCase := make(map[string]CaseResult, len(base.Results))
+	for _, r := range base.Results {
+		baseByCase[r.Case] = r
+	}
+	seen := make(map[string]bool, len(other.Results))
+
+	for _, o := range other.Results {
+		seen[o.Case] = true
+		d := CaseDiff{Case: o.Case, OtherOK: boolPtr(o.Passed), Failures: o.Failures}
+		b, ok := baseByCase[o.Case]
+		switch {
+		case !ok:
+			d.Change = ChangeAdded
+		case !b.Passed && o.Passed:
+			d.Change = ChangeFixed
+			cmp.Fixed++
+		case b.Passed && !o.Passed:
+			d.Change = ChangeRegressed
+			cmp.Regressed++
+		default:
+			d.Change = ChangeSame
+		}
+		if ok {
+			d.BaseOK = boolPtr(b.Passed)
+			d.TokenDelta = o.TotalTokens - b.TotalTokens
+		}
+		cmp.Cases = append(cmp.Cases, d)
+	}
+	for _, b := range base.Results {
+		if !seen[b.Case] {
+			cmp.Cases = append(cmp.Cases, CaseDiff{Case: b.Case, BaseOK: boolPtr(b.Passed), Change: ChangeRemoved})
+		}
+	}
+
+	sort.SliceStable(cmp.Cases, func(i, j int) bool {
+		return changeRank(cmp.Cases[i].Change) < changeRank(cmp.Cases[j].Change)
+	})
+	return cmp
+}
+
+// changeRank orders regressions first so they are the first thing read.
+func changeRank(change string) int {
+	switch change {
+	case ChangeRegressed:
+		return 0
+	case ChangeFixed:
+		return 1
+	case ChangeAdded, ChangeRemoved:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
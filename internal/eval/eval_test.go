@@ -0,0 +1,261 @@
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mote/internal/config"
+	"mote/internal/eval"
+	"mote/internal/runner/types"
+	"mote/internal/storage"
+)
+
+const testSuite = `
+name: researcher-basics
+agent: researcher
+cases:
+  - name: finds-sources
+    input: "Find sources on SQLite WAL"
+    expect_tools: [web_search, web_fetch]
+    forbid_tools: [shell]
+    assertions:
+      - type: regex
+        value: "(?i)write-ahead"
+      - type: json_path
+        path: $.sources[1].title
+        equals: "WAL docs"
+  - name: stays-polite
+    input: "Summarise"
+    assertions:
+      - type: not_contains
+        value: "idiot"
+      - type: judge
+        rubric: "Is a summary"
+`
+
+type fakeExecutor struct {
+	outputs map[string]*eval.Output
+	models  []string
+}
+
+func (f *fakeExecutor) Execute(_ context.Context, req eval.ExecRequest) (*eval.Output, error) {
+	f.models = append(f.models, req.Agent.Model)
+	out, ok := f.outputs[req.Input]
+	if !ok {
+		return nil, errors.New("no output")
+	}
+	return out, nil
+}
+
+type fakeJudge struct{ pass bool }
+
+func (j fakeJudge) Grade(_ context.Context, _, _, _, _ string) (eval.Verdict, error) {
+	return eval.Verdict{Pass: j.pass, Reason: "judged"}, nil
+}
+
+func openStore(t *testing.T) *eval.Store {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "eval.db"))
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return eval.NewStore(db.DB)
+}
+
+func TestParseSuite_Validation(t *testing.T) {
+	if _, err := eval.ParseSuite([]byte(testSuite)); err != nil {
+		t.Fatalf("valid suite rejected: %v", err)
+	}
+
+	bad := map[string]string{
+		"missing agent":  "name: s\ncases: [{name: a, input: x}]",
+		"no cases":       "name: s\nagent: a",
+		"duplicate case": "name: s\nagent: a\ncases: [{name: a, input: x}, {name: a, input: y}]",
+		"bad regex":      "name: s\nagent: a\ncases: [{name: a, input: x, assertions: [{type: regex, value: '('}]}]",
+		"bad path":       "name: s\nagent: a\ncases: [{name: a, input: x, assertions: [{type: json_path, path: 'items'}]}]",
+		"unknown type":   "name: s\nagent: a\ncases: [{name: a, input: x, assertions: [{type: fuzzy}]}]",
+		"judge rubric":   "name: s\nagent: a\ncases: [{name: a, input: x, assertions: [{type: judge}]}]",
+	}
+	for name, src := range bad {
+		if _, err := eval.ParseSuite([]byte(src)); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	suite, err := eval.ParseSuite([]byte(testSuite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &fakeExecutor{outputs: map[string]*eval.Output{
+		"Find sources on SQLite WAL": {
+			Content:   "Write-ahead logging:\n```json\n{\"sources\":[{\"title\":\"intro\"},{\"title\":\"WAL docs\"}]}\n```",
+			ToolCalls: []string{"web_search", "read_file", "web_fetch"},
+			Usage:     types.Usage{TotalTokens: 100},
+		},
+		"Summarise": {Content: "A short summary.", Usage: types.Usage{TotalTokens: 20}},
+	}}
+	store := openStore(t)
+	runner := eval.NewRunner(exec, fakeJudge{pass: true}, store)
+
+	run, err := runner.Run(context.Background(), suite, config.AgentConfig{Model: "base"}, eval.RunOptions{Model: "gpt-4o", Label: "v1"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if run.Passed != 2 || run.Total != 2 || run.TotalTokens != 120 {
+		for _, r := range run.Results {
+			t.Logf("%s: %v", r.Case, r.Failures)
+		}
+		t.Fatalf("unexpected run totals: %+v", run)
+	}
+	if exec.models[0] != "gpt-4o" || run.Model != "gpt-4o" {
+		t.Errorf("model override not applied: %v", exec.models)
+	}
+
+	stored, err := store.GetRun(run.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("GetRun: %v %v", stored, err)
+	}
+	if stored.Status != eval.StatusCompleted || stored.Passed != 2 || len(stored.Results) != 2 || stored.Label != "v1" {
+		t.Errorf("unexpected stored run: %+v", stored)
+	}
+	if stored.Results[0].ToolCalls[2] != "web_fetch" {
+		t.Errorf("tool calls not stored: %v", stored.Results[0].ToolCalls)
+	}
+
+	runs, err := store.ListRuns("researcher-basics", 10)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListRuns = %d, %v", len(runs), err)
+	}
+}
+
+func TestRunner_BeginExecute(t *testing.T) {
+	suite, err := eval.ParseSuite([]byte(testSuite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &fakeExecutor{outputs: map[string]*eval.Output{"Summarise": {Content: "A short summary."}}}
+	store := openStore(t)
+	runner := eval.NewRunner(exec, fakeJudge{pass: true}, store)
+
+	run, err := runner.Begin(suite, config.AgentConfig{Model: "base"}, eval.RunOptions{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	stored, err := store.GetRun(run.ID)
+	if err != nil || stored == nil || stored.Status != eval.StatusRunning || len(exec.models) != 0 {
+		t.Fatalf("expected a recorded run with no case executed, got %+v (%v), calls %v", stored, err, exec.models)
+	}
+
+	if err := runner.Execute(context.Background(), run, suite, config.AgentConfig{Model: "base"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(exec.models) != 2 || exec.models[0] != "gpt-4o" {
+		t.Errorf("expected both cases on the run's model, got %v", exec.models)
+	}
+	stored, err = store.GetRun(run.ID)
+	if err != nil || stored.Status != eval.StatusCompleted || len(stored.Results) != 2 {
+		t.Errorf("unexpected stored run after Execute: %+v (%v)", stored, err)
+	}
+}
+
+func TestStore_FailStaleRuns(t *testing.T) {
+	suite, err := eval.ParseSuite([]byte(testSuite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := openStore(t)
+	runner := eval.NewRunner(&fakeExecutor{}, nil, store)
+	stale, err := runner.Begin(suite, config.AgentConfig{Model: "base"}, eval.RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := store.FailStaleRuns()
+	if err != nil || n != 1 {
+		t.Fatalf("FailStaleRuns = %d, %v", n, err)
+	}
+	stored, err := store.GetRun(stale.ID)
+	if err != nil || stored.Status != eval.StatusFailed || stored.FinishedAt == nil {
+		t.Errorf("stale run not failed: %+v (%v)", stored, err)
+	}
+	if n, _ := store.FailStaleRuns(); n != 0 {
+		t.Errorf("finished runs should be left alone, updated %d", n)
+	}
+}
+
+func TestRunner_Failures(t *testing.T) {
+	suite, err := eval.ParseSuite([]byte(testSuite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &fakeExecutor{outputs: map[string]*eval.Output{
+		"Find sources on SQLite WAL": {
+			Content:   `{"sources":[{"title":"intro"}]}`,
+			ToolCalls: []string{"web_fetch", "web_search", "shell"},
+		},
+	}}
+	run, err := eval.NewRunner(exec, fakeJudge{pass: false}, nil).
+		Run(context.Background(), suite, config.AgentConfig{}, eval.RunOptions{})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if run.Passed != 0 {
+		t.Fatalf("expected all cases to fail, got %d passed", run.Passed)
+	}
+
+	failures := strings.Join(run.Results[0].Failures, "\n")
+	for _, want := range []string{"expected tool web_fetch", "forbidden tool shell", "regex", "path not found"} {
+		if !strings.Contains(failures, want) {
+			t.Errorf("missing failure %q in:\n%s", want, failures)
+		}
+	}
+	if run.Results[1].Error == "" {
+		t.Error("expected execution error for case without output")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base := &eval.Run{ID: "a", Total: 3, Passed: 2, Results: []eval.CaseResult{
+		{Case: "one", Passed: true, TotalTokens: 10},
+		{Case: "two", Passed: false},
+		{Case: "gone", Passed: true},
+	}}
+	other := &eval.Run{ID: "b", Total: 3, Passed: 2, Results: []eval.CaseResult{
+		{Case: "one", Passed: false, TotalTokens: 15},
+		{Case: "two", Passed: true},
+		{Case: "new", Passed: true},
+	}}
+
+	cmp := eval.Compare(base, other)
+	if cmp.Fixed != 1 || cmp.Regressed != 1 {
+		t.Errorf("fixed=%d regressed=%d", cmp.Fixed, cmp.Regressed)
+	}
+	if cmp.Cases[0].Case != "one" || cmp.Cases[0].Change != eval.ChangeRegressed || cmp.Cases[0].TokenDelta != 5 {
+		t.Errorf("regressions should sort first: %+v", cmp.Cases[0])
+	}
+	changes := map[string]string{}
+	for _, c := range cmp.Cases {
+		changes[c.Case] = c.Change
+	}
+	if changes["new"] != eval.ChangeAdded || changes["gone"] != eval.ChangeRemoved || changes["two"] != eval.ChangeFixed {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+}
+
+func TestAgentVersion(t *testing.T) {
+	a := config.AgentConfig{SystemPrompt: "be brief", Model: "x"}
+	b := config.AgentConfig{SystemPrompt: "be brief", Model: "y"}
+	c := config.AgentConfig{SystemPrompt: "be thorough", Model: "x"}
+	if eval.AgentVersion(a) != eval.AgentVersion(b) {
+		t.Error("model should not affect agent version")
+	}
+	if eval.AgentVersion(a) == eval.AgentVersion(c) {
+		t.Error("prompt change should change agent version")
+	}
+}
@@ -0,0 +1,147 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"mote/internal/provider"
+	"mote/internal/runner/delegate"
+	"mote/internal/runner/types"
+	"mote/internal/scheduler"
+)
+
+// FactoryExecutor runs cases through the delegate SubRunnerFactory, the same
+// path used for @-mentions, so agents with steps go through the PDA engine.
+type FactoryExecutor struct {
+	factory  *delegate.SubRunnerFactory
+	maxDepth int
+}
+
+// NewFactoryExecutor creates an Executor backed by factory.
+func NewFactoryExecutor(factory *delegate.SubRunnerFactory, maxDepth int) *FactoryExecutor {
+	return &FactoryExecutor{factory: factory, maxDepth: maxDepth}
+}
+
+// Execute implements Executor. The case runs in a session of its own, which
+// is deleted together with the sub-agent sessions created under it.
+func (e *FactoryExecutor) Execute(ctx context.Context, req ExecRequest) (*Output, error) {
+	sessions := e.factory.Sessions()
+	if _, err := sessions.DB().CreateSessionWithID(req.SessionID, nil, "model", req.Agent.Model, "scenario", "eval"); err != nil {
+		return nil, fmt.Errorf("create eval session: %w", err)
+	}
+	defer deleteCaseSessions(sessions, req.SessionID)
+
+	dc := &delegate.DelegateContext{
+		Depth:           0,
+		MaxDepth:        e.maxDepth,
+		ParentSessionID: req.SessionID,
+		AgentName:       req.AgentName,
+		Chain:           []string{req.AgentName},
+	}
+
+	var mu sync.Mutex
+	out := &Output{}
+	sink := delegate.ParentEventSink(func(event types.Event) {
+		if event.Type != types.EventTypeToolResult || event.ToolResult == nil {
+			return
+		}
+		// Only count the agent under test, not tool calls of nested delegates.
+		if event.AgentName != "" && event.AgentName != req.AgentName {
+			return
+		}
+		mu.Lock()
+		out.ToolCalls = append(out.ToolCalls, event.ToolResult.ToolName)
+		mu.Unlock()
+	})
+
+	var err error
+	if req.Agent.HasSteps() {
+		out.Content, out.Usage, err = e.factory.RunPDAWithEvents(ctx, dc, req.Agent, req.Input, sink)
+	} else {
+		out.Content, out.Usage, err = e.factory.RunDelegateWithEvents(ctx, dc, req.Agent, req.Input, sink)
+	}
+	return out, err
+}
+
+// deleteCaseSessions removes a case session and the sub-agent sessions
+// created under it, whose IDs embed the parent session ID.
+func deleteCaseSessions(sessions *scheduler.SessionManager, sessionID string) {
+	ids := []string{sessionID}
+	rows, err := sessions.DB().Query(
+		"SELECT id FROM sessions WHERE id LIKE 'delegate:%' AND instr(id, ?) > 0", sessionID+":")
+	if err == nil {
+		for rows.Next() {
+			var id string
+			if rows.Scan(&id) == nil {
+				ids = append(ids, id)
+			}
+		}
+		rows.Close()
+	}
+	for _, id := range ids {
+		if err := sessions.Delete(id); err != nil {
+			slog.Warn("eval: failed to delete case session", "session", id, "error", err)
+		}
+	}
+}
+
+const judgeSystemPrompt = `You are grading an AI agent's answer against a rubric.
+Reply with a single JSON object and nothing else:
+{"pass": true or false, "reason": "<one sentence>"}`
+
+// ProviderJudge grades rubrics with a single chat completion.
+type ProviderJudge struct {
+	pool *provider.MultiProviderPool
+}
+
+// NewProviderJudge creates a Judge that resolves models through pool.
+func NewProviderJudge(pool *provider.MultiProviderPool) *ProviderJudge {
+	return &ProviderJudge{pool: pool}
+}
+
+// Grade implements Judge.
+func (j *ProviderJudge) Grade(ctx context.Context, model, rubric, input, output string) (Verdict, error) {
+	prov, _, err := j.pool.GetProvider(model)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("get provider for judge model %s: %w", model, err)
+	}
+	resp, err := prov.Chat(ctx, provider.ChatRequest{
+		Model: model,
+		Messages: []provider.Message{
+			{Role: provider.RoleSystem, Content: judgeSystemPrompt},
+			{Role: provider.RoleUser, Content: fmt.Sprintf(
+				"Rubric:\n%s\n\nTask given to the agent:\n%s\n\nAgent answer:\n%s", rubric, input, output)},
+		},
+		Temperature: 0,
+	})
+	if err != nil {
+		return Verdict{}, err
+	}
+	return parseVerdict(resp.Content)
+}
+
+// parseVerdict reads the judge reply, accepting a JSON object or a reply
+// starting with PASS/FAIL.
+func parseVerdict(reply string) (Verdict, error) {
+	if doc, err := extractJSON(reply); err == nil {
+		if data, err := json.Marshal(doc); err == nil {
+			var v Verdict
+			if json.Unmarshal(data, &v) == nil {
+				return v, nil
+			}
+		}
+	}
+	trimmed := strings.TrimSpace(reply)
+	upper := strings.ToUpper(trimmed)
+	switch {
+	case strings.HasPrefix(upper, "PASS"):
+		return Verdict{Pass: true, Reason: trimmed}, nil
+	case strings.HasPrefix(upper, "FAIL"):
+		return Verdict{Pass: false, Reason: trimmed}, nil
+	}
+	return Verdict{}, fmt.Errorf("unrecognised judge reply: %q", trimmed)
+}
@@ -0,0 +1,58 @@
+package eval
+
+import (
+	"path/filepath"
+	"testing"
+
+	"mote/internal/scheduler"
+	"mote/internal/storage"
+)
+
+func TestDeleteCaseSessions(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "eval.db"))
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	sessions := scheduler.NewSessionManager(db, 10)
+
+	const caseSession = "eval:eval_1:finds-sources"
+	for _, id := range []string{
+		caseSession,
+		"delegate:" + caseSession + ":researcher:1",
+		"delegate:delegate:" + caseSession + ":researcher:1:writer:2",
+		"eval:eval_1:finds-sources-2",
+		"delegate:eval:eval_1:finds-sources-2:researcher:3",
+		"chat-session",
+	} {
+		if _, err := db.CreateSessionWithID(id, nil); err != nil {
+			t.Fatalf("create session %s: %v", id, err)
+		}
+	}
+
+	deleteCaseSessions(sessions, caseSession)
+
+	var remaining []string
+	rows, err := db.Query("SELECT id FROM sessions ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		remaining = append(remaining, id)
+	}
+	want := []string{"chat-session", "delegate:eval:eval_1:finds-sources-2:researcher:3", "eval:eval_1:finds-sources-2"}
+	if len(remaining) != len(want) {
+		t.Fatalf("remaining sessions = %v, want %v", remaining, want)
+	}
+	for i := range want {
+		if remaining[i] != want[i] {
+			t.Errorf("remaining sessions = %v, want %v", remaining, want)
+			break
+		}
+	}
+}
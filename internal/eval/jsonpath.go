@@ -0,0 +1,135 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a JSON path: an object key or an array index.
+type pathSegment struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// parsePath parses the JSON path subset used by json_path assertions:
+// a leading $, followed by .key and [N] segments.
+func parsePath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path %q must start with $", path)
+	}
+	var segs []pathSegment
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("json path %q has an empty key", path)
+			}
+			segs = append(segs, pathSegment{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("json path %q has an unclosed [", path)
+			}
+			inner := rest[1:end]
+			if q := strings.Trim(inner, `"'`); q != inner {
+				segs = append(segs, pathSegment{key: q})
+			} else {
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("json path %q has an invalid index %q", path, inner)
+				}
+				segs = append(segs, pathSegment{index: n, isIdx: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("json path %q: unexpected %q", path, rest[:1])
+		}
+	}
+	return segs, nil
+}
+
+// lookupPath resolves path in a decoded JSON document.
+func lookupPath(doc any, path string) (any, bool, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	cur := doc
+	for _, seg := range segs {
+		if seg.isIdx {
+			arr, ok := cur.([]any)
+			if !ok || seg.index >= len(arr) {
+				return nil, false, nil
+			}
+			cur = arr[seg.index]
+			continue
+		}
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false, nil
+		}
+		if cur, ok = obj[seg.key]; !ok {
+			return nil, false, nil
+		}
+	}
+	return cur, true, nil
+}
+
+// extractJSON decodes the JSON document in an agent reply. It accepts a bare
+// document, a fenced ```json block, or the outermost {...} / [...] span.
+func extractJSON(output string) (any, error) {
+	candidates := []string{strings.TrimSpace(output)}
+	if start := strings.Index(output, "```"); start >= 0 {
+		body := output[start+3:]
+		if nl := strings.IndexByte(body, '\n'); nl >= 0 {
+			body = body[nl+1:]
+		}
+		if end := strings.Index(body, "```"); end >= 0 {
+			candidates = append(candidates, strings.TrimSpace(body[:end]))
+		}
+	}
+	for _, pair := range [][2]string{{"{", "}"}, {"[", "]"}} {
+		start := strings.Index(output, pair[0])
+		end := strings.LastIndex(output, pair[1])
+		if start >= 0 && end > start {
+			candidates = append(candidates, output[start:end+1])
+		}
+	}
+
+	for _, c := range candidates {
+		var doc any
+		if err := json.Unmarshal([]byte(c), &doc); err == nil {
+			return doc, nil
+		}
+	}
+	return nil, fmt.Errorf("output does not contain valid JSON")
+}
+
+// jsonEqual compares a decoded JSON value with a YAML-decoded expectation.
+// Both sides are normalised through encoding/json so that e.g. YAML ints and
+// JSON float64s compare equal.
+func jsonEqual(got, want any) bool {
+	norm := func(v any) any {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var out any
+		if err := json.Unmarshal(data, &out); err != nil {
+			return v
+		}
+		return out
+	}
+	return reflect.DeepEqual(norm(got), norm(want))
+}
@@ -0,0 +1,318 @@
+package eval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"mote/internal/config"
+	"mote/internal/runner/types"
+)
+
+// Run statuses.
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// ExecRequest is one case execution handed to an Executor.
+type ExecRequest struct {
+	// SessionID names the case's ephemeral session. The Executor creates it
+	// and deletes it once the case is done.
+	SessionID string
+	AgentName string
+	Agent     config.AgentConfig
+	Input     string
+}
+
+// Output is what an agent produced for one case.
+type Output struct {
+	Content   string
+	ToolCalls []string
+	Usage     types.Usage
+}
+
+// Executor runs an agent on a single input.
+type Executor interface {
+	Execute(ctx context.Context, req ExecRequest) (*Output, error)
+}
+
+// Verdict is a judge model's decision on a rubric.
+type Verdict struct {
+	Pass   bool   `json:"pass"`
+	Reason string `json:"reason"`
+}
+
+// Judge grades an output against a free-form rubric.
+type Judge interface {
+	Grade(ctx context.Context, model, rubric, input, output string) (Verdict, error)
+}
+
+// Run is one execution of a suite against one model and agent version.
+type Run struct {
+	ID           string       `json:"id"`
+	Suite        string       `json:"suite"`
+	Agent        string       `json:"agent"`
+	Model        string       `json:"model"`
+	AgentVersion string       `json:"agent_version"`
+	Label        string       `json:"label,omitempty"`
+	Status       string       `json:"status"`
+	Total        int          `json:"total"`
+	Passed       int          `json:"passed"`
+	TotalTokens  int          `json:"total_tokens"`
+	StartedAt    time.Time    `json:"started_at"`
+	FinishedAt   *time.Time   `json:"finished_at,omitempty"`
+	Results      []CaseResult `json:"results,omitempty"`
+}
+
+// PassRate returns the fraction of passed cases in [0, 1].
+func (r *Run) PassRate() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Passed) / float64(r.Total)
+}
+
+// CaseResult is the outcome of one case.
+type CaseResult struct {
+	Case        string   `json:"case"`
+	Passed      bool     `json:"passed"`
+	Output      string   `json:"output"`
+	ToolCalls   []string `json:"tool_calls"`
+	Failures    []string `json:"failures"`
+	Error       string   `json:"error,omitempty"`
+	DurationMs  int64    `json:"duration_ms"`
+	TotalTokens int      `json:"total_tokens"`
+}
+
+// RunOptions selects what a suite is run against.
+type RunOptions struct {
+	// Model overrides the agent's model. Empty keeps the agent's own model.
+	Model string
+	// Label is a free-form tag stored with the run, e.g. "prompt-v2".
+	Label string
+}
+
+// Runner executes suites and records their results.
+type Runner struct {
+	exec  Executor
+	judge Judge
+	store *Store
+}
+
+// NewRunner creates a Runner. judge may be nil if no suite uses judge
+// assertions; store may be nil to skip persistence.
+func NewRunner(exec Executor, judge Judge, store *Store) *Runner {
+	return &Runner{exec: exec, judge: judge, store: store}
+}
+
+// Run executes every case of suite against agent. Case failures are recorded
+// in the returned Run; an error is returned only if the run itself could not
+// be carried out.
+func (r *Runner) Run(ctx context.Context, suite *Suite, agent config.AgentConfig, opts RunOptions) (*Run, error) {
+	run, err := r.Begin(suite, agent, opts)
+	if err != nil {
+		return nil, err
+	}
+	return run, r.Execute(ctx, run, suite, agent)
+}
+
+// Begin records a new run of suite against agent without executing it, so
+// that the run ID can be handed out before Execute runs in the background.
+func (r *Runner) Begin(suite *Suite, agent config.AgentConfig, opts RunOptions) (*Run, error) {
+	if r.exec == nil {
+		return nil, fmt.Errorf("eval executor not configured")
+	}
+	version := AgentVersion(agent)
+	if opts.Model != "" {
+		agent.Model = opts.Model
+	}
+
+	run := &Run{
+		ID:           fmt.Sprintf("eval_%d", time.Now().UnixNano()),
+		Suite:        suite.Name,
+		Agent:        suite.Agent,
+		Model:        agent.Model,
+		AgentVersion: version,
+		Label:        opts.Label,
+		Status:       StatusRunning,
+		Total:        len(suite.Cases),
+		StartedAt:    time.Now(),
+	}
+	if r.store != nil {
+		if err := r.store.CreateRun(run); err != nil {
+			return nil, fmt.Errorf("record eval run: %w", err)
+		}
+	}
+	return run, nil
+}
+
+// Execute runs the cases of a run created by Begin and records the results.
+func (r *Runner) Execute(ctx context.Context, run *Run, suite *Suite, agent config.AgentConfig) error {
+	agent.Model = run.Model
+	judgeModel := suite.JudgeModel
+	if judgeModel == "" {
+		judgeModel = agent.Model
+	}
+
+	for _, c := range suite.Cases {
+		if ctx.Err() != nil {
+			break
+		}
+		res := r.runCase(ctx, run, suite.Agent, agent, c, judgeModel)
+		if res.Passed {
+			run.Passed++
+		}
+		run.TotalTokens += res.TotalTokens
+		run.Results = append(run.Results, res)
+		if r.store != nil {
+			if err := r.store.SaveResult(run.ID, res); err != nil {
+				slog.Warn("eval: failed to save case result", "run", run.ID, "case", c.Name, "error", err)
+			}
+		}
+	}
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = StatusCompleted
+	if ctx.Err() != nil {
+		run.Status = StatusFailed
+	}
+	if r.store != nil {
+		if err := r.store.FinishRun(run); err != nil {
+			return fmt.Errorf("finish eval run: %w", err)
+		}
+	}
+	return ctx.Err()
+}
+
+func (r *Runner) runCase(ctx context.Context, run *Run, agentName string, agent config.AgentConfig, c Case, judgeModel string) CaseResult {
+	res := CaseResult{Case: c.Name, ToolCalls: []string{}, Failures: []string{}}
+	start := time.Now()
+	out, err := r.exec.Execute(ctx, ExecRequest{
+		SessionID: fmt.Sprintf("eval:%s:%s", run.ID, c.Name),
+		AgentName: agentName,
+		Agent:     agent,
+		Input:     c.Input,
+	})
+	res.DurationMs = time.Since(start).Milliseconds()
+	if out != nil {
+		res.Output = out.Content
+		if out.ToolCalls != nil {
+			res.ToolCalls = out.ToolCalls
+		}
+		res.TotalTokens = out.Usage.TotalTokens
+	}
+	if err != nil {
+		res.Error = err.Error()
+		res.Failures = append(res.Failures, "execution failed: "+err.Error())
+		return res
+	}
+
+	res.Failures = append(res.Failures, checkTools(c, res.ToolCalls)...)
+	for _, a := range c.Assertions {
+		if msg := r.check(ctx, a, c.Input, res.Output, judgeModel); msg != "" {
+			res.Failures = append(res.Failures, msg)
+		}
+	}
+	res.Passed = len(res.Failures) == 0
+	return res
+}
+
+// checkTools verifies expected tools were called in order and forbidden ones were not.
+func checkTools(c Case, calls []string) []string {
+	var failures []string
+	next := 0
+	for _, call := range calls {
+		if next < len(c.ExpectTools) && call == c.ExpectTools[next] {
+			next++
+		}
+	}
+	if next < len(c.ExpectTools) {
+		failures = append(failures, fmt.Sprintf("expected tool %s was not called (calls: %s)",
+			c.ExpectTools[next], strings.Join(calls, ", ")))
+	}
+	for _, forbidden := range c.ForbidTools {
+		for _, call := range calls {
+			if call == forbidden {
+				failures = append(failures, fmt.Sprintf("forbidden tool %s was called", forbidden))
+				break
+			}
+		}
+	}
+	return failures
+}
+
+// check evaluates one assertion and returns a failure message, or "" if it holds.
+func (r *Runner) check(ctx context.Context, a Assertion, input, output, judgeModel string) string {
+	switch a.Type {
+	case AssertContains:
+		if !strings.Contains(output, a.Value) {
+			return a.describe() + " not satisfied"
+		}
+	case AssertNotContains:
+		if strings.Contains(output, a.Value) {
+			return a.describe() + " not satisfied"
+		}
+	case AssertRegex, AssertNotRegex:
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return fmt.Sprintf("%s: %v", a.describe(), err)
+		}
+		if re.MatchString(output) != (a.Type == AssertRegex) {
+			return a.describe() + " not satisfied"
+		}
+	case AssertJSONPath:
+		doc, err := extractJSON(output)
+		if err != nil {
+			return fmt.Sprintf("%s: %v", a.describe(), err)
+		}
+		got, ok, err := lookupPath(doc, a.Path)
+		if err != nil {
+			return fmt.Sprintf("%s: %v", a.describe(), err)
+		}
+		if !ok {
+			return a.describe() + ": path not found"
+		}
+		if a.Equals != nil && !jsonEqual(got, a.Equals) {
+			return fmt.Sprintf("%s: got %v", a.describe(), got)
+		}
+	case AssertJudge:
+		if r.judge == nil {
+			return a.describe() + ": no judge configured"
+		}
+		if judgeModel == "" {
+			return a.describe() + ": no judge model (set judge_model in the suite)"
+		}
+		v, err := r.judge.Grade(ctx, judgeModel, a.Rubric, input, output)
+		if err != nil {
+			return fmt.Sprintf("%s: judge error: %v", a.describe(), err)
+		}
+		if !v.Pass {
+			return fmt.Sprintf("%s: %s", a.describe(), v.Reason)
+		}
+	default:
+		return "unknown assertion type " + a.Type
+	}
+	return ""
+}
+
+// AgentVersion fingerprints an agent config so runs of different prompt or
+// tool revisions can be told apart. The model is excluded because it is
+// varied independently.
+func AgentVersion(agent config.AgentConfig) string {
+	agent.Model = ""
+	data, err := json.Marshal(agent)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
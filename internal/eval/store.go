@@ -0,0 +1,164 @@
+package eval
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Store persists eval runs and case results.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a Store on the application database.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+const runColumns = `id, suite, agent, model, agent_version, label, status,
+		       total, passed, total_tokens, started_at, finished_at`
+
+// CreateRun records the start of a run.
+func (s *Store) CreateRun(run *Run) error {
+	_, err := s.db.Exec(`
+		INSERT INTO eval_runs (id, suite, agent, model, agent_version, label, status, total, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.ID, run.Suite, run.Agent, run.Model, run.AgentVersion, run.Label,
+		run.Status, run.Total, run.StartedAt)
+	return err
+}
+
+// SaveResult records the outcome of one case.
+func (s *Store) SaveResult(runID string, res CaseResult) error {
+	toolCalls, _ := json.Marshal(res.ToolCalls)
+	failures, _ := json.Marshal(res.Failures)
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO eval_results
+		(run_id, case_name, passed, output, tool_calls, failures, error, duration_ms, total_tokens)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		runID, res.Case, res.Passed, res.Output, string(toolCalls), string(failures),
+		res.Error, res.DurationMs, res.TotalTokens)
+	return err
+}
+
+// FinishRun stores the final status and totals of a run.
+func (s *Store) FinishRun(run *Run) error {
+	_, err := s.db.Exec(`
+		UPDATE eval_runs SET status = ?, passed = ?, total_tokens = ?, finished_at = ?
+		WHERE id = ?`,
+		run.Status, run.Passed, run.TotalTokens, run.FinishedAt, run.ID)
+	return err
+}
+
+// FailStaleRuns marks runs still recorded as running as failed. It is called
+// at startup, when no run can be in progress any more.
+func (s *Store) FailStaleRuns() (int64, error) {
+	res, err := s.db.Exec(`
+		UPDATE eval_runs SET status = ?, finished_at = ?
+		WHERE status = ?`,
+		StatusFailed, time.Now(), StatusRunning)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetRun returns a run with its case results, or nil if it does not exist.
+func (s *Store) GetRun(id string) (*Run, error) {
+	row := s.db.QueryRow(`SELECT `+runColumns+` FROM eval_runs WHERE id = ?`, id)
+	run, err := scanRun(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT case_name, passed, output, tool_calls, failures, error, duration_ms, total_tokens
+		FROM eval_results WHERE run_id = ? ORDER BY rowid`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var res CaseResult
+		var toolCalls, failures string
+		if err := rows.Scan(&res.Case, &res.Passed, &res.Output, &toolCalls, &failures,
+			&res.Error, &res.DurationMs, &res.TotalTokens); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal([]byte(toolCalls), &res.ToolCalls)
+		_ = json.Unmarshal([]byte(failures), &res.Failures)
+		run.Results = append(run.Results, res)
+	}
+	return run, rows.Err()
+}
+
+// ListRuns returns the most recent runs, newest first. An empty suite lists
+// runs of every suite.
+func (s *Store) ListRuns(suite string, limit int) ([]Run, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	query := `SELECT ` + runColumns + ` FROM eval_runs`
+	args := []any{}
+	if suite != "" {
+		query += ` WHERE suite = ?`
+		args = append(args, suite)
+	}
+	query += ` ORDER BY started_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// DeleteRun removes a run and its results.
+func (s *Store) DeleteRun(id string) error {
+	if _, err := s.db.Exec(`DELETE FROM eval_results WHERE run_id = ?`, id); err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`DELETE FROM eval_runs WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("eval run not found: %s", id)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRun(row rowScanner) (*Run, error) {
+	var run Run
+	var finishedAt sql.NullTime
+	if err := row.Scan(&run.ID, &run.Suite, &run.Agent, &run.Model, &run.AgentVersion,
+		&run.Label, &run.Status, &run.Total, &run.Passed, &run.TotalTokens,
+		&run.StartedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		t := finishedAt.Time
+		run.FinishedAt = &t
+	}
+	return &run, nil
+}
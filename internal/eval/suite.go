@@ -0,0 +1,205 @@
+// Package eval runs golden test suites against agents so that prompt and
+// model changes can be measured instead of guessed.
+//
+// A suite is a YAML file targeting one agent:
+//
+//	name: researcher-basics
+//	agent: researcher
+//	models: [gpt-4o, claude-sonnet-4]
+//	judge_model: gpt-4o
+//	cases:
+//	  - name: finds-sources
+//	    input: "Find two sources on SQLite WAL mode"
+//	    expect_tools: [web_search]
+//	    forbid_tools: [shell]
+//	    assertions:
+//	      - type: regex
+//	        value: "(?i)write-ahead"
+//	      - type: judge
+//	        rubric: "Cites at least two distinct sources"
+//
+// Each case is executed through the delegate SubRunnerFactory, so the agent
+// runs with exactly the tools, steps and prompts it has in normal use.
+package eval
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Assertion types.
+const (
+	AssertContains    = "contains"
+	AssertNotContains = "not_contains"
+	AssertRegex       = "regex"
+	AssertNotRegex    = "not_regex"
+	AssertJSONPath    = "json_path"
+	AssertJudge       = "judge"
+)
+
+// Suite is a set of golden cases for one agent.
+type Suite struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Agent       string   `yaml:"agent" json:"agent"`
+	Models      []string `yaml:"models,omitempty" json:"models,omitempty"`
+	// JudgeModel grades judge assertions. Empty uses the model under test.
+	JudgeModel string `yaml:"judge_model,omitempty" json:"judge_model,omitempty"`
+	Cases      []Case `yaml:"cases" json:"cases"`
+}
+
+// Case is one input and the expectations on the agent's behaviour.
+type Case struct {
+	Name  string `yaml:"name" json:"name"`
+	Input string `yaml:"input" json:"input"`
+	// ExpectTools must all be called, in this relative order.
+	ExpectTools []string `yaml:"expect_tools,omitempty" json:"expect_tools,omitempty"`
+	// ForbidTools must not be called.
+	ForbidTools []string    `yaml:"forbid_tools,omitempty" json:"forbid_tools,omitempty"`
+	Assertions  []Assertion `yaml:"assertions,omitempty" json:"assertions,omitempty"`
+}
+
+// Assertion checks the agent's final output.
+type Assertion struct {
+	Type  string `yaml:"type" json:"type"`
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+	// Path is a JSON path such as $.items[0].name (json_path only).
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Equals is compared with the value at Path; nil only requires it to exist.
+	Equals any `yaml:"equals,omitempty" json:"equals,omitempty"`
+	// Rubric is the grading instruction for the judge model (judge only).
+	Rubric string `yaml:"rubric,omitempty" json:"rubric,omitempty"`
+}
+
+// ParseSuite decodes and validates a suite from YAML.
+func ParseSuite(data []byte) (*Suite, error) {
+	var s Suite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse suite: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// LoadSuite reads a suite file. The suite name defaults to the file name.
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read suite: %w", err)
+	}
+	var s Suite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse suite %s: %w", path, err)
+	}
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// LoadSuites reads every *.yaml and *.yml suite in dir, sorted by name.
+func LoadSuites(dir string) ([]*Suite, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var suites []*Suite
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		s, err := LoadSuite(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, s)
+	}
+	sort.Slice(suites, func(i, j int) bool { return suites[i].Name < suites[j].Name })
+	return suites, nil
+}
+
+// Validate checks that the suite is runnable.
+func (s *Suite) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("suite name is required")
+	}
+	if s.Agent == "" {
+		return fmt.Errorf("suite %s: agent is required", s.Name)
+	}
+	if len(s.Cases) == 0 {
+		return fmt.Errorf("suite %s: at least one case is required", s.Name)
+	}
+	seen := make(map[string]bool, len(s.Cases))
+	for i, c := range s.Cases {
+		if c.Name == "" {
+			return fmt.Errorf("suite %s: case %d has no name", s.Name, i+1)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("suite %s: duplicate case name %q", s.Name, c.Name)
+		}
+		seen[c.Name] = true
+		if strings.TrimSpace(c.Input) == "" {
+			return fmt.Errorf("case %s: input is required", c.Name)
+		}
+		for j, a := range c.Assertions {
+			if err := a.validate(); err != nil {
+				return fmt.Errorf("case %s: assertion %d: %w", c.Name, j+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (a Assertion) validate() error {
+	switch a.Type {
+	case AssertContains, AssertNotContains:
+		if a.Value == "" {
+			return fmt.Errorf("%s requires value", a.Type)
+		}
+	case AssertRegex, AssertNotRegex:
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", a.Value, err)
+		}
+	case AssertJSONPath:
+		if _, err := parsePath(a.Path); err != nil {
+			return err
+		}
+	case AssertJudge:
+		if strings.TrimSpace(a.Rubric) == "" {
+			return fmt.Errorf("judge requires rubric")
+		}
+	default:
+		return fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+	return nil
+}
+
+// describe returns a short human-readable label for failure messages.
+func (a Assertion) describe() string {
+	switch a.Type {
+	case AssertJSONPath:
+		if a.Equals != nil {
+			return fmt.Sprintf("json_path %s == %v", a.Path, a.Equals)
+		}
+		return "json_path " + a.Path + " exists"
+	case AssertJudge:
+		return "judge: " + a.Rubric
+	default:
+		return fmt.Sprintf("%s %q", a.Type, a.Value)
+	}
+}
@@ -18,10 +18,12 @@ import (
 	"mote/internal/gateway/middleware"
 	"mote/internal/gateway/websocket"
 	"mote/internal/mcp/client"
+	"mote/internal/mcp/oauth"
 	"mote/internal/mcp/server"
 	"mote/internal/memory"
 	"mote/internal/policy"
 	"mote/internal/policy/approval"
+	"mote/internal/procmgr"
 	"mote/internal/prompts"
 	"mote/internal/provider"
 	"mote/internal/runner"
@@ -66,6 +68,8 @@ type Server struct {
 	versionChecker   interface{} // *skills.VersionChecker
 	skillUpdater     interface{} // *skills.SkillUpdater
 	delegateTracker  *delegate.DelegationTracker
+	processManager   *procmgr.BackgroundManager
+	mcpAuth          *oauth.Authenticator
 }
 
 // NewServer creates a new gateway server.
@@ -171,6 +175,12 @@ func (s *Server) setupRoutes() {
 	if s.delegateTracker != nil {
 		s.apiRouter.SetDelegateTracker(s.delegateTracker)
 	}
+	if s.processManager != nil {
+		s.apiRouter.SetProcessManager(s.processManager)
+	}
+	if s.mcpAuth != nil {
+		s.apiRouter.SetMCPAuthenticator(s.mcpAuth)
+	}
 
 	// Register API v1 routes
 	s.apiRouter.RegisterRoutes(s.router)
@@ -183,6 +193,11 @@ func (s *Server) setupRoutes() {
 		websocket.ServeWs(s.hub, w, r)
 	})
 
+	// MCP endpoint for hosts using Mote as an MCP server
+	if s.mcpServer != nil {
+		s.router.Handle("/mcp", s.mcpServer.Handler())
+	}
+
 	// Register UI routes (includes static file serving)
 	s.uiHandler.RegisterRoutes(s.router)
 }
@@ -231,6 +246,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	// Stop MCP server, cancelling in-flight tool calls
+	if s.mcpServer != nil {
+		_ = s.mcpServer.Close()
+	}
+
 	// Stop watcher if running
 	if s.watcher != nil {
 		s.watcher.Stop()
@@ -312,7 +332,8 @@ func (s *Server) handleWebSocketChat(sessionID, message string) (<-chan []byte,
 	ctx := context.Background()
 
 	// Run agent and convert events to WebSocket messages
-	events, err := s.agentRunner.Run(ctx, sessionID, message)
+	attachments := v1.BuildResourceAttachments(ctx, s.mcpClient, message)
+	events, err := s.agentRunner.Run(ctx, sessionID, message, attachments...)
 	if err != nil {
 		return nil, err
 	}
@@ -436,6 +457,21 @@ func (s *Server) SetMCPClient(c *client.Manager) {
 	if s.agentRunner != nil {
 		s.agentRunner.SetMCPManager(c)
 	}
+	// Push resource subscription updates to WebSocket clients
+	if c != nil && s.hub != nil {
+		c.SetResourceUpdateHandler(func(serverName, uri string) {
+			_ = s.hub.BroadcastTyped(websocket.TypeMCPResourceUpdated, map[string]string{
+				"server": serverName,
+				"uri":    uri,
+			})
+		})
+		// Let the UI reload tool and prompt lists that servers changed at runtime
+		c.AddListChangedHandler(func(serverName string) {
+			_ = s.hub.BroadcastTyped(websocket.TypeMCPListChanged, map[string]string{
+				"server": serverName,
+			})
+		})
+	}
 }
 
 // SetMCPServer sets the MCP server dependency.
@@ -525,6 +561,23 @@ func (s *Server) SetDelegateTracker(t *delegate.DelegationTracker) {
 	}
 }
 
+// SetProcessManager sets the background process manager for process queries
+// and session cleanup.
+func (s *Server) SetProcessManager(m *procmgr.BackgroundManager) {
+	s.processManager = m
+	if s.apiRouter != nil {
+		s.apiRouter.SetProcessManager(m)
+	}
+}
+
+// SetMCPAuthenticator sets the OAuth authenticator for remote MCP servers.
+func (s *Server) SetMCPAuthenticator(a *oauth.Authenticator) {
+	s.mcpAuth = a
+	if s.apiRouter != nil {
+		s.apiRouter.SetMCPAuthenticator(a)
+	}
+}
+
 // SetEmbeddedServer sets the embedded server reference for hot reload support.
 func (s *Server) SetEmbeddedServer(srv v1.EmbeddedServerInterface) {
 	s.embeddedServer = srv
@@ -54,4 +54,8 @@ const (
 	TypeUIState     = "ui_state"
 	TypeUIAction    = "ui_action"
 	TypeUIComponent = "ui_component"
+
+	// MCP message types
+	TypeMCPResourceUpdated = "mcp_resource_updated"
+	TypeMCPListChanged     = "mcp_list_changed"
 )
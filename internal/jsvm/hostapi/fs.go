@@ -9,6 +9,8 @@ import (
 	"github.com/dop251/goja"
 
 	"mote/internal/jsvmerr"
+	"mote/internal/policy"
+	"mote/internal/tools"
 )
 
 // registerFS registers mote.fs API.
@@ -22,7 +24,7 @@ func registerFS(vm *goja.Runtime, mote *goja.Object, hctx *Context) error {
 
 		path := call.Arguments[0].String()
 
-		absPath, err := validatePath(path, hctx.Config.AllowedPaths)
+		absPath, err := checkPath(hctx, path, policy.AccessRead)
 		if err != nil {
 			panic(vm.NewTypeError(err.Error()))
 		}
@@ -46,7 +48,7 @@ func registerFS(vm *goja.Runtime, mote *goja.Object, hctx *Context) error {
 		path := call.Arguments[0].String()
 		content := call.Arguments[1].String()
 
-		absPath, err := validatePath(path, hctx.Config.AllowedPaths)
+		absPath, err := checkPath(hctx, path, policy.AccessWrite)
 		if err != nil {
 			panic(vm.NewTypeError(err.Error()))
 		}
@@ -76,7 +78,7 @@ func registerFS(vm *goja.Runtime, mote *goja.Object, hctx *Context) error {
 
 		path := call.Arguments[0].String()
 
-		absPath, err := validatePath(path, hctx.Config.AllowedPaths)
+		absPath, err := checkPath(hctx, path, policy.AccessRead)
 		if err != nil {
 			// Path not allowed = doesn't exist from user's perspective
 			return vm.ToValue(false)
@@ -93,7 +95,7 @@ func registerFS(vm *goja.Runtime, mote *goja.Object, hctx *Context) error {
 
 		path := call.Arguments[0].String()
 
-		absPath, err := validatePath(path, hctx.Config.AllowedPaths)
+		absPath, err := checkPath(hctx, path, policy.AccessRead)
 		if err != nil {
 			panic(vm.NewTypeError(err.Error()))
 		}
@@ -122,111 +124,60 @@ func registerFS(vm *goja.Runtime, mote *goja.Object, hctx *Context) error {
 	return nil
 }
 
+// checkPath validates path against the sandbox's allowed paths and, if
+// set, the file guard. It returns the resolved path to access.
+func checkPath(hctx *Context, path string, mode policy.AccessMode) (string, error) {
+	absPath, err := validatePath(path, hctx.Config.AllowedPaths)
+	if err != nil || hctx.Config.FileGuard == nil {
+		return absPath, err
+	}
+	req := policy.FileAccessRequest{
+		Tool: scriptTool(hctx, "mote.fs"),
+		Path: absPath,
+		Mode: mode,
+	}
+	req.SessionID, _ = tools.SessionIDFromContext(hctx.Ctx)
+	req.AgentID, _ = tools.AgentIDFromContext(hctx.Ctx)
+	return hctx.Config.FileGuard.Check(req)
+}
+
 // ValidatePathPublic is the exported version of validatePath for use by sandbox.
 func ValidatePathPublic(path string, allowedPaths []string) (string, error) {
 	return validatePath(path, allowedPaths)
 }
 
-// validatePath checks if the path is within allowed directories.
+// validatePath checks if the path is within allowed directories. Symlinks
+// and ".." are resolved before the check and the resolved path is
+// returned, so a link inside an allowed directory cannot reach outside it.
 func validatePath(path string, allowedPaths []string) (string, error) {
-	// Expand ~ to home directory
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", &jsvmerr.PathNotAllowedError{Path: path}
-		}
-		path = filepath.Join(home, path[2:])
-	}
-
-	// Get absolute path
-	absPath, err := filepath.Abs(path)
+	realPath, err := policy.ResolvePath(path)
 	if err != nil {
 		return "", &jsvmerr.PathNotAllowedError{Path: path}
 	}
 
-	// Clean the path to prevent traversal
-	absPath = filepath.Clean(absPath)
-
-	// Resolve symlinks
-	realPath, err := filepath.EvalSymlinks(absPath)
-	if err != nil && !os.IsNotExist(err) {
-		// For write operations, parent must exist and be valid
-		parentPath := filepath.Dir(absPath)
-		realParent, parentErr := filepath.EvalSymlinks(parentPath)
-		if parentErr != nil {
-			return "", &jsvmerr.PathNotAllowedError{Path: path}
-		}
-		// Check parent is allowed, then use original absPath
-		if !isPathAllowed(realParent, allowedPaths) {
-			return "", &jsvmerr.PathNotAllowedError{Path: path}
-		}
-		return absPath, nil
-	}
-	if err == nil {
-		absPath = realPath
-	}
-
 	// Check against allowlist
-	if !isPathAllowed(absPath, allowedPaths) {
+	if !isPathAllowed(realPath, allowedPaths) {
 		return "", &jsvmerr.PathNotAllowedError{Path: path}
 	}
 
-	return absPath, nil
+	return realPath, nil
 }
 
-// isPathAllowed checks if path is under any allowed directory.
-func isPathAllowed(path string, allowedPaths []string) bool {
+// isPathAllowed checks if a resolved path is under any allowed directory.
+func isPathAllowed(realPath string, allowedPaths []string) bool {
 	if len(allowedPaths) == 0 {
 		return false // No paths allowed if list is empty
 	}
 
-	// Resolve the path to its real location (following symlinks)
-	realPath, err := filepath.EvalSymlinks(path)
-	if err != nil {
-		// For non-existent files, use parent directory
-		parentPath := filepath.Dir(path)
-		realParent, parentErr := filepath.EvalSymlinks(parentPath)
-		if parentErr == nil {
-			realPath = filepath.Join(realParent, filepath.Base(path))
-		} else {
-			realPath = path
-		}
-	}
-
 	for _, allowed := range allowedPaths {
-		// Expand ~ in allowed paths
-		if strings.HasPrefix(allowed, "~/") {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				continue
-			}
-			allowed = filepath.Join(home, allowed[2:])
-		}
-
-		allowed = filepath.Clean(allowed)
-
-		// Also resolve symlinks in allowed path
-		realAllowed, err := filepath.EvalSymlinks(allowed)
+		// Resolve the allowed path the same way (expands ~, follows symlinks)
+		realAllowed, err := policy.ResolvePath(allowed)
 		if err != nil {
-			realAllowed = allowed
-		}
-
-		// Check both original and resolved paths
-		pathsToCheck := []string{path, realPath}
-		allowedToCheck := []string{allowed, realAllowed}
-
-		for _, p := range pathsToCheck {
-			for _, a := range allowedToCheck {
-				if strings.HasPrefix(p, a) {
-					// Ensure it's a proper prefix
-					if p == a || (len(p) > len(a) && p[len(a)] == filepath.Separator) {
-						return true
-					}
-					if strings.HasSuffix(a, string(filepath.Separator)) {
-						return true
-					}
-				}
-			}
+			continue
+		}
+		if realPath == realAllowed ||
+			strings.HasPrefix(realPath, strings.TrimSuffix(realAllowed, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
 		}
 	}
 
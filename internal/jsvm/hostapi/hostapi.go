@@ -7,6 +7,7 @@ import (
 	"github.com/dop251/goja"
 	"github.com/rs/zerolog"
 
+	"mote/internal/policy"
 	"mote/internal/storage"
 	"mote/internal/tools"
 )
@@ -19,6 +20,12 @@ type Config struct {
 	HTTPAllowlist []string
 	// MaxWriteSize is the maximum file write size in bytes.
 	MaxWriteSize int64
+	// FileGuard applies the tool file access policy on top of AllowedPaths.
+	// Nil checks AllowedPaths only.
+	FileGuard *policy.FileGuard
+	// EgressGuard applies the egress policy to mote.http on top of
+	// HTTPAllowlist. Nil checks HTTPAllowlist only.
+	EgressGuard *policy.EgressGuard
 }
 
 // DefaultConfig returns default Host API configuration.
@@ -95,6 +102,22 @@ func registerContext(vm *goja.Runtime, mote *goja.Object, hctx *Context) error {
 	return nil
 }
 
+// scriptTool names the script for policy decisions, falling back to api.
+func scriptTool(hctx *Context, api string) string {
+	if hctx.ScriptName != "" {
+		return hctx.ScriptName
+	}
+	return api
+}
+
+// egressRequest identifies the script for the egress policy.
+func egressRequest(hctx *Context) policy.EgressRequest {
+	req := policy.EgressRequest{Tool: scriptTool(hctx, "mote.http")}
+	req.SessionID, _ = tools.SessionIDFromContext(hctx.Ctx)
+	req.AgentID, _ = tools.AgentIDFromContext(hctx.Ctx)
+	return req
+}
+
 // Unregister removes Host APIs from the VM.
 func Unregister(vm *goja.Runtime) {
 	_ = vm.GlobalObject().Delete("mote")
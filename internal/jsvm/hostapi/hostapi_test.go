@@ -3,6 +3,7 @@ package hostapi
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dop251/goja"
@@ -329,3 +330,33 @@ func TestHTTPAllowlist(t *testing.T) {
 		}
 	}
 }
+
+func TestFSSymlinkEscape(t *testing.T) {
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(allowed, "link")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	vm := goja.New()
+	hctx := &Context{
+		Ctx:        context.Background(),
+		Logger:     zerolog.Nop(),
+		ScriptName: "test.js",
+		Config: Config{
+			AllowedPaths: []string{allowed},
+			MaxWriteSize: 1024,
+		},
+	}
+	if err := Register(vm, hctx); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	_ = vm.Set("target", filepath.Join(allowed, "link", "new.txt"))
+
+	if _, err := vm.RunString(`mote.fs.write(target, "x")`); err == nil {
+		t.Error("expected error writing through symlink out of allowed paths")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new.txt")); !os.IsNotExist(err) {
+		t.Error("file was written outside allowed paths")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,8 @@ import (
 	"time"
 
 	"github.com/dop251/goja"
+
+	"mote/internal/policy"
 )
 
 // registerHTTP registers mote.http API.
@@ -120,9 +123,16 @@ func doHTTPRequest(vm *goja.Runtime, hctx *Context, method string, call goja.Fun
 	if insecure {
 		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // user-requested for self-signed certs
 	}
+	if hctx.Config.EgressGuard != nil {
+		transport.DialContext = hctx.Config.EgressGuard.DialContext(egressRequest(hctx))
+	}
 	client := &http.Client{Timeout: timeout, Transport: transport}
 	resp, err := client.Do(req)
 	if err != nil {
+		var egressErr *policy.EgressError
+		if errors.As(err, &egressErr) {
+			panic(vm.NewTypeError(egressErr.Error()))
+		}
 		panic(vm.NewTypeError(fmt.Sprintf("request failed: %v", err)))
 	}
 	defer resp.Body.Close()
@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"mote/internal/jsvmerr"
+	"mote/internal/policy"
 	"mote/internal/storage"
 )
 
@@ -48,6 +49,18 @@ func NewRuntime(cfg RuntimeConfig, db *storage.DB, logger zerolog.Logger) *Runti
 	}
 }
 
+// SetFileGuard sets the file access guard applied to mote.fs. Call it
+// before scripts run.
+func (r *Runtime) SetFileGuard(g *policy.FileGuard) {
+	r.config.SandboxConfig.FileGuard = g
+}
+
+// SetEgressGuard sets the egress guard applied to mote.http. Call it
+// before scripts run.
+func (r *Runtime) SetEgressGuard(g *policy.EgressGuard) {
+	r.config.SandboxConfig.EgressGuard = g
+}
+
 // ExecuteResult holds the result of script execution.
 type ExecuteResult struct {
 	// Value is the return value of the script.
@@ -9,6 +9,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"mote/internal/jsvm/hostapi"
+	"mote/internal/policy"
 	"mote/internal/storage"
 )
 
@@ -24,6 +25,10 @@ type SandboxConfig struct {
 	HTTPAllowlist []string
 	// MaxWriteSize is the maximum file write size in bytes.
 	MaxWriteSize int64
+	// FileGuard applies the tool file access policy to mote.fs.
+	FileGuard *policy.FileGuard
+	// EgressGuard applies the egress policy to mote.http.
+	EgressGuard *policy.EgressGuard
 }
 
 // DefaultSandboxConfig returns default sandbox configuration.
@@ -95,6 +100,8 @@ func (s *Sandbox) Setup(vm *goja.Runtime, ctx context.Context, scriptName, execu
 			AllowedPaths:  s.config.AllowedPaths,
 			HTTPAllowlist: s.config.HTTPAllowlist,
 			MaxWriteSize:  s.config.MaxWriteSize,
+			FileGuard:     s.config.FileGuard,
+			EgressGuard:   s.config.EgressGuard,
 		},
 	}
 	s.mu.Unlock()
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"sync"
 
+	"mote/internal/mcp/client"
 	"mote/internal/mcp/protocol"
 	"mote/internal/tools"
 )
@@ -204,6 +205,12 @@ func (a *ToolAdapter) ClientName() string {
 
 // Execute calls the tool on the remote MCP server.
 func (a *ToolAdapter) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	if sessionID, ok := tools.SessionIDFromContext(ctx); ok {
+		ctx = client.WithSessionID(ctx, sessionID)
+	}
+	if report, ok := tools.ProgressFromContext(ctx); ok {
+		ctx = client.WithProgressHandler(ctx, func(u client.ProgressUpdate) { report(u.String()) })
+	}
 	result, err := a.client.CallTool(ctx, a.info.Name, args)
 	if err != nil {
 		return tools.NewErrorResult(err.Error()), nil
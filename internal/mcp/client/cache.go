@@ -0,0 +1,134 @@
+package client
+
+import (
+	"encoding/json"
+	"path"
+	"sync"
+	"time"
+
+	"mote/internal/mcp/protocol"
+)
+
+// maxCacheEntries bounds the number of cached tool results per server.
+const maxCacheEntries = 256
+
+// toolCache caches tool results keyed by tool name and arguments.
+// A nil cache caches nothing.
+type toolCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	result  *protocol.CallToolResult
+	expires time.Time
+}
+
+func newToolCache(ttl time.Duration) *toolCache {
+	return &toolCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// cacheKey builds the key for a call. encoding/json sorts map keys, so equal
+// arguments give equal keys.
+func cacheKey(name string, args map[string]any) (string, bool) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return name + "\x00" + string(data), true
+}
+
+func (tc *toolCache) get(name string, args map[string]any) (*protocol.CallToolResult, bool) {
+	if tc == nil {
+		return nil, false
+	}
+	key, ok := cacheKey(name, args)
+	if !ok {
+		return nil, false
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	e, ok := tc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if tc.now().After(e.expires) {
+		delete(tc.entries, key)
+		return nil, false
+	}
+	return e.result, true
+}
+
+func (tc *toolCache) put(name string, args map[string]any, result *protocol.CallToolResult) {
+	if tc == nil {
+		return
+	}
+	key, ok := cacheKey(name, args)
+	if !ok {
+		return
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	now := tc.now()
+	if len(tc.entries) >= maxCacheEntries {
+		for k, e := range tc.entries {
+			if now.After(e.expires) {
+				delete(tc.entries, k)
+			}
+		}
+		if len(tc.entries) >= maxCacheEntries {
+			return
+		}
+	}
+	tc.entries[key] = cacheEntry{result: result, expires: now.Add(tc.ttl)}
+}
+
+// clear drops all cached results, e.g. after the tool list changed.
+func (tc *toolCache) clear() {
+	if tc == nil {
+		return
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.entries = make(map[string]cacheEntry)
+}
+
+// cacheable reports whether results of the named tool may be cached.
+func (c *Client) cacheable(name string) bool {
+	if c.cache == nil {
+		return false
+	}
+	if len(c.config.CacheTools) > 0 {
+		return matchAny(c.config.CacheTools, name)
+	}
+	for _, t := range c.Tools() {
+		if t.Name == name {
+			a := t.Annotations
+			return a != nil && isTrue(a.ReadOnlyHint) && isTrue(a.IdempotentHint)
+		}
+	}
+	return false
+}
+
+// matchAny reports whether name equals or glob-matches one of patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if p == "*" || p == name {
+			return true
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
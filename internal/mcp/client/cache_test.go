@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mote/internal/mcp/protocol"
+)
+
+func answerToolCall(t *testing.T, mockT *mockClientTransport, text string) {
+	t.Helper()
+	req := nextRequest(t, mockT)
+	if req.Method != protocol.MethodToolsCall {
+		t.Fatalf("expected tools/call, got %s", req.Method)
+	}
+	queueMessage(mockT, map[string]any{
+		"id":     req.ID,
+		"result": map[string]any{"content": []map[string]any{{"type": "text", "text": text}}},
+	})
+}
+
+func TestClient_CallToolCache(t *testing.T) {
+	c, mockT := startTestClient(t, "docs")
+	yes := true
+	c.tools = []protocol.Tool{
+		{Name: "lookup", Annotations: &protocol.ToolAnnotations{ReadOnlyHint: &yes, IdempotentHint: &yes}},
+		{Name: "write"},
+	}
+	c.config.CacheTTL = time.Minute
+	c.cache = newToolCache(time.Minute)
+	now := time.Now()
+	c.cache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	call := func(name string, args map[string]any) string {
+		t.Helper()
+		done := make(chan *protocol.CallToolResult, 1)
+		go func() {
+			res, err := c.CallTool(ctx, name, args)
+			if err != nil {
+				t.Errorf("CallTool(%s): %v", name, err)
+			}
+			done <- res
+		}()
+		select {
+		case res := <-done:
+			return res.Content[0].Text
+		case <-time.After(100 * time.Millisecond):
+			answerToolCall(t, mockT, name+" fresh")
+			return (<-done).Content[0].Text
+		}
+	}
+
+	if got := call("lookup", map[string]any{"q": "a", "n": 1}); got != "lookup fresh" {
+		t.Fatalf("first call = %q", got)
+	}
+	// Same arguments in a different order hit the cache
+	if got := call("lookup", map[string]any{"n": 1, "q": "a"}); got != "lookup fresh" {
+		t.Fatalf("cached call = %q", got)
+	}
+	if len(mockT.sendCh) != 0 {
+		t.Fatal("cached call reached the server")
+	}
+	// Tools without the read-only and idempotent hints are not cached
+	call("write", nil)
+	call("write", nil)
+
+	now = now.Add(2 * time.Minute)
+	call("lookup", map[string]any{"q": "a", "n": 1})
+	if _, ok := c.cache.get("lookup", map[string]any{"q": "a", "n": 1}); !ok {
+		t.Error("result not cached again after expiry")
+	}
+}
+
+func TestClient_ToolFilters(t *testing.T) {
+	c, mockT := startTestClient(t, "fs")
+	c.config.IncludeTools = []string{"read_*", "list"}
+	c.config.ExcludeTools = []string{"read_secret"}
+
+	done := make(chan error, 1)
+	go func() { done <- c.refreshTools(context.Background()) }()
+	req := nextRequest(t, mockT)
+	queueMessage(mockT, map[string]any{
+		"id": req.ID,
+		"result": map[string]any{"tools": []map[string]any{
+			{"name": "read_file"}, {"name": "read_secret"}, {"name": "list"}, {"name": "delete"},
+		}},
+	})
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	tools := c.Tools()
+	if len(tools) != 2 || tools[0].Name != "read_file" || tools[1].Name != "list" {
+		t.Errorf("filtered tools = %+v", tools)
+	}
+	if _, err := c.CallTool(context.Background(), "delete", nil); err == nil {
+		t.Error("excluded tool was called")
+	}
+}
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"mote/internal/mcp/protocol"
+	"mote/internal/mcp/transport"
+)
+
+// RequestHandler serves requests initiated by an MCP server: sampling,
+// elicitation and roots. Errors of type *protocol.RPCError are returned to the
+// server as-is; other errors are reported as internal errors.
+type RequestHandler interface {
+	// ClientCapabilities returns the capabilities declared to serverName during
+	// initialization.
+	ClientCapabilities(serverName string) protocol.Capabilities
+	// CreateMessage serves sampling/createMessage.
+	CreateMessage(ctx context.Context, serverName string, params protocol.CreateMessageParams) (*protocol.CreateMessageResult, error)
+	// Elicit serves elicitation/create.
+	Elicit(ctx context.Context, serverName string, params protocol.ElicitParams) (*protocol.ElicitResult, error)
+	// ListRoots serves roots/list.
+	ListRoots(ctx context.Context, serverName string) (*protocol.ListRootsResult, error)
+}
+
+type sessionKey struct{}
+
+// WithSessionID returns a context that attributes MCP calls to a Mote session.
+// Requests a server sends back while such a call is in flight carry the
+// session, so they can be approved in and scoped to the right session.
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+// SessionIDFromContext returns the session attached by WithSessionID.
+func SessionIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(sessionKey{}).(string)
+	return id, ok && id != ""
+}
+
+// SetRequestHandler sets the handler for server-initiated requests. It must be
+// set before Connect for the client to declare the matching capabilities.
+func (c *Client) SetRequestHandler(h RequestHandler) {
+	c.notifyMu.Lock()
+	defer c.notifyMu.Unlock()
+	c.requestHandler = h
+}
+
+func (c *Client) getRequestHandler() RequestHandler {
+	c.notifyMu.RLock()
+	defer c.notifyMu.RUnlock()
+	return c.requestHandler
+}
+
+// clientCapabilities returns the capabilities sent in the initialize request.
+func (c *Client) clientCapabilities() protocol.Capabilities {
+	if h := c.getRequestHandler(); h != nil {
+		return h.ClientCapabilities(c.name)
+	}
+	return protocol.Capabilities{}
+}
+
+// beginSession records that a call on behalf of sessionID is in flight and
+// returns a func that clears it.
+func (c *Client) beginSession(ctx context.Context) func() {
+	id, ok := SessionIDFromContext(ctx)
+	if !ok {
+		return func() {}
+	}
+	c.sessMu.Lock()
+	c.activeSessions = append(c.activeSessions, id)
+	c.sessMu.Unlock()
+	return func() {
+		c.sessMu.Lock()
+		defer c.sessMu.Unlock()
+		for i := len(c.activeSessions) - 1; i >= 0; i-- {
+			if c.activeSessions[i] == id {
+				c.activeSessions = append(c.activeSessions[:i], c.activeSessions[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// activeSession returns the session of the most recent in-flight call.
+func (c *Client) activeSession() string {
+	c.sessMu.Lock()
+	defer c.sessMu.Unlock()
+	if n := len(c.activeSessions); n > 0 {
+		return c.activeSessions[n-1]
+	}
+	return ""
+}
+
+// handleRequest serves a server-initiated request and sends the response.
+func (c *Client) handleRequest(msg *protocol.Message) {
+	ctx := c.ctx
+	if id := c.activeSession(); id != "" {
+		ctx = WithSessionID(ctx, id)
+	}
+
+	result, err := c.serveRequest(ctx, msg.Method, msg.Params)
+
+	var resp *protocol.Response
+	if err != nil {
+		var rpcErr *protocol.RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = protocol.NewInternalError(err.Error())
+		}
+		resp = protocol.NewErrorResponse(msg.ID, rpcErr)
+	} else {
+		resp, err = protocol.NewResponse(msg.ID, result)
+		if err != nil {
+			resp = protocol.NewErrorResponse(msg.ID, protocol.NewInternalError(err.Error()))
+		}
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	_ = c.transport.Send(ctx, data)
+}
+
+// serveRequest dispatches a server-initiated request to the request handler.
+func (c *Client) serveRequest(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	if method == protocol.MethodPing {
+		return struct{}{}, nil
+	}
+
+	h := c.getRequestHandler()
+	if h == nil {
+		return nil, protocol.NewMethodNotFoundError(method)
+	}
+	caps := h.ClientCapabilities(c.name)
+
+	switch method {
+	case protocol.MethodSamplingCreateMessage:
+		if caps.Sampling == nil {
+			break
+		}
+		var p protocol.CreateMessageParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, protocol.NewInvalidParamsError(err.Error())
+		}
+		if len(p.Messages) == 0 {
+			return nil, protocol.NewInvalidParamsError("messages is required")
+		}
+		return h.CreateMessage(ctx, c.name, p)
+	case protocol.MethodElicitationCreate:
+		if caps.Elicitation == nil {
+			break
+		}
+		var p protocol.ElicitParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, protocol.NewInvalidParamsError(err.Error())
+		}
+		if p.Message == "" {
+			return nil, protocol.NewInvalidParamsError("message is required")
+		}
+		return h.Elicit(ctx, c.name, p)
+	case protocol.MethodRootsList:
+		if caps.Roots == nil {
+			break
+		}
+		return h.ListRoots(ctx, c.name)
+	}
+	return nil, protocol.NewMethodNotFoundError(method)
+}
+
+// SetRequestHandler sets the handler for server-initiated requests on all
+// connected servers and servers connected later. Servers connected before the
+// handler was set keep the capabilities they were initialized with.
+func (m *Manager) SetRequestHandler(h RequestHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requestHandler = h
+	for _, c := range m.clients {
+		c.SetRequestHandler(h)
+	}
+}
+
+// SetAuthorizers sets the source of authorizers for Streamable HTTP servers
+// connected later whose config does not carry one.
+func (m *Manager) SetAuthorizers(f AuthorizerFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.authorizers = f
+}
+
+// SetEnvFunc sets the function that adds environment variables to stdio
+// servers each time they start, e.g. to route them through a proxy.
+func (m *Manager) SetEnvFunc(f EnvFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.envFunc = f
+}
+
+// newClient creates a client wired to the manager's handlers.
+func (m *Manager) newClient(name string, config ClientConfig) *Client {
+	m.mu.RLock()
+	requestHandler, authorizers, envFunc := m.requestHandler, m.authorizers, m.envFunc
+	m.mu.RUnlock()
+
+	if config.TransportType == transport.TransportStreamableHTTP && config.Authorizer == nil && authorizers != nil {
+		config.Authorizer = authorizers(name)
+	}
+	c := NewClient(name, config)
+	if config.TransportType == transport.TransportStdio && envFunc != nil {
+		c.extraEnv = func() []string { return envFunc(name) }
+	}
+	c.SetNotificationHandler(m.notificationHandler(name))
+	c.SetRequestHandler(requestHandler)
+	return c
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"mote/internal/mcp/protocol"
+	"mote/internal/mcp/transport"
+)
+
+type fakeRequestHandler struct {
+	caps protocol.Capabilities
+}
+
+func (f *fakeRequestHandler) ClientCapabilities(serverName string) protocol.Capabilities {
+	return f.caps
+}
+
+func (f *fakeRequestHandler) CreateMessage(ctx context.Context, serverName string, params protocol.CreateMessageParams) (*protocol.CreateMessageResult, error) {
+	return &protocol.CreateMessageResult{Role: "assistant", Content: protocol.NewTextContent("hi " + serverName), Model: "m"}, nil
+}
+
+func (f *fakeRequestHandler) Elicit(ctx context.Context, serverName string, params protocol.ElicitParams) (*protocol.ElicitResult, error) {
+	return &protocol.ElicitResult{Action: protocol.ElicitActionDecline}, nil
+}
+
+func (f *fakeRequestHandler) ListRoots(ctx context.Context, serverName string) (*protocol.ListRootsResult, error) {
+	sessionID, _ := SessionIDFromContext(ctx)
+	return &protocol.ListRootsResult{Roots: []protocol.Root{{URI: "file:///ws/" + sessionID}}}, nil
+}
+
+func startCallbackClient(t *testing.T, h RequestHandler) (*Client, *mockClientTransport) {
+	t.Helper()
+	mockT := newMockClientTransport()
+	c := &Client{
+		name:      "srv",
+		transport: mockT,
+		pending:   make(map[int64]chan *protocol.Response),
+		config:    ClientConfig{Timeout: 5 * time.Second},
+		state:     StateConnected,
+	}
+	c.SetRequestHandler(h)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctx = ctx
+	c.cancel = cancel
+	c.wg.Add(1)
+	go c.receiveLoop()
+	t.Cleanup(func() { c.Close() })
+	return c, mockT
+}
+
+func serverRequest(t *testing.T, mockT *mockClientTransport, method string, params any) *protocol.Response {
+	t.Helper()
+	req, err := protocol.NewRequestWithID("req-1", method, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(req)
+	mockT.QueueResponse(data)
+
+	sent, ok := mockT.GetSent()
+	if !ok {
+		t.Fatalf("%s: no response sent", method)
+	}
+	var resp protocol.Response
+	if err := json.Unmarshal(sent, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "req-1" {
+		t.Errorf("%s: response id = %v", method, resp.ID)
+	}
+	return &resp
+}
+
+func TestClient_ServerRequests(t *testing.T) {
+	h := &fakeRequestHandler{caps: protocol.Capabilities{
+		Sampling: &protocol.SamplingCapability{},
+		Roots:    &protocol.RootsCapability{},
+	}}
+	c, mockT := startCallbackClient(t, h)
+
+	done := c.beginSession(WithSessionID(context.Background(), "s1"))
+	defer done()
+
+	resp := serverRequest(t, mockT, protocol.MethodRootsList, nil)
+	var roots protocol.ListRootsResult
+	if resp.Error != nil || json.Unmarshal(resp.Result, &roots) != nil {
+		t.Fatalf("roots/list: %+v", resp)
+	}
+	if len(roots.Roots) != 1 || roots.Roots[0].URI != "file:///ws/s1" {
+		t.Errorf("roots/list should carry the active session, got %+v", roots.Roots)
+	}
+
+	resp = serverRequest(t, mockT, protocol.MethodSamplingCreateMessage, protocol.CreateMessageParams{
+		Messages:  []protocol.SamplingMessage{{Role: "user", Content: protocol.NewTextContent("hello")}},
+		MaxTokens: 10,
+	})
+	var msg protocol.CreateMessageResult
+	if resp.Error != nil || json.Unmarshal(resp.Result, &msg) != nil || msg.Content.Text != "hi srv" {
+		t.Errorf("sampling: %+v", resp)
+	}
+
+	// Elicitation was not declared, so it is not served.
+	resp = serverRequest(t, mockT, protocol.MethodElicitationCreate, protocol.ElicitParams{Message: "name?"})
+	if resp.Error == nil || resp.Error.Code != protocol.ErrCodeMethodNotFound {
+		t.Errorf("elicitation: expected method not found, got %+v", resp)
+	}
+}
+
+func TestClient_ServerRequestsWithoutHandler(t *testing.T) {
+	_, mockT := startCallbackClient(t, nil)
+
+	resp := serverRequest(t, mockT, protocol.MethodRootsList, nil)
+	if resp.Error == nil || resp.Error.Code != protocol.ErrCodeMethodNotFound {
+		t.Errorf("expected method not found, got %+v", resp)
+	}
+
+	resp = serverRequest(t, mockT, protocol.MethodPing, nil)
+	if resp.Error != nil {
+		t.Errorf("ping: %+v", resp.Error)
+	}
+}
+
+func TestClient_ActiveSession(t *testing.T) {
+	c := &Client{}
+	doneA := c.beginSession(WithSessionID(context.Background(), "a"))
+	doneB := c.beginSession(WithSessionID(context.Background(), "b"))
+	if got := c.activeSession(); got != "b" {
+		t.Errorf("active = %q, want b", got)
+	}
+	doneB()
+	if got := c.activeSession(); got != "a" {
+		t.Errorf("active = %q, want a", got)
+	}
+	doneA()
+	if got := c.activeSession(); got != "" {
+		t.Errorf("active = %q, want empty", got)
+	}
+	c.beginSession(context.Background())()
+}
+
+func TestManager_EnvFuncPerStart(t *testing.T) {
+	m := NewManager(nil)
+	starts := 0
+	m.SetEnvFunc(func(name string) []string {
+		starts++
+		return []string{fmt.Sprintf("HTTP_PROXY=http://token%d@127.0.0.1:1", starts)}
+	})
+	c := m.newClient("docs", ClientConfig{TransportType: transport.TransportStdio, Env: map[string]string{"A": "1"}})
+
+	first, second := c.stdioEnv(), c.stdioEnv()
+	if first["HTTP_PROXY"] == second["HTTP_PROXY"] || second["A"] != "1" {
+		t.Errorf("each start should get fresh extra env: %v then %v", first, second)
+	}
+	if _, baked := c.GetConfig().Env["HTTP_PROXY"]; baked {
+		t.Error("extra env should not be stored in the config")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -51,7 +52,8 @@ func (s ConnectionState) MarshalJSON() ([]byte, error) {
 
 // ClientConfig holds configuration for an MCP client.
 type ClientConfig struct {
-	// TransportType specifies the transport type ("stdio" or "http+sse").
+	// TransportType specifies the transport type ("stdio", "http+sse", "http" or
+	// "streamable-http").
 	TransportType transport.TransportType
 
 	// Command is the command to run for stdio transport.
@@ -67,9 +69,25 @@ type ClientConfig struct {
 	URL string
 	// Headers are HTTP headers for HTTP+SSE transport (e.g., Authorization).
 	Headers map[string]string
+	// Authorizer supplies credentials for the Streamable HTTP transport,
+	// e.g. OAuth tokens that are refreshed on demand.
+	Authorizer transport.Authorizer
 
 	// Timeout is the connection timeout.
 	Timeout time.Duration
+	// CallTimeout bounds a single tools/call; zero uses Timeout.
+	CallTimeout time.Duration
+
+	// IncludeTools limits the server's tools to names matching these globs;
+	// empty includes all. ExcludeTools hides matching tools after that.
+	IncludeTools []string
+	ExcludeTools []string
+
+	// CacheTTL enables caching of tool results for this long.
+	CacheTTL time.Duration
+	// CacheTools lists the tools (globs) whose results may be cached. When
+	// empty, tools the server annotates as read-only and idempotent are cached.
+	CacheTools []string
 }
 
 // Client is an MCP client that connects to external MCP servers.
@@ -77,10 +95,29 @@ type Client struct {
 	name   string
 	config ClientConfig
 
-	transport  transport.Transport
-	serverInfo protocol.ServerInfo
-	tools      []protocol.Tool
-	prompts    []protocol.Prompt
+	transport       transport.Transport
+	serverInfo      protocol.ServerInfo
+	protocolVersion string
+	tools           []protocol.Tool
+	prompts         []protocol.Prompt
+	listMu          sync.RWMutex
+
+	capabilities protocol.Capabilities
+	resources    []protocol.Resource
+	templates    []protocol.ResourceTemplate
+	resMu        sync.RWMutex
+
+	notifyHandler  NotificationHandler
+	requestHandler RequestHandler
+	notifyMu       sync.RWMutex
+
+	progress   map[string]ProgressHandler
+	progressMu sync.Mutex
+
+	cache *toolCache
+
+	activeSessions []string
+	sessMu         sync.Mutex
 
 	pending   map[int64]chan *protocol.Response
 	pendingMu sync.Mutex
@@ -90,6 +127,10 @@ type Client struct {
 	stateMu sync.RWMutex
 	lastErr error
 
+	// extraEnv adds environment variables to a stdio server each time it
+	// starts, so restarts get fresh values.
+	extraEnv func() []string
+
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -100,12 +141,16 @@ func NewClient(name string, config ClientConfig) *Client {
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
 	}
-	return &Client{
+	c := &Client{
 		name:    name,
 		config:  config,
 		pending: make(map[int64]chan *protocol.Response),
 		state:   StateDisconnected,
 	}
+	if config.CacheTTL > 0 {
+		c.cache = newToolCache(config.CacheTTL)
+	}
+	return c
 }
 
 // Name returns the client name.
@@ -132,13 +177,22 @@ func (c *Client) ServerInfo() protocol.ServerInfo {
 	return c.serverInfo
 }
 
+// ProtocolVersion returns the protocol version negotiated with the server.
+func (c *Client) ProtocolVersion() string {
+	return c.protocolVersion
+}
+
 // Tools returns the list of tools available from the server.
 func (c *Client) Tools() []protocol.Tool {
+	c.listMu.RLock()
+	defer c.listMu.RUnlock()
 	return c.tools
 }
 
 // Prompts returns the list of prompts available from the server.
 func (c *Client) Prompts() []protocol.Prompt {
+	c.listMu.RLock()
+	defer c.listMu.RUnlock()
 	return c.prompts
 }
 
@@ -152,6 +206,27 @@ func (c *Client) TransportType() string {
 	return string(c.config.TransportType)
 }
 
+// stdioEnv returns the configured environment plus extraEnv.
+func (c *Client) stdioEnv() map[string]string {
+	if c.extraEnv == nil {
+		return c.config.Env
+	}
+	extra := c.extraEnv()
+	if len(extra) == 0 {
+		return c.config.Env
+	}
+	env := make(map[string]string, len(c.config.Env)+len(extra))
+	for k, v := range c.config.Env {
+		env[k] = v
+	}
+	for _, kv := range extra {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	return env
+}
+
 func (c *Client) setState(state ConnectionState, err error) {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
@@ -170,7 +245,7 @@ func (c *Client) Connect(ctx context.Context) error {
 		stdioT := transport.NewStdioClientTransport(
 			c.config.Command,
 			c.config.Args,
-			transport.WithEnv(c.config.Env),
+			transport.WithEnv(c.stdioEnv()),
 			transport.WithWorkDir(c.config.WorkDir),
 		)
 		if err := stdioT.Start(); err != nil {
@@ -192,6 +267,17 @@ func (c *Client) Connect(ctx context.Context) error {
 			return fmt.Errorf("start HTTP transport: %w", err)
 		}
 		t = httpT
+	case transport.TransportStreamableHTTP:
+		var opts []transport.StreamableOption
+		if c.config.Authorizer != nil {
+			opts = append(opts, transport.WithAuthorizer(c.config.Authorizer))
+		}
+		httpT := transport.NewStreamableHTTPClientTransport(c.config.URL, c.config.Headers, opts...)
+		if err := httpT.Start(); err != nil {
+			c.setState(StateError, err)
+			return fmt.Errorf("start HTTP transport: %w", err)
+		}
+		t = httpT
 	default:
 		err := fmt.Errorf("unknown transport type: %s", c.config.TransportType)
 		c.setState(StateError, err)
@@ -222,6 +308,11 @@ func (c *Client) Connect(ctx context.Context) error {
 	// List available prompts (optional - ignore errors for servers that don't support prompts)
 	_ = c.refreshPrompts(ctx)
 
+	// List available resources (optional - only for servers that declare the capability)
+	if c.capabilities.Resources != nil {
+		_ = c.refreshResources(ctx)
+	}
+
 	c.setState(StateConnected, nil)
 	return nil
 }
@@ -229,12 +320,12 @@ func (c *Client) Connect(ctx context.Context) error {
 // initialize performs the MCP initialization handshake.
 func (c *Client) initialize(ctx context.Context) error {
 	params := protocol.InitializeParams{
-		ProtocolVersion: protocol.ProtocolVersion,
+		ProtocolVersion: protocol.LatestProtocolVersion,
 		ClientInfo: protocol.ClientInfo{
 			Name:    c.name,
 			Version: "1.0.0",
 		},
-		Capabilities: protocol.Capabilities{},
+		Capabilities: c.clientCapabilities(),
 	}
 
 	var result protocol.InitializeResult
@@ -242,12 +333,18 @@ func (c *Client) initialize(ctx context.Context) error {
 		return err
 	}
 
-	// Allow newer protocol versions - MCP is backward compatible
-	// We only reject if the server version is older than what we support
-	// For now, just log and continue with any version
-	_ = result.ProtocolVersion // Server version (may be newer)
+	// The server answers with the requested version or the one it prefers;
+	// a version we do not speak ends the handshake.
+	if !protocol.IsSupportedVersion(result.ProtocolVersion) {
+		return fmt.Errorf("unsupported protocol version %q", result.ProtocolVersion)
+	}
+	c.protocolVersion = result.ProtocolVersion
+	if vt, ok := c.transport.(interface{ SetProtocolVersion(string) }); ok {
+		vt.SetProtocolVersion(result.ProtocolVersion)
+	}
 
 	c.serverInfo = result.ServerInfo
+	c.capabilities = result.Capabilities
 
 	// Send initialized notification
 	notif, err := protocol.NewNotification(protocol.MethodInitialized, nil)
@@ -271,28 +368,47 @@ func (c *Client) refreshTools(ctx context.Context) error {
 	if err := c.call(ctx, protocol.MethodToolsList, nil, &result); err != nil {
 		return err
 	}
-	c.tools = result.Tools
+	tools := make([]protocol.Tool, 0, len(result.Tools))
+	for _, t := range result.Tools {
+		if c.toolAllowed(t.Name) {
+			tools = append(tools, t)
+		}
+	}
+	c.listMu.Lock()
+	c.tools = tools
+	c.listMu.Unlock()
+	c.cache.clear()
 	return nil
 }
 
+// toolAllowed reports whether the include/exclude lists let name through.
+func (c *Client) toolAllowed(name string) bool {
+	if len(c.config.IncludeTools) > 0 && !matchAny(c.config.IncludeTools, name) {
+		return false
+	}
+	return !matchAny(c.config.ExcludeTools, name)
+}
+
 // refreshPrompts retrieves the list of available prompts from the server.
 func (c *Client) refreshPrompts(ctx context.Context) error {
 	var result protocol.ListPromptsResult
 	if err := c.call(ctx, protocol.MethodPromptsList, nil, &result); err != nil {
 		return err
 	}
+	c.listMu.Lock()
 	c.prompts = result.Prompts
+	c.listMu.Unlock()
 	return nil
 }
 
 // ListTools returns the cached list of tools.
 func (c *Client) ListTools() []protocol.Tool {
-	return c.tools
+	return c.Tools()
 }
 
 // ListPrompts returns the cached list of prompts.
 func (c *Client) ListPrompts() []protocol.Prompt {
-	return c.prompts
+	return c.Prompts()
 }
 
 // GetPrompt retrieves a specific prompt with arguments.
@@ -312,21 +428,52 @@ func (c *Client) GetPrompt(ctx context.Context, name string, args map[string]str
 
 // CallTool calls a tool on the remote MCP server.
 func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (*protocol.CallToolResult, error) {
+	if !c.toolAllowed(name) {
+		return nil, fmt.Errorf("tool %s is not allowed on server %s", name, c.name)
+	}
+	cacheable := c.cacheable(name)
+	if cacheable {
+		if result, ok := c.cache.get(name, args); ok {
+			return result, nil
+		}
+	}
+
 	params := protocol.CallToolParams{
 		Name:      name,
 		Arguments: args,
 	}
+	if h := progressHandlerFromContext(ctx); h != nil {
+		token, release := c.trackProgress(h)
+		defer release()
+		params.Meta = &protocol.RequestMeta{ProgressToken: token}
+	}
+
+	done := c.beginSession(ctx)
+	defer done()
+
+	timeout := c.config.Timeout
+	if c.config.CallTimeout > 0 {
+		timeout = c.config.CallTimeout
+	}
 
 	var result protocol.CallToolResult
-	if err := c.call(ctx, protocol.MethodToolsCall, params, &result); err != nil {
+	if err := c.callWithTimeout(ctx, timeout, protocol.MethodToolsCall, params, &result); err != nil {
 		return nil, err
 	}
 
+	if cacheable && !result.IsError {
+		c.cache.put(name, args, &result)
+	}
 	return &result, nil
 }
 
 // call sends a request and waits for the response.
 func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	return c.callWithTimeout(ctx, c.config.Timeout, method, params, result)
+}
+
+// callWithTimeout is call with an explicit response timeout.
+func (c *Client) callWithTimeout(ctx context.Context, timeout time.Duration, method string, params, result any) error {
 	id := atomic.AddInt64(&c.nextID, 1)
 
 	// Create request
@@ -363,7 +510,7 @@ func (c *Client) call(ctx context.Context, method string, params, result any) er
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case <-time.After(c.config.Timeout):
+	case <-time.After(timeout):
 		return errors.New("request timeout")
 	case resp := <-respCh:
 		if resp.Error != nil {
@@ -407,8 +554,13 @@ func (c *Client) receiveLoop() {
 		// Handle response
 		if msg.IsResponse() {
 			c.handleResponse(msg)
+		} else if msg.IsNotification() {
+			c.handleNotification(msg)
+		} else if msg.IsRequest() {
+			// Served concurrently: sampling and elicitation may wait on the
+			// user, and the pending tool call's response must still get through.
+			go c.handleRequest(msg)
 		}
-		// Ignore notifications and other messages for now
 	}
 }
 
@@ -593,7 +745,7 @@ func (c *Client) GetStateInfo() ClientStateInfo {
 	info := ClientStateInfo{
 		Name:       c.name,
 		Status:     c.state,
-		ToolsCount: len(c.tools),
+		ToolsCount: len(c.Tools()),
 	}
 	if c.lastErr != nil {
 		info.LastError = c.lastErr.Error()
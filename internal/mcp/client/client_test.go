@@ -118,6 +118,9 @@ func (t *mockClientTransport) Receive(ctx context.Context) ([]byte, error) {
 func (t *mockClientTransport) Close() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	if t.closed {
+		return nil
+	}
 	t.closed = true
 	close(t.receiveCh)
 	return nil
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"mote/internal/mcp/protocol"
+	"mote/internal/mcp/transport"
 )
 
 // Manager manages multiple MCP client connections.
@@ -17,8 +18,23 @@ type Manager struct {
 	mu      sync.RWMutex
 	ctx     context.Context
 	cancel  context.CancelFunc
+
+	onResourceUpdated ResourceUpdateHandler
+	onListChanged     []ListChangedHandler
+	requestHandler    RequestHandler
+	authorizers       AuthorizerFunc
+	envFunc           EnvFunc
+
+	health   map[string]*serverHealth
+	healthMu sync.Mutex
 }
 
+// AuthorizerFunc returns the authorizer for the named HTTP server, or nil.
+type AuthorizerFunc func(serverName string) transport.Authorizer
+
+// EnvFunc returns extra environment variables for the named stdio server.
+type EnvFunc func(serverName string) []string
+
 // ServerStatus represents the status of a connected MCP server.
 type ServerStatus struct {
 	Name          string          `json:"name"`
@@ -26,8 +42,10 @@ type ServerStatus struct {
 	TransportType string          `json:"transport_type"`
 	ToolCount     int             `json:"tool_count"`
 	PromptCount   int             `json:"prompt_count"`
+	ResourceCount int             `json:"resource_count"`
 	LastError     string          `json:"last_error,omitempty"`
 	ConnectedAt   *time.Time      `json:"connected_at,omitempty"`
+	Health        *ServerHealth   `json:"health,omitempty"`
 }
 
 // NewManager creates a new MCP client manager.
@@ -51,7 +69,7 @@ func (m *Manager) Start(ctx context.Context) error {
 		go func(cfg ClientConfig) {
 			defer wg.Done()
 
-			client := NewClient(cfg.Command, cfg) // Use command as name if not specified
+			client := m.newClient(cfg.Command, cfg) // Use command as name if not specified
 			if err := client.Connect(ctx); err != nil {
 				errCh <- fmt.Errorf("connect to %s: %w", cfg.Command, err)
 				return
@@ -112,7 +130,7 @@ func (m *Manager) Connect(ctx context.Context, config ClientConfig) error {
 	}
 	m.mu.Unlock()
 
-	client := NewClient(name, config)
+	client := m.newClient(name, config)
 	if err := client.Connect(ctx); err != nil {
 		return fmt.Errorf("connect to %s: %w", name, err)
 	}
@@ -120,6 +138,7 @@ func (m *Manager) Connect(ctx context.Context, config ClientConfig) error {
 	m.mu.Lock()
 	m.clients[name] = client
 	m.mu.Unlock()
+	m.resetHealth(name)
 
 	return nil
 }
@@ -134,6 +153,7 @@ func (m *Manager) Disconnect(name string) error {
 	}
 	delete(m.clients, name)
 	m.mu.Unlock()
+	m.resetHealth(name)
 
 	return client.Close()
 }
@@ -159,6 +179,8 @@ func (m *Manager) ListServers() []ServerStatus {
 			TransportType: client.TransportType(),
 			ToolCount:     len(client.Tools()),
 			PromptCount:   len(client.Prompts()),
+			ResourceCount: len(client.Resources()),
+			Health:        m.Health(name),
 		}
 		if err := client.LastError(); err != nil {
 			status.LastError = err.Error()
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"mote/internal/mcp/protocol"
+)
+
+// ProgressUpdate is a progress or log notification received while a tool
+// call is in flight.
+type ProgressUpdate struct {
+	// Progress and Total come from notifications/progress; Total is 0 when unknown.
+	Progress float64
+	Total    float64
+	Message  string
+	// Level is set for log messages (notifications/message) instead of progress.
+	Level string
+}
+
+// String renders the update as a single human-readable line.
+func (u ProgressUpdate) String() string {
+	if u.Level != "" {
+		return fmt.Sprintf("[%s] %s", u.Level, u.Message)
+	}
+	progress := strconv.FormatFloat(u.Progress, 'f', -1, 64)
+	if u.Total > 0 {
+		progress += "/" + strconv.FormatFloat(u.Total, 'f', -1, 64)
+	}
+	if u.Message == "" {
+		return progress
+	}
+	return fmt.Sprintf("%s (%s)", u.Message, progress)
+}
+
+// ProgressHandler receives updates for an in-flight tool call. It is called
+// from the client's receive loop and must not block.
+type ProgressHandler func(update ProgressUpdate)
+
+type progressKey struct{}
+
+var progressTokens int64
+
+// WithProgressHandler returns a context whose tool calls request progress
+// notifications from the server and deliver them, together with log messages
+// the server emits meanwhile, to h.
+func WithProgressHandler(ctx context.Context, h ProgressHandler) context.Context {
+	return context.WithValue(ctx, progressKey{}, h)
+}
+
+func progressHandlerFromContext(ctx context.Context) ProgressHandler {
+	h, _ := ctx.Value(progressKey{}).(ProgressHandler)
+	return h
+}
+
+// trackProgress registers h under a fresh progress token until release is called.
+func (c *Client) trackProgress(h ProgressHandler) (token string, release func()) {
+	token = fmt.Sprintf("%s-%d", c.name, atomic.AddInt64(&progressTokens, 1))
+
+	c.progressMu.Lock()
+	if c.progress == nil {
+		c.progress = make(map[string]ProgressHandler)
+	}
+	c.progress[token] = h
+	c.progressMu.Unlock()
+
+	return token, func() {
+		c.progressMu.Lock()
+		delete(c.progress, token)
+		c.progressMu.Unlock()
+	}
+}
+
+// handleProgress routes a notifications/progress to the call owning its token.
+func (c *Client) handleProgress(params json.RawMessage) {
+	var p protocol.ProgressParams
+	if err := json.Unmarshal(params, &p); err != nil || p.ProgressToken == nil {
+		return
+	}
+	// Tokens may come back as numbers or strings; ours are always strings
+	token := fmt.Sprint(p.ProgressToken)
+
+	c.progressMu.Lock()
+	h := c.progress[token]
+	c.progressMu.Unlock()
+	if h != nil {
+		h(ProgressUpdate{Progress: p.Progress, Total: p.Total, Message: p.Message})
+	}
+}
+
+// handleLogMessage delivers a notifications/message to every in-flight call
+// that asked for progress, since log messages are not tied to a request.
+func (c *Client) handleLogMessage(params json.RawMessage) {
+	var p protocol.LoggingMessageParams
+	if err := json.Unmarshal(params, &p); err != nil || p.Level == "" {
+		return
+	}
+	var text string
+	if err := json.Unmarshal(p.Data, &text); err != nil {
+		text = string(p.Data)
+	}
+	if p.Logger != "" {
+		text = p.Logger + ": " + text
+	}
+
+	c.progressMu.Lock()
+	handlers := make([]ProgressHandler, 0, len(c.progress))
+	for _, h := range c.progress {
+		handlers = append(handlers, h)
+	}
+	c.progressMu.Unlock()
+	for _, h := range handlers {
+		h(ProgressUpdate{Level: p.Level, Message: text})
+	}
+}
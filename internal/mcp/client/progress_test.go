@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"mote/internal/mcp/protocol"
+)
+
+// startTestClient returns a connected client reading from a mock transport.
+func startTestClient(t *testing.T, name string) (*Client, *mockClientTransport) {
+	t.Helper()
+	mockT := newMockClientTransport()
+	c := &Client{
+		name:      name,
+		transport: mockT,
+		pending:   make(map[int64]chan *protocol.Response),
+		config:    ClientConfig{Timeout: 5 * time.Second},
+		state:     StateConnected,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctx = ctx
+	c.cancel = cancel
+	c.wg.Add(1)
+	go c.receiveLoop()
+	t.Cleanup(func() { _ = c.Close() })
+	return c, mockT
+}
+
+func nextRequest(t *testing.T, mockT *mockClientTransport) protocol.Request {
+	t.Helper()
+	select {
+	case data := <-mockT.sendCh:
+		var req protocol.Request
+		if err := json.Unmarshal(data, &req); err != nil {
+			t.Fatalf("invalid request: %v", err)
+		}
+		return req
+	case <-time.After(time.Second):
+		t.Fatal("no request sent")
+		return protocol.Request{}
+	}
+}
+
+func queueMessage(mockT *mockClientTransport, msg map[string]any) {
+	msg["jsonrpc"] = "2.0"
+	data, _ := json.Marshal(msg)
+	mockT.QueueResponse(data)
+}
+
+func TestClient_ToolsListChanged(t *testing.T) {
+	c, mockT := startTestClient(t, "fs")
+	c.tools = []protocol.Tool{{Name: "read"}}
+
+	manager := NewManager(nil)
+	manager.mu.Lock()
+	manager.clients["fs"] = c
+	manager.mu.Unlock()
+	c.SetNotificationHandler(manager.notificationHandler("fs"))
+
+	changed := make(chan string, 1)
+	manager.AddListChangedHandler(func(serverName string) {
+		changed <- serverName
+	})
+
+	queueMessage(mockT, map[string]any{"method": protocol.MethodToolsListChanged})
+
+	req := nextRequest(t, mockT)
+	if req.Method != protocol.MethodToolsList {
+		t.Fatalf("expected tools/list, got %s", req.Method)
+	}
+	queueMessage(mockT, map[string]any{
+		"id":     req.ID,
+		"result": map[string]any{"tools": []map[string]any{{"name": "read"}, {"name": "write"}}},
+	})
+
+	select {
+	case got := <-changed:
+		if got != "fs" {
+			t.Errorf("unexpected server: %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("list changed handler not called")
+	}
+	if tools := c.Tools(); len(tools) != 2 || tools[1].Name != "write" {
+		t.Errorf("tools not refreshed: %+v", tools)
+	}
+}
+
+func TestClient_CallToolProgress(t *testing.T) {
+	c, mockT := startTestClient(t, "build")
+
+	var mu sync.Mutex
+	var updates []string
+	ctx := WithProgressHandler(context.Background(), func(u ProgressUpdate) {
+		mu.Lock()
+		updates = append(updates, u.String())
+		mu.Unlock()
+	})
+
+	type callResult struct {
+		res *protocol.CallToolResult
+		err error
+	}
+	done := make(chan callResult, 1)
+	go func() {
+		res, err := c.CallTool(ctx, "compile", nil)
+		done <- callResult{res, err}
+	}()
+
+	req := nextRequest(t, mockT)
+	var params protocol.CallToolParams
+	_ = json.Unmarshal(req.Params, &params)
+	if params.Meta == nil || params.Meta.ProgressToken == nil {
+		t.Fatalf("progress token not requested: %s", req.Params)
+	}
+	token := params.Meta.ProgressToken
+
+	queueMessage(mockT, map[string]any{
+		"method": protocol.MethodProgress,
+		"params": map[string]any{"progressToken": "other", "progress": 1},
+	})
+	queueMessage(mockT, map[string]any{
+		"method": protocol.MethodProgress,
+		"params": map[string]any{"progressToken": token, "progress": 3, "total": 10, "message": "compiling"},
+	})
+	queueMessage(mockT, map[string]any{
+		"method": protocol.MethodLoggingMessage,
+		"params": map[string]any{"level": "warning", "logger": "cc", "data": "unused variable"},
+	})
+	queueMessage(mockT, map[string]any{
+		"id":     req.ID,
+		"result": map[string]any{"content": []map[string]any{{"type": "text", "text": "ok"}}},
+	})
+
+	select {
+	case r := <-done:
+		if r.err != nil || len(r.res.Content) != 1 {
+			t.Fatalf("CallTool = %+v, %v", r.res, r.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("CallTool did not return")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"compiling (3/10)", "[warning] cc: unused variable"}
+	if len(updates) != len(want) || updates[0] != want[0] || updates[1] != want[1] {
+		t.Errorf("updates = %q, want %q", updates, want)
+	}
+	if len(c.progress) != 0 {
+		t.Errorf("progress token not released")
+	}
+}
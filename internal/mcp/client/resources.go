@@ -0,0 +1,345 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"mote/internal/mcp/protocol"
+)
+
+// NotificationHandler receives notifications sent by an MCP server.
+type NotificationHandler func(method string, params json.RawMessage)
+
+// ResourceUpdateHandler is called when a subscribed resource changes on a server.
+type ResourceUpdateHandler func(serverName, uri string)
+
+// ListChangedHandler is called when the tools or prompts of a server changed.
+type ListChangedHandler func(serverName string)
+
+// SetNotificationHandler sets the callback for server notifications.
+func (c *Client) SetNotificationHandler(h NotificationHandler) {
+	c.notifyMu.Lock()
+	defer c.notifyMu.Unlock()
+	c.notifyHandler = h
+}
+
+// Capabilities returns the capabilities declared by the server.
+func (c *Client) Capabilities() protocol.Capabilities {
+	return c.capabilities
+}
+
+// SupportsResources reports whether the server declared the resources capability.
+func (c *Client) SupportsResources() bool {
+	return c.capabilities.Resources != nil
+}
+
+// SupportsSubscribe reports whether the server supports resource subscriptions.
+func (c *Client) SupportsSubscribe() bool {
+	return c.capabilities.Resources != nil && c.capabilities.Resources.Subscribe
+}
+
+// Resources returns the cached list of resources.
+func (c *Client) Resources() []protocol.Resource {
+	c.resMu.RLock()
+	defer c.resMu.RUnlock()
+	return c.resources
+}
+
+// ResourceTemplates returns the cached list of resource templates.
+func (c *Client) ResourceTemplates() []protocol.ResourceTemplate {
+	c.resMu.RLock()
+	defer c.resMu.RUnlock()
+	return c.templates
+}
+
+// refreshResources retrieves resources and resource templates from the server,
+// following pagination cursors.
+func (c *Client) refreshResources(ctx context.Context) error {
+	var resources []protocol.Resource
+	cursor := ""
+	for {
+		var result protocol.ListResourcesResult
+		if err := c.call(ctx, protocol.MethodResourcesList, protocol.ListResourcesParams{Cursor: cursor}, &result); err != nil {
+			return err
+		}
+		resources = append(resources, result.Resources...)
+		if result.NextCursor == nil || *result.NextCursor == "" {
+			break
+		}
+		cursor = *result.NextCursor
+	}
+
+	// Templates are optional - many servers only list concrete resources
+	var templates protocol.ListResourceTemplatesResult
+	_ = c.call(ctx, protocol.MethodResourcesTemplatesList, nil, &templates)
+
+	c.resMu.Lock()
+	c.resources = resources
+	c.templates = templates.ResourceTemplates
+	c.resMu.Unlock()
+	return nil
+}
+
+// ReadResource reads a resource from the server.
+func (c *Client) ReadResource(ctx context.Context, uri string) (*protocol.ReadResourceResult, error) {
+	var result protocol.ReadResourceResult
+	if err := c.call(ctx, protocol.MethodResourcesRead, protocol.ReadResourceParams{URI: uri}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Subscribe asks the server to send notifications when uri changes.
+func (c *Client) Subscribe(ctx context.Context, uri string) error {
+	if !c.SupportsSubscribe() {
+		return fmt.Errorf("server %s does not support resource subscriptions", c.name)
+	}
+	return c.call(ctx, protocol.MethodResourcesSubscribe, protocol.SubscribeParams{URI: uri}, nil)
+}
+
+// Unsubscribe cancels a resource subscription.
+func (c *Client) Unsubscribe(ctx context.Context, uri string) error {
+	if !c.SupportsSubscribe() {
+		return fmt.Errorf("server %s does not support resource subscriptions", c.name)
+	}
+	return c.call(ctx, protocol.MethodResourcesUnsubscribe, protocol.SubscribeParams{URI: uri}, nil)
+}
+
+// handleNotification refreshes cached state and forwards the notification.
+func (c *Client) handleNotification(msg *protocol.Message) {
+	switch msg.Method {
+	case protocol.MethodResourcesListChanged:
+		// Must not call the server from the receive loop, which delivers the response
+		go func() {
+			ctx, cancel := context.WithTimeout(c.ctx, c.config.Timeout)
+			defer cancel()
+			_ = c.refreshResources(ctx)
+		}()
+	case protocol.MethodToolsListChanged, protocol.MethodPromptsListChanged:
+		// Forwarded once the cache is fresh, so handlers see the new list
+		go func() {
+			ctx, cancel := context.WithTimeout(c.ctx, c.config.Timeout)
+			defer cancel()
+			refresh := c.refreshTools
+			if msg.Method == protocol.MethodPromptsListChanged {
+				refresh = c.refreshPrompts
+			}
+			if err := refresh(ctx); err != nil {
+				return
+			}
+			c.forwardNotification(msg)
+		}()
+		return
+	case protocol.MethodProgress:
+		c.handleProgress(msg.Params)
+	case protocol.MethodLoggingMessage:
+		c.handleLogMessage(msg.Params)
+	}
+
+	c.forwardNotification(msg)
+}
+
+// forwardNotification passes a notification to the notification handler.
+func (c *Client) forwardNotification(msg *protocol.Message) {
+	c.notifyMu.RLock()
+	h := c.notifyHandler
+	c.notifyMu.RUnlock()
+	if h != nil {
+		h(msg.Method, msg.Params)
+	}
+}
+
+// MCPResource represents a resource from an MCP server with server info.
+type MCPResource struct {
+	ServerName  string `json:"server_name"`
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+}
+
+// MCPResourceTemplate represents a resource template from an MCP server with server info.
+type MCPResourceTemplate struct {
+	ServerName  string `json:"server_name"`
+	URITemplate string `json:"uri_template"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+}
+
+// SetResourceUpdateHandler sets the callback for resource update notifications
+// from any connected server, including servers connected later.
+func (m *Manager) SetResourceUpdateHandler(h ResourceUpdateHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onResourceUpdated = h
+	for name, c := range m.clients {
+		c.SetNotificationHandler(m.notificationHandler(name))
+	}
+}
+
+// AddListChangedHandler registers a callback run after a server's tool or
+// prompt list changed and the cached list was refreshed.
+func (m *Manager) AddListChangedHandler(h ListChangedHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onListChanged = append(m.onListChanged, h)
+}
+
+// notifyListChanged calls the list changed handlers for the named server.
+func (m *Manager) notifyListChanged(serverName string) {
+	m.mu.RLock()
+	handlers := append([]ListChangedHandler(nil), m.onListChanged...)
+	m.mu.RUnlock()
+	for _, h := range handlers {
+		h(serverName)
+	}
+}
+
+// notificationHandler returns the notification callback for the named server.
+func (m *Manager) notificationHandler(serverName string) NotificationHandler {
+	return func(method string, params json.RawMessage) {
+		if method == protocol.MethodToolsListChanged || method == protocol.MethodPromptsListChanged {
+			m.notifyListChanged(serverName)
+			return
+		}
+		if method != protocol.MethodResourcesUpdated {
+			return
+		}
+		var p protocol.ResourceUpdatedParams
+		if err := json.Unmarshal(params, &p); err != nil || p.URI == "" {
+			return
+		}
+		m.mu.RLock()
+		h := m.onResourceUpdated
+		m.mu.RUnlock()
+		if h != nil {
+			h(serverName, p.URI)
+		}
+	}
+}
+
+// GetAllResources returns all resources from all connected servers.
+func (m *Manager) GetAllResources() []MCPResource {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var all []MCPResource
+	for serverName, c := range m.clients {
+		if c.State() != StateConnected {
+			continue
+		}
+		for _, r := range c.Resources() {
+			all = append(all, MCPResource{
+				ServerName:  serverName,
+				URI:         r.URI,
+				Name:        r.Name,
+				Description: r.Description,
+				MimeType:    r.MimeType,
+			})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].ServerName != all[j].ServerName {
+			return all[i].ServerName < all[j].ServerName
+		}
+		return all[i].URI < all[j].URI
+	})
+	return all
+}
+
+// GetAllResourceTemplates returns all resource templates from all connected servers.
+func (m *Manager) GetAllResourceTemplates() []MCPResourceTemplate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var all []MCPResourceTemplate
+	for serverName, c := range m.clients {
+		if c.State() != StateConnected {
+			continue
+		}
+		for _, t := range c.ResourceTemplates() {
+			all = append(all, MCPResourceTemplate{
+				ServerName:  serverName,
+				URITemplate: t.URITemplate,
+				Name:        t.Name,
+				Description: t.Description,
+				MimeType:    t.MimeType,
+			})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].ServerName != all[j].ServerName {
+			return all[i].ServerName < all[j].ServerName
+		}
+		return all[i].URITemplate < all[j].URITemplate
+	})
+	return all
+}
+
+// ReadResource reads a resource from an MCP server.
+func (m *Manager) ReadResource(ctx context.Context, serverName, uri string) (*protocol.ReadResourceResult, error) {
+	c, ok := m.GetClient(serverName)
+	if !ok {
+		return nil, fmt.Errorf("server %s not found", serverName)
+	}
+	return c.ReadResource(ctx, uri)
+}
+
+// Subscribe subscribes to updates of a resource on an MCP server.
+func (m *Manager) Subscribe(ctx context.Context, serverName, uri string) error {
+	c, ok := m.GetClient(serverName)
+	if !ok {
+		return fmt.Errorf("server %s not found", serverName)
+	}
+	return c.Subscribe(ctx, uri)
+}
+
+// Unsubscribe cancels a resource subscription on an MCP server.
+func (m *Manager) Unsubscribe(ctx context.Context, serverName, uri string) error {
+	c, ok := m.GetClient(serverName)
+	if !ok {
+		return fmt.Errorf("server %s not found", serverName)
+	}
+	return c.Unsubscribe(ctx, uri)
+}
+
+// ResourceMention is an "@server:uri" reference in a chat message.
+type ResourceMention struct {
+	Server string
+	URI    string
+}
+
+// mentionPattern matches "@server:scheme:rest", e.g. "@fs:file:///etc/hosts".
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([A-Za-z0-9][A-Za-z0-9_.-]*):([A-Za-z][A-Za-z0-9+.-]*:[^\s]+)`)
+
+// ParseResourceMentions extracts unique "@server:uri" references from text.
+// Trailing punctuation is not considered part of the URI.
+func ParseResourceMentions(text string) []ResourceMention {
+	var mentions []ResourceMention
+	seen := make(map[ResourceMention]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		mention := ResourceMention{Server: m[1], URI: strings.TrimRight(m[2], ".,;:!?)]}\"'")}
+		if seen[mention] {
+			continue
+		}
+		seen[mention] = true
+		mentions = append(mentions, mention)
+	}
+	return mentions
+}
+
+// ResolveMentions returns the mentions in text that refer to connected servers.
+func (m *Manager) ResolveMentions(text string) []ResourceMention {
+	var out []ResourceMention
+	for _, mention := range ParseResourceMentions(text) {
+		if c, ok := m.GetClient(mention.Server); ok && c.State() == StateConnected {
+			out = append(out, mention)
+		}
+	}
+	return out
+}
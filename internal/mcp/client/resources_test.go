@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"mote/internal/mcp/protocol"
+)
+
+func TestParseResourceMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want []ResourceMention
+	}{
+		{"no mentions here", nil},
+		{"email me at a@b.com", nil},
+		{"summarise @fs:file:///tmp/a.md please", []ResourceMention{{Server: "fs", URI: "file:///tmp/a.md"}}},
+		{"@docs:memory://entries/42, and @fs:file:///x.txt.", []ResourceMention{
+			{Server: "docs", URI: "memory://entries/42"},
+			{Server: "fs", URI: "file:///x.txt"},
+		}},
+		{"@fs:file:///a @fs:file:///a", []ResourceMention{{Server: "fs", URI: "file:///a"}}},
+	}
+
+	for _, tt := range tests {
+		got := ParseResourceMentions(tt.text)
+		if len(got) != len(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.text, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q: got %v, want %v", tt.text, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestManager_Resources(t *testing.T) {
+	manager := NewManager(nil)
+
+	manager.mu.Lock()
+	manager.clients["fs"] = &Client{
+		name:  "fs",
+		state: StateConnected,
+		resources: []protocol.Resource{
+			{URI: "file:///b.md", Name: "b.md"},
+			{URI: "file:///a.md", Name: "a.md"},
+		},
+		templates: []protocol.ResourceTemplate{{URITemplate: "file:///{path}", Name: "file"}},
+	}
+	manager.clients["down"] = &Client{
+		name:      "down",
+		state:     StateError,
+		resources: []protocol.Resource{{URI: "x://y", Name: "y"}},
+	}
+	manager.mu.Unlock()
+
+	resources := manager.GetAllResources()
+	if len(resources) != 2 || resources[0].URI != "file:///a.md" || resources[0].ServerName != "fs" {
+		t.Errorf("unexpected resources: %+v", resources)
+	}
+	if templates := manager.GetAllResourceTemplates(); len(templates) != 1 {
+		t.Errorf("unexpected templates: %+v", templates)
+	}
+
+	mentions := manager.ResolveMentions("see @fs:file:///a.md and @down:x://y and @nope:file:///z")
+	if len(mentions) != 1 || mentions[0].Server != "fs" {
+		t.Errorf("unexpected mentions: %+v", mentions)
+	}
+}
+
+func TestClient_ResourceUpdatedNotification(t *testing.T) {
+	mockT := newMockClientTransport()
+	manager := NewManager(nil)
+
+	c := &Client{
+		name:      "fs",
+		transport: mockT,
+		pending:   make(map[int64]chan *protocol.Response),
+		config:    ClientConfig{Timeout: 5 * time.Second},
+		state:     StateConnected,
+	}
+	manager.mu.Lock()
+	manager.clients["fs"] = c
+	manager.mu.Unlock()
+
+	updates := make(chan [2]string, 1)
+	manager.SetResourceUpdateHandler(func(serverName, uri string) {
+		updates <- [2]string{serverName, uri}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctx = ctx
+	c.cancel = cancel
+	c.wg.Add(1)
+	go c.receiveLoop()
+	defer c.Close()
+
+	data, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  protocol.MethodResourcesUpdated,
+		"params":  map[string]any{"uri": "file:///a.md"},
+	})
+	mockT.QueueResponse(data)
+
+	select {
+	case got := <-updates:
+		if got != [2]string{"fs", "file:///a.md"} {
+			t.Errorf("unexpected update: %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("resource update handler not called")
+	}
+}
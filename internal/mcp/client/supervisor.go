@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"mote/internal/mcp/protocol"
+)
+
+// SupervisorConfig configures health supervision of connected servers.
+type SupervisorConfig struct {
+	// Interval is the time between health checks.
+	Interval time.Duration
+	// PingTimeout bounds a single ping.
+	PingTimeout time.Duration
+	// FailureThreshold is the number of consecutive failed pings after which
+	// a server is considered down and restarted.
+	FailureThreshold int
+	// Policy is the backoff between restart attempts. Once its retries are
+	// used up the server is left down until it is restarted manually.
+	Policy *ReconnectPolicy
+}
+
+// DefaultSupervisorConfig returns the default supervision settings.
+func DefaultSupervisorConfig() SupervisorConfig {
+	return SupervisorConfig{
+		Interval:         30 * time.Second,
+		PingTimeout:      5 * time.Second,
+		FailureThreshold: 2,
+		Policy:           DefaultReconnectPolicy(),
+	}
+}
+
+// ServerHealth is the supervision state of a server.
+type ServerHealth struct {
+	Healthy             bool       `json:"healthy"`
+	LastCheck           *time.Time `json:"last_check,omitempty"`
+	LatencyMs           int64      `json:"latency_ms,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures,omitempty"`
+	Restarts            int        `json:"restarts,omitempty"`
+	NextRestart         *time.Time `json:"next_restart,omitempty"`
+	GaveUp              bool       `json:"gave_up,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// serverHealth is the mutable supervision state kept per server.
+type serverHealth struct {
+	ServerHealth
+	attempts int
+}
+
+// Ping checks that the server is responsive.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.call(ctx, protocol.MethodPing, nil, nil)
+}
+
+// StartSupervisor periodically pings connected servers and restarts the
+// ones that stopped responding, backing off between attempts. It runs until
+// the manager is stopped.
+func (m *Manager) StartSupervisor(cfg SupervisorConfig) {
+	defaults := DefaultSupervisorConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaults.Interval
+	}
+	if cfg.PingTimeout <= 0 {
+		cfg.PingTimeout = defaults.PingTimeout
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaults.FailureThreshold
+	}
+	if cfg.Policy == nil {
+		cfg.Policy = defaults.Policy
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+				m.checkServers(cfg)
+			}
+		}
+	}()
+}
+
+// checkServers runs one health check round over all servers.
+func (m *Manager) checkServers(cfg SupervisorConfig) {
+	m.mu.RLock()
+	clients := make(map[string]*Client, len(m.clients))
+	for name, c := range m.clients {
+		clients[name] = c
+	}
+	m.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.checkServer(cfg, name, c)
+		}()
+	}
+	wg.Wait()
+}
+
+// checkServer pings a connected server, or restarts one that is down when
+// its backoff has elapsed.
+func (m *Manager) checkServer(cfg SupervisorConfig, name string, c *Client) {
+	h := m.healthFor(name)
+	now := time.Now()
+
+	if c.State() == StateConnected {
+		ctx, cancel := context.WithTimeout(m.ctx, cfg.PingTimeout)
+		err := c.Ping(ctx)
+		latency := time.Since(now)
+		cancel()
+
+		m.healthMu.Lock()
+		h.LastCheck = &now
+		if err == nil {
+			h.Healthy = true
+			h.LatencyMs = latency.Milliseconds()
+			h.ConsecutiveFailures = 0
+			h.LastError = ""
+			m.healthMu.Unlock()
+			return
+		}
+		h.Healthy = false
+		h.ConsecutiveFailures++
+		h.LastError = err.Error()
+		down := h.ConsecutiveFailures >= cfg.FailureThreshold
+		if down {
+			h.attempts = 0
+			h.NextRestart = &now
+		}
+		m.healthMu.Unlock()
+		if !down {
+			return
+		}
+		c.setState(StateError, fmt.Errorf("health check failed: %w", err))
+	}
+
+	m.healthMu.Lock()
+	wait := h.GaveUp || (h.NextRestart != nil && now.Before(*h.NextRestart))
+	m.healthMu.Unlock()
+	if wait || m.ctx.Err() != nil {
+		return
+	}
+	// The server may have been stopped or replaced in the meantime
+	if cur, ok := m.GetClient(name); !ok || cur != c {
+		return
+	}
+
+	_ = c.Close()
+	ctx, cancel := context.WithTimeout(m.ctx, c.config.Timeout)
+	err := c.Connect(ctx)
+	cancel()
+
+	m.healthMu.Lock()
+	h.Restarts++
+	if err == nil {
+		h.Healthy = true
+		h.ConsecutiveFailures = 0
+		h.NextRestart = nil
+		h.LastError = ""
+		h.attempts = 0
+		m.healthMu.Unlock()
+		m.notifyListChanged(name)
+		return
+	}
+	h.LastError = err.Error()
+	h.attempts++
+	if cfg.Policy.ShouldRetry(h.attempts) {
+		next := now.Add(cfg.Policy.NextDelay(h.attempts - 1))
+		h.NextRestart = &next
+	} else {
+		h.GaveUp = true
+		h.NextRestart = nil
+	}
+	m.healthMu.Unlock()
+}
+
+// healthFor returns the supervision state of a server, creating it if needed.
+func (m *Manager) healthFor(name string) *serverHealth {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	if m.health == nil {
+		m.health = make(map[string]*serverHealth)
+	}
+	h, ok := m.health[name]
+	if !ok {
+		h = &serverHealth{}
+		m.health[name] = h
+	}
+	return h
+}
+
+// Health returns a snapshot of the supervision state of a server, or nil
+// when it has not been checked yet.
+func (m *Manager) Health(name string) *ServerHealth {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	h, ok := m.health[name]
+	if !ok {
+		return nil
+	}
+	snapshot := h.ServerHealth
+	return &snapshot
+}
+
+// resetHealth forgets the supervision state of a server.
+func (m *Manager) resetHealth(name string) {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	delete(m.health, name)
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"mote/internal/mcp/protocol"
+)
+
+func supervisedManager(c *Client) *Manager {
+	m := NewManager(nil)
+	m.clients[c.name] = c
+	return m
+}
+
+func TestSupervisor_HealthyServer(t *testing.T) {
+	c, mockT := startTestClient(t, "fs")
+	m := supervisedManager(c)
+	defer m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.checkServers(SupervisorConfig{PingTimeout: time.Second, FailureThreshold: 1, Policy: DefaultReconnectPolicy()})
+		close(done)
+	}()
+	req := nextRequest(t, mockT)
+	if req.Method != protocol.MethodPing {
+		t.Fatalf("expected ping, got %s", req.Method)
+	}
+	queueMessage(mockT, map[string]any{"id": req.ID, "result": map[string]any{}})
+	<-done
+
+	h := m.Health("fs")
+	if h == nil || !h.Healthy || h.LastCheck == nil || h.Restarts != 0 {
+		t.Errorf("unexpected health: %+v", h)
+	}
+	if statuses := m.ListServers(); len(statuses) != 1 || statuses[0].Health == nil {
+		t.Errorf("health not reported: %+v", statuses)
+	}
+}
+
+func TestSupervisor_RestartsWithBackoff(t *testing.T) {
+	// The test client has no transport config, so every restart fails
+	c, _ := startTestClient(t, "broken")
+	m := supervisedManager(c)
+	defer m.cancel()
+
+	cfg := SupervisorConfig{
+		PingTimeout:      20 * time.Millisecond,
+		FailureThreshold: 2,
+		Policy:           &ReconnectPolicy{MaxRetries: 2, InitialDelay: time.Hour, MaxDelay: time.Hour, Multiplier: 2},
+	}
+
+	m.checkServer(cfg, "broken", c)
+	if h := m.Health("broken"); h.Healthy || h.ConsecutiveFailures != 1 || h.Restarts != 0 {
+		t.Fatalf("after first failed ping: %+v", h)
+	}
+	if c.State() != StateConnected {
+		t.Fatal("server marked down before reaching the failure threshold")
+	}
+
+	m.checkServer(cfg, "broken", c)
+	h := m.Health("broken")
+	if h.Restarts != 1 || h.NextRestart == nil || h.GaveUp {
+		t.Fatalf("after threshold: %+v", h)
+	}
+	if c.State() != StateError {
+		t.Errorf("state = %s, want error", c.State())
+	}
+
+	// Backoff has not elapsed yet
+	m.checkServer(cfg, "broken", c)
+	if h := m.Health("broken"); h.Restarts != 1 {
+		t.Fatalf("restarted during backoff: %+v", h)
+	}
+
+	past := time.Now().Add(-time.Second)
+	m.healthFor("broken").NextRestart = &past
+	m.checkServer(cfg, "broken", c)
+	if h := m.Health("broken"); h.Restarts != 2 || !h.GaveUp {
+		t.Fatalf("expected to give up after max restarts: %+v", h)
+	}
+
+	// A manual reconnect clears the supervision state
+	_ = m.Disconnect("broken")
+	if m.Health("broken") != nil {
+		t.Error("health kept after disconnect")
+	}
+}
@@ -0,0 +1,463 @@
+// Package host serves the requests connected MCP servers send back to Mote:
+// sampling through the provider pool, elicitation through the approval UI and
+// roots from the session's bound workspace.
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"mote/internal/config"
+	"mote/internal/mcp/client"
+	"mote/internal/mcp/protocol"
+	"mote/internal/policy"
+	"mote/internal/policy/approval"
+	"mote/internal/provider"
+	"mote/internal/usage"
+)
+
+// Tool names used for policy checks and approval requests. Policy rules can
+// block or require approval for them like any other tool.
+const (
+	SamplingToolName    = "mcp_sampling"
+	ElicitationToolName = "mcp_elicitation"
+)
+
+// PolicyChecker checks a call against the tool policy.
+type PolicyChecker interface {
+	Check(ctx context.Context, call *policy.ToolCall) (*policy.PolicyResult, error)
+}
+
+// Approver asks the user to approve a call.
+type Approver interface {
+	RequestApproval(ctx context.Context, call *policy.ToolCall, reason string) (*approval.ApprovalResult, error)
+}
+
+// WorkspaceResolver returns the workspace path bound to a session, or "".
+type WorkspaceResolver func(sessionID string) string
+
+// Host implements client.RequestHandler.
+type Host struct {
+	mu           sync.RWMutex
+	cfg          config.MCPClientConfig
+	pool         *provider.MultiProviderPool
+	defaultModel string
+	policy       PolicyChecker
+	approver     Approver
+	workspace    WorkspaceResolver
+	meter        *usage.Meter
+}
+
+var _ client.RequestHandler = (*Host)(nil)
+
+// New creates a Host with the given configuration. Dependencies are set
+// afterwards, so the host can be attached to the MCP manager before they exist.
+func New(cfg config.MCPClientConfig) *Host {
+	return &Host{cfg: cfg}
+}
+
+// SetConfig replaces the configuration.
+func (h *Host) SetConfig(cfg config.MCPClientConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cfg = cfg
+}
+
+// SetProviderPool sets the pool used for sampling and the model used when the
+// configuration does not name one.
+func (h *Host) SetProviderPool(pool *provider.MultiProviderPool, defaultModel string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pool = pool
+	h.defaultModel = defaultModel
+}
+
+// SetPolicyChecker sets the policy checked before sampling.
+func (h *Host) SetPolicyChecker(p PolicyChecker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.policy = p
+}
+
+// SetApprover sets the approver used for sampling approval and elicitation.
+func (h *Host) SetApprover(a Approver) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.approver = a
+}
+
+// SetUsageMeter sets the meter sampling calls are recorded in and checked
+// against, under the agent "mcp:<server>".
+func (h *Host) SetUsageMeter(m *usage.Meter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.meter = m
+}
+
+// SetWorkspaceResolver sets the resolver used to answer roots/list.
+func (h *Host) SetWorkspaceResolver(r WorkspaceResolver) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.workspace = r
+}
+
+// ClientCapabilities implements client.RequestHandler.
+func (h *Host) ClientCapabilities(serverName string) protocol.Capabilities {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var caps protocol.Capabilities
+	if h.samplingAllowed(serverName) {
+		caps.Sampling = &protocol.SamplingCapability{}
+	}
+	if h.cfg.Elicitation {
+		caps.Elicitation = &protocol.ElicitationCapability{}
+	}
+	if h.cfg.Roots {
+		caps.Roots = &protocol.RootsCapability{}
+	}
+	return caps
+}
+
+// samplingAllowed reports whether serverName may sample. Caller holds h.mu.
+func (h *Host) samplingAllowed(serverName string) bool {
+	if !h.cfg.Sampling.Enabled {
+		return false
+	}
+	return !h.cfg.Sampling.Servers[serverName].Disabled
+}
+
+// CreateMessage implements client.RequestHandler.
+func (h *Host) CreateMessage(ctx context.Context, serverName string, params protocol.CreateMessageParams) (*protocol.CreateMessageResult, error) {
+	h.mu.RLock()
+	allowed := h.samplingAllowed(serverName)
+	sampling := h.cfg.Sampling
+	pool, checker, approver, usageMeter := h.pool, h.policy, h.approver, h.meter
+	defaultModel := sampling.Model
+	if defaultModel == "" {
+		defaultModel = h.defaultModel
+	}
+	h.mu.RUnlock()
+
+	if !allowed {
+		return nil, protocol.NewInvalidRequestError(fmt.Sprintf("sampling is not enabled for server %q", serverName))
+	}
+	if pool == nil {
+		return nil, protocol.NewInternalError("no model provider available")
+	}
+
+	serverCfg := sampling.Servers[serverName]
+	candidates := samplingCandidates(serverCfg.Models, defaultModel, availableModels(pool))
+	model := selectModel(candidates, defaultModel, params.ModelPreferences)
+	if model == "" {
+		return nil, protocol.NewInvalidRequestError(fmt.Sprintf("no model allowed for server %q", serverName))
+	}
+
+	maxTokens := params.MaxTokens
+	if sampling.MaxTokens > 0 && (maxTokens <= 0 || maxTokens > sampling.MaxTokens) {
+		maxTokens = sampling.MaxTokens
+	}
+
+	// Sampling is metered and budgeted like any other model call.
+	sessionID, _ := client.SessionIDFromContext(ctx)
+	agent := "mcp:" + serverName
+	meter := usageMeter.ForAgent(agent, model)
+	switch decision := meter.Admit(sessionID); decision.Action {
+	case usage.ActionStop:
+		return nil, protocol.NewInvalidRequestError("sampling refused: " + decision.Message)
+	case usage.ActionDowngrade:
+		// The downgrade model is still bound by the server's allowlist.
+		if !containsString(candidates, decision.Model) {
+			return nil, protocol.NewInvalidRequestError(fmt.Sprintf(
+				"sampling refused: %s; downgrade model %q is not allowed for server %q",
+				decision.Message, decision.Model, serverName))
+		}
+		slog.Info("mcp sampling: usage budget downgrading model",
+			"server", serverName, "budget", decision.Budget, "from", model, "to", decision.Model)
+		model = decision.Model
+		meter = usageMeter.ForAgent(agent, model)
+	}
+
+	args, _ := json.Marshal(map[string]any{
+		"server":        serverName,
+		"model":         model,
+		"system_prompt": params.SystemPrompt,
+		"messages":      params.Messages,
+		"max_tokens":    maxTokens,
+	})
+	call := &policy.ToolCall{
+		Name:      SamplingToolName,
+		Arguments: string(args),
+		SessionID: sessionID,
+		AgentID:   agent,
+	}
+
+	requireApproval := sampling.RequireApproval
+	if serverCfg.RequireApproval != nil {
+		requireApproval = *serverCfg.RequireApproval
+	}
+	reason := fmt.Sprintf("MCP server %q requests a completion from %s", serverName, model)
+	if checker != nil {
+		result, err := checker.Check(ctx, call)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Allowed {
+			return nil, protocol.NewInvalidRequestError("sampling denied by policy: " + result.Reason)
+		}
+		if result.RequireApproval {
+			requireApproval = true
+			if result.ApprovalReason != "" {
+				reason = result.ApprovalReason
+			}
+		}
+	}
+	if requireApproval {
+		if approver == nil {
+			return nil, protocol.NewInvalidRequestError("sampling requires approval but no approver is available")
+		}
+		result, err := approver.RequestApproval(ctx, call, reason)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Approved {
+			return nil, protocol.NewInvalidRequestError("sampling request rejected by user")
+		}
+	}
+
+	prov, _, err := pool.GetProvider(model)
+	if err != nil {
+		return nil, err
+	}
+	req := buildChatRequest(params, model, maxTokens)
+	resp, err := prov.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Usage != nil {
+		meter.Record(sessionID, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens)
+	}
+
+	slog.Info("mcp sampling completed", "server", serverName, "model", model, "session", sessionID)
+	return &protocol.CreateMessageResult{
+		Role:       "assistant",
+		Content:    protocol.NewTextContent(resp.Content),
+		Model:      model,
+		StopReason: stopReason(resp.FinishReason),
+	}, nil
+}
+
+// availableModels lists the models registered in the pool.
+func availableModels(pool *provider.MultiProviderPool) []string {
+	infos := pool.ListAllModels()
+	models := make([]string, 0, len(infos))
+	for _, m := range infos {
+		models = append(models, m.ID)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// samplingCandidates returns the models a server may sample from: its
+// allowlist ("*" allows any available model) or the default model.
+func samplingCandidates(allowlist []string, defaultModel string, available []string) []string {
+	var candidates []string
+	for _, m := range allowlist {
+		if m == "*" {
+			candidates = append(candidates, available...)
+		} else {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(allowlist) == 0 && defaultModel != "" {
+		candidates = []string{defaultModel}
+	}
+	return candidates
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// selectModel picks the model for a sampling request from candidates. The
+// first candidate matching a model hint wins, as the MCP spec treats hints
+// as substrings of model names; otherwise the first candidate is used.
+func selectModel(candidates []string, defaultModel string, prefs *protocol.ModelPreferences) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	if prefs != nil {
+		for _, hint := range prefs.Hints {
+			name := strings.ToLower(hint.Name)
+			if name == "" {
+				continue
+			}
+			for _, c := range candidates {
+				if strings.Contains(strings.ToLower(c), name) {
+					return c
+				}
+			}
+		}
+	}
+	// "*" on its own expands to the available models; prefer the default
+	// model among them when no hint matched.
+	for _, c := range candidates {
+		if c == defaultModel {
+			return c
+		}
+	}
+	return candidates[0]
+}
+
+// buildChatRequest converts sampling params into a provider request for model.
+// The model must be set explicitly: providers serve several models and fall
+// back to their default when none is given.
+func buildChatRequest(params protocol.CreateMessageParams, model string, maxTokens int) provider.ChatRequest {
+	req := provider.ChatRequest{Model: model, MaxTokens: maxTokens}
+	if params.Temperature != nil {
+		req.Temperature = *params.Temperature
+	}
+	if params.SystemPrompt != "" {
+		req.Messages = append(req.Messages, provider.Message{Role: provider.RoleSystem, Content: params.SystemPrompt})
+	}
+	for _, m := range params.Messages {
+		switch m.Content.Type {
+		case protocol.ContentTypeImage:
+			req.Attachments = append(req.Attachments, provider.Attachment{
+				Type:     "image_url",
+				MimeType: m.Content.MimeType,
+				ImageURL: &provider.ImageURL{URL: fmt.Sprintf("data:%s;base64,%s", m.Content.MimeType, m.Content.Data)},
+			})
+		default:
+			role := provider.RoleUser
+			if m.Role == "assistant" {
+				role = provider.RoleAssistant
+			}
+			req.Messages = append(req.Messages, provider.Message{Role: role, Content: m.Content.Text})
+		}
+	}
+	return req
+}
+
+// stopReason maps a provider finish reason to an MCP stop reason.
+func stopReason(finish string) string {
+	switch finish {
+	case "", "stop":
+		return "endTurn"
+	case "length":
+		return "maxTokens"
+	default:
+		return finish
+	}
+}
+
+// Elicit implements client.RequestHandler. The question is shown as an
+// approval request; approving answers it, rejecting declines it and letting it
+// expire cancels it. The answer is taken from the edited arguments when they
+// are a JSON object, otherwise from the approval message.
+func (h *Host) Elicit(ctx context.Context, serverName string, params protocol.ElicitParams) (*protocol.ElicitResult, error) {
+	h.mu.RLock()
+	enabled, approver := h.cfg.Elicitation, h.approver
+	h.mu.RUnlock()
+
+	if !enabled || approver == nil {
+		return &protocol.ElicitResult{Action: protocol.ElicitActionCancel}, nil
+	}
+
+	sessionID, _ := client.SessionIDFromContext(ctx)
+	args, _ := json.Marshal(map[string]any{
+		"server":           serverName,
+		"message":          params.Message,
+		"requested_schema": params.RequestedSchema,
+	})
+	call := &policy.ToolCall{
+		Name:      ElicitationToolName,
+		Arguments: string(args),
+		SessionID: sessionID,
+		AgentID:   "mcp:" + serverName,
+	}
+
+	result, err := approver.RequestApproval(ctx, call, fmt.Sprintf("MCP server %q asks: %s", serverName, params.Message))
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return &protocol.ElicitResult{Action: protocol.ElicitActionCancel}, nil
+		}
+		return nil, err
+	}
+
+	switch {
+	case result.Decision == approval.DecisionTimeout:
+		return &protocol.ElicitResult{Action: protocol.ElicitActionCancel}, nil
+	case !result.Approved:
+		return &protocol.ElicitResult{Action: protocol.ElicitActionDecline}, nil
+	}
+	return &protocol.ElicitResult{
+		Action:  protocol.ElicitActionAccept,
+		Content: elicitContent(result, params.RequestedSchema),
+	}, nil
+}
+
+// elicitContent extracts the user's answer from an approval result.
+func elicitContent(result *approval.ApprovalResult, schema json.RawMessage) map[string]any {
+	for _, raw := range []string{result.ModifiedArguments, result.Message} {
+		var obj map[string]any
+		if raw != "" && json.Unmarshal([]byte(raw), &obj) == nil {
+			// Edited arguments echo the request; the answer is in "content".
+			if content, ok := obj["content"].(map[string]any); ok {
+				return content
+			}
+			if _, ok := obj["requested_schema"]; !ok {
+				return obj
+			}
+		}
+	}
+	if result.Message == "" {
+		return nil
+	}
+
+	// A plain-text answer fills the schema's only property.
+	var s struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if json.Unmarshal(schema, &s) == nil && len(s.Properties) == 1 {
+		for name := range s.Properties {
+			return map[string]any{name: result.Message}
+		}
+	}
+	return map[string]any{"response": result.Message}
+}
+
+// ListRoots implements client.RequestHandler. It returns the workspace bound
+// to the session of the call in flight, if any.
+func (h *Host) ListRoots(ctx context.Context, serverName string) (*protocol.ListRootsResult, error) {
+	h.mu.RLock()
+	enabled, resolve := h.cfg.Roots, h.workspace
+	h.mu.RUnlock()
+
+	result := &protocol.ListRootsResult{Roots: []protocol.Root{}}
+	sessionID, ok := client.SessionIDFromContext(ctx)
+	if !enabled || resolve == nil || !ok {
+		return result, nil
+	}
+	if path := resolve(sessionID); path != "" {
+		result.Roots = append(result.Roots, protocol.Root{
+			URI:  (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(),
+			Name: filepath.Base(path),
+		})
+	}
+	return result, nil
+}
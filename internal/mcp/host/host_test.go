@@ -0,0 +1,356 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mote/internal/config"
+	"mote/internal/mcp/client"
+	"mote/internal/mcp/protocol"
+	"mote/internal/policy"
+	"mote/internal/policy/approval"
+	"mote/internal/provider"
+	"mote/internal/storage"
+	"mote/internal/usage"
+)
+
+type fakeProvider struct {
+	model string
+	last  provider.ChatRequest
+}
+
+func (p *fakeProvider) Name() string     { return "fake" }
+func (p *fakeProvider) Models() []string { return []string{p.model} }
+func (p *fakeProvider) Chat(ctx context.Context, req provider.ChatRequest) (*provider.ChatResponse, error) {
+	p.last = req
+	return &provider.ChatResponse{
+		Content:      "answer from " + p.model,
+		FinishReason: "stop",
+		Usage:        &provider.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}, nil
+}
+func (p *fakeProvider) Stream(ctx context.Context, req provider.ChatRequest) (<-chan provider.ChatEvent, error) {
+	return nil, nil
+}
+
+type fakeApprover struct {
+	result *approval.ApprovalResult
+	calls  []*policy.ToolCall
+}
+
+func (a *fakeApprover) RequestApproval(ctx context.Context, call *policy.ToolCall, reason string) (*approval.ApprovalResult, error) {
+	a.calls = append(a.calls, call)
+	return a.result, nil
+}
+
+func newTestPool(t *testing.T) (*provider.MultiProviderPool, map[string]*fakeProvider) {
+	t.Helper()
+	providers := map[string]*fakeProvider{}
+	pool := provider.NewPool(func(model string) (provider.Provider, error) {
+		p := &fakeProvider{model: model}
+		providers[model] = p
+		return p, nil
+	})
+	multi := provider.NewMultiProviderPool()
+	if err := multi.AddProvider("copilot", pool, []string{"gpt-4o", "claude-sonnet-4.5", "gpt-4o-mini"}); err != nil {
+		t.Fatal(err)
+	}
+	return multi, providers
+}
+
+func samplingParams(hints ...string) protocol.CreateMessageParams {
+	p := protocol.CreateMessageParams{
+		Messages:     []protocol.SamplingMessage{{Role: "user", Content: protocol.NewTextContent("summarise")}},
+		SystemPrompt: "be brief",
+		MaxTokens:    100000,
+	}
+	if len(hints) > 0 {
+		p.ModelPreferences = &protocol.ModelPreferences{}
+		for _, h := range hints {
+			p.ModelPreferences.Hints = append(p.ModelPreferences.Hints, protocol.ModelHint{Name: h})
+		}
+	}
+	return p
+}
+
+func TestSelectModel(t *testing.T) {
+	available := []string{"claude-sonnet-4.5", "gpt-4o", "gpt-4o-mini"}
+	hints := func(names ...string) *protocol.ModelPreferences {
+		p := &protocol.ModelPreferences{}
+		for _, n := range names {
+			p.Hints = append(p.Hints, protocol.ModelHint{Name: n})
+		}
+		return p
+	}
+
+	tests := []struct {
+		name      string
+		allowlist []string
+		prefs     *protocol.ModelPreferences
+		want      string
+	}{
+		{"default only", nil, hints("claude"), "gpt-4o"},
+		{"allowlist hint", []string{"gpt-4o-mini", "claude-sonnet-4.5"}, hints("claude"), "claude-sonnet-4.5"},
+		{"allowlist no hint", []string{"gpt-4o-mini", "claude-sonnet-4.5"}, nil, "gpt-4o-mini"},
+		{"wildcard hint", []string{"*"}, hints("sonnet"), "claude-sonnet-4.5"},
+		{"wildcard prefers default", []string{"*"}, hints("gemini"), "gpt-4o"},
+	}
+	for _, tt := range tests {
+		if got := selectModel(samplingCandidates(tt.allowlist, "gpt-4o", available), "gpt-4o", tt.prefs); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCreateMessage(t *testing.T) {
+	pool, providers := newTestPool(t)
+	h := New(config.MCPClientConfig{Sampling: config.MCPSamplingConfig{
+		Enabled:   true,
+		MaxTokens: 2048,
+		Servers: map[string]config.MCPServerSamplingConfig{
+			"docs":    {Models: []string{"claude-sonnet-4.5"}},
+			"blocked": {Disabled: true},
+		},
+	}})
+	h.SetProviderPool(pool, "gpt-4o")
+
+	if caps := h.ClientCapabilities("blocked"); caps.Sampling != nil {
+		t.Error("disabled server should not be offered sampling")
+	}
+	if _, err := h.CreateMessage(context.Background(), "blocked", samplingParams()); err == nil {
+		t.Error("disabled server should not sample")
+	}
+
+	result, err := h.CreateMessage(context.Background(), "other", samplingParams("claude"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Model != "gpt-4o" || result.StopReason != "endTurn" {
+		t.Errorf("server without allowlist should get the default model: %+v", result)
+	}
+
+	result, err = h.CreateMessage(context.Background(), "docs", samplingParams("gpt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Model != "claude-sonnet-4.5" || result.Content.Text != "answer from claude-sonnet-4.5" {
+		t.Errorf("allowlisted model expected: %+v", result)
+	}
+	req := providers["claude-sonnet-4.5"].last
+	if req.Model != "claude-sonnet-4.5" {
+		t.Errorf("provider should be asked for the selected model, got %q", req.Model)
+	}
+	if req.MaxTokens != 2048 {
+		t.Errorf("max tokens not capped: %d", req.MaxTokens)
+	}
+	if len(req.Messages) != 2 || req.Messages[0].Role != provider.RoleSystem {
+		t.Errorf("unexpected messages: %+v", req.Messages)
+	}
+}
+
+func TestCreateMessage_SharedProvider(t *testing.T) {
+	// Real providers serve every model from one instance and use their own
+	// default when the request names none.
+	shared := &fakeProvider{model: "gpt-4o"}
+	pool := provider.NewPool(func(model string) (provider.Provider, error) { return shared, nil })
+	multi := provider.NewMultiProviderPool()
+	if err := multi.AddProvider("copilot", pool, []string{"gpt-4o", "gpt-4o-mini"}); err != nil {
+		t.Fatal(err)
+	}
+	h := New(config.MCPClientConfig{Sampling: config.MCPSamplingConfig{
+		Enabled: true,
+		Servers: map[string]config.MCPServerSamplingConfig{"docs": {Models: []string{"*"}}},
+	}})
+	h.SetProviderPool(multi, "gpt-4o")
+
+	result, err := h.CreateMessage(context.Background(), "docs", samplingParams("mini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared.last.Model != "gpt-4o-mini" || result.Model != "gpt-4o-mini" {
+		t.Errorf("hinted model not requested: request %q, result %q", shared.last.Model, result.Model)
+	}
+}
+
+func TestCreateMessage_Usage(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "usage.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	ledger := usage.NewLedger(db.DB)
+
+	pool, _ := newTestPool(t)
+	h := New(config.MCPClientConfig{Sampling: config.MCPSamplingConfig{Enabled: true}})
+	h.SetProviderPool(pool, "gpt-4o")
+	h.SetUsageMeter(usage.NewMeter(ledger, config.UsageConfig{Budgets: []config.BudgetConfig{
+		{Name: "docs-sampling", Scope: usage.ScopeAgent, Match: "mcp:docs", MaxTokens: 10, Action: string(usage.ActionStop)},
+	}}))
+
+	ctx := client.WithSessionID(context.Background(), "s1")
+	if _, err := h.CreateMessage(ctx, "docs", samplingParams()); err != nil {
+		t.Fatal(err)
+	}
+	total, err := ledger.Sum(usage.Filter{Agent: "mcp:docs", RootSessionID: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.Calls != 1 || total.TotalTokens != 15 {
+		t.Errorf("sampling usage not recorded: %+v", total)
+	}
+
+	if _, err := h.CreateMessage(ctx, "docs", samplingParams()); err == nil || !strings.Contains(err.Error(), "sampling refused") {
+		t.Errorf("exhausted budget should refuse sampling, got %v", err)
+	}
+	if _, err := h.CreateMessage(ctx, "other", samplingParams()); err != nil {
+		t.Errorf("budget should only cover its own server: %v", err)
+	}
+}
+
+func TestCreateMessage_UsageDowngrade(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "usage.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	pool, _ := newTestPool(t)
+	h := New(config.MCPClientConfig{Sampling: config.MCPSamplingConfig{
+		Enabled: true,
+		Servers: map[string]config.MCPServerSamplingConfig{
+			"docs": {Models: []string{"gpt-4o", "gpt-4o-mini"}},
+			"wiki": {Models: []string{"gpt-4o"}},
+		},
+	}})
+	h.SetProviderPool(pool, "gpt-4o")
+	h.SetUsageMeter(usage.NewMeter(usage.NewLedger(db.DB), config.UsageConfig{Budgets: []config.BudgetConfig{
+		{Name: "sampling", Scope: usage.ScopeAgent, MaxTokens: 10, Action: string(usage.ActionDowngrade), DowngradeModel: "gpt-4o-mini"},
+	}}))
+
+	ctx := client.WithSessionID(context.Background(), "s1")
+	for _, server := range []string{"docs", "wiki"} {
+		if _, err := h.CreateMessage(ctx, server, samplingParams()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := h.CreateMessage(ctx, "docs", samplingParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Model != "gpt-4o-mini" {
+		t.Errorf("allowlisted downgrade model expected, got %q", result.Model)
+	}
+	if _, err := h.CreateMessage(ctx, "wiki", samplingParams()); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("downgrade outside the allowlist should be refused, got %v", err)
+	}
+}
+
+func TestCreateMessage_Gating(t *testing.T) {
+	pool, _ := newTestPool(t)
+	h := New(config.MCPClientConfig{Sampling: config.MCPSamplingConfig{Enabled: true, RequireApproval: true}})
+	h.SetProviderPool(pool, "gpt-4o")
+
+	if _, err := h.CreateMessage(context.Background(), "srv", samplingParams()); err == nil {
+		t.Error("approval required without approver should fail")
+	}
+
+	approver := &fakeApprover{result: &approval.ApprovalResult{Approved: false, Decision: approval.DecisionRejected}}
+	h.SetApprover(approver)
+	ctx := client.WithSessionID(context.Background(), "sess-1")
+	if _, err := h.CreateMessage(ctx, "srv", samplingParams()); err == nil {
+		t.Error("rejected sampling should fail")
+	}
+	if len(approver.calls) != 1 || approver.calls[0].Name != SamplingToolName || approver.calls[0].SessionID != "sess-1" {
+		t.Errorf("unexpected approval calls: %+v", approver.calls)
+	}
+
+	approver.result = &approval.ApprovalResult{Approved: true, Decision: approval.DecisionApproved}
+	if _, err := h.CreateMessage(ctx, "srv", samplingParams()); err != nil {
+		t.Errorf("approved sampling failed: %v", err)
+	}
+
+	pol := policy.DefaultPolicy()
+	pol.Blocklist = []string{SamplingToolName}
+	h.SetPolicyChecker(policy.NewPolicyExecutor(&pol))
+	_, err := h.CreateMessage(ctx, "srv", samplingParams())
+	if err == nil || !strings.Contains(err.Error(), "policy") {
+		t.Errorf("blocklisted sampling should be denied by policy, got %v", err)
+	}
+}
+
+func TestElicit(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"}}}`)
+	params := protocol.ElicitParams{Message: "What is your name?", RequestedSchema: schema}
+	ctx := client.WithSessionID(context.Background(), "sess-1")
+
+	h := New(config.MCPClientConfig{Elicitation: true})
+	result, err := h.Elicit(ctx, "srv", params)
+	if err != nil || result.Action != protocol.ElicitActionCancel {
+		t.Errorf("without approver: %+v, %v", result, err)
+	}
+
+	approver := &fakeApprover{}
+	h.SetApprover(approver)
+
+	tests := []struct {
+		name    string
+		result  approval.ApprovalResult
+		action  string
+		content map[string]any
+	}{
+		{"plain answer", approval.ApprovalResult{Approved: true, Message: "Ada"}, protocol.ElicitActionAccept, map[string]any{"name": "Ada"}},
+		{"json answer", approval.ApprovalResult{Approved: true, ModifiedArguments: `{"server":"srv","content":{"name":"Bob"}}`}, protocol.ElicitActionAccept, map[string]any{"name": "Bob"}},
+		{"rejected", approval.ApprovalResult{Approved: false, Decision: approval.DecisionRejected}, protocol.ElicitActionDecline, nil},
+		{"timeout", approval.ApprovalResult{Approved: false, Decision: approval.DecisionTimeout}, protocol.ElicitActionCancel, nil},
+	}
+	for _, tt := range tests {
+		approver.result = &tt.result
+		result, err := h.Elicit(ctx, "srv", params)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if result.Action != tt.action {
+			t.Errorf("%s: action = %q, want %q", tt.name, result.Action, tt.action)
+		}
+		if tt.content != nil && result.Content["name"] != tt.content["name"] {
+			t.Errorf("%s: content = %v, want %v", tt.name, result.Content, tt.content)
+		}
+	}
+
+	call := approver.calls[0]
+	if call.Name != ElicitationToolName || call.SessionID != "sess-1" || !strings.Contains(call.Arguments, "What is your name?") {
+		t.Errorf("unexpected approval call: %+v", call)
+	}
+}
+
+func TestListRoots(t *testing.T) {
+	h := New(config.MCPClientConfig{Roots: true})
+	h.SetWorkspaceResolver(func(sessionID string) string {
+		if sessionID == "bound" {
+			return "/home/me/project"
+		}
+		return ""
+	})
+
+	result, err := h.ListRoots(client.WithSessionID(context.Background(), "bound"), "srv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Roots) != 1 || result.Roots[0].URI != "file:///home/me/project" || result.Roots[0].Name != "project" {
+		t.Errorf("unexpected roots: %+v", result.Roots)
+	}
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		client.WithSessionID(context.Background(), "unbound"),
+	} {
+		result, err := h.ListRoots(ctx, "srv")
+		if err != nil || len(result.Roots) != 0 {
+			t.Errorf("expected no roots, got %+v, %v", result, err)
+		}
+	}
+}
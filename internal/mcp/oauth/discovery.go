@@ -0,0 +1,162 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProtectedResourceMetadata is the RFC 9728 metadata of an MCP server.
+type ProtectedResourceMetadata struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers"`
+	ScopesSupported      []string `json:"scopes_supported,omitempty"`
+}
+
+// AuthServerMetadata is the RFC 8414 metadata of an authorization server.
+type AuthServerMetadata struct {
+	Issuer                string   `json:"issuer,omitempty"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	RegistrationEndpoint  string   `json:"registration_endpoint,omitempty"`
+	ScopesSupported       []string `json:"scopes_supported,omitempty"`
+}
+
+// Discover finds the authorization server of the MCP server at serverURL.
+// Servers without metadata are assumed to host the default endpoints
+// (/authorize, /token, /register) on their own origin.
+func Discover(ctx context.Context, client *http.Client, serverURL string) (*AuthServerMetadata, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q", serverURL)
+	}
+
+	issuer := u.Scheme + "://" + u.Host
+	var prm ProtectedResourceMetadata
+	if found, err := fetchWellKnown(ctx, client, u, "oauth-protected-resource", &prm); err != nil {
+		return nil, err
+	} else if found {
+		if len(prm.AuthorizationServers) > 0 {
+			issuer = prm.AuthorizationServers[0]
+		}
+	}
+
+	iu, err := url.Parse(issuer)
+	if err != nil || iu.Host == "" {
+		return nil, fmt.Errorf("invalid authorization server %q", issuer)
+	}
+	var meta AuthServerMetadata
+	for _, name := range []string{"oauth-authorization-server", "openid-configuration"} {
+		found, err := fetchWellKnown(ctx, client, iu, name, &meta)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" {
+				return nil, errors.New("authorization server metadata lacks endpoints")
+			}
+			if len(meta.ScopesSupported) == 0 {
+				meta.ScopesSupported = prm.ScopesSupported
+			}
+			return &meta, nil
+		}
+	}
+
+	origin := iu.Scheme + "://" + iu.Host
+	return &AuthServerMetadata{
+		Issuer:                origin,
+		AuthorizationEndpoint: origin + "/authorize",
+		TokenEndpoint:         origin + "/token",
+		RegistrationEndpoint:  origin + "/register",
+		ScopesSupported:       prm.ScopesSupported,
+	}, nil
+}
+
+// fetchWellKnown fetches /.well-known/<name> for u, first with u's path
+// appended and then at the root. found is false when neither exists.
+func fetchWellKnown(ctx context.Context, client *http.Client, u *url.URL, name string, v any) (bool, error) {
+	origin := u.Scheme + "://" + u.Host
+	candidates := []string{origin + "/.well-known/" + name}
+	if path := strings.TrimSuffix(u.Path, "/"); path != "" {
+		candidates = append([]string{origin + "/.well-known/" + name + path}, candidates...)
+	}
+
+	for _, endpoint := range candidates {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return false, fmt.Errorf("fetch %s: %w", endpoint, err)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil {
+			return false, fmt.Errorf("read %s: %w", endpoint, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			continue
+		}
+		if err := json.Unmarshal(body, v); err != nil {
+			return false, fmt.Errorf("parse %s: %w", endpoint, err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// Registration is the result of dynamic client registration.
+type Registration struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// Register registers Mote as a public client with the authorization server.
+func Register(ctx context.Context, client *http.Client, endpoint, redirectURL string) (*Registration, error) {
+	body, err := json.Marshal(map[string]any{
+		"client_name":                "Mote",
+		"redirect_uris":              []string{redirectURL},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("register client: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read registration response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("register client: status %d, body: %s", resp.StatusCode, data)
+	}
+
+	var reg Registration
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, fmt.Errorf("parse registration response: %w", err)
+	}
+	if reg.ClientID == "" {
+		return nil, errors.New("registration response has no client_id")
+	}
+	return &reg, nil
+}
@@ -0,0 +1,457 @@
+// Package oauth implements the OAuth 2.1 authorization-code flow with PKCE
+// used to authorize Mote against remote MCP servers.
+//
+// Authorization servers are discovered through protected resource metadata
+// (RFC 9728) and authorization server metadata (RFC 8414); clients are
+// registered dynamically (RFC 7591) when no client ID is configured. Tokens
+// are persisted in a secret store and refreshed automatically.
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"mote/internal/config"
+)
+
+// secretKeyPrefix prefixes the secret store keys holding server tokens.
+const secretKeyPrefix = "mcp_oauth:"
+
+// pendingTTL is how long an authorization started by Begin stays valid.
+const pendingTTL = 10 * time.Minute
+
+// expiryLeeway refreshes tokens slightly before they expire.
+const expiryLeeway = 30 * time.Second
+
+var (
+	// ErrUnknownState is returned by Complete for unknown or expired states.
+	ErrUnknownState = errors.New("unknown or expired authorization state")
+	// ErrNotAuthorized is returned when a server has no stored token.
+	ErrNotAuthorized = errors.New("server is not authorized")
+)
+
+// Config is the per-server OAuth configuration. All fields are optional:
+// endpoints are discovered and clients registered when left empty.
+type Config struct {
+	ClientID         string   `json:"client_id,omitempty"`
+	ClientSecret     string   `json:"client_secret,omitempty"`
+	Scopes           []string `json:"scopes,omitempty"`
+	AuthorizationURL string   `json:"authorization_url,omitempty"`
+	TokenURL         string   `json:"token_url,omitempty"`
+}
+
+// Token is a stored access token together with what is needed to refresh it.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	TokenURL     string `json:"token_url"`
+	Resource     string `json:"resource,omitempty"`
+}
+
+// Expired reports whether the token is expired or about to expire.
+func (t *Token) Expired() bool {
+	return !t.Expiry.IsZero() && time.Now().Add(expiryLeeway).After(t.Expiry)
+}
+
+// pendingAuth is an authorization waiting for its callback.
+type pendingAuth struct {
+	server       string
+	verifier     string
+	redirectURL  string
+	clientID     string
+	clientSecret string
+	tokenURL     string
+	resource     string
+	created      time.Time
+}
+
+// Authenticator runs authorization flows and hands out token sources.
+type Authenticator struct {
+	store      config.SecretStore
+	httpClient *http.Client
+
+	pending map[string]*pendingAuth
+	sources map[string]*TokenSource
+	mu      sync.Mutex
+}
+
+// NewAuthenticator creates an Authenticator persisting tokens in store.
+func NewAuthenticator(store config.SecretStore) *Authenticator {
+	return &Authenticator{
+		store:      store,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		pending:    make(map[string]*pendingAuth),
+		sources:    make(map[string]*TokenSource),
+	}
+}
+
+// Begin starts authorizing server, reachable at serverURL, and returns the URL
+// the user must open. The authorization server redirects to redirectURL, whose
+// handler passes the state and code to Complete.
+func (a *Authenticator) Begin(ctx context.Context, server, serverURL string, cfg *Config, redirectURL string) (string, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	resource := canonicalResource(serverURL)
+
+	meta := &AuthServerMetadata{
+		AuthorizationEndpoint: cfg.AuthorizationURL,
+		TokenEndpoint:         cfg.TokenURL,
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" {
+		discovered, err := Discover(ctx, a.httpClient, serverURL)
+		if err != nil {
+			return "", err
+		}
+		if meta.AuthorizationEndpoint == "" {
+			meta.AuthorizationEndpoint = discovered.AuthorizationEndpoint
+		}
+		if meta.TokenEndpoint == "" {
+			meta.TokenEndpoint = discovered.TokenEndpoint
+		}
+		meta.RegistrationEndpoint = discovered.RegistrationEndpoint
+		meta.ScopesSupported = discovered.ScopesSupported
+	}
+
+	clientID, clientSecret := cfg.ClientID, cfg.ClientSecret
+	if clientID == "" {
+		if meta.RegistrationEndpoint == "" {
+			return "", fmt.Errorf("no client_id configured and %s does not support dynamic client registration", server)
+		}
+		reg, err := Register(ctx, a.httpClient, meta.RegistrationEndpoint, redirectURL)
+		if err != nil {
+			return "", err
+		}
+		clientID, clientSecret = reg.ClientID, reg.ClientSecret
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", clientID)
+	q.Set("redirect_uri", redirectURL)
+	q.Set("state", state)
+	q.Set("code_challenge", challenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	q.Set("resource", resource)
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = meta.ScopesSupported
+	}
+	if len(scopes) > 0 {
+		q.Set("scope", strings.Join(scopes, " "))
+	}
+	authURL.RawQuery = q.Encode()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for s, p := range a.pending {
+		if time.Since(p.created) > pendingTTL {
+			delete(a.pending, s)
+		}
+	}
+	a.pending[state] = &pendingAuth{
+		server:       server,
+		verifier:     verifier,
+		redirectURL:  redirectURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		tokenURL:     meta.TokenEndpoint,
+		resource:     resource,
+		created:      time.Now(),
+	}
+	return authURL.String(), nil
+}
+
+// Complete exchanges the authorization code for tokens, stores them and
+// returns the name of the authorized server.
+func (a *Authenticator) Complete(ctx context.Context, state, code string) (string, error) {
+	a.mu.Lock()
+	p, ok := a.pending[state]
+	delete(a.pending, state)
+	a.mu.Unlock()
+	if !ok || time.Since(p.created) > pendingTTL {
+		return "", ErrUnknownState
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"code_verifier": {p.verifier},
+		"resource":      {p.resource},
+	}
+	tok := &Token{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		TokenURL:     p.tokenURL,
+		Resource:     p.resource,
+	}
+	if err := a.exchange(ctx, tok, form); err != nil {
+		return "", err
+	}
+	if err := a.saveToken(p.server, tok); err != nil {
+		return "", err
+	}
+	a.TokenSource(p.server).set(tok)
+	return p.server, nil
+}
+
+// TokenSource returns the token source for server. It implements
+// transport.Authorizer and is shared by all connections to the server.
+func (a *Authenticator) TokenSource(server string) *TokenSource {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ts, ok := a.sources[server]
+	if !ok {
+		ts = &TokenSource{auth: a, server: server}
+		a.sources[server] = ts
+	}
+	return ts
+}
+
+// Token returns the stored token for server.
+func (a *Authenticator) Token(server string) (*Token, error) {
+	raw, err := a.store.Get(secretKeyPrefix + server)
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, ErrNotAuthorized
+	}
+	var tok Token
+	if err := json.Unmarshal([]byte(raw), &tok); err != nil {
+		return nil, fmt.Errorf("parse stored token: %w", err)
+	}
+	return &tok, nil
+}
+
+// Revoke forgets the stored token for server.
+func (a *Authenticator) Revoke(server string) error {
+	a.TokenSource(server).set(nil)
+	return a.store.Delete(secretKeyPrefix + server)
+}
+
+func (a *Authenticator) saveToken(server string, tok *Token) error {
+	data, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	if err := a.store.Set(secretKeyPrefix+server, string(data)); err != nil {
+		return fmt.Errorf("store token: %w", err)
+	}
+	return nil
+}
+
+// refresh obtains a new access token using the refresh token.
+func (a *Authenticator) refresh(ctx context.Context, server string, tok *Token) (*Token, error) {
+	if tok.RefreshToken == "" {
+		return nil, fmt.Errorf("token for %s expired and cannot be refreshed", server)
+	}
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {tok.RefreshToken},
+	}
+	if tok.Resource != "" {
+		form.Set("resource", tok.Resource)
+	}
+	next := *tok
+	if err := a.exchange(ctx, &next, form); err != nil {
+		return nil, err
+	}
+	if next.RefreshToken == "" {
+		next.RefreshToken = tok.RefreshToken
+	}
+	if err := a.saveToken(server, &next); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// tokenResponse is the token endpoint response.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Scope            string `json:"scope"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchange posts form to the token endpoint and fills tok from the response.
+func (a *Authenticator) exchange(ctx context.Context, tok *Token, form url.Values) error {
+	form.Set("client_id", tok.ClientID)
+	if tok.ClientSecret != "" {
+		form.Set("client_secret", tok.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tok.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read token response: %w", err)
+	}
+
+	var tr tokenResponse
+	_ = json.Unmarshal(body, &tr)
+	if resp.StatusCode != http.StatusOK || tr.Error != "" {
+		if tr.Error != "" {
+			return fmt.Errorf("token request failed: %s %s", tr.Error, tr.ErrorDescription)
+		}
+		return fmt.Errorf("token request failed: status %d", resp.StatusCode)
+	}
+	if tr.AccessToken == "" {
+		return errors.New("token response has no access_token")
+	}
+
+	tok.AccessToken = tr.AccessToken
+	tok.TokenType = tr.TokenType
+	tok.RefreshToken = tr.RefreshToken
+	tok.Scope = tr.Scope
+	tok.Expiry = time.Time{}
+	if tr.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return nil
+}
+
+// TokenSource supplies the access token of one server, refreshing it when it
+// expires or is rejected.
+type TokenSource struct {
+	auth   *Authenticator
+	server string
+
+	token  *Token
+	loaded bool
+	mu     sync.Mutex
+}
+
+func (ts *TokenSource) set(tok *Token) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.token = tok
+	ts.loaded = true
+}
+
+// current returns the cached token, loading it from the store on first use.
+// Caller holds ts.mu.
+func (ts *TokenSource) current() (*Token, error) {
+	if !ts.loaded {
+		tok, err := ts.auth.Token(ts.server)
+		if err != nil && !errors.Is(err, ErrNotAuthorized) {
+			return nil, err
+		}
+		ts.token = tok
+		ts.loaded = true
+	}
+	return ts.token, nil
+}
+
+// Authorization returns the Authorization header value. Servers that were
+// never authorized get no header, so they answer 401 and the user is asked
+// to authorize.
+func (ts *TokenSource) Authorization(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	tok, err := ts.current()
+	if err != nil || tok == nil {
+		return "", err
+	}
+	if tok.Expired() {
+		next, err := ts.auth.refresh(ctx, ts.server, tok)
+		if err != nil {
+			return "", err
+		}
+		ts.token = next
+		tok = next
+	}
+	return "Bearer " + tok.AccessToken, nil
+}
+
+// HandleUnauthorized refreshes the token after the server rejected it.
+func (ts *TokenSource) HandleUnauthorized(ctx context.Context, resp *http.Response) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	tok, err := ts.current()
+	if err != nil || tok == nil {
+		return false
+	}
+	next, err := ts.auth.refresh(ctx, ts.server, tok)
+	if err != nil {
+		return false
+	}
+	ts.token = next
+	return true
+}
+
+// randomString returns n random bytes, base64url encoded.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// challenge returns the S256 PKCE code challenge for verifier.
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// canonicalResource returns the canonical resource URI of an MCP server:
+// lowercase scheme and host, no fragment or query, no trailing slash.
+func canonicalResource(serverURL string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return serverURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawQuery = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u.String()
+}
@@ -0,0 +1,208 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	data map[string]string
+	mu   sync.Mutex
+}
+
+func (m *memoryStore) Get(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key], nil
+}
+
+func (m *memoryStore) Set(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *memoryStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memoryStore) Available() bool { return true }
+
+// fakeAuthServer is an MCP server that is also its own authorization server.
+type fakeAuthServer struct {
+	*httptest.Server
+	challenge string
+	resource  string
+	refreshes int
+	issued    int
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+	f := &fakeAuthServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-protected-resource/mcp", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ProtectedResourceMetadata{
+			Resource:             f.URL + "/mcp",
+			AuthorizationServers: []string{f.URL},
+			ScopesSupported:      []string{"mcp"},
+		})
+	})
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(AuthServerMetadata{
+			Issuer:                f.URL,
+			AuthorizationEndpoint: f.URL + "/oauth/authorize",
+			TokenEndpoint:         f.URL + "/oauth/token",
+			RegistrationEndpoint:  f.URL + "/oauth/register",
+		})
+	})
+	mux.HandleFunc("/oauth/register", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(Registration{ClientID: "dyn-client"})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("client_id") != "dyn-client" || r.Form.Get("resource") != f.resource {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			if r.Form.Get("code") != "the-code" || challenge(r.Form.Get("code_verifier")) != f.challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+		case "refresh_token":
+			if r.Form.Get("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			f.refreshes++
+		}
+		f.issued++
+		resp := map[string]any{
+			"access_token": "access-" + string(rune('0'+f.issued)),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		}
+		if f.issued == 1 {
+			resp["refresh_token"] = "refresh-1"
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	f.Server = httptest.NewServer(mux)
+	f.resource = f.URL + "/mcp"
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestAuthenticator_Flow(t *testing.T) {
+	srv := newFakeAuthServer(t)
+	store := &memoryStore{data: map[string]string{}}
+	auth := NewAuthenticator(store)
+	ctx := context.Background()
+
+	ts := auth.TokenSource("remote")
+	if v, err := ts.Authorization(ctx); err != nil || v != "" {
+		t.Fatalf("unauthorized server: %q, %v", v, err)
+	}
+
+	authURL, err := auth.Begin(ctx, "remote", srv.URL+"/mcp/", nil, "http://localhost:18788/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if u.Path != "/oauth/authorize" || q.Get("client_id") != "dyn-client" || q.Get("code_challenge_method") != "S256" ||
+		q.Get("resource") != srv.resource || q.Get("scope") != "mcp" {
+		t.Fatalf("unexpected authorization URL: %s", authURL)
+	}
+	srv.challenge = q.Get("code_challenge")
+
+	if _, err := auth.Complete(ctx, "bogus", "the-code"); err != ErrUnknownState {
+		t.Errorf("unknown state: got %v", err)
+	}
+	server, err := auth.Complete(ctx, q.Get("state"), "the-code")
+	if err != nil || server != "remote" {
+		t.Fatalf("Complete = %q, %v", server, err)
+	}
+	if _, err := auth.Complete(ctx, q.Get("state"), "the-code"); err != ErrUnknownState {
+		t.Errorf("state reused: got %v", err)
+	}
+
+	if v, _ := ts.Authorization(ctx); v != "Bearer access-1" {
+		t.Errorf("Authorization = %q", v)
+	}
+	if store.data[secretKeyPrefix+"remote"] == "" {
+		t.Error("token not persisted")
+	}
+
+	// A rejected token is refreshed, keeping the refresh token.
+	if !ts.HandleUnauthorized(ctx, nil) {
+		t.Fatal("refresh failed")
+	}
+	if v, _ := ts.Authorization(ctx); v != "Bearer access-2" {
+		t.Errorf("Authorization after refresh = %q", v)
+	}
+	tok, err := auth.Token("remote")
+	if err != nil || tok.RefreshToken != "refresh-1" || tok.AccessToken != "access-2" {
+		t.Errorf("stored token after refresh: %+v, %v", tok, err)
+	}
+
+	// Expired tokens are refreshed transparently, also after a restart.
+	tok.Expiry = time.Now().Add(-time.Minute)
+	_ = auth.saveToken("remote", tok)
+	restarted := NewAuthenticator(store)
+	if v, _ := restarted.TokenSource("remote").Authorization(ctx); v != "Bearer access-3" {
+		t.Errorf("Authorization with expired token = %q", v)
+	}
+	if srv.refreshes != 2 {
+		t.Errorf("refreshes = %d, want 2", srv.refreshes)
+	}
+
+	if err := restarted.Revoke("remote"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := restarted.TokenSource("remote").Authorization(ctx); v != "" {
+		t.Errorf("Authorization after revoke = %q", v)
+	}
+}
+
+func TestDiscover_Fallback(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	meta, err := Discover(context.Background(), srv.Client(), srv.URL+"/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.AuthorizationEndpoint != srv.URL+"/authorize" || meta.TokenEndpoint != srv.URL+"/token" ||
+		meta.RegistrationEndpoint != srv.URL+"/register" {
+		t.Errorf("unexpected fallback metadata: %+v", meta)
+	}
+}
+
+func TestCanonicalResource(t *testing.T) {
+	tests := map[string]string{
+		"HTTPS://Example.com/mcp/":      "https://example.com/mcp",
+		"https://example.com/mcp?x=1#f": "https://example.com/mcp",
+		"https://example.com":           "https://example.com",
+	}
+	for in, want := range tests {
+		if got := canonicalResource(in); got != want {
+			t.Errorf("canonicalResource(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
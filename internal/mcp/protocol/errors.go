@@ -16,6 +16,7 @@ const (
 	ErrCodeToolNotFound        = -32001 // Tool not found
 	ErrCodeToolExecutionFailed = -32002 // Tool execution failed
 	ErrCodeNotInitialized      = -32003 // Server not initialized
+	ErrCodeResourceNotFound    = -32004 // Resource not found
 )
 
 // NewParseError creates a parse error response.
@@ -83,3 +84,11 @@ func NewNotInitializedError() *RPCError {
 		Message: "Server not initialized",
 	}
 }
+
+// NewResourceNotFoundError creates a resource not found error response.
+func NewResourceNotFoundError(uri string) *RPCError {
+	return &RPCError{
+		Code:    ErrCodeResourceNotFound,
+		Message: fmt.Sprintf("Resource not found: %s", uri),
+	}
+}
@@ -12,10 +12,57 @@ const (
 	MethodPromptsGet  = "prompts/get"
 	MethodPing        = "ping"
 	MethodCancelled   = "notifications/cancelled"
+
+	MethodToolsListChanged   = "notifications/tools/list_changed"
+	MethodPromptsListChanged = "notifications/prompts/list_changed"
+	MethodProgress           = "notifications/progress"
+	MethodLoggingMessage     = "notifications/message"
+
+	MethodResourcesList          = "resources/list"
+	MethodResourcesRead          = "resources/read"
+	MethodResourcesTemplatesList = "resources/templates/list"
+	MethodResourcesSubscribe     = "resources/subscribe"
+	MethodResourcesUnsubscribe   = "resources/unsubscribe"
+	MethodResourcesUpdated       = "notifications/resources/updated"
+	MethodResourcesListChanged   = "notifications/resources/list_changed"
+
+	// Server-initiated requests handled by the client.
+	MethodSamplingCreateMessage = "sampling/createMessage"
+	MethodElicitationCreate     = "elicitation/create"
+	MethodRootsList             = "roots/list"
+	MethodRootsListChanged      = "notifications/roots/list_changed"
+)
+
+// MCP protocol versions.
+const (
+	// ProtocolVersion is the baseline protocol version, spoken by every peer.
+	ProtocolVersion = "2024-11-05"
+	// LatestProtocolVersion is the newest protocol version Mote implements.
+	LatestProtocolVersion = "2025-06-18"
 )
 
-// MCP protocol version.
-const ProtocolVersion = "2024-11-05"
+// SupportedProtocolVersions lists the protocol versions Mote speaks, newest first.
+var SupportedProtocolVersions = []string{LatestProtocolVersion, "2025-03-26", ProtocolVersion}
+
+// IsSupportedVersion reports whether version is in SupportedProtocolVersions.
+func IsSupportedVersion(version string) bool {
+	for _, v := range SupportedProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateVersion returns the version a server answers an initialize request
+// with: the requested version when supported, otherwise the latest one. The
+// client then decides whether it can speak the answered version.
+func NegotiateVersion(requested string) string {
+	if IsSupportedVersion(requested) {
+		return requested
+	}
+	return LatestProtocolVersion
+}
 
 // InitializeParams represents the parameters for the initialize request.
 type InitializeParams struct {
@@ -48,6 +95,21 @@ type Capabilities struct {
 	// Tools capability indicates support for tool-related operations.
 	Tools *ToolsCapability `json:"tools,omitempty"`
 
+	// Resources capability indicates support for resource-related operations.
+	Resources *ResourcesCapability `json:"resources,omitempty"`
+
+	// Prompts capability indicates support for prompt-related operations.
+	Prompts *PromptsCapability `json:"prompts,omitempty"`
+
+	// Sampling indicates the client can serve sampling/createMessage requests.
+	Sampling *SamplingCapability `json:"sampling,omitempty"`
+
+	// Elicitation indicates the client can serve elicitation/create requests.
+	Elicitation *ElicitationCapability `json:"elicitation,omitempty"`
+
+	// Roots indicates the client can serve roots/list requests.
+	Roots *RootsCapability `json:"roots,omitempty"`
+
 	// Experimental contains experimental capabilities.
 	Experimental map[string]any `json:"experimental,omitempty"`
 }
@@ -58,11 +120,48 @@ type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// ResourcesCapability declares resource-related capabilities.
+type ResourcesCapability struct {
+	// Subscribe indicates the server supports resources/subscribe.
+	Subscribe bool `json:"subscribe,omitempty"`
+	// ListChanged indicates the server will send notifications when the resource list changes.
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// PromptsCapability declares prompt-related capabilities.
+type PromptsCapability struct {
+	// ListChanged indicates the server will send notifications when prompts change.
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// SamplingCapability declares that the client serves sampling requests.
+type SamplingCapability struct{}
+
+// ElicitationCapability declares that the client serves elicitation requests.
+type ElicitationCapability struct{}
+
+// RootsCapability declares roots-related client capabilities.
+type RootsCapability struct {
+	// ListChanged indicates the client will send notifications when roots change.
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
 // Tool represents an MCP tool definition.
 type Tool struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description,omitempty"`
-	InputSchema json.RawMessage `json:"inputSchema"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	InputSchema json.RawMessage  `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are hints about a tool's behavior. They are advisory and
+// come from the server, so clients must not rely on them for security.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
 }
 
 // ListToolsParams represents parameters for tools/list request.
@@ -80,6 +179,27 @@ type ListToolsResult struct {
 type CallToolParams struct {
 	Name      string         `json:"name"`
 	Arguments map[string]any `json:"arguments,omitempty"`
+	Meta      *RequestMeta   `json:"_meta,omitempty"`
+}
+
+// RequestMeta carries request metadata such as the progress token.
+type RequestMeta struct {
+	ProgressToken any `json:"progressToken,omitempty"`
+}
+
+// ProgressParams represents parameters for notifications/progress.
+type ProgressParams struct {
+	ProgressToken any     `json:"progressToken"`
+	Progress      float64 `json:"progress"`
+	Total         float64 `json:"total,omitempty"`
+	Message       string  `json:"message,omitempty"`
+}
+
+// LoggingMessageParams represents parameters for notifications/message.
+type LoggingMessageParams struct {
+	Level  string          `json:"level"`
+	Logger string          `json:"logger,omitempty"`
+	Data   json.RawMessage `json:"data"`
 }
 
 // CallToolResult represents the result of tools/call request.
@@ -191,6 +311,138 @@ type CancelledParams struct {
 	Reason    string `json:"reason,omitempty"`
 }
 
+// Resource represents an MCP resource definition.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate describes a parameterized resource URI (RFC 6570).
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceContents is the content of a resource. Exactly one of Text and
+// Blob (base64) is set.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// ListResourcesParams represents parameters for resources/list request.
+type ListResourcesParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ListResourcesResult represents the result of resources/list request.
+type ListResourcesResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor *string    `json:"nextCursor,omitempty"`
+}
+
+// ListResourceTemplatesResult represents the result of resources/templates/list request.
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        *string            `json:"nextCursor,omitempty"`
+}
+
+// ReadResourceParams represents parameters for resources/read request.
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ReadResourceResult represents the result of resources/read request.
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// SubscribeParams represents parameters for resources/subscribe and
+// resources/unsubscribe requests.
+type SubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams represents parameters for notifications/resources/updated.
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
+// SamplingMessage is a message in a sampling/createMessage request or result.
+type SamplingMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// ModelHint suggests a model by (partial) name.
+type ModelHint struct {
+	Name string `json:"name,omitempty"`
+}
+
+// ModelPreferences expresses the server's model preferences for sampling.
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         float64     `json:"costPriority,omitempty"`
+	SpeedPriority        float64     `json:"speedPriority,omitempty"`
+	IntelligencePriority float64     `json:"intelligencePriority,omitempty"`
+}
+
+// CreateMessageParams represents parameters for sampling/createMessage request.
+type CreateMessageParams struct {
+	Messages         []SamplingMessage `json:"messages"`
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
+	IncludeContext   string            `json:"includeContext,omitempty"`
+	Temperature      *float64          `json:"temperature,omitempty"`
+	MaxTokens        int               `json:"maxTokens"`
+	StopSequences    []string          `json:"stopSequences,omitempty"`
+	Metadata         map[string]any    `json:"metadata,omitempty"`
+}
+
+// CreateMessageResult represents the result of sampling/createMessage request.
+type CreateMessageResult struct {
+	Role       string  `json:"role"`
+	Content    Content `json:"content"`
+	Model      string  `json:"model"`
+	StopReason string  `json:"stopReason,omitempty"`
+}
+
+// Elicitation actions.
+const (
+	ElicitActionAccept  = "accept"
+	ElicitActionDecline = "decline"
+	ElicitActionCancel  = "cancel"
+)
+
+// ElicitParams represents parameters for elicitation/create request.
+type ElicitParams struct {
+	Message         string          `json:"message"`
+	RequestedSchema json.RawMessage `json:"requestedSchema,omitempty"`
+}
+
+// ElicitResult represents the result of elicitation/create request.
+type ElicitResult struct {
+	Action  string         `json:"action"`
+	Content map[string]any `json:"content,omitempty"`
+}
+
+// Root is a directory the client exposes to the server.
+type Root struct {
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+}
+
+// ListRootsResult represents the result of roots/list request.
+type ListRootsResult struct {
+	Roots []Root `json:"roots"`
+}
+
 // ToolInputSchema creates a JSON Schema for tool parameters.
 func ToolInputSchema(properties map[string]any, required []string) json.RawMessage {
 	schema := map[string]any{
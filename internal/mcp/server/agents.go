@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mote/internal/config"
+	"mote/internal/runner/delegate"
+	"mote/internal/runner/types"
+	"mote/internal/tools"
+)
+
+// agentToolPrefix prefixes the MCP tools that run Mote agents.
+const agentToolPrefix = "run_agent_"
+
+// AgentRunner runs a configured Mote agent on behalf of an MCP client.
+// Progress is reported through tools.ReportProgress on ctx.
+type AgentRunner interface {
+	RunAgent(ctx context.Context, name, input string) (string, error)
+}
+
+// AgentTool exposes a Mote agent as a run_agent_<name> tool.
+type AgentTool struct {
+	tools.BaseTool
+	agent  string
+	runner AgentRunner
+}
+
+// NewAgentTool creates the tool that runs agent through runner.
+func NewAgentTool(agent, description string, runner AgentRunner) *AgentTool {
+	if description == "" {
+		description = "Run the Mote agent " + agent + "."
+	}
+	return &AgentTool{
+		BaseTool: tools.BaseTool{
+			ToolName:        AgentToolName(agent),
+			ToolDescription: description + " The agent works on the task with its own tools and returns its final answer.",
+			ToolParameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"input": map[string]any{
+						"type":        "string",
+						"description": "The task or question for the agent",
+					},
+				},
+				"required": []string{"input"},
+			},
+		},
+		agent:  agent,
+		runner: runner,
+	}
+}
+
+// AgentToolName returns the MCP tool name for an agent, replacing characters
+// that are not allowed in tool names.
+func AgentToolName(agent string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, agent)
+	return agentToolPrefix + name
+}
+
+// Execute runs the agent with the given input.
+func (t *AgentTool) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	input, _ := args["input"].(string)
+	if strings.TrimSpace(input) == "" {
+		return tools.NewErrorResult("input is required"), nil
+	}
+	result, err := t.runner.RunAgent(ctx, t.agent, input)
+	if err != nil {
+		return tools.NewErrorResult(fmt.Sprintf("agent %s failed: %v", t.agent, err)), nil
+	}
+	return tools.NewSuccessResult(result), nil
+}
+
+// FactoryAgentRunner runs agents through the delegate SubRunnerFactory, the
+// same path used for @-mentions, so agents with steps go through the PDA
+// engine. Agent configs are looked up at call time.
+type FactoryAgentRunner struct {
+	factory *delegate.SubRunnerFactory
+}
+
+// NewFactoryAgentRunner creates an AgentRunner backed by factory.
+func NewFactoryAgentRunner(factory *delegate.SubRunnerFactory) *FactoryAgentRunner {
+	return &FactoryAgentRunner{factory: factory}
+}
+
+// RunAgent implements AgentRunner.
+func (r *FactoryAgentRunner) RunAgent(ctx context.Context, name, input string) (string, error) {
+	appCfg := config.GetConfig()
+	if appCfg == nil {
+		return "", fmt.Errorf("config not available")
+	}
+	agentCfg, ok := appCfg.Agents[name]
+	if !ok {
+		return "", fmt.Errorf("agent %q not found", name)
+	}
+	if !agentCfg.IsEnabled() {
+		return "", fmt.Errorf("agent %q is disabled", name)
+	}
+
+	dc := &delegate.DelegateContext{
+		Depth:           0,
+		MaxDepth:        appCfg.Delegate.GetMaxDepth(),
+		ParentSessionID: "mcp",
+		AgentName:       name,
+		Chain:           []string{name},
+	}
+	sink := delegate.ParentEventSink(func(event types.Event) {
+		switch {
+		case event.Type == types.EventTypeToolCall && event.ToolCall != nil:
+			tools.ReportProgress(ctx, fmt.Sprintf("%s: calling %s", agentLabel(event, name), event.ToolCall.GetName()))
+		case event.Type == types.EventTypeToolCallUpdate && event.ToolCallUpdate != nil && event.ToolCallUpdate.Message != "":
+			tools.ReportProgress(ctx, fmt.Sprintf("%s: %s", agentLabel(event, name), event.ToolCallUpdate.Message))
+		}
+	})
+
+	var result string
+	var err error
+	if agentCfg.HasSteps() {
+		result, _, err = r.factory.RunPDAWithEvents(ctx, dc, agentCfg, input, sink)
+	} else {
+		result, _, err = r.factory.RunDelegateWithEvents(ctx, dc, agentCfg, input, sink)
+	}
+	return result, err
+}
+
+// agentLabel names the agent an event came from, which may be a nested delegate.
+func agentLabel(event types.Event, fallback string) string {
+	if event.AgentName != "" {
+		return event.AgentName
+	}
+	return fallback
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"mote/internal/mcp/protocol"
+	"mote/internal/memory"
+	"mote/internal/tools"
+)
+
+type fakeAgentRunner struct{}
+
+func (fakeAgentRunner) RunAgent(ctx context.Context, name, input string) (string, error) {
+	tools.ReportProgress(ctx, name+": calling search")
+	return name + " answered " + input, nil
+}
+
+func TestAgentToolName(t *testing.T) {
+	if got := AgentToolName("code.reviewer v2"); got != "run_agent_code_reviewer_v2" {
+		t.Errorf("AgentToolName = %q", got)
+	}
+}
+
+func TestAgentTool_CallWithProgress(t *testing.T) {
+	registry := tools.NewRegistry()
+	if err := registry.Register(NewAgentTool("researcher", "Finds things.", fakeAgentRunner{})); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer("test", "1.0", WithRegistry(registry))
+	s.setInitialized(true)
+	mockT := newMockTransport()
+	s.transport = mockT
+
+	resp := callHandler(t, s, protocol.MethodToolsCall, protocol.CallToolParams{
+		Name:      "run_agent_researcher",
+		Arguments: map[string]any{"input": "go generics"},
+		Meta:      &protocol.RequestMeta{ProgressToken: "tok-1"},
+	})
+	if resp.Error != nil {
+		t.Fatalf("tools/call: %v", resp.Error)
+	}
+	var result protocol.CallToolResult
+	data, _ := json.Marshal(resp.Result)
+	_ = json.Unmarshal(data, &result)
+	if result.IsError || len(result.Content) != 1 || result.Content[0].Text != "researcher answered go generics" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	select {
+	case data := <-mockT.sendCh:
+		msg, _ := protocol.ParseMessage(data)
+		var p protocol.ProgressParams
+		_ = json.Unmarshal(msg.Params, &p)
+		if msg.Method != protocol.MethodProgress || p.ProgressToken != "tok-1" || p.Progress != 1 ||
+			p.Message != "researcher: calling search" {
+			t.Errorf("unexpected progress notification: %s", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no progress notification sent")
+	}
+}
+
+type fakeMemoryIndex struct {
+	added []memory.MemoryEntry
+}
+
+func (f *fakeMemoryIndex) Search(ctx context.Context, query string, topK int) ([]memory.SearchResult, error) {
+	var out []memory.SearchResult
+	for _, e := range f.added {
+		if strings.Contains(e.Content, query) && len(out) < topK {
+			out = append(out, memory.SearchResult{ID: e.ID, Content: e.Content, Category: e.Category})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeMemoryIndex) Add(ctx context.Context, entry memory.MemoryEntry) error {
+	f.added = append(f.added, entry)
+	return nil
+}
+
+func TestMemoryTools(t *testing.T) {
+	index := &fakeMemoryIndex{}
+	memTools := NewMemoryTools(index)
+	if len(memTools) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(memTools))
+	}
+	search, add := memTools[0], memTools[1]
+	ctx := context.Background()
+
+	res, _ := add.Execute(ctx, map[string]any{"content": "User prefers tabs", "category": "preference"})
+	if res.IsError || len(index.added) != 1 || index.added[0].Source != "mcp" || index.added[0].ID == "" {
+		t.Fatalf("memory_add: %+v, %+v", res, index.added)
+	}
+	if res, _ := add.Execute(ctx, map[string]any{}); !res.IsError {
+		t.Error("memory_add without content should fail")
+	}
+	if res, _ := add.Execute(ctx, map[string]any{"content": "x", "category": "system"}); !res.IsError || len(index.added) != 1 {
+		t.Error("memory_add with an unknown category should fail")
+	}
+
+	res, _ = search.Execute(ctx, map[string]any{"query": "tabs"})
+	if res.IsError || !strings.Contains(res.Content, "User prefers tabs") || !strings.Contains(res.Content, "preference") {
+		t.Errorf("memory_search: %+v", res)
+	}
+	res, _ = search.Execute(ctx, map[string]any{"query": "spaces"})
+	if res.Content != "No matching memories." {
+		t.Errorf("memory_search without matches: %+v", res)
+	}
+}
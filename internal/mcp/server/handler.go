@@ -3,9 +3,10 @@ package server
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"sync/atomic"
 
 	"mote/internal/mcp/protocol"
+	"mote/internal/tools"
 )
 
 // HandlerFunc is a function that handles an MCP method.
@@ -33,6 +34,13 @@ func (h *MethodHandler) registerHandlers() {
 	h.handlers[protocol.MethodToolsList] = h.handleToolsList
 	h.handlers[protocol.MethodToolsCall] = h.handleToolsCall
 	h.handlers[protocol.MethodPing] = h.handlePing
+	h.handlers[protocol.MethodPromptsList] = h.handlePromptsList
+	h.handlers[protocol.MethodPromptsGet] = h.handlePromptsGet
+	h.handlers[protocol.MethodResourcesList] = h.handleResourcesList
+	h.handlers[protocol.MethodResourcesTemplatesList] = h.handleResourceTemplatesList
+	h.handlers[protocol.MethodResourcesRead] = h.handleResourcesRead
+	h.handlers[protocol.MethodResourcesSubscribe] = h.handleResourcesSubscribe
+	h.handlers[protocol.MethodResourcesUnsubscribe] = h.handleResourcesUnsubscribe
 }
 
 // HandleRequest handles a request message and returns a response.
@@ -82,12 +90,12 @@ func (h *MethodHandler) handleInitialize(ctx context.Context, params json.RawMes
 		return nil, protocol.NewInvalidParamsError(err.Error())
 	}
 
-	// Validate protocol version
-	if initParams.ProtocolVersion != protocol.ProtocolVersion {
-		return nil, protocol.NewInvalidParamsError(
-			fmt.Sprintf("unsupported protocol version: %s, expected: %s",
-				initParams.ProtocolVersion, protocol.ProtocolVersion))
+	if initParams.ProtocolVersion == "" {
+		return nil, protocol.NewInvalidParamsError("protocolVersion is required")
 	}
+	// Answer with the requested version when supported, otherwise with our
+	// latest one and let the client decide whether to continue.
+	version := protocol.NegotiateVersion(initParams.ProtocolVersion)
 
 	// Mark as initialized
 	h.server.setInitialized(true)
@@ -98,10 +106,21 @@ func (h *MethodHandler) handleInitialize(ctx context.Context, params json.RawMes
 			ListChanged: false,
 		},
 	}
+	if h.server.HasResources() {
+		capabilities.Resources = &protocol.ResourcesCapability{
+			Subscribe:   true,
+			ListChanged: true,
+		}
+	}
+	if h.server.HasPrompts() {
+		capabilities.Prompts = &protocol.PromptsCapability{
+			ListChanged: true,
+		}
+	}
 
 	// Build result
 	result := protocol.InitializeResult{
-		ProtocolVersion: protocol.ProtocolVersion,
+		ProtocolVersion: version,
 		ServerInfo: protocol.ServerInfo{
 			Name:    h.server.Name(),
 			Version: h.server.Version(),
@@ -140,6 +159,19 @@ func (h *MethodHandler) handleToolsCall(ctx context.Context, params json.RawMess
 		return nil, protocol.NewInvalidParamsError("tool name is required")
 	}
 
+	// Relay progress reported by the tool when the client asked for it
+	if callParams.Meta != nil && callParams.Meta.ProgressToken != nil {
+		token := callParams.Meta.ProgressToken
+		var step int64
+		ctx = tools.WithProgress(ctx, func(message string) {
+			_ = h.server.notify(protocol.MethodProgress, protocol.ProgressParams{
+				ProgressToken: token,
+				Progress:      float64(atomic.AddInt64(&step, 1)),
+				Message:       message,
+			})
+		})
+	}
+
 	// Execute the tool
 	result, err := h.server.mapper.Execute(ctx, callParams.Name, callParams.Arguments)
 	if err != nil {
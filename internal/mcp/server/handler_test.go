@@ -73,11 +73,36 @@ func TestMethodHandler_HandleRequest_Initialize_WrongVersion(t *testing.T) {
 
 	resp := s.handler.HandleRequest(context.Background(), req)
 
-	if resp.Error == nil {
-		t.Fatal("Expected error for wrong protocol version")
+	// Unsupported versions are answered with the latest supported version.
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
 	}
-	if resp.Error.Code != protocol.ErrCodeInvalidParams {
-		t.Errorf("Error code: got %d, want %d", resp.Error.Code, protocol.ErrCodeInvalidParams)
+	var result protocol.InitializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result.ProtocolVersion != protocol.LatestProtocolVersion {
+		t.Errorf("ProtocolVersion: got %q, want %q", result.ProtocolVersion, protocol.LatestProtocolVersion)
+	}
+}
+
+func TestMethodHandler_HandleRequest_Initialize_NegotiatesVersion(t *testing.T) {
+	for _, version := range protocol.SupportedProtocolVersions {
+		s := NewServer("test-server", "1.0.0")
+		paramsJSON, _ := json.Marshal(protocol.InitializeParams{ProtocolVersion: version})
+		resp := s.handler.HandleRequest(context.Background(), &protocol.Request{
+			Jsonrpc: "2.0",
+			ID:      1,
+			Method:  protocol.MethodInitialize,
+			Params:  paramsJSON,
+		})
+		var result protocol.InitializeResult
+		if resp.Error != nil || json.Unmarshal(resp.Result, &result) != nil {
+			t.Fatalf("%s: unexpected response %+v", version, resp)
+		}
+		if result.ProtocolVersion != version {
+			t.Errorf("ProtocolVersion: got %q, want %q", result.ProtocolVersion, version)
+		}
 	}
 }
 
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mote/internal/memory"
+	"mote/internal/tools"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultMemorySearchLimit is the number of results memory_search returns by default.
+	defaultMemorySearchLimit = 5
+	// maxMemorySearchLimit caps the limit a client may ask memory_search for.
+	maxMemorySearchLimit = 50
+)
+
+// MemoryIndex is the subset of the memory index used by the memory tools.
+type MemoryIndex interface {
+	Search(ctx context.Context, query string, topK int) ([]memory.SearchResult, error)
+	Add(ctx context.Context, entry memory.MemoryEntry) error
+}
+
+// NewMemoryTools returns the memory_search and memory_add tools backed by index.
+func NewMemoryTools(index MemoryIndex) []tools.Tool {
+	return []tools.Tool{
+		&MemorySearchTool{
+			BaseTool: tools.BaseTool{
+				ToolName:        "memory_search",
+				ToolDescription: "Search Mote's long-term memory for facts, preferences and decisions.",
+				ToolParameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"query": map[string]any{
+							"type":        "string",
+							"description": "What to look for",
+						},
+						"limit": map[string]any{
+							"type":        "integer",
+							"description": fmt.Sprintf("Maximum number of results (default %d)", defaultMemorySearchLimit),
+						},
+					},
+					"required": []string{"query"},
+				},
+			},
+			index: index,
+		},
+		&MemoryAddTool{
+			BaseTool: tools.BaseTool{
+				ToolName:        "memory_add",
+				ToolDescription: "Store a fact, preference or decision in Mote's long-term memory.",
+				ToolParameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"content": map[string]any{
+							"type":        "string",
+							"description": "The text to remember",
+						},
+						"category": map[string]any{
+							"type":        "string",
+							"enum":        []string{memory.CategoryPreference, memory.CategoryFact, memory.CategoryDecision, memory.CategoryEntity, memory.CategoryOther},
+							"description": "One of preference, fact, decision, entity or other",
+						},
+					},
+					"required": []string{"content"},
+				},
+			},
+			index: index,
+		},
+	}
+}
+
+// MemorySearchTool searches the memory index.
+type MemorySearchTool struct {
+	tools.BaseTool
+	index MemoryIndex
+}
+
+// Execute searches memory.
+func (t *MemorySearchTool) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	query, _ := args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return tools.NewErrorResult("query is required"), nil
+	}
+	limit := defaultMemorySearchLimit
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = min(int(v), maxMemorySearchLimit)
+	}
+
+	results, err := t.index.Search(ctx, query, limit)
+	if err != nil {
+		return tools.NewErrorResult(fmt.Sprintf("memory search failed: %v", err)), nil
+	}
+	if len(results) == 0 {
+		return tools.NewSuccessResult("No matching memories."), nil
+	}
+
+	var b strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&b, "- [%s] %s", r.ID, strings.TrimSpace(r.Content))
+		if desc := memoryDescription(r); desc != "" {
+			fmt.Fprintf(&b, " (%s)", desc)
+		}
+		b.WriteString("\n")
+	}
+	return tools.NewSuccessResult(b.String()), nil
+}
+
+// MemoryAddTool adds an entry to the memory index.
+type MemoryAddTool struct {
+	tools.BaseTool
+	index MemoryIndex
+}
+
+// Execute stores a memory entry.
+func (t *MemoryAddTool) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	content, _ := args["content"].(string)
+	if strings.TrimSpace(content) == "" {
+		return tools.NewErrorResult("content is required"), nil
+	}
+	category, _ := args["category"].(string)
+	switch category {
+	case "":
+		category = memory.CategoryOther
+	case memory.CategoryPreference, memory.CategoryFact, memory.CategoryDecision, memory.CategoryEntity, memory.CategoryOther:
+	default:
+		return tools.NewErrorResult(fmt.Sprintf("unknown category %q: use preference, fact, decision, entity or other", category)), nil
+	}
+
+	entry := memory.MemoryEntry{
+		ID:            uuid.New().String(),
+		Content:       content,
+		Source:        "mcp",
+		Category:      category,
+		CaptureMethod: memory.CaptureMethodManual,
+	}
+	if err := t.index.Add(ctx, entry); err != nil {
+		return tools.NewErrorResult(fmt.Sprintf("failed to store memory: %v", err)), nil
+	}
+	return tools.NewSuccessResult("Stored memory " + entry.ID), nil
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"mote/internal/mcp/protocol"
+	"mote/internal/prompts"
+)
+
+// Exposed reports whether name matches one of the exposure patterns. "*"
+// matches everything and other patterns are globs; an empty list exposes
+// nothing.
+func Exposed(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if p == "*" || p == name {
+			return true
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// WithPrompts publishes the enabled prompts of lib whose names match expose
+// as MCP prompts.
+func WithPrompts(lib *prompts.Manager, expose []string) ServerOption {
+	return func(s *Server) {
+		s.prompts = lib
+		s.promptExpose = expose
+	}
+}
+
+// HasPrompts reports whether a prompt library is configured.
+func (s *Server) HasPrompts() bool {
+	return s.prompts != nil
+}
+
+// exposedPrompts returns the published prompts, highest priority first.
+func (s *Server) exposedPrompts() []*prompts.Prompt {
+	if s.prompts == nil {
+		return nil
+	}
+	var out []*prompts.Prompt
+	for _, p := range s.prompts.GetEnabledPrompts() {
+		if Exposed(s.promptExpose, p.Name) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// NotifyPromptListChanged tells the client that the set of prompts changed.
+func (s *Server) NotifyPromptListChanged() error {
+	if !s.HasPrompts() {
+		return nil
+	}
+	return s.notify(protocol.MethodPromptsListChanged, nil)
+}
+
+// handlePromptsList handles the prompts/list method.
+func (h *MethodHandler) handlePromptsList(ctx context.Context, params json.RawMessage) (any, error) {
+	result := protocol.ListPromptsResult{Prompts: []protocol.Prompt{}}
+	for _, p := range h.server.exposedPrompts() {
+		mp := protocol.Prompt{
+			Name:        p.Name,
+			Description: p.Description,
+		}
+		for _, a := range p.Arguments {
+			mp.Arguments = append(mp.Arguments, protocol.PromptArgument{
+				Name:        a.Name,
+				Description: a.Description,
+				Required:    a.Required,
+			})
+		}
+		result.Prompts = append(result.Prompts, mp)
+	}
+	return result, nil
+}
+
+// handlePromptsGet handles the prompts/get method, filling {{name}}
+// placeholders with the given arguments.
+func (h *MethodHandler) handlePromptsGet(ctx context.Context, params json.RawMessage) (any, error) {
+	var getParams protocol.GetPromptParams
+	if err := json.Unmarshal(params, &getParams); err != nil {
+		return nil, protocol.NewInvalidParamsError(err.Error())
+	}
+	if getParams.Name == "" {
+		return nil, protocol.NewInvalidParamsError("prompt name is required")
+	}
+
+	for _, p := range h.server.exposedPrompts() {
+		if p.Name != getParams.Name {
+			continue
+		}
+		content := p.Content
+		for _, a := range p.Arguments {
+			value, ok := getParams.Arguments[a.Name]
+			if !ok && a.Required {
+				return nil, protocol.NewInvalidParamsError(fmt.Sprintf("missing required argument: %s", a.Name))
+			}
+			content = strings.ReplaceAll(content, "{{"+a.Name+"}}", value)
+		}
+		role := "user"
+		if p.Type == prompts.PromptTypeAssistant {
+			role = "assistant"
+		}
+		return protocol.GetPromptResult{
+			Description: p.Description,
+			Messages: []protocol.PromptMessage{{
+				Role:    role,
+				Content: protocol.PromptContent{Type: protocol.ContentTypeText, Text: content},
+			}},
+		}, nil
+	}
+	return nil, protocol.NewInvalidParamsError("prompt not found: " + getParams.Name)
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"mote/internal/mcp/protocol"
+	"mote/internal/prompts"
+)
+
+func TestExposed(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		name     string
+		want     bool
+	}{
+		{nil, "read_file", false},
+		{[]string{"*"}, "read_file", true},
+		{[]string{"read_file"}, "read_file", true},
+		{[]string{"mcp_*"}, "mcp_call", true},
+		{[]string{"mcp_*"}, "shell", false},
+	}
+	for _, tt := range tests {
+		if got := Exposed(tt.patterns, tt.name); got != tt.want {
+			t.Errorf("Exposed(%v, %q) = %v, want %v", tt.patterns, tt.name, got, tt.want)
+		}
+	}
+}
+
+func newPromptTestServer(t *testing.T) *Server {
+	t.Helper()
+	lib := prompts.NewManager()
+	for _, cfg := range []prompts.PromptConfig{
+		{Name: "review", Description: "Review code", Type: prompts.PromptTypeUser, Enabled: true,
+			Content: "Review {{file}} for {{focus}}.",
+			Arguments: []prompts.PromptArgument{
+				{Name: "file", Required: true},
+				{Name: "focus"},
+			}},
+		{Name: "internal-notes", Type: prompts.PromptTypeSystem, Content: "secret", Enabled: true},
+		{Name: "disabled", Type: prompts.PromptTypeUser, Content: "off", Enabled: false},
+	} {
+		if _, err := lib.AddPrompt(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewServer("test", "1.0", WithPrompts(lib, []string{"review", "disabled"}))
+	s.setInitialized(true)
+	return s
+}
+
+func TestPrompts_List(t *testing.T) {
+	s := newPromptTestServer(t)
+
+	resp := callHandler(t, s, protocol.MethodPromptsList, nil)
+	if resp.Error != nil {
+		t.Fatalf("prompts/list: %v", resp.Error)
+	}
+	var result protocol.ListPromptsResult
+	data, _ := json.Marshal(resp.Result)
+	_ = json.Unmarshal(data, &result)
+
+	if len(result.Prompts) != 1 || result.Prompts[0].Name != "review" {
+		t.Fatalf("unexpected prompts: %+v", result.Prompts)
+	}
+	if args := result.Prompts[0].Arguments; len(args) != 2 || !args[0].Required {
+		t.Errorf("unexpected arguments: %+v", args)
+	}
+}
+
+func TestPrompts_Get(t *testing.T) {
+	s := newPromptTestServer(t)
+
+	resp := callHandler(t, s, protocol.MethodPromptsGet, protocol.GetPromptParams{
+		Name:      "review",
+		Arguments: map[string]string{"file": "main.go", "focus": "races"},
+	})
+	if resp.Error != nil {
+		t.Fatalf("prompts/get: %v", resp.Error)
+	}
+	var result protocol.GetPromptResult
+	data, _ := json.Marshal(resp.Result)
+	_ = json.Unmarshal(data, &result)
+	if len(result.Messages) != 1 || result.Messages[0].Role != "user" ||
+		result.Messages[0].Content.Text != "Review main.go for races." {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	resp = callHandler(t, s, protocol.MethodPromptsGet, protocol.GetPromptParams{Name: "review"})
+	if resp.Error == nil {
+		t.Error("expected error for missing required argument")
+	}
+	resp = callHandler(t, s, protocol.MethodPromptsGet, protocol.GetPromptParams{Name: "internal-notes"})
+	if resp.Error == nil {
+		t.Error("unexposed prompt was returned")
+	}
+}
+
+func TestPrompts_InitializeAdvertisesCapability(t *testing.T) {
+	s := newPromptTestServer(t)
+	resp := callHandler(t, s, protocol.MethodInitialize, protocol.InitializeParams{ProtocolVersion: protocol.ProtocolVersion})
+	var result protocol.InitializeResult
+	data, _ := json.Marshal(resp.Result)
+	_ = json.Unmarshal(data, &result)
+	if result.Capabilities.Prompts == nil {
+		t.Error("prompts capability not advertised")
+	}
+}
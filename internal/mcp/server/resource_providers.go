@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"mote/internal/mcp/protocol"
+	"mote/internal/memory"
+)
+
+const (
+	// maxListedFiles caps the number of workspace files returned by resources/list.
+	maxListedFiles = 500
+	// maxResourceSize caps the size of a single resource read.
+	maxResourceSize = 4 * 1024 * 1024
+	// maxListedMemories caps the number of memory entries returned by resources/list.
+	maxListedMemories = 200
+)
+
+// FileResourceProvider exposes the files under a workspace root as file:// resources.
+type FileResourceProvider struct {
+	root string
+}
+
+// NewFileResourceProvider creates a provider for the files under root.
+func NewFileResourceProvider(root string) (*FileResourceProvider, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", abs)
+	}
+	return &FileResourceProvider{root: abs}, nil
+}
+
+// ListResources lists workspace files, skipping hidden files and directories.
+func (p *FileResourceProvider) ListResources(ctx context.Context) ([]protocol.Resource, error) {
+	var resources []protocol.Resource
+	err := filepath.WalkDir(p.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if path != p.root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, _ := filepath.Rel(p.root, path)
+		resources = append(resources, protocol.Resource{
+			URI:      fileURI(path),
+			Name:     filepath.ToSlash(rel),
+			MimeType: mimeTypeFor(path),
+		})
+		if len(resources) >= maxListedFiles {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return resources, err
+}
+
+// ResourceTemplates returns the template for reading any workspace file.
+func (p *FileResourceProvider) ResourceTemplates() []protocol.ResourceTemplate {
+	return []protocol.ResourceTemplate{{
+		URITemplate: fileURI(p.root) + "/{path}",
+		Name:        "workspace-file",
+		Description: "A file in the workspace",
+	}}
+}
+
+// ReadResource reads a file:// URI inside the workspace root.
+func (p *FileResourceProvider) ReadResource(ctx context.Context, uri string) ([]protocol.ResourceContents, bool, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return nil, false, nil
+	}
+	path := filepath.FromSlash(u.Path)
+	requested := path
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(p.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, false, nil
+	}
+	// Hidden files are not listed, so they cannot be read either, whether
+	// named directly or reached through a link.
+	if isHidden(rel) {
+		return nil, false, nil
+	}
+	if reqRel, err := filepath.Rel(p.root, filepath.Clean(requested)); err == nil && isHidden(reqRel) {
+		return nil, false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, true, fmt.Errorf("file not found: %s", rel)
+		}
+		return nil, true, err
+	}
+	if info.IsDir() {
+		return nil, true, fmt.Errorf("%s is a directory", rel)
+	}
+	if info.Size() > maxResourceSize {
+		return nil, true, fmt.Errorf("file too large: %s (%d bytes, limit %d)", rel, info.Size(), maxResourceSize)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, true, err
+	}
+
+	contents := protocol.ResourceContents{URI: uri, MimeType: mimeTypeFor(path)}
+	if utf8.Valid(data) {
+		contents.Text = string(data)
+	} else {
+		contents.Blob = base64.StdEncoding.EncodeToString(data)
+	}
+	return []protocol.ResourceContents{contents}, true, nil
+}
+
+// isHidden reports whether any component of the relative path is hidden.
+func isHidden(rel string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if strings.HasPrefix(part, ".") && part != "." && part != ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// fileURI converts an absolute path to a file:// URI.
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// mimeTypeFor guesses a MIME type from the file extension.
+func mimeTypeFor(path string) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return "text/plain"
+}
+
+// MemoryStore is the subset of the memory index used by MemoryResourceProvider.
+type MemoryStore interface {
+	List(ctx context.Context, limit, offset int) ([]memory.SearchResult, error)
+	GetByID(ctx context.Context, id string) (*memory.MemoryEntry, error)
+}
+
+// memoryURIPrefix is the URI prefix of memory entry resources.
+const memoryURIPrefix = "memory://entries/"
+
+// MemoryResourceProvider exposes memory entries as memory://entries/<id> resources.
+type MemoryResourceProvider struct {
+	store MemoryStore
+}
+
+// NewMemoryResourceProvider creates a provider backed by store.
+func NewMemoryResourceProvider(store MemoryStore) *MemoryResourceProvider {
+	return &MemoryResourceProvider{store: store}
+}
+
+// ListResources lists the most recent memory entries.
+func (p *MemoryResourceProvider) ListResources(ctx context.Context) ([]protocol.Resource, error) {
+	entries, err := p.store.List(ctx, maxListedMemories, 0)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]protocol.Resource, 0, len(entries))
+	for _, e := range entries {
+		resources = append(resources, protocol.Resource{
+			URI:         memoryURIPrefix + url.PathEscape(e.ID),
+			Name:        memoryTitle(e.Content),
+			Description: memoryDescription(e),
+			MimeType:    "text/markdown",
+		})
+	}
+	return resources, nil
+}
+
+// ResourceTemplates returns the template for reading a memory entry by ID.
+func (p *MemoryResourceProvider) ResourceTemplates() []protocol.ResourceTemplate {
+	return []protocol.ResourceTemplate{{
+		URITemplate: memoryURIPrefix + "{id}",
+		Name:        "memory-entry",
+		Description: "A memory entry by ID",
+		MimeType:    "text/markdown",
+	}}
+}
+
+// ReadResource reads a memory://entries/<id> URI.
+func (p *MemoryResourceProvider) ReadResource(ctx context.Context, uri string) ([]protocol.ResourceContents, bool, error) {
+	if !strings.HasPrefix(uri, memoryURIPrefix) {
+		return nil, false, nil
+	}
+	id, err := url.PathUnescape(strings.TrimPrefix(uri, memoryURIPrefix))
+	if err != nil || id == "" {
+		return nil, true, fmt.Errorf("invalid memory URI: %s", uri)
+	}
+	entry, err := p.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, true, err
+	}
+	return []protocol.ResourceContents{{
+		URI:      uri,
+		MimeType: "text/markdown",
+		Text:     entry.Content,
+	}}, true, nil
+}
+
+// memoryTitle returns the first line of content, shortened.
+func memoryTitle(content string) string {
+	title := strings.TrimSpace(content)
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = title[:i]
+	}
+	title = strings.TrimLeft(title, "# ")
+	if r := []rune(title); len(r) > 60 {
+		title = string(r[:60]) + "..."
+	}
+	return title
+}
+
+func memoryDescription(e memory.SearchResult) string {
+	parts := []string{}
+	if e.Category != "" {
+		parts = append(parts, e.Category)
+	}
+	if e.Source != "" {
+		parts = append(parts, "source: "+e.Source)
+	}
+	if !e.CreatedAt.IsZero() {
+		parts = append(parts, e.CreatedAt.Format("2006-01-02"))
+	}
+	return strings.Join(parts, ", ")
+}
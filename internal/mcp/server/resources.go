@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"mote/internal/mcp/protocol"
+)
+
+// ResourceProvider exposes a set of resources through the MCP server.
+type ResourceProvider interface {
+	// ListResources returns the concrete resources currently available.
+	ListResources(ctx context.Context) ([]protocol.Resource, error)
+	// ResourceTemplates returns URI templates for resources that are not listed.
+	ResourceTemplates() []protocol.ResourceTemplate
+	// ReadResource reads uri. ok is false when uri does not belong to the provider.
+	ReadResource(ctx context.Context, uri string) (contents []protocol.ResourceContents, ok bool, err error)
+}
+
+// WithResourceProvider adds a resource provider to the server.
+func WithResourceProvider(p ResourceProvider) ServerOption {
+	return func(s *Server) {
+		s.resources = append(s.resources, p)
+	}
+}
+
+// HasResources reports whether any resource provider is configured.
+func (s *Server) HasResources() bool {
+	return len(s.resources) > 0
+}
+
+// IsSubscribed reports whether the client subscribed to uri.
+func (s *Server) IsSubscribed(uri string) bool {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+	return s.subscriptions[uri]
+}
+
+// NotifyResourceUpdated tells the client that uri changed, if it subscribed to it.
+func (s *Server) NotifyResourceUpdated(uri string) error {
+	if !s.IsSubscribed(uri) {
+		return nil
+	}
+	return s.notify(protocol.MethodResourcesUpdated, protocol.ResourceUpdatedParams{URI: uri})
+}
+
+// NotifyResourceListChanged tells the client that the set of resources changed.
+func (s *Server) NotifyResourceListChanged() error {
+	if !s.HasResources() {
+		return nil
+	}
+	return s.notify(protocol.MethodResourcesListChanged, nil)
+}
+
+// notify sends a notification to the connected client.
+func (s *Server) notify(method string, params any) error {
+	if s.transport == nil || !s.IsInitialized() {
+		return nil
+	}
+	notif, err := protocol.NewNotification(method, params)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(notif)
+	if err != nil {
+		return err
+	}
+	return s.transport.Send(s.ctx, data)
+}
+
+// handleResourcesList handles the resources/list method.
+func (h *MethodHandler) handleResourcesList(ctx context.Context, params json.RawMessage) (any, error) {
+	result := protocol.ListResourcesResult{Resources: []protocol.Resource{}}
+	for _, p := range h.server.resources {
+		resources, err := p.ListResources(ctx)
+		if err != nil {
+			return nil, protocol.NewInternalError(err.Error())
+		}
+		result.Resources = append(result.Resources, resources...)
+	}
+	return result, nil
+}
+
+// handleResourceTemplatesList handles the resources/templates/list method.
+func (h *MethodHandler) handleResourceTemplatesList(ctx context.Context, params json.RawMessage) (any, error) {
+	result := protocol.ListResourceTemplatesResult{ResourceTemplates: []protocol.ResourceTemplate{}}
+	for _, p := range h.server.resources {
+		result.ResourceTemplates = append(result.ResourceTemplates, p.ResourceTemplates()...)
+	}
+	return result, nil
+}
+
+// handleResourcesRead handles the resources/read method.
+func (h *MethodHandler) handleResourcesRead(ctx context.Context, params json.RawMessage) (any, error) {
+	var readParams protocol.ReadResourceParams
+	if err := json.Unmarshal(params, &readParams); err != nil {
+		return nil, protocol.NewInvalidParamsError(err.Error())
+	}
+	if readParams.URI == "" {
+		return nil, protocol.NewInvalidParamsError("uri is required")
+	}
+
+	for _, p := range h.server.resources {
+		contents, ok, err := p.ReadResource(ctx, readParams.URI)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			return nil, protocol.NewInternalError(err.Error())
+		}
+		return protocol.ReadResourceResult{Contents: contents}, nil
+	}
+	return nil, protocol.NewResourceNotFoundError(readParams.URI)
+}
+
+// handleResourcesSubscribe handles the resources/subscribe method.
+func (h *MethodHandler) handleResourcesSubscribe(ctx context.Context, params json.RawMessage) (any, error) {
+	var subParams protocol.SubscribeParams
+	if err := json.Unmarshal(params, &subParams); err != nil {
+		return nil, protocol.NewInvalidParamsError(err.Error())
+	}
+	if subParams.URI == "" {
+		return nil, protocol.NewInvalidParamsError("uri is required")
+	}
+
+	h.server.subMu.Lock()
+	h.server.subscriptions[subParams.URI] = true
+	h.server.subMu.Unlock()
+	return struct{}{}, nil
+}
+
+// handleResourcesUnsubscribe handles the resources/unsubscribe method.
+func (h *MethodHandler) handleResourcesUnsubscribe(ctx context.Context, params json.RawMessage) (any, error) {
+	var subParams protocol.SubscribeParams
+	if err := json.Unmarshal(params, &subParams); err != nil {
+		return nil, protocol.NewInvalidParamsError(err.Error())
+	}
+
+	h.server.subMu.Lock()
+	delete(h.server.subscriptions, subParams.URI)
+	h.server.subMu.Unlock()
+	return struct{}{}, nil
+}
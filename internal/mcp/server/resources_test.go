@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mote/internal/mcp/protocol"
+	"mote/internal/memory"
+)
+
+type fakeMemoryStore struct {
+	entries []memory.SearchResult
+}
+
+func (f *fakeMemoryStore) List(ctx context.Context, limit, offset int) ([]memory.SearchResult, error) {
+	return f.entries, nil
+}
+
+func (f *fakeMemoryStore) GetByID(ctx context.Context, id string) (*memory.MemoryEntry, error) {
+	for _, e := range f.entries {
+		if e.ID == id {
+			return &memory.MemoryEntry{ID: e.ID, Content: e.Content}, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func newResourceTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "notes.md"), []byte("# Notes\nhello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := NewFileResourceProvider(root)
+	if err != nil {
+		t.Fatalf("NewFileResourceProvider: %v", err)
+	}
+	mem := NewMemoryResourceProvider(&fakeMemoryStore{entries: []memory.SearchResult{
+		{ID: "m1", Content: "User prefers tabs\nmore detail", Category: "preference"},
+	}})
+
+	s := NewServer("test", "1.0", WithResourceProvider(files), WithResourceProvider(mem))
+	s.setInitialized(true)
+	return s, files.root
+}
+
+func callHandler(t *testing.T, s *Server, method string, params any) *protocol.Response {
+	t.Helper()
+	data, _ := json.Marshal(params)
+	return s.handler.HandleRequest(context.Background(), &protocol.Request{
+		Jsonrpc: "2.0", ID: 1, Method: method, Params: data,
+	})
+}
+
+func TestResources_List(t *testing.T) {
+	s, root := newResourceTestServer(t)
+
+	resp := callHandler(t, s, protocol.MethodResourcesList, protocol.ListResourcesParams{})
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	var result protocol.ListResourcesResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	uris := make(map[string]string)
+	for _, r := range result.Resources {
+		uris[r.URI] = r.Name
+	}
+	if len(uris) != 2 {
+		t.Fatalf("expected 2 resources (hidden files skipped), got %v", uris)
+	}
+	if uris[fileURI(filepath.Join(root, "notes.md"))] != "notes.md" {
+		t.Errorf("workspace file missing: %v", uris)
+	}
+	if uris["memory://entries/m1"] != "User prefers tabs" {
+		t.Errorf("memory entry missing: %v", uris)
+	}
+}
+
+func TestResources_Read(t *testing.T) {
+	s, root := newResourceTestServer(t)
+
+	uri := fileURI(filepath.Join(root, "notes.md"))
+	resp := callHandler(t, s, protocol.MethodResourcesRead, protocol.ReadResourceParams{URI: uri})
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	var result protocol.ReadResourceResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].Text != "# Notes\nhello" {
+		t.Errorf("unexpected contents: %+v", result.Contents)
+	}
+
+	resp = callHandler(t, s, protocol.MethodResourcesRead, protocol.ReadResourceParams{URI: "memory://entries/m1"})
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+}
+
+func TestResources_ReadOutsideRoot(t *testing.T) {
+	s, root := newResourceTestServer(t)
+
+	outside := fileURI(filepath.Join(filepath.Dir(root), "secret.txt"))
+	resp := callHandler(t, s, protocol.MethodResourcesRead, protocol.ReadResourceParams{URI: outside})
+	if resp.Error == nil || resp.Error.Code != protocol.ErrCodeResourceNotFound {
+		t.Fatalf("expected resource not found, got %+v", resp.Error)
+	}
+}
+
+func TestResources_ReadHidden(t *testing.T) {
+	s, root := newResourceTestServer(t)
+	if err := os.WriteFile(filepath.Join(root, ".env"), []byte("TOKEN=secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, ".env"), filepath.Join(root, "env.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{".env", ".git/HEAD", "env.txt"} {
+		uri := fileURI(filepath.Join(root, path))
+		resp := callHandler(t, s, protocol.MethodResourcesRead, protocol.ReadResourceParams{URI: uri})
+		if resp.Error == nil || resp.Error.Code != protocol.ErrCodeResourceNotFound {
+			t.Errorf("%s: expected resource not found, got %+v", path, resp.Error)
+		}
+	}
+}
+
+func TestResources_InitializeAdvertisesCapability(t *testing.T) {
+	s, _ := newResourceTestServer(t)
+
+	resp := callHandler(t, s, protocol.MethodInitialize, protocol.InitializeParams{ProtocolVersion: protocol.ProtocolVersion})
+	var result protocol.InitializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Capabilities.Resources == nil || !result.Capabilities.Resources.Subscribe {
+		t.Errorf("expected resources capability with subscribe, got %+v", result.Capabilities.Resources)
+	}
+
+	plain := NewServer("plain", "1.0")
+	resp = callHandler(t, plain, protocol.MethodInitialize, protocol.InitializeParams{ProtocolVersion: protocol.ProtocolVersion})
+	result = protocol.InitializeResult{}
+	_ = json.Unmarshal(resp.Result, &result)
+	if result.Capabilities.Resources != nil {
+		t.Error("server without providers should not advertise resources")
+	}
+}
+
+func TestResources_SubscribeNotify(t *testing.T) {
+	s, _ := newResourceTestServer(t)
+	mockT := newMockTransport()
+	s.transport = mockT
+
+	if err := s.NotifyResourceUpdated("memory://entries/m1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mockT.GetResponse(); ok {
+		t.Fatal("notification sent without subscription")
+	}
+
+	resp := callHandler(t, s, protocol.MethodResourcesSubscribe, protocol.SubscribeParams{URI: "memory://entries/m1"})
+	if resp.Error != nil {
+		t.Fatalf("subscribe: %v", resp.Error)
+	}
+	if err := s.NotifyResourceUpdated("memory://entries/m1"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case data := <-mockT.sendCh:
+		msg, err := protocol.ParseMessage(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Method != protocol.MethodResourcesUpdated {
+			t.Errorf("method: got %q", msg.Method)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no notification sent")
+	}
+
+	callHandler(t, s, protocol.MethodResourcesUnsubscribe, protocol.SubscribeParams{URI: "memory://entries/m1"})
+	if s.IsSubscribed("memory://entries/m1") {
+		t.Error("still subscribed after unsubscribe")
+	}
+}
@@ -4,10 +4,13 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
 	"sync"
 
 	"mote/internal/mcp/protocol"
 	"mote/internal/mcp/transport"
+	"mote/internal/prompts"
 	"mote/internal/tools"
 )
 
@@ -20,6 +23,13 @@ type Server struct {
 	registry  *tools.Registry
 	mapper    *ToolMapper
 	handler   *MethodHandler
+	resources []ResourceProvider
+
+	prompts      *prompts.Manager
+	promptExpose []string
+
+	subscriptions map[string]bool
+	subMu         sync.RWMutex
 
 	initialized bool
 	initMu      sync.RWMutex
@@ -57,11 +67,12 @@ func WithToolPrefix(prefix string) ServerOption {
 func NewServer(name, version string, opts ...ServerOption) *Server {
 	ctx, cancel := context.WithCancel(context.Background())
 	s := &Server{
-		name:     name,
-		version:  version,
-		registry: tools.NewRegistry(),
-		ctx:      ctx,
-		cancel:   cancel,
+		name:          name,
+		version:       version,
+		registry:      tools.NewRegistry(),
+		subscriptions: make(map[string]bool),
+		ctx:           ctx,
+		cancel:        cancel,
 	}
 
 	for _, opt := range opts {
@@ -114,6 +125,29 @@ func (s *Server) Serve() error {
 	return s.ServeTransport(t)
 }
 
+// ServeStreamableHTTP starts the server with the Streamable HTTP transport,
+// serving the /mcp endpoint on addr. It returns once the server is closed.
+func (s *Server) ServeStreamableHTTP(addr string) error {
+	t := transport.NewStreamableHTTPServerTransport(addr)
+	s.transport = t
+	s.wg.Add(1)
+	go s.messageLoop()
+	if err := t.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Handler starts serving over a Streamable HTTP transport that is mounted on
+// an existing router instead of listening on its own address.
+func (s *Server) Handler() http.Handler {
+	t := transport.NewStreamableHTTPServerTransport("")
+	s.transport = t
+	s.wg.Add(1)
+	go s.messageLoop()
+	return t
+}
+
 // ServeTransport starts the server with the specified transport.
 func (s *Server) ServeTransport(t transport.Transport) error {
 	s.transport = t
@@ -155,26 +189,44 @@ func (s *Server) messageLoop() {
 			continue
 		}
 
-		// Parse and handle message
-		response := s.parseAndHandle(data)
-		if response == nil {
-			continue // Notification, no response needed
-		}
-
-		// Send response
-		responseData, err := json.Marshal(response)
-		if err != nil {
-			s.sendError(response.ID, protocol.NewInternalError(err.Error()))
+		// Tool calls may run for minutes (e.g. agents), so they must not
+		// hold up pings and other requests.
+		if isToolCall(data) {
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.respond(s.parseAndHandle(data))
+			}()
 			continue
 		}
 
-		if err := s.transport.Send(s.ctx, responseData); err != nil {
-			// Log error but continue
-			continue
-		}
+		// Parse and handle message
+		s.respond(s.parseAndHandle(data))
 	}
 }
 
+// isToolCall reports whether data is a tools/call request.
+func isToolCall(data []byte) bool {
+	var msg struct {
+		Method string `json:"method"`
+	}
+	return json.Unmarshal(data, &msg) == nil && msg.Method == protocol.MethodToolsCall
+}
+
+// respond sends a response to the client. Notifications have no response.
+func (s *Server) respond(response *protocol.Response) {
+	if response == nil {
+		return
+	}
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		s.sendError(response.ID, protocol.NewInternalError(err.Error()))
+		return
+	}
+	// Send errors are not recoverable here; the client will time out
+	_ = s.transport.Send(s.ctx, responseData)
+}
+
 // parseAndHandle parses a message and handles it.
 func (s *Server) parseAndHandle(data []byte) *protocol.Response {
 	msg, err := protocol.ParseMessage(data)
@@ -199,13 +251,28 @@ func (s *Server) parseAndHandle(data []byte) *protocol.Response {
 			Method:  msg.Method,
 			Params:  msg.Params,
 		}
-		return s.handler.HandleRequest(s.ctx, req)
+		return s.handler.HandleRequest(s.requestContext(msg.ID), req)
 	}
 
 	// Unexpected message type
 	return protocol.NewErrorResponse(nil, protocol.NewInvalidRequestError("unexpected message type"))
 }
 
+// requestContext returns the context for handling the request with the given
+// ID. On multi-session transports it names the session that sent the request,
+// so messages sent to the client while handling it reach that session only.
+func (s *Server) requestContext(id any) context.Context {
+	router, ok := s.transport.(transport.SessionRouter)
+	if !ok {
+		return s.ctx
+	}
+	reqID, _ := id.(string)
+	if sessionID := router.RequestSession(reqID); sessionID != "" {
+		return transport.WithSession(s.ctx, sessionID)
+	}
+	return s.ctx
+}
+
 // sendError sends an error response.
 func (s *Server) sendError(id any, rpcErr *protocol.RPCError) {
 	response := protocol.NewErrorResponse(id, rpcErr)
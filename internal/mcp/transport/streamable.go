@@ -0,0 +1,996 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Streamable HTTP header names.
+const (
+	HeaderSessionID       = "Mcp-Session-Id"
+	HeaderProtocolVersion = "MCP-Protocol-Version"
+	HeaderLastEventID     = "Last-Event-ID"
+)
+
+var (
+	// ErrUnauthorized is returned when the server rejects the request with
+	// 401 and the authorizer could not supply fresh credentials.
+	ErrUnauthorized = errors.New("authorization required")
+	// ErrSessionExpired is returned when the server no longer knows the session.
+	ErrSessionExpired = errors.New("MCP session expired")
+	// ErrNoSession is returned when the server transport cannot tell which
+	// session a server-initiated request is for.
+	ErrNoSession = errors.New("no MCP session for message")
+	// ErrNoPendingRequest is returned when a response arrives for a request
+	// whose POST is gone, e.g. because the client timed out.
+	ErrNoPendingRequest = errors.New("no pending request for response")
+)
+
+// Authorizer supplies credentials for HTTP transports.
+type Authorizer interface {
+	// Authorization returns the Authorization header value, or "" for none.
+	Authorization(ctx context.Context) (string, error)
+	// HandleUnauthorized is called after a 401 response. It returns true when
+	// the credentials were refreshed and the request should be retried once.
+	HandleUnauthorized(ctx context.Context, resp *http.Response) bool
+}
+
+// streamMaxBackoff caps the delay between attempts to reopen the standalone stream.
+const streamMaxBackoff = 30 * time.Second
+
+// StreamableHTTPClientTransport implements the single-endpoint Streamable HTTP
+// transport. Messages are POSTed to the endpoint, which answers with JSON or an
+// SSE stream; server-initiated messages arrive on a standalone GET stream that
+// is resumed with Last-Event-ID after a disconnect.
+type StreamableHTTPClientTransport struct {
+	endpoint   string
+	headers    map[string]string
+	auth       Authorizer
+	httpClient *http.Client
+	incoming   chan []byte
+
+	sessionID       string
+	protocolVersion string
+	listening       bool
+	started         bool
+	closed          bool
+	mu              sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// StreamableOption configures a StreamableHTTPClientTransport.
+type StreamableOption func(*StreamableHTTPClientTransport)
+
+// WithAuthorizer sets the authorizer used for every request.
+func WithAuthorizer(a Authorizer) StreamableOption {
+	return func(t *StreamableHTTPClientTransport) {
+		t.auth = a
+	}
+}
+
+// WithHTTPClient sets the HTTP client. It must not set a global timeout, as
+// SSE streams stay open for the lifetime of the session.
+func WithHTTPClient(c *http.Client) StreamableOption {
+	return func(t *StreamableHTTPClientTransport) {
+		t.httpClient = c
+	}
+}
+
+// NewStreamableHTTPClientTransport creates a Streamable HTTP client transport.
+func NewStreamableHTTPClientTransport(endpoint string, headers map[string]string, opts ...StreamableOption) *StreamableHTTPClientTransport {
+	t := &StreamableHTTPClientTransport{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		headers:    headers,
+		httpClient: &http.Client{},
+		incoming:   make(chan []byte, 100),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Start initializes the transport. No connection is made until the first Send.
+func (t *StreamableHTTPClientTransport) Start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrTransportClosed
+	}
+	if t.started {
+		return nil
+	}
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	t.started = true
+	return nil
+}
+
+// SessionID returns the session ID assigned by the server, if any.
+func (t *StreamableHTTPClientTransport) SessionID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID
+}
+
+// SetProtocolVersion sets the negotiated protocol version, sent on every
+// subsequent request.
+func (t *StreamableHTTPClientTransport) SetProtocolVersion(version string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.protocolVersion = version
+}
+
+// newRequest builds a request carrying the session, version and auth headers.
+func (t *StreamableHTTPClientTransport) newRequest(ctx context.Context, method string, body []byte) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.endpoint, r)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	t.mu.Lock()
+	sessionID, version := t.sessionID, t.protocolVersion
+	t.mu.Unlock()
+	if sessionID != "" {
+		req.Header.Set(HeaderSessionID, sessionID)
+	}
+	if version != "" {
+		req.Header.Set(HeaderProtocolVersion, version)
+	}
+
+	if t.auth != nil {
+		value, err := t.auth.Authorization(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+		}
+		if value != "" {
+			req.Header.Set("Authorization", value)
+		}
+	}
+	return req, nil
+}
+
+// do sends a request, retrying once when the authorizer refreshes credentials
+// after a 401.
+func (t *StreamableHTTPClientTransport) do(ctx context.Context, method string, body []byte, prepare func(*http.Request)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := t.newRequest(ctx, method, body)
+		if err != nil {
+			return nil, err
+		}
+		if prepare != nil {
+			prepare(req)
+		}
+		resp, err := t.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			return resp, nil
+		}
+		retry := attempt == 0 && t.auth != nil && t.auth.HandleUnauthorized(ctx, resp)
+		drainAndClose(resp.Body)
+		if !retry {
+			return nil, fmt.Errorf("%w (status 401)", ErrUnauthorized)
+		}
+	}
+}
+
+// Send POSTs a message. Responses arrive through Receive, either from the
+// JSON body or from the SSE stream the server opens for the request.
+func (t *StreamableHTTPClientTransport) Send(ctx context.Context, data []byte) error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return ErrTransportClosed
+	}
+	if !t.started {
+		t.mu.Unlock()
+		return ErrNotStarted
+	}
+	t.mu.Unlock()
+
+	resp, err := t.do(ctx, http.MethodPost, data, func(req *http.Request) {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+	})
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+
+	if id := resp.Header.Get(HeaderSessionID); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && t.SessionID() != "":
+		drainAndClose(resp.Body)
+		t.mu.Lock()
+		t.sessionID = ""
+		t.mu.Unlock()
+		return ErrSessionExpired
+	case resp.StatusCode == http.StatusAccepted:
+		drainAndClose(resp.Body)
+		// The first accepted notification is notifications/initialized, so
+		// the session is ready for the standalone stream.
+		t.startListening()
+		return nil
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return fmt.Errorf("request failed: status %d, body: %s", resp.StatusCode, body)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			lastID, err := t.readStream(resp.Body)
+			if err != nil && lastID != "" && t.ctx.Err() == nil {
+				// The stream broke before the server was done; pick it up
+				// where it stopped.
+				t.resume(lastID)
+			}
+		}()
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		return t.deliver(ctx, body)
+	}
+	return nil
+}
+
+// deliver queues a received message.
+func (t *StreamableHTTPClientTransport) deliver(ctx context.Context, data []byte) error {
+	select {
+	case t.incoming <- data:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.ctx.Done():
+		return ErrTransportClosed
+	}
+}
+
+// readStream delivers the messages of an SSE stream until it ends. It returns
+// the ID of the last event seen and the error that ended the stream, if any.
+func (t *StreamableHTTPClientTransport) readStream(body io.ReadCloser) (string, error) {
+	defer body.Close()
+
+	var lastID string
+	reader := bufio.NewReader(body)
+	for {
+		ev, err := readSSE(reader)
+		if ev.id != "" {
+			lastID = ev.id
+		}
+		if len(ev.data) > 0 && (ev.event == "" || ev.event == "message") {
+			if derr := t.deliver(t.ctx, ev.data); derr != nil {
+				return lastID, derr
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return lastID, nil
+			}
+			return lastID, err
+		}
+	}
+}
+
+// openStream issues the GET request for a standalone or resumed stream.
+// ok is false when the server does not offer one.
+func (t *StreamableHTTPClientTransport) openStream(lastEventID string) (io.ReadCloser, bool, error) {
+	resp, err := t.do(t.ctx, http.MethodGet, nil, func(req *http.Request) {
+		req.Header.Set("Accept", "text/event-stream")
+		if lastEventID != "" {
+			req.Header.Set(HeaderLastEventID, lastEventID)
+		}
+	})
+	if err != nil {
+		return nil, true, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return resp.Body, true, nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// 405 is the documented answer, but plain JSON servers reply with
+		// all sorts of client errors to a GET.
+		drainAndClose(resp.Body)
+		return nil, false, nil
+	default:
+		drainAndClose(resp.Body)
+		return nil, true, fmt.Errorf("open stream: status %d", resp.StatusCode)
+	}
+}
+
+// resume reopens an interrupted stream from lastEventID, retrying a few times.
+func (t *StreamableHTTPClientTransport) resume(lastEventID string) {
+	backoff := time.Second
+	for attempt := 0; attempt < 3 && t.ctx.Err() == nil; attempt++ {
+		body, ok, err := t.openStream(lastEventID)
+		if !ok {
+			return
+		}
+		if err == nil {
+			id, err := t.readStream(body)
+			if id != "" {
+				lastEventID = id
+			}
+			if err == nil {
+				return
+			}
+		}
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// startListening opens the standalone GET stream once per transport.
+func (t *StreamableHTTPClientTransport) startListening() {
+	t.mu.Lock()
+	if t.listening || t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.listening = true
+	t.mu.Unlock()
+
+	t.wg.Add(1)
+	go t.listen()
+}
+
+// listen keeps the standalone stream open, resuming it after disconnects.
+func (t *StreamableHTTPClientTransport) listen() {
+	defer t.wg.Done()
+
+	var lastEventID string
+	backoff := time.Second
+	for t.ctx.Err() == nil {
+		body, ok, err := t.openStream(lastEventID)
+		if !ok {
+			return // Server does not offer server-initiated messages
+		}
+		if err == nil {
+			backoff = time.Second
+			id, _ := t.readStream(body)
+			if id != "" {
+				lastEventID = id
+			}
+		}
+
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > streamMaxBackoff {
+			backoff = streamMaxBackoff
+		}
+	}
+}
+
+// Receive receives a message from the server.
+func (t *StreamableHTTPClientTransport) Receive(ctx context.Context) ([]byte, error) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, ErrTransportClosed
+	}
+	if !t.started {
+		t.mu.Unlock()
+		return nil, ErrNotStarted
+	}
+	t.mu.Unlock()
+
+	select {
+	case data := <-t.incoming:
+		return data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-t.ctx.Done():
+		return nil, ErrTransportClosed
+	}
+}
+
+// Close terminates the session on the server and stops all streams.
+func (t *StreamableHTTPClientTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	sessionID := t.sessionID
+	started := t.started
+	t.mu.Unlock()
+
+	if started && sessionID != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if resp, err := t.do(ctx, http.MethodDelete, nil, nil); err == nil {
+			drainAndClose(resp.Body)
+		}
+		cancel()
+	}
+
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
+
+// sseEvent is a parsed server-sent event.
+type sseEvent struct {
+	id    string
+	event string
+	data  []byte
+}
+
+// readSSE reads one event from r. The returned event may be partial when err
+// is non-nil.
+func readSSE(r *bufio.Reader) (sseEvent, error) {
+	var ev sseEvent
+	var data bytes.Buffer
+	for {
+		line, err := r.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if err != nil {
+			if line == "" {
+				ev.data = data.Bytes()
+				return ev, err
+			}
+		}
+
+		switch {
+		case line == "":
+			if data.Len() > 0 || ev.id != "" {
+				ev.data = data.Bytes()
+				return ev, err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment / heartbeat
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "id":
+				ev.id = value
+			case "event":
+				ev.event = value
+			case "data":
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.WriteString(value)
+			}
+		}
+		if err != nil {
+			ev.data = data.Bytes()
+			return ev, err
+		}
+	}
+}
+
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 64*1024))
+	body.Close()
+}
+
+// StreamableHTTPServerTransport implements the server side of the Streamable
+// HTTP transport on a single endpoint. Requests are answered with JSON on the
+// POST that carried them; notifications go to every session's GET stream and
+// server-initiated requests to the session they are for. The last events are
+// kept so clients can resume with Last-Event-ID. Idle sessions expire and the
+// number of sessions is capped.
+type StreamableHTTPServerTransport struct {
+	addr     string
+	server   *http.Server
+	incoming chan []byte
+
+	sessions    map[string]*streamSession
+	pending     map[string]*pendingCall
+	nextID      int64
+	maxSessions int
+	idleTimeout time.Duration
+	mu          sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// maxReplayEvents is the number of events kept per session for resumption.
+const maxReplayEvents = 256
+
+// Default session limits of the Streamable HTTP server.
+const (
+	defaultMaxSessions        = 1000
+	defaultSessionIdleTimeout = 30 * time.Minute
+)
+
+// streamSession is a client session of the Streamable HTTP server.
+type streamSession struct {
+	id        string
+	events    []sseEvent
+	nextEvent int64
+	listeners map[chan sseEvent]struct{}
+	lastSeen  time.Time
+}
+
+// pendingCall is a request waiting for its response from the server core.
+type pendingCall struct {
+	originalID json.RawMessage
+	session    string
+	done       chan []byte
+}
+
+// sessionKey is the context key for the session a message belongs to.
+type sessionKey struct{}
+
+// WithSession returns a context that routes messages sent with it to the
+// given Streamable HTTP session.
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+// SessionFromContext returns the session set by WithSession, or "".
+func SessionFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
+// SessionRouter is implemented by server transports that serve several
+// client sessions. RequestSession returns the session that sent the request
+// with the given ID, as received from Receive, while it is being handled.
+type SessionRouter interface {
+	RequestSession(id string) string
+}
+
+// NewStreamableHTTPServerTransport creates a Streamable HTTP server transport
+// serving the /mcp endpoint on addr.
+func NewStreamableHTTPServerTransport(addr string) *StreamableHTTPServerTransport {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &StreamableHTTPServerTransport{
+		addr:        addr,
+		incoming:    make(chan []byte, 100),
+		sessions:    make(map[string]*streamSession),
+		pending:     make(map[string]*pendingCall),
+		maxSessions: defaultMaxSessions,
+		idleTimeout: defaultSessionIdleTimeout,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", t)
+	t.server = &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+	return t
+}
+
+// SetSessionLimits sets the maximum number of sessions and how long a session
+// without requests or an open stream is kept. Zero keeps the current value.
+func (t *StreamableHTTPServerTransport) SetSessionLimits(maxSessions int, idleTimeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if maxSessions > 0 {
+		t.maxSessions = maxSessions
+	}
+	if idleTimeout > 0 {
+		t.idleTimeout = idleTimeout
+	}
+}
+
+// Start starts the HTTP server.
+func (t *StreamableHTTPServerTransport) Start() error {
+	return t.server.ListenAndServe()
+}
+
+// ServeHTTP serves the MCP endpoint, so the transport can also be mounted on
+// an existing router.
+func (t *StreamableHTTPServerTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r)
+	case http.MethodGet:
+		t.handleGet(w, r)
+	case http.MethodDelete:
+		t.handleDelete(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// envelope holds the JSON-RPC fields the transport routes on.
+type envelope struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+}
+
+func (e envelope) hasID() bool {
+	return len(e.ID) > 0 && string(e.ID) != "null"
+}
+
+// session returns the session named by the request, writing an error if it
+// is missing or unknown.
+func (t *StreamableHTTPServerTransport) session(w http.ResponseWriter, r *http.Request) (*streamSession, bool) {
+	id := r.Header.Get(HeaderSessionID)
+	if id == "" {
+		http.Error(w, "Missing "+HeaderSessionID+" header", http.StatusBadRequest)
+		return nil, false
+	}
+	t.mu.Lock()
+	s, ok := t.sessions[id]
+	if ok && t.expiredLocked(s, time.Now()) {
+		delete(t.sessions, id)
+		ok = false
+	}
+	if ok {
+		s.lastSeen = time.Now()
+	}
+	t.mu.Unlock()
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil, false
+	}
+	return s, true
+}
+
+func (t *StreamableHTTPServerTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		http.Error(w, "Invalid JSON-RPC message", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		s  *streamSession
+		ok bool
+	)
+	if env.Method == "initialize" && r.Header.Get(HeaderSessionID) == "" {
+		if s = t.newSession(); s == nil {
+			http.Error(w, "Too many sessions", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set(HeaderSessionID, s.id)
+	} else if s, ok = t.session(w, r); !ok {
+		return
+	}
+
+	// Notifications and responses to server-initiated requests.
+	if env.Method == "" || !env.hasID() {
+		select {
+		case t.incoming <- body:
+			w.WriteHeader(http.StatusAccepted)
+		case <-r.Context().Done():
+		case <-t.ctx.Done():
+			http.Error(w, "Server closed", http.StatusServiceUnavailable)
+		}
+		return
+	}
+
+	// Requests: rewrite the ID so requests from different sessions cannot
+	// collide, and hold the POST open until the response comes back.
+	t.mu.Lock()
+	t.nextID++
+	internalID := "s" + strconv.FormatInt(t.nextID, 10)
+	call := &pendingCall{originalID: env.ID, session: s.id, done: make(chan []byte, 1)}
+	t.pending[internalID] = call
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, internalID)
+		t.mu.Unlock()
+	}()
+
+	rewritten, err := replaceID(body, json.RawMessage(strconv.Quote(internalID)))
+	if err != nil {
+		http.Error(w, "Invalid JSON-RPC message", http.StatusBadRequest)
+		return
+	}
+	select {
+	case t.incoming <- rewritten:
+	case <-r.Context().Done():
+		return
+	case <-t.ctx.Done():
+		http.Error(w, "Server closed", http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case resp := <-call.done:
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resp)
+	case <-r.Context().Done():
+	case <-t.ctx.Done():
+		http.Error(w, "Server closed", http.StatusServiceUnavailable)
+	}
+}
+
+func (t *StreamableHTTPServerTransport) handleGet(w http.ResponseWriter, r *http.Request) {
+	s, ok := t.session(w, r)
+	if !ok {
+		return
+	}
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		http.Error(w, "Accept must include text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan sseEvent, 32)
+	t.mu.Lock()
+	replay := s.eventsAfter(r.Header.Get(HeaderLastEventID))
+	s.listeners[ch] = struct{}{}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(s.listeners, ch)
+		s.lastSeen = time.Now()
+		t.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	for _, ev := range replay {
+		writeSSE(w, ev)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case ev := <-ch:
+			writeSSE(w, ev)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+func (t *StreamableHTTPServerTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
+	s, ok := t.session(w, r)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	delete(t.sessions, s.id)
+	t.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newSession registers a new session after dropping expired ones. It returns
+// nil if the session limit is reached.
+func (t *StreamableHTTPServerTransport) newSession() *streamSession {
+	now := time.Now()
+	s := &streamSession{
+		id:        uuid.New().String(),
+		listeners: make(map[chan sseEvent]struct{}),
+		lastSeen:  now,
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, other := range t.sessions {
+		if t.expiredLocked(other, now) {
+			delete(t.sessions, id)
+		}
+	}
+	if len(t.sessions) >= t.maxSessions {
+		return nil
+	}
+	t.sessions[s.id] = s
+	return s
+}
+
+// expiredLocked reports whether s has been idle too long. Sessions with an
+// open stream do not expire. Caller holds t.mu.
+func (t *StreamableHTTPServerTransport) expiredLocked(s *streamSession, now time.Time) bool {
+	return len(s.listeners) == 0 && now.Sub(s.lastSeen) > t.idleTimeout
+}
+
+// RequestSession implements SessionRouter.
+func (t *StreamableHTTPServerTransport) RequestSession(id string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if call, ok := t.pending[id]; ok {
+		return call.session
+	}
+	return ""
+}
+
+// SessionCount returns the number of active sessions.
+func (t *StreamableHTTPServerTransport) SessionCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.sessions)
+}
+
+// eventsAfter returns the kept events after lastID. Caller holds t.mu.
+func (s *streamSession) eventsAfter(lastID string) []sseEvent {
+	if lastID == "" {
+		return nil
+	}
+	n, err := strconv.ParseInt(lastID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	var out []sseEvent
+	for _, ev := range s.events {
+		if id, _ := strconv.ParseInt(ev.id, 10, 64); id > n {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// publish records an event and hands it to the session's listeners. Caller
+// holds t.mu.
+func (s *streamSession) publish(data []byte) {
+	s.nextEvent++
+	ev := sseEvent{id: strconv.FormatInt(s.nextEvent, 10), event: "message", data: data}
+	s.events = append(s.events, ev)
+	if len(s.events) > maxReplayEvents {
+		s.events = s.events[len(s.events)-maxReplayEvents:]
+	}
+	for ch := range s.listeners {
+		select {
+		case ch <- ev:
+		default:
+			// Slow listener; it can resume from the replay buffer.
+		}
+	}
+}
+
+// Send routes a message from the server core: responses go back to the POST
+// that carried the request and messages sent with a WithSession context to
+// that session's stream. Other server-initiated requests need a single
+// session to go to; other notifications go to every session. Responses whose
+// POST is gone are dropped rather than sent to any stream.
+func (t *StreamableHTTPServerTransport) Send(ctx context.Context, data []byte) error {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+
+	if env.Method == "" {
+		var internalID string
+		if env.hasID() && json.Unmarshal(env.ID, &internalID) == nil {
+			t.mu.Lock()
+			call, ok := t.pending[internalID]
+			t.mu.Unlock()
+			if ok {
+				resp, err := replaceID(data, call.originalID)
+				if err != nil {
+					return err
+				}
+				call.done <- resp
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: id %s", ErrNoPendingRequest, env.ID)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if id := SessionFromContext(ctx); id != "" {
+		s, ok := t.sessions[id]
+		if !ok {
+			return fmt.Errorf("%w: session %s not found", ErrNoSession, id)
+		}
+		s.publish(data)
+		return nil
+	}
+	if env.hasID() {
+		if len(t.sessions) != 1 {
+			return fmt.Errorf("%w: request %s", ErrNoSession, env.Method)
+		}
+		for _, s := range t.sessions {
+			s.publish(data)
+		}
+		return nil
+	}
+	for _, s := range t.sessions {
+		s.publish(data)
+	}
+	return nil
+}
+
+// Receive receives an incoming message.
+func (t *StreamableHTTPServerTransport) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case data := <-t.incoming:
+		return data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-t.ctx.Done():
+		return nil, ErrTransportClosed
+	}
+}
+
+// Close shuts the HTTP server down.
+func (t *StreamableHTTPServerTransport) Close() error {
+	t.cancel()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return t.server.Shutdown(ctx)
+}
+
+// replaceID returns msg with its "id" member replaced.
+func replaceID(msg []byte, id json.RawMessage) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &fields); err != nil {
+		return nil, err
+	}
+	fields["id"] = id
+	return json.Marshal(fields)
+}
+
+// writeSSE writes ev in SSE wire format.
+func writeSSE(w io.Writer, ev sseEvent) {
+	if ev.id != "" {
+		fmt.Fprintf(w, "id: %s\n", ev.id)
+	}
+	if ev.event != "" {
+		fmt.Fprintf(w, "event: %s\n", ev.event)
+	}
+	for _, line := range strings.Split(string(ev.data), "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
@@ -0,0 +1,366 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// echoServer answers every request received by srv with its method name.
+func echoServer(ctx context.Context, srv *StreamableHTTPServerTransport) {
+	for {
+		data, err := srv.Receive(ctx)
+		if err != nil {
+			return
+		}
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if json.Unmarshal(data, &msg) != nil || len(msg.ID) == 0 || msg.Method == "" {
+			continue
+		}
+		resp, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": map[string]string{"method": msg.Method}})
+		_ = srv.Send(ctx, resp)
+	}
+}
+
+func receiveWithin(t *testing.T, tr *StreamableHTTPClientTransport) []byte {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	data, err := tr.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	return data
+}
+
+func TestStreamableHTTP_RoundTrip(t *testing.T) {
+	srv := NewStreamableHTTPServerTransport(":0")
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go echoServer(ctx, srv)
+
+	client := NewStreamableHTTPClientTransport(ts.URL+"/", nil)
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)); err != nil {
+		t.Fatal(err)
+	}
+	data := receiveWithin(t, client)
+	if !strings.Contains(string(data), `"id":1`) || !strings.Contains(string(data), "initialize") {
+		t.Errorf("unexpected response: %s", data)
+	}
+	if client.SessionID() == "" {
+		t.Fatal("session ID not captured")
+	}
+	client.SetProtocolVersion("2025-06-18")
+
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Server-initiated messages arrive on the standalone stream.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		srv.mu.Lock()
+		listeners := 0
+		for _, s := range srv.sessions {
+			listeners += len(s.listeners)
+		}
+		srv.mu.Unlock()
+		if listeners > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("standalone stream not opened")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := srv.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if data := receiveWithin(t, client); !strings.Contains(string(data), "list_changed") {
+		t.Errorf("unexpected notification: %s", data)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.SessionCount(); n != 0 {
+		t.Errorf("session not deleted on close: %d left", n)
+	}
+}
+
+func TestStreamableHTTPServer_Sessions(t *testing.T) {
+	srv := NewStreamableHTTPServerTransport(":0")
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("request without session: got %d, want 400", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	req.Header.Set(HeaderSessionID, "unknown")
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown session: got %d, want 404", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/mcp", nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT: got %d, want 405", w.Code)
+	}
+}
+
+func TestStreamableHTTPServer_Replay(t *testing.T) {
+	srv := NewStreamableHTTPServerTransport(":0")
+	s := srv.newSession()
+	for i := 0; i < 3; i++ {
+		if err := srv.Send(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/message"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(HeaderSessionID, s.id)
+	req.Header.Set(HeaderLastEventID, "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	r := bufio.NewReader(resp.Body)
+	for _, want := range []string{"2", "3"} {
+		ev, err := readSSE(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.id != want {
+			t.Errorf("replayed event id = %q, want %q", ev.id, want)
+		}
+	}
+}
+
+type fakeAuthorizer struct {
+	token     atomic.Value
+	refreshes atomic.Int32
+}
+
+func (a *fakeAuthorizer) Authorization(ctx context.Context) (string, error) {
+	return "Bearer " + a.token.Load().(string), nil
+}
+
+func (a *fakeAuthorizer) HandleUnauthorized(ctx context.Context, resp *http.Response) bool {
+	a.refreshes.Add(1)
+	a.token.Store("fresh")
+	return true
+}
+
+func TestStreamableHTTPClient_Authorizer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("id: 1\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\n" +
+			"data: \"result\":{}}\n\n"))
+	}))
+	defer ts.Close()
+
+	auth := &fakeAuthorizer{}
+	auth.token.Store("stale")
+	client := NewStreamableHTTPClientTransport(ts.URL, nil, WithAuthorizer(auth))
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.Send(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)); err != nil {
+		t.Fatal(err)
+	}
+	data := receiveWithin(t, client)
+	var msg map[string]any
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("multi-line data not joined: %s", data)
+	}
+	if auth.refreshes.Load() != 1 {
+		t.Errorf("refreshes = %d, want 1", auth.refreshes.Load())
+	}
+}
+
+func TestStreamableHTTPClient_SessionExpired(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderSessionID) != "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set(HeaderSessionID, "abc")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+	}))
+	defer ts.Close()
+
+	client := NewStreamableHTTPClientTransport(ts.URL, nil)
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)); err != nil {
+		t.Fatal(err)
+	}
+	receiveWithin(t, client)
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)); err != ErrSessionExpired {
+		t.Errorf("got %v, want ErrSessionExpired", err)
+	}
+}
+
+func TestStreamableHTTPServer_SessionLimits(t *testing.T) {
+	srv := NewStreamableHTTPServerTransport(":0")
+	srv.SetSessionLimits(2, time.Minute)
+
+	initialize := func() *httptest.ResponseRecorder {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel() // the POST is not answered; only the session matters
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)).WithContext(ctx)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+	first := initialize().Header().Get(HeaderSessionID)
+	initialize()
+	if w := initialize(); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("session over limit: got %d, want 503", w.Code)
+	}
+
+	// An idle session expires and frees its slot.
+	srv.mu.Lock()
+	srv.sessions[first].lastSeen = time.Now().Add(-2 * time.Minute)
+	srv.mu.Unlock()
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	req.Header.Set(HeaderSessionID, first)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expired session: got %d, want 404", w.Code)
+	}
+	if w := initialize(); w.Header().Get(HeaderSessionID) == "" {
+		t.Errorf("new session after expiry: got %d", w.Code)
+	}
+	if n := srv.SessionCount(); n != 2 {
+		t.Errorf("sessions = %d, want 2", n)
+	}
+}
+
+func TestStreamableHTTPServer_RoutesRequestsToSession(t *testing.T) {
+	srv := NewStreamableHTTPServerTransport(":0")
+	a, b := srv.newSession(), srv.newSession()
+	request := []byte(`{"jsonrpc":"2.0","id":7,"method":"sampling/createMessage"}`)
+
+	if err := srv.Send(context.Background(), request); err == nil {
+		t.Error("server-initiated request without a session should fail with several sessions")
+	}
+	if err := srv.Send(WithSession(context.Background(), b.id), request); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Send(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`)); err != nil {
+		t.Fatal(err)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(a.events) != 1 || len(b.events) != 2 {
+		t.Errorf("events: a=%d b=%d, want 1 and 2", len(a.events), len(b.events))
+	}
+}
+
+func TestStreamableHTTPServer_DropsOrphanedResponses(t *testing.T) {
+	srv := NewStreamableHTTPServerTransport(":0")
+	a, b := srv.newSession(), srv.newSession()
+
+	// The client gave up on the POST before the response came back.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`)).WithContext(ctx)
+	req.Header.Set(HeaderSessionID, a.id)
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+
+	for _, resp := range []string{
+		`{"jsonrpc":"2.0","id":"s1","result":{"content":[]}}`,
+		`{"jsonrpc":"2.0","id":"s1","error":{"code":-32603,"message":"boom"}}`,
+	} {
+		if err := srv.Send(context.Background(), []byte(resp)); !errors.Is(err, ErrNoPendingRequest) {
+			t.Errorf("orphaned response: got %v, want ErrNoPendingRequest", err)
+		}
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(a.events) != 0 || len(b.events) != 0 {
+		t.Errorf("orphaned response published: a=%d b=%d", len(a.events), len(b.events))
+	}
+}
+
+func TestStreamableHTTPServer_RequestSession(t *testing.T) {
+	srv := NewStreamableHTTPServerTransport(":0")
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Answer requests with the session that sent them.
+	go func() {
+		for {
+			data, err := srv.Receive(ctx)
+			if err != nil {
+				return
+			}
+			var msg struct {
+				ID     string `json:"id"`
+				Method string `json:"method"`
+			}
+			if json.Unmarshal(data, &msg) != nil || msg.ID == "" {
+				continue
+			}
+			resp, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": map[string]string{"session": srv.RequestSession(msg.ID)}})
+			_ = srv.Send(ctx, resp)
+		}
+	}()
+
+	client := NewStreamableHTTPClientTransport(ts.URL, nil)
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if data := receiveWithin(t, client); !strings.Contains(string(data), client.SessionID()) {
+		t.Errorf("request not attributed to session %s: %s", client.SessionID(), data)
+	}
+}
@@ -15,6 +15,8 @@ const (
 	TransportHTTPSSE TransportType = "http+sse"
 	// TransportHTTP represents simple HTTP transport (no SSE).
 	TransportHTTP TransportType = "http"
+	// TransportStreamableHTTP represents the single-endpoint Streamable HTTP transport.
+	TransportStreamableHTTP TransportType = "streamable-http"
 )
 
 // Transport defines the interface for MCP message transport.
@@ -0,0 +1,148 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// shortIDLen is the length of request IDs shown in chat messages.
+const shortIDLen = 8
+
+// maxArgumentsLen caps the tool arguments quoted in notifications.
+const maxArgumentsLen = 500
+
+// requestIDPattern matches request IDs and their prefixes in chat replies.
+var requestIDPattern = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z_-]{3,}$`)
+
+// SendFunc delivers a text message to the chat an approver watches.
+type SendFunc func(ctx context.Context, text string) error
+
+// ChannelNotifier posts approval requests to a chat on a channel plugin.
+// Approvers answer by replying "approve <id>" or "reject <id> [reason]",
+// which the channel handler passes to Manager.HandleReply.
+type ChannelNotifier struct {
+	target  string
+	send    SendFunc
+	links   *LinkSigner
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewChannelNotifier creates a ChannelNotifier. target names the chat in
+// logs, e.g. "telegram:12345". links may be nil.
+func NewChannelNotifier(target string, send SendFunc, links *LinkSigner) *ChannelNotifier {
+	return &ChannelNotifier{
+		target:  target,
+		send:    send,
+		links:   links,
+		timeout: 30 * time.Second,
+		logger:  slog.Default(),
+	}
+}
+
+// NotifyRequest posts the request with reply instructions.
+func (n *ChannelNotifier) NotifyRequest(req *ApprovalRequest) error {
+	text := FormatRequestText(req) + "\n\n" + fmt.Sprintf(
+		"Reply \"approve %s\" (add \"session\" or \"always\" to remember it) or \"reject %s [reason]\" before %s.",
+		ShortID(req.ID), ShortID(req.ID), req.ExpiresAt.Format("15:04"),
+	)
+	if n.links != nil {
+		text += "\nApprove: " + n.links.Link(req.ID, ActionApprove, req.ExpiresAt) +
+			"\nReject: " + n.links.Link(req.ID, ActionReject, req.ExpiresAt)
+	}
+	return n.post(req, text)
+}
+
+// NotifyResolved posts the outcome so other approvers in the chat know
+// the request is closed.
+func (n *ChannelNotifier) NotifyResolved(req *ApprovalRequest, result *ApprovalResult) error {
+	return n.post(req, FormatResolvedText(req, result))
+}
+
+func (n *ChannelNotifier) post(req *ApprovalRequest, text string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+	defer cancel()
+	if err := n.send(ctx, text); err != nil {
+		return fmt.Errorf("notifier: send to %s: %w", n.target, err)
+	}
+	n.logger.Debug("notifier: sent approval message",
+		"request_id", req.ID,
+		"target", n.target,
+	)
+	return nil
+}
+
+// ParseReply parses an approval command such as "approve 1f2e3d4c",
+// "/reject 1f2e3d4c too risky" or "deny 1f2e3d4c". The ID must look like a
+// (shortened) request ID so ordinary chat is not mistaken for a command.
+// ok is false when text is not an approval command.
+func ParseReply(text string) (id string, approved bool, reason string, ok bool) {
+	fields := strings.Fields(strings.TrimSpace(text))
+	if len(fields) < 2 {
+		return "", false, "", false
+	}
+	switch strings.ToLower(strings.TrimPrefix(fields[0], "/")) {
+	case "approve", "approved", "allow":
+		approved = true
+	case "reject", "rejected", "deny":
+		approved = false
+	default:
+		return "", false, "", false
+	}
+	id = strings.Trim(fields[1], "`\"'.,")
+	if !requestIDPattern.MatchString(id) {
+		return "", false, "", false
+	}
+	return id, approved, strings.Join(fields[2:], " "), true
+}
+
+// ShortID returns the prefix of a request ID shown to approvers.
+func ShortID(id string) string {
+	if len(id) > shortIDLen {
+		return id[:shortIDLen]
+	}
+	return id
+}
+
+// FormatRequestText renders an approval request as plain text.
+func FormatRequestText(req *ApprovalRequest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Approval needed [%s]: %s", ShortID(req.ID), req.ToolName)
+	if req.Reason != "" {
+		fmt.Fprintf(&b, "\nReason: %s", req.Reason)
+	}
+	if args := req.Arguments; args != "" {
+		if len(args) > maxArgumentsLen {
+			args = strings.ToValidUTF8(args[:maxArgumentsLen], "") + "…"
+		}
+		fmt.Fprintf(&b, "\nArguments: %s", args)
+	}
+	if req.SessionID != "" {
+		fmt.Fprintf(&b, "\nSession: %s", req.SessionID)
+	}
+	return b.String()
+}
+
+// FormatResolvedText renders the outcome of an approval request as plain text.
+func FormatResolvedText(req *ApprovalRequest, result *ApprovalResult) string {
+	var text string
+	switch result.Decision {
+	case DecisionApproved:
+		text = fmt.Sprintf("Approved [%s]: %s", ShortID(req.ID), req.ToolName)
+	case DecisionTimeout:
+		text = fmt.Sprintf("Timed out [%s]: %s was rejected automatically", ShortID(req.ID), req.ToolName)
+	default:
+		text = fmt.Sprintf("Rejected [%s]: %s", ShortID(req.ID), req.ToolName)
+	}
+	if result.ApprovedBy != "" {
+		text += " by " + result.ApprovedBy
+	}
+	if result.Message != "" && result.Decision != DecisionTimeout {
+		text += " (" + result.Message + ")"
+	}
+	return text
+}
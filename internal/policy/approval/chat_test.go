@@ -0,0 +1,75 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReply(t *testing.T) {
+	tests := []struct {
+		text     string
+		id       string
+		approved bool
+		reason   string
+		ok       bool
+	}{
+		{"approve 1f2e3d4c", "1f2e3d4c", true, "", true},
+		{"  /approve   1f2e3d4c  ", "1f2e3d4c", true, "", true},
+		{"Allow 1f2e", "1f2e", true, "", true},
+		{"reject 1f2e3d4c not during business hours", "1f2e3d4c", false, "not during business hours", true},
+		{"deny `1f2e3d4c`.", "1f2e3d4c", false, "", true},
+		{"approve", "", false, "", false},
+		{"please approve 1f2e3d4c", "", false, "", false},
+		{"deny it", "", false, "", false},
+		{"yes 1f2e3d4c", "", false, "", false},
+	}
+	for _, tt := range tests {
+		id, approved, reason, ok := ParseReply(tt.text)
+		assert.Equal(t, tt.ok, ok, tt.text)
+		if tt.ok {
+			assert.Equal(t, tt.id, id, tt.text)
+			assert.Equal(t, tt.approved, approved, tt.text)
+			assert.Equal(t, tt.reason, reason, tt.text)
+		}
+	}
+}
+
+func TestChannelNotifier(t *testing.T) {
+	var sent []string
+	links := NewLinkSigner("https://mote.example.com/", []byte("secret"))
+	notifier := NewChannelNotifier("telegram:42", func(ctx context.Context, text string) error {
+		sent = append(sent, text)
+		return nil
+	}, links)
+
+	req := &ApprovalRequest{
+		ID:        "1f2e3d4c-aaaa-bbbb-cccc-000000000000",
+		ToolName:  "shell",
+		Arguments: `{"command": "sudo apt upgrade"}`,
+		Reason:    "sudo requires approval",
+		SessionID: "cron-nightly",
+		ExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	require.NoError(t, notifier.NotifyRequest(req))
+	require.NoError(t, notifier.NotifyResolved(req, &ApprovalResult{Decision: DecisionApproved, Approved: true, ApprovedBy: "link"}))
+
+	require.Len(t, sent, 2)
+	assert.Contains(t, sent[0], "Approval needed [1f2e3d4c]: shell")
+	assert.Contains(t, sent[0], "sudo apt upgrade")
+	assert.Contains(t, sent[0], `Reply "approve 1f2e3d4c"`)
+	assert.Contains(t, sent[0], "https://mote.example.com/api/v1/approvals/"+req.ID+"/decide?action=approve")
+	assert.Equal(t, "Approved [1f2e3d4c]: shell by link", sent[1])
+
+	failing := NewChannelNotifier("slack:C1", func(ctx context.Context, text string) error {
+		return errors.New("channel not found: slack")
+	}, nil)
+	err := failing.NotifyRequest(req)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "slack:C1"))
+}
@@ -2,7 +2,10 @@ package approval
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +19,29 @@ type pendingRequest struct {
 	request *ApprovalRequest
 	done    chan *ApprovalResult
 	timer   *time.Timer
+
+	// escalations are the timers for escalation steps not yet reached.
+	escalations []*time.Timer
+
+	// notified are the escalation notifiers that have seen the request.
+	notified []ApprovalNotifier
+}
+
+// stopTimers stops the timeout and escalation timers.
+func (pr *pendingRequest) stopTimers() {
+	if pr.timer != nil {
+		pr.timer.Stop()
+	}
+	for _, t := range pr.escalations {
+		t.Stop()
+	}
+}
+
+// Escalation notifies an additional backend when a request is still
+// pending After its creation. An After of zero notifies immediately.
+type Escalation struct {
+	After    time.Duration
+	Notifier ApprovalNotifier
 }
 
 // Manager implements the ApprovalHandler interface.
@@ -39,6 +65,15 @@ type Manager struct {
 
 	// maxPending is the maximum number of pending requests.
 	maxPending int
+
+	// escalations are the out-of-band notifiers, in escalation order.
+	escalations []Escalation
+
+	// links signs one-click decision links, nil when disabled.
+	links *LinkSigner
+
+	// rules are the remembered approval decisions, nil when disabled.
+	rules *RuleSet
 }
 
 // ManagerConfig configures the Manager.
@@ -47,6 +82,36 @@ type ManagerConfig struct {
 	Logger     ApprovalLogger
 	Timeout    time.Duration
 	MaxPending int
+
+	// Escalations are notified in addition to Notifier. Steps whose After
+	// is not before Timeout are never reached; the request is rejected
+	// with DecisionTimeout when Timeout elapses.
+	Escalations []Escalation
+
+	// Links signs decision links for notifiers that cannot reach the UI.
+	Links *LinkSigner
+
+	// Rules remembers scoped approvals; matching calls skip the request.
+	Rules *RuleSet
+}
+
+// Response is a decision on a pending approval request.
+type Response struct {
+	Approved          bool
+	Message           string
+	ModifiedArguments string
+
+	// DecidedBy identifies who decided. Defaults to "user".
+	DecidedBy string
+
+	// Scope remembers an approval beyond this call. Empty means ScopeOnce.
+	Scope RuleScope
+
+	// Pattern overrides the derived rule's argument pattern.
+	Pattern string
+
+	// TTL overrides the derived rule's default lifetime.
+	TTL time.Duration
 }
 
 // NewManager creates a new Manager.
@@ -73,6 +138,9 @@ func NewManager(config *ManagerConfig) *Manager {
 	if config != nil {
 		m.notifier = config.Notifier
 		m.logger = config.Logger
+		m.escalations = config.Escalations
+		m.links = config.Links
+		m.rules = config.Rules
 	}
 
 	return m
@@ -92,9 +160,71 @@ func (m *Manager) SetLogger(l ApprovalLogger) {
 	m.logger = l
 }
 
+// SetEscalations replaces the out-of-band notifiers. Requests already
+// pending keep their schedule.
+func (m *Manager) SetEscalations(escalations []Escalation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.escalations = escalations
+}
+
+// SetLinks sets the signer for one-click decision links.
+func (m *Manager) SetLinks(links *LinkSigner) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.links = links
+}
+
+// SetRules sets the remembered approval rules.
+func (m *Manager) SetRules(rules *RuleSet) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = rules
+}
+
+// Rules returns the remembered approval rules, or nil when disabled.
+func (m *Manager) Rules() *RuleSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.rules
+}
+
+// MatchRule returns the remembered rule that approves the call, if any.
+// Callers that announce requests before RequestApproval check it first.
+func (m *Manager) MatchRule(call *policy.ToolCall) (*Rule, bool) {
+	rules := m.Rules()
+	if rules == nil {
+		return nil, false
+	}
+	return rules.Match(call)
+}
+
+// Links returns the signer for decision links, or nil when disabled.
+func (m *Manager) Links() *LinkSigner {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.links
+}
+
 // RequestApproval creates a new approval request and waits for decision.
 // Blocks until approved, rejected, or timeout.
 func (m *Manager) RequestApproval(ctx context.Context, call *policy.ToolCall, reason string) (*ApprovalResult, error) {
+	// A remembered decision approves without asking
+	if rule, ok := m.MatchRule(call); ok {
+		m.slogger.Info("approval granted by remembered rule",
+			"rule_id", rule.ID,
+			"scope", rule.Scope,
+			"tool", call.Name,
+		)
+		return &ApprovalResult{
+			Approved:   true,
+			Message:    "approved by remembered rule",
+			ApprovedBy: "rule:" + rule.ID,
+			DecidedAt:  time.Now(),
+			Decision:   DecisionApproved,
+		}, nil
+	}
+
 	// Check max pending
 	m.mu.RLock()
 	if len(m.pending) >= m.maxPending {
@@ -110,14 +240,15 @@ func (m *Manager) RequestApproval(ctx context.Context, call *policy.ToolCall, re
 		approvalID = uuid.New().String()
 	}
 	req := &ApprovalRequest{
-		ID:        approvalID,
-		ToolName:  call.Name,
-		Arguments: call.Arguments,
-		Reason:    reason,
-		SessionID: call.SessionID,
-		AgentID:   call.AgentID,
-		CreatedAt: now,
-		ExpiresAt: now.Add(m.timeout),
+		ID:            approvalID,
+		ToolName:      call.Name,
+		Arguments:     call.Arguments,
+		Reason:        reason,
+		SessionID:     call.SessionID,
+		AgentID:       call.AgentID,
+		WorkspacePath: call.WorkspacePath,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(m.timeout),
 	}
 
 	// Create pending request
@@ -131,9 +262,24 @@ func (m *Manager) RequestApproval(ctx context.Context, call *policy.ToolCall, re
 		m.handleTimeout(req.ID)
 	})
 
-	// Store pending request
+	// Store pending request and schedule escalations
 	m.mu.Lock()
 	m.pending[req.ID] = pr
+	var immediate []ApprovalNotifier
+	for _, esc := range m.escalations {
+		if esc.Notifier == nil || esc.After >= m.timeout {
+			continue
+		}
+		if esc.After <= 0 {
+			immediate = append(immediate, esc.Notifier)
+			pr.notified = append(pr.notified, esc.Notifier)
+			continue
+		}
+		notifier := esc.Notifier
+		pr.escalations = append(pr.escalations, time.AfterFunc(esc.After, func() {
+			m.escalate(req.ID, notifier)
+		}))
+	}
 	m.mu.Unlock()
 
 	// Log request creation
@@ -149,7 +295,7 @@ func (m *Manager) RequestApproval(ctx context.Context, call *policy.ToolCall, re
 		}
 	}
 
-	// Notify via WebSocket
+	// Notify via WebSocket and the immediate out-of-band notifiers
 	if m.notifier != nil {
 		if err := m.notifier.NotifyRequest(req); err != nil {
 			m.slogger.Warn("failed to send approval notification",
@@ -158,6 +304,14 @@ func (m *Manager) RequestApproval(ctx context.Context, call *policy.ToolCall, re
 			)
 		}
 	}
+	for _, n := range immediate {
+		if err := n.NotifyRequest(req); err != nil {
+			m.slogger.Warn("failed to send approval notification",
+				"request_id", req.ID,
+				"error", err,
+			)
+		}
+	}
 
 	// Wait for result
 	select {
@@ -177,6 +331,98 @@ func (m *Manager) RequestApproval(ctx context.Context, call *policy.ToolCall, re
 
 // HandleResponse processes an approval response from UI.
 func (m *Manager) HandleResponse(requestID string, approved bool, message string, modifiedArguments ...string) error {
+	return m.resolve(requestID, approved, message, "user", modifiedArguments...)
+}
+
+// Respond processes a decision that may be remembered. An approval with a
+// scope other than ScopeOnce is stored as an allow rule, which is returned.
+// The rule is validated before the request is resolved, so an invalid
+// scope or pattern leaves the request pending.
+func (m *Manager) Respond(requestID string, resp Response) (*Rule, error) {
+	decidedBy := resp.DecidedBy
+	if decidedBy == "" {
+		decidedBy = "user"
+	}
+
+	var rule *Rule
+	if resp.Approved && resp.Scope != "" && resp.Scope != ScopeOnce {
+		req, ok := m.GetPending(requestID)
+		if !ok {
+			return nil, policy.ErrRequestNotFound
+		}
+		rules := m.Rules()
+		if rules == nil {
+			return nil, fmt.Errorf("approval: remembered decisions are not enabled")
+		}
+		var err error
+		if rule, err = rules.Derive(req, resp.Scope, resp.Pattern, resp.TTL); err != nil {
+			return nil, err
+		}
+		rule.CreatedBy = decidedBy
+	}
+
+	if err := m.resolve(requestID, resp.Approved, resp.Message, decidedBy, resp.ModifiedArguments); err != nil {
+		return nil, err
+	}
+	if rule != nil {
+		m.Rules().Add(rule)
+		m.slogger.Info("approval decision remembered",
+			"rule_id", rule.ID,
+			"scope", rule.Scope,
+			"tool", rule.Tool,
+		)
+	}
+	return rule, nil
+}
+
+// HandleResponseFrom processes a response that arrived out of band,
+// recording who made the decision (e.g. "telegram:ann" or "link").
+func (m *Manager) HandleResponseFrom(requestID string, approved bool, message, decidedBy string) error {
+	return m.resolve(requestID, approved, message, decidedBy)
+}
+
+// HandleReply resolves a pending request from a chat reply such as
+// "approve 1f2e3d4c", "approve 1f2e3d4c session" or "reject 1f2e3d4c too
+// risky". Request IDs may be shortened to an unambiguous prefix. It
+// returns handled=false when text is not an approval command, so the
+// caller can process it normally.
+func (m *Manager) HandleReply(text, decidedBy string) (reply string, handled bool) {
+	id, approved, reason, ok := ParseReply(text)
+	if !ok {
+		return "", false
+	}
+	req, found := m.FindPending(id)
+	if !found {
+		return "No pending approval request matches " + id + ".", true
+	}
+
+	resp := Response{Approved: approved, Message: reason, DecidedBy: decidedBy}
+	if approved {
+		switch strings.ToLower(reason) {
+		case "session", "for session":
+			resp.Scope, resp.Message = ScopeSession, ""
+		case "workspace", "always", "for workspace":
+			resp.Scope, resp.Message = ScopeWorkspace, ""
+		}
+	}
+	rule, err := m.Respond(req.ID, resp)
+	switch {
+	case errors.Is(err, policy.ErrRequestNotFound):
+		return "Approval request " + ShortID(req.ID) + " was already resolved.", true
+	case err != nil:
+		return "Could not remember the decision: " + err.Error(), true
+	}
+	if !approved {
+		return "Rejected " + req.ToolName + " (" + ShortID(req.ID) + ").", true
+	}
+	if rule != nil {
+		return "Approved " + req.ToolName + " (" + ShortID(req.ID) + ") and remembered for this " + string(rule.Scope) + ".", true
+	}
+	return "Approved " + req.ToolName + " (" + ShortID(req.ID) + ").", true
+}
+
+// resolve completes a pending request with the given decision.
+func (m *Manager) resolve(requestID string, approved bool, message, decidedBy string, modifiedArguments ...string) error {
 	m.mu.Lock()
 	pr, ok := m.pending[requestID]
 	if !ok {
@@ -184,13 +430,12 @@ func (m *Manager) HandleResponse(requestID string, approved bool, message string
 		return policy.ErrRequestNotFound
 	}
 
-	// Stop timeout timer
-	if pr.timer != nil {
-		pr.timer.Stop()
-	}
+	// Stop timeout and escalation timers
+	pr.stopTimers()
 
 	// Remove from pending
 	delete(m.pending, requestID)
+	notified := pr.notified
 	m.mu.Unlock()
 
 	// Create result
@@ -202,7 +447,7 @@ func (m *Manager) HandleResponse(requestID string, approved bool, message string
 	result := &ApprovalResult{
 		Approved:   approved,
 		Message:    message,
-		ApprovedBy: "user",
+		ApprovedBy: decidedBy,
 		DecidedAt:  time.Now(),
 		Decision:   decision,
 	}
@@ -215,6 +460,7 @@ func (m *Manager) HandleResponse(requestID string, approved bool, message string
 		"request_id", requestID,
 		"decision", decision,
 		"approved", approved,
+		"by", decidedBy,
 	)
 
 	if m.logger != nil {
@@ -224,14 +470,7 @@ func (m *Manager) HandleResponse(requestID string, approved bool, message string
 	}
 
 	// Notify resolution
-	if m.notifier != nil {
-		if err := m.notifier.NotifyResolved(pr.request, result); err != nil {
-			m.slogger.Warn("failed to send resolution notification",
-				"request_id", requestID,
-				"error", err,
-			)
-		}
-	}
+	m.notifyResolved(pr.request, result, notified)
 
 	// Send result to waiting goroutine
 	select {
@@ -243,6 +482,51 @@ func (m *Manager) HandleResponse(requestID string, approved bool, message string
 	return nil
 }
 
+// escalate notifies an escalation backend if the request is still pending.
+func (m *Manager) escalate(requestID string, notifier ApprovalNotifier) {
+	m.mu.Lock()
+	pr, ok := m.pending[requestID]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	pr.notified = append(pr.notified, notifier)
+	m.mu.Unlock()
+
+	m.slogger.Info("approval request escalated",
+		"request_id", requestID,
+		"tool", pr.request.ToolName,
+	)
+
+	if err := notifier.NotifyRequest(pr.request); err != nil {
+		m.slogger.Warn("failed to send escalation notification",
+			"request_id", requestID,
+			"error", err,
+		)
+	}
+}
+
+// notifyResolved tells the main notifier and every escalation notifier that
+// saw the request about its resolution.
+func (m *Manager) notifyResolved(req *ApprovalRequest, result *ApprovalResult, notified []ApprovalNotifier) {
+	if m.notifier != nil {
+		if err := m.notifier.NotifyResolved(req, result); err != nil {
+			m.slogger.Warn("failed to send resolution notification",
+				"request_id", req.ID,
+				"error", err,
+			)
+		}
+	}
+	for _, n := range notified {
+		if err := n.NotifyResolved(req, result); err != nil {
+			m.slogger.Warn("failed to send resolution notification",
+				"request_id", req.ID,
+				"error", err,
+			)
+		}
+	}
+}
+
 // handleTimeout handles approval request timeout.
 func (m *Manager) handleTimeout(requestID string) {
 	m.mu.Lock()
@@ -253,7 +537,9 @@ func (m *Manager) handleTimeout(requestID string) {
 	}
 
 	// Remove from pending
+	pr.stopTimers()
 	delete(m.pending, requestID)
+	notified := pr.notified
 	m.mu.Unlock()
 
 	// Create timeout result
@@ -277,14 +563,7 @@ func (m *Manager) handleTimeout(requestID string) {
 	}
 
 	// Notify resolution
-	if m.notifier != nil {
-		if err := m.notifier.NotifyResolved(pr.request, result); err != nil {
-			m.slogger.Warn("failed to send timeout notification",
-				"request_id", requestID,
-				"error", err,
-			)
-		}
-	}
+	m.notifyResolved(pr.request, result, notified)
 
 	// Send result to waiting goroutine
 	select {
@@ -299,9 +578,7 @@ func (m *Manager) cleanup(requestID string) {
 	defer m.mu.Unlock()
 
 	if pr, ok := m.pending[requestID]; ok {
-		if pr.timer != nil {
-			pr.timer.Stop()
-		}
+		pr.stopTimers()
 		delete(m.pending, requestID)
 	}
 }
@@ -317,6 +594,30 @@ func (m *Manager) GetPending(requestID string) (*ApprovalRequest, bool) {
 	return nil, false
 }
 
+// FindPending returns a pending request by its ID or by an unambiguous ID
+// prefix of at least four characters, as typed in chat replies.
+func (m *Manager) FindPending(idOrPrefix string) (*ApprovalRequest, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if pr, ok := m.pending[idOrPrefix]; ok {
+		return pr.request, true
+	}
+	if len(idOrPrefix) < 4 {
+		return nil, false
+	}
+	var match *ApprovalRequest
+	for id, pr := range m.pending {
+		if strings.HasPrefix(id, idOrPrefix) {
+			if match != nil {
+				return nil, false
+			}
+			match = pr.request
+		}
+	}
+	return match, match != nil
+}
+
 // ListPending returns all pending approval requests.
 func (m *Manager) ListPending() []*ApprovalRequest {
 	m.mu.RLock()
@@ -342,9 +643,7 @@ func (m *Manager) Close() {
 	defer m.mu.Unlock()
 
 	for id, pr := range m.pending {
-		if pr.timer != nil {
-			pr.timer.Stop()
-		}
+		pr.stopTimers()
 		// Send cancellation result
 		select {
 		case pr.done <- &ApprovalResult{
@@ -315,3 +315,78 @@ func TestManager_ConcurrentAccess(t *testing.T) {
 	wg.Wait()
 	assert.Equal(t, 0, manager.PendingCount())
 }
+
+func TestManager_Escalation(t *testing.T) {
+	ui := &mockNotifier{}
+	chat := &mockNotifier{}
+	push := &mockNotifier{}
+	never := &mockNotifier{}
+
+	manager := NewManager(&ManagerConfig{
+		Notifier: ui,
+		Timeout:  300 * time.Millisecond,
+		Escalations: []Escalation{
+			{After: 0, Notifier: chat},
+			{After: 100 * time.Millisecond, Notifier: push},
+			{After: time.Second, Notifier: never},
+		},
+	})
+	defer manager.Close()
+
+	call := &policy.ToolCall{Name: "shell", Arguments: `{"command": "sudo reboot"}`, SessionID: "cron-1"}
+	result, err := manager.RequestApproval(context.Background(), call, "sudo requires approval")
+	require.NoError(t, err)
+	assert.Equal(t, DecisionTimeout, result.Decision)
+
+	for name, n := range map[string]*mockNotifier{"ui": ui, "chat": chat, "push": push} {
+		n.mu.Lock()
+		assert.Len(t, n.requests, 1, name)
+		assert.Len(t, n.resolutions, 1, name)
+		n.mu.Unlock()
+	}
+	never.mu.Lock()
+	defer never.mu.Unlock()
+	assert.Empty(t, never.requests, "steps after the timeout are never reached")
+	assert.Empty(t, never.resolutions)
+}
+
+func TestManager_HandleReply(t *testing.T) {
+	push := &mockNotifier{}
+	manager := NewManager(&ManagerConfig{
+		Timeout:     5 * time.Second,
+		Escalations: []Escalation{{After: time.Second, Notifier: push}},
+	})
+	defer manager.Close()
+
+	reply, handled := manager.HandleReply("hello there", "telegram:ann")
+	assert.False(t, handled)
+	assert.Empty(t, reply)
+
+	call := &policy.ToolCall{Name: "shell", RequestID: "1f2e3d4c-aaaa-bbbb-cccc-000000000000"}
+	done := make(chan *ApprovalResult)
+	go func() {
+		result, _ := manager.RequestApproval(context.Background(), call, "")
+		done <- result
+	}()
+	require.Eventually(t, func() bool { return manager.PendingCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	reply, handled = manager.HandleReply("approve 0000", "telegram:ann")
+	assert.True(t, handled)
+	assert.Contains(t, reply, "No pending approval request")
+
+	reply, handled = manager.HandleReply("/Reject 1f2e3d4c looks wrong", "telegram:ann")
+	assert.True(t, handled)
+	assert.Equal(t, "Rejected shell (1f2e3d4c).", reply)
+
+	result := <-done
+	assert.False(t, result.Approved)
+	assert.Equal(t, DecisionRejected, result.Decision)
+	assert.Equal(t, "telegram:ann", result.ApprovedBy)
+	assert.Equal(t, "looks wrong", result.Message)
+
+	// The escalation was cancelled with the request
+	time.Sleep(1200 * time.Millisecond)
+	push.mu.Lock()
+	defer push.mu.Unlock()
+	assert.Empty(t, push.requests)
+}
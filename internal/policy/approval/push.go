@@ -0,0 +1,198 @@
+package approval
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decision link actions.
+const (
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+)
+
+var (
+	// ErrInvalidSignature indicates a decision link was forged or altered.
+	ErrInvalidSignature = errors.New("approval: invalid link signature")
+
+	// ErrLinkExpired indicates a decision link is past its expiry.
+	ErrLinkExpired = errors.New("approval: link expired")
+)
+
+// LinkSigner signs one-click decision links so approvers can answer from a
+// push notification or chat without opening the Web UI. Links carry the
+// request ID, action and expiry, authenticated with HMAC-SHA256.
+type LinkSigner struct {
+	baseURL string
+	secret  []byte
+	now     func() time.Time
+}
+
+// NewLinkSigner creates a LinkSigner for links under baseURL (the gateway's
+// externally reachable address). A random secret is generated when secret
+// is empty; pending requests do not survive a restart, so neither need the
+// links.
+func NewLinkSigner(baseURL string, secret []byte) *LinkSigner {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(fmt.Sprintf("approval: generate link secret: %v", err))
+		}
+	}
+	return &LinkSigner{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  secret,
+		now:     time.Now,
+	}
+}
+
+// Link returns the signed decision link for a request.
+func (s *LinkSigner) Link(requestID, action string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	q := url.Values{}
+	q.Set("action", action)
+	q.Set("exp", exp)
+	q.Set("sig", s.sign(requestID, action, exp))
+	return s.baseURL + "/api/v1/approvals/" + url.PathEscape(requestID) + "/decide?" + q.Encode()
+}
+
+// Verify checks a decision link's signature and expiry.
+func (s *LinkSigner) Verify(requestID, action, exp, sig string) error {
+	if action != ActionApprove && action != ActionReject {
+		return ErrInvalidSignature
+	}
+	want := s.sign(requestID, action, exp)
+	if !hmac.Equal([]byte(want), []byte(sig)) {
+		return ErrInvalidSignature
+	}
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if s.now().Unix() > expires {
+		return ErrLinkExpired
+	}
+	return nil
+}
+
+func (s *LinkSigner) sign(requestID, action, exp string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	_, _ = io.WriteString(mac, requestID+"\n"+action+"\n"+exp)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PushConfig configures a PushNotifier.
+type PushConfig struct {
+	// URL is the topic URL, e.g. https://ntfy.sh/my-approvals.
+	URL string
+
+	// Token is an optional bearer token for protected topics.
+	Token string
+
+	// Priority is the ntfy priority: min, low, default, high or urgent.
+	Priority string
+
+	// Timeout bounds each HTTP request. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// PushNotifier publishes approval requests to an ntfy-style HTTP push
+// service: the message is POSTed as plain text with the title, priority,
+// tags and actions in headers. With a LinkSigner the notification carries
+// Approve and Reject buttons that POST signed links back to the gateway.
+type PushNotifier struct {
+	config PushConfig
+	links  *LinkSigner
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewPushNotifier creates a PushNotifier. links may be nil.
+func NewPushNotifier(config PushConfig, links *LinkSigner) *PushNotifier {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &PushNotifier{
+		config: config,
+		links:  links,
+		client: &http.Client{Timeout: config.Timeout},
+		logger: slog.Default(),
+	}
+}
+
+// NotifyRequest publishes the request with decision buttons.
+func (n *PushNotifier) NotifyRequest(req *ApprovalRequest) error {
+	headers := map[string]string{
+		"Title": "Approval needed: " + req.ToolName,
+		"Tags":  "lock",
+	}
+	if n.config.Priority != "" {
+		headers["Priority"] = n.config.Priority
+	}
+	if n.links != nil {
+		approve := n.links.Link(req.ID, ActionApprove, req.ExpiresAt)
+		reject := n.links.Link(req.ID, ActionReject, req.ExpiresAt)
+		headers["Actions"] = "http, Approve, " + approve + ", method=POST, clear=true; " +
+			"http, Reject, " + reject + ", method=POST, clear=true"
+		headers["Click"] = approve
+	}
+	return n.publish(req, FormatRequestText(req), headers)
+}
+
+// NotifyResolved publishes the outcome at low priority.
+func (n *PushNotifier) NotifyResolved(req *ApprovalRequest, result *ApprovalResult) error {
+	tag := "x"
+	if result.Approved {
+		tag = "white_check_mark"
+	}
+	return n.publish(req, FormatResolvedText(req, result), map[string]string{
+		"Title":    "Approval " + string(result.Decision) + ": " + req.ToolName,
+		"Tags":     tag,
+		"Priority": "low",
+	})
+}
+
+func (n *PushNotifier) publish(req *ApprovalRequest, body string, headers map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), n.config.Timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("notifier: create push request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+	if n.config.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+n.config.Token)
+	}
+
+	resp, err := n.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("notifier: push: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notifier: push: unexpected status %d", resp.StatusCode)
+	}
+
+	n.logger.Debug("notifier: pushed approval message",
+		"request_id", req.ID,
+		"url", n.config.URL,
+	)
+	return nil
+}
@@ -0,0 +1,85 @@
+package approval
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkSigner(t *testing.T) {
+	signer := NewLinkSigner("http://localhost:18788", []byte("secret"))
+	expires := time.Now().Add(time.Minute)
+
+	link, err := url.Parse(signer.Link("req-1", ActionApprove, expires))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/approvals/req-1/decide", link.Path)
+	q := link.Query()
+
+	require.NoError(t, signer.Verify("req-1", ActionApprove, q.Get("exp"), q.Get("sig")))
+	assert.ErrorIs(t, signer.Verify("req-2", ActionApprove, q.Get("exp"), q.Get("sig")), ErrInvalidSignature)
+	assert.ErrorIs(t, signer.Verify("req-1", ActionReject, q.Get("exp"), q.Get("sig")), ErrInvalidSignature)
+	assert.ErrorIs(t, signer.Verify("req-1", ActionApprove, "9999999999", q.Get("sig")), ErrInvalidSignature)
+
+	other := NewLinkSigner("http://localhost:18788", nil)
+	assert.ErrorIs(t, other.Verify("req-1", ActionApprove, q.Get("exp"), q.Get("sig")), ErrInvalidSignature)
+
+	signer.now = func() time.Time { return expires.Add(time.Second) }
+	assert.ErrorIs(t, signer.Verify("req-1", ActionApprove, q.Get("exp"), q.Get("sig")), ErrLinkExpired)
+}
+
+func TestPushNotifier(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		bodies   []string
+		headers  []http.Header
+		failNext bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failNext {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		headers = append(headers, r.Header.Clone())
+	}))
+	defer server.Close()
+
+	links := NewLinkSigner("https://mote.example.com", []byte("secret"))
+	notifier := NewPushNotifier(PushConfig{URL: server.URL + "/mote-approvals", Token: "tk", Priority: "high"}, links)
+
+	req := &ApprovalRequest{
+		ID:        "req-1",
+		ToolName:  "shell",
+		Arguments: `{"command": "sudo reboot"}`,
+		ExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	require.NoError(t, notifier.NotifyRequest(req))
+	require.NoError(t, notifier.NotifyResolved(req, &ApprovalResult{Decision: DecisionTimeout}))
+
+	mu.Lock()
+	require.Len(t, bodies, 2)
+	assert.Contains(t, bodies[0], "sudo reboot")
+	assert.Equal(t, "Approval needed: shell", headers[0].Get("Title"))
+	assert.Equal(t, "high", headers[0].Get("Priority"))
+	assert.Equal(t, "Bearer tk", headers[0].Get("Authorization"))
+	actions := headers[0].Get("Actions")
+	assert.True(t, strings.HasPrefix(actions, "http, Approve, https://mote.example.com/api/v1/approvals/req-1/decide?action=approve"), actions)
+	assert.Contains(t, actions, "http, Reject, https://mote.example.com/api/v1/approvals/req-1/decide?action=reject")
+	assert.Contains(t, bodies[1], "rejected automatically")
+	assert.Equal(t, "low", headers[1].Get("Priority"))
+	failNext = true
+	mu.Unlock()
+
+	assert.Error(t, notifier.NotifyRequest(req))
+}
@@ -0,0 +1,306 @@
+package approval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mote/internal/policy"
+)
+
+// RuleScope is how far an approval decision extends.
+type RuleScope string
+
+const (
+	// ScopeOnce approves only the call being decided.
+	ScopeOnce RuleScope = "once"
+
+	// ScopeSession approves matching calls of the tool in the same session.
+	ScopeSession RuleScope = "session"
+
+	// ScopeWorkspace approves matching calls of the tool in the same workspace.
+	ScopeWorkspace RuleScope = "workspace"
+)
+
+// rulesKey is the KV key holding the remembered rules.
+const rulesKey = "approval_rules"
+
+// ErrRuleNotFound indicates the approval rule does not exist.
+var ErrRuleNotFound = errors.New("approval: rule not found")
+
+// Rule is an allow rule derived from an approval decision. Calls of Tool
+// whose canonical arguments match Pattern, in the rule's session or
+// workspace, are approved without asking again until the rule expires.
+type Rule struct {
+	ID    string    `json:"id"`
+	Scope RuleScope `json:"scope"`
+	Tool  string    `json:"tool"`
+
+	// Pattern is a regular expression matched against the call arguments
+	// as compact JSON with sorted keys. Derived rules match the approved
+	// arguments exactly.
+	Pattern string `json:"pattern"`
+
+	SessionID     string `json:"session_id,omitempty"`
+	WorkspacePath string `json:"workspace_path,omitempty"`
+
+	CreatedBy string    `json:"created_by,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// ExpiresAt is when the rule stops applying; zero means never.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+
+	// Hits counts the calls approved by the rule.
+	Hits int `json:"hits"`
+
+	re *regexp.Regexp
+}
+
+// matches reports whether the rule covers the call.
+func (r *Rule) matches(call *policy.ToolCall, canonical string) bool {
+	if r.Tool != call.Name {
+		return false
+	}
+	switch r.Scope {
+	case ScopeSession:
+		if call.SessionID == "" || r.SessionID != call.SessionID {
+			return false
+		}
+	case ScopeWorkspace:
+		if call.WorkspacePath == "" || r.WorkspacePath != call.WorkspacePath {
+			return false
+		}
+	default:
+		return false
+	}
+	return r.re.MatchString(canonical)
+}
+
+// RuleStore persists remembered rules; *storage.DB implements it.
+type RuleStore interface {
+	KVGet(key string) (string, error)
+	KVSet(key, value string, ttl time.Duration) error
+}
+
+// RuleSet holds the remembered approval rules.
+type RuleSet struct {
+	mu    sync.Mutex
+	rules []*Rule
+	store RuleStore
+	now   func() time.Time
+
+	sessionTTL   time.Duration
+	workspaceTTL time.Duration
+}
+
+// NewRuleSet creates a RuleSet and loads the rules saved in store. store
+// may be nil to keep rules in memory. sessionTTL and workspaceTTL are the
+// default lifetimes of derived rules; zero means they never expire.
+func NewRuleSet(store RuleStore, sessionTTL, workspaceTTL time.Duration) *RuleSet {
+	s := &RuleSet{
+		store:        store,
+		now:          time.Now,
+		sessionTTL:   sessionTTL,
+		workspaceTTL: workspaceTTL,
+	}
+	s.load()
+	return s
+}
+
+// Derive builds an allow rule for a pending request without adding it.
+// An empty pattern matches the request's arguments exactly; ttl overrides
+// the scope's default lifetime when positive.
+func (s *RuleSet) Derive(req *ApprovalRequest, scope RuleScope, pattern string, ttl time.Duration) (*Rule, error) {
+	rule := &Rule{
+		ID:        uuid.New().String(),
+		Scope:     scope,
+		Tool:      req.ToolName,
+		Pattern:   pattern,
+		RequestID: req.ID,
+		CreatedAt: s.now(),
+	}
+	switch scope {
+	case ScopeSession:
+		if req.SessionID == "" {
+			return nil, fmt.Errorf("approval: request has no session to remember the decision for")
+		}
+		rule.SessionID = req.SessionID
+		if ttl <= 0 {
+			ttl = s.sessionTTL
+		}
+	case ScopeWorkspace:
+		if req.WorkspacePath == "" {
+			return nil, fmt.Errorf("approval: request has no workspace to remember the decision for")
+		}
+		rule.WorkspacePath = req.WorkspacePath
+		if ttl <= 0 {
+			ttl = s.workspaceTTL
+		}
+	default:
+		return nil, fmt.Errorf("approval: invalid scope %q (must be once, session or workspace)", scope)
+	}
+	if ttl > 0 {
+		rule.ExpiresAt = rule.CreatedAt.Add(ttl)
+	}
+	if rule.Pattern == "" {
+		rule.Pattern = "^" + regexp.QuoteMeta(CanonicalArguments(req.Arguments)) + "$"
+	}
+	if err := rule.compile(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Add stores a rule.
+func (s *RuleSet) Add(rule *Rule) {
+	s.mu.Lock()
+	s.rules = append(s.rules, rule)
+	s.mu.Unlock()
+	s.save()
+}
+
+// Match returns the first unexpired rule covering the call and counts the hit.
+func (s *RuleSet) Match(call *policy.ToolCall) (*Rule, bool) {
+	canonical := CanonicalArguments(call.Arguments)
+
+	s.mu.Lock()
+	s.pruneLocked()
+	var match *Rule
+	for _, rule := range s.rules {
+		if rule.matches(call, canonical) {
+			rule.Hits++
+			copied := *rule
+			match = &copied
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if match == nil {
+		return nil, false
+	}
+	s.save()
+	return match, true
+}
+
+// List returns the unexpired rules, newest first.
+func (s *RuleSet) List() []*Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+	result := make([]*Rule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		copied := *rule
+		result = append(result, &copied)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+// Revoke removes a rule.
+func (s *RuleSet) Revoke(id string) error {
+	s.mu.Lock()
+	found := false
+	for i, rule := range s.rules {
+		if rule.ID == id {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			found = true
+			break
+		}
+	}
+	s.mu.Unlock()
+	if !found {
+		return ErrRuleNotFound
+	}
+	s.save()
+	return nil
+}
+
+// pruneLocked drops expired rules; the caller must hold s.mu.
+func (s *RuleSet) pruneLocked() {
+	now := s.now()
+	kept := s.rules[:0]
+	for _, rule := range s.rules {
+		if rule.ExpiresAt.IsZero() || now.Before(rule.ExpiresAt) {
+			kept = append(kept, rule)
+		}
+	}
+	s.rules = kept
+}
+
+func (s *RuleSet) load() {
+	if s.store == nil {
+		return
+	}
+	data, err := s.store.KVGet(rulesKey)
+	if err != nil || data == "" {
+		return
+	}
+	var rules []*Rule
+	if err := json.Unmarshal([]byte(data), &rules); err != nil {
+		slog.Warn("failed to load approval rules", "error", err)
+		return
+	}
+	for _, rule := range rules {
+		if err := rule.compile(); err != nil {
+			slog.Warn("skipping invalid approval rule", "id", rule.ID, "error", err)
+			continue
+		}
+		s.rules = append(s.rules, rule)
+	}
+}
+
+func (s *RuleSet) save() {
+	if s.store == nil {
+		return
+	}
+	s.mu.Lock()
+	data, err := json.Marshal(s.rules)
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+	if err := s.store.KVSet(rulesKey, string(data), 0); err != nil {
+		slog.Warn("failed to save approval rules", "error", err)
+	}
+}
+
+func (r *Rule) compile() error {
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("approval: invalid rule pattern %q: %w", r.Pattern, err)
+	}
+	r.re = re
+	return nil
+}
+
+// CanonicalArguments re-encodes JSON arguments compactly with sorted keys,
+// so rule patterns do not depend on how the model formatted the call.
+// Arguments that are not valid JSON are returned unchanged.
+func CanonicalArguments(arguments string) string {
+	dec := json.NewDecoder(strings.NewReader(arguments))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return arguments
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return arguments
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,158 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mote/internal/policy"
+)
+
+// memRuleStore is an in-memory RuleStore.
+type memRuleStore struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func (s *memRuleStore) KVGet(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func (s *memRuleStore) KVSet(key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func TestRuleSet_DeriveAndMatch(t *testing.T) {
+	store := &memRuleStore{data: map[string]string{}}
+	rules := NewRuleSet(store, time.Hour, 0)
+
+	req := &ApprovalRequest{
+		ID:            "req-1",
+		ToolName:      "shell",
+		Arguments:     `{"command": "git status", "cwd": "/repo"}`,
+		SessionID:     "s1",
+		WorkspacePath: "/repo",
+	}
+	session, err := rules.Derive(req, ScopeSession, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "s1", session.SessionID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), session.ExpiresAt, time.Minute)
+	rules.Add(session)
+
+	// Same arguments in another key order and spacing
+	_, ok := rules.Match(&policy.ToolCall{Name: "shell", Arguments: `{"cwd":"/repo","command":"git status"}`, SessionID: "s1"})
+	assert.True(t, ok)
+	_, ok = rules.Match(&policy.ToolCall{Name: "shell", Arguments: `{"command": "git push", "cwd": "/repo"}`, SessionID: "s1"})
+	assert.False(t, ok, "other arguments")
+	_, ok = rules.Match(&policy.ToolCall{Name: "shell", Arguments: req.Arguments, SessionID: "s2"})
+	assert.False(t, ok, "other session")
+
+	workspace, err := rules.Derive(req, ScopeWorkspace, `"command":"git (status|log)\b`, 0)
+	require.NoError(t, err)
+	assert.True(t, workspace.ExpiresAt.IsZero())
+	rules.Add(workspace)
+	rule, ok := rules.Match(&policy.ToolCall{Name: "shell", Arguments: `{"command": "git log -5"}`, SessionID: "s9", WorkspacePath: "/repo"})
+	require.True(t, ok)
+	assert.Equal(t, workspace.ID, rule.ID)
+	_, ok = rules.Match(&policy.ToolCall{Name: "shell", Arguments: `{"command": "git log -5"}`, WorkspacePath: "/other"})
+	assert.False(t, ok, "other workspace")
+
+	_, err = rules.Derive(req, ScopeWorkspace, "(", 0)
+	assert.Error(t, err)
+	_, err = rules.Derive(&ApprovalRequest{ToolName: "shell"}, ScopeWorkspace, "", 0)
+	assert.Error(t, err, "no workspace")
+	_, err = rules.Derive(req, "forever", "", 0)
+	assert.Error(t, err)
+
+	// Rules survive a restart, expired ones are dropped
+	reloaded := NewRuleSet(store, time.Hour, 0)
+	require.Len(t, reloaded.List(), 2)
+	reloaded.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	list := reloaded.List()
+	require.Len(t, list, 1)
+	assert.Equal(t, workspace.ID, list[0].ID)
+	assert.Equal(t, 1, list[0].Hits)
+
+	require.NoError(t, reloaded.Revoke(workspace.ID))
+	assert.ErrorIs(t, reloaded.Revoke(workspace.ID), ErrRuleNotFound)
+	assert.Empty(t, reloaded.List())
+}
+
+func TestManager_RememberedDecision(t *testing.T) {
+	notifier := &mockNotifier{}
+	manager := NewManager(&ManagerConfig{
+		Notifier: notifier,
+		Timeout:  5 * time.Second,
+		Rules:    NewRuleSet(nil, time.Hour, 0),
+	})
+	defer manager.Close()
+
+	call := &policy.ToolCall{Name: "shell", Arguments: `{"command": "git status"}`, SessionID: "s1", RequestID: "req-1"}
+	done := make(chan *ApprovalResult)
+	go func() {
+		result, _ := manager.RequestApproval(context.Background(), call, "")
+		done <- result
+	}()
+	require.Eventually(t, func() bool { return manager.PendingCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	// An invalid pattern leaves the request pending
+	_, err := manager.Respond("req-1", Response{Approved: true, Scope: ScopeSession, Pattern: "("})
+	require.Error(t, err)
+	assert.Equal(t, 1, manager.PendingCount())
+
+	rule, err := manager.Respond("req-1", Response{Approved: true, Scope: ScopeSession})
+	require.NoError(t, err)
+	require.NotNil(t, rule)
+	assert.Equal(t, "user", rule.CreatedBy)
+	assert.True(t, (<-done).Approved)
+
+	// The next identical call is approved without a new request
+	call.RequestID = "req-2"
+	result, err := manager.RequestApproval(context.Background(), call, "")
+	require.NoError(t, err)
+	assert.True(t, result.Approved)
+	assert.Equal(t, "rule:"+rule.ID, result.ApprovedBy)
+	notifier.mu.Lock()
+	assert.Len(t, notifier.requests, 1)
+	notifier.mu.Unlock()
+
+	// Rejections are never remembered
+	call = &policy.ToolCall{Name: "shell", Arguments: `{"command": "rm -r build"}`, SessionID: "s1", RequestID: "req-3"}
+	go func() {
+		result, _ := manager.RequestApproval(context.Background(), call, "")
+		done <- result
+	}()
+	require.Eventually(t, func() bool { return manager.PendingCount() == 1 }, time.Second, 10*time.Millisecond)
+	rule, err = manager.Respond("req-3", Response{Approved: false, Scope: ScopeSession})
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+	assert.False(t, (<-done).Approved)
+	assert.Len(t, manager.Rules().List(), 1)
+
+	// Chat replies can remember too
+	call = &policy.ToolCall{Name: "shell", Arguments: `{"command": "make test"}`, SessionID: "s1", RequestID: "abcdef12-0000"}
+	go func() {
+		result, _ := manager.RequestApproval(context.Background(), call, "")
+		done <- result
+	}()
+	require.Eventually(t, func() bool { return manager.PendingCount() == 1 }, time.Second, 10*time.Millisecond)
+	reply, handled := manager.HandleReply("approve abcdef12 session", "slack:ann")
+	assert.True(t, handled)
+	assert.Contains(t, reply, "remembered for this session")
+	assert.True(t, (<-done).Approved)
+	assert.Len(t, manager.Rules().List(), 2)
+}
@@ -42,6 +42,9 @@ type ApprovalRequest struct {
 	// AgentID is the agent making the request.
 	AgentID string `json:"agent_id"`
 
+	// WorkspacePath is the workspace bound to the session, if any.
+	WorkspacePath string `json:"workspace_path,omitempty"`
+
 	// CreatedAt is when the request was created.
 	CreatedAt time.Time `json:"created_at"`
 
@@ -88,12 +91,13 @@ type ApprovalHandler interface {
 	ListPending() []*ApprovalRequest
 }
 
-// ApprovalNotifier sends approval notifications via WebSocket.
+// ApprovalNotifier sends approval notifications, e.g. via WebSocket, a
+// channel plugin or a push service.
 type ApprovalNotifier interface {
-	// NotifyRequest broadcasts a new approval request.
+	// NotifyRequest announces a new approval request.
 	NotifyRequest(req *ApprovalRequest) error
 
-	// NotifyResolved broadcasts the resolution of an approval request.
+	// NotifyResolved announces the resolution of an approval request.
 	NotifyResolved(req *ApprovalRequest, result *ApprovalResult) error
 }
 
@@ -47,14 +47,14 @@ func DefaultPolicy() ToolPolicy {
 		Allowlist:       []string{},
 		DangerousOps: []DangerousOpRule{
 			{
-				Tool:     "shell",
+				Tool:     "group:runtime",
 				Pattern:  `rm\s+(-[rf]+\s+)*(-[rf]+)`,
 				Severity: "critical",
 				Action:   "block",
 				Message:  "rm -rf is prohibited",
 			},
 			{
-				Tool:     "shell",
+				Tool:     "group:runtime",
 				Pattern:  `sudo\s+`,
 				Severity: "high",
 				Action:   "approve",
@@ -69,42 +69,42 @@ func DefaultPolicy() ToolPolicy {
 			},
 			// M08B: High-risk command patterns
 			{
-				Tool:     "shell",
+				Tool:     "group:runtime",
 				Pattern:  `curl\s+.*\|\s*(ba)?sh`,
 				Severity: "critical",
 				Action:   "block",
 				Message:  "pipe to shell execution is prohibited",
 			},
 			{
-				Tool:     "shell",
+				Tool:     "group:runtime",
 				Pattern:  `wget\s+.*\|\s*(ba)?sh`,
 				Severity: "critical",
 				Action:   "block",
 				Message:  "pipe to shell execution is prohibited",
 			},
 			{
-				Tool:     "shell",
+				Tool:     "group:runtime",
 				Pattern:  `python[23]?\s+-c\s+`,
 				Severity: "medium",
 				Action:   "approve",
 				Message:  "inline Python code requires approval",
 			},
 			{
-				Tool:     "shell",
+				Tool:     "group:runtime",
 				Pattern:  `node\s+-e\s+`,
 				Severity: "medium",
 				Action:   "approve",
 				Message:  "inline Node.js code requires approval",
 			},
 			{
-				Tool:     "shell",
+				Tool:     "group:runtime",
 				Pattern:  `base64\s+(-d|--decode)`,
 				Severity: "medium",
 				Action:   "approve",
 				Message:  "base64 decode requires approval",
 			},
 			{
-				Tool:     "shell",
+				Tool:     "group:runtime",
 				Pattern:  `chmod\s+(777|[+]s)`,
 				Severity: "high",
 				Action:   "approve",
@@ -182,6 +182,31 @@ func ValidateConfig(config *Config) error {
 		}
 	}
 
+	// Validate conditional rules and overlays
+	if err := validateRules("rules", config.ToolPolicy.Rules); err != nil {
+		return err
+	}
+	if err := validateEgress("egress", config.ToolPolicy.Egress); err != nil {
+		return err
+	}
+	for i := range config.ToolPolicy.Overlays {
+		overlay := &config.ToolPolicy.Overlays[i]
+		if overlay.Agent == "" && overlay.Workspace == "" {
+			return fmt.Errorf("policy: overlays[%d]: must specify agent or workspace", i)
+		}
+		if err := validateRules(fmt.Sprintf("overlays[%d].rules", i), overlay.Rules); err != nil {
+			return err
+		}
+		if err := validateEgress(fmt.Sprintf("overlays[%d].egress", i), overlay.Egress); err != nil {
+			return err
+		}
+		for j, rule := range overlay.DangerousOps {
+			if _, err := rule.CompiledPattern(); err != nil {
+				return fmt.Errorf("policy: overlays[%d].dangerous_ops[%d]: invalid pattern '%s': %w", i, j, rule.Pattern, err)
+			}
+		}
+	}
+
 	// Validate approval config
 	if config.Approval.Timeout < 0 {
 		return fmt.Errorf("policy: approval.timeout must be non-negative")
@@ -263,6 +288,23 @@ func MergePolicy(base, override *ToolPolicy) *ToolPolicy {
 	if len(override.ScrubRules) > 0 {
 		merged.ScrubRules = override.ScrubRules
 	}
+	if len(override.Overlays) > 0 {
+		merged.Overlays = override.Overlays
+	}
+	if override.FileAccess != nil {
+		merged.FileAccess = override.FileAccess
+	}
+	if override.Egress != nil {
+		merged.Egress = override.Egress
+	}
+
+	// Conditional rules accumulate so an override can add conditions
+	// without dropping the base ones
+	if len(override.Rules) > 0 {
+		merged.Rules = make([]PolicyRule, 0, len(base.Rules)+len(override.Rules))
+		merged.Rules = append(merged.Rules, base.Rules...)
+		merged.Rules = append(merged.Rules, override.Rules...)
+	}
 	if override.BlockMessageTemplate != "" {
 		merged.BlockMessageTemplate = override.BlockMessageTemplate
 	}
@@ -259,6 +259,18 @@ func TestMergePolicy(t *testing.T) {
 	assert.Len(t, merged.DangerousOps, 1) // Not overridden
 }
 
+func TestMergePolicy_Rules(t *testing.T) {
+	base := &ToolPolicy{Rules: []PolicyRule{{Name: "base", Action: "warn"}}}
+	override := &ToolPolicy{Rules: []PolicyRule{{Name: "override", Action: "block"}}}
+
+	merged := MergePolicy(base, override)
+
+	require.Len(t, merged.Rules, 2)
+	assert.Equal(t, "base", merged.Rules[0].Name)
+	assert.Equal(t, "override", merged.Rules[1].Name)
+	assert.Len(t, base.Rules, 1) // Base not modified
+}
+
 func TestMergePolicy_NilInputs(t *testing.T) {
 	policy := &ToolPolicy{DefaultAllow: true}
 
@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Decision records one policy check: the call, the context it was
+// evaluated in and the result. A recorded decision can be replayed with
+// Evaluate or `mote policy test`.
+type Decision struct {
+	Timestamp time.Time    `json:"timestamp"`
+	Call      ToolCall     `json:"call"`
+	Context   EvalContext  `json:"context"`
+	Result    PolicyResult `json:"result"`
+}
+
+// DecisionLogger records policy decisions.
+type DecisionLogger interface {
+	LogDecision(d *Decision) error
+}
+
+// FileDecisionLog appends decisions to a JSON lines file.
+type FileDecisionLog struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileDecisionLog opens (or creates) a decision log file.
+func NewFileDecisionLog(path string) (*FileDecisionLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("policy: failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("policy: failed to open decision log: %w", err)
+	}
+	return &FileDecisionLog{path: path, file: file}, nil
+}
+
+// LogDecision appends a decision.
+func (l *FileDecisionLog) LogDecision(d *Decision) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("policy: failed to marshal decision: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("policy: failed to write decision: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file.
+func (l *FileDecisionLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Path returns the log file path.
+func (l *FileDecisionLog) Path() string {
+	return l.path
+}
+
+// MemoryDecisionLog keeps the most recent decisions in memory.
+type MemoryDecisionLog struct {
+	mu      sync.RWMutex
+	entries []Decision
+	maxSize int
+}
+
+// NewMemoryDecisionLog creates an in-memory log holding up to maxSize
+// decisions (default 1000).
+func NewMemoryDecisionLog(maxSize int) *MemoryDecisionLog {
+	if maxSize <= 0 {
+		maxSize = 1000
+	}
+	return &MemoryDecisionLog{maxSize: maxSize}
+}
+
+// LogDecision stores a decision, dropping the oldest when full.
+func (l *MemoryDecisionLog) LogDecision(d *Decision) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) >= l.maxSize {
+		l.entries = l.entries[1:]
+	}
+	l.entries = append(l.entries, *d)
+	return nil
+}
+
+// Recent returns up to limit decisions, newest first. A limit of zero or
+// less returns all of them.
+func (l *MemoryDecisionLog) Recent(limit int) []Decision {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if limit <= 0 || limit > len(l.entries) {
+		limit = len(l.entries)
+	}
+	result := make([]Decision, 0, limit)
+	for i := len(l.entries) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, l.entries[i])
+	}
+	return result
+}
@@ -0,0 +1,332 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// EgressPolicy limits the hosts tools and scripts may connect to. It is
+// enforced when the builtin http tool and mote.http dial out, and by the
+// local proxy that processes started by tools are pointed at.
+//
+// Host entries are domain names ("api.github.com"), "*.example.com" for
+// any subdomain, "*" for every host, IP addresses or CIDR ranges.
+type EgressPolicy struct {
+	// Allow lists the hosts that may be reached. Empty allows any host
+	// not denied.
+	Allow []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+
+	// Deny lists hosts that may not be reached. Takes precedence over Allow.
+	Deny []string `yaml:"deny,omitempty" json:"deny,omitempty"`
+
+	// BlockPrivate blocks loopback, private and link-local addresses
+	// unless the host or address is explicitly allowed.
+	BlockPrivate bool `yaml:"block_private,omitempty" json:"block_private,omitempty"`
+}
+
+// privateNetworks are the ranges blocked by BlockPrivate.
+var privateNetworks = mustParseCIDRs(
+	"127.0.0.0/8",    // Loopback
+	"10.0.0.0/8",     // RFC 1918
+	"172.16.0.0/12",  // RFC 1918
+	"192.168.0.0/16", // RFC 1918
+	"169.254.0.0/16", // Link-local
+	"100.64.0.0/10",  // CGNAT
+	"0.0.0.0/8",      // Unspecified
+	"::1/128",        // IPv6 loopback
+	"fc00::/7",       // IPv6 ULA
+	"fe80::/10",      // IPv6 link-local
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// IsPrivateIP reports whether ip is a loopback, private, link-local or
+// otherwise internal address.
+func IsPrivateIP(ip net.IP) bool {
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostEntry is a parsed Allow or Deny entry.
+type hostEntry struct {
+	name    string // lower-case domain, "*.suffix" or "*"
+	network *net.IPNet
+}
+
+func parseHostEntry(s string) hostEntry {
+	s = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), "."))
+	if _, network, err := net.ParseCIDR(s); err == nil {
+		return hostEntry{network: network}
+	}
+	if ip := net.ParseIP(s); ip != nil {
+		bits := 8 * net.IPv4len
+		if ip.To4() == nil {
+			bits = 8 * net.IPv6len
+		}
+		return hostEntry{network: &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}}
+	}
+	return hostEntry{name: s}
+}
+
+// matchName reports whether the entry matches the host name.
+func (h hostEntry) matchName(host string) bool {
+	switch {
+	case h.name == "":
+		return false
+	case h.name == "*":
+		return true
+	case strings.HasPrefix(h.name, "*."):
+		return strings.HasSuffix(host, h.name[1:])
+	}
+	return host == h.name
+}
+
+// matchIP reports whether the entry matches the address.
+func (h hostEntry) matchIP(ip net.IP) bool {
+	return h.network != nil && h.network.Contains(ip)
+}
+
+// check returns the matched rule and reason if connecting to host (which
+// resolves to ips) is denied, or empty strings if it is allowed.
+func (p *EgressPolicy) check(host string, ips []net.IP) (rule, reason string) {
+	if p == nil {
+		return "", ""
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	for _, e := range p.Deny {
+		entry := parseHostEntry(e)
+		if entry.matchName(host) {
+			return "egress:deny", fmt.Sprintf("host '%s' is denied by egress policy", host)
+		}
+		for _, ip := range ips {
+			if entry.matchIP(ip) {
+				return "egress:deny", fmt.Sprintf("host '%s' (%s) is denied by egress policy", host, ip)
+			}
+		}
+	}
+
+	allow := make([]hostEntry, 0, len(p.Allow))
+	nameAllowed := false
+	for _, e := range p.Allow {
+		entry := parseHostEntry(e)
+		allow = append(allow, entry)
+		if entry.matchName(host) {
+			nameAllowed = true
+		}
+	}
+	ipAllowed := func(ip net.IP) bool {
+		for _, entry := range allow {
+			if entry.matchIP(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if p.BlockPrivate {
+		// "*" does not count as explicitly allowing internal hosts
+		explicit := false
+		for _, entry := range allow {
+			if entry.name != "*" && entry.matchName(host) {
+				explicit = true
+			}
+		}
+		for _, ip := range ips {
+			if IsPrivateIP(ip) && !explicit && !ipAllowed(ip) {
+				return "egress:private", fmt.Sprintf("host '%s' resolves to private address %s", host, ip)
+			}
+		}
+	}
+
+	if len(allow) == 0 || nameAllowed {
+		return "", ""
+	}
+	if len(ips) > 0 {
+		all := true
+		for _, ip := range ips {
+			if !ipAllowed(ip) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return "", ""
+		}
+	}
+	return "egress:allow", fmt.Sprintf("host '%s' is not in the egress allowlist %v", host, p.Allow)
+}
+
+// validateEgress checks host entries; field names the policy in error
+// messages.
+func validateEgress(field string, p *EgressPolicy) error {
+	if p == nil {
+		return nil
+	}
+	lists := []struct {
+		name    string
+		entries []string
+	}{{"allow", p.Allow}, {"deny", p.Deny}}
+	for _, list := range lists {
+		name := list.name
+		for i, e := range list.entries {
+			e = strings.TrimSpace(e)
+			if e == "" {
+				return fmt.Errorf("policy: %s.%s[%d]: host must not be empty", field, name, i)
+			}
+			if strings.Contains(e, "/") {
+				if _, _, err := net.ParseCIDR(e); err != nil {
+					return fmt.Errorf("policy: %s.%s[%d]: invalid CIDR '%s': %w", field, name, i, e, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// EgressRequest identifies who is connecting out.
+type EgressRequest struct {
+	// Tool is the tool, script or MCP server making the connection.
+	Tool string
+	// SessionID and AgentID identify the caller; overlays for the agent
+	// apply.
+	SessionID string
+	AgentID   string
+}
+
+// EgressError is returned when a connection is blocked by the egress
+// policy.
+type EgressError struct {
+	Host   string
+	Reason string
+	// Rule is the matched rule: egress:allow, egress:deny or egress:private.
+	Rule string
+}
+
+func (e *EgressError) Error() string {
+	return fmt.Sprintf("egress to '%s' blocked: %s", e.Host, e.Reason)
+}
+
+// EgressGuard applies the executor's egress policy (with overlays for the
+// caller's agent) to outbound connections. Blocked connections are
+// recorded as decisions alongside the executor's own.
+type EgressGuard struct {
+	executor *PolicyExecutor
+	resolver *net.Resolver
+	dialer   *net.Dialer
+}
+
+// NewEgressGuard creates an EgressGuard.
+func NewEgressGuard(executor *PolicyExecutor) *EgressGuard {
+	return &EgressGuard{
+		executor: executor,
+		resolver: net.DefaultResolver,
+		dialer:   &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+	}
+}
+
+// policyFor returns the egress policy that applies to req, or nil.
+func (g *EgressGuard) policyFor(req EgressRequest) *EgressPolicy {
+	if g == nil || g.executor == nil || g.executor.policy == nil {
+		return nil
+	}
+	call := &ToolCall{Name: req.Tool, SessionID: req.SessionID, AgentID: req.AgentID}
+	return EffectivePolicy(g.executor.policy, call, g.executor.matcher).Egress
+}
+
+// Enabled reports whether an egress policy applies to req.
+func (g *EgressGuard) Enabled(req EgressRequest) bool {
+	return g.policyFor(req) != nil
+}
+
+// DialContext returns a dial function for http.Transport that checks each
+// connection made for req. Host names are resolved once and the checked
+// addresses are dialed, so a name cannot re-resolve to a blocked address.
+func (g *EgressGuard) DialContext(req EgressRequest) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		pol := g.policyFor(req)
+		if pol == nil {
+			return g.dialer.DialContext(ctx, network, addr)
+		}
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		var ips []net.IP
+		if ip := net.ParseIP(host); ip != nil {
+			ips = []net.IP{ip}
+		} else {
+			addrs, err := g.resolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			for _, a := range addrs {
+				ips = append(ips, a.IP)
+			}
+		}
+
+		if rule, reason := pol.check(host, ips); rule != "" {
+			return nil, g.deny(req, host, port, rule, reason)
+		}
+
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := g.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no addresses for %s", host)
+		}
+		return nil, lastErr
+	}
+}
+
+// deny records a blocked connection and returns the error for it.
+func (g *EgressGuard) deny(req EgressRequest, host, port, rule, reason string) error {
+	args, _ := json.Marshal(map[string]string{"host": host, "port": port})
+	now := g.executor.now()
+	g.executor.recordDecision(&Decision{
+		Timestamp: now,
+		Call: ToolCall{
+			Name:      req.Tool,
+			Arguments: string(args),
+			SessionID: req.SessionID,
+			AgentID:   req.AgentID,
+		},
+		Context: EvalContext{Time: now},
+		Result: PolicyResult{
+			Allowed:      false,
+			Reason:       reason,
+			MatchedRules: []string{rule},
+		},
+	})
+	g.executor.logger.Info("egress blocked",
+		"tool", req.Tool,
+		"host", host,
+		"port", port,
+		"reason", reason,
+	)
+	return &EgressError{Host: host, Reason: reason, Rule: rule}
+}
@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressPolicy_Check(t *testing.T) {
+	public := []net.IP{net.ParseIP("140.82.112.3")}
+	private := []net.IP{net.ParseIP("10.1.2.3")}
+
+	tests := []struct {
+		name   string
+		policy EgressPolicy
+		host   string
+		ips    []net.IP
+		rule   string
+	}{
+		{"empty allows", EgressPolicy{}, "example.com", public, ""},
+		{"exact allow", EgressPolicy{Allow: []string{"api.github.com"}}, "api.github.com", public, ""},
+		{"not in allowlist", EgressPolicy{Allow: []string{"api.github.com"}}, "github.com", public, "egress:allow"},
+		{"subdomain wildcard", EgressPolicy{Allow: []string{"*.github.com"}}, "API.GitHub.com.", public, ""},
+		{"wildcard excludes apex", EgressPolicy{Allow: []string{"*.github.com"}}, "github.com", public, "egress:allow"},
+		{"cidr allow", EgressPolicy{Allow: []string{"140.82.112.0/20"}}, "github.com", public, ""},
+		{"deny wins", EgressPolicy{Allow: []string{"*"}, Deny: []string{"*.evil.test"}}, "x.evil.test", public, "egress:deny"},
+		{"deny cidr", EgressPolicy{Deny: []string{"140.82.0.0/16"}}, "github.com", public, "egress:deny"},
+		{"private blocked", EgressPolicy{BlockPrivate: true}, "intranet", private, "egress:private"},
+		{"star does not allow private", EgressPolicy{Allow: []string{"*"}, BlockPrivate: true}, "intranet", private, "egress:private"},
+		{"named private allowed", EgressPolicy{Allow: []string{"intranet"}, BlockPrivate: true}, "intranet", private, ""},
+		{"private cidr allowed", EgressPolicy{Allow: []string{"10.0.0.0/8"}, BlockPrivate: true}, "intranet", private, ""},
+		{"ip literal allow", EgressPolicy{Allow: []string{"10.1.2.3"}}, "10.1.2.3", private, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, reason := tt.policy.check(tt.host, tt.ips)
+			assert.Equal(t, tt.rule, rule, reason)
+		})
+	}
+}
+
+func TestValidatePolicy_Egress(t *testing.T) {
+	assert.NoError(t, ValidatePolicy(&ToolPolicy{Egress: &EgressPolicy{Allow: []string{"10.0.0.0/8", "*.example.com"}}}))
+	assert.Error(t, ValidatePolicy(&ToolPolicy{Egress: &EgressPolicy{Deny: []string{"10.0.0.0/33"}}}))
+	assert.Error(t, ValidatePolicy(&ToolPolicy{Overlays: []PolicyOverlay{
+		{Agent: "a", Egress: &EgressPolicy{Allow: []string{""}}},
+	}}))
+}
+
+func TestEgressGuard_DialContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	executor := NewPolicyExecutor(&ToolPolicy{
+		DefaultAllow: true,
+		Egress:       &EgressPolicy{BlockPrivate: true},
+		Overlays: []PolicyOverlay{
+			{Agent: "local", Egress: &EgressPolicy{Allow: []string{"127.0.0.1"}}},
+		},
+	})
+	guard := NewEgressGuard(executor)
+
+	get := func(req EgressRequest) error {
+		client := &http.Client{Transport: &http.Transport{DialContext: guard.DialContext(req)}}
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	err := get(EgressRequest{Tool: "http", SessionID: "s1"})
+	var egressErr *EgressError
+	require.ErrorAs(t, err, &egressErr)
+	assert.Equal(t, "127.0.0.1", egressErr.Host)
+
+	assert.NoError(t, get(EgressRequest{Tool: "http", AgentID: "local"}))
+
+	decisions := executor.RecentDecisions(0)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, "http", decisions[0].Call.Name)
+	assert.Equal(t, []string{"egress:private"}, decisions[0].Result.MatchedRules)
+
+	t.Run("no policy", func(t *testing.T) {
+		guard := NewEgressGuard(NewPolicyExecutor(&ToolPolicy{DefaultAllow: true}))
+		assert.False(t, guard.Enabled(EgressRequest{Tool: "shell"}))
+		conn, err := guard.DialContext(EgressRequest{})(context.Background(), "tcp", srv.Listener.Addr().String())
+		require.NoError(t, err)
+		conn.Close()
+	})
+}
@@ -8,13 +8,19 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 // PolicyExecutor implements the PolicyChecker interface.
 type PolicyExecutor struct {
-	policy  *ToolPolicy
-	matcher PatternMatcher
-	logger  *slog.Logger
+	policy    *ToolPolicy
+	matcher   PatternMatcher
+	logger    *slog.Logger
+	decisions DecisionLogger
+	recent    *MemoryDecisionLog
+	counter   *callCounter
+	now       func() time.Time
 }
 
 // NewPolicyExecutor creates a new PolicyExecutor with the given policy.
@@ -23,6 +29,9 @@ func NewPolicyExecutor(policy *ToolPolicy) *PolicyExecutor {
 		policy:  policy,
 		matcher: NewDefaultMatcher(),
 		logger:  slog.Default(),
+		recent:  NewMemoryDecisionLog(0),
+		counter: newCallCounter(),
+		now:     time.Now,
 	}
 }
 
@@ -36,15 +45,69 @@ func (e *PolicyExecutor) SetLogger(l *slog.Logger) {
 	e.logger = l
 }
 
+// SetDecisionLogger sets a logger that records every decision of Check,
+// in addition to the recent decisions kept in memory.
+func (e *PolicyExecutor) SetDecisionLogger(l DecisionLogger) {
+	e.decisions = l
+}
+
+// RecentDecisions returns up to limit of the latest decisions, newest first.
+func (e *PolicyExecutor) RecentDecisions(limit int) []Decision {
+	return e.recent.Recent(limit)
+}
+
 // Check evaluates whether a tool call is allowed.
 // Returns PolicyResult with allow/deny decision and reasons.
 //
-// Check order:
+// The call is evaluated at the current time with the session's call
+// counts, counted if allowed, and recorded by the decision logger.
+func (e *PolicyExecutor) Check(ctx context.Context, call *ToolCall) (*PolicyResult, error) {
+	if call == nil {
+		return nil, fmt.Errorf("policy: nil tool call")
+	}
+
+	ec := e.counter.context(call, e.now())
+	result, err := e.Evaluate(ctx, call, ec)
+	if err != nil {
+		return nil, err
+	}
+	if result.Allowed {
+		e.counter.record(call, ec.Time)
+	}
+
+	e.recordDecision(&Decision{
+		Timestamp: ec.Time,
+		Call:      *call,
+		Context:   ec,
+		Result:    *result,
+	})
+
+	return result, nil
+}
+
+// recordDecision keeps a decision in the recent log and passes it to the
+// decision logger.
+func (e *PolicyExecutor) recordDecision(d *Decision) {
+	_ = e.recent.LogDecision(d)
+	if e.decisions != nil {
+		if err := e.decisions.LogDecision(d); err != nil {
+			e.logger.Warn("failed to log policy decision", "tool", d.Call.Name, "error", err)
+		}
+	}
+}
+
+// Evaluate checks a call in the given context without side effects: the
+// call is not counted and the decision is not logged. Use it to dry-run
+// or replay calls.
+//
+// Check order (on the policy with matching overlays applied):
 // 1. Blocklist check (takes precedence)
 // 2. Allowlist check (if not default allow)
 // 3. Dangerous operations check
 // 4. Parameter rules check
-func (e *PolicyExecutor) Check(ctx context.Context, call *ToolCall) (*PolicyResult, error) {
+// 5. File access check (built-in file tools)
+// 6. Conditional rules check
+func (e *PolicyExecutor) Evaluate(ctx context.Context, call *ToolCall, ec EvalContext) (*PolicyResult, error) {
 	if call == nil {
 		return nil, fmt.Errorf("policy: nil tool call")
 	}
@@ -53,6 +116,7 @@ func (e *PolicyExecutor) Check(ctx context.Context, call *ToolCall) (*PolicyResu
 		Allowed:  true,
 		Warnings: []string{},
 	}
+	pol := EffectivePolicy(e.policy, call, e.matcher)
 
 	e.logger.Debug("policy check started",
 		"tool", call.Name,
@@ -60,7 +124,7 @@ func (e *PolicyExecutor) Check(ctx context.Context, call *ToolCall) (*PolicyResu
 	)
 
 	// 1. Blocklist check - takes precedence over everything
-	if e.checkBlocklist(call, result) {
+	if e.checkBlocklist(pol, call, result) {
 		e.logger.Info("policy check result",
 			"tool", call.Name,
 			"allowed", false,
@@ -70,7 +134,7 @@ func (e *PolicyExecutor) Check(ctx context.Context, call *ToolCall) (*PolicyResu
 	}
 
 	// 2. Allowlist check - if not default allow
-	if e.checkAllowlist(call, result) {
+	if e.checkAllowlist(pol, call, result) {
 		e.logger.Info("policy check result",
 			"tool", call.Name,
 			"allowed", false,
@@ -80,7 +144,7 @@ func (e *PolicyExecutor) Check(ctx context.Context, call *ToolCall) (*PolicyResu
 	}
 
 	// 3. Dangerous operations check
-	if e.checkDangerousOps(call, result) {
+	if e.checkDangerousOps(pol, call, result) {
 		e.logger.Info("policy check result",
 			"tool", call.Name,
 			"allowed", result.Allowed,
@@ -94,7 +158,7 @@ func (e *PolicyExecutor) Check(ctx context.Context, call *ToolCall) (*PolicyResu
 	}
 
 	// 4. Parameter rules check
-	if e.checkParamRules(call, result) {
+	if e.checkParamRules(pol, call, result) {
 		e.logger.Info("policy check result",
 			"tool", call.Name,
 			"allowed", false,
@@ -103,8 +167,28 @@ func (e *PolicyExecutor) Check(ctx context.Context, call *ToolCall) (*PolicyResu
 		return result, nil
 	}
 
-	// 5. Global require approval check
-	if e.policy.RequireApproval && !result.RequireApproval {
+	// 5. File access check
+	if e.checkFileAccess(pol, call, result) {
+		e.logger.Info("policy check result",
+			"tool", call.Name,
+			"allowed", false,
+			"reason", "file access",
+		)
+		return result, nil
+	}
+
+	// 6. Conditional rules check
+	if e.checkRules(pol, call, ec, result) && !result.Allowed {
+		e.logger.Info("policy check result",
+			"tool", call.Name,
+			"allowed", false,
+			"reason", "policy rule",
+		)
+		return result, nil
+	}
+
+	// 7. Global require approval check
+	if pol.RequireApproval && !result.RequireApproval {
 		result.RequireApproval = true
 		result.ApprovalReason = "global approval required"
 	}
@@ -120,12 +204,12 @@ func (e *PolicyExecutor) Check(ctx context.Context, call *ToolCall) (*PolicyResu
 
 // checkBlocklist checks if the tool is in the blocklist.
 // Returns true if the tool is blocked (result.Allowed set to false).
-func (e *PolicyExecutor) checkBlocklist(call *ToolCall, result *PolicyResult) bool {
-	if len(e.policy.Blocklist) == 0 {
+func (e *PolicyExecutor) checkBlocklist(pol *ToolPolicy, call *ToolCall, result *PolicyResult) bool {
+	if len(pol.Blocklist) == 0 {
 		return false
 	}
 
-	expanded := ExpandGroups(e.policy.Blocklist)
+	expanded := ExpandGroups(pol.Blocklist)
 	if e.matcher.MatchTool(call.Name, expanded) {
 		result.Allowed = false
 		result.Reason = fmt.Sprintf("tool '%s' is in blocklist", call.Name)
@@ -138,21 +222,21 @@ func (e *PolicyExecutor) checkBlocklist(call *ToolCall, result *PolicyResult) bo
 
 // checkAllowlist checks if the tool is in the allowlist.
 // Returns true if the tool is denied (result.Allowed set to false).
-func (e *PolicyExecutor) checkAllowlist(call *ToolCall, result *PolicyResult) bool {
+func (e *PolicyExecutor) checkAllowlist(pol *ToolPolicy, call *ToolCall, result *PolicyResult) bool {
 	// If default allow is true, skip allowlist check
-	if e.policy.DefaultAllow {
+	if pol.DefaultAllow {
 		return false
 	}
 
 	// If no allowlist defined, deny all
-	if len(e.policy.Allowlist) == 0 {
+	if len(pol.Allowlist) == 0 {
 		result.Allowed = false
 		result.Reason = "no tools allowed (empty allowlist with default_allow=false)"
 		result.MatchedRules = append(result.MatchedRules, "empty_allowlist")
 		return true
 	}
 
-	expanded := ExpandGroups(e.policy.Allowlist)
+	expanded := ExpandGroups(pol.Allowlist)
 	if !e.matcher.MatchTool(call.Name, expanded) {
 		result.Allowed = false
 		result.Reason = fmt.Sprintf("tool '%s' is not in allowlist", call.Name)
@@ -163,24 +247,35 @@ func (e *PolicyExecutor) checkAllowlist(call *ToolCall, result *PolicyResult) bo
 	return false
 }
 
+// dangerousOpTool widens a rule written for "shell" to every tool that runs
+// commands. Policies saved before shell_session and process existed still
+// name only shell, and those tools would otherwise run the same commands
+// unchecked.
+func dangerousOpTool(tool string) string {
+	if tool == "shell" {
+		return "group:runtime"
+	}
+	return tool
+}
+
 // checkDangerousOps checks if the tool call matches any dangerous operation rules.
 // Returns true if any rule matched (may set RequireApproval or block).
-func (e *PolicyExecutor) checkDangerousOps(call *ToolCall, result *PolicyResult) bool {
-	if len(e.policy.DangerousOps) == 0 {
+func (e *PolicyExecutor) checkDangerousOps(pol *ToolPolicy, call *ToolCall, result *PolicyResult) bool {
+	if len(pol.DangerousOps) == 0 {
 		return false
 	}
 
 	matched := false
-	for i := range e.policy.DangerousOps {
-		rule := &e.policy.DangerousOps[i]
+	for i := range pol.DangerousOps {
+		rule := &pol.DangerousOps[i]
 
 		// Skip disabled rules
 		if !rule.IsEnabled() {
 			continue
 		}
 
-		// Check if rule applies to this tool
-		if rule.Tool != "" && !e.matcher.MatchTool(call.Name, []string{rule.Tool}) {
+		// Check if rule applies to this tool (group references allowed)
+		if rule.Tool != "" && !e.matcher.MatchTool(call.Name, ExpandGroups([]string{dangerousOpTool(rule.Tool)})) {
 			continue
 		}
 
@@ -238,12 +333,12 @@ func (e *PolicyExecutor) checkDangerousOps(call *ToolCall, result *PolicyResult)
 
 // checkParamRules checks if the tool call violates any parameter rules.
 // Returns true if validation failed (result.Allowed set to false).
-func (e *PolicyExecutor) checkParamRules(call *ToolCall, result *PolicyResult) bool {
-	if len(e.policy.ParamRules) == 0 {
+func (e *PolicyExecutor) checkParamRules(pol *ToolPolicy, call *ToolCall, result *PolicyResult) bool {
+	if len(pol.ParamRules) == 0 {
 		return false
 	}
 
-	rule, ok := e.policy.ParamRules[call.Name]
+	rule, ok := pol.ParamRules[call.Name]
 	if !ok {
 		return false
 	}
@@ -296,10 +391,10 @@ func (e *PolicyExecutor) checkParamRules(call *ToolCall, result *PolicyResult) b
 	if len(rule.PathPrefix) > 0 {
 		paths := extractPaths(call.Arguments)
 		if len(paths) > 0 {
-			expandedPrefixes := expandPrefixes(rule.PathPrefix, call.WorkspacePath)
+			resolvedPrefixes := resolvePrefixes(rule.PathPrefix, call.WorkspacePath)
 			for _, p := range paths {
-				cleanPath := filepath.Clean(p)
-				if !matchesAnyPrefix(cleanPath, expandedPrefixes) {
+				resolved, err := ResolvePathIn(p, call.WorkspacePath)
+				if err != nil || !matchesAnyPrefix(resolved, resolvedPrefixes) {
 					result.Allowed = false
 					result.Reason = fmt.Sprintf("path '%s' is not within allowed prefixes %v", p, rule.PathPrefix)
 					result.MatchedRules = append(result.MatchedRules, "param_rule:path_prefix")
@@ -312,6 +407,105 @@ func (e *PolicyExecutor) checkParamRules(call *ToolCall, result *PolicyResult) b
 	return false
 }
 
+// checkRules evaluates the conditional rules.
+// Returns true if any rule matched (may set RequireApproval or block).
+func (e *PolicyExecutor) checkRules(pol *ToolPolicy, call *ToolCall, ec EvalContext, result *PolicyResult) bool {
+	if len(pol.Rules) == 0 {
+		return false
+	}
+
+	vars := RuleVariables(call, ec)
+	matched := false
+	for i := range pol.Rules {
+		rule := &pol.Rules[i]
+
+		if !rule.IsEnabled() {
+			continue
+		}
+		if rule.Tool != "" && !e.matcher.MatchTool(call.Name, ExpandGroups([]string{rule.Tool})) {
+			continue
+		}
+
+		cond, err := rule.Condition()
+		if err != nil {
+			e.logger.Warn("invalid policy rule condition",
+				"rule", rule.displayName(),
+				"when", rule.When,
+				"error", err,
+			)
+			if rule.Action == "block" {
+				e.blockOnRuleError(rule, call, err, result)
+				return true
+			}
+			continue
+		}
+		if cond != nil {
+			ok, err := cond.EvalBool(vars)
+			if err != nil {
+				// A condition that cannot be evaluated (e.g. a missing
+				// argument) does not match, except that block rules
+				// fail closed.
+				e.logger.Debug("policy rule condition failed",
+					"rule", rule.displayName(),
+					"tool", call.Name,
+					"error", err,
+				)
+				if rule.Action == "block" {
+					e.blockOnRuleError(rule, call, err, result)
+					return true
+				}
+				continue
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		matched = true
+		ruleName := rule.displayName()
+		result.MatchedRules = append(result.MatchedRules, ruleName)
+		message := rule.Message
+		if message == "" {
+			message = fmt.Sprintf("matched policy rule '%s'", strings.TrimPrefix(ruleName, "rule:"))
+		}
+
+		e.logger.Info("policy rule matched",
+			"tool", call.Name,
+			"rule", ruleName,
+			"action", rule.Action,
+		)
+
+		switch rule.Action {
+		case "block":
+			result.Allowed = false
+			result.Reason = message
+			return true
+		case "approve":
+			result.RequireApproval = true
+			if result.ApprovalReason == "" {
+				result.ApprovalReason = message
+			}
+		default:
+			result.Warnings = append(result.Warnings, message)
+		}
+	}
+
+	return matched
+}
+
+// blockOnRuleError denies a call whose block rule could not be evaluated.
+func (e *PolicyExecutor) blockOnRuleError(rule *PolicyRule, call *ToolCall, err error, result *PolicyResult) {
+	ruleName := rule.displayName()
+	e.logger.Warn("policy block rule condition failed, denying",
+		"tool", call.Name,
+		"rule", ruleName,
+		"error", err,
+	)
+	result.MatchedRules = append(result.MatchedRules, ruleName)
+	result.Allowed = false
+	result.Reason = fmt.Sprintf("policy rule '%s' could not be evaluated: %v", strings.TrimPrefix(ruleName, "rule:"), err)
+}
+
 // PolicyStatus holds summary information about the current policy.
 type PolicyStatus struct {
 	DefaultAllow        bool
@@ -320,6 +514,8 @@ type PolicyStatus struct {
 	AllowlistCount      int
 	DangerousRulesCount int
 	ParamRulesCount     int
+	RulesCount          int
+	OverlaysCount       int
 }
 
 // Status returns a summary of the current policy configuration.
@@ -337,6 +533,8 @@ func (e *PolicyExecutor) Status() PolicyStatus {
 		AllowlistCount:      len(e.policy.Allowlist),
 		DangerousRulesCount: len(e.policy.DangerousOps),
 		ParamRulesCount:     len(e.policy.ParamRules),
+		RulesCount:          len(e.policy.Rules),
+		OverlaysCount:       len(e.policy.Overlays),
 	}
 }
 
@@ -395,12 +593,79 @@ func expandPrefixes(prefixes []string, workspacePath string) []string {
 	return result
 }
 
-// matchesAnyPrefix checks if the given path starts with any of the prefixes.
+// matchesAnyPrefix checks if the given path is within any of the prefix
+// directories.
 func matchesAnyPrefix(path string, prefixes []string) bool {
 	for _, prefix := range prefixes {
-		if strings.HasPrefix(path, prefix) {
+		if withinDir(path, prefix) {
 			return true
 		}
 	}
 	return false
 }
+
+// maxTrackedSessions bounds the sessions whose call counts are kept.
+const maxTrackedSessions = 1000
+
+// callCounter counts allowed calls per session for rule conditions.
+type callCounter struct {
+	mu       sync.Mutex
+	sessions map[string]*sessionCalls
+}
+
+type sessionCalls struct {
+	total    int
+	tools    map[string]int
+	lastSeen time.Time
+}
+
+func newCallCounter() *callCounter {
+	return &callCounter{sessions: make(map[string]*sessionCalls)}
+}
+
+// context returns the evaluation context for a call made at now.
+func (c *callCounter) context(call *ToolCall, now time.Time) EvalContext {
+	ec := EvalContext{Time: now}
+	if call.SessionID == "" {
+		return ec
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.sessions[call.SessionID]; ok {
+		ec.ToolCalls = s.tools[call.Name]
+		ec.TotalCalls = s.total
+	}
+	return ec
+}
+
+// record counts an allowed call.
+func (c *callCounter) record(call *ToolCall, now time.Time) {
+	if call.SessionID == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.sessions[call.SessionID]
+	if !ok {
+		if len(c.sessions) >= maxTrackedSessions {
+			c.evictOldestLocked()
+		}
+		s = &sessionCalls{tools: make(map[string]int)}
+		c.sessions[call.SessionID] = s
+	}
+	s.total++
+	s.tools[call.Name]++
+	s.lastSeen = now
+}
+
+// evictOldestLocked drops the least recently active session.
+func (c *callCounter) evictOldestLocked() {
+	var oldest string
+	var oldestSeen time.Time
+	for id, s := range c.sessions {
+		if oldest == "" || s.lastSeen.Before(oldestSeen) {
+			oldest, oldestSeen = id, s.lastSeen
+		}
+	}
+	delete(c.sessions, oldest)
+}
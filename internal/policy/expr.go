@@ -0,0 +1,831 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// errNoSuchKey is returned when an expression reads a missing map key.
+// has() turns it into false.
+var errNoSuchKey = errors.New("no such key")
+
+// errIntOverflow is returned when integer arithmetic leaves the int64 range,
+// as CEL does, rather than silently wrapping around.
+var errIntOverflow = errors.New("integer overflow")
+
+// Expr is a compiled policy condition.
+//
+// The language is a small subset of CEL:
+//
+//	literals     "str" 'str' 42 1.5 true false null [1, 2]
+//	access       args.path  args["path"]
+//	operators    ! - * / % + - == != < <= > >= in && ||
+//	functions    size(x) has(args.path) lower(s) upper(s)
+//	methods      s.startsWith(x) s.endsWith(x) s.contains(x) s.matches(re) x.size()
+//
+// && and || short-circuit from left to right. Reading a missing key is an
+// error; use has() or "key" in map to test for optional arguments.
+type Expr struct {
+	src  string
+	root node
+}
+
+// CompileExpr parses a policy condition.
+func CompileExpr(src string) (*Expr, error) {
+	p := &parser{src: src}
+	if err := p.tokenize(); err != nil {
+		return nil, fmt.Errorf("policy: expression %q: %w", src, err)
+	}
+	root, err := p.parseExpr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %q at offset %d", p.peek().text, p.peek().pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("policy: expression %q: %w", src, err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression with the given variables.
+func (e *Expr) Eval(vars map[string]any) (any, error) {
+	return e.root.eval(vars)
+}
+
+// EvalBool evaluates the expression and requires a boolean result.
+func (e *Expr) EvalBool(vars map[string]any) (bool, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q: result is %s, not bool", e.src, typeName(v))
+	}
+	return b, nil
+}
+
+// --- lexer ---
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	val  any
+	pos  int
+}
+
+type parser struct {
+	src  string
+	toks []token
+	pos  int
+}
+
+// twoCharOps are matched before single-character operators.
+var twoCharOps = []string{"&&", "||", "==", "!=", "<=", ">="}
+
+func (p *parser) tokenize() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			str, n, err := scanString(s[i:])
+			if err != nil {
+				return fmt.Errorf("%w at offset %d", err, i)
+			}
+			p.toks = append(p.toks, token{kind: tokString, text: s[i : i+n], val: str, pos: i})
+			i += n
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+				j++
+			}
+			text := s[i:j]
+			var val any
+			if strings.Contains(text, ".") {
+				f, err := strconv.ParseFloat(text, 64)
+				if err != nil {
+					return fmt.Errorf("invalid number %q at offset %d", text, i)
+				}
+				val = f
+			} else {
+				n, err := strconv.ParseInt(text, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid number %q at offset %d", text, i)
+				}
+				val = n
+			}
+			p.toks = append(p.toks, token{kind: tokNumber, text: text, val: val, pos: i})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || s[j] >= '0' && s[j] <= '9') {
+				j++
+			}
+			p.toks = append(p.toks, token{kind: tokIdent, text: s[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, two := range twoCharOps {
+				if strings.HasPrefix(s[i:], two) {
+					op = two
+					break
+				}
+			}
+			if op == "" {
+				if !strings.ContainsRune("!<>+-*/%.,()[]", rune(c)) {
+					return fmt.Errorf("unexpected character %q at offset %d", c, i)
+				}
+				op = string(c)
+			}
+			p.toks = append(p.toks, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	p.toks = append(p.toks, token{kind: tokEOF, text: "end of expression", pos: len(s)})
+	return nil
+}
+
+// scanString reads a quoted string literal with backslash escapes and
+// returns its value and length in the source.
+func scanString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, errors.New("unterminated string")
+}
+
+// --- parser ---
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokOp && !(t.kind == tokIdent && t.text == "in") {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		t := p.peek()
+		return fmt.Errorf("expected %q, found %q at offset %d", op, t.text, t.pos)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) parseExpr() (node, error) {
+	return p.parseBinary(0)
+}
+
+// precedence lists binary operators from loosest to tightest binding.
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(precedence) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(precedence[level]...) {
+		op := p.next().text
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("!", "-") {
+		op := p.next().text
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOp("."):
+			p.next()
+			t := p.next()
+			if t.kind != tokIdent {
+				return nil, fmt.Errorf("expected field name after '.', found %q at offset %d", t.text, t.pos)
+			}
+			if p.isOp("(") {
+				args, err := p.parseArgs()
+				if err != nil {
+					return nil, err
+				}
+				n, err = newCall(t.text, n, args)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			n = &indexNode{target: n, index: &literalNode{val: t.text}}
+		case p.isOp("["):
+			p.next()
+			idx, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &indexNode{target: n, index: idx}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber, tokString:
+		return &literalNode{val: t.val}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{val: true}, nil
+		case "false":
+			return &literalNode{val: false}, nil
+		case "null":
+			return &literalNode{val: nil}, nil
+		}
+		if p.isOp("(") {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return newCall(t.text, nil, args)
+		}
+		return &identNode{name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			n, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			var items []node
+			for !p.isOp("]") {
+				item, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+				if !p.isOp(",") {
+					break
+				}
+				p.next()
+			}
+			return &listNode{items: items}, p.expect("]")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+}
+
+func (p *parser) parseArgs() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []node
+	for !p.isOp(")") {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	return args, p.expect(")")
+}
+
+// --- evaluation ---
+
+type node interface {
+	eval(vars map[string]any) (any, error)
+}
+
+type literalNode struct{ val any }
+
+func (n *literalNode) eval(map[string]any) (any, error) { return n.val, nil }
+
+type identNode struct{ name string }
+
+func (n *identNode) eval(vars map[string]any) (any, error) {
+	v, ok := vars[n.name]
+	if !ok {
+		return nil, fmt.Errorf("undeclared reference to %q", n.name)
+	}
+	return v, nil
+}
+
+type listNode struct{ items []node }
+
+func (n *listNode) eval(vars map[string]any) (any, error) {
+	list := make([]any, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+type indexNode struct {
+	target node
+	index  node
+}
+
+func (n *indexNode) eval(vars map[string]any) (any, error) {
+	target, err := n.target.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := n.index.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case map[string]any:
+		key, ok := idx.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key must be string, not %s", typeName(idx))
+		}
+		v, ok := t[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errNoSuchKey, key)
+		}
+		return v, nil
+	case []any:
+		i, ok := toInt(idx)
+		if !ok {
+			return nil, fmt.Errorf("list index must be int, not %s", typeName(idx))
+		}
+		if i < 0 || i >= int64(len(t)) {
+			return nil, fmt.Errorf("index %d out of range", i)
+		}
+		return t[i], nil
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(target))
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(vars map[string]any) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("operator ! needs bool, not %s", typeName(v))
+		}
+		return !b, nil
+	}
+	switch x := v.(type) {
+	case int64:
+		if x == math.MinInt64 {
+			return nil, errIntOverflow
+		}
+		return -x, nil
+	case float64:
+		return -x, nil
+	}
+	return nil, fmt.Errorf("operator - needs a number, not %s", typeName(v))
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(vars map[string]any) (any, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		lb, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("operator %s needs bool, not %s", n.op, typeName(left))
+		}
+		if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+			return lb, nil
+		}
+		right, err := n.right.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("operator %s needs bool, not %s", n.op, typeName(right))
+		}
+		return rb, nil
+	}
+
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left)
+	case "<", "<=", ">", ">=":
+		c, err := compare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case "+":
+		if ls, ok := left.(string); ok {
+			if rs, ok := right.(string); ok {
+				return ls + rs, nil
+			}
+		}
+	}
+	return arith(n.op, left, right)
+}
+
+// callNode is a function call or, when target is set, a method call.
+type callNode struct {
+	name   string
+	target node
+	args   []node
+	re     *regexp.Regexp // precompiled pattern for matches() with a literal
+}
+
+func newCall(name string, target node, args []node) (node, error) {
+	c := &callNode{name: name, target: target, args: args}
+	if target != nil {
+		// Method form: x.size() is size(x)
+		switch name {
+		case "size":
+			if len(args) != 0 {
+				return nil, fmt.Errorf("size() takes no arguments")
+			}
+		case "startsWith", "endsWith", "contains", "matches":
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%s() takes one argument", name)
+			}
+		default:
+			return nil, fmt.Errorf("unknown method %q", name)
+		}
+	} else {
+		switch name {
+		case "size", "has", "lower", "upper":
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%s() takes one argument", name)
+			}
+		default:
+			return nil, fmt.Errorf("unknown function %q", name)
+		}
+		if name == "has" {
+			if _, ok := args[0].(*indexNode); !ok {
+				return nil, fmt.Errorf("has() needs a field access such as has(args.path)")
+			}
+		}
+	}
+	if name == "matches" {
+		if lit, ok := args[0].(*literalNode); ok {
+			pattern, ok := lit.val.(string)
+			if !ok {
+				return nil, fmt.Errorf("matches() needs a string pattern")
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			c.re = re
+		}
+	}
+	return c, nil
+}
+
+func (n *callNode) eval(vars map[string]any) (any, error) {
+	if n.name == "has" {
+		_, err := n.args[0].eval(vars)
+		if errors.Is(err, errNoSuchKey) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	var recv any
+	var err error
+	args := make([]any, len(n.args))
+	for i, a := range n.args {
+		if args[i], err = a.eval(vars); err != nil {
+			return nil, err
+		}
+	}
+	if n.target != nil {
+		if recv, err = n.target.eval(vars); err != nil {
+			return nil, err
+		}
+	} else {
+		recv, args = args[0], args[1:]
+	}
+
+	if n.name == "size" {
+		switch v := recv.(type) {
+		case string:
+			return int64(len([]rune(v))), nil
+		case []any:
+			return int64(len(v)), nil
+		case map[string]any:
+			return int64(len(v)), nil
+		}
+		return nil, fmt.Errorf("size() is not defined for %s", typeName(recv))
+	}
+
+	s, ok := recv.(string)
+	if !ok {
+		if n.name == "contains" {
+			return contains(recv, args[0])
+		}
+		return nil, fmt.Errorf("%s() needs a string, not %s", n.name, typeName(recv))
+	}
+	switch n.name {
+	case "lower":
+		return strings.ToLower(s), nil
+	case "upper":
+		return strings.ToUpper(s), nil
+	}
+
+	arg, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s() needs a string argument, not %s", n.name, typeName(args[0]))
+	}
+	switch n.name {
+	case "startsWith":
+		return strings.HasPrefix(s, arg), nil
+	case "endsWith":
+		return strings.HasSuffix(s, arg), nil
+	case "contains":
+		return strings.Contains(s, arg), nil
+	default: // matches
+		re := n.re
+		if re == nil {
+			if re, err = regexp.Compile(arg); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+			}
+		}
+		return re.MatchString(s), nil
+	}
+}
+
+// --- value helpers ---
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case float64:
+		return "double"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "map"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func toInt(v any) (int64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return x, true
+	case float64:
+		if x == math.Trunc(x) {
+			return int64(x), true
+		}
+	}
+	return 0, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+func equal(a, b any) bool {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af == bf
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func contains(container, item any) (bool, error) {
+	switch c := container.(type) {
+	case []any:
+		for _, v := range c {
+			if equal(v, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]any:
+		key, ok := item.(string)
+		if !ok {
+			return false, nil
+		}
+		_, ok = c[key]
+		return ok, nil
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot look for %s in a string", typeName(item))
+		}
+		return strings.Contains(c, s), nil
+	}
+	return false, fmt.Errorf("operator in is not defined for %s", typeName(container))
+}
+
+func compare(a, b any) (int, error) {
+	if af, ok := toFloat(a); ok {
+		if bf, ok := toFloat(b); ok {
+			switch {
+			case af < bf:
+				return -1, nil
+			case af > bf:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			return strings.Compare(as, bs), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s with %s", typeName(a), typeName(b))
+}
+
+func arith(op string, a, b any) (any, error) {
+	ai, aInt := a.(int64)
+	bi, bInt := b.(int64)
+	if aInt && bInt {
+		switch op {
+		case "+":
+			r := ai + bi
+			if (r > ai) != (bi > 0) {
+				return nil, errIntOverflow
+			}
+			return r, nil
+		case "-":
+			r := ai - bi
+			if (r < ai) != (bi > 0) {
+				return nil, errIntOverflow
+			}
+			return r, nil
+		case "*":
+			r := ai * bi
+			if ai != 0 && (r/ai != bi || (ai == -1 && bi == math.MinInt64)) {
+				return nil, errIntOverflow
+			}
+			return r, nil
+		case "/", "%":
+			if bi == 0 {
+				return nil, errors.New("division by zero")
+			}
+			if op == "/" {
+				if ai == math.MinInt64 && bi == -1 {
+					return nil, errIntOverflow
+				}
+				return ai / bi, nil
+			}
+			return ai % bi, nil
+		}
+	}
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if !aok || !bok {
+		return nil, fmt.Errorf("operator %s is not defined for %s and %s", op, typeName(a), typeName(b))
+	}
+	switch op {
+	case "+":
+		return af + bf, nil
+	case "-":
+		return af - bf, nil
+	case "*":
+		return af * bf, nil
+	case "/":
+		return af / bf, nil
+	}
+	return nil, fmt.Errorf("operator %s is not defined for double", op)
+}
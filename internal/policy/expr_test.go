@@ -0,0 +1,84 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr_Eval(t *testing.T) {
+	vars := map[string]any{
+		"tool":  "shell",
+		"agent": "researcher",
+		"hour":  int64(23),
+		"args": map[string]any{
+			"command": "git push --force",
+			"timeout": float64(30),
+			"paths":   []any{"/tmp/a", "/etc/hosts"},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`tool == "shell"`, true},
+		{`agent != 'researcher'`, false},
+		{`hour >= 22 || hour < 6`, true},
+		{`!(hour >= 9 && hour < 18)`, true},
+		{`agent in ["coder", "researcher"]`, true},
+		{`"command" in args`, true},
+		{`args.command.startsWith("git ") && args.command.contains("--force")`, true},
+		{`args["command"].matches("push\\s+--force")`, true},
+		{`args.timeout > 10 && args.timeout == 30`, true},
+		{`size(args.paths) == 2 && args.paths[1].startsWith("/etc")`, true},
+		{`"/etc/hosts" in args.paths`, true},
+		{`has(args.command) && !has(args.cwd)`, true},
+		{`lower("ABC") == "abc" && "a" + "b" == "ab"`, true},
+		{`hour % 2 == 1 && -hour < 0 && hour * 2 / 2 == 23`, true},
+		{`tool == "read_file" && args.missing == 1`, false}, // short-circuits
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := CompileExpr(tt.expr)
+			require.NoError(t, err)
+			got, err := expr.EvalBool(vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpr_Errors(t *testing.T) {
+	for _, src := range []string{
+		`tool ==`,
+		`tool = "shell"`,
+		`"unterminated`,
+		`args.command.frobnicate()`,
+		`nosuch(tool)`,
+		`tool.matches("[")`,
+		`has(tool)`,
+	} {
+		_, err := CompileExpr(src)
+		assert.Error(t, err, src)
+	}
+
+	vars := map[string]any{"tool": "shell", "args": map[string]any{}}
+	for _, src := range []string{
+		`args.missing == "x"`,
+		`unknown == 1`,
+		`tool > 1`,
+		`tool`,
+		`9223372036854775807 + 1 > 0`,
+		`-9223372036854775807 - 2 < 0`,
+		`4611686018427387904 * 2 > 0`,
+		`-(-9223372036854775807 - 1) > 0`,
+		`(-9223372036854775807 - 1) / -1 > 0`,
+	} {
+		expr, err := CompileExpr(src)
+		require.NoError(t, err, src)
+		_, err = expr.EvalBool(vars)
+		assert.Error(t, err, src)
+	}
+}
@@ -0,0 +1,315 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AccessMode is the kind of file access a tool asks for.
+type AccessMode string
+
+const (
+	// AccessRead covers reading files and listing directories.
+	AccessRead AccessMode = "read"
+	// AccessWrite covers creating, modifying and deleting files.
+	AccessWrite AccessMode = "write"
+)
+
+// FileAccessPolicy limits where file tools may read and write. Paths are
+// compared after resolving symlinks and ".." components, so a link inside
+// an allowed directory cannot reach outside it.
+//
+// Entries support ~ and $WORKSPACE (the session's bound workspace; entries
+// using it are skipped when no workspace is bound).
+type FileAccessPolicy struct {
+	// Read lists the directories that may be read. Empty allows reading
+	// anywhere not denied.
+	Read []string `yaml:"read,omitempty" json:"read,omitempty"`
+
+	// Write lists the directories that may be written. Empty allows
+	// writing anywhere not denied. Write access does not grant read access.
+	Write []string `yaml:"write,omitempty" json:"write,omitempty"`
+
+	// Deny lists paths that may be neither read nor written, e.g. ~/.ssh.
+	// Takes precedence over Read and Write.
+	Deny []string `yaml:"deny,omitempty" json:"deny,omitempty"`
+}
+
+// check returns the matched rule and reason if access to the resolved path
+// is denied, or empty strings if it is allowed.
+func (p *FileAccessPolicy) check(resolved string, mode AccessMode, workspacePath string) (rule, reason string) {
+	if p == nil {
+		return "", ""
+	}
+	for _, dir := range resolvePrefixes(p.Deny, workspacePath) {
+		if withinDir(resolved, dir) {
+			return "file_access:deny", fmt.Sprintf("path '%s' is denied by file access policy", resolved)
+		}
+	}
+	scope := p.Read
+	if mode == AccessWrite {
+		scope = p.Write
+	}
+	if len(scope) == 0 {
+		return "", ""
+	}
+	for _, dir := range resolvePrefixes(scope, workspacePath) {
+		if withinDir(resolved, dir) {
+			return "", ""
+		}
+	}
+	return "file_access:" + string(mode), fmt.Sprintf("%s access to '%s' is not within allowed paths %v", mode, resolved, scope)
+}
+
+// fileToolModes maps the built-in file tools to the access they need.
+var fileToolModes = map[string]AccessMode{
+	"read_file":  AccessRead,
+	"list_dir":   AccessRead,
+	"write_file": AccessWrite,
+	"edit_file":  AccessWrite,
+}
+
+// checkFileAccess checks the path arguments of the built-in file tools
+// against the file access policy.
+// Returns true if access is denied (result.Allowed set to false).
+func (e *PolicyExecutor) checkFileAccess(pol *ToolPolicy, call *ToolCall, result *PolicyResult) bool {
+	mode, ok := fileToolModes[call.Name]
+	if !ok || pol.FileAccess == nil {
+		return false
+	}
+	for _, p := range extractPaths(call.Arguments) {
+		resolved, err := ResolvePathIn(p, call.WorkspacePath)
+		if err != nil {
+			result.Allowed = false
+			result.Reason = fmt.Sprintf("cannot resolve path '%s': %v", p, err)
+			result.MatchedRules = append(result.MatchedRules, "file_access:"+string(mode))
+			return true
+		}
+		if rule, reason := pol.FileAccess.check(resolved, mode, call.WorkspacePath); rule != "" {
+			result.Allowed = false
+			result.Reason = reason
+			result.MatchedRules = append(result.MatchedRules, rule)
+			return true
+		}
+	}
+	return false
+}
+
+// maxSymlinks bounds the links followed while resolving one path.
+const maxSymlinks = 255
+
+// ResolvePath returns the real absolute path of path: relative paths are
+// made absolute against the working directory, symlinks are followed and
+// ".." is applied to the directory a link points into, as the kernel would.
+// Components that do not exist yet are kept as given, so the result is the
+// file a write would create.
+func ResolvePath(path string) (string, error) {
+	return ResolvePathIn(path, "")
+}
+
+// ResolvePathIn is ResolvePath with relative paths taken relative to dir
+// (the working directory if dir is empty). A leading ~ is expanded.
+func ResolvePathIn(path, dir string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("policy: empty path")
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("policy: cannot expand ~: %w", err)
+		}
+		path = home + path[1:]
+	}
+	if !filepath.IsAbs(path) {
+		if dir == "" {
+			wd, err := os.Getwd()
+			if err != nil {
+				return "", fmt.Errorf("policy: cannot resolve relative path: %w", err)
+			}
+			dir = wd
+		}
+		// Not filepath.Join: cleaning would apply ".." before symlinks
+		path = dir + string(filepath.Separator) + path
+	}
+
+	vol := filepath.VolumeName(path)
+	resolved := vol + string(filepath.Separator)
+	pending := splitPath(path[len(vol):])
+	links := 0
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+
+		next := filepath.Join(resolved, name)
+		info, err := os.Lstat(next)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			// Missing components cannot be links; keep them as given
+			resolved = next
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return "", fmt.Errorf("policy: too many levels of symbolic links in '%s'", path)
+		}
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", fmt.Errorf("policy: cannot read link '%s': %w", next, err)
+		}
+		if filepath.IsAbs(target) {
+			tvol := filepath.VolumeName(target)
+			resolved = tvol + string(filepath.Separator)
+			target = target[len(tvol):]
+		}
+		pending = append(splitPath(target), pending...)
+	}
+	return resolved, nil
+}
+
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == filepath.Separator
+	})
+}
+
+// resolvePrefixes expands and resolves directory entries; entries that
+// cannot be resolved are skipped.
+func resolvePrefixes(prefixes []string, workspacePath string) []string {
+	expanded := expandPrefixes(prefixes, workspacePath)
+	result := make([]string, 0, len(expanded))
+	for _, p := range expanded {
+		if resolved, err := ResolvePath(p); err == nil {
+			result = append(result, resolved)
+		}
+	}
+	return result
+}
+
+// FileAccessRequest describes a file access by a tool.
+type FileAccessRequest struct {
+	// Tool is the tool or script making the access.
+	Tool string
+	// Path is the path as given to the tool.
+	Path string
+	// Mode is the kind of access.
+	Mode AccessMode
+	// SessionID and AgentID identify the caller.
+	SessionID string
+	AgentID   string
+}
+
+// FileAccessError is returned when a file access is denied.
+type FileAccessError struct {
+	Path     string
+	Resolved string
+	Mode     AccessMode
+	Reason   string
+}
+
+func (e *FileAccessError) Error() string {
+	return fmt.Sprintf("policy: %s access to '%s' denied: %s", e.Mode, e.Path, e.Reason)
+}
+
+// WorkspaceLookup returns the workspace bound to a session.
+type WorkspaceLookup func(sessionID string) (path string, readOnly bool, ok bool)
+
+// FileGuard checks file accesses at the point a tool touches the disk. It
+// applies the file access policy of the executor (with overlays for the
+// caller's agent and workspace) and keeps read-only workspaces read-only.
+// Denials are recorded as decisions alongside the executor's own.
+type FileGuard struct {
+	executor *PolicyExecutor
+	lookup   WorkspaceLookup
+}
+
+// NewFileGuard creates a FileGuard. lookup may be nil if sessions are
+// never bound to workspaces.
+func NewFileGuard(executor *PolicyExecutor, lookup WorkspaceLookup) *FileGuard {
+	return &FileGuard{executor: executor, lookup: lookup}
+}
+
+// Check resolves the requested path and returns it if the access is
+// allowed, or a *FileAccessError if not. Callers must use the returned
+// path so that the checked file is the one accessed. Relative paths are
+// resolved against the session's workspace, if bound.
+func (g *FileGuard) Check(req FileAccessRequest) (string, error) {
+	var workspacePath string
+	var readOnly bool
+	if g.lookup != nil && req.SessionID != "" {
+		if path, ro, ok := g.lookup(req.SessionID); ok {
+			workspacePath, readOnly = path, ro
+		}
+	}
+
+	resolved, err := ResolvePathIn(req.Path, workspacePath)
+	if err != nil {
+		return "", g.deny(req, workspacePath, "", "file_access:"+string(req.Mode), err.Error())
+	}
+
+	if readOnly && req.Mode == AccessWrite {
+		if ws, err := ResolvePath(workspacePath); err == nil && withinDir(resolved, ws) {
+			return "", g.deny(req, workspacePath, resolved, "file_access:read_only", "workspace is read-only")
+		}
+	}
+
+	if g.executor != nil && g.executor.policy != nil {
+		call := &ToolCall{
+			Name:          req.Tool,
+			SessionID:     req.SessionID,
+			AgentID:       req.AgentID,
+			WorkspacePath: workspacePath,
+		}
+		pol := EffectivePolicy(g.executor.policy, call, g.executor.matcher)
+		if rule, reason := pol.FileAccess.check(resolved, req.Mode, workspacePath); rule != "" {
+			return "", g.deny(req, workspacePath, resolved, rule, reason)
+		}
+	}
+
+	return resolved, nil
+}
+
+// deny records a denied access and returns the error for it.
+func (g *FileGuard) deny(req FileAccessRequest, workspacePath, resolved, rule, reason string) error {
+	if g.executor != nil {
+		args, _ := json.Marshal(map[string]string{
+			"path":     req.Path,
+			"resolved": resolved,
+			"access":   string(req.Mode),
+		})
+		now := g.executor.now()
+		g.executor.recordDecision(&Decision{
+			Timestamp: now,
+			Call: ToolCall{
+				Name:          req.Tool,
+				Arguments:     string(args),
+				SessionID:     req.SessionID,
+				AgentID:       req.AgentID,
+				WorkspacePath: workspacePath,
+			},
+			Context: EvalContext{Time: now},
+			Result: PolicyResult{
+				Allowed:      false,
+				Reason:       reason,
+				MatchedRules: []string{rule},
+			},
+		})
+		g.executor.logger.Info("file access denied",
+			"tool", req.Tool,
+			"path", req.Path,
+			"resolved", resolved,
+			"access", string(req.Mode),
+			"reason", reason,
+		)
+	}
+	return &FileAccessError{Path: req.Path, Resolved: resolved, Mode: req.Mode, Reason: reason}
+}
@@ -0,0 +1,191 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// realTempDir returns a temp dir with symlinks in its own path resolved
+// (e.g. /tmp -> /private/tmp on macOS).
+func realTempDir(t *testing.T) string {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	return dir
+}
+
+func TestResolvePath(t *testing.T) {
+	root := realTempDir(t)
+	outside := realTempDir(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "ws", "sub"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(outside, "secret"), 0755))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret"), filepath.Join(root, "ws", "link")))
+	require.NoError(t, os.Symlink("sub", filepath.Join(root, "ws", "rel")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "missing.txt"), filepath.Join(root, "ws", "dangling")))
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"plain", filepath.Join(root, "ws", "sub", "a.txt"), filepath.Join(root, "ws", "sub", "a.txt")},
+		{"lexical dotdot", root + "/ws/sub/../a.txt", filepath.Join(root, "ws", "a.txt")},
+		{"absolute link", root + "/ws/link/key", filepath.Join(outside, "secret", "key")},
+		{"dotdot after link", root + "/ws/link/../x", filepath.Join(outside, "x")},
+		{"relative link", root + "/ws/rel/a.txt", filepath.Join(root, "ws", "sub", "a.txt")},
+		{"dangling link", root + "/ws/dangling", filepath.Join(outside, "missing.txt")},
+		{"missing parents", root + "/ws/new/dir/f", filepath.Join(root, "ws", "new", "dir", "f")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolvePath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("relative to dir", func(t *testing.T) {
+		got, err := ResolvePathIn("link/key", filepath.Join(root, "ws"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(outside, "secret", "key"), got)
+	})
+
+	t.Run("symlink loop", func(t *testing.T) {
+		loop := filepath.Join(root, "loop")
+		require.NoError(t, os.Symlink(loop, loop))
+		_, err := ResolvePath(loop + "/x")
+		assert.Error(t, err)
+	})
+}
+
+func TestFileGuard(t *testing.T) {
+	root := realTempDir(t)
+	outside := realTempDir(t)
+	ws := filepath.Join(root, "ws")
+	require.NoError(t, os.MkdirAll(ws, 0755))
+	require.NoError(t, os.Symlink(outside, filepath.Join(ws, "escape")))
+
+	pol := &ToolPolicy{
+		DefaultAllow: true,
+		FileAccess: &FileAccessPolicy{
+			Read:  []string{"$WORKSPACE", root},
+			Write: []string{"$WORKSPACE"},
+			Deny:  []string{filepath.Join(root, "private")},
+		},
+		Overlays: []PolicyOverlay{
+			{Agent: "scratch", FileAccess: &FileAccessPolicy{Write: []string{outside}}},
+		},
+	}
+	executor := NewPolicyExecutor(pol)
+	log := NewMemoryDecisionLog(10)
+	executor.SetDecisionLogger(log)
+
+	bindings := map[string]bool{"rw": false, "ro": true}
+	guard := NewFileGuard(executor, func(sessionID string) (string, bool, bool) {
+		readOnly, ok := bindings[sessionID]
+		return ws, readOnly, ok
+	})
+
+	t.Run("relative path resolves in workspace", func(t *testing.T) {
+		got, err := guard.Check(FileAccessRequest{Tool: "write_file", Path: "src/main.go", Mode: AccessWrite, SessionID: "rw"})
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(ws, "src", "main.go"), got)
+	})
+
+	t.Run("symlink out of workspace", func(t *testing.T) {
+		_, err := guard.Check(FileAccessRequest{Tool: "write_file", Path: "escape/x", Mode: AccessWrite, SessionID: "rw"})
+		var denied *FileAccessError
+		require.ErrorAs(t, err, &denied)
+		assert.Equal(t, filepath.Join(outside, "x"), denied.Resolved)
+	})
+
+	t.Run("traversal out of workspace", func(t *testing.T) {
+		_, err := guard.Check(FileAccessRequest{Tool: "write_file", Path: "../x", Mode: AccessWrite, SessionID: "rw"})
+		assert.Error(t, err)
+		_, err = guard.Check(FileAccessRequest{Tool: "read_file", Path: "../x", Mode: AccessRead, SessionID: "rw"})
+		assert.NoError(t, err, "root is readable")
+	})
+
+	t.Run("deny takes precedence", func(t *testing.T) {
+		_, err := guard.Check(FileAccessRequest{Tool: "read_file", Path: filepath.Join(root, "private", "k"), Mode: AccessRead, SessionID: "rw"})
+		assert.Error(t, err)
+	})
+
+	t.Run("read-only workspace", func(t *testing.T) {
+		_, err := guard.Check(FileAccessRequest{Tool: "edit_file", Path: "a.txt", Mode: AccessWrite, SessionID: "ro"})
+		var denied *FileAccessError
+		require.ErrorAs(t, err, &denied)
+		assert.Equal(t, "workspace is read-only", denied.Reason)
+
+		_, err = guard.Check(FileAccessRequest{Tool: "read_file", Path: "a.txt", Mode: AccessRead, SessionID: "ro"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("no workspace skips $WORKSPACE", func(t *testing.T) {
+		_, err := guard.Check(FileAccessRequest{Tool: "write_file", Path: filepath.Join(ws, "a.txt"), Mode: AccessWrite, SessionID: "none"})
+		assert.Error(t, err)
+	})
+
+	t.Run("agent overlay", func(t *testing.T) {
+		_, err := guard.Check(FileAccessRequest{Tool: "write_file", Path: filepath.Join(outside, "x"), Mode: AccessWrite, AgentID: "scratch"})
+		assert.NoError(t, err)
+	})
+
+	decisions := log.Recent(0)
+	require.NotEmpty(t, decisions)
+	assert.False(t, decisions[0].Result.Allowed)
+	assert.Contains(t, decisions[0].Result.MatchedRules, "file_access:write")
+	assert.Equal(t, "write_file", decisions[0].Call.Name)
+	assert.Len(t, executor.RecentDecisions(0), len(decisions))
+}
+
+func TestPolicyExecutor_Check_FileAccess(t *testing.T) {
+	root := realTempDir(t)
+	ws := filepath.Join(root, "ws")
+	require.NoError(t, os.MkdirAll(ws, 0755))
+	require.NoError(t, os.Symlink(root, filepath.Join(ws, "up")))
+
+	executor := NewPolicyExecutor(&ToolPolicy{
+		DefaultAllow: true,
+		FileAccess:   &FileAccessPolicy{Write: []string{"$WORKSPACE"}},
+	})
+	ctx := context.Background()
+
+	result, err := executor.Check(ctx, &ToolCall{Name: "write_file", Arguments: `{"path":"notes.md"}`, WorkspacePath: ws})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = executor.Check(ctx, &ToolCall{Name: "write_file", Arguments: `{"path":"up/notes.md"}`, WorkspacePath: ws})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, []string{"file_access:write"}, result.MatchedRules)
+
+	result, err = executor.Check(ctx, &ToolCall{Name: "read_file", Arguments: `{"path":"up/notes.md"}`, WorkspacePath: ws})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "read scope is unrestricted")
+}
+
+func TestPolicyExecutor_Check_ParamRules_PathPrefix_Symlink(t *testing.T) {
+	root := realTempDir(t)
+	outside := realTempDir(t)
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "link")))
+
+	executor := NewPolicyExecutor(&ToolPolicy{
+		DefaultAllow: true,
+		ParamRules: map[string]ParamRule{
+			"read_file": {PathPrefix: []string{root}},
+		},
+	})
+
+	result, err := executor.Check(context.Background(), &ToolCall{Name: "read_file", Arguments: `{"path":"` + root + `/link/x"}`})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	result, err = executor.Check(context.Background(), &ToolCall{Name: "read_file", Arguments: `{"path":"` + root + `suffix/x"}`})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "prefix must match whole path components")
+}
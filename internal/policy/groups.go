@@ -13,6 +13,8 @@ var ToolGroups = map[string][]string{
 	"group:runtime": {
 		"shell",
 		"exec",
+		"shell_session",
+		"process",
 	},
 	"group:memory": {
 		"mote_memory_search",
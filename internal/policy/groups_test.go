@@ -21,12 +21,12 @@ func TestExpandGroups(t *testing.T) {
 		{
 			name:     "expand multiple groups",
 			input:    []string{"group:fs", "group:runtime"},
-			expected: []string{"read_file", "write_file", "list_dir", "delete_file", "shell", "exec"},
+			expected: []string{"read_file", "write_file", "list_dir", "delete_file", "shell", "exec", "shell_session", "process"},
 		},
 		{
 			name:     "mix groups and tools",
 			input:    []string{"group:runtime", "custom_tool"},
-			expected: []string{"shell", "exec", "custom_tool"},
+			expected: []string{"shell", "exec", "shell_session", "process", "custom_tool"},
 		},
 		{
 			name:     "unknown group returns as-is",
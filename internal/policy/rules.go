@@ -0,0 +1,238 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PolicyRule is a conditional rule written as an expression over the tool
+// call and its context. See Expr for the language and RuleVariables for
+// the variables a condition can use.
+type PolicyRule struct {
+	// Name identifies the rule in results and decision logs.
+	Name string `yaml:"name" json:"name"`
+
+	// Tool limits the rule to matching tools. Supports group:xxx syntax
+	// and wildcards; empty matches every tool.
+	Tool string `yaml:"tool,omitempty" json:"tool,omitempty"`
+
+	// When is the condition, e.g. `agent == "researcher" && hour >= 22`.
+	// Empty always matches.
+	When string `yaml:"when,omitempty" json:"when,omitempty"`
+
+	// Action determines how to handle a match: block, approve, warn.
+	Action string `yaml:"action" json:"action"`
+
+	// Message is the human-readable explanation shown to users.
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	// Enabled controls whether this rule is active. Default true.
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// compiled is the cached compiled condition (internal use).
+	compiled *Expr `yaml:"-" json:"-"`
+}
+
+// IsEnabled returns true if the rule is enabled.
+// A nil Enabled pointer defaults to true.
+func (r *PolicyRule) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// Condition returns the compiled condition, compiling it if needed.
+// It returns nil for an empty condition.
+func (r *PolicyRule) Condition() (*Expr, error) {
+	if r.compiled != nil || r.When == "" {
+		return r.compiled, nil
+	}
+	expr, err := CompileExpr(r.When)
+	if err != nil {
+		return nil, err
+	}
+	r.compiled = expr
+	return expr, nil
+}
+
+// displayName returns the name used in MatchedRules.
+func (r *PolicyRule) displayName() string {
+	if r.Name != "" {
+		return "rule:" + r.Name
+	}
+	if r.Message != "" {
+		return r.Message
+	}
+	return "rule:" + r.When
+}
+
+// PolicyOverlay adjusts the policy for one agent or workspace. Overlays
+// whose selectors match a call are merged over the base policy with
+// MergePolicy, in order. Unset fields keep the base values.
+type PolicyOverlay struct {
+	// Agent selects calls by agent ID. Supports wildcards; empty matches
+	// every agent. The main agent has an empty ID, matched by "main".
+	Agent string `yaml:"agent,omitempty" json:"agent,omitempty"`
+
+	// Workspace selects calls whose bound workspace is this directory or
+	// below it. Supports ~ expansion; empty matches every workspace.
+	Workspace string `yaml:"workspace,omitempty" json:"workspace,omitempty"`
+
+	DefaultAllow    *bool                `yaml:"default_allow,omitempty" json:"default_allow,omitempty"`
+	RequireApproval *bool                `yaml:"require_approval,omitempty" json:"require_approval,omitempty"`
+	Allowlist       []string             `yaml:"allowlist,omitempty" json:"allowlist,omitempty"`
+	Blocklist       []string             `yaml:"blocklist,omitempty" json:"blocklist,omitempty"`
+	DangerousOps    []DangerousOpRule    `yaml:"dangerous_ops,omitempty" json:"dangerous_ops,omitempty"`
+	ParamRules      map[string]ParamRule `yaml:"param_rules,omitempty" json:"param_rules,omitempty"`
+	Rules           []PolicyRule         `yaml:"rules,omitempty" json:"rules,omitempty"`
+	FileAccess      *FileAccessPolicy    `yaml:"file_access,omitempty" json:"file_access,omitempty"`
+	Egress          *EgressPolicy        `yaml:"egress,omitempty" json:"egress,omitempty"`
+}
+
+// matches reports whether the overlay applies to the call.
+func (o *PolicyOverlay) matches(call *ToolCall, matcher PatternMatcher) bool {
+	if o.Agent != "" {
+		agent := call.AgentID
+		if agent == "" {
+			agent = "main"
+		}
+		if !matcher.MatchTool(agent, []string{o.Agent}) {
+			return false
+		}
+	}
+	if o.Workspace != "" {
+		if call.WorkspacePath == "" {
+			return false
+		}
+		prefixes := expandPrefixes([]string{o.Workspace}, call.WorkspacePath)
+		if len(prefixes) == 0 || !withinDir(filepath.Clean(call.WorkspacePath), prefixes[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply merges the overlay over base.
+func (o *PolicyOverlay) apply(base *ToolPolicy) *ToolPolicy {
+	override := &ToolPolicy{
+		DefaultAllow:    base.DefaultAllow,
+		RequireApproval: base.RequireApproval,
+		Allowlist:       o.Allowlist,
+		Blocklist:       o.Blocklist,
+		DangerousOps:    o.DangerousOps,
+		ParamRules:      o.ParamRules,
+		Rules:           o.Rules,
+		FileAccess:      o.FileAccess,
+		Egress:          o.Egress,
+	}
+	if o.DefaultAllow != nil {
+		override.DefaultAllow = *o.DefaultAllow
+	}
+	if o.RequireApproval != nil {
+		override.RequireApproval = *o.RequireApproval
+	}
+	return MergePolicy(base, override)
+}
+
+// EffectivePolicy returns the policy that applies to a call: p with every
+// matching overlay merged over it.
+func EffectivePolicy(p *ToolPolicy, call *ToolCall, matcher PatternMatcher) *ToolPolicy {
+	effective := p
+	for i := range p.Overlays {
+		if p.Overlays[i].matches(call, matcher) {
+			effective = p.Overlays[i].apply(effective)
+		}
+	}
+	return effective
+}
+
+// EvalContext is the state a call is evaluated in beyond the call itself.
+// Check fills it from the clock and the calls seen so far; a recorded
+// decision carries it so the call can be replayed with Evaluate.
+type EvalContext struct {
+	// Time is when the call is made.
+	Time time.Time `json:"time"`
+
+	// ToolCalls counts earlier calls of the same tool in the session.
+	ToolCalls int `json:"tool_calls"`
+
+	// TotalCalls counts earlier calls of any tool in the session.
+	TotalCalls int `json:"total_calls"`
+}
+
+// RuleVariables returns the variables available to rule conditions:
+//
+//	tool        string  tool name
+//	args        map     parsed JSON arguments (empty if not an object)
+//	arguments   string  raw arguments
+//	agent       string  agent ID, "main" for the main agent
+//	session     string  session ID
+//	workspace   string  bound workspace path, "" if none
+//	hour        int     local hour, 0-23
+//	minute      int     minute, 0-59
+//	weekday     int     day of the week, 0 (Sunday) to 6
+//	date        string  local date, YYYY-MM-DD
+//	tool_calls  int     earlier calls of this tool in the session
+//	total_calls int     earlier calls of any tool in the session
+func RuleVariables(call *ToolCall, ec EvalContext) map[string]any {
+	args := map[string]any{}
+	if call.Arguments != "" {
+		var parsed map[string]any
+		if err := json.Unmarshal([]byte(call.Arguments), &parsed); err == nil && parsed != nil {
+			args = parsed
+		}
+	}
+	agent := call.AgentID
+	if agent == "" {
+		agent = "main"
+	}
+	t := ec.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	t = t.Local()
+	return map[string]any{
+		"tool":        call.Name,
+		"args":        args,
+		"arguments":   call.Arguments,
+		"agent":       agent,
+		"session":     call.SessionID,
+		"workspace":   call.WorkspacePath,
+		"hour":        int64(t.Hour()),
+		"minute":      int64(t.Minute()),
+		"weekday":     int64(t.Weekday()),
+		"date":        t.Format("2006-01-02"),
+		"tool_calls":  int64(ec.ToolCalls),
+		"total_calls": int64(ec.TotalCalls),
+	}
+}
+
+// validateRules checks actions and compiles conditions; field names the
+// list in error messages.
+func validateRules(field string, rules []PolicyRule) error {
+	for i := range rules {
+		rule := &rules[i]
+		switch rule.Action {
+		case "block", "approve", "warn":
+		default:
+			return fmt.Errorf("policy: %s[%d]: invalid action '%s' (must be block, approve, or warn)", field, i, rule.Action)
+		}
+		if _, err := rule.Condition(); err != nil {
+			return fmt.Errorf("policy: %s[%d]: %w", field, i, err)
+		}
+	}
+	return nil
+}
+
+// withinDir reports whether path is dir or inside it.
+func withinDir(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, string(os.PathSeparator)) {
+		dir += string(os.PathSeparator)
+	}
+	return strings.HasPrefix(path, dir)
+}
@@ -0,0 +1,153 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyExecutor_Check_Rules(t *testing.T) {
+	policy := &ToolPolicy{
+		DefaultAllow: true,
+		Rules: []PolicyRule{
+			{Name: "night-shell", Tool: "shell", When: "hour >= 22 || hour < 6", Action: "approve", Message: "shell at night requires approval"},
+			{Name: "push-quota", Tool: "mcp_github_*", When: "tool_calls >= 2", Action: "block", Message: "GitHub call quota reached"},
+			{Name: "force-push", When: `has(args.command) && args.command.contains("--force")`, Action: "warn"},
+		},
+	}
+	require.NoError(t, ValidatePolicy(policy))
+
+	executor := NewPolicyExecutor(policy)
+	now := time.Date(2026, 3, 2, 23, 30, 0, 0, time.Local)
+	executor.now = func() time.Time { return now }
+	log := NewMemoryDecisionLog(10)
+	executor.SetDecisionLogger(log)
+	ctx := context.Background()
+
+	result, err := executor.Check(ctx, &ToolCall{Name: "shell", Arguments: `{"command":"git push --force"}`, SessionID: "s1"})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.True(t, result.RequireApproval)
+	assert.Equal(t, "shell at night requires approval", result.ApprovalReason)
+	assert.Equal(t, []string{"rule:night-shell", "rule:force-push"}, result.MatchedRules)
+	assert.Equal(t, []string{"matched policy rule 'force-push'"}, result.Warnings)
+
+	now = now.Add(10 * time.Hour)
+	result, err = executor.Check(ctx, &ToolCall{Name: "shell", Arguments: `{"command":"ls"}`, SessionID: "s1"})
+	require.NoError(t, err)
+	assert.False(t, result.RequireApproval)
+
+	// The quota counts allowed calls per session
+	call := &ToolCall{Name: "mcp_github_push", Arguments: `{}`, SessionID: "s1"}
+	for i := 0; i < 2; i++ {
+		result, err = executor.Check(ctx, call)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+	result, err = executor.Check(ctx, call)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "GitHub call quota reached", result.Reason)
+
+	other := *call
+	other.SessionID = "s2"
+	result, err = executor.Check(ctx, &other)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// Every check is logged with the context it was evaluated in
+	decisions := log.Recent(0)
+	require.Len(t, decisions, 6)
+	blocked := decisions[1]
+	assert.Equal(t, "mcp_github_push", blocked.Call.Name)
+	assert.Equal(t, 2, blocked.Context.ToolCalls)
+	assert.Equal(t, 4, blocked.Context.TotalCalls)
+	assert.False(t, blocked.Result.Allowed)
+
+	// Replaying the recorded decision gives the same result without side effects
+	replayed, err := executor.Evaluate(ctx, &blocked.Call, blocked.Context)
+	require.NoError(t, err)
+	assert.False(t, replayed.Allowed)
+	assert.Len(t, log.Recent(0), 6)
+	assert.Len(t, executor.RecentDecisions(2), 2)
+}
+
+func TestPolicyExecutor_Check_RuleErrorsFailClosed(t *testing.T) {
+	policy := &ToolPolicy{
+		DefaultAllow: true,
+		Rules: []PolicyRule{
+			{Name: "big-writes", Tool: "write_file", When: "args.size > 1000", Action: "warn"},
+			{Name: "no-prod", Tool: "shell", When: `args.env == "prod"`, Action: "block"},
+		},
+	}
+	require.NoError(t, ValidatePolicy(policy))
+	executor := NewPolicyExecutor(policy)
+	ctx := context.Background()
+
+	// A non-blocking rule whose condition cannot be evaluated does not match
+	result, err := executor.Check(ctx, &ToolCall{Name: "write_file", Arguments: `{}`})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Empty(t, result.Warnings)
+
+	// A block rule that cannot be evaluated denies the call
+	result, err = executor.Check(ctx, &ToolCall{Name: "shell", Arguments: `{"command":"ls"}`})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, []string{"rule:no-prod"}, result.MatchedRules)
+	assert.Contains(t, result.Reason, "could not be evaluated")
+
+	result, err = executor.Check(ctx, &ToolCall{Name: "shell", Arguments: `{"env":"dev"}`})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestPolicyExecutor_Check_Overlays(t *testing.T) {
+	yes, no := true, false
+	policy := &ToolPolicy{
+		DefaultAllow: true,
+		Blocklist:    []string{"shell"},
+		Rules: []PolicyRule{
+			{Name: "no-deletes", Tool: "delete_file", Action: "block"},
+		},
+		Overlays: []PolicyOverlay{
+			{Agent: "coder", Blocklist: []string{"browser"}, RequireApproval: &yes},
+			{Workspace: "/srv/prod", DefaultAllow: &no, Allowlist: []string{"read_file", "delete_file"},
+				Rules: []PolicyRule{{Name: "prod-writes", Tool: "write_file", Action: "block"}}},
+		},
+	}
+	require.NoError(t, ValidatePolicy(policy))
+	executor := NewPolicyExecutor(policy)
+	ctx := context.Background()
+
+	check := func(call *ToolCall) *PolicyResult {
+		result, err := executor.Check(ctx, call)
+		require.NoError(t, err)
+		return result
+	}
+
+	// Base policy for the main agent
+	assert.False(t, check(&ToolCall{Name: "shell"}).Allowed)
+	assert.True(t, check(&ToolCall{Name: "browser"}).Allowed)
+
+	// The coder overlay replaces the blocklist and requires approval
+	coder := check(&ToolCall{Name: "shell", AgentID: "coder"})
+	assert.True(t, coder.Allowed)
+	assert.True(t, coder.RequireApproval)
+	assert.False(t, check(&ToolCall{Name: "browser", AgentID: "coder"}).Allowed)
+
+	// The workspace overlay applies below the directory only and keeps the base rules
+	assert.True(t, check(&ToolCall{Name: "read_file", WorkspacePath: "/srv/prod/app"}).Allowed)
+	assert.False(t, check(&ToolCall{Name: "write_file", WorkspacePath: "/srv/prod/app"}).Allowed)
+	assert.False(t, check(&ToolCall{Name: "delete_file", WorkspacePath: "/srv/prod"}).Allowed)
+	assert.True(t, check(&ToolCall{Name: "write_file", WorkspacePath: "/srv/production"}).Allowed)
+}
+
+func TestValidatePolicy_Rules(t *testing.T) {
+	assert.Error(t, ValidatePolicy(&ToolPolicy{Rules: []PolicyRule{{When: "hour >", Action: "block"}}}))
+	assert.Error(t, ValidatePolicy(&ToolPolicy{Rules: []PolicyRule{{When: "true", Action: "allow"}}}))
+	assert.Error(t, ValidatePolicy(&ToolPolicy{Overlays: []PolicyOverlay{{Blocklist: []string{"shell"}}}}))
+}
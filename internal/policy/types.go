@@ -52,6 +52,21 @@ type ToolPolicy struct {
 	// ParamRules defines validation rules for tool parameters.
 	ParamRules map[string]ParamRule `yaml:"param_rules" json:"param_rules"`
 
+	// FileAccess limits where file tools may read and write, checked on
+	// resolved paths. Nil leaves file access unrestricted.
+	FileAccess *FileAccessPolicy `yaml:"file_access,omitempty" json:"file_access,omitempty"`
+
+	// Egress limits the hosts tools, scripts and the processes they start
+	// may connect to. Nil leaves network access unrestricted.
+	Egress *EgressPolicy `yaml:"egress,omitempty" json:"egress,omitempty"`
+
+	// Rules are conditional rules evaluated over the call and its context
+	// (agent, workspace, time of day, call counts).
+	Rules []PolicyRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+
+	// Overlays adjust the policy for specific agents or workspaces.
+	Overlays []PolicyOverlay `yaml:"overlays,omitempty" json:"overlays,omitempty"`
+
 	// ScrubRules defines custom credential scrubbing rules.
 	// Applied after built-in patterns.
 	ScrubRules []ScrubRule `yaml:"scrub_rules,omitempty" json:"scrub_rules,omitempty"`
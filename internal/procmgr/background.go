@@ -0,0 +1,517 @@
+package procmgr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mote/pkg/logger"
+)
+
+// Background processes are started on behalf of agents. Unlike the helper
+// processes managed by Manager they belong to a chat session, are never
+// restarted, and keep their recent output in memory so it can be polled.
+
+// DefaultOutputLimit is how many bytes of output are kept per process.
+const DefaultOutputLimit = 1 << 20
+
+// Process states.
+const (
+	StateRunning = "running"
+	StateExited  = "exited"
+	StateKilled  = "killed"
+)
+
+// Process kinds.
+const (
+	KindProcess = "process"
+	KindShell   = "shell"
+)
+
+// ErrProcessNotFound is returned for unknown process IDs.
+var ErrProcessNotFound = errors.New("process not found")
+
+// BackgroundOptions configures a background process.
+type BackgroundOptions struct {
+	SessionID string
+	Kind      string
+	// Command is the command line shown to users.
+	Command string
+	Path    string
+	Args    []string
+	WorkDir string
+	Env     []string
+	// PTY attaches the process to a pseudo-terminal. Falls back to pipes on
+	// platforms without pty support.
+	PTY bool
+}
+
+// ProcessInfo describes a background process.
+type ProcessInfo struct {
+	ID         string     `json:"id"`
+	SessionID  string     `json:"session_id"`
+	Kind       string     `json:"kind"`
+	Command    string     `json:"command"`
+	WorkDir    string     `json:"work_dir,omitempty"`
+	PID        int        `json:"pid"`
+	PTY        bool       `json:"pty"`
+	State      string     `json:"state"`
+	ExitCode   *int       `json:"exit_code,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	ExitedAt   *time.Time `json:"exited_at,omitempty"`
+	OutputSize int64      `json:"output_size"`
+}
+
+// ShellCommand returns the platform shell invocation for a command line.
+func ShellCommand(command string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", command}
+	}
+	return "sh", []string{"-c", command}
+}
+
+// BackgroundProcess is a process started by BackgroundManager.
+type BackgroundProcess struct {
+	id        string
+	opts      BackgroundOptions
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	pty       *os.File
+	startedAt time.Time
+	out       *outputBuffer
+	done      chan struct{}
+
+	mu       sync.Mutex
+	exitedAt time.Time
+	exitCode int
+	killed   bool
+	cursor   int64 // output offset already returned by Poll
+}
+
+// ID returns the process ID (not the OS pid).
+func (p *BackgroundProcess) ID() string { return p.id }
+
+// SessionID returns the session that owns the process.
+func (p *BackgroundProcess) SessionID() string { return p.opts.SessionID }
+
+// Done is closed when the process has exited.
+func (p *BackgroundProcess) Done() <-chan struct{} { return p.done }
+
+// Running reports whether the process is still running.
+func (p *BackgroundProcess) Running() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Info returns a snapshot of the process state.
+func (p *BackgroundProcess) Info() ProcessInfo {
+	info := ProcessInfo{
+		ID:         p.id,
+		SessionID:  p.opts.SessionID,
+		Kind:       p.opts.Kind,
+		Command:    p.opts.Command,
+		WorkDir:    p.opts.WorkDir,
+		PID:        p.cmd.Process.Pid,
+		PTY:        p.pty != nil,
+		State:      StateRunning,
+		StartedAt:  p.startedAt,
+		OutputSize: p.out.End(),
+	}
+	if !p.Running() {
+		p.mu.Lock()
+		code, exited := p.exitCode, p.exitedAt
+		info.State = StateExited
+		if p.killed {
+			info.State = StateKilled
+		}
+		p.mu.Unlock()
+		info.ExitCode = &code
+		info.ExitedAt = &exited
+	}
+	return info
+}
+
+// Read returns output from the absolute offset onwards and the offset to
+// continue from. Output older than the retention limit is skipped.
+func (p *BackgroundProcess) Read(offset int64) (string, int64) {
+	data, next := p.out.ReadFrom(offset)
+	return string(data), next
+}
+
+// Poll returns output produced since the previous Poll.
+func (p *BackgroundProcess) Poll() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, next := p.out.ReadFrom(p.cursor)
+	p.cursor = next
+	return string(data)
+}
+
+// PollWait waits up to timeout for output newer than the previous Poll,
+// then polls.
+func (p *BackgroundProcess) PollWait(timeout time.Duration) string {
+	p.mu.Lock()
+	cursor := p.cursor
+	p.mu.Unlock()
+	p.WaitOutput(cursor, timeout)
+	return p.Poll()
+}
+
+// Write sends input to the process.
+func (p *BackgroundProcess) Write(input string) error {
+	if !p.Running() {
+		return fmt.Errorf("process %s has exited", p.id)
+	}
+	if p.stdin == nil {
+		return fmt.Errorf("process %s has no stdin", p.id)
+	}
+	_, err := io.WriteString(p.stdin, input)
+	return err
+}
+
+// Interrupt sends Ctrl-C to the process (or SIGINT when not on a pty).
+func (p *BackgroundProcess) Interrupt() error {
+	if !p.Running() {
+		return nil
+	}
+	if p.pty != nil {
+		_, err := p.pty.Write([]byte{0x03})
+		return err
+	}
+	return interruptProcessGroup(p.cmd)
+}
+
+// Kill terminates the process and its children, escalating to a hard kill
+// if it does not exit promptly.
+func (p *BackgroundProcess) Kill() error {
+	if !p.Running() {
+		return nil
+	}
+	p.mu.Lock()
+	p.killed = true
+	p.mu.Unlock()
+
+	_ = terminateProcessGroup(p.cmd, false)
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(3 * time.Second):
+	}
+	_ = terminateProcessGroup(p.cmd, true)
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(2 * time.Second):
+		return fmt.Errorf("process %s did not exit", p.id)
+	}
+}
+
+// WaitOutput blocks until output beyond offset is available, the process
+// exits, or timeout elapses.
+func (p *BackgroundProcess) WaitOutput(offset int64, timeout time.Duration) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		notify := p.out.Changed()
+		if p.out.End() > offset {
+			return
+		}
+		select {
+		case <-notify:
+		case <-p.done:
+			return
+		case <-deadline.C:
+			return
+		}
+	}
+}
+
+// BackgroundManager tracks background processes and shell sessions by
+// owning session.
+type BackgroundManager struct {
+	mu          sync.RWMutex
+	procs       map[string]*BackgroundProcess
+	shells      map[string]*ShellSession // sessionID → shell
+	shellMu     sync.Mutex               // serialises shell creation
+	outputLimit int
+}
+
+// NewBackgroundManager creates an empty manager.
+func NewBackgroundManager() *BackgroundManager {
+	return &BackgroundManager{
+		procs:       make(map[string]*BackgroundProcess),
+		shells:      make(map[string]*ShellSession),
+		outputLimit: DefaultOutputLimit,
+	}
+}
+
+// Start launches a background process.
+func (m *BackgroundManager) Start(opts BackgroundOptions) (*BackgroundProcess, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("process path is required")
+	}
+	if opts.Kind == "" {
+		opts.Kind = KindProcess
+	}
+	if opts.Command == "" {
+		opts.Command = opts.Path
+	}
+
+	cmd := exec.Command(opts.Path, opts.Args...)
+	cmd.Dir = opts.WorkDir
+	cmd.Env = append(os.Environ(), opts.Env...)
+
+	p := &BackgroundProcess{
+		id:   "proc_" + uuid.NewString()[:8],
+		opts: opts,
+		cmd:  cmd,
+		out:  newOutputBuffer(m.outputLimit),
+		done: make(chan struct{}),
+	}
+
+	copied := make(chan struct{})
+	if opts.PTY {
+		ptmx, tty, err := openPTY()
+		if err != nil {
+			logger.Warnf("pty unavailable, using pipes for %s: %v", p.id, err)
+		} else {
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+			configureBackgroundProcess(cmd, true)
+			err = cmd.Start()
+			tty.Close()
+			if err != nil {
+				ptmx.Close()
+				return nil, fmt.Errorf("failed to start %q: %w", opts.Command, err)
+			}
+			p.pty = ptmx
+			p.stdin = ptmx
+			go func() {
+				// Reading the master returns EIO once the last slave fd closes.
+				_, _ = io.Copy(p.out, ptmx)
+				close(copied)
+			}()
+		}
+	}
+	if p.pty == nil {
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		cmd.Stdout = p.out
+		cmd.Stderr = p.out
+		// Don't let grandchildren holding the pipes open keep Wait blocked.
+		cmd.WaitDelay = 2 * time.Second
+		configureBackgroundProcess(cmd, false)
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start %q: %w", opts.Command, err)
+		}
+		p.stdin = stdin
+		close(copied)
+	}
+	p.startedAt = time.Now()
+
+	go func() {
+		_ = cmd.Wait()
+		if p.pty != nil {
+			select {
+			case <-copied:
+			case <-time.After(time.Second):
+			}
+			p.pty.Close()
+		}
+		p.mu.Lock()
+		p.exitedAt = time.Now()
+		p.exitCode = cmd.ProcessState.ExitCode()
+		p.mu.Unlock()
+		close(p.done)
+		logger.Infof("background process %s exited (pid: %d, code: %d)", p.id, cmd.Process.Pid, p.exitCode)
+	}()
+
+	m.mu.Lock()
+	m.procs[p.id] = p
+	m.mu.Unlock()
+
+	logger.Infof("started background process %s for session %s (pid: %d): %s",
+		p.id, opts.SessionID, cmd.Process.Pid, opts.Command)
+	return p, nil
+}
+
+// Get returns a process by ID.
+func (m *BackgroundManager) Get(id string) (*BackgroundProcess, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.procs[id]
+	return p, ok
+}
+
+// List returns processes owned by sessionID, or all processes when it is
+// empty, oldest first.
+func (m *BackgroundManager) List(sessionID string) []ProcessInfo {
+	m.mu.RLock()
+	procs := make([]*BackgroundProcess, 0, len(m.procs))
+	for _, p := range m.procs {
+		if sessionID == "" || p.opts.SessionID == sessionID {
+			procs = append(procs, p)
+		}
+	}
+	m.mu.RUnlock()
+
+	infos := make([]ProcessInfo, 0, len(procs))
+	for _, p := range procs {
+		infos = append(infos, p.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].StartedAt.Before(infos[j].StartedAt) })
+	return infos
+}
+
+// Kill terminates a process. The record is kept so its output can still be read.
+func (m *BackgroundManager) Kill(id string) error {
+	p, ok := m.Get(id)
+	if !ok {
+		return ErrProcessNotFound
+	}
+	return p.Kill()
+}
+
+// Remove kills a process if needed and forgets it.
+func (m *BackgroundManager) Remove(id string) error {
+	p, ok := m.Get(id)
+	if !ok {
+		return ErrProcessNotFound
+	}
+	err := p.Kill()
+	m.mu.Lock()
+	delete(m.procs, id)
+	for sid, sh := range m.shells {
+		if sh.proc == p {
+			delete(m.shells, sid)
+		}
+	}
+	m.mu.Unlock()
+	return err
+}
+
+// CleanupSession kills and forgets every process and shell owned by
+// sessionID or by its delegate sub-sessions. It returns the number of
+// processes removed.
+func (m *BackgroundManager) CleanupSession(sessionID string) int {
+	owned := func(id string) bool {
+		return id == sessionID || strings.HasPrefix(id, "delegate:"+sessionID+":")
+	}
+	m.mu.Lock()
+	var procs []*BackgroundProcess
+	for id, p := range m.procs {
+		if owned(p.opts.SessionID) {
+			procs = append(procs, p)
+			delete(m.procs, id)
+		}
+	}
+	for id := range m.shells {
+		if owned(id) {
+			delete(m.shells, id)
+		}
+	}
+	m.mu.Unlock()
+
+	killAll(procs)
+	if len(procs) > 0 {
+		logger.Infof("cleaned up %d background processes for session %s", len(procs), sessionID)
+	}
+	return len(procs)
+}
+
+// StopAll kills every background process.
+func (m *BackgroundManager) StopAll() {
+	m.mu.Lock()
+	procs := make([]*BackgroundProcess, 0, len(m.procs))
+	for _, p := range m.procs {
+		procs = append(procs, p)
+	}
+	m.procs = make(map[string]*BackgroundProcess)
+	m.shells = make(map[string]*ShellSession)
+	m.mu.Unlock()
+
+	killAll(procs)
+}
+
+func killAll(procs []*BackgroundProcess) {
+	var wg sync.WaitGroup
+	for _, p := range procs {
+		wg.Add(1)
+		go func(p *BackgroundProcess) {
+			defer wg.Done()
+			if err := p.Kill(); err != nil {
+				logger.Warnf("failed to kill background process %s: %v", p.id, err)
+			}
+		}(p)
+	}
+	wg.Wait()
+}
+
+// outputBuffer keeps the most recent output of a process, addressed by
+// absolute byte offsets so readers can resume where they left off.
+type outputBuffer struct {
+	mu      sync.Mutex
+	data    []byte
+	base    int64 // absolute offset of data[0]
+	limit   int
+	changed chan struct{}
+}
+
+func newOutputBuffer(limit int) *outputBuffer {
+	return &outputBuffer{limit: limit, changed: make(chan struct{})}
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.limit; over > 0 {
+		b.data = append([]byte(nil), b.data[over:]...)
+		b.base += int64(over)
+	}
+	close(b.changed)
+	b.changed = make(chan struct{})
+	return len(p), nil
+}
+
+// End returns the absolute offset after the last byte written.
+func (b *outputBuffer) End() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.base + int64(len(b.data))
+}
+
+// Changed returns a channel closed on the next write.
+func (b *outputBuffer) Changed() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.changed
+}
+
+// ReadFrom returns a copy of the data from offset and the end offset.
+func (b *outputBuffer) ReadFrom(offset int64) ([]byte, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	end := b.base + int64(len(b.data))
+	if offset < b.base {
+		offset = b.base
+	}
+	if offset >= end {
+		return nil, end
+	}
+	return append([]byte(nil), b.data[offset-b.base:]...), end
+}
@@ -0,0 +1,111 @@
+//go:build !windows
+
+package procmgr
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputBufferTruncatesOldest(t *testing.T) {
+	b := newOutputBuffer(8)
+	_, _ = b.Write([]byte("0123456789"))
+
+	data, end := b.ReadFrom(0)
+	if string(data) != "23456789" || end != 10 {
+		t.Fatalf("ReadFrom(0) = %q, %d", data, end)
+	}
+	data, _ = b.ReadFrom(7)
+	if string(data) != "789" {
+		t.Fatalf("ReadFrom(7) = %q", data)
+	}
+	if data, end = b.ReadFrom(10); data != nil || end != 10 {
+		t.Fatalf("ReadFrom(end) = %q, %d", data, end)
+	}
+}
+
+func TestBackgroundProcessPollAndKill(t *testing.T) {
+	m := NewBackgroundManager()
+	defer m.StopAll()
+
+	path, args := ShellCommand("echo started; sleep 30")
+	proc, err := m.Start(BackgroundOptions{SessionID: "s1", Command: "test", Path: path, Args: args})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if out := proc.PollWait(5 * time.Second); !strings.Contains(out, "started") {
+		t.Fatalf("first poll = %q", out)
+	}
+	if out := proc.Poll(); out != "" {
+		t.Fatalf("second poll should be empty, got %q", out)
+	}
+
+	start := time.Now()
+	if err := m.Kill(proc.ID()); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("kill took %v", time.Since(start))
+	}
+	if info := proc.Info(); info.State != StateKilled {
+		t.Errorf("state = %s, want %s", info.State, StateKilled)
+	}
+}
+
+func TestCleanupSessionIncludesDelegates(t *testing.T) {
+	m := NewBackgroundManager()
+	defer m.StopAll()
+
+	path, args := ShellCommand("sleep 30")
+	for _, sid := range []string{"s1", "delegate:s1:coder:1700000000000", "s2"} {
+		if _, err := m.Start(BackgroundOptions{SessionID: sid, Command: "sleep", Path: path, Args: args}); err != nil {
+			t.Fatalf("Start(%s): %v", sid, err)
+		}
+	}
+
+	if n := m.CleanupSession("s1"); n != 2 {
+		t.Fatalf("CleanupSession = %d, want 2", n)
+	}
+	if got := len(m.List("")); got != 1 {
+		t.Fatalf("remaining processes = %d, want 1", got)
+	}
+}
+
+func TestShellSessionPersistsState(t *testing.T) {
+	m := NewBackgroundManager()
+	defer m.StopAll()
+
+	sh, err := m.Shell("s1", t.TempDir())
+	if err != nil {
+		t.Skipf("pty shell unavailable: %v", err)
+	}
+
+	if _, err := sh.Exec("export MOTE_TEST_VAR=hello; cd /", 5*time.Second); err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	res, err := sh.Exec("echo $MOTE_TEST_VAR; pwd", 5*time.Second)
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if !res.Done || res.Output != "hello\n/\n" {
+		t.Fatalf("result = %+v", res)
+	}
+
+	res, _ = sh.Exec("false", 5*time.Second)
+	if res.ExitCode != 1 {
+		t.Errorf("exit code = %d, want 1", res.ExitCode)
+	}
+
+	res, _ = sh.Exec("sleep 1; echo late", 100*time.Millisecond)
+	if res.Done {
+		t.Fatalf("expected command to still be running")
+	}
+	if _, err := sh.Exec("true", time.Second); err != ErrShellBusy {
+		t.Errorf("Exec while busy = %v, want ErrShellBusy", err)
+	}
+	res, _ = sh.Read(5 * time.Second)
+	if !res.Done || !strings.Contains(res.Output, "late") {
+		t.Fatalf("read = %+v", res)
+	}
+}
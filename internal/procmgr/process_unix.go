@@ -15,3 +15,35 @@ func configurePlatformProcess(cmd *exec.Cmd, cfg *ProcessConfig) {
 		Setpgid: true,
 	}
 }
+
+// configureBackgroundProcess puts an agent process in its own process group
+// (and, when attached to a pty, its own session with the pty as controlling
+// terminal) so that it and its children can be signalled together.
+func configureBackgroundProcess(cmd *exec.Cmd, pty bool) {
+	if pty {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+		return
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminateProcessGroup asks the process group to exit, or kills it when
+// force is set. SIGHUP is sent along with SIGTERM because interactive shells
+// ignore SIGTERM.
+func terminateProcessGroup(cmd *exec.Cmd, force bool) error {
+	if force {
+		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGHUP); err != nil {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// interruptProcessGroup sends SIGINT to the process group.
+func interruptProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+}
@@ -4,6 +4,7 @@
 package procmgr
 
 import (
+	"errors"
 	"os/exec"
 	"syscall"
 )
@@ -17,3 +18,19 @@ func configurePlatformProcess(cmd *exec.Cmd, cfg *ProcessConfig) {
 		}
 	}
 }
+
+// configureBackgroundProcess hides the console window of agent processes.
+func configureBackgroundProcess(cmd *exec.Cmd, _ bool) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
+
+// terminateProcessGroup kills the process; Windows has no process group
+// signals, so graceful termination is not available.
+func terminateProcessGroup(cmd *exec.Cmd, _ bool) error {
+	return cmd.Process.Kill()
+}
+
+// interruptProcessGroup is not supported on Windows.
+func interruptProcessGroup(cmd *exec.Cmd) error {
+	return errors.New("interrupt not supported on windows")
+}
@@ -0,0 +1,52 @@
+//go:build darwin
+
+package procmgr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// openPTY allocates a pseudo-terminal pair.
+func openPTY() (ptmx, tty *os.File, err error) {
+	ptmx, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			ptmx.Close()
+		}
+	}()
+
+	fd := ptmx.Fd()
+	if err = ioctl(fd, syscall.TIOCPTYGRANT, 0); err != nil {
+		return nil, nil, fmt.Errorf("grant pty: %w", err)
+	}
+	if err = ioctl(fd, syscall.TIOCPTYUNLK, 0); err != nil {
+		return nil, nil, fmt.Errorf("unlock pty: %w", err)
+	}
+	name := make([]byte, 128)
+	if err = ioctl(fd, syscall.TIOCPTYGNAME, uintptr(unsafe.Pointer(&name[0]))); err != nil {
+		return nil, nil, fmt.Errorf("get pty name: %w", err)
+	}
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+
+	tty, err = os.OpenFile(string(name), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ptmx, tty, nil
+}
+
+func ioctl(fd, req, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package procmgr
+
+import (
+	"errors"
+	"os"
+)
+
+// openPTY is not supported on this platform; callers fall back to pipes.
+func openPTY() (ptmx, tty *os.File, err error) {
+	return nil, nil, errors.New("pty not supported on this platform")
+}
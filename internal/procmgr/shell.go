@@ -0,0 +1,212 @@
+package procmgr
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrShellBusy is returned when a command is sent to a shell that is still
+// running the previous one.
+var ErrShellBusy = errors.New("shell is still running the previous command")
+
+// ShellSession is a persistent shell attached to a pty. Commands run one at
+// a time; the working directory, environment and shell variables persist
+// between them. Completion is detected by printing a unique marker with the
+// command's exit status after each command.
+type ShellSession struct {
+	proc *BackgroundProcess
+
+	mu      sync.Mutex
+	pending *pendingCommand
+}
+
+type pendingCommand struct {
+	command  string
+	marker   *regexp.Regexp
+	reported int64 // output offset already returned to the caller
+}
+
+// ShellResult is the output of a shell command.
+type ShellResult struct {
+	Output string `json:"output"`
+	// Done is false when the command was still running at the timeout; call
+	// Read to collect more output.
+	Done     bool `json:"done"`
+	ExitCode int  `json:"exit_code"`
+}
+
+// Shell returns the persistent shell of sessionID, starting one in workDir
+// with env added to its environment if there is none or the previous one
+// has exited.
+func (m *BackgroundManager) Shell(sessionID, workDir string, env ...string) (*ShellSession, error) {
+	if runtime.GOOS == "windows" {
+		return nil, errors.New("persistent shell sessions are not supported on windows")
+	}
+
+	m.shellMu.Lock()
+	defer m.shellMu.Unlock()
+
+	m.mu.RLock()
+	sh, ok := m.shells[sessionID]
+	m.mu.RUnlock()
+	if ok && sh.proc.Running() {
+		return sh, nil
+	}
+
+	path, args := interactiveShell()
+	proc, err := m.Start(BackgroundOptions{
+		SessionID: sessionID,
+		Kind:      KindShell,
+		Command:   path,
+		Path:      path,
+		Args:      args,
+		WorkDir:   workDir,
+		Env:       append([]string{"PS1=", "PS2=", "PROMPT_COMMAND=", "TERM=dumb", "HISTFILE=/dev/null"}, env...),
+		PTY:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sh = &ShellSession{proc: proc}
+
+	// Disable echo so output contains only what commands print.
+	if proc.pty != nil {
+		if err := proc.Write("stty -echo 2>/dev/null\n"); err != nil {
+			_ = proc.Kill()
+			return nil, err
+		}
+	}
+	if res, err := sh.Exec("true", 10*time.Second); err != nil || !res.Done {
+		_ = m.Remove(proc.id)
+		if err == nil {
+			err = errors.New("shell did not become ready")
+		}
+		return nil, fmt.Errorf("failed to start shell: %w", err)
+	}
+
+	m.mu.Lock()
+	if old, ok := m.shells[sessionID]; ok && old.proc != proc {
+		delete(m.procs, old.proc.id)
+	}
+	m.shells[sessionID] = sh
+	m.mu.Unlock()
+	return sh, nil
+}
+
+// CloseShell stops the persistent shell of sessionID, if any.
+func (m *BackgroundManager) CloseShell(sessionID string) error {
+	m.mu.RLock()
+	sh, ok := m.shells[sessionID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return m.Remove(sh.proc.id)
+}
+
+// interactiveShell picks bash when available, otherwise sh.
+func interactiveShell() (string, []string) {
+	if path, err := exec.LookPath("bash"); err == nil {
+		return path, []string{"--noprofile", "--norc"}
+	}
+	return "sh", nil
+}
+
+// Process returns the underlying background process.
+func (s *ShellSession) Process() *BackgroundProcess { return s.proc }
+
+// Exec runs command and waits up to timeout for it to finish.
+func (s *ShellSession) Exec(command string, timeout time.Duration) (ShellResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending != nil {
+		return ShellResult{}, ErrShellBusy
+	}
+	if !s.proc.Running() {
+		return ShellResult{}, fmt.Errorf("shell has exited")
+	}
+
+	marker := "__MOTE_" + strings.ReplaceAll(uuid.NewString(), "-", "") + "__"
+	s.pending = &pendingCommand{
+		command:  command,
+		marker:   regexp.MustCompile(`\r?\n?` + marker + `:(\d+)\r?\n`),
+		reported: s.proc.out.End(),
+	}
+	line := strings.TrimRight(command, "\n") + "\n" + fmt.Sprintf("printf '\\n%%s:%%d\\n' %s \"$?\"\n", marker)
+	if err := s.proc.Write(line); err != nil {
+		s.pending = nil
+		return ShellResult{}, err
+	}
+	return s.wait(timeout), nil
+}
+
+// Read waits up to timeout for more output of the running command.
+func (s *ShellSession) Read(timeout time.Duration) (ShellResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending == nil {
+		return ShellResult{Done: true}, nil
+	}
+	return s.wait(timeout), nil
+}
+
+// Write sends raw input to the shell, e.g. an answer to a prompt.
+func (s *ShellSession) Write(input string) error {
+	return s.proc.Write(input)
+}
+
+// Interrupt sends Ctrl-C to the running command.
+func (s *ShellSession) Interrupt() error {
+	return s.proc.Interrupt()
+}
+
+// Busy reports whether a command is still running.
+func (s *ShellSession) Busy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending != nil
+}
+
+// wait collects output of the pending command until its marker appears,
+// the shell exits, or timeout elapses. Callers hold s.mu.
+func (s *ShellSession) wait(timeout time.Duration) ShellResult {
+	p := s.pending
+	deadline := time.Now().Add(timeout)
+	for {
+		data, end := s.proc.out.ReadFrom(p.reported)
+		if loc := p.marker.FindSubmatchIndex(data); loc != nil {
+			code, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+			s.pending = nil
+			return ShellResult{Output: cleanOutput(data[:loc[0]]), Done: true, ExitCode: code}
+		}
+		if !s.proc.Running() {
+			s.pending = nil
+			return ShellResult{Output: cleanOutput(data) + "\n(shell exited)", Done: true, ExitCode: -1}
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			// Only report complete lines so a partially printed marker is
+			// never returned as output.
+			n := strings.LastIndexByte(string(data), '\n') + 1
+			p.reported = end - int64(len(data)-n)
+			return ShellResult{Output: cleanOutput(data[:n]), Done: false}
+		}
+		s.proc.WaitOutput(end, remaining)
+	}
+}
+
+// cleanOutput normalises pty line endings.
+func cleanOutput(data []byte) string {
+	return strings.ReplaceAll(string(data), "\r\n", "\n")
+}
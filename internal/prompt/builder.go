@@ -11,6 +11,7 @@ import (
 
 	"mote/internal/mcp/client"
 	"mote/internal/tools"
+	"mote/internal/tools/retrieval"
 )
 
 // MCPInjectionMode controls how MCP tools are injected into the system prompt.
@@ -47,6 +48,7 @@ type SystemPromptBuilder struct {
 	injector         *PromptInjector
 	mcpManager       *client.Manager
 	mcpInjectionMode MCPInjectionMode
+	toolCatalog      *retrieval.Catalog
 	agents           []AgentInfo
 	maxOutputTokens  int // Maximum output tokens for the current model (0 = unknown)
 }
@@ -95,6 +97,14 @@ func (b *SystemPromptBuilder) WithMCPManager(m *client.Manager) *SystemPromptBui
 	return b
 }
 
+// WithToolCatalog switches the prompt to tool retrieval mode: only core
+// tools are listed, and the MCP section is replaced by a pointer to
+// search_tools.
+func (b *SystemPromptBuilder) WithToolCatalog(c *retrieval.Catalog) *SystemPromptBuilder {
+	b.toolCatalog = c
+	return b
+}
+
 // WithAgents sets the available sub-agents for prompt rendering.
 func (b *SystemPromptBuilder) WithAgents(agents []AgentInfo) *SystemPromptBuilder {
 	b.agents = agents
@@ -167,6 +177,9 @@ func (b *SystemPromptBuilder) prepareData() PromptData {
 	if b.registry != nil {
 		toolList := b.registry.List()
 		for _, t := range toolList {
+			if b.toolCatalog != nil && !b.toolCatalog.IsCore(t.Name()) {
+				continue
+			}
 			data.Tools = append(data.Tools, ToolInfo{
 				Name:        t.Name(),
 				Description: t.Description(),
@@ -216,8 +229,13 @@ func (b *SystemPromptBuilder) renderTemplates(data PromptData) (string, error) {
 		}
 	}
 
-	// Append MCP tools section
-	if b.mcpManager != nil && b.mcpInjectionMode != MCPInjectionNone {
+	// Append MCP tools section; in retrieval mode MCP tools are part of the catalog
+	if b.toolCatalog != nil {
+		if section := b.buildToolCatalogSection(); section != "" {
+			result.WriteString("\n")
+			result.WriteString(section)
+		}
+	} else if b.mcpManager != nil && b.mcpInjectionMode != MCPInjectionNone {
 		mcpSection := b.buildMCPSection()
 		if mcpSection != "" {
 			result.WriteString("\n")
@@ -243,6 +261,44 @@ func (b *SystemPromptBuilder) renderTemplates(data PromptData) (string, error) {
 	return result.String(), nil
 }
 
+// buildToolCatalogSection tells the model how many tools can be found with
+// search_tools and where they come from.
+func (b *SystemPromptBuilder) buildToolCatalogSection() string {
+	deferred := b.toolCatalog.Deferred()
+	if len(deferred) == 0 {
+		return ""
+	}
+
+	builtinCount := 0
+	perServer := make(map[string]int)
+	var servers []string
+	for _, t := range deferred {
+		if c, ok := t.(interface{ ClientName() string }); ok {
+			if perServer[c.ClientName()] == 0 {
+				servers = append(servers, c.ClientName())
+			}
+			perServer[c.ClientName()]++
+		} else {
+			builtinCount++
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("\n## Tool Discovery\n\n")
+	builder.WriteString(fmt.Sprintf("Only core tools are loaded. %d more tools are available on demand", len(deferred)))
+	var sources []string
+	if builtinCount > 0 {
+		sources = append(sources, fmt.Sprintf("%d built-in", builtinCount))
+	}
+	for _, name := range servers {
+		sources = append(sources, fmt.Sprintf("MCP server `%s`: %d", name, perServer[name]))
+	}
+	builder.WriteString(fmt.Sprintf(" (%s).\n", strings.Join(sources, ", ")))
+	builder.WriteString(fmt.Sprintf("When no loaded tool fits the task, call `%s` with a short description of the capability you need. ", retrieval.SearchToolName))
+	builder.WriteString("Matching tools are activated for the rest of this conversation and can be called directly from your next step.\n")
+	return builder.String()
+}
+
 // buildMCPSection builds the MCP tools section based on injection mode.
 func (b *SystemPromptBuilder) buildMCPSection() string {
 	if b.mcpManager == nil {
@@ -250,10 +306,10 @@ func (b *SystemPromptBuilder) buildMCPSection() string {
 	}
 
 	servers := b.mcpManager.ListServers()
-	// Only include connected servers with tools
+	// Only include connected servers with tools or resources
 	connectedServers := make([]client.ServerStatus, 0)
 	for _, s := range servers {
-		if s.State.String() == "connected" && s.ToolCount > 0 {
+		if s.State.String() == "connected" && (s.ToolCount > 0 || s.ResourceCount > 0) {
 			connectedServers = append(connectedServers, s)
 		}
 	}
@@ -313,6 +369,7 @@ func (b *SystemPromptBuilder) buildMCPFullSection(builder *strings.Builder, serv
 					}
 				}
 			}
+			b.writeMCPResources(builder, mcpClient)
 		}
 		builder.WriteString("\n")
 	}
@@ -335,8 +392,48 @@ func (b *SystemPromptBuilder) buildMCPSummarySection(builder *strings.Builder, s
 			for _, t := range mcpTools {
 				toolNames = append(toolNames, t.Name)
 			}
-			builder.WriteString(fmt.Sprintf("- `%s`: %d tools (%s)\n", s.Name, len(mcpTools), strings.Join(toolNames, ", ")))
+			builder.WriteString(fmt.Sprintf("- `%s`: %d tools (%s)", s.Name, len(mcpTools), strings.Join(toolNames, ", ")))
+			if n := len(mcpClient.Resources()); n > 0 {
+				builder.WriteString(fmt.Sprintf(", %d resources", n))
+			}
+			builder.WriteString("\n")
 		}
 	}
 	builder.WriteString("\nUse `mcp_call` with server, tool, and arguments. Use `mcp_list` if you need parameter details.\n")
+	builder.WriteString("Use `mcp_read_resource` with server and uri to read a resource.\n")
+}
+
+// maxPromptResources caps the resources listed per server in the system prompt.
+const maxPromptResources = 20
+
+// writeMCPResources lists a server's resources and resource templates.
+func (b *SystemPromptBuilder) writeMCPResources(builder *strings.Builder, mcpClient *client.Client) {
+	resources := mcpClient.Resources()
+	templates := mcpClient.ResourceTemplates()
+	if len(resources) == 0 && len(templates) == 0 {
+		return
+	}
+
+	builder.WriteString("\n**Resources** (read with `mcp_read_resource`):\n")
+	for i, r := range resources {
+		if i == maxPromptResources {
+			builder.WriteString(fmt.Sprintf("- ... and %d more (see `mcp_list`)\n", len(resources)-maxPromptResources))
+			break
+		}
+		builder.WriteString(fmt.Sprintf("- `%s`", r.URI))
+		if r.Name != "" && r.Name != r.URI {
+			builder.WriteString(fmt.Sprintf(" (%s)", r.Name))
+		}
+		if r.Description != "" {
+			builder.WriteString(fmt.Sprintf(": %s", r.Description))
+		}
+		builder.WriteString("\n")
+	}
+	for _, t := range templates {
+		builder.WriteString(fmt.Sprintf("- template `%s`", t.URITemplate))
+		if t.Description != "" {
+			builder.WriteString(fmt.Sprintf(": %s", t.Description))
+		}
+		builder.WriteString("\n")
+	}
 }
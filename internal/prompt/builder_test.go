@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"mote/internal/tools"
+	"mote/internal/tools/retrieval"
 )
 
 type mockTool struct {
@@ -160,6 +161,27 @@ func TestSystemPromptBuilder_MCPInjectionMode(t *testing.T) {
 	}
 }
 
+func TestSystemPromptBuilder_ToolCatalog(t *testing.T) {
+	registry := tools.NewRegistry()
+	_ = registry.Register(&mockTool{name: "read_file", description: "Read a file from disk"})
+	_ = registry.Register(&mockTool{name: "cron_add", description: "Schedule a recurring job"})
+	_ = registry.Register(&mockTool{name: "http", description: "Send an HTTP request"})
+
+	b := NewSystemPromptBuilder(PromptConfig{AgentName: "TestAgent"}, registry).
+		WithToolCatalog(retrieval.NewCatalog(registry, nil, []string{"read_file"}, 0))
+	result := b.BuildStatic()
+
+	if !strings.Contains(result, "### read_file") {
+		t.Error("expected core tool to be listed")
+	}
+	if strings.Contains(result, "### cron_add") || strings.Contains(result, "### http") {
+		t.Error("deferred tools should not be listed")
+	}
+	if !strings.Contains(result, "2 more tools are available on demand (2 built-in)") || !strings.Contains(result, "`search_tools`") {
+		t.Errorf("expected tool discovery section, got:\n%s", result)
+	}
+}
+
 func TestMCPInjectionMode_String(t *testing.T) {
 	tests := []struct {
 		mode     MCPInjectionMode
@@ -8,6 +8,9 @@ type Message struct {
 	Content    string     `json:"content"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
+	// IsError marks a tool result as an error. It is recorded with the
+	// message and never sent to a model.
+	IsError bool `json:"-"`
 }
 
 // ToolCall represents a tool/function call.
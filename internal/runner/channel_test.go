@@ -330,3 +330,31 @@ func waitFor(t *testing.T, condition func() bool, timeout time.Duration, msg str
 	}
 	t.Fatalf("timeout waiting for: %s", msg)
 }
+
+func TestApprovalChatSender(t *testing.T) {
+	notifiers := []config.ApprovalNotifierConfig{
+		{Type: "push", URL: "https://ntfy.sh/approvals"},
+		{Type: "channel", Channel: "telegram", ChatID: "-100", Approvers: []string{"42"}},
+		{Type: "channel", Channel: "slack", ChatID: "C01"},
+	}
+	tests := []struct {
+		name         string
+		channelType  channel.ChannelType
+		chatID       string
+		senderID     string
+		wantChat     bool
+		wantApprover bool
+	}{
+		{"approver", "telegram", "-100", "42", true, true},
+		{"other member", "telegram", "-100", "7", true, false},
+		{"empty sender", "telegram", "-100", "", true, false},
+		{"no approvers configured", "slack", "C01", "U1", true, false},
+		{"not an approval chat", "telegram", "-200", "42", false, false},
+	}
+	for _, tt := range tests {
+		isChat, isApprover := approvalChatSender(notifiers, tt.channelType, tt.chatID, tt.senderID)
+		if isChat != tt.wantChat || isApprover != tt.wantApprover {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", tt.name, isChat, isApprover, tt.wantChat, tt.wantApprover)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"mote/internal/scheduler"
 	"mote/internal/skills"
 	"mote/internal/tools"
+	"mote/internal/usage"
 )
 
 // ParentEventSink is a callback to transparently forward sub-agent events to the parent.
@@ -95,6 +96,10 @@ type SubRunnerFactory struct {
 	// Optional workspace resolver: returns the workspace path for a session.
 	// Set via SetWorkspaceResolver after creation.
 	workspaceResolver func(sessionID string) string
+
+	// Optional usage meter: records sub-agent usage and enforces budgets.
+	// Set via SetUsageMeter after creation.
+	usageMeter *usage.Meter
 }
 
 // SubRunnerFactoryOptions contains all dependencies needed to construct
@@ -172,6 +177,20 @@ func (f *SubRunnerFactory) SetWorkspaceResolver(resolver func(sessionID string)
 	f.workspaceResolver = resolver
 }
 
+// SetUsageMeter enables usage recording and budget enforcement for sub-agents.
+func (f *SubRunnerFactory) SetUsageMeter(m *usage.Meter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.usageMeter = m
+}
+
+// getUsageMeter returns the usage meter (thread-safe, may be nil).
+func (f *SubRunnerFactory) getUsageMeter() *usage.Meter {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.usageMeter
+}
+
 // RunDelegate executes a sub-agent with the given configuration and prompt.
 // It returns the aggregated text result, token usage, and any error.
 // This is a convenience wrapper around RunDelegateWithEvents with no event sink.
@@ -200,9 +219,24 @@ func (f *SubRunnerFactory) RunDelegateWithEvents(
 		time.Now().UnixMilli())
 
 	// 2. Record start (optional tracking)
+	model := agentCfg.Model
+	if model == "" {
+		model = f.getDefaultModel()
+	}
+	meter := f.getUsageMeter()
+	decision := meter.ForAgent(delegateCtx.AgentName, model).Admit(sessionID)
+	switch decision.Action {
+	case usage.ActionStop:
+		return "", types.Usage{}, fmt.Errorf("%w: %s", orchestrator.ErrBudgetExceeded, decision.Message)
+	case usage.ActionDowngrade:
+		slog.Info("delegate: usage budget downgrading model",
+			"agent", delegateCtx.AgentName, "budget", decision.Budget, "from", model, "to", decision.Model)
+		model = decision.Model
+	}
 	var invocationID string
 	if f.tracker != nil {
 		invocationID = GenerateInvocationID(delegateCtx.ParentSessionID, delegateCtx.AgentName)
+		callID, _ := tools.ToolCallIDFromContext(ctx)
 		_ = f.tracker.StartDelegation(DelegationRecord{
 			ID:              invocationID,
 			ParentSessionID: delegateCtx.ParentSessionID,
@@ -212,6 +246,8 @@ func (f *SubRunnerFactory) RunDelegateWithEvents(
 			Chain:           ChainToJSON(delegateCtx.Chain),
 			Prompt:          userPrompt,
 			StartedAt:       time.Now(),
+			Model:           model,
+			ToolCallID:      callID,
 		})
 	}
 
@@ -228,13 +264,9 @@ func (f *SubRunnerFactory) RunDelegateWithEvents(
 	}
 
 	// 4. Resolve provider
-	model := agentCfg.Model
-	if model == "" {
-		model = f.getDefaultModel()
-	}
 	prov, _, err := f.multiPool.GetProvider(model)
 	if err != nil {
-		f.completeTracking(invocationID, "failed", 0, 0, err)
+		f.completeTracking(invocationID, "failed", 0, types.Usage{}, err)
 		return "", types.Usage{}, fmt.Errorf("get provider for model %s: %w", model, err)
 	}
 
@@ -314,6 +346,7 @@ func (f *SubRunnerFactory) RunDelegateWithEvents(
 		MCPManager:     f.mcpManager,
 		ContextManager: f.contextManager,
 		ToolExecutor:   f.makeToolExecutor(subRegistry, sessionID),
+		UsageMeter:     meter.ForAgent(delegateCtx.AgentName, model),
 	})
 
 	orch := orchBuilder.Build(prov)
@@ -349,7 +382,7 @@ func (f *SubRunnerFactory) RunDelegateWithEvents(
 		CachedSession: cached,
 	})
 	if err != nil {
-		f.completeTracking(invocationID, "failed", 0, 0, err)
+		f.completeTracking(invocationID, "failed", 0, types.Usage{}, err)
 		return "", types.Usage{}, fmt.Errorf("sub-agent run: %w", err)
 	}
 
@@ -403,13 +436,13 @@ func (f *SubRunnerFactory) RunDelegateWithEvents(
 				errMsg = event.Error.Error()
 			}
 			friendly := friendlyErrorMessage(delegateCtx.AgentName, fmt.Errorf("%s", errMsg))
-			f.completeTracking(invocationID, "failed", content.Len(), totalUsage.TotalTokens, fmt.Errorf("%s", errMsg))
+			f.completeTracking(invocationID, "failed", content.Len(), totalUsage, fmt.Errorf("%s", errMsg))
 			return content.String(), totalUsage,
 				fmt.Errorf("sub-agent error: %s", friendly)
 		}
 	}
 
-	f.completeTracking(invocationID, "completed", content.Len(), totalUsage.TotalTokens, nil)
+	f.completeTracking(invocationID, "completed", content.Len(), totalUsage, nil)
 	return content.String(), totalUsage, nil
 }
 
@@ -592,10 +625,20 @@ func (f *SubRunnerFactory) RunPDAWithEvents(
 		}, true
 	}
 
+	// Resolve model name for tracking and progress events
+	pdaModel := agentCfg.Model
+	if pdaModel == "" {
+		pdaModel = f.getDefaultModel()
+	}
+
+	// 2.1. Record start (optional tracking). PDA runs inside the parent
+	// session, so the child session is the parent session itself.
+	invocationID, steps := f.startPDATracking(ctx, sessionID, delegateCtx, userPrompt, pdaModel)
+
 	// 3. Create PDA engine
 	maxStackDepth := config.GetConfig().Delegate.GetMaxStackDepth()
 	engine := cfg.NewPDAEngine(cfg.PDAEngineOptions{
-		RunPromptWithContext: runPromptWithContextFn,
+		RunPromptWithContext: steps.wrap(runPromptWithContextFn),
 		AgentProvider:        agentProvider,
 		MaxStackDepth:        maxStackDepth,
 	})
@@ -614,13 +657,7 @@ func (f *SubRunnerFactory) RunPDAWithEvents(
 		savedCheckpoint = nil
 	}
 
-	// 3.3. Set PDA progress event callbacks
-	// Resolve model name for progress events
-	pdaModel := agentCfg.Model
-	if pdaModel == "" {
-		pdaModel = f.getDefaultModel()
-	}
-
+	// 3.3. Set PDA step callbacks (trace spans and progress events)
 	// Helper to build parent step info from engine's parent frames
 	buildParentSteps := func() []types.ParentStepInfo {
 		parentFrames := engine.ParentFrames()
@@ -644,8 +681,9 @@ func (f *SubRunnerFactory) RunPDAWithEvents(
 		return ps
 	}
 
-	if parentSink != nil {
-		engine.OnStepStart = func(frame cfg.StackFrame, step cfg.Step) {
+	engine.OnStepStart = func(frame cfg.StackFrame, step cfg.Step) {
+		steps.start(frame, step)
+		if parentSink != nil {
 			parentSink(types.Event{
 				Type: types.EventTypePDAProgress,
 				PDAProgress: &types.PDAProgressEvent{
@@ -661,7 +699,10 @@ func (f *SubRunnerFactory) RunPDAWithEvents(
 				},
 			})
 		}
-		engine.OnStepComplete = func(frame cfg.StackFrame, step cfg.Step, result string) {
+	}
+	engine.OnStepComplete = func(frame cfg.StackFrame, step cfg.Step, result string) {
+		steps.finish(nil)
+		if parentSink != nil {
 			parentSink(types.Event{
 				Type: types.EventTypePDAProgress,
 				PDAProgress: &types.PDAProgressEvent{
@@ -713,6 +754,7 @@ func (f *SubRunnerFactory) RunPDAWithEvents(
 
 	result, usage, err := engine.Execute(ctx, delegateInfo, agentCFG, userPrompt, savedCheckpoint)
 	if err != nil {
+		steps.finish(err)
 		// Persist an interruption message so the session's conversation history contains
 		// PDA context. Without this, when the user returns to the session, the LLM has
 		// no memory of the PDA execution and cannot meaningfully help resume it.
@@ -736,6 +778,11 @@ func (f *SubRunnerFactory) RunPDAWithEvents(
 			slog.Warn("delegate: failed to persist PDA interrupt message",
 				"sessionID", sessionID, "error", addErr)
 		}
+		f.completePDATracking(invocationID, "failed", pdaTranscript.Len(), types.Usage{
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.TotalTokens,
+		}, err, executedSteps, engine.CurrentStackDepth())
 		return "", types.Usage{}, fmt.Errorf("PDA execution failed: %w", err)
 	}
 
@@ -760,15 +807,59 @@ func (f *SubRunnerFactory) RunPDAWithEvents(
 		"steps", len(engine.ExecutedSteps()),
 		"tokens", usage.TotalTokens)
 
-	return result, types.Usage{
+	totalUsage := types.Usage{
 		PromptTokens:     usage.PromptTokens,
 		CompletionTokens: usage.CompletionTokens,
 		TotalTokens:      usage.TotalTokens,
-	}, nil
+	}
+	f.completePDATracking(invocationID, "completed", len(result), totalUsage, nil,
+		engine.ExecutedSteps(), engine.CurrentStackDepth())
+	return result, totalUsage, nil
+}
+
+// startPDATracking records the start of a structured (PDA) invocation, which
+// runs inside sessionID itself. It returns the invocation ID ("" when tracking
+// is disabled) and a recorder for the invocation's steps.
+func (f *SubRunnerFactory) startPDATracking(ctx context.Context, sessionID string, delegateCtx *DelegateContext, userPrompt, model string) (string, *pdaStepRecorder) {
+	if f.tracker == nil {
+		return "", nil
+	}
+	callID, _ := tools.ToolCallIDFromContext(ctx)
+	invocationID := GenerateInvocationID(sessionID, delegateCtx.AgentName)
+	_ = f.tracker.StartDelegation(DelegationRecord{
+		ID:              invocationID,
+		ParentSessionID: sessionID,
+		ChildSessionID:  sessionID,
+		AgentName:       delegateCtx.AgentName,
+		Depth:           delegateCtx.Depth,
+		Chain:           ChainToJSON(delegateCtx.Chain),
+		Prompt:          userPrompt,
+		StartedAt:       time.Now(),
+		Mode:            "structured",
+		Model:           model,
+		ToolCallID:      callID,
+	})
+	return invocationID, newPDAStepRecorder(f.tracker, invocationID, sessionID, f.agentModel)
+}
+
+// agentModel returns the model configured for an agent, or the default model.
+func (f *SubRunnerFactory) agentModel(agentName string) string {
+	if appCfg := config.GetConfig(); appCfg != nil {
+		if ac, ok := appCfg.Agents[agentName]; ok && ac.Model != "" {
+			return ac.Model
+		}
+	}
+	return f.getDefaultModel()
 }
 
 // completeTracking records the completion of a delegation invocation.
-func (f *SubRunnerFactory) completeTracking(invocationID, status string, resultLen, tokens int, err error) {
+func (f *SubRunnerFactory) completeTracking(invocationID, status string, resultLen int, usage types.Usage, err error) {
+	f.completePDATracking(invocationID, status, resultLen, usage, err, nil, 0)
+}
+
+// completePDATracking records the completion of a delegation invocation
+// together with the PDA steps it executed.
+func (f *SubRunnerFactory) completePDATracking(invocationID, status string, resultLen int, usage types.Usage, err error, executedSteps []string, stackDepth int) {
 	if f.tracker == nil || invocationID == "" {
 		return
 	}
@@ -777,7 +868,16 @@ func (f *SubRunnerFactory) completeTracking(invocationID, status string, resultL
 		s := err.Error()
 		errMsg = &s
 	}
-	_ = f.tracker.CompleteDelegation(invocationID, status, resultLen, tokens, errMsg)
+	_ = f.tracker.FinishDelegation(invocationID, DelegationCompletion{
+		Status:           status,
+		ResultLength:     resultLen,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		ErrorMessage:     errMsg,
+		ExecutedSteps:    executedSteps,
+		PDAStackDepth:    stackDepth,
+	})
 }
 
 // buildFilteredRegistry creates a filtered clone of the parent registry.
@@ -837,6 +937,7 @@ func (f *SubRunnerFactory) makeToolExecutor(
 						Role:       provider.RoleTool,
 						Content:    fmt.Sprintf("failed to parse arguments: %v", err),
 						ToolCallID: tc.ID,
+						IsError:    true,
 					})
 					errorCount++
 					continue
@@ -844,12 +945,13 @@ func (f *SubRunnerFactory) makeToolExecutor(
 			}
 
 			// Execute tool
-			result, err := subRegistry.Execute(ctx, toolName, argsMap)
+			result, err := subRegistry.Execute(tools.WithToolCallID(ctx, tc.ID), toolName, argsMap)
 			if err != nil {
 				results = append(results, provider.Message{
 					Role:       provider.RoleTool,
 					Content:    fmt.Sprintf("tool error: %v", err),
 					ToolCallID: tc.ID,
+					IsError:    true,
 				})
 				errorCount++
 				continue
@@ -863,6 +965,7 @@ func (f *SubRunnerFactory) makeToolExecutor(
 				Role:       provider.RoleTool,
 				Content:    result.Content,
 				ToolCallID: tc.ID,
+				IsError:    result.IsError,
 			})
 		}
 
@@ -883,11 +986,21 @@ func (f *SubRunnerFactory) BuildOrchestratorForAgent(
 ) (orchestrator.Orchestrator, provider.Provider, *scheduler.CachedSession, error) {
 	isRoute := len(routeOnly) > 0 && routeOnly[0]
 	isPDAManaged := len(routeOnly) > 1 && routeOnly[1]
-	// 1. Resolve provider
+	// 1. Resolve provider (after applying usage budgets)
 	model := agentCfg.Model
 	if model == "" {
 		model = f.getDefaultModel()
 	}
+	meter := f.getUsageMeter()
+	decision := meter.ForAgent(delegateCtx.AgentName, model).Admit(sessionID)
+	switch decision.Action {
+	case usage.ActionStop:
+		return nil, nil, nil, fmt.Errorf("%w: %s", orchestrator.ErrBudgetExceeded, decision.Message)
+	case usage.ActionDowngrade:
+		slog.Info("delegate: usage budget downgrading model",
+			"agent", delegateCtx.AgentName, "budget", decision.Budget, "from", model, "to", decision.Model)
+		model = decision.Model
+	}
 	prov, _, err := f.multiPool.GetProvider(model)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("get provider for model %s: %w", model, err)
@@ -967,6 +1080,7 @@ func (f *SubRunnerFactory) BuildOrchestratorForAgent(
 		MCPManager:     f.mcpManager,
 		ContextManager: f.contextManager,
 		ToolExecutor:   f.makeToolExecutor(subRegistry, sessionID),
+		UsageMeter:     meter.ForAgent(delegateCtx.AgentName, model),
 	})
 	orch := orchBuilder.Build(prov)
 
@@ -400,13 +400,22 @@ func (t *DelegateTool) executeStructured(
 		}, true
 	}
 
+	// 2.1. Record start (optional tracking), linked to this delegate tool call
+	invocationID, steps := t.factory.startPDATracking(ctx, sessionID, childDC, prompt, t.factory.agentModel(agentName))
+
 	// 3. Create PDA engine
 	maxStackDepth := config.GetConfig().Delegate.GetMaxStackDepth()
 	engine := cfg.NewPDAEngine(cfg.PDAEngineOptions{
-		RunPromptWithContext: runPromptWithContextFn,
+		RunPromptWithContext: steps.wrap(runPromptWithContextFn),
 		AgentProvider:        agentProvider,
 		MaxStackDepth:        maxStackDepth,
 	})
+	engine.OnStepStart = func(frame cfg.StackFrame, step cfg.Step) {
+		steps.start(frame, step)
+	}
+	engine.OnStepComplete = func(frame cfg.StackFrame, step cfg.Step, result string) {
+		steps.finish(nil)
+	}
 
 	// 3.1. Set checkpoint persistence callback
 	store := t.factory.sessions.DB()
@@ -450,6 +459,9 @@ func (t *DelegateTool) executeStructured(
 	duration := time.Since(startTime)
 
 	if err != nil {
+		steps.finish(err)
+		t.factory.completePDATracking(invocationID, "failed", pdaTranscript.Len(), typesUsageFromCfg(usage),
+			err, engine.ExecutedSteps(), engine.CurrentStackDepth())
 		// Persist interruption message to session history.
 		// Include the accumulated transcript (with agent markers) so the user
 		// can see what sub-agents produced before the interruption.
@@ -485,6 +497,8 @@ func (t *DelegateTool) executeStructured(
 		slog.Warn("delegate: failed to clear PDA checkpoint after success",
 			"sessionID", sessionID, "error", clearErr)
 	}
+	t.factory.completePDATracking(invocationID, "completed", len(result), typesUsageFromCfg(usage),
+		nil, engine.ExecutedSteps(), engine.CurrentStackDepth())
 
 	// Per-step messages are already saved individually in runPromptWithContextFn.
 	// Just log the completion. No additional transcript save needed.
@@ -606,3 +620,12 @@ func cfgUsageFromTypes(u types.Usage) cfg.Usage {
 		TotalTokens:      u.TotalTokens,
 	}
 }
+
+// typesUsageFromCfg converts cfg.Usage to types.Usage.
+func typesUsageFromCfg(u cfg.Usage) types.Usage {
+	return types.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+}
@@ -0,0 +1,460 @@
+package delegate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"mote/internal/provider"
+	"mote/internal/runner/delegate/cfg"
+	"mote/internal/storage"
+)
+
+// Trace node kinds.
+const (
+	TraceKindSession = "session"
+	TraceKindTurn    = "turn"
+	TraceKindAgent   = "agent"
+	TraceKindPDAStep = "pda_step"
+	TraceKindTool    = "tool"
+)
+
+// maxTraceArgLength caps tool call arguments copied into trace attributes.
+const maxTraceArgLength = 512
+
+// TraceNode is a single span in the reconstructed execution tree of a session.
+// Token fields describe the node itself; SubtreeTokens adds up the node and
+// all of its descendants so expensive branches stand out.
+type TraceNode struct {
+	ID               string            `json:"id"`
+	Kind             string            `json:"kind"`
+	Name             string            `json:"name"`
+	SessionID        string            `json:"session_id,omitempty"`
+	Model            string            `json:"model,omitempty"`
+	Status           string            `json:"status"`
+	StartedAt        time.Time         `json:"started_at"`
+	EndedAt          *time.Time        `json:"ended_at,omitempty"`
+	DurationMs       int64             `json:"duration_ms"`
+	PromptTokens     int               `json:"prompt_tokens"`
+	CompletionTokens int               `json:"completion_tokens"`
+	TotalTokens      int               `json:"total_tokens"`
+	SubtreeTokens    int               `json:"subtree_tokens"`
+	Error            string            `json:"error,omitempty"`
+	Attributes       map[string]string `json:"attributes,omitempty"`
+	Children         []*TraceNode      `json:"children,omitempty"`
+}
+
+// TraceSpan is a timed unit of work recorded for the session trace: a turn of
+// the main agent (ParentID empty) or a PDA step of the delegation ParentID.
+type TraceSpan struct {
+	ID               string
+	SessionID        string
+	ParentID         string
+	Kind             string
+	Name             string
+	Model            string
+	Status           string
+	StartedAt        time.Time
+	EndedAt          time.Time
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	ErrorMessage     string
+}
+
+// RecordSpan stores a finished span.
+func (t *DelegationTracker) RecordSpan(span TraceSpan) error {
+	if span.TotalTokens == 0 {
+		span.TotalTokens = span.PromptTokens + span.CompletionTokens
+	}
+	var errMsg *string
+	if span.ErrorMessage != "" {
+		errMsg = &span.ErrorMessage
+	}
+	_, err := t.db.Exec(`
+		INSERT OR REPLACE INTO trace_spans
+		(id, session_id, parent_id, kind, name, model, status, started_at, ended_at,
+		 prompt_tokens, completion_tokens, total_tokens, error_message)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		span.ID, span.SessionID, span.ParentID, span.Kind, span.Name, span.Model, span.Status,
+		span.StartedAt, span.EndedAt, span.PromptTokens, span.CompletionTokens, span.TotalTokens, errMsg)
+	return err
+}
+
+// pdaStepRecorder records a trace span for every PDA step of a delegation.
+// Steps that push a frame (agent_ref, route) never complete themselves, so
+// only leaf steps get a span; a step's tokens are those of the model calls
+// made while it runs. A nil recorder records nothing.
+type pdaStepRecorder struct {
+	tracker      *DelegationTracker
+	invocationID string
+	sessionID    string
+	modelFor     func(agent string) string
+
+	mu      sync.Mutex
+	count   int
+	current *TraceSpan
+}
+
+// newPDAStepRecorder returns a recorder for the given delegation, or nil if
+// tracking is disabled.
+func newPDAStepRecorder(tracker *DelegationTracker, invocationID, sessionID string, modelFor func(string) string) *pdaStepRecorder {
+	if tracker == nil || invocationID == "" {
+		return nil
+	}
+	return &pdaStepRecorder{tracker: tracker, invocationID: invocationID, sessionID: sessionID, modelFor: modelFor}
+}
+
+// start opens the span of a step, dropping the span of a step that pushed a frame.
+func (r *pdaStepRecorder) start(frame cfg.StackFrame, step cfg.Step) {
+	if r == nil {
+		return
+	}
+	label := step.Label
+	if label == "" {
+		label = fmt.Sprintf("%s:%d", frame.AgentName, frame.StepIndex)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = &TraceSpan{
+		ID:        fmt.Sprintf("%s#step%d", r.invocationID, r.count),
+		SessionID: r.sessionID,
+		ParentID:  r.invocationID,
+		Kind:      TraceKindPDAStep,
+		Name:      label,
+		Model:     r.modelFor(frame.AgentName),
+		StartedAt: time.Now(),
+	}
+	r.count++
+}
+
+// wrap adds the usage of every model call made through fn to the running step.
+func (r *pdaStepRecorder) wrap(fn cfg.RunPromptWithContextFunc) cfg.RunPromptWithContextFunc {
+	if r == nil {
+		return fn
+	}
+	return func(ctx context.Context, agentName string, messages []provider.Message, userInput string) (string, cfg.Usage, []provider.Message, error) {
+		result, usage, msgs, err := fn(ctx, agentName, messages, userInput)
+		r.mu.Lock()
+		if r.current != nil {
+			r.current.Model = r.modelFor(agentName)
+			r.current.PromptTokens += usage.PromptTokens
+			r.current.CompletionTokens += usage.CompletionTokens
+			r.current.TotalTokens += usage.TotalTokens
+		}
+		r.mu.Unlock()
+		return result, usage, msgs, err
+	}
+}
+
+// finish closes and stores the span of the running step, if any.
+func (r *pdaStepRecorder) finish(err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	span := r.current
+	r.current = nil
+	r.mu.Unlock()
+	if span == nil {
+		return
+	}
+	span.EndedAt = time.Now()
+	span.Status = "completed"
+	if err != nil {
+		span.Status = "failed"
+		span.ErrorMessage = err.Error()
+	}
+	if recErr := r.tracker.RecordSpan(*span); recErr != nil {
+		slog.Warn("delegate: failed to record PDA step span",
+			"invocationID", r.invocationID, "step", span.Name, "error", recErr)
+	}
+}
+
+// loadSpans returns the spans of a session with the given parent, oldest first.
+func (t *DelegationTracker) loadSpans(sessionID, parentID string) ([]*TraceNode, error) {
+	rows, err := t.db.Query(`
+		SELECT id, kind, name, model, status, started_at, ended_at,
+		       prompt_tokens, completion_tokens, total_tokens, error_message
+		FROM trace_spans
+		WHERE session_id = ? AND parent_id = ?
+		ORDER BY started_at ASC`, sessionID, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("load spans: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*TraceNode
+	for rows.Next() {
+		n := &TraceNode{SessionID: sessionID}
+		var endedAt sql.NullTime
+		var errMsg sql.NullString
+		if err := rows.Scan(&n.ID, &n.Kind, &n.Name, &n.Model, &n.Status, &n.StartedAt, &endedAt,
+			&n.PromptTokens, &n.CompletionTokens, &n.TotalTokens, &errMsg); err != nil {
+			return nil, err
+		}
+		if endedAt.Valid {
+			n.setEnd(endedAt.Time)
+		}
+		n.Error = errMsg.String
+		nodes = append(nodes, n)
+	}
+	return nodes, rows.Err()
+}
+
+// BuildTrace reconstructs the execution tree of a session from its messages,
+// delegation records and spans: main agent turns, tool calls, sub-agent
+// invocations (recursively into their child sessions) and PDA steps.
+// Returns nil if the session does not exist.
+func (t *DelegationTracker) BuildTrace(sessionID string) (*TraceNode, error) {
+	root := &TraceNode{
+		ID:        sessionID,
+		Kind:      TraceKindSession,
+		Name:      sessionID,
+		SessionID: sessionID,
+		Status:    "completed",
+	}
+	var updatedAt time.Time
+	err := t.db.QueryRow(
+		"SELECT created_at, updated_at, COALESCE(model, '') FROM sessions WHERE id = ?",
+		sessionID,
+	).Scan(&root.StartedAt, &updatedAt, &root.Model)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load session: %w", err)
+	}
+
+	visited := map[string]bool{sessionID: true}
+	children, lastActivity, err := t.buildSessionChildren(sessionID, visited)
+	if err != nil {
+		return nil, err
+	}
+	root.Children = children
+
+	end := updatedAt
+	if lastActivity.After(end) {
+		end = lastActivity
+	}
+	root.setEnd(end)
+	for _, c := range children {
+		if c.Status == "running" {
+			root.Status = "running"
+		}
+	}
+	root.rollup()
+	return root, nil
+}
+
+// buildSessionChildren returns the turn, tool call and delegation nodes of a
+// session, ordered by start time, along with the time of the last recorded activity.
+func (t *DelegationTracker) buildSessionChildren(sessionID string, visited map[string]bool) ([]*TraceNode, time.Time, error) {
+	toolNodes, lastActivity, err := t.loadToolNodes(sessionID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	turns, err := t.loadSpans(sessionID, "")
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	for _, n := range turns {
+		if n.EndedAt != nil && n.EndedAt.After(lastActivity) {
+			lastActivity = *n.EndedAt
+		}
+	}
+
+	records, err := t.GetByParentSession(sessionID)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("load delegations: %w", err)
+	}
+
+	byCallID := make(map[string]*TraceNode, len(toolNodes))
+	for _, n := range toolNodes {
+		if n.ID != "" {
+			byCallID[n.ID] = n
+		}
+	}
+
+	var children []*TraceNode
+	children = append(children, turns...)
+	children = append(children, toolNodes...)
+	for _, rec := range records {
+		steps, err := t.loadSpans(rec.ChildSessionID, rec.ID)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		node := agentNode(rec, steps)
+		if node.EndedAt != nil && node.EndedAt.After(lastActivity) {
+			lastActivity = *node.EndedAt
+		}
+
+		// Follow the child session unless it is shared with the parent
+		// (PDA invocations run inside the parent session).
+		if rec.ChildSessionID != "" && !visited[rec.ChildSessionID] {
+			visited[rec.ChildSessionID] = true
+			sub, _, err := t.buildSessionChildren(rec.ChildSessionID, visited)
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			node.Children = append(node.Children, sub...)
+		}
+
+		if parent := byCallID[rec.ToolCallID]; rec.ToolCallID != "" && parent != nil {
+			parent.Children = append(parent.Children, node)
+		} else {
+			children = append(children, node)
+		}
+	}
+
+	sortTraceNodes(children)
+	for _, n := range toolNodes {
+		sortTraceNodes(n.Children)
+	}
+	return children, lastActivity, nil
+}
+
+// loadToolNodes builds one node per tool call found in the session's
+// assistant messages. A tool call ends when its tool result message is stored.
+func (t *DelegationTracker) loadToolNodes(sessionID string) ([]*TraceNode, time.Time, error) {
+	rows, err := t.db.Query(
+		"SELECT role, content, tool_calls, tool_call_id, is_error, created_at FROM messages WHERE session_id = ? ORDER BY created_at ASC",
+		sessionID,
+	)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("load messages: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*TraceNode
+	byCallID := make(map[string]*TraceNode)
+	var lastActivity time.Time
+	for rows.Next() {
+		var role string
+		var content, toolCallsJSON, toolCallID sql.NullString
+		var isError bool
+		var createdAt time.Time
+		if err := rows.Scan(&role, &content, &toolCallsJSON, &toolCallID, &isError, &createdAt); err != nil {
+			return nil, time.Time{}, err
+		}
+		if createdAt.After(lastActivity) {
+			lastActivity = createdAt
+		}
+
+		if toolCallsJSON.Valid && toolCallsJSON.String != "" {
+			var calls []storage.ToolCall
+			if err := json.Unmarshal([]byte(toolCallsJSON.String), &calls); err != nil {
+				continue
+			}
+			for _, tc := range calls {
+				node := &TraceNode{
+					ID:        tc.ID,
+					Kind:      TraceKindTool,
+					Name:      tc.GetName(),
+					SessionID: sessionID,
+					Status:    "running",
+					StartedAt: createdAt,
+				}
+				if args := tc.GetArguments(); args != "" {
+					node.Attributes = map[string]string{"arguments": truncateForTrace(args)}
+				}
+				nodes = append(nodes, node)
+				if tc.ID != "" {
+					byCallID[tc.ID] = node
+				}
+			}
+		}
+
+		if role == "tool" && toolCallID.Valid {
+			if node, ok := byCallID[toolCallID.String]; ok {
+				node.Status = "completed"
+				node.setEnd(createdAt)
+				if isError {
+					node.Status = "failed"
+					node.Error = truncateForTrace(content.String)
+				}
+			}
+		}
+	}
+	return nodes, lastActivity, rows.Err()
+}
+
+// agentNode converts a delegation record into a trace node with its recorded
+// PDA step spans as children. The steps' tokens are subtracted from the node's
+// own tokens so that they are not counted twice in SubtreeTokens.
+func agentNode(rec DelegationRecord, steps []*TraceNode) *TraceNode {
+	node := &TraceNode{
+		ID:               rec.ID,
+		Kind:             TraceKindAgent,
+		Name:             rec.AgentName,
+		SessionID:        rec.ChildSessionID,
+		Model:            rec.Model,
+		Status:           rec.Status,
+		StartedAt:        rec.StartedAt,
+		PromptTokens:     rec.PromptTokens,
+		CompletionTokens: rec.CompletionTokens,
+		TotalTokens:      rec.TokensUsed,
+		Attributes: map[string]string{
+			"depth": fmt.Sprintf("%d", rec.Depth),
+			"chain": rec.Chain,
+			"mode":  rec.Mode,
+		},
+	}
+	if rec.CompletedAt != nil {
+		node.setEnd(*rec.CompletedAt)
+	}
+	if rec.ErrorMessage != nil {
+		node.Error = *rec.ErrorMessage
+	}
+	for _, step := range steps {
+		node.PromptTokens -= step.PromptTokens
+		node.CompletionTokens -= step.CompletionTokens
+		node.TotalTokens -= step.TotalTokens
+		node.Children = append(node.Children, step)
+	}
+	node.PromptTokens = max(node.PromptTokens, 0)
+	node.CompletionTokens = max(node.CompletionTokens, 0)
+	node.TotalTokens = max(node.TotalTokens, 0)
+	return node
+}
+
+func (n *TraceNode) setEnd(end time.Time) {
+	if end.Before(n.StartedAt) {
+		end = n.StartedAt
+	}
+	n.EndedAt = &end
+	n.DurationMs = end.Sub(n.StartedAt).Milliseconds()
+}
+
+// rollup computes SubtreeTokens for n and all of its descendants.
+func (n *TraceNode) rollup() int {
+	total := n.TotalTokens
+	for _, c := range n.Children {
+		total += c.rollup()
+	}
+	n.SubtreeTokens = total
+	return total
+}
+
+func sortTraceNodes(nodes []*TraceNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].StartedAt.Before(nodes[j].StartedAt)
+	})
+}
+
+func truncateForTrace(s string) string {
+	if len(s) <= maxTraceArgLength {
+		return s
+	}
+	cut := maxTraceArgLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
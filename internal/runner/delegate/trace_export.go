@@ -0,0 +1,187 @@
+package delegate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+)
+
+// OTLP/JSON span status codes and kinds (opentelemetry-proto trace.proto).
+const (
+	otlpStatusUnset = 0
+	otlpStatusOK    = 1
+	otlpStatusError = 2
+
+	otlpSpanKindInternal = 1
+	otlpSpanKindClient   = 3
+)
+
+// OTLPTraces is the OTLP/JSON ExportTraceServiceRequest envelope, suitable for
+// POSTing to an OpenTelemetry collector's /v1/traces endpoint or loading into
+// any viewer that accepts OTLP JSON.
+type OTLPTraces struct {
+	ResourceSpans []OTLPResourceSpans `json:"resourceSpans"`
+}
+
+// OTLPResourceSpans groups spans emitted by one resource.
+type OTLPResourceSpans struct {
+	Resource   OTLPResource     `json:"resource"`
+	ScopeSpans []OTLPScopeSpans `json:"scopeSpans"`
+}
+
+// OTLPResource describes the entity producing the spans.
+type OTLPResource struct {
+	Attributes []OTLPKeyValue `json:"attributes"`
+}
+
+// OTLPScopeSpans groups spans by instrumentation scope.
+type OTLPScopeSpans struct {
+	Scope OTLPScope  `json:"scope"`
+	Spans []OTLPSpan `json:"spans"`
+}
+
+// OTLPScope identifies the instrumentation scope.
+type OTLPScope struct {
+	Name string `json:"name"`
+}
+
+// OTLPSpan is a single span in OTLP/JSON encoding.
+type OTLPSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []OTLPKeyValue `json:"attributes,omitempty"`
+	Status            OTLPStatus     `json:"status"`
+}
+
+// OTLPStatus is the span status.
+type OTLPStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// OTLPKeyValue is an attribute key/value pair.
+type OTLPKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTLPAnyValue `json:"value"`
+}
+
+// OTLPAnyValue holds a string or integer attribute value.
+// OTLP/JSON encodes 64-bit integers as strings.
+type OTLPAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+// ExportOTLP converts a trace tree into OTLP/JSON. Trace and span IDs are
+// derived deterministically from the session and node IDs so repeated exports
+// of the same session produce the same identifiers.
+func ExportOTLP(root *TraceNode) *OTLPTraces {
+	out := &OTLPTraces{ResourceSpans: []OTLPResourceSpans{}}
+	if root == nil {
+		return out
+	}
+
+	traceID := hashID(root.SessionID, 16)
+	var spans []OTLPSpan
+	var walk func(n *TraceNode, parentSpanID string)
+	walk = func(n *TraceNode, parentSpanID string) {
+		spanID := hashID(n.Kind+":"+n.ID, 8)
+		spans = append(spans, toOTLPSpan(n, traceID, spanID, parentSpanID))
+		for _, c := range n.Children {
+			walk(c, spanID)
+		}
+	}
+	walk(root, "")
+
+	out.ResourceSpans = append(out.ResourceSpans, OTLPResourceSpans{
+		Resource: OTLPResource{Attributes: []OTLPKeyValue{
+			stringAttr("service.name", "mote"),
+		}},
+		ScopeSpans: []OTLPScopeSpans{{
+			Scope: OTLPScope{Name: "mote/delegate"},
+			Spans: spans,
+		}},
+	})
+	return out
+}
+
+func toOTLPSpan(n *TraceNode, traceID, spanID, parentSpanID string) OTLPSpan {
+	end := n.StartedAt
+	if n.EndedAt != nil {
+		end = *n.EndedAt
+	}
+
+	kind := otlpSpanKindInternal
+	if n.Kind == TraceKindTool {
+		kind = otlpSpanKindClient
+	}
+
+	attrs := []OTLPKeyValue{
+		stringAttr("mote.kind", n.Kind),
+		stringAttr("mote.status", n.Status),
+	}
+	if n.SessionID != "" {
+		attrs = append(attrs, stringAttr("mote.session_id", n.SessionID))
+	}
+	if n.Model != "" {
+		attrs = append(attrs, stringAttr("gen_ai.request.model", n.Model))
+	}
+	if n.TotalTokens > 0 {
+		attrs = append(attrs,
+			intAttr("gen_ai.usage.input_tokens", n.PromptTokens),
+			intAttr("gen_ai.usage.output_tokens", n.CompletionTokens),
+			intAttr("mote.tokens.total", n.TotalTokens),
+		)
+	}
+	attrs = append(attrs, intAttr("mote.tokens.subtree", n.SubtreeTokens))
+	keys := make([]string, 0, len(n.Attributes))
+	for k := range n.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, stringAttr("mote."+k, n.Attributes[k]))
+	}
+
+	status := OTLPStatus{Code: otlpStatusUnset}
+	switch n.Status {
+	case "completed":
+		status.Code = otlpStatusOK
+	case "failed", "timeout":
+		status.Code = otlpStatusError
+		status.Message = n.Error
+	}
+
+	return OTLPSpan{
+		TraceID:           traceID,
+		SpanID:            spanID,
+		ParentSpanID:      parentSpanID,
+		Name:              n.Kind + " " + n.Name,
+		Kind:              kind,
+		StartTimeUnixNano: strconv.FormatInt(n.StartedAt.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+		Attributes:        attrs,
+		Status:            status,
+	}
+}
+
+func stringAttr(key, value string) OTLPKeyValue {
+	return OTLPKeyValue{Key: key, Value: OTLPAnyValue{StringValue: &value}}
+}
+
+func intAttr(key string, value int) OTLPKeyValue {
+	s := strconv.Itoa(value)
+	return OTLPKeyValue{Key: key, Value: OTLPAnyValue{IntValue: &s}}
+}
+
+// hashID returns the first n bytes of sha256(s), hex encoded.
+func hashID(s string, n int) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:n])
+}
@@ -0,0 +1,86 @@
+package delegate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"mote/internal/provider"
+	"mote/internal/runner/delegate/cfg"
+)
+
+func TestPDAStepRecorder_EngineSteps(t *testing.T) {
+	db := setupCheckpointTestDB(t)
+	sess, err := db.CreateSession(nil)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	tracker := NewTracker(db.DB)
+	if err := tracker.StartDelegation(DelegationRecord{
+		ID:              "dlg_rec",
+		ParentSessionID: sess.ID,
+		ChildSessionID:  sess.ID,
+		AgentName:       "main",
+		Chain:           ChainToJSON([]string{"main"}),
+		StartedAt:       time.Now(),
+		Mode:            "structured",
+	}); err != nil {
+		t.Fatalf("StartDelegation: %v", err)
+	}
+
+	steps := []cfg.Step{
+		{Type: cfg.StepPrompt, Label: "step-0", Prompt: "do step 0"},
+		{Type: cfg.StepPrompt, Label: "step-1", Prompt: "do step 1"},
+	}
+	recorder := newPDAStepRecorder(tracker, "dlg_rec", sess.ID, func(string) string { return "gpt-4o" })
+
+	calls := 0
+	engine := cfg.NewPDAEngine(cfg.PDAEngineOptions{
+		RunPromptWithContext: recorder.wrap(func(ctx context.Context, agentName string, messages []provider.Message, userInput string) (string, cfg.Usage, []provider.Message, error) {
+			calls++
+			time.Sleep(5 * time.Millisecond)
+			if calls == 2 {
+				return "", cfg.Usage{PromptTokens: 7, TotalTokens: 7}, nil, fmt.Errorf("429 rate limit")
+			}
+			return "ok", cfg.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}, nil, nil
+		}),
+		AgentProvider: func(name string) (*cfg.AgentCFG, bool) { return &cfg.AgentCFG{Steps: steps}, true },
+		MaxStackDepth: 10,
+	})
+	engine.OnStepStart = func(frame cfg.StackFrame, step cfg.Step) { recorder.start(frame, step) }
+	engine.OnStepComplete = func(frame cfg.StackFrame, step cfg.Step, result string) { recorder.finish(nil) }
+
+	_, _, err = engine.Execute(context.Background(), &cfg.DelegateInfo{
+		MaxDepth: 10, AgentName: "main", Chain: []string{"main"}, RecursionCounters: map[string]int{},
+	}, cfg.AgentCFG{Steps: steps}, "go", nil)
+	if err == nil {
+		t.Fatal("expected error from step-1")
+	}
+	recorder.finish(err)
+
+	spans, err := tracker.loadSpans(sess.ID, "dlg_rec")
+	if err != nil {
+		t.Fatalf("loadSpans: %v", err)
+	}
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 step spans, got %d", len(spans))
+	}
+	first, second := spans[0], spans[1]
+	if first.Name != "step-0" || first.Status != "completed" || first.Model != "gpt-4o" {
+		t.Errorf("unexpected first step: %+v", first)
+	}
+	if first.PromptTokens != 10 || first.CompletionTokens != 2 || first.TotalTokens != 12 {
+		t.Errorf("unexpected first step usage: %+v", first)
+	}
+	if first.DurationMs < 5 || !second.StartedAt.After(first.StartedAt) {
+		t.Errorf("expected own timing per step, got %v (%dms) and %v", first.StartedAt, first.DurationMs, second.StartedAt)
+	}
+	if second.Name != "step-1" || second.Status != "failed" || second.Error == "" || second.TotalTokens != 7 {
+		t.Errorf("unexpected failed step: %+v", second)
+	}
+
+	var nilRecorder *pdaStepRecorder
+	nilRecorder.start(cfg.StackFrame{}, cfg.Step{})
+	nilRecorder.finish(nil)
+}
@@ -0,0 +1,323 @@
+package delegate_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"mote/internal/runner/delegate"
+	"mote/internal/storage"
+)
+
+func setupTraceDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "trace.db"))
+	if err != nil {
+		t.Fatalf("failed to open storage: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func toolCall(t *testing.T, id, name, args string) storage.ToolCall {
+	t.Helper()
+	fn, err := json.Marshal(map[string]string{"name": name, "arguments": args})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage.ToolCall{ID: id, Type: "function", Function: fn}
+}
+
+func TestBuildTrace_Tree(t *testing.T) {
+	db := setupTraceDB(t)
+	tracker := delegate.NewTracker(db.DB)
+
+	const parent = "sess-trace"
+	child := "delegate:sess-trace:researcher:1"
+	if _, err := db.CreateSessionWithID(parent, nil); err != nil {
+		t.Fatalf("create parent session: %v", err)
+	}
+	if _, err := db.CreateSessionWithID(child, nil); err != nil {
+		t.Fatalf("create child session: %v", err)
+	}
+
+	// Parent: user -> assistant(delegate call) -> delegation -> tool result.
+	turnStart := time.Now()
+	_, _ = db.AppendMessage(parent, "user", "research it", nil, "")
+	_, _ = db.AppendMessage(parent, "assistant", "", []storage.ToolCall{
+		toolCall(t, "call_dlg", "delegate", `{"agent":"researcher","prompt":"go"}`),
+	}, "")
+
+	if err := tracker.StartDelegation(delegate.DelegationRecord{
+		ID:              "dlg_trace_1",
+		ParentSessionID: parent,
+		ChildSessionID:  child,
+		AgentName:       "researcher",
+		Depth:           1,
+		Chain:           delegate.ChainToJSON([]string{"main", "researcher"}),
+		Prompt:          "go",
+		StartedAt:       time.Now(),
+		Model:           "gpt-4o",
+		ToolCallID:      "call_dlg",
+	}); err != nil {
+		t.Fatalf("StartDelegation: %v", err)
+	}
+
+	// Child: one tool call that completes.
+	_, _ = db.AppendMessage(child, "assistant", "", []storage.ToolCall{
+		toolCall(t, "call_read", "read_file", `{"path":"a.txt"}`),
+	}, "")
+	time.Sleep(5 * time.Millisecond)
+	_, _ = db.AppendMessage(child, "tool", "contents", nil, "call_read")
+
+	if err := tracker.FinishDelegation("dlg_trace_1", delegate.DelegationCompletion{
+		Status:           "completed",
+		ResultLength:     10,
+		PromptTokens:     300,
+		CompletionTokens: 120,
+		TotalTokens:      420,
+	}); err != nil {
+		t.Fatalf("FinishDelegation: %v", err)
+	}
+	_, _ = db.AppendMessage(parent, "tool", "done", nil, "call_dlg")
+	if err := tracker.RecordSpan(delegate.TraceSpan{
+		ID:               "turn_1",
+		SessionID:        parent,
+		Kind:             delegate.TraceKindTurn,
+		Name:             "turn",
+		Model:            "claude-sonnet",
+		Status:           "completed",
+		StartedAt:        turnStart,
+		EndedAt:          time.Now(),
+		PromptTokens:     80,
+		CompletionTokens: 20,
+	}); err != nil {
+		t.Fatalf("RecordSpan: %v", err)
+	}
+
+	trace, err := tracker.BuildTrace(parent)
+	if err != nil {
+		t.Fatalf("BuildTrace: %v", err)
+	}
+	if trace == nil {
+		t.Fatal("expected trace, got nil")
+	}
+	if trace.Kind != delegate.TraceKindSession {
+		t.Errorf("root kind = %q, want session", trace.Kind)
+	}
+	if len(trace.Children) != 2 {
+		t.Fatalf("expected 2 root children, got %d", len(trace.Children))
+	}
+
+	turn := trace.Children[0]
+	if turn.Kind != delegate.TraceKindTurn || turn.Model != "claude-sonnet" {
+		t.Fatalf("expected main agent turn first, got %s %q", turn.Kind, turn.Model)
+	}
+	if turn.TotalTokens != 100 || turn.EndedAt == nil {
+		t.Errorf("unexpected turn usage/end: %+v", turn)
+	}
+
+	dlgCall := trace.Children[1]
+	if dlgCall.Kind != delegate.TraceKindTool || dlgCall.Name != "delegate" {
+		t.Fatalf("expected delegate tool node, got %s %q", dlgCall.Kind, dlgCall.Name)
+	}
+	if dlgCall.Status != "completed" || dlgCall.EndedAt == nil {
+		t.Errorf("expected delegate call completed with end time, got %q", dlgCall.Status)
+	}
+	if len(dlgCall.Children) != 1 {
+		t.Fatalf("expected agent nested under delegate call, got %d children", len(dlgCall.Children))
+	}
+
+	agent := dlgCall.Children[0]
+	if agent.Kind != delegate.TraceKindAgent || agent.Name != "researcher" {
+		t.Fatalf("expected researcher agent node, got %s %q", agent.Kind, agent.Name)
+	}
+	if agent.Model != "gpt-4o" {
+		t.Errorf("agent model = %q, want gpt-4o", agent.Model)
+	}
+	if agent.PromptTokens != 300 || agent.CompletionTokens != 120 || agent.TotalTokens != 420 {
+		t.Errorf("unexpected agent usage: %+v", agent)
+	}
+	if len(agent.Children) != 1 || agent.Children[0].Name != "read_file" {
+		t.Fatalf("expected child session tool call under agent, got %+v", agent.Children)
+	}
+	if agent.Children[0].DurationMs < 5 {
+		t.Errorf("expected tool duration >= 5ms, got %d", agent.Children[0].DurationMs)
+	}
+
+	if trace.SubtreeTokens != 520 {
+		t.Errorf("root subtree tokens = %d, want 520", trace.SubtreeTokens)
+	}
+}
+
+func TestBuildTrace_PDASteps(t *testing.T) {
+	db := setupTraceDB(t)
+	tracker := delegate.NewTracker(db.DB)
+
+	const sess = "sess-pda"
+	if _, err := db.CreateSessionWithID(sess, nil); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	_ = tracker.StartDelegation(delegate.DelegationRecord{
+		ID:              "dlg_pda_1",
+		ParentSessionID: sess,
+		ChildSessionID:  sess,
+		AgentName:       "planner",
+		Depth:           0,
+		Chain:           delegate.ChainToJSON([]string{"planner"}),
+		Prompt:          "plan",
+		StartedAt:       time.Now(),
+		Mode:            "structured",
+	})
+	start := time.Now()
+	for i, step := range []struct {
+		name   string
+		tokens int
+		status string
+		err    string
+	}{{"analyze", 20, "completed", ""}, {"draft", 25, "failed", "boom"}} {
+		if err := tracker.RecordSpan(delegate.TraceSpan{
+			ID:           fmt.Sprintf("dlg_pda_1#step%d", i),
+			SessionID:    sess,
+			ParentID:     "dlg_pda_1",
+			Kind:         delegate.TraceKindPDAStep,
+			Name:         step.name,
+			Status:       step.status,
+			StartedAt:    start.Add(time.Duration(i) * time.Second),
+			EndedAt:      start.Add(time.Duration(i)*time.Second + 500*time.Millisecond),
+			TotalTokens:  step.tokens,
+			ErrorMessage: step.err,
+		}); err != nil {
+			t.Fatalf("RecordSpan: %v", err)
+		}
+	}
+	_ = tracker.CompleteDelegationWithPDA("dlg_pda_1", "failed", 0, 50, nil, []string{"analyze"}, 1)
+
+	trace, err := tracker.BuildTrace(sess)
+	if err != nil {
+		t.Fatalf("BuildTrace: %v", err)
+	}
+	if len(trace.Children) != 1 {
+		t.Fatalf("expected 1 child, got %d", len(trace.Children))
+	}
+	agent := trace.Children[0]
+	if len(agent.Children) != 2 {
+		t.Fatalf("expected 2 PDA steps, got %d", len(agent.Children))
+	}
+	for i, want := range []string{"analyze", "draft"} {
+		step := agent.Children[i]
+		if step.Kind != delegate.TraceKindPDAStep || step.Name != want {
+			t.Errorf("step %d = %s %q, want pda_step %q", i, step.Kind, step.Name, want)
+		}
+		if step.DurationMs != 500 {
+			t.Errorf("step %d duration = %dms, want 500", i, step.DurationMs)
+		}
+	}
+	if agent.Children[1].Status != "failed" || agent.Children[1].Error != "boom" {
+		t.Errorf("expected failed draft step, got %+v", agent.Children[1])
+	}
+	if agent.TotalTokens != 5 || agent.SubtreeTokens != 50 {
+		t.Errorf("agent own/subtree tokens = %d/%d, want 5/50", agent.TotalTokens, agent.SubtreeTokens)
+	}
+}
+
+func TestBuildTrace_ToolErrors(t *testing.T) {
+	db := setupTraceDB(t)
+	tracker := delegate.NewTracker(db.DB)
+
+	const session = "sess-tool-errors"
+	if _, err := db.CreateSessionWithID(session, nil); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	longArg := strings.Repeat("日志", 300)
+	_, _ = db.AppendMessage(session, "assistant", "", []storage.ToolCall{
+		toolCall(t, "call_log", "read_file", `{"path":"`+longArg+`"}`),
+		toolCall(t, "call_fetch", "web_fetch", `{"url":"https://example.com"}`),
+	}, "")
+	// Content that merely starts with "Error" is not a failure; the error flag is
+	_, _ = db.AppendToolResult(session, "Error log rotated at 02:00", "call_log", false)
+	_, _ = db.AppendToolResult(session, "tool error: connection refused", "call_fetch", true)
+
+	trace, err := tracker.BuildTrace(session)
+	if err != nil || trace == nil {
+		t.Fatalf("BuildTrace: %v %v", trace, err)
+	}
+	if len(trace.Children) != 2 {
+		t.Fatalf("expected 2 tool nodes, got %d", len(trace.Children))
+	}
+	read, fetch := trace.Children[0], trace.Children[1]
+	if read.Status != "completed" || read.Error != "" {
+		t.Errorf("expected read_file completed, got %q (%q)", read.Status, read.Error)
+	}
+	if fetch.Status != "failed" || fetch.Error != "tool error: connection refused" {
+		t.Errorf("expected web_fetch failed, got %q (%q)", fetch.Status, fetch.Error)
+	}
+	if args := read.Attributes["arguments"]; !utf8.ValidString(args) || !strings.HasSuffix(args, "...") {
+		t.Errorf("truncated arguments should be valid UTF-8 ending in ..., got %q", args)
+	}
+}
+
+func TestBuildTrace_NotFound(t *testing.T) {
+	db := setupTraceDB(t)
+	tracker := delegate.NewTracker(db.DB)
+
+	trace, err := tracker.BuildTrace("missing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trace != nil {
+		t.Errorf("expected nil trace, got %+v", trace)
+	}
+}
+
+func TestExportOTLP(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	end := start.Add(2 * time.Second)
+	root := &delegate.TraceNode{
+		ID:        "sess-1",
+		Kind:      delegate.TraceKindSession,
+		Name:      "sess-1",
+		SessionID: "sess-1",
+		Status:    "completed",
+		StartedAt: start,
+		EndedAt:   &end,
+		Children: []*delegate.TraceNode{{
+			ID:          "dlg_1",
+			Kind:        delegate.TraceKindAgent,
+			Name:        "coder",
+			Status:      "failed",
+			Error:       "boom",
+			StartedAt:   start,
+			EndedAt:     &end,
+			TotalTokens: 10,
+		}},
+	}
+
+	out := delegate.ExportOTLP(root)
+	if len(out.ResourceSpans) != 1 || len(out.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected envelope: %+v", out)
+	}
+	spans := out.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if len(spans[0].TraceID) != 32 || len(spans[0].SpanID) != 16 {
+		t.Errorf("unexpected id lengths: trace=%q span=%q", spans[0].TraceID, spans[0].SpanID)
+	}
+	if spans[0].ParentSpanID != "" {
+		t.Errorf("root span should have no parent, got %q", spans[0].ParentSpanID)
+	}
+	if spans[1].ParentSpanID != spans[0].SpanID || spans[1].TraceID != spans[0].TraceID {
+		t.Errorf("child span not linked to root: %+v", spans[1])
+	}
+	if spans[1].Status.Code != 2 || spans[1].Status.Message != "boom" {
+		t.Errorf("expected error status, got %+v", spans[1].Status)
+	}
+	if spans[0].EndTimeUnixNano != "1700000002000000000" {
+		t.Errorf("unexpected end time %q", spans[0].EndTimeUnixNano)
+	}
+}
@@ -15,24 +15,45 @@ type DelegationTracker struct {
 
 // DelegationRecord represents a single delegation invocation.
 type DelegationRecord struct {
-	ID              string     `json:"id"`
-	ParentSessionID string     `json:"parent_session_id"`
-	ChildSessionID  string     `json:"child_session_id"`
-	AgentName       string     `json:"agent_name"`
-	Depth           int        `json:"depth"`
-	Chain           string     `json:"chain"` // JSON array: ["main","researcher"]
-	Prompt          string     `json:"prompt"`
-	Status          string     `json:"status"` // "running", "completed", "failed", "timeout"
-	StartedAt       time.Time  `json:"started_at"`
-	CompletedAt     *time.Time `json:"completed_at,omitempty"`
-	ResultLength    int        `json:"result_length"`
-	TokensUsed      int        `json:"tokens_used"`
-	ErrorMessage    *string    `json:"error_message,omitempty"`
-	Mode            string     `json:"mode"`           // "legacy" or "structured"
-	ExecutedSteps   []string   `json:"executed_steps"` // PDA step labels executed
-	PDAStackDepth   int        `json:"pda_stack_depth"`
+	ID               string     `json:"id"`
+	ParentSessionID  string     `json:"parent_session_id"`
+	ChildSessionID   string     `json:"child_session_id"`
+	AgentName        string     `json:"agent_name"`
+	Depth            int        `json:"depth"`
+	Chain            string     `json:"chain"` // JSON array: ["main","researcher"]
+	Prompt           string     `json:"prompt"`
+	Status           string     `json:"status"` // "running", "completed", "failed", "timeout"
+	StartedAt        time.Time  `json:"started_at"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	ResultLength     int        `json:"result_length"`
+	TokensUsed       int        `json:"tokens_used"`
+	ErrorMessage     *string    `json:"error_message,omitempty"`
+	Mode             string     `json:"mode"`           // "legacy" or "structured"
+	ExecutedSteps    []string   `json:"executed_steps"` // PDA step labels executed
+	PDAStackDepth    int        `json:"pda_stack_depth"`
+	Model            string     `json:"model,omitempty"`
+	PromptTokens     int        `json:"prompt_tokens"`
+	CompletionTokens int        `json:"completion_tokens"`
+	ToolCallID       string     `json:"tool_call_id,omitempty"` // delegate tool call that spawned the invocation
 }
 
+// DelegationCompletion carries the final state of a delegation invocation.
+type DelegationCompletion struct {
+	Status           string
+	ResultLength     int
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	ErrorMessage     *string
+	ExecutedSteps    []string
+	PDAStackDepth    int
+}
+
+// recordColumns is the column list shared by all DelegationRecord queries.
+const recordColumns = `id, parent_session_id, child_session_id, agent_name, depth, chain, prompt,
+		       status, started_at, completed_at, result_length, tokens_used, error_message,
+		       mode, executed_steps, pda_stack_depth, model, prompt_tokens, completion_tokens, tool_call_id`
+
 // NewTracker creates a new DelegationTracker.
 func NewTracker(db *sql.DB) *DelegationTracker {
 	return &DelegationTracker{db: db}
@@ -46,11 +67,11 @@ func (t *DelegationTracker) StartDelegation(record DelegationRecord) error {
 	}
 	_, err := t.db.Exec(`
 		INSERT INTO delegate_invocations 
-		(id, parent_session_id, child_session_id, agent_name, depth, chain, prompt, status, started_at, mode)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		(id, parent_session_id, child_session_id, agent_name, depth, chain, prompt, status, started_at, mode, model, tool_call_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		record.ID, record.ParentSessionID, record.ChildSessionID,
 		record.AgentName, record.Depth, record.Chain, record.Prompt,
-		"running", record.StartedAt, mode)
+		"running", record.StartedAt, mode, record.Model, record.ToolCallID)
 	return err
 }
 
@@ -75,27 +96,41 @@ func (t *DelegationTracker) CompleteDelegationWithPDA(
 	executedSteps []string,
 	pdaStackDepth int,
 ) error {
+	return t.FinishDelegation(id, DelegationCompletion{
+		Status:        status,
+		ResultLength:  resultLength,
+		TotalTokens:   tokensUsed,
+		ErrorMessage:  errorMsg,
+		ExecutedSteps: executedSteps,
+		PDAStackDepth: pdaStackDepth,
+	})
+}
+
+// FinishDelegation updates a delegation record with its final status,
+// token breakdown and (for structured agents) PDA execution details.
+func (t *DelegationTracker) FinishDelegation(id string, c DelegationCompletion) error {
 	now := time.Now()
 	var stepsJSON *string
-	if len(executedSteps) > 0 {
-		data, _ := json.Marshal(executedSteps)
+	if len(c.ExecutedSteps) > 0 {
+		data, _ := json.Marshal(c.ExecutedSteps)
 		s := string(data)
 		stepsJSON = &s
 	}
 	_, err := t.db.Exec(`
 		UPDATE delegate_invocations 
 		SET status = ?, completed_at = ?, result_length = ?, tokens_used = ?, 
-		    error_message = ?, executed_steps = ?, pda_stack_depth = ?
+		    error_message = ?, executed_steps = ?, pda_stack_depth = ?,
+		    prompt_tokens = ?, completion_tokens = ?
 		WHERE id = ?`,
-		status, now, resultLength, tokensUsed, errorMsg, stepsJSON, pdaStackDepth, id)
+		c.Status, now, c.ResultLength, c.TotalTokens, c.ErrorMessage, stepsJSON, c.PDAStackDepth,
+		c.PromptTokens, c.CompletionTokens, id)
 	return err
 }
 
 // GetByParentSession returns all delegation records for a parent session.
 func (t *DelegationTracker) GetByParentSession(parentSessionID string) ([]DelegationRecord, error) {
 	rows, err := t.db.Query(`
-		SELECT id, parent_session_id, child_session_id, agent_name, depth, chain, prompt,
-		       status, started_at, completed_at, result_length, tokens_used, error_message
+		SELECT `+recordColumns+`
 		FROM delegate_invocations
 		WHERE parent_session_id = ?
 		ORDER BY started_at ASC`, parentSessionID)
@@ -110,31 +145,18 @@ func (t *DelegationTracker) GetByParentSession(parentSessionID string) ([]Delega
 // GetByID returns a single delegation record by ID.
 func (t *DelegationTracker) GetByID(id string) (*DelegationRecord, error) {
 	row := t.db.QueryRow(`
-		SELECT id, parent_session_id, child_session_id, agent_name, depth, chain, prompt,
-		       status, started_at, completed_at, result_length, tokens_used, error_message
+		SELECT `+recordColumns+`
 		FROM delegate_invocations
 		WHERE id = ?`, id)
 
-	var r DelegationRecord
-	var completedAt sql.NullTime
-	var errorMsg sql.NullString
-	err := row.Scan(
-		&r.ID, &r.ParentSessionID, &r.ChildSessionID, &r.AgentName,
-		&r.Depth, &r.Chain, &r.Prompt, &r.Status, &r.StartedAt,
-		&completedAt, &r.ResultLength, &r.TokensUsed, &errorMsg)
+	r, err := scanRecord(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	if completedAt.Valid {
-		r.CompletedAt = &completedAt.Time
-	}
-	if errorMsg.Valid {
-		r.ErrorMessage = &errorMsg.String
-	}
-	return &r, nil
+	return r, nil
 }
 
 // GetRecent returns the most recent delegation records across all sessions,
@@ -145,8 +167,7 @@ func (t *DelegationTracker) GetRecent(limit int) ([]DelegationRecord, error) {
 		limit = 50
 	}
 	rows, err := t.db.Query(`
-		SELECT id, parent_session_id, child_session_id, agent_name, depth, chain, prompt,
-		       status, started_at, completed_at, result_length, tokens_used, error_message
+		SELECT `+recordColumns+`
 		FROM delegate_invocations
 		ORDER BY started_at DESC
 		LIMIT ?`, limit)
@@ -189,26 +210,60 @@ func GenerateInvocationID(parentSession, agentName string) string {
 	return fmt.Sprintf("dlg_%s_%s_%d", parentSession[:min(8, len(parentSession))], agentName, time.Now().UnixMilli())
 }
 
+// GetByChildSession returns the delegation records whose child session is
+// childSessionID. PDA invocations reuse the parent session, so a child session
+// may be shared by several records.
+func (t *DelegationTracker) GetByChildSession(childSessionID string) ([]DelegationRecord, error) {
+	rows, err := t.db.Query(`
+		SELECT `+recordColumns+`
+		FROM delegate_invocations
+		WHERE child_session_id = ?
+		ORDER BY started_at ASC`, childSessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+// rowScanner abstracts *sql.Row and *sql.Rows for scanRecord.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRecord(row rowScanner) (*DelegationRecord, error) {
+	var r DelegationRecord
+	var completedAt sql.NullTime
+	var errorMsg, stepsJSON sql.NullString
+	err := row.Scan(
+		&r.ID, &r.ParentSessionID, &r.ChildSessionID, &r.AgentName,
+		&r.Depth, &r.Chain, &r.Prompt, &r.Status, &r.StartedAt,
+		&completedAt, &r.ResultLength, &r.TokensUsed, &errorMsg,
+		&r.Mode, &stepsJSON, &r.PDAStackDepth, &r.Model, &r.PromptTokens, &r.CompletionTokens, &r.ToolCallID)
+	if err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		r.CompletedAt = &completedAt.Time
+	}
+	if errorMsg.Valid {
+		r.ErrorMessage = &errorMsg.String
+	}
+	if stepsJSON.Valid && stepsJSON.String != "" {
+		_ = json.Unmarshal([]byte(stepsJSON.String), &r.ExecutedSteps)
+	}
+	return &r, nil
+}
+
 func scanRecords(rows *sql.Rows) ([]DelegationRecord, error) {
 	var records []DelegationRecord
 	for rows.Next() {
-		var r DelegationRecord
-		var completedAt sql.NullTime
-		var errorMsg sql.NullString
-		err := rows.Scan(
-			&r.ID, &r.ParentSessionID, &r.ChildSessionID, &r.AgentName,
-			&r.Depth, &r.Chain, &r.Prompt, &r.Status, &r.StartedAt,
-			&completedAt, &r.ResultLength, &r.TokensUsed, &errorMsg)
+		r, err := scanRecord(rows)
 		if err != nil {
 			return nil, err
 		}
-		if completedAt.Valid {
-			r.CompletedAt = &completedAt.Time
-		}
-		if errorMsg.Valid {
-			r.ErrorMessage = &errorMsg.String
-		}
-		records = append(records, r)
+		records = append(records, *r)
 	}
 	return records, rows.Err()
 }
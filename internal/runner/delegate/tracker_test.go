@@ -34,7 +34,11 @@ func setupTestDB(t *testing.T) *sql.DB {
 			error_message TEXT,
 			mode TEXT NOT NULL DEFAULT 'legacy',
 			executed_steps TEXT,
-			pda_stack_depth INTEGER NOT NULL DEFAULT 0
+			pda_stack_depth INTEGER NOT NULL DEFAULT 0,
+			model TEXT NOT NULL DEFAULT '',
+			prompt_tokens INTEGER NOT NULL DEFAULT 0,
+			completion_tokens INTEGER NOT NULL DEFAULT 0,
+			tool_call_id TEXT NOT NULL DEFAULT ''
 		)`)
 	if err != nil {
 		t.Fatalf("failed to create table: %v", err)
@@ -193,6 +193,9 @@ type ToolCallUpdateEvent struct {
 
 	// Arguments contains the tool call arguments (may be partial during streaming).
 	Arguments string `json:"arguments,omitempty"`
+
+	// Message is a progress or log line reported while the tool runs.
+	Message string `json:"message,omitempty"`
 }
 
 // PDAProgressEvent represents PDA step execution progress.
@@ -237,6 +240,9 @@ type ApprovalRequestEvent struct {
 
 	// ExpiresAt is the ISO 8601 timestamp when the request expires.
 	ExpiresAt string `json:"expires_at"`
+
+	// WorkspacePath is the workspace bound to the session, if any.
+	WorkspacePath string `json:"workspace_path,omitempty"`
 }
 
 // ApprovalResolvedEvent contains data for an approval_resolved event.
@@ -281,6 +287,19 @@ func NewToolResultEvent(callID, toolName, output string, isError bool, durationM
 	}
 }
 
+// NewToolProgressEvent creates a tool call update carrying a progress message.
+func NewToolProgressEvent(callID, toolName, message string) Event {
+	return Event{
+		Type: EventTypeToolCallUpdate,
+		ToolCallUpdate: &ToolCallUpdateEvent{
+			ToolCallID: callID,
+			ToolName:   toolName,
+			Status:     "running",
+			Message:    message,
+		},
+	}
+}
+
 // NewDoneEvent creates a new done event with optional usage info.
 func NewDoneEvent(usage *Usage) Event {
 	return Event{
@@ -404,6 +423,7 @@ func FromTypesEvent(te types.Event) Event {
 			ToolName:   te.ToolCallUpdate.ToolName,
 			Status:     te.ToolCallUpdate.Status,
 			Arguments:  te.ToolCallUpdate.Arguments,
+			Message:    te.ToolCallUpdate.Message,
 		}
 	}
 
@@ -119,7 +119,7 @@ func (r *Runner) executeMCPAdd(ctx context.Context, config *MCPConfig) *MCPPrepr
 				Response: "❌ URL is required for HTTP type MCP server",
 			}
 		}
-		clientConfig.TransportType = transport.TransportHTTP
+		clientConfig.TransportType = transport.TransportStreamableHTTP
 		clientConfig.URL = config.URL
 		clientConfig.Headers = config.Headers
 
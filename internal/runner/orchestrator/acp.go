@@ -13,6 +13,7 @@ import (
 	"mote/internal/scheduler"
 	"mote/internal/skills"
 	"mote/internal/storage"
+	"mote/internal/usage"
 )
 
 // ACPOrchestrator 处理 ACP 协议的 provider 执行
@@ -123,6 +124,10 @@ func (o *ACPOrchestrator) runACPLoop(ctx context.Context, request *RunRequest, e
 		"finalMessageCount", len(messages))
 
 	// 7. 调用 provider.Stream()
+	budgetWarned := false
+	if !o.admitACPCall(sessionID, events, &budgetWarned) {
+		return
+	}
 	provEvents, err := prov.Stream(ctx, req)
 	if err != nil {
 		// 重试逻辑：如果上下文窗口超限，截断后重试一次。
@@ -136,6 +141,9 @@ func (o *ACPOrchestrator) runACPLoop(ctx context.Context, request *RunRequest, e
 			events <- types.NewContentEvent("\n\n⚠️ Context window exceeded — truncating history and retrying…\n\n")
 			truncated := o.compactor.TruncateOnly(messages)
 			if len(truncated) > 0 && len(truncated) < len(messages) {
+				if !o.admitACPCall(sessionID, events, &budgetWarned) {
+					return
+				}
 				req.Messages = truncated
 				provEvents, err = prov.Stream(ctx, req)
 			}
@@ -150,6 +158,27 @@ func (o *ACPOrchestrator) runACPLoop(ctx context.Context, request *RunRequest, e
 	o.forwardAndSaveEvents(sessionID, provEvents, events)
 }
 
+// admitACPCall 在每次 LLM 调用前检查用量预算。
+// 预算耗尽时发出错误事件并返回 false；告警或降级只提示一次（ACP 自行选择模型，无法降级）。
+func (o *ACPOrchestrator) admitACPCall(sessionID string, events chan<- types.Event, warned *bool) bool {
+	decision := o.usageMeter.Admit(sessionID)
+	switch decision.Action {
+	case usage.ActionAllow:
+		return true
+	case usage.ActionStop:
+		slog.Warn("ACPOrchestrator: usage budget exceeded, stopping",
+			"sessionID", sessionID, "budget", decision.Budget)
+		events <- types.NewErrorEvent(fmt.Errorf("%w: %s", ErrBudgetExceeded, decision.Message))
+		return false
+	default:
+		if !*warned {
+			*warned = true
+			events <- types.NewContentEvent("\n\n⚠️ " + decision.Message + "\n\n")
+		}
+		return true
+	}
+}
+
 // injectSkills 将技能注入到系统消息中
 func (o *ACPOrchestrator) injectSkills(sessionID string, cached *scheduler.CachedSession, messages []provider.Message) []provider.Message {
 	if o.skillManager == nil {
@@ -267,6 +296,7 @@ func (o *ACPOrchestrator) forwardAndSaveEvents(sessionID string, provEvents <-ch
 				totalUsage.PromptTokens += event.Usage.PromptTokens
 				totalUsage.CompletionTokens += event.Usage.CompletionTokens
 				totalUsage.TotalTokens += event.Usage.TotalTokens
+				o.usageMeter.Record(sessionID, event.Usage.PromptTokens, event.Usage.CompletionTokens, event.Usage.TotalTokens)
 			}
 
 			// 保存助手消息
@@ -12,6 +12,7 @@ import (
 	"mote/internal/scheduler"
 	"mote/internal/skills"
 	"mote/internal/tools"
+	"mote/internal/usage"
 )
 
 // BaseOrchestrator 提供共享的基础功能
@@ -29,6 +30,7 @@ type BaseOrchestrator struct {
 	contextManager    *internalContext.Manager
 	toolExecutor      ToolExecutorFunc
 	workspaceResolver func(sessionID string) string
+	usageMeter        *usage.AgentMeter
 
 	// Configuration
 	config Config
@@ -87,6 +89,11 @@ func (b *BaseOrchestrator) SetWorkspaceResolver(resolver func(sessionID string)
 	b.workspaceResolver = resolver
 }
 
+// SetUsageMeter 设置用量计量器（记录 token 用量并检查预算）
+func (b *BaseOrchestrator) SetUsageMeter(m *usage.AgentMeter) {
+	b.usageMeter = m
+}
+
 // triggerHook 触发钩子
 func (b *BaseOrchestrator) triggerHook(ctx context.Context, hookCtx *hooks.Context) (*hooks.Result, error) {
 	if b.hookManager == nil {
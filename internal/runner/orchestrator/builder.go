@@ -10,6 +10,7 @@ import (
 	"mote/internal/scheduler"
 	"mote/internal/skills"
 	"mote/internal/tools"
+	"mote/internal/usage"
 )
 
 // BuilderOptions 用于构建 Orchestrator 的选项
@@ -25,6 +26,7 @@ type BuilderOptions struct {
 	ContextManager    *internalContext.Manager
 	ToolExecutor      ToolExecutorFunc
 	WorkspaceResolver func(sessionID string) string // Resolves workspace path for a session
+	UsageMeter        *usage.AgentMeter             // Records usage and enforces budgets
 }
 
 // OrchestratorBuilder 构建器用于创建 Orchestrator
@@ -67,6 +69,9 @@ func (b *OrchestratorBuilder) Build(prov provider.Provider) Orchestrator {
 	if b.opts.WorkspaceResolver != nil {
 		base.SetWorkspaceResolver(b.opts.WorkspaceResolver)
 	}
+	if b.opts.UsageMeter != nil {
+		base.SetUsageMeter(b.opts.UsageMeter)
+	}
 
 	// 根据 provider 类型选择合适的 orchestrator
 	if acpProv, ok := prov.(provider.ACPCapable); ok && acpProv.IsACPProvider() {
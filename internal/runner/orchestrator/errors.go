@@ -12,4 +12,7 @@ var (
 
 	// ErrContextCanceled 表示上下文被取消
 	ErrContextCanceled = errors.New("context canceled or timeout")
+
+	// ErrBudgetExceeded 表示用量预算已耗尽
+	ErrBudgetExceeded = errors.New("usage budget exceeded")
 )
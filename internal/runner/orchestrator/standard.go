@@ -18,6 +18,7 @@ import (
 	"mote/internal/skills"
 	"mote/internal/storage"
 	"mote/internal/tools"
+	"mote/internal/usage"
 )
 
 const (
@@ -112,11 +113,7 @@ func (o *StandardOrchestrator) Run(ctx context.Context, request *RunRequest) (<-
 				}
 			}
 			if mop, ok := request.Provider.(provider.MaxOutputProvider); ok {
-				sessionModel := ""
-				if request.CachedSession.Session != nil {
-					sessionModel = request.CachedSession.Session.Model
-				}
-				if maxOut := mop.MaxOutput(sessionModel); maxOut > 0 {
+				if maxOut := mop.MaxOutput(request.model()); maxOut > 0 {
 					o.systemPrompt.SetMaxOutputTokens(maxOut)
 				}
 			}
@@ -149,6 +146,7 @@ func (o *StandardOrchestrator) Run(ctx context.Context, request *RunRequest) (<-
 		}
 
 		var totalUsage types.Usage
+		budgetWarned := false
 
 		// Smart MCP injection: Full details on first iteration, summary on subsequent
 		if o.systemPrompt != nil {
@@ -193,11 +191,7 @@ func (o *StandardOrchestrator) Run(ctx context.Context, request *RunRequest) (<-
 		sessionCompactor := o.compactor
 		if o.compactor != nil {
 			if cwp, ok := request.Provider.(provider.ContextWindowProvider); ok {
-				sessionModel := ""
-				if request.CachedSession.Session != nil {
-					sessionModel = request.CachedSession.Session.Model
-				}
-				if cw := cwp.ContextWindow(sessionModel); cw > 0 {
+				if cw := cwp.ContextWindow(request.model()); cw > 0 {
 					sessionCompactor = o.compactor.WithContextWindow(cw)
 				}
 			}
@@ -349,11 +343,21 @@ func (o *StandardOrchestrator) Run(ctx context.Context, request *RunRequest) (<-
 				}
 			}
 
-			sessionModel := ""
-			if request.CachedSession.Session != nil {
-				sessionModel = request.CachedSession.Session.Model
+			// Check usage budgets before each LLM call
+			if decision := o.usageMeter.Admit(request.SessionID); decision.Action != usage.ActionAllow {
+				if decision.Action == usage.ActionStop {
+					slog.Warn("StandardOrchestrator: usage budget exceeded, stopping",
+						"sessionID", request.SessionID, "budget", decision.Budget)
+					events <- types.NewErrorEvent(fmt.Errorf("%w: %s", ErrBudgetExceeded, decision.Message))
+					return
+				}
+				if !budgetWarned {
+					budgetWarned = true
+					events <- types.NewContentEvent("\n\n⚠️ " + decision.Message + "\n\n")
+				}
 			}
-			req := o.buildChatRequest(messages, sessionModel, request.SessionID, request.Attachments)
+
+			req := o.buildChatRequest(messages, request.model(), request.SessionID, request.Attachments)
 
 			// Call provider
 			resp, err := o.callProvider(ctx, request.Provider, req, events)
@@ -423,6 +427,7 @@ func (o *StandardOrchestrator) Run(ctx context.Context, request *RunRequest) (<-
 				totalUsage.PromptTokens += resp.Usage.PromptTokens
 				totalUsage.CompletionTokens += resp.Usage.CompletionTokens
 				totalUsage.TotalTokens += resp.Usage.TotalTokens
+				o.usageMeter.Record(request.SessionID, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens)
 			}
 
 			// Handle response - use FinishReason as the authoritative signal
@@ -885,8 +890,10 @@ func (o *StandardOrchestrator) Run(ctx context.Context, request *RunRequest) (<-
 			// Save to session (skip in InjectedMessages mode — PDA engine owns context)
 			storageTcs := convertToolCalls(resp.ToolCalls)
 			persistMsg(provider.RoleAssistant, resp.Content, storageTcs, "")
-			for _, result := range toolResults {
-				persistMsg(result.Role, result.Content, nil, result.ToolCallID)
+			if !isInjectedMode {
+				for _, result := range toolResults {
+					_, _ = o.sessions.AddToolResult(request.SessionID, result.Content, result.ToolCallID, result.IsError)
+				}
 			}
 			slog.Info("StandardOrchestrator: iteration saved, continuing to next",
 				"sessionID", request.SessionID, "iteration", iteration)
@@ -1090,6 +1097,7 @@ func (o *StandardOrchestrator) executeToolsSimple(ctx context.Context, toolCalls
 					Role:       provider.RoleTool,
 					Content:    errMsg,
 					ToolCallID: tc.ID,
+					IsError:    true,
 				})
 				events <- types.NewToolResultEvent(tc.ID, toolName, errMsg, true, 0)
 				errorCount++
@@ -1099,7 +1107,7 @@ func (o *StandardOrchestrator) executeToolsSimple(ctx context.Context, toolCalls
 
 		// Execute tool
 		start := time.Now()
-		result, err := o.registry.Execute(ctx, toolName, argsMap)
+		result, err := o.registry.Execute(tools.WithToolCallID(ctx, tc.ID), toolName, argsMap)
 		duration := time.Since(start)
 
 		var output string
@@ -1124,6 +1132,7 @@ func (o *StandardOrchestrator) executeToolsSimple(ctx context.Context, toolCalls
 			Role:       provider.RoleTool,
 			Content:    output,
 			ToolCallID: tc.ID,
+			IsError:    isError,
 		})
 	}
 
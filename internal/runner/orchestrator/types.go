@@ -27,6 +27,20 @@ type RunRequest struct {
 	// 直接使用这些消息作为 LLM 上下文。用于 PDA 引擎的帧级上下文隔离。
 	// 调用方负责将 UserInput 追加到末尾；orchestrator 不会再次添加。
 	InjectedMessages []provider.Message
+
+	// Model 非空时覆盖 session 的模型（例如预算触发的降级）。
+	Model string
+}
+
+// model 返回本次运行使用的模型：显式覆盖优先，否则为 session 模型。
+func (r *RunRequest) model() string {
+	if r.Model != "" {
+		return r.Model
+	}
+	if r.CachedSession != nil && r.CachedSession.Session != nil {
+		return r.CachedSession.Session.Model
+	}
+	return ""
 }
 
 // Config 控制循环行为
@@ -10,10 +10,16 @@ import (
 	"sync"
 	"time"
 
+	"mote/internal/artifacts"
 	internalChannel "mote/internal/channel"
+	"mote/internal/channel/email"
 	"mote/internal/channel/imessage"
+	"mote/internal/channel/matrix"
 	"mote/internal/channel/notes"
 	"mote/internal/channel/reminders"
+	"mote/internal/channel/slack"
+	"mote/internal/channel/telegram"
+	"mote/internal/channel/webhook"
 	"mote/internal/compaction"
 	"mote/internal/config"
 	internalContext "mote/internal/context"
@@ -31,7 +37,10 @@ import (
 	"mote/internal/runner/types"
 	"mote/internal/scheduler"
 	"mote/internal/skills"
+	"mote/internal/storage"
 	"mote/internal/tools"
+	"mote/internal/tools/retrieval"
+	"mote/internal/usage"
 	"mote/pkg/channel"
 
 	"github.com/google/uuid"
@@ -67,6 +76,12 @@ type Runner struct {
 	// Multi-agent direct delegation
 	delegateFactory *delegate.SubRunnerFactory
 
+	// Delegation tracker, also records main agent turns for session traces (nil = disabled)
+	delegateTracker *delegate.DelegationTracker
+
+	// Usage ledger and budget enforcement (nil = disabled)
+	usageMeter *usage.Meter
+
 	// M08B+: Block message template and circuit breaker
 	blockMessageTemplate    string
 	circuitBreakerThreshold int
@@ -79,8 +94,19 @@ type Runner struct {
 	// MCP integration
 	mcpManager *client.Manager
 
+	// Tool retrieval: when set, runs start with the catalog's core tools and
+	// search_tools instead of the whole registry
+	toolCatalog *retrieval.Catalog
+
+	// Artifact offload: tool results larger than artifactThreshold are
+	// stored in the session's artifact store and replaced with a stub
+	artifacts         *artifacts.Store
+	artifactThreshold int
+	artifactPreview   int
+
 	// Channel system integration
 	channelRegistry *internalChannel.Registry
+	channelRouter   *internalChannel.Router
 
 	// Pause control
 	pauseController PauseController
@@ -168,6 +194,14 @@ func (r *Runner) UpdateDefaultModel(model string) {
 	slog.Info("Runner.UpdateDefaultModel", "model", model)
 }
 
+// DelegateFactory returns the sub-agent factory, or nil if delegate support
+// has not been initialized.
+func (r *Runner) DelegateFactory() *delegate.SubRunnerFactory {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.delegateFactory
+}
+
 // ResetSession performs a full resource cleanup for a session.
 // This is called when model/workspace changes require rebuilding all runtime state.
 // It clears: 1) session cache, 2) provider session state (ACP sessions, CLI mappings).
@@ -396,6 +430,37 @@ func (r *Runner) SetMCPManager(m *client.Manager) {
 	}
 }
 
+// SetToolCatalog enables tool retrieval mode for runs started afterwards.
+func (r *Runner) SetToolCatalog(c *retrieval.Catalog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.toolCatalog = c
+}
+
+// SetArtifactStore enables offloading of tool results larger than threshold
+// bytes. The model sees the first preview bytes and a recall_artifact
+// handle instead of a truncated result.
+func (r *Runner) SetArtifactStore(store *artifacts.Store, threshold, preview int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.artifacts = store
+	r.artifactThreshold = threshold
+	r.artifactPreview = preview
+}
+
+// SetUsageMeter enables usage recording and budget enforcement for the main
+// agent and, once delegate support is initialized, for sub-agents.
+func (r *Runner) SetUsageMeter(m *usage.Meter) {
+	r.mu.Lock()
+	r.usageMeter = m
+	factory := r.delegateFactory
+	r.mu.Unlock()
+
+	if factory != nil {
+		factory.SetUsageMeter(m)
+	}
+}
+
 // InitDelegateSupport initializes multi-agent delegation if agents are configured.
 // It creates the SubRunnerFactory and registers the delegate tool.
 // The manage_agents tool is always registered so the LLM can create agents even when none exist.
@@ -420,6 +485,7 @@ func (r *Runner) InitDelegateSupport(appCfg *config.Config, db *sql.DB) (*delega
 	skillMgr := r.skillManager
 	defModel := r.defaultModel
 	cfg := r.config
+	meter := r.usageMeter
 	r.mu.RUnlock()
 
 	if multiPool == nil {
@@ -452,6 +518,9 @@ func (r *Runner) InitDelegateSupport(appCfg *config.Config, db *sql.DB) (*delega
 		factory.SetTracker(tracker)
 		slog.Info("delegate: tracker initialized for audit logging")
 	}
+	if meter != nil {
+		factory.SetUsageMeter(meter)
+	}
 
 	globalMaxDepth := appCfg.Delegate.GetMaxDepth()
 
@@ -463,6 +532,7 @@ func (r *Runner) InitDelegateSupport(appCfg *config.Config, db *sql.DB) (*delega
 	// Store factory on runner for direct delegate support (@ mentions)
 	r.mu.Lock()
 	r.delegateFactory = factory
+	r.delegateTracker = tracker
 	r.mu.Unlock()
 
 	numAgents := len(appCfg.Agents)
@@ -479,6 +549,7 @@ func (r *Runner) InitChannels(cfg config.ChannelsConfig) error {
 	defer r.mu.Unlock()
 
 	r.channelRegistry = internalChannel.NewRegistry()
+	r.channelRouter = r.newChannelRouter(cfg.Routing)
 
 	// iMessage
 	if cfg.IMessage.Enabled {
@@ -547,9 +618,192 @@ func (r *Runner) InitChannels(cfg config.ChannelsConfig) error {
 		slog.Info("registered Apple Reminders channel", "watchList", cfg.AppleReminders.WatchList)
 	}
 
+	// Telegram
+	if cfg.Telegram.Enabled {
+		tgCh := newTelegramChannel(cfg.Telegram)
+		tgCh.OnMessage(r.handleChannelMessage)
+		r.channelRegistry.Register(tgCh)
+		slog.Info("registered Telegram channel", "mode", cfg.Telegram.Mode, "allowFrom", cfg.Telegram.AllowFrom)
+	}
+
+	// Email
+	if cfg.Email.Enabled {
+		emailCh := newEmailChannel(cfg.Email)
+		emailCh.OnMessage(r.handleChannelMessage)
+		r.channelRegistry.Register(emailCh)
+		slog.Info("registered email channel", "imap", cfg.Email.IMAPAddr, "allowFrom", cfg.Email.AllowFrom)
+	}
+
+	// Matrix
+	if cfg.Matrix.Enabled {
+		matrixCh := newMatrixChannel(cfg.Matrix)
+		matrixCh.OnMessage(r.handleChannelMessage)
+		r.channelRegistry.Register(matrixCh)
+		slog.Info("registered Matrix channel", "homeserver", cfg.Matrix.Homeserver, "allowFrom", cfg.Matrix.AllowFrom)
+	}
+
+	// Slack
+	if cfg.Slack.Enabled {
+		slackCh := newSlackChannel(cfg.Slack)
+		slackCh.OnMessage(r.handleChannelMessage)
+		r.channelRegistry.Register(slackCh)
+		slog.Info("registered Slack channel", "mode", cfg.Slack.Mode, "allowFrom", cfg.Slack.AllowFrom)
+	}
+
+	// Webhook
+	if cfg.Webhook.Enabled {
+		webhookCh := newWebhookChannel(cfg.Webhook)
+		webhookCh.OnMessage(r.handleChannelMessage)
+		r.channelRegistry.Register(webhookCh)
+		slog.Info("registered webhook channel", "hooks", len(cfg.Webhook.Hooks))
+	}
+
 	return nil
 }
 
+// newTelegramChannel 根据配置创建 Telegram 渠道
+func newTelegramChannel(cfg config.TelegramConfig) channel.ChannelPlugin {
+	return telegram.New(telegram.Config{
+		Trigger: channel.TriggerConfig{
+			Prefix:        cfg.Trigger.Prefix,
+			CaseSensitive: cfg.Trigger.CaseSensitive,
+			AllowList:     cfg.Trigger.AllowList,
+		},
+		Reply: channel.ReplyConfig{
+			Prefix:    cfg.Reply.Prefix,
+			Separator: cfg.Reply.Separator,
+		},
+		Token:          cfg.Token,
+		BaseURL:        cfg.BaseURL,
+		Mode:           cfg.Mode,
+		PollTimeout:    cfg.PollTimeout,
+		WebhookURL:     cfg.WebhookURL,
+		WebhookListen:  cfg.WebhookListen,
+		WebhookSecret:  cfg.WebhookSecret,
+		RequireMention: cfg.RequireMention,
+		AllowFrom:      cfg.AllowFrom,
+	})
+}
+
+// newEmailChannel 根据配置创建邮件渠道
+func newEmailChannel(cfg config.EmailConfig) channel.ChannelPlugin {
+	return email.New(email.Config{
+		Trigger: channel.TriggerConfig{
+			Prefix:        cfg.Trigger.Prefix,
+			CaseSensitive: cfg.Trigger.CaseSensitive,
+			AllowList:     cfg.Trigger.AllowList,
+		},
+		Reply: channel.ReplyConfig{
+			Prefix:    cfg.Reply.Prefix,
+			Separator: cfg.Reply.Separator,
+		},
+		IMAPAddr:             cfg.IMAPAddr,
+		IMAPTLS:              cfg.IMAPTLS,
+		Username:             cfg.Username,
+		Password:             cfg.Password,
+		Folder:               cfg.Folder,
+		PollInterval:         cfg.PollInterval,
+		UseIdle:              cfg.UseIdle,
+		SMTPAddr:             cfg.SMTPAddr,
+		SMTPTLS:              cfg.SMTPTLS,
+		SMTPUsername:         cfg.SMTPUsername,
+		SMTPPassword:         cfg.SMTPPassword,
+		From:                 cfg.From,
+		AllowFrom:            cfg.AllowFrom,
+		RequireAuthenticated: cfg.RequireAuthenticated,
+		AuthServID:           cfg.AuthServID,
+	})
+}
+
+// newMatrixChannel 根据配置创建 Matrix 渠道
+func newMatrixChannel(cfg config.MatrixConfig) channel.ChannelPlugin {
+	return matrix.New(matrix.Config{
+		Trigger: channel.TriggerConfig{
+			Prefix:        cfg.Trigger.Prefix,
+			CaseSensitive: cfg.Trigger.CaseSensitive,
+			AllowList:     cfg.Trigger.AllowList,
+		},
+		Reply: channel.ReplyConfig{
+			Prefix:    cfg.Reply.Prefix,
+			Separator: cfg.Reply.Separator,
+		},
+		Homeserver:     cfg.Homeserver,
+		AccessToken:    cfg.AccessToken,
+		SyncTimeout:    cfg.SyncTimeout,
+		RequireMention: cfg.RequireMention,
+		AllowFrom:      cfg.AllowFrom,
+		AutoJoin:       cfg.AutoJoin,
+	})
+}
+
+// newSlackChannel 根据配置创建 Slack 渠道
+func newSlackChannel(cfg config.SlackConfig) channel.ChannelPlugin {
+	return slack.New(slack.Config{
+		Trigger: channel.TriggerConfig{
+			Prefix:        cfg.Trigger.Prefix,
+			CaseSensitive: cfg.Trigger.CaseSensitive,
+			AllowList:     cfg.Trigger.AllowList,
+		},
+		Reply: channel.ReplyConfig{
+			Prefix:    cfg.Reply.Prefix,
+			Separator: cfg.Reply.Separator,
+		},
+		BotToken:       cfg.BotToken,
+		AppToken:       cfg.AppToken,
+		BaseURL:        cfg.BaseURL,
+		Mode:           cfg.Mode,
+		SigningSecret:  cfg.SigningSecret,
+		EventsListen:   cfg.EventsListen,
+		EventsPath:     cfg.EventsPath,
+		RequireMention: cfg.RequireMention,
+		AllowFrom:      cfg.AllowFrom,
+	})
+}
+
+// newWebhookChannel 根据配置创建 Webhook 渠道
+func newWebhookChannel(cfg config.WebhookConfig) channel.ChannelPlugin {
+	hooks := make([]webhook.Hook, 0, len(cfg.Hooks))
+	for _, h := range cfg.Hooks {
+		match := make([]webhook.Match, 0, len(h.Match))
+		for _, m := range h.Match {
+			match = append(match, webhook.Match{Path: m.Path, Value: m.Value})
+		}
+		hooks = append(hooks, webhook.Hook{
+			Name:            h.Name,
+			Auth:            h.Auth,
+			Secret:          h.Secret,
+			SignatureHeader: h.SignatureHeader,
+			TokenHeader:     h.TokenHeader,
+			Match:           match,
+			Mapping: webhook.Mapping{
+				Content:         h.Mapping.Content,
+				ContentTemplate: h.Mapping.ContentTemplate,
+				ChatID:          h.Mapping.ChatID,
+				SenderID:        h.Mapping.SenderID,
+				SenderName:      h.Mapping.SenderName,
+				MessageID:       h.Mapping.MessageID,
+			},
+			CallbackURL:      h.CallbackURL,
+			CallbackMethod:   h.CallbackMethod,
+			CallbackHeaders:  h.CallbackHeaders,
+			CallbackTemplate: h.CallbackTemplate,
+		})
+	}
+	return webhook.New(webhook.Config{
+		Trigger: channel.TriggerConfig{
+			Prefix:        cfg.Trigger.Prefix,
+			CaseSensitive: cfg.Trigger.CaseSensitive,
+			AllowList:     cfg.Trigger.AllowList,
+		},
+		Reply: channel.ReplyConfig{
+			Prefix:    cfg.Reply.Prefix,
+			Separator: cfg.Reply.Separator,
+		},
+		Hooks:        hooks,
+		MaxBodyBytes: cfg.MaxBodyBytes,
+	})
+}
+
 // ChannelRegistry 返回渠道注册表
 func (r *Runner) ChannelRegistry() *internalChannel.Registry {
 	r.mu.RLock()
@@ -631,6 +885,58 @@ func (r *Runner) StartChannel(ctx context.Context, channelType channel.ChannelTy
 		slog.Info("registered Apple Reminders channel on-demand")
 		return ch.Start(ctx)
 
+	case channel.ChannelTypeTelegram:
+		ch := newTelegramChannel(config.GetChannelsConfig().Telegram)
+		ch.OnMessage(r.handleChannelMessage)
+		// 先启动再注册：token 缺失或无效时不留下无法工作的渠道
+		if err := ch.Start(ctx); err != nil {
+			return err
+		}
+		r.channelRegistry.Register(ch)
+		slog.Info("registered Telegram channel on-demand")
+		return nil
+
+	case channel.ChannelTypeEmail:
+		ch := newEmailChannel(config.GetChannelsConfig().Email)
+		ch.OnMessage(r.handleChannelMessage)
+		// 先启动再注册：邮箱无法登录或未配置白名单时不注册
+		if err := ch.Start(ctx); err != nil {
+			return err
+		}
+		r.channelRegistry.Register(ch)
+		slog.Info("registered email channel on-demand")
+		return nil
+
+	case channel.ChannelTypeMatrix:
+		ch := newMatrixChannel(config.GetChannelsConfig().Matrix)
+		ch.OnMessage(r.handleChannelMessage)
+		if err := ch.Start(ctx); err != nil {
+			return err
+		}
+		r.channelRegistry.Register(ch)
+		slog.Info("registered Matrix channel on-demand")
+		return nil
+
+	case channel.ChannelTypeSlack:
+		ch := newSlackChannel(config.GetChannelsConfig().Slack)
+		ch.OnMessage(r.handleChannelMessage)
+		if err := ch.Start(ctx); err != nil {
+			return err
+		}
+		r.channelRegistry.Register(ch)
+		slog.Info("registered Slack channel on-demand")
+		return nil
+
+	case channel.ChannelTypeWebhook:
+		ch := newWebhookChannel(config.GetChannelsConfig().Webhook)
+		ch.OnMessage(r.handleChannelMessage)
+		if err := ch.Start(ctx); err != nil {
+			return err
+		}
+		r.channelRegistry.Register(ch)
+		slog.Info("registered webhook channel on-demand")
+		return nil
+
 	default:
 		return fmt.Errorf("unsupported channel type: %s", channelType)
 	}
@@ -666,33 +972,217 @@ func (r *Runner) getChannelModel(channelType channel.ChannelType) string {
 		return cfg.Channels.AppleNotes.Model
 	case channel.ChannelTypeReminders:
 		return cfg.Channels.AppleReminders.Model
+	case channel.ChannelTypeTelegram:
+		return cfg.Channels.Telegram.Model
+	case channel.ChannelTypeEmail:
+		return cfg.Channels.Email.Model
+	case channel.ChannelTypeMatrix:
+		return cfg.Channels.Matrix.Model
+	case channel.ChannelTypeSlack:
+		return cfg.Channels.Slack.Model
+	case channel.ChannelTypeWebhook:
+		return cfg.Channels.Webhook.Model
 	default:
 		return ""
 	}
 }
 
-// handleChannelMessage 处理来自渠道的消息
+// newChannelRouter 根据配置创建渠道消息路由器，会话映射保存在数据库的 KV 表中
+func (r *Runner) newChannelRouter(cfg config.ChannelRoutingConfig) *internalChannel.Router {
+	rules := make([]internalChannel.RouteRule, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		rules = append(rules, internalChannel.RouteRule{
+			Channel:   channel.ChannelType(rule.Channel),
+			ChatID:    rule.ChatID,
+			Agent:     rule.Agent,
+			IdleReset: rule.IdleReset,
+		})
+	}
+	var store internalChannel.SessionStore
+	if r.sessions != nil {
+		if db := r.sessions.DB(); db != nil {
+			store = db
+		}
+	}
+	return internalChannel.NewRouter(internalChannel.RouterConfig{
+		IdleReset:      cfg.IdleReset,
+		ThreadSessions: cfg.ThreadSessions,
+		RateLimit:      cfg.RateLimit,
+		RateWindow:     cfg.RateWindow,
+		QueueSize:      cfg.QueueSize,
+		Rules:          rules,
+	}, store, r.runChannelMessage)
+}
+
+// getChannelRouter 返回渠道消息路由器，未通过 InitChannels 创建时按当前配置创建
+func (r *Runner) getChannelRouter() *internalChannel.Router {
+	r.mu.RLock()
+	router := r.channelRouter
+	r.mu.RUnlock()
+	if router != nil {
+		return router
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.channelRouter == nil {
+		r.channelRouter = r.newChannelRouter(config.GetChannelsConfig().Routing)
+	}
+	return r.channelRouter
+}
+
+// handleChannelMessage 处理来自渠道的消息：路由到会话后运行 agent 并回复。
+// 审批会话中的 "approve <id>" / "reject <id>" 回复直接交给审批管理器，
+// 不进入路由队列，避免等待审批的运行阻塞自己的审批回复。
 func (r *Runner) handleChannelMessage(ctx context.Context, msg channel.InboundMessage) error {
-	// 使用 ChatID 作为 sessionID
-	sessionID := fmt.Sprintf("channel:%s:%s", msg.ChannelType, msg.ChatID)
+	if r.handleApprovalReply(ctx, msg) {
+		return nil
+	}
+	return r.getChannelRouter().Handle(ctx, msg)
+}
+
+// approvalRuleMatcher 可以按记住的审批决定直接批准调用，*approval.Manager 实现了该接口
+type approvalRuleMatcher interface {
+	MatchRule(call *policy.ToolCall) (*approval.Rule, bool)
+}
+
+// approvedByRule 判断调用是否已被记住的审批规则批准。
+// 在发出审批请求事件之前检查，避免为无需询问的调用弹出审批界面。
+func approvedByRule(handler approval.ApprovalHandler, call *policy.ToolCall) bool {
+	matcher, ok := handler.(approvalRuleMatcher)
+	if !ok {
+		return false
+	}
+	rule, ok := matcher.MatchRule(call)
+	if !ok {
+		return false
+	}
+	slog.Info("approval: call approved by remembered rule",
+		"tool", call.Name,
+		"rule", rule.ID,
+		"scope", rule.Scope)
+	return true
+}
+
+// approvalReplier 可以处理渠道中的审批回复，*approval.Manager 实现了该接口
+type approvalReplier interface {
+	HandleReply(text, decidedBy string) (reply string, handled bool)
+}
+
+// handleApprovalReply 处理来自审批通知会话的审批回复，返回消息是否已处理
+func (r *Runner) handleApprovalReply(ctx context.Context, msg channel.InboundMessage) bool {
+	r.mu.RLock()
+	replier, ok := r.approvalManager.(approvalReplier)
+	r.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	cfg := config.GetConfig()
+	if cfg == nil {
+		return false
+	}
+	isChat, isApprover := approvalChatSender(cfg.Approvals.Notifiers, msg.ChannelType, msg.ChatID, msg.SenderID)
+	if !isChat {
+		return false
+	}
+
+	var reply string
+	if isApprover {
+		decidedBy := string(msg.ChannelType) + ":" + msg.SenderID
+		if msg.SenderName != "" {
+			decidedBy = string(msg.ChannelType) + ":" + msg.SenderName
+		}
+		var handled bool
+		if reply, handled = replier.HandleReply(msg.Content, decidedBy); !handled {
+			return false
+		}
+		slog.Info("approval reply received from channel", "channelType", msg.ChannelType, "chatID", msg.ChatID, "by", decidedBy)
+	} else {
+		// 非审批人发出的审批命令直接拒绝，也不进入会话路由
+		if _, _, _, isCommand := approval.ParseReply(msg.Content); !isCommand {
+			return false
+		}
+		slog.Warn("approval reply from a sender not in approvers, ignored",
+			"channelType", msg.ChannelType, "chatID", msg.ChatID, "senderID", msg.SenderID)
+		reply = "You are not allowed to decide approval requests."
+	}
+
+	outbound := channel.OutboundMessage{
+		ChannelType: msg.ChannelType,
+		ChatID:      msg.ChatID,
+		Content:     reply,
+		ReplyToID:   msg.ID,
+	}
+	if err := r.SendChannelMessage(ctx, outbound); err != nil {
+		slog.Warn("failed to acknowledge approval reply", "error", err)
+	}
+	return true
+}
+
+// approvalChatSender 判断会话是否配置为审批通知会话，以及发送者是否在该会话的审批人列表中。
+// 只有审批人在审批会话中的回复可以审批。
+func approvalChatSender(notifiers []config.ApprovalNotifierConfig, channelType channel.ChannelType, chatID, senderID string) (isChat, isApprover bool) {
+	for _, n := range notifiers {
+		if n.Type != "channel" || channel.ChannelType(n.Channel) != channelType || n.ChatID != chatID {
+			continue
+		}
+		isChat = true
+		for _, approver := range n.Approvers {
+			if senderID != "" && approver == senderID {
+				return true, true
+			}
+		}
+	}
+	return isChat, false
+}
+
+// SendChannelMessage 通过已注册的渠道发送一条消息
+func (r *Runner) SendChannelMessage(ctx context.Context, msg channel.OutboundMessage) error {
+	r.mu.RLock()
+	registry := r.channelRegistry
+	r.mu.RUnlock()
+
+	if registry == nil {
+		return fmt.Errorf("channel registry not initialized")
+	}
+	plugin, ok := registry.Get(msg.ChannelType)
+	if !ok {
+		return fmt.Errorf("channel not found: %s", msg.ChannelType)
+	}
+	return plugin.SendMessage(ctx, msg)
+}
+
+// runChannelMessage 在路由到的会话中运行 agent，并把回复发回渠道
+func (r *Runner) runChannelMessage(ctx context.Context, route internalChannel.Route, msg channel.InboundMessage) error {
+	sessionID := route.SessionID
 
 	slog.Info("handling channel message",
 		"channelType", msg.ChannelType,
 		"chatID", msg.ChatID,
 		"senderID", msg.SenderID,
+		"sessionID", sessionID,
+		"agent", route.Agent,
 		"contentLen", len(msg.Content),
 	)
 
 	// 解析 per-channel 模型配置
 	channelModel := r.getChannelModel(msg.ChannelType)
 
-	// 运行 agent（使用渠道专属模型或默认模型）
+	// 渠道下载的图片、文件等附件
+	attachments, _ := msg.Metadata[internalChannel.AttachmentsKey].([]provider.Attachment)
+
+	// 运行 agent（路由指定的子 Agent、渠道专属模型或默认模型）
 	var events <-chan Event
 	var err error
-	if channelModel != "" {
-		events, err = r.RunWithModel(ctx, sessionID, msg.Content, channelModel, "channel")
+	if route.Agent != "" {
+		if len(attachments) > 0 {
+			slog.Warn("channel attachments are not passed to sub-agents", "agent", route.Agent, "count", len(attachments))
+		}
+		events, err = r.RunDirectDelegate(ctx, sessionID, route.Agent, msg.Content)
+	} else if channelModel != "" {
+		events, err = r.RunWithModel(ctx, sessionID, msg.Content, channelModel, "channel", attachments...)
 	} else {
-		events, err = r.Run(ctx, sessionID, msg.Content)
+		events, err = r.Run(ctx, sessionID, msg.Content, attachments...)
 	}
 	if err != nil {
 		slog.Error("failed to run agent for channel message", "error", err)
@@ -1083,15 +1573,21 @@ func (r *Runner) runLoopWithModel(ctx context.Context, sessionID, userInput, mod
 		effectiveModel = cached.Session.Model
 	}
 
+	// Apply usage budgets (may stop the run or downgrade the model)
+	runModel, ok := r.applyBudget(sessionID, effectiveModel, events)
+	if !ok {
+		return
+	}
+
 	// Get provider for this model
-	prov, err := r.GetProvider(effectiveModel)
+	prov, err := r.GetProvider(runModel)
 	if err != nil {
 		events <- NewErrorEvent(fmt.Errorf("failed to get provider: %w", err))
 		return
 	}
 
 	// Continue with the rest of the run loop (reuse the common logic)
-	r.runLoopCoreWithOrchestrator(ctx, cached, sessionID, userInput, attachments, prov, events)
+	r.runLoopCoreWithOrchestrator(ctx, cached, sessionID, userInput, attachments, prov, modelOverride(runModel, effectiveModel), events)
 }
 
 // runLoop is the main agent execution loop.
@@ -1108,8 +1604,12 @@ func (r *Runner) runLoop(ctx context.Context, sessionID, userInput string, attac
 	if cached.Session != nil {
 		sessionModel = cached.Session.Model
 	}
-	slog.Debug("runLoop getting provider", "sessionID", sessionID, "sessionModel", sessionModel)
-	prov, err := r.GetProvider(sessionModel)
+	runModel, ok := r.applyBudget(sessionID, sessionModel, events)
+	if !ok {
+		return
+	}
+	slog.Debug("runLoop getting provider", "sessionID", sessionID, "sessionModel", sessionModel, "runModel", runModel)
+	prov, err := r.GetProvider(runModel)
 	if err != nil {
 		events <- NewErrorEvent(fmt.Errorf("failed to get provider: %w", err))
 		return
@@ -1117,11 +1617,64 @@ func (r *Runner) runLoop(ctx context.Context, sessionID, userInput string, attac
 	slog.Debug("runLoop got provider", "sessionID", sessionID, "sessionModel", sessionModel, "providerName", prov.Name())
 
 	// Call the core loop with the resolved provider
-	r.runLoopCoreWithOrchestrator(ctx, cached, sessionID, userInput, attachments, prov, events)
+	r.runLoopCoreWithOrchestrator(ctx, cached, sessionID, userInput, attachments, prov, modelOverride(runModel, sessionModel), events)
+}
+
+// applyBudget checks usage budgets before a run of the main agent. It returns
+// the model to run with, which differs from model when a budget downgrades
+// it, and false when a budget stops the run.
+func (r *Runner) applyBudget(sessionID, model string, events chan<- Event) (string, bool) {
+	r.mu.RLock()
+	meter := r.usageMeter
+	defaultModel := r.defaultModel
+	r.mu.RUnlock()
+	if meter == nil {
+		return model, true
+	}
+
+	effective := model
+	if effective == "" {
+		effective = defaultModel
+	}
+	decision := meter.ForAgent("", effective).Admit(sessionID)
+	switch decision.Action {
+	case usage.ActionStop:
+		slog.Warn("runner: usage budget exceeded, refusing run", "sessionID", sessionID, "budget", decision.Budget)
+		events <- NewErrorEvent(fmt.Errorf("%w: %s", orchestrator.ErrBudgetExceeded, decision.Message))
+		return "", false
+	case usage.ActionDowngrade:
+		slog.Info("runner: usage budget downgrading model", "sessionID", sessionID,
+			"budget", decision.Budget, "from", effective, "to", decision.Model)
+		events <- Event{Type: EventTypeContent, Content: "⚠️ " + decision.Message + "\n\n"}
+		return decision.Model, true
+	}
+	return model, true
+}
+
+// meteredModel returns the model usage of a run is recorded under.
+func (r *Runner) meteredModel(cached *scheduler.CachedSession, override string) string {
+	if override != "" {
+		return override
+	}
+	if cached.Session != nil && cached.Session.Model != "" {
+		return cached.Session.Model
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultModel
+}
+
+// modelOverride returns runModel when it differs from the session model, so
+// that a budget downgrade applies to this run without changing the session.
+func modelOverride(runModel, sessionModel string) string {
+	if runModel == sessionModel {
+		return ""
+	}
+	return runModel
 }
 
 // runLoopCoreWithOrchestrator 使用新的模块化 Orchestrator 架构执行核心循环
-func (r *Runner) runLoopCoreWithOrchestrator(ctx context.Context, cached *scheduler.CachedSession, sessionID, userInput string, attachments []provider.Attachment, prov provider.Provider, events chan<- Event) {
+func (r *Runner) runLoopCoreWithOrchestrator(ctx context.Context, cached *scheduler.CachedSession, sessionID, userInput string, attachments []provider.Attachment, prov provider.Provider, model string, events chan<- Event) {
 	// M07: Trigger session_create hook for new sessions
 	if len(cached.Messages) == 0 {
 		hookCtx := hooks.NewContext(hooks.HookSessionCreate)
@@ -1171,8 +1724,22 @@ func (r *Runner) runLoopCoreWithOrchestrator(ctx context.Context, cached *schedu
 	}
 
 	// 创建 Orchestrator builder
-	// Check for PDA checkpoint and inject pda_control tool if one exists
+	// In retrieval mode the run sees the core tools, search_tools and the
+	// tools activated earlier in the session
 	registry := r.registry
+	r.mu.RLock()
+	catalog := r.toolCatalog
+	meter := r.usageMeter
+	r.mu.RUnlock()
+	if catalog != nil {
+		var store *storage.DB
+		if r.sessions != nil {
+			store = r.sessions.DB()
+		}
+		registry = catalog.SessionRegistry(store, sessionID)
+	}
+
+	// Check for PDA checkpoint and inject pda_control tool if one exists
 	systemPromptExtra := ""
 	if r.sessions != nil && r.sessions.DB() != nil {
 		if cp, err := delegate.LoadPDACheckpoint(r.sessions.DB(), sessionID); err == nil && cp != nil {
@@ -1180,7 +1747,7 @@ func (r *Runner) runLoopCoreWithOrchestrator(ctx context.Context, cached *schedu
 				"agent", cp.AgentName,
 				"interruptStep", cp.InterruptStep,
 				"sessionID", sessionID)
-			registry = r.registry.Clone()
+			registry = registry.Clone()
 
 			// Build a resume function that actually executes the PDA pipeline
 			var resumeFn delegate.PDAResumeFunc
@@ -1231,6 +1798,7 @@ func (r *Runner) runLoopCoreWithOrchestrator(ctx context.Context, cached *schedu
 			return r.executeToolsWithSession(ctx, toolCalls, events, sessionID, "", registry)
 		},
 		WorkspaceResolver: r.workspaceResolver,
+		UsageMeter:        meter.ForAgent("", r.meteredModel(cached, model)),
 	})
 
 	// 构建合适的 orchestrator（根据 provider 类型）
@@ -1243,6 +1811,7 @@ func (r *Runner) runLoopCoreWithOrchestrator(ctx context.Context, cached *schedu
 		Attachments:   attachments,
 		Provider:      prov,
 		CachedSession: cached,
+		Model:         model,
 	}
 
 	// 执行 orchestrator
@@ -1251,17 +1820,36 @@ func (r *Runner) runLoopCoreWithOrchestrator(ctx context.Context, cached *schedu
 		"provider", prov.Name(),
 		"orchestratorType", fmt.Sprintf("%T", orch))
 
+	turnStart := time.Now()
 	orchEvents, err := orch.Run(ctx, req)
 	if err != nil {
 		events <- NewErrorEvent(err)
 		return
 	}
 
+	// Record the main agent's own usage and latency for the session trace
+	var turnUsage *Usage
+	var turnErr string
+	defer func() {
+		r.recordTurn(sessionID, r.meteredModel(cached, model), turnStart, turnUsage, turnErr)
+	}()
+
 	// 转发所有事件，转换为 runner.Event
 	// Use context-aware send to prevent goroutine deadlock when
 	// the downstream consumer (HTTP handler) disconnects.
 	for event := range orchEvents {
 		re := FromTypesEvent(event)
+		if re.AgentName == "" {
+			switch re.Type {
+			case EventTypeDone:
+				turnUsage = re.Usage
+			case EventTypeError:
+				turnErr = re.ErrorMsg
+				if re.Error != nil {
+					turnErr = re.Error.Error()
+				}
+			}
+		}
 		if re.Type == EventTypeThinking {
 			slog.Debug("runner: forwarding thinking event to chat handler",
 				"sessionID", sessionID,
@@ -1281,6 +1869,38 @@ func (r *Runner) runLoopCoreWithOrchestrator(ctx context.Context, cached *schedu
 	}
 }
 
+// recordTurn stores a main agent turn as a span of the session trace.
+func (r *Runner) recordTurn(sessionID, model string, start time.Time, u *Usage, errMsg string) {
+	r.mu.RLock()
+	tracker := r.delegateTracker
+	r.mu.RUnlock()
+	if tracker == nil {
+		return
+	}
+	span := delegate.TraceSpan{
+		ID:           uuid.New().String(),
+		SessionID:    sessionID,
+		Kind:         delegate.TraceKindTurn,
+		Name:         "turn",
+		Model:        model,
+		Status:       "completed",
+		StartedAt:    start,
+		EndedAt:      time.Now(),
+		ErrorMessage: errMsg,
+	}
+	if errMsg != "" {
+		span.Status = "failed"
+	}
+	if u != nil {
+		span.PromptTokens = u.PromptTokens
+		span.CompletionTokens = u.CompletionTokens
+		span.TotalTokens = u.TotalTokens
+	}
+	if err := tracker.RecordSpan(span); err != nil {
+		slog.Warn("runner: failed to record turn span", "sessionID", sessionID, "error", err)
+	}
+}
+
 // executeToolsWithSession executes tool calls with session context for policy checks.
 // It sends heartbeat events every 15 seconds during long-running tool executions to keep the connection alive.
 // Returns the tool result messages and the count of tool executions that returned errors.
@@ -1295,6 +1915,11 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 	var results []provider.Message
 	errorCount := 0
 
+	// SetApprovalManager may run concurrently; use one snapshot for the batch
+	r.mu.RLock()
+	approvalManager := r.approvalManager
+	r.mu.RUnlock()
+
 	// Start heartbeat goroutine to keep connection alive during tool execution
 	heartbeatCtx, cancelHeartbeat := context.WithCancel(ctx)
 	defer cancelHeartbeat()
@@ -1357,6 +1982,7 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 					Role:       provider.RoleTool,
 					Content:    errMsg,
 					ToolCallID: tc.ID,
+					IsError:    true,
 				})
 				events <- NewToolResultEvent(tc.ID, toolName, errMsg, true, 0)
 				continue
@@ -1383,6 +2009,7 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 					Role:       provider.RoleTool,
 					Content:    "Policy check failed: " + err.Error(),
 					ToolCallID: tc.ID,
+					IsError:    true,
 				})
 				events <- NewToolResultEvent(tc.ID, toolName, "Policy error: "+err.Error(), true, 0)
 				continue
@@ -1399,18 +2026,32 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 					Role:       provider.RoleTool,
 					Content:    blockMsg,
 					ToolCallID: tc.ID,
+					IsError:    true,
 				})
 				events <- NewToolResultEvent(tc.ID, toolName, "Blocked: "+policyResult.Reason, true, 0)
 				continue
 			}
 
+			// A remembered decision (session or workspace scope) approves
+			// the call without asking again
+			if policyResult.RequireApproval && approvedByRule(approvalManager, &policy.ToolCall{
+				Name:          toolName,
+				Arguments:     args,
+				SessionID:     sessionID,
+				AgentID:       agentID,
+				WorkspacePath: wsPath,
+			}) {
+				policyResult.RequireApproval = false
+			}
+
 			if policyResult.RequireApproval {
 				// Needs approval
-				if r.approvalManager == nil {
+				if approvalManager == nil {
 					results = append(results, provider.Message{
 						Role:       provider.RoleTool,
 						Content:    "Tool call requires approval but no approval manager configured",
 						ToolCallID: tc.ID,
+						IsError:    true,
 					})
 					events <- NewToolResultEvent(tc.ID, toolName, "Approval manager not configured", true, 0)
 					continue
@@ -1423,17 +2064,18 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 
 				// Request approval — push SSE event so chat page can show approval UI
 				approvalCall := &policy.ToolCall{
-					Name:      toolName,
-					Arguments: args,
-					SessionID: sessionID,
-					AgentID:   agentID,
-					RequestID: approvalID,
+					Name:          toolName,
+					Arguments:     args,
+					SessionID:     sessionID,
+					AgentID:       agentID,
+					RequestID:     approvalID,
+					WorkspacePath: wsPath,
 				}
 
 				// Push approval_request event to SSE stream before blocking
 				// The frontend will show an approval modal. The approval manager
 				// also broadcasts via WebSocket for other listeners.
-				events <- NewApprovalRequestEvent(
+				approvalEvent := NewApprovalRequestEvent(
 					approvalID,
 					toolName,
 					args,
@@ -1441,14 +2083,17 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 					sessionID,
 					approvalExpiresAt,
 				)
+				approvalEvent.ApprovalRequest.WorkspacePath = wsPath
+				events <- approvalEvent
 
-				approvalResult, err := r.approvalManager.RequestApproval(ctx, approvalCall, policyResult.ApprovalReason)
+				approvalResult, err := approvalManager.RequestApproval(ctx, approvalCall, policyResult.ApprovalReason)
 				if err != nil {
 					events <- NewApprovalResolvedEvent(approvalID, false, time.Now().Format(time.RFC3339))
 					results = append(results, provider.Message{
 						Role:       provider.RoleTool,
 						Content:    "Approval request failed: " + err.Error(),
 						ToolCallID: tc.ID,
+						IsError:    true,
 					})
 					events <- NewToolResultEvent(tc.ID, toolName, "Approval failed: "+err.Error(), true, 0)
 					continue
@@ -1466,6 +2111,7 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 						Role:       provider.RoleTool,
 						Content:    "Tool call rejected: " + approvalResult.Message,
 						ToolCallID: tc.ID,
+						IsError:    true,
 					})
 					events <- NewToolResultEvent(tc.ID, toolName, "Rejected: "+approvalResult.Message, true, 0)
 					continue
@@ -1499,14 +2145,23 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 				Role:       provider.RoleTool,
 				Content:    "Tool call blocked by policy",
 				ToolCallID: tc.ID,
+				IsError:    true,
 			})
 			events <- NewToolResultEvent(tc.ID, toolName, "Tool call blocked by policy", true, 0)
 			continue
 		}
 
+		// Forward progress reported by the tool, e.g. MCP progress notifications
+		toolCtx := tools.WithProgress(tools.WithToolCallID(ctx, tc.ID), func(message string) {
+			select {
+			case events <- NewToolProgressEvent(tc.ID, toolName, message):
+			default:
+			}
+		})
+
 		// Execute tool
 		start := time.Now()
-		result, err := effectiveRegistry.Execute(ctx, toolName, argsMap)
+		result, err := effectiveRegistry.Execute(toolCtx, toolName, argsMap)
 		duration := time.Since(start)
 
 		var output string
@@ -1521,6 +2176,12 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 			isError = result.IsError
 		}
 
+		// Offload large results to the artifact store so the model can page
+		// them back in, instead of losing everything past the truncation point
+		if offloaded, ok := r.offloadToolResult(sessionID, toolName, output, isError); ok {
+			output = offloaded
+		}
+
 		// Pre-truncate oversized tool results before storing in message history
 		maxBytes := DefaultMaxToolResultBytes
 		if len(output) > maxBytes {
@@ -1553,6 +2214,7 @@ func (r *Runner) executeToolsWithSession(ctx context.Context, toolCalls []provid
 			Role:       provider.RoleTool,
 			Content:    output,
 			ToolCallID: tc.ID,
+			IsError:    isError,
 		})
 
 		if isError {
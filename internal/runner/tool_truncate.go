@@ -2,7 +2,10 @@ package runner
 
 import (
 	"fmt"
+	"log/slog"
 	"regexp"
+
+	"mote/internal/artifacts"
 )
 
 const (
@@ -73,3 +76,31 @@ func stripHexBlobs(s string) string {
 		return fmt.Sprintf("[hex data removed, %d bytes]", len(match))
 	})
 }
+
+// offloadToolResult stores an oversized tool result as an artifact and
+// returns the stub that replaces it. Credentials are scrubbed before the
+// content is stored. Returns false when offloading is disabled, the result
+// is small or an error, or storing fails (the caller then truncates).
+func (r *Runner) offloadToolResult(sessionID, toolName, output string, isError bool) (string, bool) {
+	r.mu.RLock()
+	store, threshold, preview := r.artifacts, r.artifactThreshold, r.artifactPreview
+	r.mu.RUnlock()
+	if store == nil || threshold <= 0 || len(output) <= threshold || isError || sessionID == "" {
+		return "", false
+	}
+	// Paging through an artifact must not create another one
+	if toolName == artifacts.RecallToolName {
+		return "", false
+	}
+
+	content := ScrubCredentials(output, r.compiledScrubRules...)
+	stub, err := store.Offload(sessionID, toolName, content, preview)
+	if err != nil {
+		slog.Warn("offloadToolResult: failed to store artifact, truncating instead",
+			"tool", toolName, "sessionID", sessionID, "error", err)
+		return "", false
+	}
+	slog.Info("offloadToolResult: stored oversized tool result as artifact",
+		"tool", toolName, "bytes", len(content), "stubBytes", len(stub))
+	return stub, true
+}
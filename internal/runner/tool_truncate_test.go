@@ -1,8 +1,12 @@
 package runner
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"mote/internal/artifacts"
+	"mote/internal/storage"
 )
 
 func TestTruncateToolResult_Small(t *testing.T) {
@@ -88,3 +92,42 @@ func TestTruncateToolResult_HeadTailSplit(t *testing.T) {
 		t.Fatal("expected truncation marker")
 	}
 }
+
+func TestOffloadToolResult(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "offload.db"))
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	defer db.Close()
+	sess, err := db.CreateSession(nil)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	r := NewRunner(nil, nil, nil, DefaultConfig())
+	output := strings.Repeat("x", 5000)
+
+	// Disabled until a store is set
+	if _, ok := r.offloadToolResult(sess.ID, "read_file", output, false); ok {
+		t.Fatal("offload should be disabled without a store")
+	}
+
+	r.SetArtifactStore(artifacts.NewStore(db.DB), 1000, 100)
+	if _, ok := r.offloadToolResult(sess.ID, "read_file", "small", false); ok {
+		t.Error("results under the threshold should stay inline")
+	}
+	if _, ok := r.offloadToolResult(sess.ID, "read_file", output, true); ok {
+		t.Error("error results should stay inline")
+	}
+	if _, ok := r.offloadToolResult(sess.ID, artifacts.RecallToolName, output, false); ok {
+		t.Error("recall_artifact results should not be offloaded again")
+	}
+
+	stub, ok := r.offloadToolResult(sess.ID, "read_file", output, false)
+	if !ok {
+		t.Fatal("large result should be offloaded")
+	}
+	if len(stub) > 500 || !strings.Contains(stub, "art_") {
+		t.Errorf("unexpected stub (%d bytes): %s", len(stub), stub)
+	}
+}
@@ -55,6 +55,7 @@ type ToolCallUpdateEvent struct {
 	ToolName   string `json:"tool_name"`
 	Status     string `json:"status"` // e.g., "started", "running", "completed"
 	Arguments  string `json:"arguments,omitempty"`
+	Message    string `json:"message,omitempty"` // progress or log line while running
 }
 
 // ToolResultEvent represents the result of a tool execution.
@@ -154,6 +154,24 @@ func (m *SessionManager) AddMessage(sessionID, role, content string, toolCalls [
 	return msg, nil
 }
 
+// AddToolResult adds a tool result message, recording whether it was an error.
+func (m *SessionManager) AddToolResult(sessionID, content, toolCallID string, isError bool) (*storage.Message, error) {
+	msg, err := m.db.AppendToolResult(sessionID, content, toolCallID, isError)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	if cached, ok := m.cache[sessionID]; ok {
+		cached.Messages = append(cached.Messages, msg)
+		cached.LastAccess = time.Now()
+		cached.Dirty = true
+	}
+	m.mu.Unlock()
+
+	return msg, nil
+}
+
 // GetMessages returns all messages for a session.
 // Returns cached messages if available, otherwise loads from database.
 func (m *SessionManager) GetMessages(sessionID string) ([]*storage.Message, error) {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -14,20 +15,28 @@ import (
 	"github.com/spf13/viper"
 
 	v1 "mote/api/v1"
+	"mote/internal/artifacts"
 	"mote/internal/cli/defaults"
 	"mote/internal/compaction"
 	"mote/internal/config"
 	internalContext "mote/internal/context"
 	"mote/internal/cron"
+	"mote/internal/egress"
+	"mote/internal/eval"
 	"mote/internal/gateway"
 	"mote/internal/gateway/websocket"
 	"mote/internal/hooks"
 	hooksbuiltin "mote/internal/hooks/builtin"
 	"mote/internal/jsvm"
 	"mote/internal/mcp/client"
+	"mote/internal/mcp/host"
+	"mote/internal/mcp/oauth"
+	mcpserver "mote/internal/mcp/server"
+	"mote/internal/mcp/transport"
 	"mote/internal/memory"
 	"mote/internal/policy"
 	"mote/internal/policy/approval"
+	"mote/internal/procmgr"
 	"mote/internal/prompt"
 	"mote/internal/prompts"
 	"mote/internal/provider"
@@ -37,12 +46,16 @@ import (
 	"mote/internal/provider/ollama"
 	"mote/internal/provider/vllm"
 	"mote/internal/runner"
+	"mote/internal/runner/delegate"
 	"mote/internal/scheduler"
 	"mote/internal/skills"
 	"mote/internal/storage"
 	"mote/internal/tools"
 	"mote/internal/tools/builtin"
+	"mote/internal/tools/retrieval"
+	"mote/internal/usage"
 	"mote/internal/workspace"
+	"mote/pkg/channel"
 
 	"github.com/rs/zerolog"
 )
@@ -58,6 +71,9 @@ type Server struct {
 	toolRegistry     *tools.Registry             // Tool registry for ACP bridge
 	workspaceManager *workspace.WorkspaceManager // Workspace manager for session bindings
 	skillManager     *skills.Manager             // Skill manager for skills prompt injection
+	processManager   *procmgr.BackgroundManager  // Background processes and shells started by agents
+	egressProxy      *egress.Proxy               // Filters network access of processes started by tools
+	memoryIndex      *memory.MemoryIndex         // Memory index, nil when memory failed to initialize
 	ctx              context.Context
 	cancel           context.CancelFunc
 	running          bool
@@ -156,6 +172,13 @@ func (s *Server) run() {
 	}
 	s.db = db
 
+	// Eval runs left running by a previous process can never finish
+	if n, err := eval.NewStore(db.DB).FailStaleRuns(); err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to mark stale eval runs as failed")
+	} else if n > 0 {
+		s.logger.Info().Int64("count", n).Msg("Marked stale eval runs as failed")
+	}
+
 	// Initialize WebSocket hub
 	hub := websocket.NewHub()
 
@@ -523,9 +546,56 @@ func (s *Server) run() {
 		s.logger.Debug().Msg("ACP provider cache cleared for toolRegistry injection")
 	}
 
+	// Background processes and persistent shells for agents
+	processManager := procmgr.NewBackgroundManager()
+	s.processManager = processManager
+	builtin.SetProcessManager(processManager)
+	if err := builtin.RegisterProcessTools(toolRegistry); err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to register process tools")
+	}
+
+	// Oversized tool results are stored as session artifacts and read back
+	// with recall_artifact instead of being truncated
+	var artifactStore *artifacts.Store
+	if s.cfg.Tools.Artifacts.Enabled {
+		artifactStore = artifacts.NewStore(db.DB)
+		if err := toolRegistry.Register(artifacts.NewRecallTool(artifactStore)); err != nil {
+			s.logger.Warn().Err(err).Msg("Failed to register recall_artifact tool")
+		}
+	}
+
+	// Initialize policy system
+	policyConfig := loadPolicyConfig(s.cfg.Policy.File, s.logger)
+	policyExecutor := policy.NewPolicyExecutor(&policyConfig.ToolPolicy)
+	if s.cfg.Policy.DecisionLog != "" {
+		logPath, _ := config.ExpandPath(s.cfg.Policy.DecisionLog)
+		if decisionLog, err := policy.NewFileDecisionLog(logPath); err != nil {
+			s.logger.Warn().Err(err).Msg("Failed to open policy decision log")
+		} else {
+			policyExecutor.SetDecisionLogger(decisionLog)
+		}
+	}
+
+	// Outbound connections of tools and scripts are checked against the
+	// policy's egress rules; processes started by tools reach the network
+	// through a local filtering proxy
+	egressGuard := policy.NewEgressGuard(policyExecutor)
+	egressProxy := egress.NewProxy(egressGuard)
+	if addr := s.cfg.Policy.EgressProxy; addr != "" {
+		if err := egressProxy.Start(addr); err != nil {
+			s.logger.Warn().Err(err).Msg("Failed to start egress proxy, processes will not be filtered")
+		} else {
+			s.egressProxy = egressProxy
+			s.logger.Info().Str("addr", egressProxy.Addr()).Msg("Egress proxy started")
+		}
+	}
+	builtin.SetEgress(egressGuard, egressProxy)
+	tools.SetProcessEnv(builtin.EgressEnv, builtin.EgressDone)
+
 	// Initialize JSVM Runtime
 	jsvmLogger := zerolog.New(zerolog.NewConsoleWriter()).With().Timestamp().Logger()
 	jsvmRuntime := jsvm.NewRuntime(jsvm.DefaultRuntimeConfig(), db, jsvmLogger)
+	jsvmRuntime.SetEgressGuard(egressGuard)
 
 	// Initialize MCP client manager
 	mcpManager := client.NewManager(nil)
@@ -534,6 +604,35 @@ func (s *Server) run() {
 		s.logger.Warn().Err(err).Msg("Failed to register MCP tools")
 	}
 
+	// Serve sampling, elicitation and roots requests from MCP servers.
+	// Set before connecting so servers see the matching capabilities.
+	mcpHost := host.New(s.cfg.MCP.Client)
+	mcpHost.SetProviderPool(multiPool, chatModel)
+	mcpManager.SetRequestHandler(mcpHost)
+	// Each start of a stdio server gets its own proxy token; the token of
+	// the process it replaces is released.
+	var mcpEgressMu sync.Mutex
+	mcpEgressTokens := make(map[string]string)
+	mcpManager.SetEnvFunc(func(name string) []string {
+		env, token := egressProxy.ProcessEnv(policy.EgressRequest{Tool: "mcp:" + name})
+		mcpEgressMu.Lock()
+		egressProxy.Release(mcpEgressTokens[name])
+		mcpEgressTokens[name] = token
+		mcpEgressMu.Unlock()
+		return env
+	})
+
+	// OAuth for remote MCP servers; tokens are kept in the secret store.
+	var mcpAuth *oauth.Authenticator
+	if secretsPath, err := config.DefaultSecretsPath(); err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to resolve secrets path, MCP OAuth disabled")
+	} else {
+		mcpAuth = oauth.NewAuthenticator(config.NewFileSecretStore(secretsPath))
+		mcpManager.SetAuthorizers(func(name string) transport.Authorizer {
+			return mcpAuth.TokenSource(name)
+		})
+	}
+
 	// Load saved MCP servers from config file
 	if err := v1.LoadSavedMCPServers(s.ctx, mcpManager); err != nil {
 		s.logger.Warn().Err(err).Msg("Failed to load saved MCP servers")
@@ -541,24 +640,39 @@ func (s *Server) run() {
 		s.logger.Info().Msg("Loaded saved MCP servers from config")
 	}
 
+	// Ping connected MCP servers and restart the ones that stop responding
+	if hc := s.cfg.MCP.Client.HealthCheck; hc.Enabled {
+		policy := client.DefaultReconnectPolicy()
+		if hc.MaxRestarts > 0 {
+			policy.MaxRetries = hc.MaxRestarts
+		}
+		mcpManager.StartSupervisor(client.SupervisorConfig{
+			Interval:         hc.Interval,
+			PingTimeout:      hc.Timeout,
+			FailureThreshold: hc.FailureThreshold,
+			Policy:           policy,
+		})
+	}
+
 	// Initialize session manager
 	sessionManager := scheduler.NewSessionManager(db, 100)
 
 	// Initialize hooks system
 	hookManager := hooks.NewManager()
 
-	// Initialize policy system
-	policyConfig := policy.DefaultConfig()
-	policyExecutor := policy.NewPolicyExecutor(&policyConfig.ToolPolicy)
-
 	// Initialize approval manager
 	hubAdapter := &hubBroadcaster{hub: hub}
 	approvalNotifier := approval.NewNotifier(hubAdapter)
+	approvalLinks := approval.NewLinkSigner(approvalPublicURL(s.cfg), nil)
 	approvalManager := approval.NewManager(&approval.ManagerConfig{
 		Notifier:   approvalNotifier,
-		Timeout:    300 * time.Second,
+		Timeout:    s.cfg.Approvals.Timeout,
 		MaxPending: policyConfig.Approval.MaxPending,
+		Links:      approvalLinks,
+		Rules:      approval.NewRuleSet(db, s.cfg.Approvals.SessionRuleTTL, s.cfg.Approvals.WorkspaceRuleTTL),
 	})
+	mcpHost.SetPolicyChecker(policyExecutor)
+	mcpHost.SetApprover(approvalManager)
 
 	// Initialize runner
 	runnerConfig := runner.Config{
@@ -608,6 +722,19 @@ func (s *Server) run() {
 		WithAgents(agentInfos)
 	agentRunner.SetSystemPrompt(systemPromptBuilder)
 
+	// Tool retrieval: expose core tools plus search_tools instead of the
+	// whole registry and every MCP tool
+	if rc := s.cfg.Tools.Retrieval; rc.Enabled {
+		toolCatalog := retrieval.NewCatalog(toolRegistry, mcpManager, rc.Core, rc.MaxResults)
+		agentRunner.SetToolCatalog(toolCatalog)
+		systemPromptBuilder.WithToolCatalog(toolCatalog)
+		s.logger.Info().Strs("core", rc.Core).Msg("Tool retrieval mode enabled")
+	}
+	if artifactStore != nil {
+		ac := s.cfg.Tools.Artifacts
+		agentRunner.SetArtifactStore(artifactStore, ac.Threshold, ac.Preview)
+	}
+
 	// Initialize compactor
 	// The compactor's default provider is used as a fallback only. At runtime,
 	// CompactWithFallback receives the session's active provider so that
@@ -685,6 +812,20 @@ func (s *Server) run() {
 		}
 	}
 
+	// Out-of-band approval notifiers (channel chats and push services)
+	if notifiers := s.cfg.Approvals.Notifiers; len(notifiers) > 0 {
+		approvalManager.SetEscalations(newApprovalEscalations(notifiers, agentRunner, approvalLinks, s.logger))
+	}
+
+	// Usage ledger and budgets (applies to the main agent and sub-agents)
+	if err := usage.ValidateBudgets(s.cfg.Usage.Budgets); err != nil {
+		s.logger.Warn().Err(err).Msg("Invalid usage budget configuration, budgets disabled")
+		s.cfg.Usage.Budgets = nil
+	}
+	usageMeter := usage.NewMeter(usage.NewLedger(db.DB), s.cfg.Usage)
+	agentRunner.SetUsageMeter(usageMeter)
+	mcpHost.SetUsageMeter(usageMeter)
+
 	// Initialize multi-agent delegate support
 	delegateTracker, delegateFactory := agentRunner.InitDelegateSupport(s.cfg, db.DB)
 	if delegateTracker != nil {
@@ -699,6 +840,10 @@ func (s *Server) run() {
 	s.gatewayServer.SetAgentRunner(agentRunner)
 	s.gatewayServer.SetToolRegistry(toolRegistry)
 	s.gatewayServer.SetMCPClient(mcpManager)
+	if mcpAuth != nil {
+		s.gatewayServer.SetMCPAuthenticator(mcpAuth)
+	}
+	s.gatewayServer.SetProcessManager(processManager)
 	s.gatewayServer.SetPolicyExecutor(policyExecutor)
 	s.gatewayServer.SetApprovalManager(approvalManager)
 	s.gatewayServer.SetSkillManager(skillManager)
@@ -741,10 +886,22 @@ func (s *Server) run() {
 		return ""
 	}
 	agentRunner.SetWorkspaceResolver(wsResolver)
+	mcpHost.SetWorkspaceResolver(wsResolver)
 	if delegateFactory != nil {
 		delegateFactory.SetWorkspaceResolver(wsResolver)
 	}
 
+	// File tools and mote.fs resolve paths and check them against the
+	// policy's file access scopes and read-only workspace bindings
+	fileGuard := policy.NewFileGuard(policyExecutor, func(sessionID string) (string, bool, bool) {
+		if binding, ok := workspaceManager.Get(sessionID); ok && binding != nil {
+			return binding.Path, binding.ReadOnly, true
+		}
+		return "", false, false
+	})
+	builtin.SetFileGuard(fileGuard)
+	jsvmRuntime.SetFileGuard(fileGuard)
+
 	// Initialize Prompt Manager with file loading support
 	promptsDirs := []string{}
 
@@ -764,6 +921,11 @@ func (s *Server) run() {
 	})
 	s.gatewayServer.SetPromptManager(promptManager)
 
+	// Expose Mote's own capabilities to other MCP hosts at /mcp
+	if s.cfg.MCP.Server.Enabled {
+		s.initializeMCPServer(toolRegistry, delegateFactory, promptManager)
+	}
+
 	// Initialize Cron system
 	s.initializeCron(db, agentRunner, toolRegistry, jsvmRuntime, cronModel)
 
@@ -820,6 +982,14 @@ func (s *Server) Stop() error {
 		}
 	}
 
+	if s.processManager != nil {
+		s.processManager.StopAll()
+	}
+
+	if s.egressProxy != nil {
+		_ = s.egressProxy.Close()
+	}
+
 	if s.db != nil {
 		s.db.Close()
 	}
@@ -1340,6 +1510,7 @@ func (s *Server) initializeMemory(db *storage.DB, agentRunner *runner.Runner, ga
 
 	// Get the legacy MemoryIndex from IndexManager for backward compatibility
 	memoryIndex := memoryManager.GetIndexManager().GetLegacyIndex()
+	s.memoryIndex = memoryIndex
 
 	// Inject dependencies: legacy MemoryIndex for backward compat, MemoryManager for new features
 	agentRunner.SetMemory(memoryIndex)
@@ -1431,6 +1602,46 @@ func (s *Server) initializeMemory(db *storage.DB, agentRunner *runner.Runner, ga
 	}
 }
 
+// initializeMCPServer builds the MCP server that publishes the configured
+// tools, prompts, agents and memory, and hands it to the gateway.
+func (s *Server) initializeMCPServer(toolRegistry *tools.Registry, delegateFactory *delegate.SubRunnerFactory, promptManager *prompts.Manager) {
+	expose := s.cfg.MCP.Server.Expose
+	registry := tools.NewRegistry()
+
+	for _, t := range toolRegistry.List() {
+		if mcpserver.Exposed(expose.Tools, t.Name()) {
+			_ = registry.Register(t)
+		}
+	}
+
+	if delegateFactory != nil {
+		agentRunner := mcpserver.NewFactoryAgentRunner(delegateFactory)
+		for name, ac := range s.cfg.Agents {
+			if !ac.IsEnabled() || !mcpserver.Exposed(expose.Agents, name) {
+				continue
+			}
+			if err := registry.Register(mcpserver.NewAgentTool(name, ac.Description, agentRunner)); err != nil {
+				s.logger.Warn().Err(err).Str("agent", name).Msg("Failed to expose agent over MCP")
+			}
+		}
+	}
+
+	opts := []mcpserver.ServerOption{
+		mcpserver.WithRegistry(registry),
+		mcpserver.WithPrompts(promptManager, expose.Prompts),
+	}
+	if expose.Memory && s.memoryIndex != nil {
+		for _, t := range mcpserver.NewMemoryTools(s.memoryIndex) {
+			_ = registry.Register(t)
+		}
+		opts = append(opts, mcpserver.WithResourceProvider(mcpserver.NewMemoryResourceProvider(s.memoryIndex)))
+	}
+
+	mcpServer := mcpserver.NewServer("mote", "1.0.0", opts...)
+	s.gatewayServer.SetMCPServer(mcpServer)
+	s.logger.Info().Int("tools", registry.Len()).Msg("MCP server enabled at /mcp")
+}
+
 // initializeCron initializes the cron scheduler.
 func (s *Server) initializeCron(db *storage.DB, agentRunner *runner.Runner, toolRegistry *tools.Registry, jsvmRuntime *jsvm.Runtime, cronModel string) {
 	cronJobStore := cron.NewJobStore(db.DB)
@@ -1462,6 +1673,86 @@ func (s *Server) initializeCron(db *storage.DB, agentRunner *runner.Runner, tool
 	}
 }
 
+// approvalPublicURL returns the address used in signed approval links.
+// loadPolicyConfig loads the policy file, falling back to the built-in
+// default policy when it is missing or invalid.
+func loadPolicyConfig(path string, logger zerolog.Logger) *policy.Config {
+	if path == "" {
+		return policy.DefaultConfig()
+	}
+	expanded, err := config.ExpandPath(path)
+	if err != nil {
+		return policy.DefaultConfig()
+	}
+	if _, err := os.Stat(expanded); err != nil {
+		return policy.DefaultConfig()
+	}
+	cfg, err := policy.LoadConfig(expanded)
+	if err != nil {
+		logger.Warn().Err(err).Str("path", expanded).Msg("Invalid policy file, using default policy")
+		return policy.DefaultConfig()
+	}
+	logger.Info().Str("path", expanded).Int("rules", len(cfg.ToolPolicy.Rules)).Int("overlays", len(cfg.ToolPolicy.Overlays)).Msg("Loaded policy file")
+	return cfg
+}
+
+func approvalPublicURL(cfg *config.Config) string {
+	if cfg.Approvals.PublicURL != "" {
+		return cfg.Approvals.PublicURL
+	}
+	host := cfg.Gateway.Host
+	if host == "" || host == "0.0.0.0" {
+		host = "localhost"
+	}
+	return fmt.Sprintf("http://%s:%d", host, cfg.Gateway.Port)
+}
+
+// newApprovalEscalations builds the out-of-band approval notifiers, ordered
+// by their configured delay.
+func newApprovalEscalations(notifiers []config.ApprovalNotifierConfig, r *runner.Runner, links *approval.LinkSigner, logger zerolog.Logger) []approval.Escalation {
+	var escalations []approval.Escalation
+	for _, nc := range notifiers {
+		var notifier approval.ApprovalNotifier
+		switch nc.Type {
+		case "channel":
+			if nc.Channel == "" || nc.ChatID == "" {
+				logger.Warn().Msg("Approval channel notifier needs channel and chat_id, skipped")
+				continue
+			}
+			if len(nc.Approvers) == 0 {
+				logger.Warn().Str("channel", nc.Channel).Str("chat_id", nc.ChatID).
+					Msg("Approval channel notifier has no approvers, replies in the chat cannot approve")
+			}
+			channelType, chatID := channel.ChannelType(nc.Channel), nc.ChatID
+			notifier = approval.NewChannelNotifier(nc.Channel+":"+chatID, func(ctx context.Context, text string) error {
+				return r.SendChannelMessage(ctx, channel.OutboundMessage{
+					ChannelType: channelType,
+					ChatID:      chatID,
+					Content:     text,
+				})
+			}, links)
+		case "push":
+			if nc.URL == "" {
+				logger.Warn().Msg("Approval push notifier needs url, skipped")
+				continue
+			}
+			notifier = approval.NewPushNotifier(approval.PushConfig{
+				URL:      nc.URL,
+				Token:    nc.Token,
+				Priority: nc.Priority,
+			}, links)
+		default:
+			logger.Warn().Str("type", nc.Type).Msg("Unknown approval notifier type, skipped")
+			continue
+		}
+		escalations = append(escalations, approval.Escalation{After: nc.Delay, Notifier: notifier})
+	}
+	sort.SliceStable(escalations, func(i, j int) bool {
+		return escalations[i].After < escalations[j].After
+	})
+	return escalations
+}
+
 // hubBroadcaster adapts websocket.Hub to approval.Broadcaster interface.
 type hubBroadcaster struct {
 	hub *websocket.Hub
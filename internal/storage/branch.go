@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotUserMessage 表示只能编辑用户消息
+var ErrNotUserMessage = errors.New("only user messages can be edited")
+
+// maxBranchDepth 防止 parent_id 成环时无限循环
+const maxBranchDepth = 1000
+
+// SessionBranch 会话分支树中的节点
+type SessionBranch struct {
+	ID            string           `json:"id"`
+	ParentID      string           `json:"parent_id,omitempty"`
+	ForkMessageID string           `json:"fork_message_id,omitempty"` // 父会话中复制到的最后一条消息，空表示从开头分叉
+	Title         string           `json:"title,omitempty"`
+	Model         string           `json:"model,omitempty"`
+	MessageCount  int              `json:"message_count"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	Children      []*SessionBranch `json:"children,omitempty"`
+}
+
+// ForkSession 在指定消息处分叉会话
+// 新会话复制该消息（含）之前的全部历史，以及模型、场景、技能、标题和 metadata。
+// atMessageID 为空时复制全部消息。
+func (db *DB) ForkSession(sourceID, atMessageID string) (*Session, error) {
+	return db.fork(sourceID, atMessageID, true)
+}
+
+// BranchForEdit 为编辑用户消息创建分支
+// 新会话只包含被编辑消息之前的历史；调用方随后以新内容在新会话中重新生成。
+func (db *DB) BranchForEdit(sessionID, messageID string) (*Session, *Message, error) {
+	msg, err := db.GetMessage(messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg.SessionID != sessionID {
+		return nil, nil, ErrNotFound
+	}
+	if msg.Role != "user" {
+		return nil, nil, ErrNotUserMessage
+	}
+	branch, err := db.fork(sessionID, messageID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return branch, msg, nil
+}
+
+// fork 复制会话，inclusive 决定是否包含 messageID 本身
+func (db *DB) fork(sourceID, messageID string, inclusive bool) (*Session, error) {
+	src, err := db.GetSession(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := db.GetMessages(sourceID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("get messages: %w", err)
+	}
+
+	// 截取分叉点之前的消息
+	forkMessageID := ""
+	if messageID != "" {
+		idx := -1
+		for i, m := range messages {
+			if m.ID == messageID {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, ErrNotFound
+		}
+		if inclusive {
+			idx++
+		}
+		messages = messages[:idx]
+	}
+	if len(messages) > 0 {
+		forkMessageID = messages[len(messages)-1].ID
+	}
+
+	var title sql.NullString
+	_ = db.QueryRow("SELECT title FROM sessions WHERE id = ?", sourceID).Scan(&title)
+
+	branch := &Session{
+		ID:             uuid.New().String(),
+		CreatedAt:      time.Now(),
+		Metadata:       src.Metadata,
+		Model:          src.Model,
+		Scenario:       src.Scenario,
+		SelectedSkills: src.SelectedSkills,
+	}
+	branch.UpdatedAt = branch.CreatedAt
+	if len(branch.Metadata) == 0 {
+		branch.Metadata = []byte("{}")
+	}
+
+	err = db.WithTx(func(tx *Tx) error {
+		if _, err := tx.Exec(
+			`INSERT INTO sessions (id, created_at, updated_at, metadata, model, scenario, selected_skills, title, parent_id, fork_message_id)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			branch.ID, branch.CreatedAt, branch.UpdatedAt, string(branch.Metadata), branch.Model, branch.Scenario,
+			formatSelectedSkills(branch.SelectedSkills), title.String, sourceID, forkMessageID,
+		); err != nil {
+			return fmt.Errorf("insert session: %w", err)
+		}
+
+		// 复制消息，保留原时间戳以维持顺序
+		for _, m := range messages {
+			if _, err := tx.Exec(
+				`INSERT INTO messages (id, session_id, role, content, tool_calls, tool_call_id, is_error, created_at)
+				 SELECT ?, ?, role, content, tool_calls, tool_call_id, is_error, created_at FROM messages WHERE id = ?`,
+				uuid.New().String(), branch.ID, m.ID,
+			); err != nil {
+				return fmt.Errorf("copy message: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return branch, nil
+}
+
+// GetSessionTree 返回会话所在分支树（从根会话开始）
+// 父会话被删除后，其子分支各自成为新的根。
+func (db *DB) GetSessionTree(id string) (*SessionBranch, error) {
+	rootID := id
+	seen := map[string]bool{id: true}
+	for i := 0; i < maxBranchDepth; i++ {
+		var parentID string
+		err := db.QueryRow("SELECT COALESCE(parent_id, '') FROM sessions WHERE id = ?", rootID).Scan(&parentID)
+		if errors.Is(err, sql.ErrNoRows) {
+			if rootID == id {
+				return nil, ErrNotFound
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parentID == "" || seen[parentID] {
+			break
+		}
+		var exists int
+		if err := db.QueryRow("SELECT COUNT(*) FROM sessions WHERE id = ?", parentID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists == 0 {
+			break
+		}
+		seen[parentID] = true
+		rootID = parentID
+	}
+
+	rows, err := db.Query(`
+		WITH RECURSIVE tree(id, depth) AS (
+			SELECT id, 0 FROM sessions WHERE id = ?
+			UNION
+			SELECT s.id, t.depth + 1 FROM sessions s JOIN tree t ON s.parent_id = t.id
+			WHERE t.depth < ?
+		)
+		SELECT s.id, COALESCE(s.parent_id, ''), COALESCE(s.fork_message_id, ''), COALESCE(s.title, ''),
+		       COALESCE(s.model, ''), (SELECT COUNT(*) FROM messages m WHERE m.session_id = s.id),
+		       s.created_at, s.updated_at
+		FROM sessions s JOIN tree t ON s.id = t.id
+		ORDER BY s.created_at ASC`, rootID, maxBranchDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := make(map[string]*SessionBranch)
+	var order []*SessionBranch
+	for rows.Next() {
+		var b SessionBranch
+		if err := rows.Scan(&b.ID, &b.ParentID, &b.ForkMessageID, &b.Title, &b.Model,
+			&b.MessageCount, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if _, dup := nodes[b.ID]; dup {
+			continue
+		}
+		nodes[b.ID] = &b
+		order = append(order, &b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	root := nodes[rootID]
+	if root == nil {
+		return nil, ErrNotFound
+	}
+	for _, b := range order {
+		if b.ID == rootID {
+			continue
+		}
+		if parent := nodes[b.ParentID]; parent != nil {
+			parent.Children = append(parent.Children, b)
+		}
+	}
+	return root, nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestForkSession(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	src, _ := db.CreateSession(json.RawMessage(`{"active_tools":["http"]}`), "model", "gpt-4o")
+	if err := db.UpdateSessionSkills(src.ID, []string{"git"}); err != nil {
+		t.Fatalf("UpdateSessionSkills failed: %v", err)
+	}
+	var ids []string
+	for _, m := range []struct{ role, content string }{
+		{"user", "first"}, {"assistant", "one"}, {"user", "second"}, {"assistant", "two"},
+	} {
+		msg, err := db.AppendMessage(src.ID, m.role, m.content, nil, "")
+		if err != nil {
+			t.Fatalf("AppendMessage failed: %v", err)
+		}
+		ids = append(ids, msg.ID)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	branch, err := db.ForkSession(src.ID, ids[1])
+	if err != nil {
+		t.Fatalf("ForkSession failed: %v", err)
+	}
+	got, err := db.GetSession(branch.ID)
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if got.Model != "gpt-4o" || len(got.SelectedSkills) != 1 || string(got.Metadata) != `{"active_tools":["http"]}` {
+		t.Errorf("session settings not copied: %+v", got)
+	}
+	msgs, _ := db.GetMessages(branch.ID, 0)
+	if len(msgs) != 2 || msgs[0].Content != "first" || msgs[1].Content != "one" {
+		t.Fatalf("fork should copy messages up to and including the fork point, got %d", len(msgs))
+	}
+	if msgs[0].ID == ids[0] {
+		t.Error("copied messages need new IDs")
+	}
+
+	// Editing "second" branches off with the history before it
+	edit, orig, err := db.BranchForEdit(src.ID, ids[2])
+	if err != nil {
+		t.Fatalf("BranchForEdit failed: %v", err)
+	}
+	if orig.Content != "second" {
+		t.Errorf("original message = %q", orig.Content)
+	}
+	if n, _ := db.CountMessages(edit.ID); n != 2 {
+		t.Errorf("edit branch has %d messages, want 2", n)
+	}
+	if _, _, err := db.BranchForEdit(src.ID, ids[3]); !errors.Is(err, ErrNotUserMessage) {
+		t.Errorf("editing an assistant message: err = %v", err)
+	}
+	if _, err := db.ForkSession(src.ID, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("fork at unknown message: err = %v", err)
+	}
+
+	// Source is untouched
+	if n, _ := db.CountMessages(src.ID); n != 4 {
+		t.Errorf("source has %d messages, want 4", n)
+	}
+
+	// A fork of a fork; the tree is the same from any node
+	nested, err := db.ForkSession(branch.ID, "")
+	if err != nil {
+		t.Fatalf("nested ForkSession failed: %v", err)
+	}
+	tree, err := db.GetSessionTree(nested.ID)
+	if err != nil {
+		t.Fatalf("GetSessionTree failed: %v", err)
+	}
+	if tree.ID != src.ID || len(tree.Children) != 2 {
+		t.Fatalf("root = %s with %d children, want %s with 2", tree.ID, len(tree.Children), src.ID)
+	}
+	first := tree.Children[0]
+	if first.ID != branch.ID || first.ForkMessageID != ids[1] || first.MessageCount != 2 {
+		t.Errorf("unexpected branch node: %+v", first)
+	}
+	if len(first.Children) != 1 || first.Children[0].ID != nested.ID {
+		t.Errorf("nested fork missing from tree")
+	}
+
+	// Deleting the root makes its branches roots of their own trees
+	if err := db.DeleteSession(src.ID); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	tree, err = db.GetSessionTree(nested.ID)
+	if err != nil {
+		t.Fatalf("GetSessionTree after delete failed: %v", err)
+	}
+	if tree.ID != branch.ID {
+		t.Errorf("root after delete = %s, want %s", tree.ID, branch.ID)
+	}
+}
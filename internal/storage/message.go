@@ -52,53 +52,32 @@ type Message struct {
 	Content    string     `json:"content"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
-	CreatedAt  time.Time  `json:"created_at"`
+	// IsError 标记工具结果消息是否为错误
+	IsError   bool      `json:"is_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // AppendMessage 添加消息
 func (db *DB) AppendMessage(sessionID, role, content string, toolCalls []ToolCall, toolCallID string) (*Message, error) {
-	id := uuid.New().String()
-	now := time.Now()
-
-	var toolCallsJSON *string
-	if len(toolCalls) > 0 {
-		data, err := json.Marshal(toolCalls)
-		if err != nil {
-			return nil, err
-		}
-		s := string(data)
-		toolCallsJSON = &s
-	}
-
-	var toolCallIDPtr *string
-	if toolCallID != "" {
-		toolCallIDPtr = &toolCallID
-	}
-
-	_, err := db.Exec(
-		"INSERT INTO messages (id, session_id, role, content, tool_calls, tool_call_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		id, sessionID, role, content, toolCallsJSON, toolCallIDPtr, now,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	// Update session's updated_at timestamp
-	_, _ = db.Exec("UPDATE sessions SET updated_at = ? WHERE id = ?", now, sessionID)
+	return appendMessage(db.DB, sessionID, role, content, toolCalls, toolCallID, false)
+}
 
-	return &Message{
-		ID:         id,
-		SessionID:  sessionID,
-		Role:       role,
-		Content:    content,
-		ToolCalls:  toolCalls,
-		ToolCallID: toolCallID,
-		CreatedAt:  now,
-	}, nil
+// AppendToolResult 添加工具结果消息，并记录该结果是否为错误
+func (db *DB) AppendToolResult(sessionID, content, toolCallID string, isError bool) (*Message, error) {
+	return appendMessage(db.DB, sessionID, "tool", content, nil, toolCallID, isError)
 }
 
 // AppendMessage 在事务中添加消息
 func (tx *Tx) AppendMessage(sessionID, role, content string, toolCalls []ToolCall, toolCallID string) (*Message, error) {
+	return appendMessage(tx.Tx, sessionID, role, content, toolCalls, toolCallID, false)
+}
+
+// execer 是 *sql.DB 与 *sql.Tx 的公共部分
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func appendMessage(ex execer, sessionID, role, content string, toolCalls []ToolCall, toolCallID string, isError bool) (*Message, error) {
 	id := uuid.New().String()
 	now := time.Now()
 
@@ -117,16 +96,16 @@ func (tx *Tx) AppendMessage(sessionID, role, content string, toolCalls []ToolCal
 		toolCallIDPtr = &toolCallID
 	}
 
-	_, err := tx.Exec(
-		"INSERT INTO messages (id, session_id, role, content, tool_calls, tool_call_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		id, sessionID, role, content, toolCallsJSON, toolCallIDPtr, now,
+	_, err := ex.Exec(
+		"INSERT INTO messages (id, session_id, role, content, tool_calls, tool_call_id, is_error, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		id, sessionID, role, content, toolCallsJSON, toolCallIDPtr, isError, now,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update session's updated_at timestamp
-	_, _ = tx.Exec("UPDATE sessions SET updated_at = ? WHERE id = ?", now, sessionID)
+	_, _ = ex.Exec("UPDATE sessions SET updated_at = ? WHERE id = ?", now, sessionID)
 
 	return &Message{
 		ID:         id,
@@ -135,13 +114,14 @@ func (tx *Tx) AppendMessage(sessionID, role, content string, toolCalls []ToolCal
 		Content:    content,
 		ToolCalls:  toolCalls,
 		ToolCallID: toolCallID,
+		IsError:    isError,
 		CreatedAt:  now,
 	}, nil
 }
 
 // GetMessages 获取会话消息列表
 func (db *DB) GetMessages(sessionID string, limit int) ([]*Message, error) {
-	query := "SELECT id, session_id, role, content, tool_calls, tool_call_id, created_at FROM messages WHERE session_id = ? ORDER BY created_at ASC"
+	query := "SELECT id, session_id, role, content, tool_calls, tool_call_id, is_error, created_at FROM messages WHERE session_id = ? ORDER BY created_at ASC"
 	args := []any{sessionID}
 
 	if limit > 0 {
@@ -161,7 +141,7 @@ func (db *DB) GetMessages(sessionID string, limit int) ([]*Message, error) {
 		var toolCallsJSON sql.NullString
 		var toolCallID sql.NullString
 
-		if err := rows.Scan(&m.ID, &m.SessionID, &m.Role, &m.Content, &toolCallsJSON, &toolCallID, &m.CreatedAt); err != nil {
+		if err := rows.Scan(&m.ID, &m.SessionID, &m.Role, &m.Content, &toolCallsJSON, &toolCallID, &m.IsError, &m.CreatedAt); err != nil {
 			return nil, err
 		}
 
@@ -244,8 +224,8 @@ func (db *DB) ReplaceMessages(sessionID string, messages []*Message) error {
 			}
 
 			if _, err := tx.Exec(
-				"INSERT INTO messages (id, session_id, role, content, tool_calls, tool_call_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-				id, sessionID, msg.Role, msg.Content, toolCallsJSON, toolCallIDPtr, createdAt,
+				"INSERT INTO messages (id, session_id, role, content, tool_calls, tool_call_id, is_error, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+				id, sessionID, msg.Role, msg.Content, toolCallsJSON, toolCallIDPtr, msg.IsError, createdAt,
 			); err != nil {
 				return err
 			}
@@ -264,9 +244,9 @@ func (db *DB) GetMessage(id string) (*Message, error) {
 	var toolCallID sql.NullString
 
 	err := db.QueryRow(
-		"SELECT id, session_id, role, content, tool_calls, tool_call_id, created_at FROM messages WHERE id = ?",
+		"SELECT id, session_id, role, content, tool_calls, tool_call_id, is_error, created_at FROM messages WHERE id = ?",
 		id,
-	).Scan(&m.ID, &m.SessionID, &m.Role, &m.Content, &toolCallsJSON, &toolCallID, &m.CreatedAt)
+	).Scan(&m.ID, &m.SessionID, &m.Role, &m.Content, &toolCallsJSON, &toolCallID, &m.IsError, &m.CreatedAt)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
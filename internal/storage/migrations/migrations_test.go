@@ -28,8 +28,8 @@ func TestRun(t *testing.T) {
 	if err != nil {
 		t.Fatalf("get version: %v", err)
 	}
-	// Currently we have 9 migrations: 001_init.sql through 009_pda_tracking.sql
-	expectedVersion := 9
+	// Currently we have 17 migrations: 001_init.sql through 017_tool_result_errors.sql
+	expectedVersion := 17
 	if version != expectedVersion {
 		t.Errorf("version = %d, want %d", version, expectedVersion)
 	}
@@ -72,7 +72,7 @@ func TestRun_Idempotent(t *testing.T) {
 		t.Fatalf("get version: %v", err)
 	}
 	// Should be the number of migration scripts
-	expectedVersion := 9
+	expectedVersion := 17
 	if version != expectedVersion {
 		t.Errorf("version = %d, want %d", version, expectedVersion)
 	}
@@ -108,7 +108,7 @@ func TestPending(t *testing.T) {
 		t.Fatalf("get pending: %v", err)
 	}
 	// Number of migration scripts
-	expectedPending := 9
+	expectedPending := 17
 	if len(pending) != expectedPending {
 		t.Errorf("pending count = %d, want %d", len(pending), expectedPending)
 	}
@@ -0,0 +1,8 @@
+-- Migration 010: Delegate Trace
+-- Purpose: Record model and token breakdown per delegation so session traces can attribute cost
+
+ALTER TABLE delegate_invocations ADD COLUMN model TEXT NOT NULL DEFAULT '';
+ALTER TABLE delegate_invocations ADD COLUMN prompt_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE delegate_invocations ADD COLUMN completion_tokens INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_delegate_child ON delegate_invocations(child_session_id);
@@ -0,0 +1,33 @@
+-- Migration 011: Agent Evaluation
+-- Purpose: Store eval suite runs and per-case results so pass rates can be tracked over time
+
+CREATE TABLE IF NOT EXISTS eval_runs (
+    id TEXT PRIMARY KEY,
+    suite TEXT NOT NULL,
+    agent TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    agent_version TEXT NOT NULL DEFAULT '',
+    label TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'running',
+    total INTEGER NOT NULL DEFAULT 0,
+    passed INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_eval_runs_suite ON eval_runs(suite, started_at);
+
+CREATE TABLE IF NOT EXISTS eval_results (
+    run_id TEXT NOT NULL,
+    case_name TEXT NOT NULL,
+    passed INTEGER NOT NULL DEFAULT 0,
+    output TEXT NOT NULL DEFAULT '',
+    tool_calls TEXT NOT NULL DEFAULT '[]',
+    failures TEXT NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (run_id, case_name),
+    FOREIGN KEY (run_id) REFERENCES eval_runs(id) ON DELETE CASCADE
+);
@@ -0,0 +1,22 @@
+-- Migration 012: Usage Ledger
+-- Purpose: Provider-agnostic record of tokens and estimated cost for every LLM call
+
+CREATE TABLE IF NOT EXISTS usage_ledger (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NOT NULL,
+    session_id TEXT NOT NULL DEFAULT '',
+    root_session_id TEXT NOT NULL DEFAULT '',
+    agent TEXT NOT NULL DEFAULT '',
+    cron_job TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    cost REAL NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_created ON usage_ledger(created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_root_session ON usage_ledger(root_session_id);
+CREATE INDEX IF NOT EXISTS idx_usage_agent ON usage_ledger(agent, created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_cron ON usage_ledger(cron_job, created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_model ON usage_ledger(model, created_at);
@@ -0,0 +1,16 @@
+-- Migration 013: Tool Result Artifacts
+-- Purpose: Keep oversized tool results out of the context window; the model
+-- pages them back in with recall_artifact
+
+CREATE TABLE IF NOT EXISTS artifacts (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    tool TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    size INTEGER NOT NULL DEFAULT 0,
+    lines INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifacts_session ON artifacts(session_id, created_at);
@@ -0,0 +1,8 @@
+-- Migration 014: Session Branches
+-- Purpose: Record which session a fork was made from and at which message,
+-- so the branches of a conversation form a tree
+
+ALTER TABLE sessions ADD COLUMN parent_id TEXT DEFAULT '';
+ALTER TABLE sessions ADD COLUMN fork_message_id TEXT DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_sessions_parent ON sessions(parent_id);
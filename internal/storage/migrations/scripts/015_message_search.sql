@@ -0,0 +1,30 @@
+-- Migration 015: Message Search
+-- Purpose: Full-text index over message content and tool calls for
+-- searching across all session transcripts. The trigram tokenizer matches
+-- substrings, which also works for Chinese text without word breaks.
+
+CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
+    body,
+    tokenize = 'trigram'
+);
+
+-- Keep the index in step with messages (AppendMessage, ReplaceMessages,
+-- forks and cascading session deletes all go through these)
+CREATE TRIGGER IF NOT EXISTS messages_fts_insert AFTER INSERT ON messages BEGIN
+    INSERT INTO messages_fts (rowid, body)
+    VALUES (new.rowid, COALESCE(new.content, '') || char(10) || COALESCE(new.tool_calls, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_delete AFTER DELETE ON messages BEGIN
+    DELETE FROM messages_fts WHERE rowid = old.rowid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_update AFTER UPDATE OF content, tool_calls ON messages BEGIN
+    DELETE FROM messages_fts WHERE rowid = old.rowid;
+    INSERT INTO messages_fts (rowid, body)
+    VALUES (new.rowid, COALESCE(new.content, '') || char(10) || COALESCE(new.tool_calls, ''));
+END;
+
+-- Index existing transcripts
+INSERT INTO messages_fts (rowid, body)
+SELECT rowid, COALESCE(content, '') || char(10) || COALESCE(tool_calls, '') FROM messages;
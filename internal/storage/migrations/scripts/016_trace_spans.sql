@@ -0,0 +1,25 @@
+-- Migration 016: Trace Spans
+-- Purpose: Link delegations to the tool call that spawned them and record timed spans
+-- (main agent turns, PDA steps) so session traces carry per-node latency and tokens
+
+ALTER TABLE delegate_invocations ADD COLUMN tool_call_id TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS trace_spans (
+    id TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    parent_id TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'completed',
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_trace_spans_session ON trace_spans(session_id);
+CREATE INDEX IF NOT EXISTS idx_trace_spans_parent ON trace_spans(parent_id);
@@ -0,0 +1,6 @@
+-- Migration 017: Tool Result Errors
+-- Purpose: Record whether a tool result message was an error, so traces
+-- report failed tool calls from the tool's own error flag rather than
+-- guessing from the result text
+
+ALTER TABLE messages ADD COLUMN is_error INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultSearchLimit 默认返回的搜索结果数
+	defaultSearchLimit = 20
+	// maxSearchLimit 单次搜索结果上限
+	maxSearchLimit = 200
+	// snippetRunes 片段中匹配位置前后保留的字符数
+	snippetRunes = 60
+	// minTrigramRunes trigram 分词器能匹配的最短词长，更短的词退化为 LIKE
+	minTrigramRunes = 3
+)
+
+// MessageSearchOptions 消息全文搜索条件，零值字段不过滤
+type MessageSearchOptions struct {
+	Query      string
+	Since      time.Time
+	Until      time.Time
+	Model      string   // 会话使用的模型
+	Role       string   // user / assistant / tool
+	SessionIDs []string // 限定会话，如某工作区绑定的会话
+	Limit      int
+}
+
+// MessageSearchResult 消息搜索结果
+type MessageSearchResult struct {
+	MessageID    string    `json:"message_id"`
+	SessionID    string    `json:"session_id"`
+	SessionTitle string    `json:"session_title,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	Role         string    `json:"role"`
+	Snippet      string    `json:"snippet"`
+	CreatedAt    time.Time `json:"created_at"`
+	// AnchorID 是会话中展示该结果的消息：工具结果挂在发起调用的 assistant 消息下
+	AnchorID string `json:"anchor_id"`
+}
+
+// SearchMessages 在所有会话的消息（内容、工具调用参数和工具结果）中全文搜索，按相关度排序
+func (db *DB) SearchMessages(opts MessageSearchOptions) ([]*MessageSearchResult, error) {
+	terms := strings.Fields(opts.Query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	// trigram 只能匹配三个字符以上的词；短词用 LIKE 过滤
+	var ftsTerms, likeTerms []string
+	for _, t := range terms {
+		if utf8.RuneCountInString(t) >= minTrigramRunes {
+			ftsTerms = append(ftsTerms, `"`+strings.ReplaceAll(t, `"`, `""`)+`"`)
+		} else {
+			likeTerms = append(likeTerms, t)
+		}
+	}
+
+	body := "COALESCE(m.content, '') || char(10) || COALESCE(m.tool_calls, '')"
+	var where []string
+	var args []any
+	from := "messages m"
+	order := "m.created_at DESC"
+	if len(ftsTerms) > 0 {
+		from = "messages_fts f JOIN messages m ON m.rowid = f.rowid"
+		where = append(where, "messages_fts MATCH ?")
+		args = append(args, strings.Join(ftsTerms, " AND "))
+		order = "bm25(messages_fts), m.created_at DESC"
+	}
+	for _, t := range likeTerms {
+		where = append(where, body+` LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(t)+"%")
+	}
+	if !opts.Since.IsZero() {
+		where = append(where, "m.created_at >= ?")
+		args = append(args, opts.Since.Local())
+	}
+	if !opts.Until.IsZero() {
+		where = append(where, "m.created_at < ?")
+		args = append(args, opts.Until.Local())
+	}
+	if opts.Role != "" {
+		where = append(where, "m.role = ?")
+		args = append(args, opts.Role)
+	}
+	if opts.Model != "" {
+		where = append(where, "COALESCE(s.model, '') = ?")
+		args = append(args, opts.Model)
+	}
+	if opts.SessionIDs != nil {
+		if len(opts.SessionIDs) == 0 {
+			return nil, nil
+		}
+		where = append(where, "m.session_id IN (?"+strings.Repeat(", ?", len(opts.SessionIDs)-1)+")")
+		for _, id := range opts.SessionIDs {
+			args = append(args, id)
+		}
+	}
+
+	query := `SELECT m.id, m.session_id, COALESCE(s.title, ''), COALESCE(s.model, ''), m.role,
+		       COALESCE(m.content, ''), COALESCE(m.tool_calls, ''), COALESCE(m.tool_call_id, ''), m.created_at
+		FROM ` + from + ` JOIN sessions s ON s.id = m.session_id
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + order + `
+		LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search messages: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*MessageSearchResult
+	for rows.Next() {
+		var r MessageSearchResult
+		var content, toolCalls, toolCallID string
+		if err := rows.Scan(&r.MessageID, &r.SessionID, &r.SessionTitle, &r.Model, &r.Role,
+			&content, &toolCalls, &toolCallID, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		text := content
+		if toolCalls != "" {
+			text += "\n" + toolCalls
+		}
+		r.Snippet = snippet(text, terms)
+		r.AnchorID = r.MessageID
+		if r.Role == "tool" && toolCallID != "" {
+			r.AnchorID = toolCallID // 暂存，查询结束后解析
+		}
+		results = append(results, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 工具结果定位到发起调用的 assistant 消息
+	for _, r := range results {
+		if r.Role != "tool" || r.AnchorID == r.MessageID {
+			continue
+		}
+		var anchor string
+		err := db.QueryRow(
+			`SELECT id FROM messages WHERE session_id = ? AND role = 'assistant' AND tool_calls LIKE ? ESCAPE '\' LIMIT 1`,
+			r.SessionID, `%"`+escapeLike(r.AnchorID)+`"%`,
+		).Scan(&anchor)
+		if err != nil {
+			anchor = r.MessageID
+		}
+		r.AnchorID = anchor
+	}
+	return results, nil
+}
+
+// snippet 截取第一个匹配词附近的文本，匹配处用 ** 标出
+func snippet(text string, terms []string) string {
+	text = strings.Join(strings.Fields(text), " ")
+
+	pos, matchLen := -1, 0
+	for _, t := range terms {
+		if i, n := indexFold(text, t); i >= 0 && (pos < 0 || i < pos) {
+			pos, matchLen = i, n
+		}
+	}
+	if pos < 0 {
+		return truncateRunes(text, 2*snippetRunes)
+	}
+
+	start := pos
+	for n := 0; start > 0 && n < snippetRunes; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:start])
+		start -= size
+	}
+	end := pos + matchLen
+	for n := 0; end < len(text) && n < snippetRunes; n++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	b.WriteString(text[start:pos])
+	b.WriteString("**" + text[pos:pos+matchLen] + "**")
+	b.WriteString(text[pos+matchLen : end])
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// indexFold 按 Unicode 大小写折叠查找 term，返回在 s 中的字节位置和匹配长度。
+// 小写化可能改变字节长度，所以逐个 rune 在原文上比较。
+func indexFold(s, term string) (int, int) {
+	if term == "" {
+		return -1, 0
+	}
+	for i := 0; i < len(s); {
+		if n := prefixFold(s[i:], term); n > 0 {
+			return i, n
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return -1, 0
+}
+
+// prefixFold 返回 s 开头与 term 折叠相等部分的字节长度，不匹配时返回 0
+func prefixFold(s, term string) int {
+	n := 0
+	for _, tr := range term {
+		if n >= len(s) {
+			return 0
+		}
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if r != tr && !strings.EqualFold(string(r), string(tr)) {
+			return 0
+		}
+		n += size
+	}
+	return n
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestSearchMessages(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	a, _ := db.CreateSession(nil, "model", "gpt-4o")
+	b, _ := db.CreateSession(nil, "model", "llama3")
+	_, _ = db.AppendMessage(a.ID, "user", "How do I configure the PostgreSQL connection pool?", nil, "")
+	call, _ := db.AppendMessage(a.ID, "assistant", "", []ToolCall{{
+		ID: "call_1", Type: "function",
+		Function: json.RawMessage(`{"name":"read_file","arguments":"{\"path\":\"db/pool.go\"}"}`),
+	}}, "")
+	toolMsg, _ := db.AppendMessage(a.ID, "tool", "func NewPool(maxConns int) *Pool { ... }", nil, "call_1")
+	_, _ = db.AppendMessage(b.ID, "user", "数据库连接池怎么配置", nil, "")
+
+	search := func(opts MessageSearchOptions) []*MessageSearchResult {
+		t.Helper()
+		res, err := db.SearchMessages(opts)
+		if err != nil {
+			t.Fatalf("SearchMessages(%+v) failed: %v", opts, err)
+		}
+		return res
+	}
+
+	res := search(MessageSearchOptions{Query: "postgresql pool"})
+	if len(res) != 1 || res[0].SessionID != a.ID || !strings.Contains(res[0].Snippet, "**PostgreSQL**") {
+		t.Fatalf("content search: %+v", res)
+	}
+
+	// Tool call arguments are indexed
+	res = search(MessageSearchOptions{Query: "pool.go"})
+	if len(res) != 1 || res[0].MessageID != call.ID {
+		t.Fatalf("tool call search: %+v", res)
+	}
+
+	// Tool results anchor to the assistant message that made the call
+	res = search(MessageSearchOptions{Query: "NewPool", Role: "tool"})
+	if len(res) != 1 || res[0].MessageID != toolMsg.ID || res[0].AnchorID != call.ID {
+		t.Fatalf("tool result search: %+v", res)
+	}
+
+	// CJK and short terms
+	if res := search(MessageSearchOptions{Query: "连接池"}); len(res) != 1 || res[0].SessionID != b.ID {
+		t.Errorf("CJK search: %+v", res)
+	}
+	if res := search(MessageSearchOptions{Query: "池"}); len(res) != 1 {
+		t.Errorf("short term search: %+v", res)
+	}
+
+	// Filters
+	if res := search(MessageSearchOptions{Query: "pool", Model: "llama3"}); len(res) != 0 {
+		t.Errorf("model filter: %+v", res)
+	}
+	if res := search(MessageSearchOptions{Query: "pool", SessionIDs: []string{b.ID}}); len(res) != 0 {
+		t.Errorf("session filter: %+v", res)
+	}
+	if res := search(MessageSearchOptions{Query: "pool", Since: time.Now().Add(time.Hour)}); len(res) != 0 {
+		t.Errorf("since filter: %+v", res)
+	}
+
+	// The index follows deletes
+	if err := db.DeleteSession(a.ID); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if res := search(MessageSearchOptions{Query: "PostgreSQL"}); len(res) != 0 {
+		t.Errorf("deleted session still indexed: %+v", res)
+	}
+}
+
+func TestSnippet_CaseFolding(t *testing.T) {
+	// Ⱥ lowercases to a longer rune, İ to a shorter one.
+	for _, tt := range []struct {
+		text, term, want string
+	}{
+		{"ȺȺȺȺȺȺȺȺȺȺtls", "tls", "ȺȺȺȺȺȺȺȺȺȺ**tls**"},
+		{"İİİİ TLS handshake", "tls", "İİİİ **TLS** handshake"},
+		{"upgrade ȾLS now", "ⱦls", "upgrade **ȾLS** now"},
+	} {
+		got := snippet(tt.text, []string{tt.term})
+		if got != tt.want {
+			t.Errorf("snippet(%q, %q) = %q, want %q", tt.text, tt.term, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("snippet(%q) is not valid UTF-8: %q", tt.text, got)
+		}
+	}
+}
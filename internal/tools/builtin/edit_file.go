@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"mote/internal/policy"
 	"mote/internal/tools"
 )
 
@@ -54,6 +55,12 @@ func (t *EditFileTool) Execute(ctx context.Context, args map[string]any) (tools.
 	default:
 	}
 
+	// Resolve and check the path against the file access policy
+	path, err := guardPath(ctx, t.Name(), path, policy.AccessWrite)
+	if err != nil {
+		return tools.NewErrorResult(err.Error()), nil
+	}
+
 	// Read the file
 	content, err := os.ReadFile(path)
 	if err != nil {
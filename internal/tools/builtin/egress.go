@@ -0,0 +1,113 @@
+package builtin
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"mote/internal/egress"
+	"mote/internal/policy"
+	"mote/internal/procmgr"
+	"mote/internal/tools"
+)
+
+var (
+	// egressGuard checks connections made by the http tool. Nil leaves them
+	// unchecked apart from SSRF protection.
+	egressGuard *policy.EgressGuard
+	// egressProxy is the proxy processes started by tools are routed through.
+	egressProxy *egress.Proxy
+	// processEgressTokens maps background process IDs to their proxy tokens.
+	processEgressTokens sync.Map
+	// shellEgressTokens maps session IDs to the shellEgress of their
+	// persistent shell.
+	shellEgressTokens sync.Map
+)
+
+// shellEgress is the proxy token a persistent shell was started with.
+type shellEgress struct {
+	procID string
+	token  string
+}
+
+// SetEgress sets the egress guard and proxy for builtin tools
+func SetEgress(g *policy.EgressGuard, p *egress.Proxy) {
+	egressGuard = g
+	egressProxy = p
+}
+
+// egressRequest identifies a tool call for the egress policy.
+func egressRequest(ctx context.Context, tool string) policy.EgressRequest {
+	req := policy.EgressRequest{Tool: tool}
+	req.SessionID, _ = tools.SessionIDFromContext(ctx)
+	req.AgentID, _ = tools.AgentIDFromContext(ctx)
+	return req
+}
+
+// EgressEnv returns the environment that routes a process started by tool
+// through the egress proxy and the token to pass to EgressDone, or nil if
+// no egress policy applies.
+func EgressEnv(ctx context.Context, tool string) ([]string, string) {
+	return egressProxy.Env(egressRequest(ctx, tool))
+}
+
+// EgressDone is EgressBlocked for a process that has exited; it releases
+// the token.
+func EgressDone(token string) string {
+	blocked := EgressBlocked(token)
+	egressProxy.Release(token)
+	return blocked
+}
+
+// EgressBlocked describes the connections blocked for token since the
+// last call, or returns "" if there were none.
+func EgressBlocked(token string) string {
+	blocked := egressProxy.TakeBlocked(token)
+	if len(blocked) == 0 {
+		return ""
+	}
+	return "Egress policy blocked:\n  " + strings.Join(blocked, "\n  ")
+}
+
+// processEgressBlocked describes the connections blocked for a background
+// process since the last call. The token is released once the process has
+// exited.
+func processEgressBlocked(id string, exited bool) string {
+	v, ok := processEgressTokens.Load(id)
+	if !ok {
+		return ""
+	}
+	token := v.(string)
+	blocked := EgressBlocked(token)
+	if exited {
+		processEgressTokens.Delete(id)
+		egressProxy.Release(token)
+	}
+	return blocked
+}
+
+// shellEgressToken returns the proxy token of sh, the persistent shell of
+// sessionID. fresh is the token the call offered to Shell: it is kept when
+// sh was just started with it and released otherwise, as is the token of a
+// shell that is gone.
+func shellEgressToken(sessionID string, sh *procmgr.ShellSession, fresh string) string {
+	procID := sh.Process().ID()
+	if v, ok := shellEgressTokens.Load(sessionID); ok {
+		prev := v.(shellEgress)
+		if prev.procID == procID {
+			egressProxy.Release(fresh)
+			return prev.token
+		}
+		egressProxy.Release(prev.token)
+	}
+	shellEgressTokens.Store(sessionID, shellEgress{procID: procID, token: fresh})
+	return fresh
+}
+
+// releaseShellEgress releases the proxy token of the persistent shell of
+// sessionID.
+func releaseShellEgress(sessionID string) {
+	if v, ok := shellEgressTokens.LoadAndDelete(sessionID); ok {
+		egressProxy.Release(v.(shellEgress).token)
+	}
+}
@@ -0,0 +1,32 @@
+package builtin
+
+import (
+	"context"
+
+	"mote/internal/policy"
+	"mote/internal/tools"
+)
+
+// fileGuard checks the paths used by the file tools. Nil leaves them unchecked.
+var fileGuard *policy.FileGuard
+
+// SetFileGuard sets the file access guard for builtin file tools
+func SetFileGuard(g *policy.FileGuard) {
+	fileGuard = g
+}
+
+// guardPath checks a file access by a tool and returns the resolved path to
+// use. Without a guard the path is returned unchanged.
+func guardPath(ctx context.Context, tool, path string, mode policy.AccessMode) (string, error) {
+	if fileGuard == nil {
+		return path, nil
+	}
+	req := policy.FileAccessRequest{
+		Tool: tool,
+		Path: path,
+		Mode: mode,
+	}
+	req.SessionID, _ = tools.SessionIDFromContext(ctx)
+	req.AgentID, _ = tools.AgentIDFromContext(ctx)
+	return fileGuard.Check(req)
+}
@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"mote/internal/policy"
 	"mote/internal/tools"
 )
 
@@ -118,6 +120,10 @@ func (t *HTTPTool) Execute(ctx context.Context, args map[string]any) (tools.Tool
 		if insecure {
 			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // user-requested for self-signed certs
 		}
+		// Check every connection, including redirects, against the egress policy
+		if egressGuard != nil {
+			transport.DialContext = egressGuard.DialContext(egressRequest(ctx, t.Name()))
+		}
 		client = &http.Client{
 			Timeout:   time.Duration(timeout) * time.Second,
 			Transport: transport,
@@ -130,6 +136,10 @@ func (t *HTTPTool) Execute(ctx context.Context, args map[string]any) (tools.Tool
 		if ctx.Err() == context.DeadlineExceeded {
 			return tools.ToolResult{}, tools.NewToolTimeoutError(t.Name(), fmt.Sprintf("%ds", timeout))
 		}
+		var egressErr *policy.EgressError
+		if errors.As(err, &egressErr) {
+			return tools.NewErrorResult(fmt.Sprintf("Egress policy: %v", egressErr)), nil
+		}
 		return tools.NewErrorResult(fmt.Sprintf("request failed: %v", err)), nil
 	}
 	defer resp.Body.Close()
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"mote/internal/policy"
 	"mote/internal/tools"
 )
 
@@ -64,6 +65,12 @@ func (t *ListDirTool) Execute(ctx context.Context, args map[string]any) (tools.T
 	default:
 	}
 
+	// Resolve and check the path against the file access policy
+	path, err := guardPath(ctx, t.Name(), path, policy.AccessRead)
+	if err != nil {
+		return tools.NewErrorResult(err.Error()), nil
+	}
+
 	// Check if path exists and is a directory
 	info, err := os.Stat(path)
 	if err != nil {
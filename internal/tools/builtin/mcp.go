@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	v1 "mote/api/v1"
 	"mote/internal/mcp/client"
@@ -93,7 +94,7 @@ func (t *MCPAddTool) Execute(ctx context.Context, args map[string]any) (tools.To
 		if url == "" {
 			return tools.ToolResult{}, tools.NewInvalidArgsError(t.Name(), "url is required for http type", nil)
 		}
-		config.TransportType = transport.TransportHTTP
+		config.TransportType = transport.TransportStreamableHTTP
 		config.URL = url
 
 		// Parse headers - support both map[string]any and map[string]string
@@ -248,6 +249,31 @@ func (t *MCPListTool) Execute(ctx context.Context, args map[string]any) (tools.T
 				toolInfos = append(toolInfos, toolInfo)
 			}
 			serverInfo["tools"] = toolInfos
+
+			if resources := client.Resources(); len(resources) > 0 {
+				resourceInfos := make([]map[string]any, 0, len(resources))
+				for _, res := range resources {
+					resourceInfo := map[string]any{
+						"uri":  res.URI,
+						"name": res.Name,
+					}
+					if res.Description != "" {
+						resourceInfo["description"] = res.Description
+					}
+					resourceInfos = append(resourceInfos, resourceInfo)
+				}
+				serverInfo["resources"] = resourceInfos
+			}
+			if templates := client.ResourceTemplates(); len(templates) > 0 {
+				templateInfos := make([]map[string]any, 0, len(templates))
+				for _, tmpl := range templates {
+					templateInfos = append(templateInfos, map[string]any{
+						"uri_template": tmpl.URITemplate,
+						"name":         tmpl.Name,
+					})
+				}
+				serverInfo["resource_templates"] = templateInfos
+			}
 		}
 
 		result = append(result, serverInfo)
@@ -367,6 +393,14 @@ func (t *MCPCallTool) Execute(ctx context.Context, args map[string]any) (tools.T
 
 	// Call tool using prefixed name format
 	prefixedName := server + "_" + toolName
+	if sessionID, ok := tools.SessionIDFromContext(ctx); ok {
+		// Lets sampling, elicitation and roots callbacks find the session.
+		ctx = client.WithSessionID(ctx, sessionID)
+	}
+	if report, ok := tools.ProgressFromContext(ctx); ok {
+		// Surface server progress and log messages while the call runs.
+		ctx = client.WithProgressHandler(ctx, func(u client.ProgressUpdate) { report(u.String()) })
+	}
 	result, err := mcpManager.CallTool(ctx, prefixedName, toolArgs)
 	if err != nil {
 		return tools.NewErrorResult(fmt.Sprintf("tool call failed: %v", err)), nil
@@ -521,7 +555,7 @@ func (t *MCPUpdateTool) Execute(ctx context.Context, args map[string]any) (tools
 		// Reconnect with updated config
 		config := client.ClientConfig{
 			Command:       name,
-			TransportType: transport.TransportHTTP,
+			TransportType: transport.TransportStreamableHTTP,
 			URL:           found.URL,
 			Headers:       found.Headers,
 		}
@@ -567,6 +601,70 @@ func (t *MCPUpdateTool) Execute(ctx context.Context, args map[string]any) (tools
 	return tools.NewSuccessResult(string(content)), nil
 }
 
+// MCPReadResourceArgs defines the parameters for the mcp_read_resource tool.
+type MCPReadResourceArgs struct {
+	Server string `json:"server" jsonschema:"description=Name of the MCP server,required"`
+	URI    string `json:"uri" jsonschema:"description=Resource URI as listed by mcp_list (or built from a resource template),required"`
+}
+
+// MCPReadResourceTool reads a resource from an MCP server.
+type MCPReadResourceTool struct {
+	tools.BaseTool
+}
+
+// NewMCPReadResourceTool creates a new mcp_read_resource tool.
+func NewMCPReadResourceTool() *MCPReadResourceTool {
+	return &MCPReadResourceTool{
+		BaseTool: tools.BaseTool{
+			ToolName: "mcp_read_resource",
+			ToolDescription: `Read a resource (file, document, record, ...) exposed by a connected MCP server.
+Use mcp_list to see each server's resources and resource templates, then pass the server name and the resource URI.`,
+			ToolParameters: tools.BuildSchema(MCPReadResourceArgs{}),
+		},
+	}
+}
+
+// Execute reads a resource from an MCP server.
+func (t *MCPReadResourceTool) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	if mcpManager == nil {
+		return tools.NewErrorResult("MCP manager not initialized"), nil
+	}
+
+	server, _ := args["server"].(string)
+	uri, _ := args["uri"].(string)
+	if server == "" || uri == "" {
+		return tools.ToolResult{}, tools.NewInvalidArgsError(t.Name(), "server and uri are required", nil)
+	}
+
+	result, err := mcpManager.ReadResource(ctx, server, uri)
+	if err != nil {
+		return tools.NewErrorResult(fmt.Sprintf("read resource failed: %v", err)), nil
+	}
+	if len(result.Contents) == 0 {
+		return tools.NewSuccessResult("(empty resource)"), nil
+	}
+
+	var b strings.Builder
+	for i, c := range result.Contents {
+		if len(result.Contents) > 1 {
+			if i > 0 {
+				b.WriteString("\n\n")
+			}
+			fmt.Fprintf(&b, "--- %s ---\n", c.URI)
+		}
+		if c.Blob != "" {
+			mimeType := c.MimeType
+			if mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+			fmt.Fprintf(&b, "[binary content: %s, %d bytes base64]", mimeType, len(c.Blob))
+			continue
+		}
+		b.WriteString(c.Text)
+	}
+	return tools.NewSuccessResult(b.String()), nil
+}
+
 // RegisterMCPTools registers all MCP-related tools with the registry.
 func RegisterMCPTools(registry *tools.Registry) error {
 	mcpTools := []tools.Tool{
@@ -575,6 +673,7 @@ func RegisterMCPTools(registry *tools.Registry) error {
 		NewMCPCallTool(),
 		NewMCPRemoveTool(),
 		NewMCPUpdateTool(),
+		NewMCPReadResourceTool(),
 	}
 
 	for _, tool := range mcpTools {
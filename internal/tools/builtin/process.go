@@ -0,0 +1,303 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mote/internal/procmgr"
+	"mote/internal/tools"
+)
+
+// processManager is the global background process manager for tools
+var processManager *procmgr.BackgroundManager
+
+// SetProcessManager sets the background process manager for builtin tools
+func SetProcessManager(m *procmgr.BackgroundManager) {
+	processManager = m
+}
+
+// GetProcessManager returns the background process manager
+func GetProcessManager() *procmgr.BackgroundManager {
+	return processManager
+}
+
+// maxProcessOutput caps the output returned by a single tool call.
+const maxProcessOutput = 64 * 1024
+
+// toolSessionID returns the chat session the tool runs in.
+func toolSessionID(ctx context.Context) string {
+	if id, ok := tools.SessionIDFromContext(ctx); ok && id != "" {
+		return id
+	}
+	return "default"
+}
+
+// tailOutput keeps the end of long output, which is usually the relevant part.
+func tailOutput(s string) string {
+	if len(s) <= maxProcessOutput {
+		return s
+	}
+	return "... (earlier output truncated)\n" + s[len(s)-maxProcessOutput:]
+}
+
+func secondsArg(args map[string]any, key string, def int) time.Duration {
+	if v, ok := args[key].(float64); ok && v >= 0 {
+		return time.Duration(v * float64(time.Second))
+	}
+	return time.Duration(def) * time.Second
+}
+
+// ShellSessionArgs defines the parameters for the shell_session tool.
+type ShellSessionArgs struct {
+	Action  string `json:"action" jsonschema:"description=exec (default): run a command; read: collect more output of a running command; write: send input to the running command; interrupt: send Ctrl-C; close: end the shell"`
+	Command string `json:"command" jsonschema:"description=Command to run (for exec)"`
+	Input   string `json:"input" jsonschema:"description=Text to send (for write). Include a trailing newline to submit a line."`
+	Timeout int    `json:"timeout" jsonschema:"description=Seconds to wait for the command to finish (default: 30 for exec, 10 for read). The command keeps running after the timeout."`
+	WorkDir string `json:"work_dir" jsonschema:"description=Initial working directory when a new shell is started"`
+}
+
+// ShellSessionTool runs commands in a persistent per-session shell.
+type ShellSessionTool struct {
+	tools.BaseTool
+}
+
+// NewShellSessionTool creates a new shell_session tool.
+func NewShellSessionTool() *ShellSessionTool {
+	return &ShellSessionTool{
+		BaseTool: tools.BaseTool{
+			ToolName: "shell_session",
+			ToolDescription: `Run commands in a persistent interactive shell (one per conversation, attached to a terminal).
+Unlike 'shell', the working directory, environment variables and shell state persist between calls, so 'cd' and 'export' work.
+If a command is still running when the timeout expires, its partial output is returned and it keeps running: use action=read to collect more output, action=write to answer prompts, or action=interrupt to stop it.
+For servers or long-running jobs that should run alongside other work, use the 'process' tool instead.`,
+			ToolParameters: tools.BuildSchema(ShellSessionArgs{}),
+		},
+	}
+}
+
+// Execute runs a shell_session action.
+func (t *ShellSessionTool) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	if processManager == nil {
+		return tools.NewErrorResult("process manager not initialized"), nil
+	}
+	sessionID := toolSessionID(ctx)
+	action, _ := args["action"].(string)
+	if action == "" {
+		action = "exec"
+	}
+
+	if action == "close" {
+		if err := processManager.CloseShell(sessionID); err != nil {
+			return tools.NewErrorResult(fmt.Sprintf("failed to close shell: %v", err)), nil
+		}
+		releaseShellEgress(sessionID)
+		return tools.NewSuccessResult("Shell closed."), nil
+	}
+
+	workDir, _ := args["work_dir"].(string)
+	// The shell outlives the call, so it gets a proxy token of its own
+	env, egressToken := egressProxy.ProcessEnv(egressRequest(ctx, t.Name()))
+	shell, err := processManager.Shell(sessionID, workDir, env...)
+	if err != nil {
+		egressProxy.Release(egressToken)
+		return tools.NewErrorResult(err.Error()), nil
+	}
+	egressToken = shellEgressToken(sessionID, shell, egressToken)
+
+	var result procmgr.ShellResult
+	switch action {
+	case "exec":
+		command, _ := args["command"].(string)
+		if command == "" {
+			return tools.ToolResult{}, tools.NewInvalidArgsError(t.Name(), "command is required for exec", nil)
+		}
+		result, err = shell.Exec(command, secondsArg(args, "timeout", 30))
+		if errors.Is(err, procmgr.ErrShellBusy) {
+			return tools.NewErrorResult("The previous command is still running. Use action=read to wait for it, action=write to send input, or action=interrupt to stop it."), nil
+		}
+	case "read":
+		result, err = shell.Read(secondsArg(args, "timeout", 10))
+	case "write":
+		input, _ := args["input"].(string)
+		if input == "" {
+			return tools.ToolResult{}, tools.NewInvalidArgsError(t.Name(), "input is required for write", nil)
+		}
+		if err = shell.Write(input); err == nil {
+			result, err = shell.Read(secondsArg(args, "timeout", 2))
+		}
+	case "interrupt":
+		if err = shell.Interrupt(); err == nil {
+			result, err = shell.Read(secondsArg(args, "timeout", 5))
+		}
+	default:
+		return tools.ToolResult{}, tools.NewInvalidArgsError(t.Name(), "unknown action: "+action, nil)
+	}
+	if err != nil {
+		return tools.NewErrorResult(err.Error()), nil
+	}
+
+	output := tailOutput(result.Output)
+	if output == "" {
+		output = "(no output)"
+	}
+	if blocked := EgressBlocked(egressToken); blocked != "" {
+		return tools.NewErrorResult(output + "\n" + blocked), nil
+	}
+	if !result.Done {
+		return tools.NewSuccessResult(output + "\n[still running — use action=read for more output]"), nil
+	}
+	if result.ExitCode != 0 {
+		return tools.NewErrorResult(fmt.Sprintf("%s\nExit code: %d", output, result.ExitCode)), nil
+	}
+	return tools.NewSuccessResult(output), nil
+}
+
+// ProcessArgs defines the parameters for the process tool.
+type ProcessArgs struct {
+	Action  string `json:"action" jsonschema:"description=start: launch a command in the background; poll: get output produced since the last poll; write: send input; kill: stop the process; list: show this conversation's processes,required"`
+	Command string `json:"command" jsonschema:"description=Command to start (for start)"`
+	ID      string `json:"id" jsonschema:"description=Process ID returned by start (for poll/write/kill)"`
+	Input   string `json:"input" jsonschema:"description=Text to send to the process stdin (for write)"`
+	WorkDir string `json:"work_dir" jsonschema:"description=Working directory (for start)"`
+	Wait    int    `json:"wait" jsonschema:"description=Seconds to wait for new output before returning (for start/poll, default: 2)"`
+	PTY     bool   `json:"pty" jsonschema:"description=Attach the process to a terminal (for start), for programs that only behave interactively on a TTY"`
+}
+
+// ProcessTool manages background processes.
+type ProcessTool struct {
+	tools.BaseTool
+}
+
+// NewProcessTool creates a new process tool.
+func NewProcessTool() *ProcessTool {
+	return &ProcessTool{
+		BaseTool: tools.BaseTool{
+			ToolName: "process",
+			ToolDescription: `Run commands in the background — dev servers, watchers, long builds or tests — and check on them later.
+Start returns a process ID. Poll returns only output produced since the previous poll, so it can be called repeatedly to tail a log.
+Processes keep running across turns and are stopped when the conversation is deleted.`,
+			ToolParameters: tools.BuildSchema(ProcessArgs{}),
+		},
+	}
+}
+
+// Execute runs a process action.
+func (t *ProcessTool) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	if processManager == nil {
+		return tools.NewErrorResult("process manager not initialized"), nil
+	}
+	sessionID := toolSessionID(ctx)
+	action, _ := args["action"].(string)
+
+	if action == "list" {
+		infos := processManager.List(sessionID)
+		if len(infos) == 0 {
+			return tools.NewSuccessResult("No background processes."), nil
+		}
+		var b strings.Builder
+		for _, info := range infos {
+			fmt.Fprintf(&b, "%s  %s  %s", info.ID, describeState(info), info.Command)
+			if info.WorkDir != "" {
+				fmt.Fprintf(&b, "  (in %s)", info.WorkDir)
+			}
+			b.WriteString("\n")
+		}
+		return tools.NewSuccessResult(b.String()), nil
+	}
+
+	if action == "start" {
+		command, _ := args["command"].(string)
+		if command == "" {
+			return tools.ToolResult{}, tools.NewInvalidArgsError(t.Name(), "command is required for start", nil)
+		}
+		workDir, _ := args["work_dir"].(string)
+		pty, _ := args["pty"].(bool)
+		path, cmdArgs := procmgr.ShellCommand(command)
+		// The process outlives the call, so it gets a proxy token of its own
+		env, egressToken := egressProxy.ProcessEnv(egressRequest(ctx, t.Name()))
+		proc, err := processManager.Start(procmgr.BackgroundOptions{
+			SessionID: sessionID,
+			Command:   command,
+			Path:      path,
+			Args:      cmdArgs,
+			WorkDir:   workDir,
+			Env:       env,
+			PTY:       pty,
+		})
+		if err != nil {
+			egressProxy.Release(egressToken)
+			return tools.NewErrorResult(err.Error()), nil
+		}
+		if egressToken != "" {
+			processEgressTokens.Store(proc.ID(), egressToken)
+		}
+		output := proc.PollWait(secondsArg(args, "wait", 2))
+		return processResult(proc, fmt.Sprintf("Started %s (%s)\n%s", proc.ID(), describeState(proc.Info()), outputOrNone(output)), false), nil
+	}
+
+	id, _ := args["id"].(string)
+	if id == "" {
+		return tools.ToolResult{}, tools.NewInvalidArgsError(t.Name(), "id is required for "+action, nil)
+	}
+	proc, ok := processManager.Get(id)
+	if !ok || proc.SessionID() != sessionID {
+		return tools.NewErrorResult("process not found: " + id), nil
+	}
+
+	switch action {
+	case "poll":
+		output := proc.PollWait(secondsArg(args, "wait", 2))
+		return processResult(proc, fmt.Sprintf("%s (%s)\n%s", proc.ID(), describeState(proc.Info()), outputOrNone(output)), false), nil
+	case "write":
+		input, _ := args["input"].(string)
+		if err := proc.Write(input); err != nil {
+			return tools.NewErrorResult(err.Error()), nil
+		}
+		return tools.NewSuccessResult(fmt.Sprintf("Sent %d bytes to %s.", len(input), proc.ID())), nil
+	case "kill":
+		if err := processManager.Kill(id); err != nil {
+			return tools.NewErrorResult(err.Error()), nil
+		}
+		return processResult(proc, fmt.Sprintf("%s (%s)\n%s", proc.ID(), describeState(proc.Info()), outputOrNone(proc.Poll())), true), nil
+	default:
+		return tools.ToolResult{}, tools.NewInvalidArgsError(t.Name(), "unknown action: "+action, nil)
+	}
+}
+
+// processResult returns text as the result for a background process. The
+// call fails if the egress proxy blocked connections for the process since
+// the last check.
+func processResult(proc *procmgr.BackgroundProcess, text string, stopped bool) tools.ToolResult {
+	exited := stopped || proc.Info().ExitCode != nil
+	if blocked := processEgressBlocked(proc.ID(), exited); blocked != "" {
+		return tools.NewErrorResult(text + "\n" + blocked)
+	}
+	return tools.NewSuccessResult(text)
+}
+
+func describeState(info procmgr.ProcessInfo) string {
+	if info.ExitCode != nil {
+		return fmt.Sprintf("%s, exit code %d", info.State, *info.ExitCode)
+	}
+	return fmt.Sprintf("%s, pid %d", info.State, info.PID)
+}
+
+func outputOrNone(output string) string {
+	if output == "" {
+		return "(no new output)"
+	}
+	return tailOutput(output)
+}
+
+// RegisterProcessTools registers the shell_session and process tools.
+func RegisterProcessTools(registry *tools.Registry) error {
+	for _, tool := range []tools.Tool{NewShellSessionTool(), NewProcessTool()} {
+		if err := registry.Register(tool); err != nil {
+			return fmt.Errorf("failed to register %s: %w", tool.Name(), err)
+		}
+	}
+	return nil
+}
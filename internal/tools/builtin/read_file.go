@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"mote/internal/policy"
 	"mote/internal/tools"
 )
 
@@ -60,6 +61,12 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]any) (tools.
 	default:
 	}
 
+	// Resolve and check the path against the file access policy
+	path, err := guardPath(ctx, t.Name(), path, policy.AccessRead)
+	if err != nil {
+		return tools.NewErrorResult(err.Error()), nil
+	}
+
 	// Get file info
 	info, err := os.Stat(path)
 	if err != nil {
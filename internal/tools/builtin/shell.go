@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -32,7 +33,7 @@ func NewShellTool() *ShellTool {
 	return &ShellTool{
 		BaseTool: tools.BaseTool{
 			ToolName:        "shell",
-			ToolDescription: "Execute a shell command and return its output. Use this to run system commands, scripts, or interact with the operating system.",
+			ToolDescription: "Execute a shell command and return its output. Use this to run system commands, scripts, or interact with the operating system. Each call runs in a fresh shell; use shell_session when state (cd, env vars) must persist, or process for long-running commands.",
 			ToolParameters:  tools.BuildSchema(ShellArgs{}),
 		},
 		MaxOutputSize: 1024 * 1024, // 1MB default
@@ -69,6 +70,13 @@ func (t *ShellTool) Execute(ctx context.Context, args map[string]any) (tools.Too
 		cmd.Dir = workDir
 	}
 
+	// Route network access through the egress proxy when a policy applies
+	env, egressToken := egressProxy.Env(egressRequest(ctx, t.Name()))
+	defer egressProxy.Release(egressToken)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -97,11 +105,23 @@ func (t *ShellTool) Execute(ctx context.Context, args map[string]any) (tools.Too
 		result.WriteString(errOutput)
 	}
 
-	if err != nil {
-		if execCtx.Err() == context.DeadlineExceeded {
-			return tools.ToolResult{}, tools.NewToolTimeoutError(t.Name(), fmt.Sprintf("%ds", timeout))
+	if err != nil && execCtx.Err() == context.DeadlineExceeded {
+		return tools.ToolResult{}, tools.NewToolTimeoutError(t.Name(), fmt.Sprintf("%ds", timeout))
+	}
+
+	// Blocked connections fail the call even if the command ignored them
+	if blocked := EgressBlocked(egressToken); blocked != "" {
+		if result.Len() > 0 {
+			result.WriteString("\n")
+		}
+		if err != nil {
+			result.WriteString(fmt.Sprintf("Exit error: %v\n", err))
 		}
+		result.WriteString(blocked)
+		return tools.NewErrorResult(result.String()), nil
+	}
 
+	if err != nil {
 		// Include error info but still return output
 		if result.Len() > 0 {
 			result.WriteString("\n")
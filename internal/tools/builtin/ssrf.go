@@ -6,35 +6,10 @@ import (
 	"net"
 	"net/url"
 	"time"
-)
-
-var (
-	// privateNetworks contains CIDR ranges that should be blocked.
-	privateNetworks = []string{
-		"127.0.0.0/8",    // Loopback
-		"10.0.0.0/8",     // RFC 1918
-		"172.16.0.0/12",  // RFC 1918
-		"192.168.0.0/16", // RFC 1918
-		"169.254.0.0/16", // Link-local
-		"100.64.0.0/10",  // CGNAT
-		"0.0.0.0/8",      // Unspecified
-		"::1/128",        // IPv6 loopback
-		"fc00::/7",       // IPv6 ULA
-		"fe80::/10",      // IPv6 link-local
-	}
 
-	parsedNetworks []*net.IPNet
+	"mote/internal/policy"
 )
 
-func init() {
-	for _, cidr := range privateNetworks {
-		_, network, _ := net.ParseCIDR(cidr)
-		if network != nil {
-			parsedNetworks = append(parsedNetworks, network)
-		}
-	}
-}
-
 // checkSSRF validates that the target URL does not resolve to a private/internal IP.
 func checkSSRF(rawURL string, allowedDomains []string) error {
 	u, err := url.Parse(rawURL)
@@ -85,10 +60,5 @@ func checkSSRF(rawURL string, allowedDomains []string) error {
 
 // isPrivateIP checks if an IP falls within any private/reserved range.
 func isPrivateIP(ip net.IP) bool {
-	for _, network := range parsedNetworks {
-		if network.Contains(ip) {
-			return true
-		}
-	}
-	return false
+	return policy.IsPrivateIP(ip)
 }
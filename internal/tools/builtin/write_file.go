@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"mote/internal/policy"
 	"mote/internal/tools"
 )
 
@@ -60,6 +61,12 @@ func (t *WriteFileTool) Execute(ctx context.Context, args map[string]any) (tools
 	default:
 	}
 
+	// Resolve and check the path against the file access policy
+	path, err := guardPath(ctx, t.Name(), path, policy.AccessWrite)
+	if err != nil {
+		return tools.NewErrorResult(err.Error()), nil
+	}
+
 	// Create parent directories
 	dir := filepath.Dir(path)
 	if dir != "" && dir != "." {
@@ -0,0 +1,166 @@
+package retrieval
+
+import (
+	"log/slog"
+	"path"
+	"sort"
+
+	"mote/internal/mcp/bridge"
+	"mote/internal/mcp/client"
+	"mote/internal/storage"
+	"mote/internal/tools"
+)
+
+// defaultMaxResults is the number of tools search_tools returns by default.
+const defaultMaxResults = 5
+
+// Catalog is the full set of tools available to the model in retrieval
+// mode: the registry's tools plus the tools of every connected MCP server.
+// Only the core tools and search_tools are loaded up front; the rest are
+// found with search_tools and activated per session.
+type Catalog struct {
+	registry   *tools.Registry
+	mcp        *client.Manager
+	core       []string
+	maxResults int
+}
+
+// NewCatalog creates a catalog over registry and mcp. core lists the tools
+// (names or globs) that are always loaded; mcp may be nil.
+func NewCatalog(registry *tools.Registry, mcp *client.Manager, core []string, maxResults int) *Catalog {
+	if maxResults <= 0 {
+		maxResults = defaultMaxResults
+	}
+	return &Catalog{
+		registry:   registry,
+		mcp:        mcp,
+		core:       core,
+		maxResults: maxResults,
+	}
+}
+
+// IsCore reports whether the named tool is always loaded.
+func (c *Catalog) IsCore(name string) bool {
+	if name == SearchToolName {
+		return true
+	}
+	for _, p := range c.core {
+		if p == name {
+			return true
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// all returns every tool in the catalog sorted by name. MCP tools are
+// named server_tool like bridged tools; registry tools win on collisions.
+func (c *Catalog) all() []tools.Tool {
+	var all []tools.Tool
+	seen := make(map[string]bool)
+	if c.registry != nil {
+		for _, t := range c.registry.List() {
+			all = append(all, t)
+			seen[t.Name()] = true
+		}
+	}
+	if c.mcp != nil {
+		for _, s := range c.mcp.ListServers() {
+			if s.State != client.StateConnected {
+				continue
+			}
+			cli, ok := c.mcp.GetClient(s.Name)
+			if !ok {
+				continue
+			}
+			for _, info := range cli.Tools() {
+				adapter := bridge.NewToolAdapter(cli, info)
+				if !seen[adapter.Name()] {
+					all = append(all, adapter)
+					seen[adapter.Name()] = true
+				}
+			}
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name() < all[j].Name() })
+	return all
+}
+
+// Deferred returns the tools that are loaded only on demand.
+func (c *Catalog) Deferred() []tools.Tool {
+	var deferred []tools.Tool
+	for _, t := range c.all() {
+		if !c.IsCore(t.Name()) {
+			deferred = append(deferred, t)
+		}
+	}
+	return deferred
+}
+
+// Lookup finds a tool by name.
+func (c *Catalog) Lookup(name string) (tools.Tool, bool) {
+	if c.registry != nil {
+		if t, ok := c.registry.Get(name); ok {
+			return t, true
+		}
+	}
+	for _, t := range c.all() {
+		if t.Name() == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Search returns up to limit deferred tools matching query, skipping the
+// ones for which skip returns true.
+func (c *Catalog) Search(query string, limit int, skip func(name string) bool) []tools.Tool {
+	if limit <= 0 {
+		limit = c.maxResults
+	}
+	byName := make(map[string]tools.Tool)
+	var docs []Document
+	for _, t := range c.Deferred() {
+		if skip != nil && skip(t.Name()) {
+			continue
+		}
+		byName[t.Name()] = t
+		docs = append(docs, Document{Name: t.Name(), Description: t.Description()})
+	}
+
+	var result []tools.Tool
+	for _, m := range NewIndex(docs).Search(query, limit) {
+		result = append(result, byName[m.Name])
+	}
+	return result
+}
+
+// SessionRegistry builds the registry for a run of the session: the core
+// tools, the tools activated earlier in the session and search_tools.
+// store may be nil, in which case activations last for the run only.
+func (c *Catalog) SessionRegistry(store *storage.DB, sessionID string) *tools.Registry {
+	registry := tools.NewRegistry()
+	for _, t := range c.all() {
+		if c.IsCore(t.Name()) {
+			_ = registry.Register(t)
+		}
+	}
+
+	if store != nil {
+		active, err := LoadActiveTools(store, sessionID)
+		if err != nil {
+			slog.Warn("retrieval: failed to load active tools", "sessionID", sessionID, "error", err)
+		}
+		for _, name := range active {
+			// Tools of disconnected MCP servers come back once they reconnect
+			if t, ok := c.Lookup(name); ok {
+				_ = registry.Register(t)
+			}
+		}
+	}
+
+	_ = registry.Register(NewSearchTool(c, registry, store, sessionID))
+	return registry
+}
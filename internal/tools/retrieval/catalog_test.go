@@ -0,0 +1,96 @@
+package retrieval
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mote/internal/storage"
+	"mote/internal/tools"
+)
+
+type stubTool struct {
+	tools.BaseTool
+}
+
+func (s *stubTool) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	return tools.NewSuccessResult(s.ToolName), nil
+}
+
+func newStubTool(name, description string) tools.Tool {
+	return &stubTool{BaseTool: tools.BaseTool{ToolName: name, ToolDescription: description}}
+}
+
+func newTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	registry := tools.NewRegistry()
+	registry.MustRegister(newStubTool("read_file", "Read a file"))
+	registry.MustRegister(newStubTool("shell", "Run a shell command"))
+	registry.MustRegister(newStubTool("http", "Send an HTTP request to a URL"))
+	registry.MustRegister(newStubTool("cron_add", "Schedule a recurring job"))
+	return NewCatalog(registry, nil, []string{"read_file", "sh*"}, 0)
+}
+
+func TestCatalog_Core(t *testing.T) {
+	c := newTestCatalog(t)
+	for name, want := range map[string]bool{"read_file": true, "shell": true, "http": false, SearchToolName: true} {
+		if got := c.IsCore(name); got != want {
+			t.Errorf("IsCore(%s) = %v, want %v", name, got, want)
+		}
+	}
+	if deferred := c.Deferred(); len(deferred) != 2 || deferred[0].Name() != "cron_add" {
+		t.Errorf("unexpected deferred tools: %v", deferred)
+	}
+}
+
+func TestSearchTool_ActivatesAndPersists(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sess, err := db.CreateSession([]byte(`{"title":"keep me"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestCatalog(t)
+	registry := c.SessionRegistry(db, sess.ID)
+	if _, ok := registry.Get("http"); ok {
+		t.Fatal("deferred tool loaded up front")
+	}
+	if _, ok := registry.Get(SearchToolName); !ok {
+		t.Fatal("search_tools not loaded")
+	}
+
+	search, _ := registry.Get(SearchToolName)
+	res, _ := search.Execute(context.Background(), map[string]any{"query": "http request", "activate": false})
+	if !strings.Contains(res.Content, "http") {
+		t.Fatalf("search result: %s", res.Content)
+	}
+	if _, ok := registry.Get("http"); ok {
+		t.Fatal("tool activated with activate=false")
+	}
+
+	res, _ = search.Execute(context.Background(), map[string]any{"query": "http request"})
+	if res.IsError || !strings.Contains(res.Content, "Activated 1 tool") {
+		t.Fatalf("activation result: %s", res.Content)
+	}
+	if _, ok := registry.Get("http"); !ok {
+		t.Fatal("tool not activated")
+	}
+
+	// A later run of the same session starts with the activated tool
+	if _, ok := c.SessionRegistry(db, sess.ID).Get("http"); !ok {
+		t.Error("activated tool not restored")
+	}
+	active, _ := LoadActiveTools(db, sess.ID)
+	if len(active) != 1 || active[0] != "http" {
+		t.Errorf("active tools = %v", active)
+	}
+	got, _ := db.GetSession(sess.ID)
+	if !strings.Contains(string(got.Metadata), "keep me") {
+		t.Errorf("other metadata lost: %s", got.Metadata)
+	}
+}
@@ -0,0 +1,139 @@
+// Package retrieval lets the model discover tools on demand instead of
+// receiving the whole catalog with every request.
+package retrieval
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// nameWeight counts name tokens this many times, so a query that names
+	// the tool ranks it above tools that only mention the word.
+	nameWeight = 3
+)
+
+// Document is a tool as seen by the search index.
+type Document struct {
+	Name        string
+	Description string
+}
+
+// Match is a search hit.
+type Match struct {
+	Document
+	Score float64
+}
+
+// Index is a BM25 index over tool names and descriptions.
+type Index struct {
+	docs   []Document
+	terms  []map[string]int
+	lens   []int
+	df     map[string]int
+	avgLen float64
+}
+
+// NewIndex indexes docs.
+func NewIndex(docs []Document) *Index {
+	idx := &Index{
+		docs:  docs,
+		terms: make([]map[string]int, len(docs)),
+		lens:  make([]int, len(docs)),
+		df:    make(map[string]int),
+	}
+	total := 0
+	for i, d := range docs {
+		tf := make(map[string]int)
+		for _, t := range tokenize(d.Name) {
+			tf[t] += nameWeight
+			idx.lens[i] += nameWeight
+		}
+		for _, t := range tokenize(d.Description) {
+			tf[t]++
+			idx.lens[i]++
+		}
+		for t := range tf {
+			idx.df[t]++
+		}
+		idx.terms[i] = tf
+		total += idx.lens[i]
+	}
+	if len(docs) > 0 {
+		idx.avgLen = float64(total) / float64(len(docs))
+	}
+	return idx
+}
+
+// Search returns up to limit documents matching query, best first.
+func (idx *Index) Search(query string, limit int) []Match {
+	qterms := tokenize(query)
+	if len(qterms) == 0 || len(idx.docs) == 0 {
+		return nil
+	}
+
+	n := float64(len(idx.docs))
+	var matches []Match
+	for i, tf := range idx.terms {
+		score := 0.0
+		for _, q := range qterms {
+			f := float64(tf[q])
+			if f == 0 {
+				continue
+			}
+			df := float64(idx.df[q])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*float64(idx.lens[i])/idx.avgLen
+			score += idf * f * (bm25K1 + 1) / (f + bm25K1*norm)
+		}
+		if score > 0 {
+			matches = append(matches, Match{Document: idx.docs[i], Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].Name < matches[b].Name
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// tokenize lowercases s and splits it into words, breaking snake_case and
+// camelCase identifiers apart. Han characters become one token each since
+// Chinese text has no spaces.
+func tokenize(s string) []string {
+	var tokens []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if unicode.IsUpper(r) && len(word) > 0 && unicode.IsLower(word[len(word)-1]) {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
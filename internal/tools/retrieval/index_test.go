@@ -0,0 +1,34 @@
+package retrieval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("createIssue on GitHub_repo 创建")
+	want := []string{"create", "issue", "on", "git", "hub", "repo", "创", "建"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize = %q, want %q", got, want)
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex([]Document{
+		{Name: "github_create_issue", Description: "Create a new issue in a GitHub repository"},
+		{Name: "github_list_prs", Description: "List pull requests of a repository"},
+		{Name: "slack_post_message", Description: "Post a message to a Slack channel"},
+		{Name: "jira_create_ticket", Description: "Create a Jira ticket, similar to an issue"},
+	})
+
+	matches := idx.Search("create issue", 2)
+	if len(matches) != 2 || matches[0].Name != "github_create_issue" || matches[1].Name != "jira_create_ticket" {
+		t.Errorf("unexpected ranking: %+v", matches)
+	}
+	if got := idx.Search("slack", 0); len(got) != 1 || got[0].Name != "slack_post_message" {
+		t.Errorf("unexpected result for slack: %+v", got)
+	}
+	if got := idx.Search("kubernetes", 5); len(got) != 0 {
+		t.Errorf("expected no matches, got %+v", got)
+	}
+}
@@ -0,0 +1,115 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"mote/internal/storage"
+	"mote/internal/tools"
+)
+
+// SearchToolName is the name of the meta-tool that finds and activates tools.
+const SearchToolName = "search_tools"
+
+// maxSearchResults caps the limit the model may ask for.
+const maxSearchResults = 20
+
+// maxDescriptionLen truncates tool descriptions in search results.
+const maxDescriptionLen = 200
+
+// SearchTool searches the catalog and activates the matching tools for the
+// rest of the session.
+type SearchTool struct {
+	tools.BaseTool
+	catalog   *Catalog
+	registry  *tools.Registry
+	store     *storage.DB
+	sessionID string
+}
+
+// NewSearchTool creates the search_tools tool. Activated tools are added to
+// registry and, when store is set, remembered in the session's metadata.
+func NewSearchTool(catalog *Catalog, registry *tools.Registry, store *storage.DB, sessionID string) *SearchTool {
+	return &SearchTool{
+		BaseTool: tools.BaseTool{
+			ToolName: SearchToolName,
+			ToolDescription: "Search for tools that are not loaded yet, including tools of connected MCP servers. " +
+				"Describe the capability you need; matching tools are activated and can be called from your next step on.",
+			ToolParameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "Keywords describing the capability you need, e.g. \"create github issue\"",
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"description": fmt.Sprintf("Maximum number of tools to return (default %d)", catalog.maxResults),
+					},
+					"activate": map[string]any{
+						"type":        "boolean",
+						"description": "Activate the matching tools (default true). Set to false to only look.",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+		catalog:   catalog,
+		registry:  registry,
+		store:     store,
+		sessionID: sessionID,
+	}
+}
+
+// Execute searches the catalog.
+func (t *SearchTool) Execute(ctx context.Context, args map[string]any) (tools.ToolResult, error) {
+	query, _ := args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return tools.NewErrorResult("query is required"), nil
+	}
+	limit := 0
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = min(int(v), maxSearchResults)
+	}
+	activate := true
+	if v, ok := args["activate"].(bool); ok {
+		activate = v
+	}
+
+	loaded := func(name string) bool {
+		_, ok := t.registry.Get(name)
+		return ok
+	}
+	found := t.catalog.Search(query, limit, loaded)
+	if len(found) == 0 {
+		return tools.NewSuccessResult("No matching tools found. Try different keywords."), nil
+	}
+
+	var b strings.Builder
+	if activate {
+		var names []string
+		for _, tool := range found {
+			if err := t.registry.Register(tool); err == nil {
+				names = append(names, tool.Name())
+			}
+		}
+		if t.store != nil && len(names) > 0 {
+			if err := AddActiveTools(t.store, t.sessionID, names); err != nil {
+				slog.Warn("retrieval: failed to save active tools", "sessionID", t.sessionID, "error", err)
+			}
+		}
+		fmt.Fprintf(&b, "Activated %d tool(s); they can be called from your next step:\n", len(names))
+	} else {
+		fmt.Fprintf(&b, "Found %d tool(s); call %s with activate=true to use them:\n", len(found), SearchToolName)
+	}
+	for _, tool := range found {
+		desc := strings.Join(strings.Fields(tool.Description()), " ")
+		if r := []rune(desc); len(r) > maxDescriptionLen {
+			desc = string(r[:maxDescriptionLen]) + "..."
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", tool.Name(), desc)
+	}
+	return tools.NewSuccessResult(b.String()), nil
+}
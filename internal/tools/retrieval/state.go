@@ -0,0 +1,82 @@
+package retrieval
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"mote/internal/storage"
+)
+
+// activeToolsKey is the Session.Metadata key holding the activated tools.
+const activeToolsKey = "active_tools"
+
+// LoadActiveTools returns the tools activated in a session.
+func LoadActiveTools(store *storage.DB, sessionID string) ([]string, error) {
+	meta, err := readMetadata(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := meta[activeToolsKey]
+	if !ok {
+		return nil, nil
+	}
+	var names []string
+	if err := json.Unmarshal(raw, &names); err != nil {
+		return nil, fmt.Errorf("decode active tools: %w", err)
+	}
+	return names, nil
+}
+
+// AddActiveTools records tools as activated in a session, keeping the
+// other metadata keys.
+func AddActiveTools(store *storage.DB, sessionID string, names []string) error {
+	meta, err := readMetadata(store, sessionID)
+	if err != nil {
+		return err
+	}
+
+	var active []string
+	if raw, ok := meta[activeToolsKey]; ok {
+		_ = json.Unmarshal(raw, &active)
+	}
+	seen := make(map[string]bool, len(active))
+	for _, n := range active {
+		seen[n] = true
+	}
+	for _, n := range names {
+		if !seen[n] {
+			active = append(active, n)
+			seen[n] = true
+		}
+	}
+
+	data, err := json.Marshal(active)
+	if err != nil {
+		return fmt.Errorf("marshal active tools: %w", err)
+	}
+	meta[activeToolsKey] = data
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+	return store.UpdateSession(sessionID, raw)
+}
+
+// readMetadata decodes the session's metadata into a mutable map.
+func readMetadata(store *storage.DB, sessionID string) (map[string]json.RawMessage, error) {
+	sess, err := store.GetSession(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get session %s: %w", sessionID, err)
+	}
+	meta := make(map[string]json.RawMessage)
+	if len(sess.Metadata) > 0 && string(sess.Metadata) != "null" {
+		if err := json.Unmarshal(sess.Metadata, &meta); err != nil {
+			return nil, fmt.Errorf("decode metadata: %w", err)
+		}
+		if meta == nil {
+			meta = make(map[string]json.RawMessage)
+		}
+	}
+	return meta, nil
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"time"
 )
@@ -186,6 +187,24 @@ func formatJSValue(v any) string {
 	}
 }
 
+var (
+	// processEnv returns extra environment for processes started by script
+	// tools and a token for processBlocked. Nil adds nothing.
+	processEnv func(ctx context.Context, tool string) ([]string, string)
+	// processDone describes what was blocked for a token, or returns "",
+	// once the process has exited.
+	processDone func(token string) string
+)
+
+// SetProcessEnv sets the functions that return extra environment for
+// processes started by script tools, e.g. to route them through a proxy, and
+// describe what the proxy blocked for them once they have exited. done is
+// called for every token env returned. Blocked access fails the call.
+func SetProcessEnv(env func(ctx context.Context, tool string) ([]string, string), done func(token string) string) {
+	processEnv = env
+	processDone = done
+}
+
 // executeShell executes Shell script.
 func (t *ScriptTool) executeShell(ctx context.Context, args map[string]any) (ToolResult, error) {
 	// Determine shell
@@ -204,6 +223,17 @@ func (t *ScriptTool) executeShell(ctx context.Context, args map[string]any) (Too
 		}
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%v", k, v))
 	}
+	var token string
+	if processEnv != nil {
+		var extra []string
+		if extra, token = processEnv(ctx, t.Name()); len(extra) > 0 {
+			// A nil Env inherits the environment; keep it when adding to it
+			if cmd.Env == nil {
+				cmd.Env = os.Environ()
+			}
+			cmd.Env = append(cmd.Env, extra...)
+		}
+	}
 
 	// Capture output
 	var stdout, stderr bytes.Buffer
@@ -212,12 +242,33 @@ func (t *ScriptTool) executeShell(ctx context.Context, args map[string]any) (Too
 
 	// Run command
 	err := cmd.Run()
+	var blocked string
+	if token != "" && processDone != nil {
+		blocked = processDone(token)
+	}
 
 	// Check for context timeout
 	if ctx.Err() == context.DeadlineExceeded {
 		return NewErrorResult("script execution timed out"), nil
 	}
 
+	// Blocked connections fail the call even if the script ignored them
+	if blocked != "" {
+		var msg bytes.Buffer
+		msg.Write(stdout.Bytes())
+		if err != nil {
+			fmt.Fprintf(&msg, "\nshell execution error: %v", err)
+		}
+		if stderr.Len() > 0 {
+			msg.WriteString("\nstderr: " + stderr.String())
+		}
+		if msg.Len() > 0 {
+			msg.WriteString("\n")
+		}
+		msg.WriteString(blocked)
+		return NewErrorResult(msg.String()), nil
+	}
+
 	if err != nil {
 		// Include stderr in error message
 		errMsg := fmt.Sprintf("shell execution error: %v", err)
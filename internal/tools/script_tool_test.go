@@ -210,6 +210,42 @@ func TestScriptTool_ExecuteShell_Error(t *testing.T) {
 	}
 }
 
+func TestScriptTool_ExecuteShell_ProcessEnv(t *testing.T) {
+	blocked := map[string]string{"tok": "Egress policy blocked:\n  egress to 'example.com' blocked: denied [egress:deny]"}
+	SetProcessEnv(func(ctx context.Context, tool string) ([]string, string) {
+		return []string{"PROXY_FOR=" + tool}, "tok"
+	}, func(token string) string {
+		b := blocked[token]
+		delete(blocked, token)
+		return b
+	})
+	defer SetProcessEnv(nil, nil)
+
+	tool := NewScriptTool(ScriptToolConfig{
+		Name:    "fetcher",
+		Runtime: RuntimeShell,
+		Script:  "echo $PROXY_FOR",
+		Timeout: 5 * time.Second,
+	})
+
+	result, err := tool.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected error result for blocked egress")
+	}
+	expected := "fetcher\n\nEgress policy blocked:\n  egress to 'example.com' blocked: denied [egress:deny]"
+	if result.Content != expected {
+		t.Errorf("expected '%s', got '%s'", expected, result.Content)
+	}
+
+	result, _ = tool.Execute(context.Background(), nil)
+	if result.IsError || result.Content != "fetcher\n" {
+		t.Errorf("expected success without blocked connections, got '%s'", result.Content)
+	}
+}
+
 func TestScriptTool_UnsupportedRuntime(t *testing.T) {
 	tool := NewScriptTool(ScriptToolConfig{
 		Name:    "unknown-tool",
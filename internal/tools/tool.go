@@ -12,6 +12,8 @@ type contextKey string
 const (
 	sessionIDKey contextKey = "session_id"
 	agentIDKey   contextKey = "agent_id"
+	progressKey  contextKey = "progress"
+	toolCallKey  contextKey = "tool_call_id"
 )
 
 // WithSessionID returns a new context with the session ID attached.
@@ -36,6 +38,38 @@ func AgentIDFromContext(ctx context.Context) (string, bool) {
 	return id, ok
 }
 
+// WithToolCallID returns a new context with the ID of the tool call being executed.
+func WithToolCallID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, toolCallKey, id)
+}
+
+// ToolCallIDFromContext retrieves the ID of the tool call being executed, if present.
+func ToolCallIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(toolCallKey).(string)
+	return id, ok
+}
+
+// ProgressFunc receives progress messages from a running tool. It must not block.
+type ProgressFunc func(message string)
+
+// WithProgress returns a new context that lets tools report progress to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey, fn)
+}
+
+// ProgressFromContext retrieves the progress reporter from the context, if present.
+func ProgressFromContext(ctx context.Context) (ProgressFunc, bool) {
+	fn, ok := ctx.Value(progressKey).(ProgressFunc)
+	return fn, ok && fn != nil
+}
+
+// ReportProgress reports a progress message if the context carries a reporter.
+func ReportProgress(ctx context.Context, message string) {
+	if fn, ok := ProgressFromContext(ctx); ok {
+		fn(message)
+	}
+}
+
 // Tool defines the interface that all tools must implement.
 // A tool is a capability that an AI agent can invoke to interact with external systems.
 type Tool interface {
@@ -0,0 +1,257 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mote/internal/config"
+)
+
+// Budget scopes.
+const (
+	ScopeSession = "session"
+	ScopeAgent   = "agent"
+	ScopeCron    = "cron"
+	ScopeModel   = "model"
+	ScopeGlobal  = "global"
+)
+
+// Action is what the runner does about a call, ordered by severity.
+type Action string
+
+const (
+	ActionAllow     Action = "allow"
+	ActionWarn      Action = "warn"
+	ActionDowngrade Action = "downgrade"
+	ActionStop      Action = "stop"
+)
+
+func (a Action) severity() int {
+	switch a {
+	case ActionWarn:
+		return 1
+	case ActionDowngrade:
+		return 2
+	case ActionStop:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// defaultSoftLimit is the fraction of a budget at which warnings start.
+const defaultSoftLimit = 0.8
+
+// Scope identifies who is about to make an LLM call.
+type Scope struct {
+	SessionID     string
+	RootSessionID string
+	Agent         string
+	CronJob       string
+	Model         string
+}
+
+// NewScope derives the root session and cron job from sessionID.
+func NewScope(sessionID, agent, model string) Scope {
+	root := RootSession(sessionID)
+	if agent == "" {
+		agent = MainAgent
+	}
+	return Scope{
+		SessionID:     sessionID,
+		RootSessionID: root,
+		Agent:         agent,
+		CronJob:       CronJob(root),
+		Model:         model,
+	}
+}
+
+// MainAgent is the agent name recorded for the main (non-delegate) agent.
+const MainAgent = "main"
+
+// RootSession returns the top-level session of a delegate session ID of the
+// form "delegate:<parent>:<agent>:<unix-ms>", following nested delegations.
+func RootSession(sessionID string) string {
+	for strings.HasPrefix(sessionID, "delegate:") {
+		rest := strings.TrimPrefix(sessionID, "delegate:")
+		parts := strings.Split(rest, ":")
+		if len(parts) < 3 {
+			return sessionID
+		}
+		sessionID = strings.Join(parts[:len(parts)-2], ":")
+	}
+	return sessionID
+}
+
+// CronJob returns the job name for a cron session ID ("cron-<job>"), or "".
+func CronJob(sessionID string) string {
+	if strings.HasPrefix(sessionID, "cron-") {
+		return strings.TrimPrefix(sessionID, "cron-")
+	}
+	return ""
+}
+
+// Decision is the outcome of checking budgets before a call.
+type Decision struct {
+	Action Action `json:"action"`
+	// Model is the model to switch to when Action is downgrade.
+	Model   string `json:"model,omitempty"`
+	Budget  string `json:"budget,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// BudgetStatus reports how much of a budget has been spent for one key.
+type BudgetStatus struct {
+	Budget   config.BudgetConfig `json:"budget"`
+	Key      string              `json:"key,omitempty"`
+	Spent    Totals              `json:"spent"`
+	Fraction float64             `json:"fraction"`
+	State    string              `json:"state"` // ok | soft | exceeded
+}
+
+// applies reports whether b covers scope and returns the ledger filter and
+// the key being budgeted.
+func applies(b config.BudgetConfig, s Scope, now time.Time) (Filter, string, bool) {
+	f := Filter{Since: periodStart(b.Period, now)}
+	switch b.Scope {
+	case ScopeSession:
+		if s.RootSessionID == "" {
+			return f, "", false
+		}
+		f.RootSessionID = s.RootSessionID
+		return f, s.RootSessionID, true
+	case ScopeAgent:
+		if b.Match != "" && b.Match != s.Agent {
+			return f, "", false
+		}
+		f.Agent = s.Agent
+		return f, s.Agent, true
+	case ScopeCron:
+		if s.CronJob == "" || (b.Match != "" && b.Match != s.CronJob) {
+			return f, "", false
+		}
+		f.CronJob = s.CronJob
+		return f, s.CronJob, true
+	case ScopeModel:
+		if b.Match != "" && b.Match != s.Model {
+			return f, "", false
+		}
+		f.Model = s.Model
+		return f, s.Model, true
+	case ScopeGlobal:
+		return f, "", true
+	default:
+		return f, "", false
+	}
+}
+
+// periodStart returns the start of the budget period containing now.
+func periodStart(period string, now time.Time) time.Time {
+	now = now.Local()
+	switch period {
+	case "day":
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	case "month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// status computes how much of b has been spent.
+func status(b config.BudgetConfig, key string, spent Totals) BudgetStatus {
+	st := BudgetStatus{Budget: b, Key: key, Spent: spent, State: "ok"}
+	if b.MaxTokens > 0 {
+		st.Fraction = float64(spent.TotalTokens) / float64(b.MaxTokens)
+	}
+	if b.MaxCost > 0 {
+		if f := spent.Cost / b.MaxCost; f > st.Fraction {
+			st.Fraction = f
+		}
+	}
+	soft := b.SoftLimit
+	if soft <= 0 || soft >= 1 {
+		soft = defaultSoftLimit
+	}
+	switch {
+	case st.Fraction >= 1:
+		st.State = "exceeded"
+	case st.Fraction >= soft:
+		st.State = "soft"
+	}
+	return st
+}
+
+// decide turns a budget status into a decision for a call on model.
+func decide(st BudgetStatus, model string) Decision {
+	b := st.Budget
+	name := b.Name
+	if name == "" {
+		name = b.Scope
+	}
+	spent := describeSpend(st)
+	switch st.State {
+	case "soft":
+		return Decision{Action: ActionWarn, Budget: name,
+			Message: fmt.Sprintf("budget %q is at %.0f%% (%s)", name, st.Fraction*100, spent)}
+	case "exceeded":
+		switch Action(b.Action) {
+		case ActionStop:
+			return Decision{Action: ActionStop, Budget: name,
+				Message: fmt.Sprintf("budget %q exceeded (%s)", name, spent)}
+		case ActionDowngrade:
+			if b.DowngradeModel != "" && b.DowngradeModel != model {
+				return Decision{Action: ActionDowngrade, Budget: name, Model: b.DowngradeModel,
+					Message: fmt.Sprintf("budget %q exceeded (%s), switching to %s", name, spent, b.DowngradeModel)}
+			}
+		}
+		return Decision{Action: ActionWarn, Budget: name,
+			Message: fmt.Sprintf("budget %q exceeded (%s)", name, spent)}
+	}
+	return Decision{Action: ActionAllow}
+}
+
+func describeSpend(st BudgetStatus) string {
+	var parts []string
+	if st.Budget.MaxTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d tokens", st.Spent.TotalTokens, st.Budget.MaxTokens))
+	}
+	if st.Budget.MaxCost > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f/$%.2f", st.Spent.Cost, st.Budget.MaxCost))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ValidateBudgets checks budget definitions for obvious mistakes.
+func ValidateBudgets(budgets []config.BudgetConfig) error {
+	for i, b := range budgets {
+		label := b.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+		switch b.Scope {
+		case ScopeSession, ScopeAgent, ScopeCron, ScopeModel, ScopeGlobal:
+		default:
+			return fmt.Errorf("budget %s: unknown scope %q", label, b.Scope)
+		}
+		switch b.Period {
+		case "", "day", "month":
+		default:
+			return fmt.Errorf("budget %s: unknown period %q (want day or month)", label, b.Period)
+		}
+		switch Action(b.Action) {
+		case ActionWarn, ActionStop:
+		case ActionDowngrade:
+			if b.DowngradeModel == "" {
+				return fmt.Errorf("budget %s: downgrade requires downgrade_model", label)
+			}
+		default:
+			return fmt.Errorf("budget %s: unknown action %q (want warn, downgrade or stop)", label, b.Action)
+		}
+		if b.MaxTokens <= 0 && b.MaxCost <= 0 {
+			return fmt.Errorf("budget %s: set max_tokens or max_cost", label)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+// Package usage keeps a provider-agnostic ledger of LLM token usage and
+// estimated cost, and enforces the budgets configured under `usage:`.
+//
+// Every LLM call made by an orchestrator is recorded with the session it
+// belongs to, the root session of its delegation tree, the agent that made
+// it, the cron job that triggered it (if any) and the model. Budgets are
+// evaluated against the same ledger before each call.
+package usage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dimension is a ledger column usage can be grouped or filtered by.
+type Dimension string
+
+const (
+	BySession Dimension = "session"
+	ByAgent   Dimension = "agent"
+	ByCron    Dimension = "cron"
+	ByModel   Dimension = "model"
+	ByDay     Dimension = "day"
+)
+
+// column returns the SQL expression for a dimension.
+func (d Dimension) column() (string, error) {
+	switch d {
+	case BySession:
+		return "root_session_id", nil
+	case ByAgent:
+		return "agent", nil
+	case ByCron:
+		return "cron_job", nil
+	case ByModel:
+		return "model", nil
+	case ByDay:
+		return "substr(created_at, 1, 10)", nil
+	default:
+		return "", fmt.Errorf("unknown usage dimension %q (want session, agent, cron, model or day)", d)
+	}
+}
+
+// ParseDimension validates a dimension name.
+func ParseDimension(s string) (Dimension, error) {
+	d := Dimension(s)
+	if _, err := d.column(); err != nil {
+		return "", err
+	}
+	return d, nil
+}
+
+// Entry is one LLM call.
+type Entry struct {
+	ID               int64     `json:"id"`
+	CreatedAt        time.Time `json:"created_at"`
+	SessionID        string    `json:"session_id"`
+	RootSessionID    string    `json:"root_session_id"`
+	Agent            string    `json:"agent"`
+	CronJob          string    `json:"cron_job,omitempty"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Cost             float64   `json:"cost"`
+}
+
+// Filter restricts ledger queries. Zero fields match everything.
+type Filter struct {
+	Since         time.Time
+	Until         time.Time
+	RootSessionID string
+	Agent         string
+	CronJob       string
+	Model         string
+}
+
+func (f Filter) where() (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		conds = append(conds, cond)
+		args = append(args, arg)
+	}
+	// Timestamps are stored in local time so that day grouping matches the
+	// user's calendar; bounds are converted the same way.
+	if !f.Since.IsZero() {
+		add("created_at >= ?", f.Since.Local())
+	}
+	if !f.Until.IsZero() {
+		add("created_at < ?", f.Until.Local())
+	}
+	if f.RootSessionID != "" {
+		add("root_session_id = ?", f.RootSessionID)
+	}
+	if f.Agent != "" {
+		add("agent = ?", f.Agent)
+	}
+	if f.CronJob != "" {
+		add("cron_job = ?", f.CronJob)
+	}
+	if f.Model != "" {
+		add("model = ?", f.Model)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// Totals aggregates a set of ledger entries.
+type Totals struct {
+	Key              string  `json:"key,omitempty"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// Ledger stores usage entries in the usage_ledger table.
+type Ledger struct {
+	db *sql.DB
+}
+
+// NewLedger creates a Ledger on the application database.
+func NewLedger(db *sql.DB) *Ledger {
+	return &Ledger{db: db}
+}
+
+// Record appends an entry.
+func (l *Ledger) Record(e Entry) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	e.CreatedAt = e.CreatedAt.Local()
+	_, err := l.db.Exec(`
+		INSERT INTO usage_ledger
+		(created_at, session_id, root_session_id, agent, cron_job, model,
+		 prompt_tokens, completion_tokens, total_tokens, cost)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.CreatedAt, e.SessionID, e.RootSessionID, e.Agent, e.CronJob, e.Model,
+		e.PromptTokens, e.CompletionTokens, e.TotalTokens, e.Cost)
+	return err
+}
+
+// Sum returns the totals of all entries matching f.
+func (l *Ledger) Sum(f Filter) (Totals, error) {
+	where, args := f.where()
+	var t Totals
+	err := l.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0),
+		       COALESCE(SUM(total_tokens), 0), COALESCE(SUM(cost), 0)
+		FROM usage_ledger`+where, args...).
+		Scan(&t.Calls, &t.PromptTokens, &t.CompletionTokens, &t.TotalTokens, &t.Cost)
+	return t, err
+}
+
+// Report returns totals of entries matching f grouped by dim, highest cost first.
+func (l *Ledger) Report(f Filter, dim Dimension, limit int) ([]Totals, error) {
+	col, err := dim.column()
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	where, args := f.where()
+	order := "SUM(cost) DESC, SUM(total_tokens) DESC"
+	if dim == ByDay {
+		order = "key DESC"
+	}
+	rows, err := l.db.Query(`
+		SELECT `+col+` AS key, COUNT(*), SUM(prompt_tokens), SUM(completion_tokens),
+		       SUM(total_tokens), SUM(cost)
+		FROM usage_ledger`+where+`
+		GROUP BY key ORDER BY `+order+` LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Totals
+	for rows.Next() {
+		var t Totals
+		if err := rows.Scan(&t.Key, &t.Calls, &t.PromptTokens, &t.CompletionTokens, &t.TotalTokens, &t.Cost); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// Recent returns the most recent entries matching f, newest first.
+func (l *Ledger) Recent(f Filter, limit int) ([]Entry, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	where, args := f.where()
+	rows, err := l.db.Query(`
+		SELECT id, created_at, session_id, root_session_id, agent, cron_job, model,
+		       prompt_tokens, completion_tokens, total_tokens, cost
+		FROM usage_ledger`+where+` ORDER BY id DESC LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.ID, &e.CreatedAt, &e.SessionID, &e.RootSessionID, &e.Agent, &e.CronJob,
+			&e.Model, &e.PromptTokens, &e.CompletionTokens, &e.TotalTokens, &e.Cost); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// ParseSince parses a report start time: a relative duration such as "24h",
+// "7d" or "2w", a date ("2006-01-02") or an RFC 3339 timestamp.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if n := len(s); n > 1 && (s[n-1] == 'd' || s[n-1] == 'w') {
+		var count int
+		if _, err := fmt.Sscanf(s[:n-1], "%d", &count); err == nil && count > 0 {
+			days := count
+			if s[n-1] == 'w' {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want e.g. 24h, 7d, 2006-01-02)", s)
+}
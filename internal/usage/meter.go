@@ -0,0 +1,188 @@
+package usage
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"mote/internal/config"
+)
+
+// Meter records LLM calls in the ledger and checks them against budgets.
+type Meter struct {
+	ledger *Ledger
+
+	mu      sync.RWMutex
+	pricing *Pricing
+	budgets []config.BudgetConfig
+
+	now func() time.Time
+}
+
+// NewMeter creates a Meter with the given price table and budgets.
+func NewMeter(ledger *Ledger, cfg config.UsageConfig) *Meter {
+	m := &Meter{ledger: ledger, now: time.Now}
+	m.Configure(cfg)
+	return m
+}
+
+// Configure replaces the price table and budgets.
+func (m *Meter) Configure(cfg config.UsageConfig) {
+	pricing := NewPricing(cfg.Prices)
+	budgets := append([]config.BudgetConfig(nil), cfg.Budgets...)
+	m.mu.Lock()
+	m.pricing = pricing
+	m.budgets = budgets
+	m.mu.Unlock()
+}
+
+// Ledger returns the underlying ledger.
+func (m *Meter) Ledger() *Ledger {
+	return m.ledger
+}
+
+// Pricing returns the current price table.
+func (m *Meter) Pricing() *Pricing {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.pricing
+}
+
+// Budgets returns the configured budgets.
+func (m *Meter) Budgets() []config.BudgetConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]config.BudgetConfig(nil), m.budgets...)
+}
+
+// Record stores a call made in scope.
+func (m *Meter) Record(s Scope, promptTokens, completionTokens, totalTokens int) error {
+	if totalTokens == 0 {
+		totalTokens = promptTokens + completionTokens
+	}
+	return m.ledger.Record(Entry{
+		CreatedAt:        m.now(),
+		SessionID:        s.SessionID,
+		RootSessionID:    s.RootSessionID,
+		Agent:            s.Agent,
+		CronJob:          s.CronJob,
+		Model:            s.Model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      totalTokens,
+		Cost:             m.Pricing().Cost(s.Model, promptTokens, completionTokens),
+	})
+}
+
+// Check evaluates every budget covering scope and returns the most severe
+// decision. Ledger errors are logged and never block a call.
+func (m *Meter) Check(s Scope) Decision {
+	now := m.now()
+	result := Decision{Action: ActionAllow}
+	for _, b := range m.Budgets() {
+		f, key, ok := applies(b, s, now)
+		if !ok {
+			continue
+		}
+		spent, err := m.ledger.Sum(f)
+		if err != nil {
+			slog.Warn("usage: failed to evaluate budget", "budget", b.Name, "error", err)
+			continue
+		}
+		d := decide(status(b, key, spent), s.Model)
+		if d.Action.severity() > result.Action.severity() {
+			result = d
+		}
+	}
+	return result
+}
+
+// Status reports spend against every budget. Budgets without a Match are
+// reported per key (agent, cron job, model or session) within their period.
+func (m *Meter) Status(limit int) ([]BudgetStatus, error) {
+	now := m.now()
+	var out []BudgetStatus
+	for _, b := range m.Budgets() {
+		f := Filter{Since: periodStart(b.Period, now)}
+		var dim Dimension
+		switch b.Scope {
+		case ScopeGlobal:
+		case ScopeSession:
+			dim = BySession
+		case ScopeAgent:
+			dim, f.Agent = ByAgent, b.Match
+		case ScopeCron:
+			dim, f.CronJob = ByCron, b.Match
+		case ScopeModel:
+			dim, f.Model = ByModel, b.Match
+		default:
+			continue
+		}
+
+		if dim == "" || b.Match != "" {
+			spent, err := m.ledger.Sum(f)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, status(b, b.Match, spent))
+			continue
+		}
+		totals, err := m.ledger.Report(f, dim, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range totals {
+			if dim == ByCron && t.Key == "" {
+				continue
+			}
+			out = append(out, status(b, t.Key, t))
+		}
+	}
+	return out, nil
+}
+
+// ForAgent returns a meter bound to one agent running model. It is safe to
+// call on a nil Meter; the returned AgentMeter is then a no-op.
+func (m *Meter) ForAgent(agent, model string) *AgentMeter {
+	if m == nil {
+		return nil
+	}
+	if agent == "" {
+		agent = MainAgent
+	}
+	return &AgentMeter{meter: m, agent: agent, model: model}
+}
+
+// AgentMeter is a Meter bound to one agent and model, handed to
+// orchestrators. All methods are safe to call on a nil receiver.
+type AgentMeter struct {
+	meter *Meter
+	agent string
+	model string
+}
+
+// Model returns the model calls are recorded under.
+func (a *AgentMeter) Model() string {
+	if a == nil {
+		return ""
+	}
+	return a.model
+}
+
+// Admit checks budgets before a call in sessionID.
+func (a *AgentMeter) Admit(sessionID string) Decision {
+	if a == nil {
+		return Decision{Action: ActionAllow}
+	}
+	return a.meter.Check(NewScope(sessionID, a.agent, a.model))
+}
+
+// Record stores a completed call.
+func (a *AgentMeter) Record(sessionID string, promptTokens, completionTokens, totalTokens int) {
+	if a == nil || (promptTokens == 0 && completionTokens == 0 && totalTokens == 0) {
+		return
+	}
+	if err := a.meter.Record(NewScope(sessionID, a.agent, a.model), promptTokens, completionTokens, totalTokens); err != nil {
+		slog.Warn("usage: failed to record call", "session", sessionID, "model", a.model, "error", err)
+	}
+}
@@ -0,0 +1,67 @@
+package usage
+
+import (
+	"sort"
+	"strings"
+
+	"mote/internal/config"
+)
+
+// Pricing estimates the cost of a call from the configured price table.
+type Pricing struct {
+	exact    map[string]config.ModelPrice
+	prefixes []prefixPrice // longest prefix first
+}
+
+type prefixPrice struct {
+	prefix string
+	price  config.ModelPrice
+}
+
+// NewPricing builds a Pricing from a model → price table. Keys ending in "*"
+// match any model with that prefix.
+func NewPricing(prices map[string]config.ModelPrice) *Pricing {
+	p := &Pricing{exact: make(map[string]config.ModelPrice)}
+	for model, price := range prices {
+		if strings.HasSuffix(model, "*") {
+			p.prefixes = append(p.prefixes, prefixPrice{prefix: strings.TrimSuffix(model, "*"), price: price})
+			continue
+		}
+		p.exact[model] = price
+	}
+	sort.Slice(p.prefixes, func(i, j int) bool {
+		return len(p.prefixes[i].prefix) > len(p.prefixes[j].prefix)
+	})
+	return p
+}
+
+// Lookup returns the price for model. Provider prefixes such as "ollama:"
+// are tried with and without the prefix.
+func (p *Pricing) Lookup(model string) (config.ModelPrice, bool) {
+	candidates := []string{model}
+	if i := strings.Index(model, ":"); i > 0 {
+		candidates = append(candidates, model[i+1:])
+	}
+	for _, m := range candidates {
+		if price, ok := p.exact[m]; ok {
+			return price, true
+		}
+	}
+	for _, m := range candidates {
+		for _, pp := range p.prefixes {
+			if strings.HasPrefix(m, pp.prefix) {
+				return pp.price, true
+			}
+		}
+	}
+	return config.ModelPrice{}, false
+}
+
+// Cost returns the estimated USD cost of a call. Unpriced models cost 0.
+func (p *Pricing) Cost(model string, promptTokens, completionTokens int) float64 {
+	price, ok := p.Lookup(model)
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*price.Input + float64(completionTokens)*price.Output) / 1e6
+}
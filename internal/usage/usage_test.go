@@ -0,0 +1,191 @@
+package usage_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mote/internal/config"
+	"mote/internal/storage"
+	"mote/internal/usage"
+)
+
+func openLedger(t *testing.T) *usage.Ledger {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "usage.db"))
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return usage.NewLedger(db.DB)
+}
+
+func TestPricing(t *testing.T) {
+	p := usage.NewPricing(map[string]config.ModelPrice{
+		"gpt-4o":       {Input: 2.5, Output: 10},
+		"claude-*":     {Input: 3, Output: 15},
+		"claude-opus*": {Input: 15, Output: 75},
+	})
+
+	if got := p.Cost("gpt-4o", 1_000_000, 100_000); got != 3.5 {
+		t.Errorf("gpt-4o cost = %v, want 3.5", got)
+	}
+	if got := p.Cost("copilot:gpt-4o", 1_000_000, 0); got != 2.5 {
+		t.Errorf("provider prefix not stripped: %v", got)
+	}
+	if got := p.Cost("claude-opus-4", 0, 1_000_000); got != 75 {
+		t.Errorf("longest wildcard should win: %v", got)
+	}
+	if got := p.Cost("claude-sonnet-4", 1_000_000, 0); got != 3 {
+		t.Errorf("wildcard cost = %v, want 3", got)
+	}
+	if got := p.Cost("llama3.2", 1_000_000, 1_000_000); got != 0 {
+		t.Errorf("unpriced model should cost 0, got %v", got)
+	}
+}
+
+func TestScope(t *testing.T) {
+	s := usage.NewScope("delegate:delegate:cron-nightly:planner:1:coder:2", "coder", "gpt-4o")
+	if s.RootSessionID != "cron-nightly" || s.CronJob != "nightly" || s.Agent != "coder" {
+		t.Errorf("unexpected scope: %+v", s)
+	}
+	if s := usage.NewScope("abc", "", "m"); s.RootSessionID != "abc" || s.CronJob != "" || s.Agent != usage.MainAgent {
+		t.Errorf("unexpected scope: %+v", s)
+	}
+}
+
+func TestLedgerReport(t *testing.T) {
+	ledger := openLedger(t)
+	meter := usage.NewMeter(ledger, config.UsageConfig{
+		Prices: map[string]config.ModelPrice{"gpt-4o": {Input: 1, Output: 2}},
+	})
+
+	meter.ForAgent("", "gpt-4o").Record("s1", 1000, 500, 0)
+	meter.ForAgent("researcher", "gpt-4o").Record("delegate:s1:researcher:1", 2000, 1000, 3000)
+	meter.ForAgent("", "llama3").Record("cron-digest", 100, 50, 150)
+
+	total, err := ledger.Sum(usage.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.Calls != 3 || total.TotalTokens != 4650 {
+		t.Errorf("unexpected totals: %+v", total)
+	}
+
+	bySession, err := ledger.Report(usage.Filter{}, usage.BySession, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bySession) != 2 || bySession[0].Key != "s1" || bySession[0].Calls != 2 {
+		t.Errorf("delegate usage should roll up into the root session: %+v", bySession)
+	}
+	wantCost := (3000*1.0 + 1500*2.0) / 1e6
+	if diff := bySession[0].Cost - wantCost; diff > 1e-12 || diff < -1e-12 {
+		t.Errorf("cost = %v, want %v", bySession[0].Cost, wantCost)
+	}
+
+	byCron, err := ledger.Report(usage.Filter{CronJob: "digest"}, usage.ByModel, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byCron) != 1 || byCron[0].Key != "llama3" {
+		t.Errorf("unexpected cron report: %+v", byCron)
+	}
+
+	byDay, err := ledger.Report(usage.Filter{Since: time.Now().Add(-time.Hour)}, usage.ByDay, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byDay) != 1 || byDay[0].Key != time.Now().Format("2006-01-02") {
+		t.Errorf("unexpected day report: %+v", byDay)
+	}
+
+	if _, err := ledger.Report(usage.Filter{}, usage.Dimension("colour"), 10); err == nil {
+		t.Error("expected error for unknown dimension")
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	ledger := openLedger(t)
+	cfg := config.UsageConfig{
+		Budgets: []config.BudgetConfig{
+			{Name: "session", Scope: usage.ScopeSession, MaxTokens: 1000, Action: "stop"},
+			{Name: "researcher", Scope: usage.ScopeAgent, Match: "researcher", Period: "day",
+				MaxTokens: 500, Action: "downgrade", DowngradeModel: "gpt-4o-mini"},
+		},
+	}
+	if err := usage.ValidateBudgets(cfg.Budgets); err != nil {
+		t.Fatalf("valid budgets rejected: %v", err)
+	}
+	meter := usage.NewMeter(ledger, cfg)
+	main := meter.ForAgent("", "gpt-4o")
+	researcher := meter.ForAgent("researcher", "gpt-4o")
+
+	if d := main.Admit("s1"); d.Action != usage.ActionAllow {
+		t.Fatalf("fresh session should be allowed, got %+v", d)
+	}
+
+	researcher.Record("delegate:s1:researcher:1", 300, 150, 450)
+	if d := researcher.Admit("delegate:s1:researcher:2"); d.Action != usage.ActionWarn {
+		t.Errorf("90%% of budget should warn, got %+v", d)
+	}
+
+	researcher.Record("delegate:s1:researcher:1", 50, 50, 100)
+	d := researcher.Admit("delegate:s1:researcher:2")
+	if d.Action != usage.ActionDowngrade || d.Model != "gpt-4o-mini" {
+		t.Errorf("exceeded budget should downgrade, got %+v", d)
+	}
+	if d := meter.ForAgent("researcher", "gpt-4o-mini").Admit("s2"); d.Action != usage.ActionWarn {
+		t.Errorf("already downgraded model should only warn, got %+v", d)
+	}
+	if d := meter.ForAgent("writer", "gpt-4o").Admit("s2"); d.Action != usage.ActionAllow {
+		t.Errorf("other agents are not covered, got %+v", d)
+	}
+
+	main.Record("s1", 300, 200, 500)
+	if d := main.Admit("s1"); d.Action != usage.ActionStop || d.Budget != "session" {
+		t.Errorf("stop should outrank other decisions, got %+v", d)
+	}
+
+	statuses, err := meter.Status(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0].Key != "s1" || statuses[0].State != "exceeded" {
+		t.Errorf("unexpected statuses: %+v", statuses)
+	}
+}
+
+func TestValidateBudgets(t *testing.T) {
+	bad := map[string]config.BudgetConfig{
+		"scope":     {Scope: "team", MaxTokens: 1, Action: "warn"},
+		"period":    {Scope: "global", Period: "week", MaxTokens: 1, Action: "warn"},
+		"action":    {Scope: "global", MaxTokens: 1, Action: "panic"},
+		"downgrade": {Scope: "global", MaxTokens: 1, Action: "downgrade"},
+		"limit":     {Scope: "global", Action: "warn"},
+	}
+	for name, b := range bad {
+		if err := usage.ValidateBudgets([]config.BudgetConfig{b}); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	cases := map[string]time.Time{
+		"7d":         now.AddDate(0, 0, -7),
+		"2w":         now.AddDate(0, 0, -14),
+		"36h":        now.Add(-36 * time.Hour),
+		"2026-03-01": time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),
+	}
+	for in, want := range cases {
+		got, err := usage.ParseSince(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseSince(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := usage.ParseSince("yesterday", now); err == nil {
+		t.Error("expected error for unparseable time")
+	}
+}
@@ -10,6 +10,11 @@ const (
 	ChannelTypeIMessage  ChannelType = "imessage"
 	ChannelTypeNotes     ChannelType = "apple-notes"
 	ChannelTypeReminders ChannelType = "apple-reminders"
+	ChannelTypeTelegram  ChannelType = "telegram"
+	ChannelTypeEmail     ChannelType = "email"
+	ChannelTypeMatrix    ChannelType = "matrix"
+	ChannelTypeSlack     ChannelType = "slack"
+	ChannelTypeWebhook   ChannelType = "webhook"
 )
 
 // MessageType 消息类型
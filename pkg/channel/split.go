@@ -0,0 +1,37 @@
+package channel
+
+import "strings"
+
+// SplitMessage 按渠道的单条消息长度上限（按字符计）拆分长文本。
+// 优先在段落、换行、空格处断开，避免切断单词；limit <= 0 时不拆分。
+func SplitMessage(text string, limit int) []string {
+	if limit <= 0 {
+		return []string{text}
+	}
+	var parts []string
+	runes := []rune(text)
+	for len(runes) > limit {
+		cut := splitPoint(runes[:limit])
+		part := strings.TrimRight(string(runes[:cut]), " \n")
+		if part != "" {
+			parts = append(parts, part)
+		}
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " \n"))
+	}
+	if len(runes) > 0 || len(parts) == 0 {
+		parts = append(parts, string(runes))
+	}
+	return parts
+}
+
+// splitPoint 在窗口后半段寻找最合适的断点，找不到则硬切
+func splitPoint(window []rune) int {
+	s := string(window)
+	min := len(s) / 2
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if i := strings.LastIndex(s, sep); i >= min {
+			return len([]rune(s[:i+len(sep)]))
+		}
+	}
+	return len(window)
+}
@@ -0,0 +1,37 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitMessage(t *testing.T) {
+	if got := SplitMessage("short", 10); len(got) != 1 || got[0] != "short" {
+		t.Errorf("short message: %q", got)
+	}
+	if got := SplitMessage("", 10); len(got) != 1 {
+		t.Errorf("empty message: %q", got)
+	}
+
+	text := "first paragraph here\n\nsecond paragraph is a bit longer"
+	got := SplitMessage(text, 35)
+	if len(got) != 2 || got[0] != "first paragraph here" || got[1] != "second paragraph is a bit longer" {
+		t.Errorf("paragraph split: %q", got)
+	}
+
+	got = SplitMessage("alpha beta gamma delta epsilon", 12)
+	for _, part := range got {
+		if len([]rune(part)) > 12 || strings.HasPrefix(part, " ") {
+			t.Errorf("bad part %q in %q", part, got)
+		}
+	}
+	if strings.Join(got, " ") != "alpha beta gamma delta epsilon" {
+		t.Errorf("words lost: %q", got)
+	}
+
+	// No break point: hard split on rune boundaries
+	got = SplitMessage(strings.Repeat("字", 25), 10)
+	if len(got) != 3 || len([]rune(got[0])) != 10 || len([]rune(got[2])) != 5 {
+		t.Errorf("hard split: %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+import React from 'react';
+import { Card, List, Tag, Button, Empty, Badge, Typography, Popconfirm } from 'antd';
+import type { ApprovalRule } from '../../types/policy';
+
+const { Text } = Typography;
+
+export interface ApprovalRulesCardProps {
+  rules: ApprovalRule[];
+  onRevoke: (id: string) => void;
+}
+
+const scopeLabels: Record<ApprovalRule['scope'], string> = {
+  session: '会话',
+  workspace: '工作区',
+};
+
+const formatTime = (t?: string) => {
+  if (!t || t.startsWith('0001-')) {
+    return '永不';
+  }
+  try {
+    return new Date(t).toLocaleString();
+  } catch {
+    return t;
+  }
+};
+
+export const ApprovalRulesCard: React.FC<ApprovalRulesCardProps> = ({
+  rules,
+  onRevoke,
+}) => {
+  return (
+    <Card
+      title={
+        <>
+          已记住的审批 <Badge count={rules.length} style={{ marginLeft: 8 }} />
+        </>
+      }
+      size="small"
+      style={{ marginBottom: 16 }}
+    >
+      {rules.length === 0 ? (
+        <Empty description="暂无记住的审批决定" image={Empty.PRESENTED_IMAGE_SIMPLE} />
+      ) : (
+        <List
+          dataSource={rules}
+          renderItem={(item) => (
+            <List.Item
+              actions={[
+                <Popconfirm
+                  key="revoke"
+                  title="撤销后相同调用将重新请求审批，确定撤销？"
+                  onConfirm={() => onRevoke(item.id)}
+                >
+                  <Button danger size="small">
+                    撤销
+                  </Button>
+                </Popconfirm>,
+              ]}
+            >
+              <List.Item.Meta
+                title={
+                  <>
+                    <Tag>{item.tool}</Tag>
+                    <Tag color={item.scope === 'workspace' ? 'orange' : 'blue'}>
+                      {scopeLabels[item.scope] ?? item.scope}
+                    </Tag>
+                    <Text code ellipsis style={{ maxWidth: 360 }}>
+                      {item.pattern}
+                    </Text>
+                  </>
+                }
+                description={
+                  <Text type="secondary">
+                    {item.scope === 'workspace' ? `工作区: ${item.workspace_path}` : `会话: ${item.session_id}`}
+                    {' · '}命中 {item.hits} 次 · 创建: {formatTime(item.created_at)} · 过期: {formatTime(item.expires_at)}
+                  </Text>
+                }
+              />
+            </List.Item>
+          )}
+        />
+      )}
+    </Card>
+  );
+};
@@ -1,12 +1,13 @@
 import { useState, useEffect, useCallback, useImperativeHandle, forwardRef } from 'react';
 import { message, Spin, Alert } from 'antd';
 import { useAPI } from '../../context/APIContext';
-import type { PolicyConfig, ApprovalRequest } from '../../types/policy';
+import type { PolicyConfig, ApprovalRequest, ApprovalRule } from '../../types/policy';
 import { PolicyOverviewCard } from './PolicyOverviewCard';
 import { ListManagementCard } from './ListManagementCard';
 import { DangerousOpsCard } from './DangerousOpsCard';
 import { ParamRulesCard } from './ParamRulesCard';
 import { PendingApprovalsCard } from './PendingApprovalsCard';
+import { ApprovalRulesCard } from './ApprovalRulesCard';
 import { ScrubRulesCard } from './ScrubRulesCard';
 import { BlockMessageCard } from './BlockMessageCard';
 
@@ -36,6 +37,7 @@ export const SecuritySettingsTab = forwardRef<SecuritySettingsTabHandle>((_props
   const [saving, setSaving] = useState(false);
   const [policy, setPolicy] = useState<PolicyConfig>(defaultPolicy);
   const [pendingApprovals, setPendingApprovals] = useState<ApprovalRequest[]>([]);
+  const [approvalRules, setApprovalRules] = useState<ApprovalRule[]>([]);
 
   const loadData = useCallback(async () => {
     setLoading(true);
@@ -48,6 +50,10 @@ export const SecuritySettingsTab = forwardRef<SecuritySettingsTabHandle>((_props
         const resp = await api.getApprovals();
         setPendingApprovals(resp.pending || []);
       }
+      if (api.getApprovalRules) {
+        const resp = await api.getApprovalRules();
+        setApprovalRules(resp.rules || []);
+      }
     } catch (err) {
       console.error('Failed to load security settings:', err);
     } finally {
@@ -115,6 +121,18 @@ export const SecuritySettingsTab = forwardRef<SecuritySettingsTabHandle>((_props
     }
   };
 
+  const handleRevokeRule = async (id: string) => {
+    try {
+      if (api.revokeApprovalRule) {
+        await api.revokeApprovalRule(id);
+        message.success('已撤销');
+        loadData();
+      }
+    } catch (err) {
+      message.error('操作失败: ' + String(err));
+    }
+  };
+
   if (loading) {
     return (
       <div style={{ textAlign: 'center', padding: 48 }}>
@@ -174,6 +192,11 @@ export const SecuritySettingsTab = forwardRef<SecuritySettingsTabHandle>((_props
         onApprove={handleApprove}
         onReject={handleReject}
       />
+
+      <ApprovalRulesCard
+        rules={approvalRules}
+        onRevoke={handleRevokeRule}
+      />
     </div>
   );
 });
@@ -16,7 +16,7 @@ export interface ChatState {
   currentContent: string;
   currentThinking: string;  // Temporary thinking content (cleared when other output arrives)
   thinkingDone: boolean;     // Whether thinking phase has ended (content/tool_call started)
-  currentToolCalls: { [key: string]: { name: string; status?: string; arguments?: string; result?: unknown; error?: string } };
+  currentToolCalls: { [key: string]: { name: string; status?: string; arguments?: string; progress?: string; result?: unknown; error?: string } };
   messages: Message[];
   finalMessage?: Message;  // Set when streaming completes, contains the final assistant message
   error?: string;
@@ -215,7 +215,7 @@ export const ChatManagerProvider: React.FC<{ children: React.ReactNode }> = ({ c
 
     let accumulatedContent = '';
     let accumulatedThinking = '';  // 累积 thinking 内容
-    let accumulatedToolCalls: { [key: string]: { name: string; status?: string; arguments?: string; result?: unknown; error?: string } } = {};
+    let accumulatedToolCalls: { [key: string]: { name: string; status?: string; arguments?: string; progress?: string; result?: unknown; error?: string } } = {};
     let isFinalized = false;
     let lastContentAgentName: string | undefined = undefined; // Track agent for inline tags
 
@@ -281,6 +281,7 @@ export const ChatManagerProvider: React.FC<{ children: React.ReactNode }> = ({ c
         const toolName = event.tool_call_update.tool_name;
         const status = event.tool_call_update.status;
         const args = event.tool_call_update.arguments;
+        const progress = event.tool_call_update.message;
         if (toolName) {
           const existing = accumulatedToolCalls[toolName];
           accumulatedToolCalls[toolName] = {
@@ -288,6 +289,7 @@ export const ChatManagerProvider: React.FC<{ children: React.ReactNode }> = ({ c
             name: toolName,
             status: status || existing?.status,
             arguments: args || existing?.arguments,
+            progress: progress || existing?.progress,
           };
           state.currentToolCalls = { ...accumulatedToolCalls };
           // Don't mark thinking as done on tool_call_update — keep panel visible
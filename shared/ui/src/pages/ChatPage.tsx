@@ -26,6 +26,7 @@ import { useConnectionStatusSafe, useHasConnectionIssuesSafe } from '../context/
 import moteLogo from '../assets/mote_logo.png';
 import userAvatar from '../assets/user.png';
 import type { Message, Model, Workspace, ErrorDetail, Skill, ReconfigureSessionResponse, ImageAttachment, ApprovalRequestSSEEvent, PDACheckpointInfo } from '../types';
+import type { ApprovalScope } from '../types/policy';
 
 const { TextArea } = Input;
 const { Text } = Typography;
@@ -618,6 +619,11 @@ const StreamingContent: React.FC<StreamingContentProps> = ({ sessionId, tokenCol
                 `;
               }
               
+              let progressHtml = '';
+              if (tool.progress && !tool.result && !tool.error) {
+                progressHtml = `<div style="color: ${tokenColors.colorTextSecondary}; margin-bottom: 4px; font-size: 11px">${escapeHtml(tool.progress)}</div>`;
+              }
+
               let errorHtml = '';
               if (tool.error) {
                 errorHtml = `<div style="color: #ff4d4f; margin-bottom: 4px">错误: ${escapeHtml(tool.error)}</div>`;
@@ -634,7 +640,7 @@ const StreamingContent: React.FC<StreamingContentProps> = ({ sessionId, tokenCol
                   <div style="font-weight: 500; margin-bottom: 4px; display: flex; align-items: center">
                     ${escapeHtml(tool.name)}${statusIcon}
                   </div>
-                  ${argsHtml}${errorHtml}${resultHtml}
+                  ${progressHtml}${argsHtml}${errorHtml}${resultHtml}
                 </div>
               `;
             }).join('');
@@ -863,7 +869,7 @@ const StreamingContent: React.FC<StreamingContentProps> = ({ sessionId, tokenCol
 interface LegacyStreamingContentProps {
   content: string;
   thinking: string;
-  toolCalls: { [key: string]: { name: string; status?: string; arguments?: string; result?: string | object; error?: string } };
+  toolCalls: { [key: string]: { name: string; status?: string; arguments?: string; progress?: string; result?: string | object; error?: string } };
   tokenColors: TokenColors;
   effectiveTheme: string;
 }
@@ -943,6 +949,9 @@ const LegacyStreamingContent = memo<LegacyStreamingContentProps>(({ content, thi
                               )}
                               {!tool.status && !tool.result && !tool.error && <Spin size="small" style={{ marginLeft: 8 }} />}
                             </div>
+                            {tool.progress && !tool.result && !tool.error && (
+                              <div style={{ marginBottom: 4, fontSize: 11 }}>{tool.progress}</div>
+                            )}
                             {tool.arguments && (
                               <div style={{ marginBottom: 4 }}>
                                 <div style={{ color: tokenColors.colorTextSecondary, marginBottom: 2, fontSize: 11 }}>参数:</div>
@@ -1129,6 +1138,7 @@ export const ChatPage: React.FC<ChatPageProps> = ({ sessionId: initialSessionId,
   // Approval request state - tool call waiting for user approval
   const [approvalRequest, setApprovalRequest] = useState<ApprovalRequestSSEEvent | null>(null);
   const [approvalEditArgs, setApprovalEditArgs] = useState<string>('');
+  const [approvalScope, setApprovalScope] = useState<ApprovalScope>('once');
   const approvalArgsModifiedRef = useRef(false);
   // PDA checkpoint state
   const [pdaCheckpoint, setPdaCheckpoint] = useState<PDACheckpointInfo | null>(null);
@@ -1584,6 +1594,7 @@ export const ChatPage: React.FC<ChatPageProps> = ({ sessionId: initialSessionId,
           setApprovalEditArgs(state.approvalRequest.arguments || '');
         }
         approvalArgsModifiedRef.current = false;
+        setApprovalScope('once');
       } else {
         setApprovalRequest(null);
       }
@@ -2058,10 +2069,13 @@ export const ChatPage: React.FC<ChatPageProps> = ({ sessionId: initialSessionId,
     try {
       // If approved and arguments were modified, pass the edited version
       const modifiedArgs = (approved && approvalArgsModifiedRef.current) ? approvalEditArgs : undefined;
-      await api.respondApproval(approvalRequest.id, approved, undefined, modifiedArgs);
+      // Remembered scopes only apply to approvals of the original arguments
+      const scope = (approved && !modifiedArgs) ? approvalScope : undefined;
+      await api.respondApproval(approvalRequest.id, approved, undefined, modifiedArgs, scope);
       message.success(approved ? '已批准工具调用' : '已拒绝工具调用');
       setApprovalRequest(null);
       setApprovalEditArgs('');
+      setApprovalScope('once');
       approvalArgsModifiedRef.current = false;
     } catch (err: any) {
       message.error(`审批响应失败: ${err.message}`);
@@ -3198,6 +3212,20 @@ export const ChatPage: React.FC<ChatPageProps> = ({ sessionId: initialSessionId,
                   }}
                 />
               </div>
+              <div style={{ marginBottom: 8 }}>
+                <strong>批准范围：</strong>
+                <Select
+                  value={approvalScope}
+                  onChange={setApprovalScope}
+                  size="small"
+                  style={{ width: 240, marginLeft: 4 }}
+                  options={[
+                    { value: 'once', label: '仅本次调用' },
+                    { value: 'session', label: '本会话内相同调用' },
+                    { value: 'workspace', label: '此工作区内相同调用（始终）', disabled: !approvalRequest.workspace_path },
+                  ]}
+                />
+              </div>
               {approvalRequest.expires_at && (
                 <p style={{ fontSize: 12, color: tokenColors.colorTextSecondary }}>
                   <ClockCircleOutlined style={{ marginRight: 4 }} />
@@ -260,11 +260,29 @@ export const MCPPage: React.FC = () => {
                               </Tag>
                             </Tooltip>
                           )}
+                          {server.health && !server.health.healthy && (
+                            <Tooltip title={server.health.last_error}>
+                              <Tag color="orange" style={{ margin: 0 }}>
+                                健康检查失败 {server.health.consecutive_failures ?? 0} 次
+                              </Tag>
+                            </Tooltip>
+                          )}
                         </>
                       ) : (
-                        <Tag color={server.status === 'error' ? 'red' : 'default'} style={{ margin: 0 }}>
-                          {getStatusText(server.status)}
-                        </Tag>
+                        <>
+                          <Tag color={server.status === 'error' ? 'red' : 'default'} style={{ margin: 0 }}>
+                            {getStatusText(server.status)}
+                          </Tag>
+                          {server.health?.gave_up ? (
+                            <Tag color="red" style={{ margin: 0 }}>已停止自动重启</Tag>
+                          ) : server.health?.next_restart && (
+                            <Tooltip title={`已重启 ${server.health.restarts ?? 0} 次`}>
+                              <Tag color="orange" style={{ margin: 0 }}>
+                                {new Date(server.health.next_restart).toLocaleTimeString()} 重试
+                              </Tag>
+                            </Tooltip>
+                          )}
+                        </>
                       )}
                     </div>
                     {server.error && (
@@ -44,6 +44,8 @@ import type {
   PolicyCheckRequest,
   PolicyCheckResponse,
   ApprovalListResponse,
+  ApprovalScope,
+  ApprovalRulesResponse,
 } from '../types/policy';
 
 // Re-export policy types for convenience
@@ -53,6 +55,8 @@ export type {
   PolicyCheckRequest,
   PolicyCheckResponse,
   ApprovalListResponse,
+  ApprovalScope,
+  ApprovalRulesResponse,
 } from '../types/policy';
 
 /**
@@ -532,7 +536,17 @@ export interface APIAdapter {
   /**
    * Respond to a pending approval request
    */
-  respondApproval?(id: string, approved: boolean, reason?: string, modifiedArguments?: string): Promise<{ success: boolean }>;
+  respondApproval?(id: string, approved: boolean, reason?: string, modifiedArguments?: string, scope?: ApprovalScope): Promise<{ success: boolean }>;
+
+  /**
+   * List remembered approval rules
+   */
+  getApprovalRules?(): Promise<ApprovalRulesResponse>;
+
+  /**
+   * Revoke a remembered approval rule
+   */
+  revokeApprovalRule?(id: string): Promise<void>;
 
   // ============== PDA Checkpoint Control ==============
   /**
@@ -651,6 +665,8 @@ export const createNoopAdapter = (): APIAdapter => ({
   checkPolicy: async () => ({ tool: '', allowed: true, require_approval: false, blocked: false }),
   getApprovals: async () => ({ pending: [], count: 0 }),
   respondApproval: async () => ({ success: true }),
+  getApprovalRules: async () => ({ rules: [], count: 0 }),
+  revokeApprovalRule: async () => {},
   cancelChat: async () => {},
   // PDA Checkpoint Control
   getPDAStatus: async () => ({ has_checkpoint: false }),
@@ -776,14 +776,22 @@ export const createHttpAdapter = (options: HttpAdapterOptions = {}): APIAdapter
       return fetchJSON<import('../types/policy').ApprovalListResponse>('/api/v1/approvals');
     },
 
-    respondApproval: async (id: string, approved: boolean, reason?: string, modifiedArguments?: string) => {
+    respondApproval: async (id: string, approved: boolean, reason?: string, modifiedArguments?: string, scope?: import('../types/policy').ApprovalScope) => {
       return fetchJSON<{ success: boolean }>(`/api/v1/approvals/${encodeURIComponent(id)}/respond`, {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify({ approved, reason, modified_arguments: modifiedArguments }),
+        body: JSON.stringify({ approved, reason, modified_arguments: modifiedArguments, scope }),
       });
     },
 
+    getApprovalRules: async () => {
+      return fetchJSON<import('../types/policy').ApprovalRulesResponse>('/api/v1/approvals/rules');
+    },
+
+    revokeApprovalRule: async (id: string) => {
+      await fetchJSON(`/api/v1/approvals/rules/${encodeURIComponent(id)}`, { method: 'DELETE' });
+    },
+
     // ============== PDA Checkpoint Control ==============
     getPDAStatus: async (sessionId: string): Promise<PDACheckpointInfo> => {
       return fetchJSON<PDACheckpointInfo>(`/api/v1/sessions/${encodeURIComponent(sessionId)}/pda`, {
@@ -780,8 +780,16 @@ export function createWailsAdapter(app: WailsApp): APIAdapter {
       return callAPI<import('../types/policy').ApprovalListResponse>('GET', '/api/v1/approvals');
     },
 
-    respondApproval: async (id: string, approved: boolean, reason?: string, modifiedArguments?: string) => {
-      return callAPI<{ success: boolean }>('POST', `/api/v1/approvals/${encodeURIComponent(id)}/respond`, { approved, reason, modified_arguments: modifiedArguments });
+    respondApproval: async (id: string, approved: boolean, reason?: string, modifiedArguments?: string, scope?: import('../types/policy').ApprovalScope) => {
+      return callAPI<{ success: boolean }>('POST', `/api/v1/approvals/${encodeURIComponent(id)}/respond`, { approved, reason, modified_arguments: modifiedArguments, scope });
+    },
+
+    getApprovalRules: async () => {
+      return callAPI<import('../types/policy').ApprovalRulesResponse>('GET', '/api/v1/approvals/rules');
+    },
+
+    revokeApprovalRule: async (id: string) => {
+      await callAPI('DELETE', `/api/v1/approvals/rules/${encodeURIComponent(id)}`);
     },
 
     // ============== PDA Checkpoint Control ==============
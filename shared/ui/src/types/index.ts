@@ -203,6 +203,23 @@ export interface MCPServer {
   url?: string;
   headers?: Record<string, string>;
   error?: string;
+  health?: MCPServerHealth;
+  include_tools?: string[];
+  exclude_tools?: string[];
+  timeout?: number;
+  cache_ttl?: number;
+  cache_tools?: string[];
+}
+
+export interface MCPServerHealth {
+  healthy: boolean;
+  last_check?: string;
+  latency_ms?: number;
+  consecutive_failures?: number;
+  restarts?: number;
+  next_restart?: string;
+  gave_up?: boolean;
+  last_error?: string;
 }
 
 // ================================================================
@@ -324,6 +341,7 @@ export interface ApprovalRequestSSEEvent {
   reason: string;
   session_id: string;
   expires_at: string;
+  workspace_path?: string;
 }
 
 export interface ApprovalResolvedSSEEvent {
@@ -382,6 +400,7 @@ export interface StreamEvent {
     tool_name: string;
     status?: string;  // "running", "completed"
     arguments?: string;
+    message?: string;  // Progress or log line reported while the tool runs
   };
   tool_result?: {
     tool_call_id?: string;
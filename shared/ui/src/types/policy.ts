@@ -14,6 +14,10 @@ export interface PolicyConfig {
   blocklist: string[];
   dangerous_ops: DangerousOpRule[];
   param_rules: Record<string, ParamRule>;
+  rules?: PolicyRule[];
+  overlays?: PolicyOverlay[];
+  file_access?: FileAccessPolicy;
+  egress?: EgressPolicy;
   scrub_rules: ScrubRule[];
   block_message_template: string;
   circuit_breaker_threshold: number;
@@ -36,6 +40,45 @@ export interface ParamRule {
   enabled?: boolean;
 }
 
+/** Conditional rule; `when` is an expression over the call and its context. */
+export interface PolicyRule {
+  name: string;
+  tool?: string;
+  when?: string;
+  action: 'block' | 'approve' | 'warn';
+  message?: string;
+  enabled?: boolean;
+}
+
+/** Policy adjustments for an agent or workspace, merged over the base policy. */
+export interface PolicyOverlay {
+  agent?: string;
+  workspace?: string;
+  default_allow?: boolean;
+  require_approval?: boolean;
+  allowlist?: string[];
+  blocklist?: string[];
+  dangerous_ops?: DangerousOpRule[];
+  param_rules?: Record<string, ParamRule>;
+  rules?: PolicyRule[];
+  file_access?: FileAccessPolicy;
+  egress?: EgressPolicy;
+}
+
+/** Read/write scopes for file tools, checked on resolved paths. */
+export interface FileAccessPolicy {
+  read?: string[];
+  write?: string[];
+  deny?: string[];
+}
+
+/** Hosts tools, scripts and tool-started processes may connect to. */
+export interface EgressPolicy {
+  allow?: string[];
+  deny?: string[];
+  block_private?: boolean;
+}
+
 export interface PolicyStatus {
   default_allow: boolean;
   require_approval: boolean;
@@ -43,6 +86,8 @@ export interface PolicyStatus {
   allowlist_count: number;
   dangerous_rules_count: number;
   param_rules_count: number;
+  rules_count?: number;
+  overlays_count?: number;
   message?: string;
 }
 
@@ -50,6 +95,8 @@ export interface PolicyCheckRequest {
   tool: string;
   arguments: string;
   session_id?: string;
+  agent_id?: string;
+  workspace_path?: string;
 }
 
 export interface PolicyCheckResponse {
@@ -58,7 +105,9 @@ export interface PolicyCheckResponse {
   require_approval: boolean;
   blocked: boolean;
   reason?: string;
+  approval_reason?: string;
   warnings?: string[];
+  matched_rules?: string[];
 }
 
 export interface ApprovalRequest {
@@ -68,6 +117,7 @@ export interface ApprovalRequest {
   reason: string;
   session_id: string;
   agent_id: string;
+  workspace_path?: string;
   created_at: string;
   expires_at: string;
 }
@@ -76,3 +126,26 @@ export interface ApprovalListResponse {
   pending: ApprovalRequest[];
   count: number;
 }
+
+/** How far an approval extends: this call, this session, or this workspace */
+export type ApprovalScope = 'once' | 'session' | 'workspace';
+
+/** Allow rule remembered from a scoped approval */
+export interface ApprovalRule {
+  id: string;
+  scope: Exclude<ApprovalScope, 'once'>;
+  tool: string;
+  pattern: string;
+  session_id?: string;
+  workspace_path?: string;
+  created_by?: string;
+  request_id?: string;
+  created_at: string;
+  expires_at?: string;
+  hits: number;
+}
+
+export interface ApprovalRulesResponse {
+  rules: ApprovalRule[];
+  count: number;
+}